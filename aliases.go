@@ -0,0 +1,330 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrAliasNotFound is returned by RemoveAlias and RewriteAliasedFacts when
+// the named alias does not exist.
+var ErrAliasNotFound = errors.New("memstore: subject alias not found")
+
+// SubjectAlias records that Alias is another name for the subject
+// Canonical -- a renamed repo, a fork, an abbreviation. Aliases are per
+// user and match case-insensitively.
+type SubjectAlias struct {
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AliasRewriteResult reports a RewriteAliasedFacts run.
+type AliasRewriteResult struct {
+	Facts    int               `json:"facts"`              // facts whose subject was rewritten, history and trash included
+	Subjects map[string]string `json:"subjects,omitempty"` // alias found on facts -> canonical subject it was rewritten to
+	AuditID  int64             `json:"audit_id,omitempty"`
+}
+
+// SubjectAliaser is implemented by stores that keep subject aliases. Both
+// built-in backends and the HTTP client implement it.
+//
+// The store consults the alias table itself: Insert, InsertBatch and
+// BulkUpdate file a fact given an alias under its canonical subject, and a
+// Subject filter in List, BySubject, Search and SearchFTS matches the whole
+// alias group -- the canonical subject and every alias of it -- so facts
+// written before the alias existed are still found. Aliases never chain:
+// every alias points directly at a subject that is not itself an alias.
+type SubjectAliaser interface {
+	// AddAlias makes alias another name for canonical. If canonical is
+	// itself an alias, alias joins its group instead; aliases that pointed
+	// at alias are re-pointed the same way. Naming the current canonical
+	// subject of a group as the alias swaps the group's canonical subject.
+	AddAlias(ctx context.Context, alias, canonical string) (*SubjectAlias, error)
+	// RemoveAlias deletes an alias. Facts already rewritten keep their
+	// canonical subject.
+	RemoveAlias(ctx context.Context, alias string) error
+	// Aliases lists every alias, by canonical subject then alias.
+	Aliases(ctx context.Context) ([]SubjectAlias, error)
+	// RewriteAliasedFacts rewrites the subject of every fact filed under
+	// an alias to its canonical subject, in place, and records the change
+	// in the audit log. An empty alias rewrites every alias.
+	RewriteAliasedFacts(ctx context.Context, alias string) (*AliasRewriteResult, error)
+}
+
+// CheckAlias validates an alias pair before it reaches the store.
+func CheckAlias(alias, canonical string) error {
+	if strings.TrimSpace(alias) == "" || strings.TrimSpace(canonical) == "" {
+		return errors.New("memstore: alias and canonical subject are required")
+	}
+	if strings.EqualFold(alias, canonical) {
+		return fmt.Errorf("memstore: %q cannot be an alias of itself", alias)
+	}
+	return nil
+}
+
+// AliasMap resolves subjects to their canonical form in memory, for callers
+// that compare many subjects at once.
+type AliasMap map[string]string // lower-cased alias -> canonical subject
+
+// NewAliasMap builds an AliasMap from a store's aliases.
+func NewAliasMap(aliases []SubjectAlias) AliasMap {
+	m := make(AliasMap, len(aliases))
+	for _, a := range aliases {
+		m[strings.ToLower(a.Alias)] = a.Canonical
+	}
+	return m
+}
+
+// LoadAliases returns the store's aliases as an AliasMap. A store without
+// alias support, or one that fails to answer, yields an empty map: alias
+// resolution is a refinement, never a reason to fail a read.
+func LoadAliases(ctx context.Context, s Store) AliasMap {
+	sa, ok := s.(SubjectAliaser)
+	if !ok {
+		return nil
+	}
+	aliases, err := sa.Aliases(ctx)
+	if err != nil {
+		return nil
+	}
+	return NewAliasMap(aliases)
+}
+
+// Canonical returns subject's canonical subject, or subject itself when it
+// is not an alias. A nil map resolves nothing.
+func (m AliasMap) Canonical(subject string) string {
+	if c, ok := m[strings.ToLower(subject)]; ok {
+		return c
+	}
+	return subject
+}
+
+// Same reports whether two subjects name the same thing: equal, ignoring
+// case, once resolved.
+func (m AliasMap) Same(a, b string) bool {
+	return strings.EqualFold(m.Canonical(a), m.Canonical(b))
+}
+
+// ProjectFromCWD is ProjectNameFromCWD resolved through the store's subject
+// aliases, so a renamed checkout or a fork maps to the subject its project
+// memory is filed under.
+func ProjectFromCWD(ctx context.Context, s Store, cwd string) string {
+	return LoadAliases(ctx, s).Canonical(ProjectNameFromCWD(cwd))
+}
+
+// migrateV19 creates the subject alias table. alias matches
+// case-insensitively; canonical is kept as given.
+func (s *SQLiteStore) migrateV19() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_subject_aliases (
+			namespace  TEXT NOT NULL,
+			user_id    INTEGER NOT NULL,
+			alias      TEXT NOT NULL COLLATE NOCASE,
+			canonical  TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (namespace, user_id, alias)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_subject_aliases_canonical ON memstore_subject_aliases(namespace, user_id, canonical)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("memstore V19 migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// canonicalSubjectSQL resolves a subject through the alias table in SQL, so
+// writes pick up the canonical subject inside whatever statement or
+// transaction they are already in. Args: namespace, user ID, subject,
+// subject.
+const canonicalSubjectSQL = `COALESCE((SELECT canonical FROM memstore_subject_aliases WHERE namespace = ? AND user_id = ? AND alias = ?), ?)`
+
+// appendSubjectFilter matches col against subject's alias group: subject
+// itself, its canonical subject, and every alias of that canonical subject.
+func (s *SQLiteStore) appendSubjectFilter(q *string, args *[]any, col, subject string) {
+	*q += ` AND ` + col + ` IN (SELECT ? UNION SELECT canonical FROM memstore_subject_aliases WHERE namespace = ? AND user_id = ? AND alias = ?
+		UNION SELECT alias FROM memstore_subject_aliases WHERE namespace = ? AND user_id = ? AND canonical = ` + canonicalSubjectSQL + `)`
+	*args = append(*args, subject, s.namespace, s.userID, subject, s.namespace, s.userID, s.namespace, s.userID, subject, subject)
+}
+
+// AddAlias implements SubjectAliaser.
+func (s *SQLiteStore) AddAlias(ctx context.Context, alias, canonical string) (*SubjectAlias, error) {
+	if err := CheckAlias(alias, canonical); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	requested := canonical
+	if err := tx.QueryRowContext(ctx, `SELECT `+canonicalSubjectSQL,
+		s.namespace, s.userID, canonical, canonical).Scan(&canonical); err != nil {
+		return nil, fmt.Errorf("memstore: resolving subject %q: %w", canonical, err)
+	}
+	if strings.EqualFold(canonical, alias) {
+		// alias is the canonical subject of requested's group: requested
+		// takes its place and stops being an alias.
+		canonical = requested
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM memstore_subject_aliases WHERE namespace = ? AND user_id = ? AND alias = ?`,
+			s.namespace, s.userID, requested); err != nil {
+			return nil, fmt.Errorf("memstore: swapping canonical subject: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	stmts := []struct {
+		q    string
+		args []any
+	}{
+		{`UPDATE memstore_subject_aliases SET canonical = ? WHERE namespace = ? AND user_id = ? AND canonical = ? COLLATE NOCASE`,
+			[]any{canonical, s.namespace, s.userID, alias}},
+		{`INSERT INTO memstore_subject_aliases (namespace, user_id, alias, canonical, created_at) VALUES (?, ?, ?, ?, ?)
+		  ON CONFLICT (namespace, user_id, alias) DO UPDATE SET canonical = excluded.canonical`,
+			[]any{s.namespace, s.userID, alias, canonical, now.Format(time.RFC3339)}},
+	}
+	for _, st := range stmts {
+		if _, err := tx.ExecContext(ctx, st.q, st.args...); err != nil {
+			return nil, fmt.Errorf("memstore: adding alias %q: %w", alias, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing alias: %w", err)
+	}
+	return &SubjectAlias{Alias: alias, Canonical: canonical, CreatedAt: now}, nil
+}
+
+// RemoveAlias implements SubjectAliaser.
+func (s *SQLiteStore) RemoveAlias(ctx context.Context, alias string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM memstore_subject_aliases WHERE namespace = ? AND user_id = ? AND alias = ?`,
+		s.namespace, s.userID, alias)
+	if err != nil {
+		return fmt.Errorf("memstore: removing alias %q: %w", alias, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAliasNotFound
+	}
+	return nil
+}
+
+// Aliases implements SubjectAliaser.
+func (s *SQLiteStore) Aliases(ctx context.Context) ([]SubjectAlias, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT alias, canonical, created_at FROM memstore_subject_aliases
+		 WHERE namespace = ? AND user_id = ? ORDER BY canonical, alias`,
+		s.namespace, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: listing aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SubjectAlias
+	for rows.Next() {
+		var (
+			a       SubjectAlias
+			created string
+		)
+		if err := rows.Scan(&a.Alias, &a.Canonical, &created); err != nil {
+			return nil, fmt.Errorf("memstore: scanning alias: %w", err)
+		}
+		a.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// aliasRewriteDetail is the Detail recorded for an AuditAliasRewrite entry.
+type aliasRewriteDetail struct {
+	Alias    string            `json:"alias,omitempty"`
+	Subjects map[string]string `json:"subjects"`
+}
+
+// RewriteAliasedFacts implements SubjectAliaser.
+func (s *SQLiteStore) RewriteAliasedFacts(ctx context.Context, alias string) (*AliasRewriteResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := `SELECT f.id, f.subject, a.canonical FROM memstore_facts f
+	      JOIN memstore_subject_aliases a ON a.namespace = f.namespace AND a.user_id = ? AND a.alias = f.subject
+	      WHERE f.namespace = ? AND f.subject <> a.canonical`
+	args := []any{s.userID, s.namespace}
+	if alias != "" {
+		var n int
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM memstore_subject_aliases WHERE namespace = ? AND user_id = ? AND alias = ?`,
+			s.namespace, s.userID, alias).Scan(&n); err != nil {
+			return nil, fmt.Errorf("memstore: looking up alias %q: %w", alias, err)
+		}
+		if n == 0 {
+			return nil, ErrAliasNotFound
+		}
+		q += ` AND a.alias = ?`
+		args = append(args, alias)
+	}
+	rows, err := tx.QueryContext(ctx, q+` ORDER BY f.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: selecting aliased facts: %w", err)
+	}
+	var (
+		ids      []int64
+		subjects = map[string]string{}
+	)
+	for rows.Next() {
+		var (
+			id                 int64
+			subject, canonical string
+		)
+		if err := rows.Scan(&id, &subject, &canonical); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("memstore: scanning aliased fact: %w", err)
+		}
+		ids = append(ids, id)
+		subjects[subject] = canonical
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memstore: selecting aliased facts: %w", err)
+	}
+
+	res := &AliasRewriteResult{Facts: len(ids), Subjects: subjects}
+	if len(ids) == 0 {
+		return res, nil
+	}
+	for subject, canonical := range subjects {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE memstore_facts SET subject = ? WHERE namespace = ? AND subject = ?`,
+			canonical, s.namespace, subject); err != nil {
+			return nil, fmt.Errorf("memstore: rewriting subject %q: %w", subject, err)
+		}
+	}
+	if res.AuditID, err = s.recordAudit(ctx, tx, s.namespace, AuditAliasRewrite, aliasRewriteDetail{alias, subjects}, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing alias rewrite: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package memstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestSubjectAliases(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	before := insertTestFact(t, s, "the fork adds a redis cache", "memstore-fork")
+	insertTestFact(t, s, "memstore stores facts in sqlite", "memstore")
+
+	if _, err := s.AddAlias(ctx, "memstore-fork", "memstore"); err != nil {
+		t.Fatal(err)
+	}
+	after := insertTestFact(t, s, "the fork rewrote the cache layer", "Memstore-Fork")
+	if f, err := s.Get(ctx, after); err != nil || f.Subject != "memstore" {
+		t.Fatalf("fact written under an alias: subject %q, %v; want memstore", f.Subject, err)
+	}
+	// The duplicate check sees the fact under either name.
+	for _, subject := range []string{"memstore-fork", "MEMSTORE-FORK", "memstore"} {
+		if ok, err := s.Exists(ctx, "the fork rewrote the cache layer", subject); err != nil || !ok {
+			t.Errorf("Exists(subject=%s) = %v, %v; want true", subject, ok, err)
+		}
+	}
+
+	// Either name finds the whole group, including facts written before
+	// the alias existed.
+	for _, subject := range []string{"memstore", "memstore-fork"} {
+		facts, err := s.List(ctx, memstore.QueryOpts{Subject: subject})
+		if err != nil || len(facts) != 3 {
+			t.Errorf("List(subject=%s) = %d facts, %v; want 3", subject, len(facts), err)
+		}
+	}
+	hits, err := s.SearchFTS(ctx, "redis", memstore.SearchOpts{Subject: "memstore", MaxResults: 5})
+	if err != nil || len(hits) != 1 || hits[0].Fact.ID != before {
+		t.Errorf("SearchFTS(subject=memstore) = %+v, %v; want fact %d", hits, err, before)
+	}
+
+	// An alias of an alias joins the group instead of chaining.
+	a, err := s.AddAlias(ctx, "ms", "memstore-fork")
+	if err != nil || a.Canonical != "memstore" {
+		t.Fatalf("AddAlias(ms, memstore-fork) = %+v, %v; want canonical memstore", a, err)
+	}
+	if err := s.RemoveAlias(ctx, "nope"); !errors.Is(err, memstore.ErrAliasNotFound) {
+		t.Errorf("RemoveAlias(nope) = %v, want ErrAliasNotFound", err)
+	}
+
+	res, err := s.RewriteAliasedFacts(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Facts != 1 || res.Subjects["memstore-fork"] != "memstore" || res.AuditID == 0 {
+		t.Errorf("RewriteAliasedFacts = %+v, want 1 fact from memstore-fork", res)
+	}
+	if f, err := s.Get(ctx, before); err != nil || f.Subject != "memstore" {
+		t.Errorf("rewritten fact: subject %q, %v; want memstore", f.Subject, err)
+	}
+	log, err := s.AuditLog(ctx, 1)
+	if err != nil || len(log) != 1 || log[0].Action != memstore.AuditAliasRewrite {
+		t.Errorf("AuditLog = %+v, %v", log, err)
+	}
+	if res, err := s.RewriteAliasedFacts(ctx, "memstore-fork"); err != nil || res.Facts != 0 {
+		t.Errorf("second rewrite = %+v, %v; want nothing to do", res, err)
+	}
+}
+
+func TestSubjectAliases_Swap(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, alias := range []string{"oldname", "fork"} {
+		if _, err := s.AddAlias(ctx, alias, "project"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Making the canonical subject an alias of a group member promotes
+	// that member.
+	if _, err := s.AddAlias(ctx, "project", "oldname"); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := s.Aliases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := memstore.NewAliasMap(aliases)
+	if len(aliases) != 2 || m.Canonical("fork") != "oldname" || m.Canonical("PROJECT") != "oldname" {
+		t.Errorf("aliases after swap = %+v, want fork and project -> oldname", aliases)
+	}
+	if _, err := s.AddAlias(ctx, "x", "X"); err == nil {
+		t.Error("AddAlias(x, X) should be refused")
+	}
+}
+
+func TestSubjectAliases_BulkUpdate(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	id := insertTestFact(t, s, "a loose note", "misc")
+	if _, err := s.AddAlias(ctx, "infra", "ops"); err != nil {
+		t.Fatal(err)
+	}
+	infra := "infra"
+	if _, err := s.BulkUpdate(ctx, memstore.BulkUpdateRequest{
+		Filter: memstore.QueryOpts{Subject: "misc"},
+		Change: memstore.BulkChange{Subject: &infra},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := s.Get(ctx, id); err != nil || f.Subject != "ops" {
+		t.Errorf("bulk-updated fact: subject %q, %v; want ops", f.Subject, err)
+	}
+}
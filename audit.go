@@ -0,0 +1,123 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audit actions.
+const (
+	AuditBulkUpdate      = "bulk_update"      // BulkUpdate applied a change to many facts
+	AuditNamespaceRename = "namespace_rename" // RenameNamespace moved a namespace
+	AuditNamespaceCopy   = "namespace_copy"   // CopyNamespace copied a namespace
+	AuditNamespaceMerge  = "namespace_merge"  // MergeNamespace merged a namespace into another
+	AuditNamespaceDelete = "namespace_delete" // DeleteNamespace removed a namespace
+	AuditAliasRewrite    = "alias_rewrite"    // RewriteAliasedFacts moved facts to their canonical subject
+)
+
+// AuditEntry records one administrative change to the store -- the kind
+// that touches many facts at once and leaves nothing in their history to
+// explain it.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor,omitempty"`  // identity that made the change (Provenance.Origin); empty for local writes
+	Detail    json.RawMessage `json:"detail,omitempty"` // action-specific record, e.g. a bulk update's filter and change
+	FactIDs   []int64         `json:"fact_ids,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditReader is implemented by stores that keep an audit log. Both
+// built-in backends and the HTTP client implement it.
+type AuditReader interface {
+	// AuditLog returns the most recent audit entries, newest first.
+	// limit <= 0 means no limit.
+	AuditLog(ctx context.Context, limit int) ([]AuditEntry, error)
+}
+
+// migrateV18 creates the audit log.
+func (s *SQLiteStore) migrateV18() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_audit (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			namespace  TEXT NOT NULL,
+			action     TEXT NOT NULL,
+			actor      TEXT NOT NULL DEFAULT '',
+			detail     TEXT,
+			fact_ids   TEXT,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_audit_namespace ON memstore_audit(namespace, id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("memstore V18 migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordAudit writes an audit entry for namespace ns inside tx, attributing
+// it to the context's provenance origin, and returns its ID.
+func (s *SQLiteStore) recordAudit(ctx context.Context, tx *sql.Tx, ns, action string, detail any, factIDs []int64) (int64, error) {
+	d, err := json.Marshal(detail)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: encoding audit detail: %w", err)
+	}
+	ids, err := json.Marshal(factIDs)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: encoding audit fact IDs: %w", err)
+	}
+	p, _ := ProvenanceFromContext(ctx)
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO memstore_audit (namespace, action, actor, detail, fact_ids, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		ns, action, p.Origin, string(d), string(ids), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("memstore: recording %s audit entry: %w", action, err)
+	}
+	return res.LastInsertId()
+}
+
+// AuditLog implements AuditReader.
+func (s *SQLiteStore) AuditLog(ctx context.Context, limit int) ([]AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q := `SELECT id, action, actor, detail, fact_ids, created_at FROM memstore_audit WHERE namespace = ? ORDER BY id DESC`
+	args := []any{s.namespace}
+	if limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: reading audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var out []AuditEntry
+	for rows.Next() {
+		var (
+			e               AuditEntry
+			detail, factIDs sql.NullString
+			created         string
+		)
+		if err := rows.Scan(&e.ID, &e.Action, &e.Actor, &detail, &factIDs, &created); err != nil {
+			return nil, fmt.Errorf("memstore: scanning audit entry: %w", err)
+		}
+		if detail.Valid {
+			e.Detail = json.RawMessage(detail.String)
+		}
+		if factIDs.Valid {
+			if err := json.Unmarshal([]byte(factIDs.String), &e.FactIDs); err != nil {
+				return nil, fmt.Errorf("memstore: decoding audit entry %d: %w", e.ID, err)
+			}
+		}
+		e.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,251 @@
+package memstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrBulkPreviewStale is returned by BulkUpdate when the request's Token no
+// longer matches: facts entered or left the filter, or the change differs,
+// since the preview it came from.
+var ErrBulkPreviewStale = errors.New("memstore: the facts matching the filter changed since the preview; preview again")
+
+// BulkChange is the edit BulkUpdate applies to every matched fact. Nil
+// typed fields are left alone. Metadata is a patch with UpdateMetadata
+// semantics: keys with nil values are deleted.
+//
+// Unlike Revise, a bulk change edits the matched rows in place -- it
+// re-classifies facts rather than changing what they say, so no new
+// versions are written and supersession chains are untouched.
+type BulkChange struct {
+	Subject   *string        `json:"subject,omitempty"`
+	Category  *string        `json:"category,omitempty"`
+	Kind      *string        `json:"kind,omitempty"`
+	Subsystem *string        `json:"subsystem,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// IsZero reports whether c changes nothing.
+func (c BulkChange) IsZero() bool {
+	return c.Subject == nil && c.Category == nil && c.Kind == nil && c.Subsystem == nil && len(c.Metadata) == 0
+}
+
+// String renders c for display, e.g. `subject="ci" metadata.owner=<deleted>`.
+func (c BulkChange) String() string {
+	var parts []string
+	for _, f := range []struct {
+		name string
+		v    *string
+	}{{"subject", c.Subject}, {"category", c.Category}, {"kind", c.Kind}, {"subsystem", c.Subsystem}} {
+		if f.v != nil {
+			parts = append(parts, fmt.Sprintf("%s=%q", f.name, *f.v))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Metadata)) {
+		if v := c.Metadata[k]; v == nil {
+			parts = append(parts, "metadata."+k+"=<deleted>")
+		} else {
+			b, _ := json.Marshal(v)
+			parts = append(parts, "metadata."+k+"="+string(b))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// BulkUpdateRequest selects facts with a List filter and describes the
+// change to make to them.
+type BulkUpdateRequest struct {
+	Filter QueryOpts  `json:"filter"`
+	Change BulkChange `json:"change"`
+	DryRun bool       `json:"dry_run,omitempty"`
+	// Token, when set, must equal the Token of a preview: the apply is
+	// refused with ErrBulkPreviewStale if the matched set or the change
+	// differs from what was previewed.
+	Token string `json:"token,omitempty"`
+}
+
+// Validate checks that r describes a bounded, well-formed change. The filter
+// must constrain something -- a bulk update never silently means "every
+// fact" -- and may not carry a Limit or other namespaces.
+func (r BulkUpdateRequest) Validate() error {
+	f := r.Filter
+	if f.Limit != 0 {
+		return errors.New("memstore: bulk update takes no limit; narrow the filter instead")
+	}
+	if len(f.Namespaces) > 0 {
+		return errors.New("memstore: bulk update works within the store's own namespace")
+	}
+	if f.Subject == "" && f.Category == "" && f.Kind == "" && f.Subsystem == "" &&
+		len(f.MetadataFilters) == 0 && f.CreatedAfter == nil && f.CreatedBefore == nil &&
+		len(f.IDs) == 0 && f.Provenance == (ProvenanceFilter{}) {
+		return errors.New("memstore: bulk update needs a filter; it will not touch every fact")
+	}
+	c := r.Change
+	if c.IsZero() {
+		return errors.New("memstore: bulk update changes nothing")
+	}
+	if c.Subject != nil && strings.TrimSpace(*c.Subject) == "" {
+		return errors.New("memstore: bulk update cannot clear subject")
+	}
+	if c.Category != nil && strings.TrimSpace(*c.Category) == "" {
+		return errors.New("memstore: bulk update cannot clear category")
+	}
+	for k := range c.Metadata {
+		if !validMetadataKey(k) {
+			return fmt.Errorf("memstore: invalid metadata key %q", k)
+		}
+	}
+	return nil
+}
+
+// BulkResult reports what a bulk update matched and, unless it was a dry
+// run, changed.
+type BulkResult struct {
+	Matched int     `json:"matched"`
+	IDs     []int64 `json:"ids"`   // matched fact IDs, ascending
+	Token   string  `json:"token"` // pass back as BulkUpdateRequest.Token to apply exactly this preview
+	Applied bool    `json:"applied"`
+	AuditID int64   `json:"audit_id,omitempty"` // the audit entry recording the apply
+}
+
+// BulkToken fingerprints a bulk update: the matched IDs (ascending) and the
+// change. Equal tokens mean an apply will do exactly what was previewed.
+func BulkToken(ids []int64, change BulkChange) string {
+	b, _ := json.Marshal(struct {
+		IDs    []int64    `json:"ids"`
+		Change BulkChange `json:"change"`
+	}{ids, change})
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:8])
+}
+
+// BulkUpdater is implemented by stores that can re-classify facts in bulk.
+// Both built-in backends and the HTTP client implement it.
+type BulkUpdater interface {
+	// BulkUpdate applies req.Change to every fact req.Filter matches (as
+	// List would return them, superseded versions included unless
+	// OnlyActive is set) in one transaction, and records an AuditBulkUpdate
+	// entry. With req.DryRun nothing is written. A filter matching nothing
+	// is not an error; nothing is applied or audited.
+	BulkUpdate(ctx context.Context, req BulkUpdateRequest) (*BulkResult, error)
+}
+
+// bulkAuditDetail is the Detail recorded for an AuditBulkUpdate entry.
+type bulkAuditDetail struct {
+	Filter QueryOpts  `json:"filter"`
+	Change BulkChange `json:"change"`
+}
+
+// BulkUpdate implements BulkUpdater.
+func (s *SQLiteStore) BulkUpdate(ctx context.Context, req BulkUpdateRequest) (*BulkResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	where, args, err := s.listFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, kind, metadata FROM memstore_facts`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: selecting facts for bulk update: %w", err)
+	}
+	var (
+		ids   []int64
+		kinds []string
+		meta  []sql.NullString
+	)
+	for rows.Next() {
+		var id int64
+		var k string
+		var m sql.NullString
+		if err := rows.Scan(&id, &k, &m); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("memstore: scanning bulk update match: %w", err)
+		}
+		ids = append(ids, id)
+		kinds = append(kinds, k)
+		meta = append(meta, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memstore: selecting facts for bulk update: %w", err)
+	}
+
+	res := &BulkResult{Matched: len(ids), IDs: ids, Token: BulkToken(ids, req.Change)}
+	if res.IDs == nil {
+		res.IDs = []int64{}
+	}
+	if req.Token != "" && req.Token != res.Token {
+		return nil, ErrBulkPreviewStale
+	}
+	if req.DryRun || len(ids) == 0 {
+		return res, nil
+	}
+
+	c := req.Change
+	set, setArgs := "", []any{}
+	for _, f := range []struct {
+		col string
+		v   *string
+	}{{"subject", c.Subject}, {"category", c.Category}, {"kind", c.Kind}, {"subsystem", c.Subsystem}} {
+		if f.v != nil {
+			set += ", " + f.col + " = ?"
+			setArgs = append(setArgs, *f.v)
+		}
+	}
+	if c.Subject != nil {
+		// File under the canonical subject when the new one is an alias.
+		set = strings.Replace(set, "subject = ?", "subject = "+canonicalSubjectSQL, 1)
+		setArgs = append([]any{s.namespace, s.userID, *c.Subject}, setArgs...)
+	}
+	for i, id := range ids {
+		q, qArgs := set, append([]any(nil), setArgs...)
+		merged := []byte(meta[i].String)
+		if len(c.Metadata) > 0 {
+			if merged, err = mergeMetadata(merged, c.Metadata); err != nil {
+				return nil, fmt.Errorf("memstore: merging metadata for fact %d: %w", id, err)
+			}
+			if merged == nil {
+				merged = []byte("{}")
+			}
+			q += ", metadata = ?"
+			qArgs = append(qArgs, string(merged))
+		}
+		if err := s.schemas.ValidateBulkChange(id, kinds[i], merged, c); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE memstore_facts SET `+strings.TrimPrefix(q, ", ")+` WHERE id = ? AND namespace = ?`,
+			append(qArgs, id, s.namespace)...,
+		); err != nil {
+			return nil, fmt.Errorf("memstore: bulk updating fact %d: %w", id, err)
+		}
+	}
+
+	if res.AuditID, err = s.recordAudit(ctx, tx, s.namespace, AuditBulkUpdate, bulkAuditDetail{req.Filter, c}, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing bulk update: %w", err)
+	}
+	res.Applied = true
+	return res, nil
+}
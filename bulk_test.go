@@ -0,0 +1,137 @@
+package memstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestBulkUpdateRequestValidate(t *testing.T) {
+	ci, empty := "ci", " "
+	change := memstore.BulkChange{Subject: &ci}
+	tests := []struct {
+		name    string
+		req     memstore.BulkUpdateRequest
+		wantErr bool
+	}{
+		{"subject filter", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}, Change: change}, false},
+		{"metadata only", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Kind: "note"}, Change: memstore.BulkChange{Metadata: map[string]any{"owner": nil}}}, false},
+		{"no filter", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{OnlyActive: true}, Change: change}, true},
+		{"limit", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build", Limit: 10}, Change: change}, true},
+		{"other namespace", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build", Namespaces: []string{"x"}}, Change: change}, true},
+		{"no change", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}}, true},
+		{"blank subject", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}, Change: memstore.BulkChange{Subject: &empty}}, true},
+		{"bad metadata key", memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}, Change: memstore.BulkChange{Metadata: map[string]any{"a'b": 1}}}, true},
+	}
+	for _, tc := range tests {
+		if err := tc.req.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("%s: Validate() = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSQLiteBulkUpdate(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	a, err := s.Insert(ctx, memstore.Fact{Content: "ci runs on push", Subject: "build", Category: "note", Metadata: json.RawMessage(`{"owner":"ops","keep":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Insert(ctx, memstore.Fact{Content: "ci caches modules", Subject: "build", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.Insert(ctx, memstore.Fact{Content: "tabs, not spaces", Subject: "style", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ci, convention := "ci", "convention"
+	req := memstore.BulkUpdateRequest{
+		Filter: memstore.QueryOpts{Subject: "build"},
+		Change: memstore.BulkChange{Subject: &ci, Kind: &convention, Metadata: map[string]any{"owner": nil, "team": "infra"}},
+		DryRun: true,
+	}
+	preview, err := s.BulkUpdate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Applied || !slices.Equal(preview.IDs, []int64{a, b}) {
+		t.Fatalf("preview = %+v, want ids [%d %d] unapplied", preview, a, b)
+	}
+	if f, _ := s.Get(ctx, a); f.Subject != "build" {
+		t.Fatalf("dry run wrote: subject = %q", f.Subject)
+	}
+
+	// A fact joining the filter after the preview invalidates its token.
+	c, err := s.Insert(ctx, memstore.Fact{Content: "ci pins go", Subject: "build", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.DryRun, req.Token = false, preview.Token
+	if _, err := s.BulkUpdate(ctx, req); !errors.Is(err, memstore.ErrBulkPreviewStale) {
+		t.Fatalf("stale apply = %v, want ErrBulkPreviewStale", err)
+	}
+
+	req.DryRun, req.Token = true, ""
+	preview, err = s.BulkUpdate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.DryRun, req.Token = false, preview.Token
+	ctx = memstore.WithProvenance(ctx, memstore.Provenance{Origin: "alice"})
+	res, err := s.BulkUpdate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Applied || res.Matched != 3 || res.AuditID == 0 {
+		t.Fatalf("apply = %+v, want 3 applied with an audit entry", res)
+	}
+
+	f, err := s.Get(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Subject != "ci" || f.Kind != "convention" || f.Category != "note" {
+		t.Errorf("fact %d = %s/%s/%s, want ci/note/convention", a, f.Subject, f.Category, f.Kind)
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(f.Metadata, &meta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := meta["owner"]; ok || meta["team"] != "infra" || meta["keep"] == nil {
+		t.Errorf("fact %d metadata = %s, want owner deleted, team set, keep kept", a, f.Metadata)
+	}
+	if f, _ := s.Get(ctx, other); f.Subject != "style" || f.Kind == "convention" {
+		t.Errorf("unmatched fact changed: %+v", f)
+	}
+	if hits, err := s.Search(ctx, "caches", memstore.SearchOpts{Subject: "ci", MaxResults: 5}); err != nil || len(hits) != 1 {
+		t.Errorf("search under the new subject = %d hits, %v; want 1", len(hits), err)
+	}
+
+	log, err := s.AuditLog(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 1 {
+		t.Fatalf("audit log has %d entries, want 1", len(log))
+	}
+	e := log[0]
+	if e.ID != res.AuditID || e.Action != memstore.AuditBulkUpdate || e.Actor != "alice" || !slices.Equal(e.FactIDs, []int64{a, b, c}) {
+		t.Errorf("audit entry = %+v", e)
+	}
+
+	// Nothing matched: not an error, nothing applied or audited.
+	res, err = s.BulkUpdate(ctx, memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}, Change: req.Change})
+	if err != nil || res.Matched != 0 || res.Applied {
+		t.Fatalf("empty apply = %+v, %v", res, err)
+	}
+	if log, _ := s.AuditLog(ctx, 0); len(log) != 1 {
+		t.Errorf("empty apply was audited: %d entries", len(log))
+	}
+}
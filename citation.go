@@ -0,0 +1,169 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// Citation status values. A citation starts current; each re-ingest of the
+// cited document re-resolves it against the new chunk set.
+const (
+	CitationCurrent  = "current"  // the cited code is unchanged in the latest ingest
+	CitationChanged  = "changed"  // the cited symbol or span is still there but its content differs
+	CitationVanished = "vanished" // nothing in the latest ingest corresponds to the citation
+)
+
+// Citation ties a fact to the document-corpus chunk it was drawn from. The
+// location and hashes are a snapshot taken at citation time; re-ingest never
+// rewrites them, it only re-resolves ChunkID and Status, so a changed
+// citation still says what the fact was checked against. AcceptCitation
+// takes a new snapshot once someone has looked.
+type Citation struct {
+	ID            int64      `json:"id"`
+	FactID        int64      `json:"fact_id"`
+	DocumentID    int64      `json:"document_id,omitempty"` // 0 once the document has been deleted
+	ChunkID       int64      `json:"chunk_id,omitempty"`    // chunk the citation resolves to in the latest ingest; 0 when vanished
+	RepoURL       string     `json:"repo_url,omitempty"`
+	Commit        string     `json:"commit,omitempty"` // document commit at citation time
+	Path          string     `json:"path"`
+	Symbol        string     `json:"symbol,omitempty"`
+	Receiver      string     `json:"receiver,omitempty"`
+	LineStart     int        `json:"line_start"`
+	LineEnd       int        `json:"line_end"`
+	FileSHA256    []byte     `json:"file_sha256"`    // whole-file hash at citation time
+	ContentSHA256 []byte     `json:"content_sha256"` // cited chunk's content hash at citation time
+	Status        string     `json:"status"`         // CitationCurrent, CitationChanged or CitationVanished
+	CitedAt       time.Time  `json:"cited_at"`
+	StaleAt       *time.Time `json:"stale_at,omitempty"` // when the citation last stopped being current
+
+	// Current is the chunk ChunkID names, as of the latest ingest. Only
+	// Citations fills it in; nil when the citation has vanished.
+	Current *DocumentChunk `json:"current,omitempty"`
+}
+
+// Stale reports whether the cited code changed or vanished since the
+// citation was taken.
+func (c Citation) Stale() bool {
+	return c.Status == CitationChanged || c.Status == CitationVanished
+}
+
+// Location renders the cited span as "repo@commit path:Lx-y (symbol)", the
+// shape of DocumentSearchResult.Citation.
+func (c Citation) Location() string {
+	loc := fmt.Sprintf("%s:L%d-%d", c.Path, c.LineStart, c.LineEnd)
+	switch {
+	case c.RepoURL != "" && c.Commit != "":
+		loc = c.RepoURL + "@" + c.Commit + " " + loc
+	case c.RepoURL != "":
+		loc = c.RepoURL + " " + loc
+	}
+	if sym := c.Symbol; sym != "" {
+		if c.Receiver != "" {
+			sym = "(" + c.Receiver + ")." + sym
+		}
+		loc += " (" + sym + ")"
+	}
+	return loc
+}
+
+// ChunkContentHash is the content hash a citation records for a chunk.
+func ChunkContentHash(content string) []byte {
+	h := sha256.Sum256([]byte(content))
+	return h[:]
+}
+
+// NewCitation snapshots chunk, a chunk of doc, as a citation for factID.
+func NewCitation(factID int64, doc Document, chunk DocumentChunk) Citation {
+	return Citation{
+		FactID:        factID,
+		DocumentID:    doc.ID,
+		ChunkID:       chunk.ID,
+		RepoURL:       doc.RepoURL,
+		Commit:        doc.Commit,
+		Path:          doc.Path,
+		Symbol:        chunk.Symbol,
+		Receiver:      chunk.Receiver,
+		LineStart:     chunk.LineStart,
+		LineEnd:       chunk.LineEnd,
+		FileSHA256:    doc.FileSHA256,
+		ContentSHA256: ChunkContentHash(chunk.Content),
+		Status:        CitationCurrent,
+	}
+}
+
+// ResolveCitation finds the chunk that c refers to in a re-ingested chunk set
+// and reports the citation's new status. It returns the index into chunks,
+// or -1 when the citation has vanished.
+//
+// A chunk with the cited content is a match wherever it moved to, preferring
+// one with the cited symbol. Failing that, a code citation follows its symbol
+// (same name and receiver) and a prose citation the first chunk overlapping
+// its old line span; either is reported changed. Nothing left means
+// vanished.
+func ResolveCitation(c Citation, chunks []DocumentChunk) (int, string) {
+	sameSymbol := func(ch DocumentChunk) bool {
+		return c.Symbol != "" && ch.Symbol == c.Symbol && ch.Receiver == c.Receiver
+	}
+	exact := -1
+	for i, ch := range chunks {
+		if !bytes.Equal(ChunkContentHash(ch.Content), c.ContentSHA256) {
+			continue
+		}
+		if sameSymbol(ch) {
+			return i, CitationCurrent
+		}
+		if exact < 0 {
+			exact = i
+		}
+	}
+	if exact >= 0 {
+		return exact, CitationCurrent
+	}
+	for i, ch := range chunks {
+		if c.Symbol != "" {
+			if sameSymbol(ch) {
+				return i, CitationChanged
+			}
+			continue
+		}
+		if ch.LineStart <= c.LineEnd && ch.LineEnd >= c.LineStart {
+			return i, CitationChanged
+		}
+	}
+	return -1, CitationVanished
+}
+
+// Citer is implemented by stores that can cite document-corpus chunks from
+// facts. Postgres and the HTTP client implement it; the SQLite backend does
+// not carry the corpus.
+//
+// Citations are re-resolved whenever UpsertDocument replaces the cited
+// document, and marked vanished when DeleteDocuments removes it. A fact with
+// a stale citation is queued for review (see Reviewer) until each stale
+// citation is accepted or deleted.
+type Citer interface {
+	// CiteChunk records that fact factID was drawn from chunk chunkID. The
+	// fact and chunk must both be visible to the caller and belong to the
+	// same user.
+	CiteChunk(ctx context.Context, factID, chunkID int64) (*Citation, error)
+
+	// Citations returns a fact's citations, oldest first, with Current
+	// filled in.
+	Citations(ctx context.Context, factID int64) ([]Citation, error)
+
+	// StaleCitations returns changed and vanished citations on active facts,
+	// longest stale first. limit <= 0 means no limit.
+	StaleCitations(ctx context.Context, limit int) ([]Citation, error)
+
+	// AcceptCitation re-snapshots a changed citation from the chunk it now
+	// resolves to and marks it current: the fact was checked against the
+	// new code and still holds. A vanished citation cannot be accepted;
+	// delete it instead.
+	AcceptCitation(ctx context.Context, id int64) (*Citation, error)
+
+	// DeleteCitation removes a citation.
+	DeleteCitation(ctx context.Context, id int64) error
+}
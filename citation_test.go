@@ -0,0 +1,64 @@
+package memstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestResolveCitation(t *testing.T) {
+	doc := memstore.Document{ID: 1, RepoURL: "r", Path: "queue.go"}
+	fn := memstore.DocumentChunk{ID: 10, Symbol: "Drain", Receiver: "*Queue", Content: "func (q *Queue) Drain() {}", LineStart: 5, LineEnd: 5}
+	prose := memstore.DocumentChunk{ID: 11, Content: "Retries are capped.", LineStart: 20, LineEnd: 22}
+	code := memstore.NewCitation(7, doc, fn)
+	text := memstore.NewCitation(7, doc, prose)
+
+	for _, tc := range []struct {
+		name   string
+		c      memstore.Citation
+		chunks []memstore.DocumentChunk
+		want   int
+		status string
+	}{
+		{"unchanged and moved", code, []memstore.DocumentChunk{prose, {Symbol: "Drain", Receiver: "*Queue", Content: fn.Content, LineStart: 40}}, 1, memstore.CitationCurrent},
+		{"symbol body changed", code, []memstore.DocumentChunk{{Symbol: "Drain", Receiver: "*Queue", Content: "func (q *Queue) Drain() { q.wg.Wait() }"}}, 0, memstore.CitationChanged},
+		{"other receiver is not the symbol", code, []memstore.DocumentChunk{{Symbol: "Drain", Receiver: "*Pool", Content: "func (p *Pool) Drain() {}"}}, -1, memstore.CitationVanished},
+		{"prose rewritten in place", text, []memstore.DocumentChunk{{Content: "Retries are unlimited.", LineStart: 21, LineEnd: 21}}, 0, memstore.CitationChanged},
+		{"prose moved verbatim", text, []memstore.DocumentChunk{{Content: "intro", LineStart: 1, LineEnd: 1}, {Content: prose.Content, LineStart: 90, LineEnd: 92}}, 1, memstore.CitationCurrent},
+		{"prose gone", text, []memstore.DocumentChunk{{Content: "intro", LineStart: 1, LineEnd: 3}}, -1, memstore.CitationVanished},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			i, status := memstore.ResolveCitation(tc.c, tc.chunks)
+			if i != tc.want || status != tc.status {
+				t.Errorf("ResolveCitation = %d, %s; want %d, %s", i, status, tc.want, tc.status)
+			}
+		})
+	}
+}
+
+func TestCitationLocation(t *testing.T) {
+	c := memstore.Citation{RepoURL: "github.com/x/y", Commit: "abc", Path: "q.go", Symbol: "Drain", Receiver: "*Queue", LineStart: 5, LineEnd: 9}
+	if got, want := c.Location(), "github.com/x/y@abc q.go:L5-9 ((*Queue).Drain)"; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+	if got, want := (memstore.Citation{Path: "README.md", LineStart: 1, LineEnd: 2}).Location(), "README.md:L1-2"; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+}
+
+func TestRankReviewCited(t *testing.T) {
+	now := time.Now()
+	facts := []memstore.Fact{
+		{ID: 1, CreatedAt: now.Add(-120 * 24 * time.Hour)},
+		{ID: 2, CreatedAt: now.Add(-time.Hour)}, // young, but its code changed
+	}
+	items := memstore.RankReviewCited(context.Background(), facts, map[int64]int{2: 1}, nil, memstore.ReviewOpts{}, now)
+	if len(items) != 2 || items[0].Fact.ID != 2 || items[0].StaleCitations != 1 {
+		t.Fatalf("items = %+v; want the stale-cited fact first", items)
+	}
+	if r := items[0].Reasons; r[len(r)-1] != "cited code changed (1 citations)" {
+		t.Errorf("reasons = %q", r)
+	}
+}
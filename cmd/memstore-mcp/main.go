@@ -129,6 +129,40 @@ func main() {
 		if err != nil {
 			log.Fatalf("initializing store: %v", err)
 		}
+		if pol, err := memstore.QueryCachePolicyFromEnv("MEMSTORE_QUERY_CACHE_PERSIST"); err != nil {
+			log.Fatalf("memstore-mcp: %v", err)
+		} else {
+			sqlStore.SetQueryCache(pol)
+		}
+		if schemas, err := memstore.LoadSchemas(cfg.SchemasFile); err != nil {
+			log.Fatalf("memstore-mcp: %v", err)
+		} else {
+			sqlStore.SetSchemas(schemas)
+		}
+		// Expired facts are already hidden from active queries; the reaper
+		// archives (or deletes) them so the table doesn't accumulate them.
+		if pol, err := memstore.ExpiryPolicyFromEnv("MEMSTORE_EXPIRY"); err != nil {
+			log.Fatalf("memstore-mcp: %v", err)
+		} else if pol.Enabled() {
+			reaper := memstore.NewExpirySweeper(sqlStore, pol, log.Printf)
+			reaper.Start()
+			defer reaper.Stop()
+		}
+		// Deleted facts sit in the trash until they outlive the retention.
+		if pol, err := memstore.TrashPolicyFromEnv("MEMSTORE_TRASH"); err != nil {
+			log.Fatalf("memstore-mcp: %v", err)
+		} else if pol.Enabled() {
+			purger := memstore.NewTrashPurger(sqlStore, pol, log.Printf)
+			purger.Start()
+			defer purger.Stop()
+		}
+		// Surface recurring and scheduled tasks that came due while no
+		// session was running; the SessionStart hook re-checks each session.
+		if pol, err := memstore.ReminderPolicyFromEnv("MEMSTORE_REMINDERS"); err != nil {
+			log.Fatalf("memstore-mcp: %v", err)
+		} else if pol.Enabled() {
+			memstore.NewReminderEvaluator(sqlStore, pol, log.Printf).EvaluateOnce(context.Background())
+		}
 		if rr, rcfg, err := memstore.RerankerFromEnv("MEMSTORE_RERANK"); err != nil {
 			log.Fatalf("memstore-mcp: %v", err)
 		} else if rr != nil {
@@ -172,7 +206,17 @@ func main() {
 		srvCfg.SessionStore = rc // enables memory_rate_context
 	} else if *genModel != "" {
 		// Local mode: talk to Ollama directly.
-		srvCfg.Generator = memstore.NewOpenAIGenerator(*ollamaURL, *llmAPIKey, *genModel)
+		gen := memstore.NewOpenAIGenerator(*ollamaURL, *llmAPIKey, *genModel)
+		srvCfg.Generator = gen
+		// The contradiction audit costs an LLM call per candidate pair, so it
+		// runs only when explicitly enabled.
+		if pol, err := memstore.ContradictionPolicyFromEnv("MEMSTORE_CONTRADICTION_AUDIT"); err != nil {
+			log.Fatalf("memstore-mcp: %v", err)
+		} else if as, ok := store.(memstore.ContradictionAuditStore); ok && pol.Enabled {
+			auditor := memstore.NewContradictionAuditor(as, gen, pol, log.Printf)
+			auditor.Start()
+			defer auditor.Stop()
+		}
 	}
 
 	memorySrv := mcpserver.NewMemoryServerWithConfig(store, embedder, srvCfg)
@@ -41,6 +41,14 @@ func runAdmin(args []string) {
 		runRevokeToken(args[1:], os.Stdout)
 	case "rotate-token":
 		runRotateToken(args[1:], os.Stdout)
+	case "export-training":
+		runExportTraining(args[1:], os.Stdout)
+	case "list-experiments":
+		runListExperiments(args[1:], os.Stdout)
+	case "stop-experiment":
+		runStopExperiment(args[1:], os.Stdout)
+	case "namespace":
+		runAdminNamespace(args[1:], os.Stdout)
 	default:
 		fmt.Fprintf(os.Stderr, "admin: unknown subcommand %q\n", args[0])
 		printAdminUsage(os.Stderr)
@@ -60,6 +68,10 @@ Subcommands:
   list-tokens             List all active tokens (name, scopes, created, last used). Token values are not stored.
   revoke-token <name>     Revoke all active tokens with the given name.
   rotate-token <name>     Issue a new token preserving name + scopes; revoke the old one.
+  export-training         Write the retrieval logs as a JSONL training dataset (pointwise, pairwise, listwise).
+  list-experiments        List online ranking experiments with per-arm injection and feedback averages.
+  stop-experiment <name>  Stop a running experiment; daemons fall back to their own ranker within a minute.
+  namespace <subcommand>  List, rename, copy, merge or delete namespaces across all users (see memstore namespace).
 
 Flags may appear before or after the positional argument.
 
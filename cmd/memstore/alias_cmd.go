@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+const aliasUsage = `Usage: memstore alias <subcommand> [flags]
+
+Subcommands:
+  list                        List subject aliases.
+  add <alias> <canonical>     Make <alias> another name for <canonical>.
+  remove <alias>              Remove an alias.
+  rewrite [<alias>]           Rewrite facts filed under an alias (or every alias) to the canonical subject.
+  resolve <subject> | --cwd <dir>
+                              Print the canonical subject for a subject, or for the project at <dir>.`
+
+func runAlias(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, aliasUsage)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("alias "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	cwd := fs.String("cwd", "", "resolve: the working directory whose project to resolve")
+	positional, err := parseAdminArgs(fs, args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		// DB not initialized yet: no aliases, but resolve still answers so
+		// hooks can call it unconditionally.
+		if args[0] == "resolve" {
+			fmt.Println(resolveSubject(context.Background(), nil, positional, *cwd))
+		}
+		return
+	}
+	defer closeStore()
+
+	if err := aliasCommand(context.Background(), store, args[0], positional, *cwd, *format, os.Stdout); err != nil {
+		log.Fatalf("alias %s: %v", args[0], err)
+	}
+}
+
+// aliasCommand runs one alias subcommand against store and writes its
+// outcome to out.
+func aliasCommand(ctx context.Context, store memstore.Store, sub string, positional []string, cwd, format string, out io.Writer) error {
+	if sub == "resolve" {
+		if (cwd == "") == (len(positional) != 1) {
+			return fmt.Errorf("expected either a subject or --cwd\n\n%s", aliasUsage)
+		}
+		fmt.Fprintln(out, resolveSubject(ctx, store, positional, cwd))
+		return nil
+	}
+
+	wantArgs := map[string][2]int{"list": {0, 0}, "add": {2, 2}, "remove": {1, 1}, "rewrite": {0, 1}}
+	n, ok := wantArgs[sub]
+	if !ok {
+		return fmt.Errorf("unknown subcommand\n\n%s", aliasUsage)
+	}
+	if len(positional) < n[0] || len(positional) > n[1] {
+		return fmt.Errorf("wrong number of positional arguments\n\n%s", aliasUsage)
+	}
+	sa, ok := store.(memstore.SubjectAliaser)
+	if !ok {
+		return errors.New("this store does not support subject aliases")
+	}
+
+	switch sub {
+	case "list":
+		aliases, err := sa.Aliases(ctx)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			if aliases == nil {
+				aliases = []memstore.SubjectAlias{}
+			}
+			return writeJSON(out, aliases)
+		}
+		if len(aliases) == 0 {
+			fmt.Fprintln(out, "No subject aliases.")
+		}
+		for _, a := range aliases {
+			fmt.Fprintf(out, "%s -> %s\n", a.Alias, a.Canonical)
+		}
+	case "add":
+		a, err := sa.AddAlias(ctx, positional[0], positional[1])
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			return writeJSON(out, a)
+		}
+		fmt.Fprintf(out, "%q is now an alias of %q.\n", a.Alias, a.Canonical)
+	case "remove":
+		if err := sa.RemoveAlias(ctx, positional[0]); err != nil {
+			return err
+		}
+		if format != "json" {
+			fmt.Fprintf(out, "Removed alias %q.\n", positional[0])
+		}
+	case "rewrite":
+		var alias string
+		if len(positional) == 1 {
+			alias = positional[0]
+		}
+		res, err := sa.RewriteAliasedFacts(ctx, alias)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			return writeJSON(out, res)
+		}
+		if res.Facts == 0 {
+			fmt.Fprintln(out, "No facts are filed under an alias; nothing to rewrite.")
+			return nil
+		}
+		fmt.Fprintf(out, "Rewrote %d facts (audit entry %d).\n", res.Facts, res.AuditID)
+		aliases := make([]string, 0, len(res.Subjects))
+		for a := range res.Subjects {
+			aliases = append(aliases, a)
+		}
+		sort.Strings(aliases)
+		for _, a := range aliases {
+			fmt.Fprintf(out, "  %s -> %s\n", a, res.Subjects[a])
+		}
+	}
+	return nil
+}
+
+// resolveSubject returns the canonical subject for positional[0], or for the
+// project at cwd when cwd is set. A nil store resolves without aliases.
+func resolveSubject(ctx context.Context, store memstore.Store, positional []string, cwd string) string {
+	if cwd != "" {
+		return memstore.ProjectFromCWD(ctx, store, cwd)
+	}
+	if len(positional) == 0 {
+		return ""
+	}
+	return memstore.LoadAliases(ctx, store).Canonical(positional[0])
+}
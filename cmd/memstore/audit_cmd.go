@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runAudit(args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	limit := fs.Int("limit", 20, "max entries (0 = all)")
+	fs.Parse(args)
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing logged
+	}
+	defer closeStore()
+
+	ar, ok := store.(memstore.AuditReader)
+	if !ok {
+		log.Fatal("audit: this store does not keep an audit log")
+	}
+	entries, err := ar.AuditLog(context.Background(), *limit)
+	if err != nil {
+		log.Fatalf("audit: %v", err)
+	}
+
+	switch *format {
+	case "json":
+		if entries == nil {
+			entries = []memstore.AuditEntry{}
+		}
+		if err := writeJSON(os.Stdout, entries); err != nil {
+			log.Fatalf("audit: %v", err)
+		}
+	default:
+		if len(entries) == 0 {
+			fmt.Fprintln(os.Stderr, "The audit log is empty.")
+			return
+		}
+		for _, e := range entries {
+			writeAuditEntry(os.Stdout, e)
+		}
+	}
+}
+
+// writeAuditEntry writes one audit entry in a human-readable format.
+func writeAuditEntry(w io.Writer, e memstore.AuditEntry) {
+	fmt.Fprintf(w, "[%d] %s %s", e.ID, e.CreatedAt.Format("2006-01-02 15:04"), e.Action)
+	if e.Actor != "" {
+		fmt.Fprintf(w, " by %s", e.Actor)
+	}
+	fmt.Fprintf(w, " (%d facts)\n", len(e.FactIDs))
+	if len(e.Detail) > 0 {
+		fmt.Fprintf(w, "  %s\n", e.Detail)
+	}
+	fmt.Fprintln(w)
+}
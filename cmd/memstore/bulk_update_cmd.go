@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// bulkPreviewSample caps how many matched facts a preview prints.
+const bulkPreviewSample = 20
+
+func runBulkUpdate(args []string) {
+	fs := flag.NewFlagSet("bulk-update", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	subject := fs.String("subject", "", "match facts with this subject")
+	category := fs.String("category", "", "match facts with this category")
+	kind := fs.String("kind", "", "match facts with this kind")
+	subsystem := fs.String("subsystem", "", "match facts with this subsystem")
+	metadataStr := fs.String("metadata", "", `JSON object of equality filters (e.g. '{"surface":"startup"}')`)
+	source := fs.String("source", "", "match by provenance source: manual|extraction|import|summary")
+	session := fs.String("session", "", "match facts that originated in this session")
+	origin := fs.String("origin", "", "match by the identity that wrote the fact")
+	onlyActive := fs.Bool("active", true, "exclude superseded and expired facts")
+	setSubject := fs.String("set-subject", "", "new subject")
+	setCategory := fs.String("set-category", "", "new category")
+	setKind := fs.String("set-kind", "", "new kind (pass an empty value to clear)")
+	setSubsystem := fs.String("set-subsystem", "", "new subsystem (pass an empty value to clear)")
+	setMetadata := fs.String("set-metadata", "", `JSON metadata patch; null values delete keys (e.g. '{"owner":null}')`)
+	apply := fs.Bool("apply", false, "apply the change (default: preview only)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore bulk-update [filter flags] [--set-* flags] [--apply]")
+		fmt.Fprintln(os.Stderr, "Re-classifies every fact matching the filter in one transaction and records it in the audit log.")
+		fmt.Fprintln(os.Stderr, "Without --apply, only reports what would change.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	req := memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{
+		Subject:    *subject,
+		Category:   *category,
+		Kind:       *kind,
+		Subsystem:  *subsystem,
+		OnlyActive: *onlyActive,
+		Provenance: memstore.ProvenanceFilter{Source: *source, SessionID: *session, Origin: *origin},
+	}}
+	if *metadataStr != "" {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(*metadataStr), &m); err != nil {
+			log.Fatalf("bulk-update: invalid --metadata JSON: %v", err)
+		}
+		for k, v := range m {
+			req.Filter.MetadataFilters = append(req.Filter.MetadataFilters, memstore.MetadataFilter{Key: k, Op: "=", Value: v})
+		}
+	}
+	// A --set-* flag given, even empty, is part of the change; one left off
+	// leaves the field alone.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "set-subject":
+			req.Change.Subject = setSubject
+		case "set-category":
+			req.Change.Category = setCategory
+		case "set-kind":
+			req.Change.Kind = setKind
+		case "set-subsystem":
+			req.Change.Subsystem = setSubsystem
+		}
+	})
+	if *setMetadata != "" {
+		if err := json.Unmarshal([]byte(*setMetadata), &req.Change.Metadata); err != nil {
+			log.Fatalf("bulk-update: invalid --set-metadata JSON: %v", err)
+		}
+	}
+	if err := req.Validate(); err != nil {
+		log.Fatalf("bulk-update: %v", err)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing to update
+	}
+	defer closeStore()
+
+	res, err := bulkUpdate(context.Background(), store, req, *apply, os.Stdout, *format == "json")
+	if err != nil {
+		log.Fatalf("bulk-update: %v", err)
+	}
+	if *format == "json" {
+		if err := writeJSON(os.Stdout, res); err != nil {
+			log.Fatalf("bulk-update: %v", err)
+		}
+	}
+}
+
+// bulkUpdate previews req and, with apply, applies exactly the previewed set:
+// the preview's token rides along, so facts entering or leaving the filter in
+// between fail the apply instead of widening it. Unless quiet, the preview
+// and outcome are written to w as text.
+func bulkUpdate(ctx context.Context, store memstore.Store, req memstore.BulkUpdateRequest, apply bool, w io.Writer, quiet bool) (*memstore.BulkResult, error) {
+	bu, ok := store.(memstore.BulkUpdater)
+	if !ok {
+		return nil, fmt.Errorf("this store does not support bulk updates")
+	}
+	req.DryRun = true
+	res, err := bu.BulkUpdate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !quiet {
+		if err := writeBulkPreview(ctx, w, store, req.Change, res); err != nil {
+			return nil, err
+		}
+	}
+	if !apply || res.Matched == 0 {
+		if !quiet && res.Matched > 0 {
+			fmt.Fprintln(w, "Nothing changed; rerun with --apply to apply.")
+		}
+		return res, nil
+	}
+
+	req.DryRun, req.Token = false, res.Token
+	if res, err = bu.BulkUpdate(ctx, req); err != nil {
+		return nil, err
+	}
+	if !quiet {
+		fmt.Fprintf(w, "Updated %d facts (audit entry %d).\n", res.Matched, res.AuditID)
+	}
+	return res, nil
+}
+
+// writeBulkPreview writes the change and a sample of the matched facts.
+func writeBulkPreview(ctx context.Context, w io.Writer, store memstore.Store, change memstore.BulkChange, res *memstore.BulkResult) error {
+	if res.Matched == 0 {
+		fmt.Fprintln(w, "No facts match.")
+		return nil
+	}
+	fmt.Fprintf(w, "%d facts match; each gets %s.\n\n", res.Matched, change)
+	facts, err := store.List(ctx, memstore.QueryOpts{IDs: res.IDs[:min(len(res.IDs), bulkPreviewSample)]})
+	if err != nil {
+		return err
+	}
+	writeFactsText(w, facts)
+	if n := res.Matched - len(facts); n > 0 {
+		fmt.Fprintf(w, "... and %d more\n\n", n)
+	}
+	return nil
+}
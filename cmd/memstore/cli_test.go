@@ -1,11 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/matthewjhunter/memstore"
 	_ "modernc.org/sqlite"
@@ -167,3 +173,515 @@ func TestRunStore_integration(t *testing.T) {
 		t.Errorf("expected subject %q, got %q", "test-subject", fact.Subject)
 	}
 }
+
+func TestEditText(t *testing.T) {
+	got, err := editText("sed -i s/1.24/1.25/", "Go version is 1.24")
+	if err != nil {
+		t.Fatalf("editText: %v", err)
+	}
+	if got != "Go version is 1.25" {
+		t.Errorf("editText = %q, want the edited content without the trailing newline", got)
+	}
+
+	if _, err := editText("false", "unchanged"); err == nil {
+		t.Error("expected an error when the editor exits non-zero")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := editorCommand(); got != "vi" {
+		t.Errorf("editorCommand() = %q, want vi fallback", got)
+	}
+	t.Setenv("EDITOR", "nano")
+	if got := editorCommand(); got != "nano" {
+		t.Errorf("editorCommand() = %q, want $EDITOR", got)
+	}
+	t.Setenv("VISUAL", "code --wait")
+	if got := editorCommand(); got != "code --wait" {
+		t.Errorf("editorCommand() = %q, want $VISUAL to win", got)
+	}
+}
+
+func TestApplyDedupe(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+
+	for _, f := range []memstore.Fact{
+		{Content: "the staging cluster runs postgres sixteen", Subject: "infra", Category: "note"},
+		{Content: "the staging cluster runs postgres sixteen", Subject: "ops", Category: "note"},
+		{Content: "deploys go out every tuesday morning", Subject: "release", Category: "note"},
+		{Content: "deploys go out every tuesday morning", Subject: "deploy", Category: "note"},
+	} {
+		if _, err := store.Insert(ctx, f); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	clusters, err := store.(memstore.Deduper).FindDuplicates(ctx, memstore.DedupeOpts{})
+	if err != nil || len(clusters) != 2 {
+		t.Fatalf("FindDuplicates = %d clusters, %v; want 2", len(clusters), err)
+	}
+	superseded, merged := clusters[0], clusters[1]
+
+	// Supersede the first cluster; merge the second, keeping only the first
+	// line the editor is handed.
+	t.Setenv("VISUAL", "sed -i 1!d")
+	var out bytes.Buffer
+	if err := applyDedupe(ctx, store, clusters, bufio.NewReader(strings.NewReader("s\nm\n")), &out, false); err != nil {
+		t.Fatalf("applyDedupe: %v\n%s", err, out.String())
+	}
+
+	keeper := superseded.Facts[0].ID
+	if f, _ := store.Get(ctx, superseded.Facts[1].ID); f == nil || f.SupersededBy == nil || *f.SupersededBy != keeper {
+		t.Errorf("older fact = %+v, want superseded by %d", f, keeper)
+	}
+	active, _ := store.List(ctx, memstore.QueryOpts{OnlyActive: true})
+	if len(active) != 2 {
+		t.Fatalf("active facts = %+v, want the keeper and the merged fact", active)
+	}
+	for _, f := range active {
+		if f.ID != keeper && f.Content != merged.Facts[0].Content {
+			t.Errorf("merged fact content = %q, want %q", f.Content, merged.Facts[0].Content)
+		}
+	}
+	if !strings.Contains(out.String(), "Superseded 1 facts") || !strings.Contains(out.String(), "Merged 2 facts") {
+		t.Errorf("unexpected prompt output:\n%s", out.String())
+	}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+
+	var ids []int64
+	for _, f := range []memstore.Fact{
+		{Content: "ci runs on push", Subject: "build", Category: "note"},
+		{Content: "ci caches modules", Subject: "build", Category: "note"},
+		{Content: "tabs, not spaces", Subject: "style", Category: "note"},
+	} {
+		id, err := store.Insert(ctx, f)
+		if err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	ci := "ci"
+	req := memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}, Change: memstore.BulkChange{Subject: &ci}}
+	var out bytes.Buffer
+	res, err := bulkUpdate(ctx, store, req, false, &out, false)
+	if err != nil {
+		t.Fatalf("bulkUpdate preview: %v", err)
+	}
+	if res.Applied || res.Matched != 2 || !strings.Contains(out.String(), "ci caches modules") || !strings.Contains(out.String(), "--apply") {
+		t.Fatalf("preview = %+v\n%s", res, out.String())
+	}
+	if f, _ := store.Get(ctx, ids[0]); f.Subject != "build" {
+		t.Fatalf("preview wrote: subject = %q", f.Subject)
+	}
+
+	out.Reset()
+	if res, err = bulkUpdate(ctx, store, req, true, &out, false); err != nil {
+		t.Fatalf("bulkUpdate apply: %v", err)
+	}
+	if !res.Applied || !strings.Contains(out.String(), "Updated 2 facts") {
+		t.Fatalf("apply = %+v\n%s", res, out.String())
+	}
+	if f, _ := store.Get(ctx, ids[1]); f.Subject != "ci" {
+		t.Errorf("fact %d subject = %q, want ci", ids[1], f.Subject)
+	}
+
+	entries, err := store.(memstore.AuditReader).AuditLog(ctx, 0)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("AuditLog = %+v, %v", entries, err)
+	}
+	out.Reset()
+	writeAuditEntry(&out, entries[0])
+	if !strings.Contains(out.String(), "bulk_update (2 facts)") || !strings.Contains(out.String(), `"subject":"build"`) {
+		t.Errorf("audit entry text:\n%s", out.String())
+	}
+}
+
+func TestApplyReview(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	var ids []int64
+	for _, content := range []string{"the CI runner is named gort", "builds use make", "deploys go out on fridays"} {
+		id, err := store.Insert(ctx, memstore.Fact{Content: content, Subject: "ci", Category: "project", CreatedAt: old})
+		if err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	items, err := store.(memstore.Reviewer).ReviewQueue(ctx, memstore.ReviewOpts{})
+	if err != nil || len(items) != 3 {
+		t.Fatalf("ReviewQueue = %+v, %v; want 3 items", items, err)
+	}
+	// Equal priorities come oldest first, so the items are in insertion order.
+	t.Setenv("VISUAL", "sed -i s/make/bazel/")
+	var out bytes.Buffer
+	if err := applyReview(ctx, store, items, bufio.NewReader(strings.NewReader("c\ns\nd\n")), &out); err != nil {
+		t.Fatalf("applyReview: %v\n%s", err, out.String())
+	}
+
+	if f, _ := store.Get(ctx, ids[0]); f == nil || f.ConfirmedCount != 1 {
+		t.Errorf("confirmed fact = %+v, want confirmed once", f)
+	}
+	f, _ := store.Get(ctx, ids[1])
+	if f == nil || f.SupersededBy == nil {
+		t.Fatalf("superseded fact = %+v, want a new version", f)
+	}
+	if nf, _ := store.Get(ctx, *f.SupersededBy); nf == nil || nf.Content != "builds use bazel" {
+		t.Errorf("new version = %+v, want the edited content", nf)
+	}
+	if f, _ := store.Get(ctx, ids[2]); f != nil {
+		t.Errorf("deleted fact still visible: %+v", f)
+	}
+	if items, _ := store.(memstore.Reviewer).ReviewQueue(ctx, memstore.ReviewOpts{}); len(items) != 0 {
+		t.Errorf("queue after review = %+v, want empty", items)
+	}
+}
+
+func TestWriteContradiction(t *testing.T) {
+	var buf bytes.Buffer
+	writeContradiction(&buf, memstore.Contradiction{
+		LinkID: 7,
+		Reason: "capped at 3 vs unlimited",
+		A:      memstore.Fact{ID: 1, Subject: "retries", Category: "project", Content: "retries are capped at 3"},
+		B:      memstore.Fact{ID: 2, Subject: "retries", Category: "project", Content: "retries are unlimited"},
+	})
+	out := buf.String()
+	for _, want := range []string{"[link=7] capped at 3 vs unlimited", "[id=1] retries", "retries are capped at 3", "[id=2] retries", "retries are unlimited"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteHistoryEntry(t *testing.T) {
+	next := int64(4)
+	var buf bytes.Buffer
+	writeHistoryEntry(&buf, memstore.HistoryEntry{
+		Position:    0,
+		ChainLength: 2,
+		Fact: memstore.Fact{ID: 3, Subject: "ci", Category: "project", Content: "builds use make", SupersededBy: &next,
+			Provenance: memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "sess-1", Origin: "laptop"}},
+	})
+	writeHistoryEntry(&buf, memstore.HistoryEntry{Position: 1, ChainLength: 2, Fact: memstore.Fact{ID: 4, Subject: "ci", Content: "legacy"}})
+	out := buf.String()
+	for _, want := range []string{
+		"[1/2] id=3 | ci | project | superseded by 4",
+		"provenance: extraction session=sess-1 origin=laptop",
+		"[2/2] id=4 | ci |  | active",
+		"provenance: unknown",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNamespaceCommand(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	if _, err := store.Insert(ctx, memstore.Fact{Content: "ci runs on push", Subject: "build", Category: "note"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	na := store.(memstore.NamespaceAdmin)
+
+	var out bytes.Buffer
+	if err := namespaceCommand(ctx, na, "copy", []string{"test", "backup"}, "", false, "text", &out); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if !strings.Contains(out.String(), `Copied 1 facts and 0 links from "test" to "backup"`) {
+		t.Errorf("copy output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := namespaceCommand(ctx, na, "list", nil, "", false, "text", &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "backup") || !strings.Contains(out.String(), "test") {
+		t.Errorf("list output = %q", out.String())
+	}
+
+	if err := namespaceCommand(ctx, na, "delete", []string{"backup"}, "", false, "text", &out); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("delete without --yes = %v, want a confirmation error", err)
+	}
+	if err := namespaceCommand(ctx, na, "rename", []string{"test"}, "", false, "text", &out); err == nil {
+		t.Error("rename with one argument succeeded")
+	}
+	out.Reset()
+	if err := namespaceCommand(ctx, na, "delete", []string{"backup"}, "", true, "text", &out); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if infos, err := na.Namespaces(ctx); err != nil || len(infos) != 1 || infos[0].Namespace != "test" {
+		t.Errorf("Namespaces after delete = %+v, %v", infos, err)
+	}
+}
+
+func TestAliasCommand(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	id, err := store.Insert(ctx, memstore.Fact{Content: "the fork adds a redis cache", Subject: "memstore-fork", Category: "note"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := aliasCommand(ctx, store, "add", []string{"memstore-fork", "memstore"}, "", "text", &out); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	out.Reset()
+	if err := aliasCommand(ctx, store, "list", nil, "", "text", &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "memstore-fork -> memstore") {
+		t.Errorf("list output = %q", out.String())
+	}
+
+	repo := filepath.Join(t.TempDir(), "memstore-fork")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := aliasCommand(ctx, store, "resolve", nil, repo, "text", &out); err != nil {
+		t.Fatalf("resolve --cwd: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "memstore" {
+		t.Errorf("resolve --cwd = %q, want memstore", got)
+	}
+	if err := aliasCommand(ctx, store, "resolve", []string{"x"}, repo, "text", &out); err == nil {
+		t.Error("resolve with both a subject and --cwd succeeded")
+	}
+
+	out.Reset()
+	if err := aliasCommand(ctx, store, "rewrite", nil, "", "text", &out); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if !strings.Contains(out.String(), "Rewrote 1 facts") {
+		t.Errorf("rewrite output = %q", out.String())
+	}
+	if f, _ := store.Get(ctx, id); f.Subject != "memstore" {
+		t.Errorf("rewritten fact subject = %q, want memstore", f.Subject)
+	}
+	if err := aliasCommand(ctx, store, "remove", []string{"nope"}, "", "text", &out); !errors.Is(err, memstore.ErrAliasNotFound) {
+		t.Errorf("remove nope = %v, want ErrAliasNotFound", err)
+	}
+}
+
+func TestPinCommand(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	id, err := store.Insert(ctx, memstore.Fact{Content: "never commit generated protobufs", Subject: "memstore", Category: "project"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	var out bytes.Buffer
+	idArg := []string{strconv.FormatInt(id, 10)}
+	if err := pinCommand(ctx, store, "add", idArg, memstore.Pin{Subject: "memstore"}, "", "text", &out); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := pinCommand(ctx, store, "add", idArg, memstore.Pin{}, "", "text", &out); err == nil {
+		t.Error("add without --subject or --path should fail")
+	}
+
+	out.Reset()
+	if err := pinCommand(ctx, store, "list", nil, memstore.Pin{}, "/src/memstore", "text", &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "never commit generated protobufs") {
+		t.Errorf("list --cwd output = %q", out.String())
+	}
+	out.Reset()
+	if err := pinCommand(ctx, store, "list", nil, memstore.Pin{}, "/src/herald", "text", &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "No pinned facts.") {
+		t.Errorf("list for another project = %q", out.String())
+	}
+
+	if err := pinCommand(ctx, store, "remove", idArg, memstore.Pin{}, "", "text", &out); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := pinCommand(ctx, store, "remove", idArg, memstore.Pin{}, "", "text", &out); !errors.Is(err, memstore.ErrPinNotFound) {
+		t.Errorf("second remove = %v, want ErrPinNotFound", err)
+	}
+}
+
+func TestTagCommand(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	id, err := store.Insert(ctx, memstore.Fact{Content: "the feed poller backs off exponentially", Subject: "herald", Category: "project"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	idArg := strconv.FormatInt(id, 10)
+
+	var out bytes.Buffer
+	if err := tagCommand(ctx, store, "add", []string{idArg, "Perf", "todo"}, "", "text", &out); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if !strings.Contains(out.String(), "perf, todo") {
+		t.Errorf("add output = %q", out.String())
+	}
+	if err := tagCommand(ctx, store, "add", []string{idArg}, "", "text", &out); err == nil {
+		t.Error("add without a tag should fail")
+	}
+	if err := tagCommand(ctx, store, "remove", []string{idArg, "todo"}, "", "text", &out); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := tagCommand(ctx, store, "rename", []string{"perf", "performance"}, "", "text", &out); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	out.Reset()
+	if err := tagCommand(ctx, store, "list", nil, "herald", "text", &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "performance") || strings.Contains(out.String(), "todo") {
+		t.Errorf("list output = %q, want only performance", out.String())
+	}
+
+	out.Reset()
+	if err := tagCommand(ctx, store, "delete", []string{"performance"}, "", "text", &out); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !strings.Contains(out.String(), "from 1 facts") {
+		t.Errorf("delete output = %q", out.String())
+	}
+	if err := tagCommand(ctx, store, "bogus", nil, "", "text", &out); err == nil {
+		t.Error("unknown subcommand should fail")
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	schemas := memstore.DefaultSchemas()
+
+	// A trigger stored before schemas were enforced, missing its pattern.
+	store.(*memstore.SQLiteStore).SetSchemas(nil)
+	bad, err := store.Insert(ctx, memstore.Fact{Content: "load api rules", Subject: "global", Category: "project", Kind: "trigger",
+		Metadata: json.RawMessage(`{"signal_type":"file_pattern","load_subsystem":"api"}`)})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := schemaCommand(ctx, nil, schemas, "list", nil, "", "text", &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "failure_mode\n") || !strings.Contains(out.String(), "trigger\n") {
+		t.Errorf("list output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := schemaCommand(ctx, nil, schemas, "show", []string{"trigger"}, "", "text", &out); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if !strings.Contains(out.String(), `"signal_type": {`) {
+		t.Errorf("show output = %q", out.String())
+	}
+	if err := schemaCommand(ctx, nil, schemas, "show", []string{"runbook"}, "", "text", &out); err == nil {
+		t.Error("show for a kind without a schema should fail")
+	}
+
+	out.Reset()
+	if err := schemaCommand(ctx, store, schemas, "audit", nil, "", "json", &out); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	var violations []memstore.SchemaViolation
+	if err := json.Unmarshal(out.Bytes(), &violations); err != nil {
+		t.Fatalf("audit json: %v", err)
+	}
+	if len(violations) != 1 || violations[0].FactID != bad || !strings.Contains(violations[0].Reason, "signal") {
+		t.Errorf("audit = %+v, want the trigger missing its signal", violations)
+	}
+
+	out.Reset()
+	if err := schemaCommand(ctx, store, schemas, "audit", nil, "task", "text", &out); err != nil {
+		t.Fatalf("audit --kind task: %v", err)
+	}
+	if !strings.Contains(out.String(), "Every fact matches") {
+		t.Errorf("audit --kind task output = %q", out.String())
+	}
+}
+
+func TestTasksCommand_Board(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	insert := func(content, meta string) int64 {
+		t.Helper()
+		id, err := store.Insert(ctx, memstore.Fact{Content: content, Subject: "todo", Category: "note", Kind: "task", Metadata: json.RawMessage(meta)})
+		if err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+		return id
+	}
+	schema := insert("write the schema", `{"kind":"task","status":"pending","priority":"high"}`)
+	api := insert("build the API", `{"kind":"task","status":"pending","owner":"ana","due":"2000-01-01"}`)
+	insert("old cleanup", `{"kind":"task","status":"completed"}`)
+	if err := memstore.AddTaskDependency(ctx, store, api, schema); err != nil {
+		t.Fatalf("AddTaskDependency: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := tasksCommand(ctx, store, nil, "", "state", "text", &out); err != nil {
+		t.Fatalf("board: %v", err)
+	}
+	board := out.String()
+	for _, want := range []string{"BLOCKED (1)", "OVERDUE", "blocked by " + strconv.FormatInt(schema, 10), "READY (1)", "DONE (1)"} {
+		if !strings.Contains(board, want) {
+			t.Errorf("board missing %q:\n%s", want, board)
+		}
+	}
+
+	out.Reset()
+	if err := tasksCommand(ctx, store, nil, "ready", "", "text", &out); err != nil {
+		t.Fatalf("ready: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "write the schema") || strings.Contains(got, "build the API") {
+		t.Errorf("ready tasks = %q, want only the schema task", got)
+	}
+	if err := tasksCommand(ctx, store, nil, "stuck", "", "text", &out); err == nil {
+		t.Error("unknown state should fail")
+	}
+}
+
+func TestRemindCommand(t *testing.T) {
+	ctx := t.Context()
+	store := openInMemStore(t)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	meta := `{"kind":"task","status":"pending","due":"2099-01-01","recur":"weekly","remind_at":"` + past + `"}`
+	if _, err := store.Insert(ctx, memstore.Fact{Content: "re-run backfill-feedback", Subject: "todo", Category: "note", Kind: "task", Metadata: json.RawMessage(meta)}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := remindCommand(ctx, store, "text", &out); err != nil {
+		t.Fatalf("remind: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "[MEMSTORE - Reminders]") || !strings.Contains(got, "re-run backfill-feedback (due 2099-01-01, repeats weekly") {
+		t.Errorf("remind output = %q", got)
+	}
+
+	// Surfaced once: the task now shows in the startup list, and a second
+	// run has nothing new to say.
+	out.Reset()
+	startup := []memstore.MetadataFilter{{Key: "surface", Op: "=", Value: "startup"}}
+	if err := tasksCommand(ctx, store, startup, "", "", "text", &out); err != nil {
+		t.Fatalf("tasks: %v", err)
+	}
+	if !strings.Contains(out.String(), "re-run backfill-feedback") {
+		t.Errorf("startup tasks = %q, want the surfaced reminder", out.String())
+	}
+	out.Reset()
+	if err := remindCommand(ctx, store, "text", &out); err != nil || out.Len() != 0 {
+		t.Errorf("second remind = %q, %v; want no output", out.String(), err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runContradictions(args []string) {
+	fs := flag.NewFlagSet("contradictions", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	limit := fs.Int("limit", 0, "max queue entries to show (0 = all)")
+	format := fs.String("format", "text", "output format: text|json")
+	dismiss := fs.String("dismiss", "", "comma-separated link IDs to dismiss as not real conflicts")
+	audit := fs.Bool("audit", false, "run one audit pass with the configured LLM before listing (local store only)")
+	batch := fs.Int("batch", memstore.DefaultContradictionBatch, "with --audit, max facts to audit in this pass")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore contradictions [flags]")
+		fmt.Fprintln(os.Stderr, "Lists pairs of active facts the contradiction audit judged to conflict, oldest first.")
+		fmt.Fprintln(os.Stderr, "Resolve one with memstore supersede/edit/delete, or --dismiss it if it is not a real conflict.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing to review
+	}
+	defer closeStore()
+	ctx := context.Background()
+
+	if *audit {
+		as, ok := store.(memstore.ContradictionAuditStore)
+		if !ok {
+			log.Fatal("contradictions: --audit needs a local store; the daemon runs the audit itself when MEMSTORE_CONTRADICTION_AUDIT_ENABLED is set")
+		}
+		gen, err := cliGenerator()
+		if err != nil {
+			log.Fatalf("contradictions: %v", err)
+		}
+		jgen, ok := gen.(memstore.JSONSchemaGenerator)
+		if !ok {
+			log.Fatal("contradictions: the configured generator does not support structured output")
+		}
+		auditor := memstore.NewContradictionAuditor(as, jgen, memstore.ContradictionPolicy{Enabled: true, Batch: *batch}, nil)
+		res, err := auditor.AuditOnce(ctx)
+		if err != nil {
+			log.Fatalf("contradictions: audit: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Audited %d facts (%d pairs checked), %d new contradictions.\n", len(res.Audited), res.Compared, len(res.Links))
+	}
+
+	if *dismiss != "" {
+		for _, s := range strings.Split(*dismiss, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil || id <= 0 {
+				log.Fatalf("contradictions: invalid link ID %q", s)
+			}
+			if err := memstore.DismissContradiction(ctx, store, id); err != nil {
+				log.Fatalf("contradictions: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "Dismissed link %d\n", id)
+		}
+	}
+
+	cq, ok := store.(memstore.ContradictionQueue)
+	if !ok {
+		log.Fatal("contradictions: this store has no contradiction queue")
+	}
+	items, err := cq.Contradictions(ctx, *limit)
+	if err != nil {
+		log.Fatalf("contradictions: %v", err)
+	}
+	switch *format {
+	case "json":
+		if items == nil {
+			items = []memstore.Contradiction{}
+		}
+		if err := writeJSON(os.Stdout, items); err != nil {
+			log.Fatalf("contradictions: %v", err)
+		}
+	default:
+		if len(items) == 0 {
+			fmt.Fprintln(os.Stderr, "No open contradictions.")
+			return
+		}
+		for _, c := range items {
+			writeContradiction(os.Stdout, c)
+		}
+	}
+}
+
+// writeContradiction prints one review-queue entry: the model's reason, then
+// both facts.
+func writeContradiction(w io.Writer, c memstore.Contradiction) {
+	fmt.Fprintf(w, "[link=%d] %s\n", c.LinkID, c.Reason)
+	for _, f := range []memstore.Fact{c.A, c.B} {
+		fmt.Fprintf(w, "  [id=%d] %s | %s | %s\n    %s\n", f.ID, f.Subject, f.Category, f.CreatedAt.Local().Format("2006-01-02"), f.Content)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runDedupe(args []string) {
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	threshold := fs.Float64("threshold", memstore.DefaultDedupeThreshold, "minimum embedding similarity (0-1) for two facts to count as duplicates")
+	lexical := fs.Float64("lexical-threshold", memstore.DefaultDedupeLexicalThreshold, "minimum term overlap (0-1) for facts without embeddings to count as duplicates")
+	neighbors := fs.Int("neighbors", memstore.DefaultDedupeNeighbors, "nearest neighbours examined per fact")
+	limit := fs.Int("limit", 0, "max clusters to report (0 = all)")
+	format := fs.String("format", "text", "output format: text|json")
+	apply := fs.Bool("apply", false, "walk the clusters and accept or skip each suggestion interactively")
+	draft := fs.Bool("draft", false, "with --apply, have the configured LLM draft merged content instead of opening $EDITOR")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore dedupe [flags]")
+		fmt.Fprintln(os.Stderr, "Reports clusters of near-duplicate active facts across all subjects, most similar first.")
+		fmt.Fprintln(os.Stderr, "With --apply, each cluster can be merged into one fact or superseded by its newest fact.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *threshold <= 0 || *threshold > 1 {
+		log.Fatalf("dedupe: --threshold must be in (0, 1], got %v", *threshold)
+	}
+	if *lexical <= 0 || *lexical > 1 {
+		log.Fatalf("dedupe: --lexical-threshold must be in (0, 1], got %v", *lexical)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing to compare
+	}
+	defer closeStore()
+
+	d, ok := store.(memstore.Deduper)
+	if !ok {
+		log.Fatal("dedupe: this store cannot scan for duplicates")
+	}
+	ctx := context.Background()
+	clusters, err := d.FindDuplicates(ctx, memstore.DedupeOpts{Threshold: *threshold, LexicalThreshold: *lexical, Neighbors: *neighbors, Limit: *limit})
+	if err != nil {
+		log.Fatalf("dedupe: %v", err)
+	}
+
+	if *apply {
+		if err := applyDedupe(ctx, store, clusters, bufio.NewReader(os.Stdin), os.Stderr, *draft); err != nil {
+			log.Fatalf("dedupe: %v", err)
+		}
+		return
+	}
+	switch *format {
+	case "json":
+		if clusters == nil {
+			clusters = []memstore.DuplicateCluster{}
+		}
+		if err := writeJSON(os.Stdout, clusters); err != nil {
+			log.Fatalf("dedupe: %v", err)
+		}
+	default:
+		if len(clusters) == 0 {
+			fmt.Fprintln(os.Stderr, "No duplicates found.")
+			return
+		}
+		for i, c := range clusters {
+			writeCluster(os.Stdout, i+1, c)
+		}
+	}
+}
+
+// writeCluster prints one duplicate cluster: its facts newest first, then
+// the pairs that connect them.
+func writeCluster(w io.Writer, n int, c memstore.DuplicateCluster) {
+	fmt.Fprintf(w, "Cluster %d: %d facts, similarity %.3f\n", n, len(c.Facts), c.Similarity)
+	for _, f := range c.Facts {
+		fmt.Fprintf(w, "  [id=%d] %s | %s | %s\n    %s\n", f.ID, f.Subject, f.Category, f.CreatedAt.Local().Format("2006-01-02"), f.Content)
+	}
+	pairs := make([]string, len(c.Pairs))
+	for i, p := range c.Pairs {
+		pairs[i] = fmt.Sprintf("%d~%d %.3f (%s)", p.A, p.B, p.Similarity, p.Method)
+	}
+	fmt.Fprintf(w, "  pairs: %s\n\n", strings.Join(pairs, ", "))
+}
+
+// applyDedupe walks clusters, asking on out and reading answers from in.
+// Merging consolidates the cluster into one fact templated on its newest
+// member; superseding marks every older member replaced by the newest.
+func applyDedupe(ctx context.Context, store memstore.Store, clusters []memstore.DuplicateCluster, in *bufio.Reader, out io.Writer, draft bool) error {
+	for i, c := range clusters {
+		writeCluster(out, i+1, c)
+		newest := c.Facts[0]
+		fmt.Fprintf(out, "[m]erge, [s]upersede by %d, [n]ext, [q]uit? ", newest.ID)
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "m", "merge":
+			ids := make([]int64, len(c.Facts))
+			for j, f := range c.Facts {
+				ids[j] = f.ID
+			}
+			content, err := dedupeMergeContent(ctx, c.Facts, draft)
+			if err != nil {
+				return err
+			}
+			if content == "" {
+				fmt.Fprintln(out, "Empty content; cluster skipped.")
+				continue
+			}
+			id, err := store.Merge(ctx, ids, content, nil)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Merged %d facts into id=%d\n\n", len(ids), id)
+		case "s", "supersede":
+			for _, f := range c.Facts[1:] {
+				if err := store.Supersede(ctx, f.ID, newest.ID); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(out, "Superseded %d facts by id=%d\n\n", len(c.Facts)-1, newest.ID)
+		case "q", "quit":
+			return nil
+		default:
+			fmt.Fprintln(out)
+		}
+	}
+	return nil
+}
+
+// dedupeMergeContent returns the consolidated content for a cluster, drafted
+// by the LLM or edited by hand from the members' contents.
+func dedupeMergeContent(ctx context.Context, facts []memstore.Fact, draft bool) (string, error) {
+	if draft {
+		gen, err := cliGenerator()
+		if err != nil {
+			return "", err
+		}
+		byAge := append([]memstore.Fact(nil), facts...)
+		sort.SliceStable(byAge, func(i, j int) bool { return byAge[i].CreatedAt.Before(byAge[j].CreatedAt) })
+		return memstore.DraftMerge(ctx, gen, byAge)
+	}
+	var b strings.Builder
+	for _, f := range facts {
+		fmt.Fprintf(&b, "%s\n\n", f.Content)
+	}
+	return editText(editorCommand(), b.String())
+}
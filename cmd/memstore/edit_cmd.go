@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func runEdit(args []string) {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	metadataStr := fs.String("metadata", "", `JSON metadata patch for the new version; null values delete keys (e.g. '{"source":"review"}')`)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore edit [flags] <id>")
+		fmt.Fprintln(os.Stderr, "Opens the fact's content in $VISUAL or $EDITOR and stores the result as a revision.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil || id <= 0 {
+		log.Fatalf("edit: invalid fact ID %q", fs.Arg(0))
+	}
+	var patch map[string]any
+	if *metadataStr != "" {
+		if err := json.Unmarshal([]byte(*metadataStr), &patch); err != nil {
+			log.Fatalf("edit: invalid --metadata JSON: %v", err)
+		}
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		log.Fatalf("edit: database not found at %s", *dbPath)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	f, err := store.Get(ctx, id)
+	if err != nil {
+		log.Fatalf("edit: %v", err)
+	}
+	if f == nil {
+		log.Fatalf("edit: fact %d not found", id)
+	}
+	if f.SupersededBy != nil {
+		log.Fatalf("edit: fact %d is superseded by fact %d; edit that one instead", id, *f.SupersededBy)
+	}
+
+	content, err := editText(editorCommand(), f.Content)
+	if err != nil {
+		log.Fatalf("edit: %v", err)
+	}
+	if content == "" {
+		log.Fatal("edit: empty content, fact left unchanged")
+	}
+	if content == strings.TrimSpace(f.Content) && len(patch) == 0 {
+		fmt.Fprintln(os.Stderr, "No changes.")
+		return
+	}
+
+	newID, err := store.Revise(ctx, id, content, patch)
+	if err != nil {
+		log.Fatalf("edit: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Revised (id=%d, supersedes=%d)\n", newID, id)
+}
+
+// editorCommand returns the user's editor: $VISUAL, then $EDITOR, then vi.
+func editorCommand() string {
+	for _, v := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(v)); e != "" {
+			return e
+		}
+	}
+	return "vi"
+}
+
+// editText writes text to a temporary file, runs editor on it attached to
+// the terminal, and returns the saved contents with surrounding whitespace
+// trimmed. editor may carry arguments (e.g. "code --wait").
+func editText(editor, text string) (string, error) {
+	tmp, err := os.CreateTemp("", "memstore-edit-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(text + "\n"); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	argv := strings.Fields(editor)
+	cmd := exec.Command(argv[0], append(argv[1:], tmp.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running %s: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(edited)), nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/matthewjhunter/go-embedding"
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/internal/eval"
+)
+
+// runEval scores a golden query set against the search and recall pipelines
+// (docs/retrieval-eval.md). By default it builds an in-memory fixture store
+// from the set's fixtures with a deterministic embedder and reranker, so the
+// numbers are reproducible without models; --live runs against the
+// configured database with the MEMSTORE_EMBED / MEMSTORE_RERANK models.
+func runEval(args []string, out io.Writer) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	golden := fs.String("golden", "", "golden set JSON file (required)")
+	configsPath := fs.String("configs", "", "JSON array of parameter configurations (overrides the set's own)")
+	k := fs.Int("k", 5, "ranking cutoff for recall@k and nDCG@k")
+	pipelines := fs.String("pipeline", "search,recall", "pipelines to evaluate: search, recall, or both")
+	format := fs.String("format", "text", "output format: text|json")
+	minNDCG := fs.Float64("min-ndcg", 0, "exit non-zero if any run's mean nDCG falls below this")
+	live := fs.Bool("live", false, "evaluate the configured database instead of the set's fixtures")
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database (--live)")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace (--live)")
+	fs.Parse(args)
+
+	if *golden == "" {
+		fmt.Fprintln(os.Stderr, "eval: --golden is required")
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fail(fmt.Errorf("eval: unknown --format %q (want text|json)", *format))
+	}
+	set, err := eval.LoadGoldenSet(*golden)
+	if err != nil {
+		fail(err)
+	}
+	configs := set.Configs
+	if *configsPath != "" {
+		if configs, err = eval.LoadConfigs(*configsPath); err != nil {
+			fail(err)
+		}
+	}
+	pl, err := eval.ParsePipelines(*pipelines)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx := context.Background()
+	var target eval.Target
+	if *live {
+		t, closeStore, err := liveEvalTarget(*dbPath, *namespace)
+		if err != nil {
+			fail(err)
+		}
+		defer closeStore()
+		target = t
+	} else {
+		fixtures, err := eval.NewFixtureStore(ctx, set.Fixtures)
+		if err != nil {
+			fail(err)
+		}
+		defer fixtures.Close()
+		target = fixtures.Target()
+	}
+
+	report, err := eval.Run(ctx, target, set, configs, eval.Options{K: *k, Pipelines: pl})
+	if err != nil {
+		fail(err)
+	}
+	if *format == "json" {
+		err = report.WriteJSON(out)
+	} else {
+		err = report.WriteText(out)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	if *minNDCG > 0 {
+		for _, run := range report.Runs {
+			if run.Mean.NDCG < *minNDCG {
+				fail(fmt.Errorf("eval: %s/%s mean nDCG %.3f is below --min-ndcg %.3f",
+					run.Pipeline, run.Config, run.Mean.NDCG, *minNDCG))
+			}
+		}
+	}
+}
+
+// liveEvalTarget opens the configured store with the environment's embedder
+// and reranker. Either may be absent: without an embedder search runs
+// FTS-only, and configurations that enable rerank fail rather than silently
+// measuring first-stage order.
+func liveEvalTarget(dbPath, namespace string) (eval.Target, func(), error) {
+	var embedder embedding.Embedder
+	cfg, err := embedding.ConfigFromEnvPrefix("MEMSTORE_EMBED")
+	if err != nil {
+		return eval.Target{}, nil, fmt.Errorf("eval: embedder config: %w", err)
+	}
+	if cfg.Model != "" {
+		if embedder, err = embedding.New(cfg); err != nil {
+			return eval.Target{}, nil, fmt.Errorf("eval: create embedder: %w", err)
+		}
+	}
+	rr, _, err := memstore.RerankerFromEnv("MEMSTORE_RERANK")
+	if err != nil {
+		return eval.Target{}, nil, err
+	}
+	store, closeStore, err := openStoreWithEmbedder(dbPath, namespace, embedder)
+	if err != nil {
+		return eval.Target{}, nil, err
+	}
+	if store == nil {
+		return eval.Target{}, nil, fmt.Errorf("eval: database %s does not exist", dbPath)
+	}
+	if s, ok := store.(*memstore.SQLiteStore); ok && rr != nil {
+		s.SetReranker(rr)
+	}
+	return eval.Target{Store: store, Embedder: embedder, Reranker: rr}, closeStore, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/pgstore"
+)
+
+// --- list-experiments ---
+
+// runListExperiments prints the namespace's online ranking experiments and,
+// for each, the per-arm feedback on the injections it served. Without --user
+// the feedback spans every user through the session store's service scope.
+func runListExperiments(args []string, out io.Writer) {
+	fs := flag.NewFlagSet("list-experiments", flag.ExitOnError)
+	pgDSN := fs.String("pg", "", "PostgreSQL DSN (defaults to MEMSTORE_PG_SECRET / config)")
+	namespace := fs.String("namespace", defaultAdminNamespace(), namespaceFlagUsage)
+	userName := fs.String("user", "", "feedback from this user's sessions only (default: all users)")
+	activeOnly := fs.Bool("active", false, "only running experiments")
+	if _, err := parseAdminArgs(fs, args); err != nil {
+		fail(err)
+	}
+
+	pool, closePool, err := openPool(*pgDSN)
+	if err != nil {
+		fail(err)
+	}
+	defer closePool()
+
+	ctx := context.Background()
+	es, err := pgstore.NewExperimentStore(ctx, pool, *namespace)
+	if err != nil {
+		fail(err)
+	}
+	ss, err := pgstore.NewSessionStore(ctx, pool)
+	if err != nil {
+		fail(err)
+	}
+	fb := ss.ServiceScope()
+	if *userName != "" {
+		uid, err := pgstore.LookupUserID(ctx, pool, *namespace, *userName)
+		if err != nil {
+			fail(err)
+		}
+		scoped, err := ss.ForUser(uid)
+		if err != nil {
+			fail(err)
+		}
+		fb = scoped.(*pgstore.SessionStore)
+	}
+
+	infos, err := es.List(ctx)
+	if err != nil {
+		fail(err)
+	}
+	shown := 0
+	for _, e := range infos {
+		if *activeOnly && e.StoppedAt != nil {
+			continue
+		}
+		if shown > 0 {
+			fmt.Fprintln(out)
+		}
+		shown++
+
+		status := "running"
+		if e.StoppedAt != nil {
+			status = "stopped " + e.StoppedAt.Format("2006-01-02")
+			if e.StopReason != "" {
+				status += " (" + e.StopReason + ")"
+			}
+		}
+		traffic := e.Traffic
+		if traffic <= 0 {
+			traffic = 1
+		}
+		fmt.Fprintf(out, "%s  mode=%s  traffic=%.0f%%  started=%s  %s\n",
+			e.Name, e.Mode, traffic*100, e.StartedAt.Format("2006-01-02"), status)
+
+		arms, err := fb.ExperimentFeedback(ctx, e.Name)
+		if err != nil {
+			fail(err)
+		}
+		writeArmFeedback(out, e.Experiment, arms)
+	}
+	if shown == 0 {
+		fmt.Fprintln(out, "No experiments.")
+	}
+}
+
+// writeArmFeedback prints one row per arm and ref type. Arms that have served
+// nothing yet still get a row, so a stalled arm is visible.
+func writeArmFeedback(out io.Writer, e memstore.Experiment, arms []memstore.ArmFeedback) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ARM\tRANKER\tREF\tSESSIONS\tINJECTED\tRATED\t+\t-\tAVG")
+	for i, a := range e.Arms {
+		served := false
+		for _, f := range arms {
+			if f.Arm != a.Name {
+				continue
+			}
+			served = true
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%+.3f\n",
+				a.Name, e.RankerVersionFor(i), f.RefType, f.Sessions, f.Injections,
+				f.Rated, f.Positive, f.Negative, f.AvgScore)
+		}
+		if !served {
+			fmt.Fprintf(tw, "  %s\t%s\t-\t0\t0\t0\t0\t0\t-\n", a.Name, e.RankerVersionFor(i))
+		}
+	}
+	tw.Flush()
+}
+
+// --- stop-experiment ---
+
+func runStopExperiment(args []string, out io.Writer) {
+	fs := flag.NewFlagSet("stop-experiment", flag.ExitOnError)
+	pgDSN := fs.String("pg", "", "PostgreSQL DSN (defaults to MEMSTORE_PG_SECRET / config)")
+	namespace := fs.String("namespace", defaultAdminNamespace(), namespaceFlagUsage)
+	reason := fs.String("reason", "stopped by admin", "why the experiment was stopped (shown by list-experiments)")
+	positional, err := parseAdminArgs(fs, args)
+	if err != nil {
+		fail(err)
+	}
+	name := exactlyOneArg("stop-experiment", positional, "<name>")
+
+	pool, closePool, err := openPool(*pgDSN)
+	if err != nil {
+		fail(err)
+	}
+	defer closePool()
+
+	ctx := context.Background()
+	es, err := pgstore.NewExperimentStore(ctx, pool, *namespace)
+	if err != nil {
+		fail(err)
+	}
+	ok, err := es.Stop(ctx, name, *reason)
+	if err != nil {
+		fail(err)
+	}
+	if !ok {
+		fmt.Fprintf(out, "No running experiment named %q.\n", name)
+		os.Exit(1)
+	}
+	fmt.Fprintf(out, "Stopped experiment %q. Running daemons stop serving it within a minute.\n", name)
+	fmt.Fprintln(out, "Remove it from the daemon's experiments file too; a stopped experiment is not restarted.")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	subject := fs.String("subject", "", "show every fact for this subject, including superseded ones")
+	format := fs.String("format", "text", "output format: text|json")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore history [flags] <id>")
+		fmt.Fprintln(os.Stderr, "       memstore history [flags] --subject <s>")
+		fmt.Fprintln(os.Stderr, "Shows a fact's supersession chain, oldest first, with where each version came from.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var id int64
+	if fs.NArg() > 0 {
+		n, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil || n <= 0 {
+			log.Fatalf("history: invalid fact ID %q", fs.Arg(0))
+		}
+		id = n
+	}
+	if id == 0 && *subject == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; no history
+	}
+	defer closeStore()
+
+	entries, err := store.History(context.Background(), id, *subject)
+	if err != nil {
+		log.Fatalf("history: %v", err)
+	}
+	switch *format {
+	case "json":
+		if entries == nil {
+			entries = []memstore.HistoryEntry{}
+		}
+		if err := writeJSON(os.Stdout, entries); err != nil {
+			log.Fatalf("history: %v", err)
+		}
+	default:
+		for _, e := range entries {
+			writeHistoryEntry(os.Stdout, e)
+		}
+	}
+}
+
+// writeHistoryEntry prints one version of a fact with its status and
+// provenance.
+func writeHistoryEntry(w io.Writer, e memstore.HistoryEntry) {
+	f := e.Fact
+	status := "active"
+	if f.SupersededBy != nil {
+		status = fmt.Sprintf("superseded by %d", *f.SupersededBy)
+	}
+	fmt.Fprintf(w, "[%d/%d] id=%d | %s | %s | %s | %s\n  %s\n  provenance: %s\n\n",
+		e.Position+1, e.ChainLength, f.ID, f.Subject, f.Category, status,
+		f.CreatedAt.Format("2006-01-02 15:04"), f.Content, f.Provenance)
+}
@@ -31,7 +31,7 @@ const now = new Date().toISOString();
 
 // 1. Record a "last active" fact for this working directory.
 try {
-  const project = cwd.split('/').filter(Boolean).pop() || cwd;
+  const project = resolveProject(cwd);
   const metadata = JSON.stringify({ directory: cwd, timestamp: now });
   execSync(
     `${MEMSTORE_BIN} store --subject "session-activity" --category "note" ` +
@@ -58,6 +58,23 @@ try {
   // tasks command failed — proceed silently.
 }
 
+// Helper: the project for cwd, resolved through subject aliases so a renamed
+// checkout or a fork is recorded under its canonical name. Falls back to the
+// directory name if the binary is too old to know the alias command.
+function resolveProject(dir) {
+  try {
+    const name = execSync(`${MEMSTORE_BIN} alias resolve --cwd ${JSON.stringify(dir)}`, {
+      encoding: 'utf-8',
+      timeout: 2000,
+      stdio: ['pipe', 'pipe', 'pipe'],
+    }).trim();
+    if (name) return name;
+  } catch {
+    // Fall through to the directory name.
+  }
+  return dir.split('/').filter(Boolean).pop() || dir;
+}
+
 // Helper: read all of stdin as a string (Node 18+).
 async function stdinText() {
   const chunks = [];
@@ -2,7 +2,8 @@
 /**
  * memstore-startup: Claude Code SessionStart hook
  *
- * Injects pending startup-surface tasks and homelab host inventory at
+ * Injects reminders that just came due, pending startup-surface tasks,
+ * homelab host inventory, and the top of the staleness review queue at
  * session start. Project context is handled via the per-prompt recall
  * pipeline (UserPromptSubmit hook), which applies a project-surface boost
  * when the CWD matches a fact's project_path.
@@ -21,7 +22,22 @@ try {
 
 const sections = [];
 
-// 1. Pending startup tasks.
+// 1. Reminders. Evaluating them surfaces recurring and scheduled tasks whose
+// time has come, so this runs before the task list picks them up. memstored
+// also evaluates on a timer; here it covers local mode and a stopped daemon.
+try {
+  const reminders = execSync(`${MEMSTORE_BIN} remind`, {
+    encoding: 'utf-8',
+    timeout: 3000,
+    stdio: ['pipe', 'pipe', 'pipe'],
+  }).trim();
+
+  if (reminders) sections.push(reminders);
+} catch {
+  // Binary missing, DB absent, or command failed — proceed silently.
+}
+
+// 2. Pending startup tasks.
 try {
   const tasks = execSync(`${MEMSTORE_BIN} tasks --surface startup`, {
     encoding: 'utf-8',
@@ -34,7 +50,7 @@ try {
   // Binary missing, DB absent, or command failed — proceed silently.
 }
 
-// 2. Homelab system inventory (always inject so hosts/IPs are available without asking).
+// 3. Homelab system inventory (always inject so hosts/IPs are available without asking).
 try {
   const hosts = execSync(
     `${MEMSTORE_BIN} search -query "homelab hosts" -limit 1`,
@@ -48,6 +64,25 @@ try {
   // Search failed — proceed silently.
 }
 
+// 4. Facts due for re-confirmation, highest priority first. Kept short: the
+// agent can pull the full batch with memory_review_queue.
+try {
+  const review = execSync(`${MEMSTORE_BIN} review --limit 3`, {
+    encoding: 'utf-8',
+    timeout: 3000,
+    stdio: ['pipe', 'pipe', 'pipe'],
+  }).trim();
+
+  if (review) {
+    sections.push(
+      `[FACTS DUE FOR REVIEW]\n${review}\n` +
+      'If one comes up this session, verify it: memory_confirm if it holds, memory_revise or memory_delete if not.'
+    );
+  }
+} catch {
+  // Review queue unavailable — proceed silently.
+}
+
 if (sections.length === 0) {
   console.log(JSON.stringify({ continue: true }));
   process.exit(0);
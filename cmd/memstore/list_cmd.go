@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/matthewjhunter/memstore"
 )
@@ -22,8 +23,12 @@ func runList(args []string) {
 	kind := fs.String("kind", "", "filter by kind")
 	subsystem := fs.String("subsystem", "", "filter by subsystem")
 	metadataStr := fs.String("metadata", "", `JSON object of equality filters (e.g. '{"surface":"startup"}')`)
+	source := fs.String("source", "", "filter by provenance source: manual|extraction|import|summary")
+	session := fs.String("session", "", "filter to facts that originated in this session")
+	origin := fs.String("origin", "", "filter by the identity that wrote the fact")
 	limit := fs.Int("limit", 0, "max results (0 = no limit)")
 	onlyActive := fs.Bool("active", true, "exclude superseded facts")
+	tags := tagFilterFlags(fs)
 	fs.Parse(args)
 
 	var filters []memstore.MetadataFilter
@@ -54,6 +59,8 @@ func runList(args []string) {
 		OnlyActive:      *onlyActive,
 		MetadataFilters: filters,
 		Limit:           *limit,
+		Provenance:      memstore.ProvenanceFilter{Source: *source, SessionID: *session, Origin: *origin},
+		Tags:            tags(),
 	})
 	if err != nil {
 		log.Fatalf("list: %v", err)
@@ -72,12 +79,20 @@ func runList(args []string) {
 // writeFactsText writes facts in a human-readable format.
 func writeFactsText(w io.Writer, facts []memstore.Fact) {
 	for _, f := range facts {
-		fmt.Fprintf(w, "[id=%d] %s | %s | %s\n  %s\n\n",
-			f.ID, f.Subject, f.Category, f.CreatedAt.Format("2006-01-02"),
+		fmt.Fprintf(w, "[id=%d] %s | %s | %s%s\n  %s\n\n",
+			f.ID, f.Subject, f.Category, f.CreatedAt.Format("2006-01-02"), tagSuffix(f.Tags),
 			f.Content)
 	}
 }
 
+// tagSuffix formats a fact's tags for a text listing line.
+func tagSuffix(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " | #" + strings.Join(tags, " #")
+}
+
 // writeJSON encodes v as indented JSON to w.
 func writeJSON(w io.Writer, v any) error {
 	enc := json.NewEncoder(w)
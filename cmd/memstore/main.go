@@ -4,11 +4,26 @@
 //
 //	memstore export --db path/to/db.sqlite [--output=path]
 //	memstore import --db path/to/db.sqlite [--skip-duplicates] file.json
-//	memstore tasks [--surface startup] [--status pending] [--scope claude] [--format text|json]
+//	memstore tasks [--surface startup] [--status pending] [--scope claude] [--owner o] [--state blocked|ready|in_progress|done|overdue|due_soon] [--group-by state|project|owner|priority|scope] [--format text|json]
+//	memstore remind [--format text|json]
 //	memstore backfill-feedback
-//	memstore store --subject <s> --content <c> [--category note] [--kind <k>] [--subsystem <ss>] [--metadata '{}'] [--supersedes id]
-//	memstore list [--subject <s>] [--category <c>] [--metadata '{}'] [--format text|json]
-//	memstore search --query <q> [--subject <s>] [--category <c>] [--limit 5] [--format text|json]
+//	memstore store --subject <s> --content <c> [--category note] [--kind <k>] [--subsystem <ss>] [--metadata '{}'] [--tags a,b] [--supersedes id]
+//	memstore edit [--metadata '{}'] <id>
+//	memstore merge [--content <c> | --draft] [--dry-run] [--metadata '{}'] <id> <id>...
+//	memstore dedupe [--threshold 0.92] [--limit N] [--format text|json] [--apply [--draft]]
+//	memstore contradictions [--limit N] [--format text|json] [--dismiss id,...] [--audit [--batch N]]
+//	memstore bulk-update [--subject <s>] [--kind <k>] [--metadata '{}'] ... [--set-subject <s>] [--set-kind <k>] [--set-metadata '{}'] [--apply]
+//	memstore audit [--limit 20] [--format text|json]
+//	memstore namespace list | rename|copy|merge <from> <to> [--duplicates supersede|keep] | delete <ns> --yes
+//	memstore alias list | add <alias> <canonical> | remove <alias> | rewrite [<alias>] | resolve <subject> | resolve --cwd <dir>
+//	memstore pin list [--cwd <dir>] | add <id> --subject <s> | --path <dir> [--position N] | remove <id>
+//	memstore tag list [--subject <s>] | add|remove <id> <tag>... | rename <from> <to> | delete <tag>
+//	memstore schema list | show <kind> | audit [--kind <k>] [--format text|json]
+//	memstore review [--limit 10] [--min-age 30d] [--subject s] [--format text|json] [--apply]
+//	memstore list [--subject <s>] [--category <c>] [--metadata '{}'] [--source <k>] [--session <id>] [--origin <name>] [--tag a,b] [--tag-all a,b] [--tag-none a,b] [--format text|json]
+//	memstore history [--format text|json] <id> | --subject <s>
+//	memstore search --query <q> [--subject <s>] [--category <c>] [--tag a,b] [--tag-all a,b] [--tag-none a,b] [--limit 5] [--format text|json]
+//	memstore eval --golden set.json [--configs configs.json] [--k 5] [--pipeline search,recall] [--format text|json] [--live]
 package main
 
 import (
@@ -45,14 +60,50 @@ func main() {
 		runImport(os.Args[2:])
 	case "tasks":
 		runTasks(os.Args[2:])
+	case "remind":
+		runRemind(os.Args[2:])
 	case "store":
 		runStore(os.Args[2:])
+	case "edit":
+		runEdit(os.Args[2:])
+	case "merge":
+		runMerge(os.Args[2:])
+	case "dedupe":
+		runDedupe(os.Args[2:])
+	case "contradictions":
+		runContradictions(os.Args[2:])
+	case "review":
+		runReview(os.Args[2:])
+	case "bulk-update":
+		runBulkUpdate(os.Args[2:])
+	case "audit":
+		runAudit(os.Args[2:])
+	case "namespace":
+		runNamespace(os.Args[2:])
+	case "alias":
+		runAlias(os.Args[2:])
+	case "pin":
+		runPin(os.Args[2:])
+	case "tag":
+		runTag(os.Args[2:])
+	case "schema":
+		runSchema(os.Args[2:])
 	case "list":
 		runList(os.Args[2:])
+	case "history":
+		runHistory(os.Args[2:])
 	case "search":
 		runSearch(os.Args[2:])
+	case "trash":
+		runTrash(os.Args[2:])
+	case "restore":
+		runRestore(os.Args[2:])
+	case "purge":
+		runPurge(os.Args[2:])
 	case "eval-triggers":
 		runEvalTriggers(os.Args[2:])
+	case "eval":
+		runEval(os.Args[2:], os.Stdout)
 	case "setup":
 		runSetup(os.Args[2:])
 	case "tls":
@@ -76,11 +127,29 @@ func printUsage() {
 Commands:
   export    Export all facts to JSON
   import    Import facts from a JSON export
-  tasks     List tasks (filter by surface, status, scope, project)
+  tasks     List tasks (filter by surface, status, scope, project, owner, state; --group-by for a board)
+  remind    Surface recurring and scheduled tasks whose reminder time has passed
   store     Store a new fact
-  list      List facts (filter by subject, category, metadata)
+  edit      Revise a fact's content in $EDITOR (stored as a superseding version)
+  merge     Consolidate facts into one that supersedes them (--draft, --dry-run)
+  dedupe    Report near-duplicate facts across subjects (--threshold; --apply to merge or supersede)
+  contradictions  Review facts the LLM audit judged to conflict (--dismiss; --audit runs a pass)
+  review    List facts due for re-confirmation (--apply to confirm, supersede or delete inline)
+  bulk-update  Re-classify every fact matching a filter (--set-*; preview unless --apply)
+  audit     Show the audit log of bulk and namespace changes
+  namespace  List, rename, copy, merge or delete namespaces
+  alias     Manage subject aliases (renamed repos, forks) and rewrite facts to the canonical subject
+  pin       Pin facts injected at the top of every recall for a project (list, add, remove)
+  tag       List, add, remove, rename or delete fact tags
+  schema    List and show per-kind metadata schemas; audit facts that fail them
+  list      List facts (filter by subject, category, metadata, provenance, tags)
+  history   Show a fact's supersession chain with each version's provenance
   search    FTS search facts by query text
+  trash     List deleted facts still in the trash
+  restore   Restore deleted facts from the trash by ID
+  purge     Permanently remove facts from the trash (--older-than 30d, or --all)
   eval-triggers  Evaluate trigger facts against a file path and load context
+  eval           Score a golden query set against search and recall (recall@k, MRR, nDCG)
   setup              Install hooks, register MCP server, and configure memstore
   tls                Generate a self-signed CA + server cert, or issue client certs
   admin              Manage api_tokens (issue / list / revoke / rotate). Requires --pg.
@@ -127,6 +196,18 @@ func openStoreWithEmbedder(dbPath, namespace string, embedder embedding.Embedder
 		db.Close()
 		return nil, nil, fmt.Errorf("open store: %w", err)
 	}
+	pol, err := memstore.QueryCachePolicyFromEnv("MEMSTORE_QUERY_CACHE_PERSIST")
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	store.SetQueryCache(pol)
+	schemas, err := memstore.LoadSchemas(cliConfig.SchemasFile)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	store.SetSchemas(schemas)
 	return store, func() { db.Close() }, nil
 }
 
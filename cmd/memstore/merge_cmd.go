@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpclient"
+)
+
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	content := fs.String("content", "", "consolidated content for the merged fact")
+	draft := fs.Bool("draft", false, "have the configured LLM draft the consolidated content")
+	dryRun := fs.Bool("dry-run", false, "show the sources and the content that would be stored, then exit")
+	metadataStr := fs.String("metadata", "", `JSON metadata patch for the merged fact; null values delete keys`)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore merge [flags] <id> <id>...")
+		fmt.Fprintln(os.Stderr, "Consolidates facts into one that supersedes them all. The first ID is the template for")
+		fmt.Fprintln(os.Stderr, "subject, category, kind, and subsystem. Without --content or --draft, opens $EDITOR.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *content != "" && *draft {
+		log.Fatal("merge: --content and --draft are mutually exclusive")
+	}
+	var ids []int64
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			log.Fatalf("merge: invalid fact ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	ids, err := memstore.MergeSources(ids)
+	if err != nil {
+		fs.Usage()
+		log.Fatalf("merge: %v", err)
+	}
+	var patch map[string]any
+	if *metadataStr != "" {
+		if err := json.Unmarshal([]byte(*metadataStr), &patch); err != nil {
+			log.Fatalf("merge: invalid --metadata JSON: %v", err)
+		}
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		log.Fatalf("merge: database not found at %s", *dbPath)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	sources, err := mergeSourceFacts(ctx, store, ids)
+	if err != nil {
+		log.Fatalf("merge: %v", err)
+	}
+
+	text := strings.TrimSpace(*content)
+	switch {
+	case text != "":
+	case *draft:
+		gen, err := cliGenerator()
+		if err != nil {
+			log.Fatalf("merge: %v", err)
+		}
+		byAge := append([]memstore.Fact(nil), sources...)
+		sort.SliceStable(byAge, func(i, j int) bool { return byAge[i].CreatedAt.Before(byAge[j].CreatedAt) })
+		if text, err = memstore.DraftMerge(ctx, gen, byAge); err != nil {
+			log.Fatalf("merge: %v", err)
+		}
+	default:
+		var b strings.Builder
+		for _, f := range sources {
+			fmt.Fprintf(&b, "%s\n\n", f.Content)
+		}
+		if text, err = editText(editorCommand(), b.String()); err != nil {
+			log.Fatalf("merge: %v", err)
+		}
+		if text == "" {
+			log.Fatal("merge: empty content, nothing merged")
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("Would merge %d facts:\n", len(sources))
+		for _, f := range sources {
+			fmt.Printf("  [id=%d] %s | %s\n    %s\n", f.ID, f.Subject, f.Category, f.Content)
+		}
+		fmt.Printf("\ninto:\n  %s\n", text)
+		return
+	}
+
+	newID, err := store.Merge(ctx, ids, text, patch)
+	if err != nil {
+		log.Fatalf("merge: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Merged %d facts into id=%d\n", len(ids), newID)
+}
+
+// mergeSourceFacts fetches the facts to merge in ids order, failing if any
+// is missing or already superseded.
+func mergeSourceFacts(ctx context.Context, store memstore.Store, ids []int64) ([]memstore.Fact, error) {
+	facts, err := store.List(ctx, memstore.QueryOpts{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]memstore.Fact, len(facts))
+	for _, f := range facts {
+		byID[f.ID] = f
+	}
+	out := make([]memstore.Fact, 0, len(ids))
+	for _, id := range ids {
+		f, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("fact %d not found", id)
+		}
+		if f.SupersededBy != nil {
+			return nil, fmt.Errorf("fact %d is already superseded by fact %d", id, *f.SupersededBy)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// cliGenerator returns the LLM used for drafting: the daemon's in remote
+// mode, otherwise the configured chat model.
+func cliGenerator() (memstore.Generator, error) {
+	if cliConfig.Remote != "" {
+		gen, err := httpclient.NewHTTPGeneratorWithOptions(cliConfig.Remote, cliConfig.APIKey, httpclient.ClientOptionsFromConfig(cliConfig))
+		if err != nil {
+			return nil, err
+		}
+		return gen, nil
+	}
+	if cliConfig.GenModel == "" {
+		return nil, fmt.Errorf("no generation model configured (set gen_model or MEMSTORE_GEN_MODEL)")
+	}
+	url := cliConfig.GenURL
+	if url == "" {
+		url = cliConfig.Ollama
+	}
+	return memstore.NewOpenAIGenerator(url, cliConfig.LLMAPIKey, cliConfig.GenModel), nil
+}
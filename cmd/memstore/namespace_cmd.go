@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/pgstore"
+)
+
+const namespaceUsage = `Usage: memstore namespace <subcommand> [flags]
+       memstore admin namespace <subcommand> [flags]   (PostgreSQL, all users)
+
+Subcommands:
+  list                  List namespaces with fact, link and document counts.
+  rename <from> <to>    Move everything in <from> to the empty namespace <to>.
+  copy <from> <to>      Copy facts and links, chains and all, into the empty namespace <to>.
+  merge <from> <to>     Move everything in <from> into <to> (--duplicates supersede|keep).
+  delete <ns> --yes     Permanently delete everything in <ns>.`
+
+func runNamespace(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, namespaceUsage)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("namespace "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	format := fs.String("format", "text", "output format: text|json")
+	dup := fs.String("duplicates", "supersede", "merge: what to do with facts already in the destination (supersede|keep)")
+	yes := fs.Bool("yes", false, "delete: confirm the deletion")
+	positional, err := parseAdminArgs(fs, args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, closeStore, err := openStore(*dbPath, cliConfig.Namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; no namespaces
+	}
+	defer closeStore()
+
+	na, ok := store.(memstore.NamespaceAdmin)
+	if !ok {
+		log.Fatal("namespace: this store cannot manage namespaces")
+	}
+	if err := namespaceCommand(context.Background(), na, args[0], positional, *dup, *yes, *format, os.Stdout); err != nil {
+		log.Fatalf("namespace %s: %v", args[0], err)
+	}
+}
+
+// runAdminNamespace is `memstore admin namespace`: the same subcommands,
+// run against PostgreSQL in service scope so they cover every user.
+func runAdminNamespace(args []string, out io.Writer) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, namespaceUsage)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("admin namespace "+args[0], flag.ExitOnError)
+	pgDSN := fs.String("pg", "", "PostgreSQL DSN (defaults to MEMSTORE_PG_SECRET / config)")
+	format := fs.String("format", "text", "output format: text|json")
+	dup := fs.String("duplicates", "supersede", "merge: what to do with facts already in the destination (supersede|keep)")
+	yes := fs.Bool("yes", false, "delete: confirm the deletion")
+	positional, err := parseAdminArgs(fs, args[1:])
+	if err != nil {
+		fail(err)
+	}
+
+	pool, closePool, err := openPool(*pgDSN)
+	if err != nil {
+		fail(err)
+	}
+	defer closePool()
+
+	ctx := context.Background()
+	store, err := pgstore.New(ctx, pool, nil, defaultAdminNamespace(), 0, 0)
+	if err != nil {
+		fail(err)
+	}
+	if err := namespaceCommand(ctx, store.ServiceScope(), args[0], positional, *dup, *yes, *format, out); err != nil {
+		fail(fmt.Errorf("admin namespace %s: %w", args[0], err))
+	}
+}
+
+// namespaceCommand runs one namespace subcommand against na and writes its
+// outcome to out.
+func namespaceCommand(ctx context.Context, na memstore.NamespaceAdmin, sub string, positional []string, dup string, yes bool, format string, out io.Writer) error {
+	wantArgs := map[string]int{"list": 0, "rename": 2, "copy": 2, "merge": 2, "delete": 1}
+	n, ok := wantArgs[sub]
+	if !ok {
+		return fmt.Errorf("unknown subcommand\n\n%s", namespaceUsage)
+	}
+	if len(positional) != n {
+		return fmt.Errorf("expected %d positional arguments, got %d\n\n%s", n, len(positional), namespaceUsage)
+	}
+
+	if sub == "list" {
+		infos, err := na.Namespaces(ctx)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			if infos == nil {
+				infos = []memstore.NamespaceInfo{}
+			}
+			return writeJSON(out, infos)
+		}
+		writeNamespacesText(out, infos)
+		return nil
+	}
+
+	var (
+		res *memstore.NamespaceResult
+		err error
+	)
+	switch sub {
+	case "rename":
+		res, err = na.RenameNamespace(ctx, positional[0], positional[1])
+	case "copy":
+		res, err = na.CopyNamespace(ctx, positional[0], positional[1])
+	case "merge":
+		var policy memstore.DuplicatePolicy
+		if policy, err = memstore.ParseDuplicatePolicy(dup); err == nil {
+			res, err = na.MergeNamespace(ctx, positional[0], positional[1], policy)
+		}
+	case "delete":
+		if !yes {
+			return errors.New("deleting a namespace cannot be undone; rerun with --yes")
+		}
+		res, err = na.DeleteNamespace(ctx, positional[0])
+	}
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		return writeJSON(out, res)
+	}
+
+	verb := map[string]string{"rename": "Moved", "copy": "Copied", "merge": "Merged", "delete": "Deleted"}[sub]
+	fmt.Fprintf(out, "%s %d facts and %d links", verb, res.Facts, res.Links)
+	if res.Documents > 0 {
+		fmt.Fprintf(out, " and %d documents", res.Documents)
+	}
+	if sub != "delete" {
+		fmt.Fprintf(out, " from %q to %q", positional[0], positional[1])
+	} else {
+		fmt.Fprintf(out, " in %q", positional[0])
+	}
+	fmt.Fprintf(out, " (audit entry %d).\n", res.AuditID)
+	if res.Duplicates > 0 {
+		fmt.Fprintf(out, "%d duplicates were superseded by the destination's copy.\n", res.Duplicates)
+	}
+	return nil
+}
+
+// writeNamespacesText writes the namespace list as a table.
+func writeNamespacesText(w io.Writer, infos []memstore.NamespaceInfo) {
+	if len(infos) == 0 {
+		fmt.Fprintln(w, "No namespaces hold any data.")
+		return
+	}
+	fmt.Fprintf(w, "%-24s %8s %8s %8s %8s %9s\n", "NAMESPACE", "FACTS", "ACTIVE", "TRASHED", "LINKS", "DOCUMENTS")
+	for _, n := range infos {
+		name := n.Namespace
+		if name == "" {
+			name = `""`
+		}
+		fmt.Fprintf(w, "%-24s %8d %8d %8d %8d %9d\n", name, n.Facts, n.Active, n.Trashed, n.Links, n.Documents)
+	}
+}
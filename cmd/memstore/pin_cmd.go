@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+const pinUsage = `Usage: memstore pin <subcommand> [flags]
+
+Subcommands:
+  list [--cwd <dir>]          List pinned facts, or only those that apply to a session at <dir>.
+  add <id> --subject <s> | --path <dir> [--position N]
+                              Pin a fact for a project subject or directory; re-pinning re-scopes or re-orders it.
+  remove <id>                 Unpin a fact (the pinned fact or any later version of it).`
+
+func runPin(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, pinUsage)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("pin "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	subject := fs.String("subject", "", "add: pin for this project subject")
+	path := fs.String("path", "", "add: pin for sessions in this directory or below")
+	position := fs.Int("position", 0, "add: order among the pins (0 = after the others, or unchanged)")
+	cwd := fs.String("cwd", "", "list: only the pins that apply to a session in this directory")
+	positional, err := parseAdminArgs(fs, args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *path != "" {
+		if *path, err = filepath.Abs(*path); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		if args[0] == "list" {
+			return // DB not initialized yet; nothing is pinned
+		}
+		log.Fatalf("pin: database not found at %s", *dbPath)
+	}
+	defer closeStore()
+
+	p := memstore.Pin{Subject: *subject, ProjectPath: *path, Position: *position}
+	if err := pinCommand(context.Background(), store, args[0], positional, p, *cwd, *format, os.Stdout); err != nil {
+		log.Fatalf("pin %s: %v", args[0], err)
+	}
+}
+
+// pinCommand runs one pin subcommand against store and writes its outcome
+// to out. For add, p carries the pin's scope and position.
+func pinCommand(ctx context.Context, store memstore.Store, sub string, positional []string, p memstore.Pin, cwd, format string, out io.Writer) error {
+	wantArgs := map[string]int{"list": 0, "add": 1, "remove": 1}
+	n, ok := wantArgs[sub]
+	if !ok {
+		return fmt.Errorf("unknown subcommand\n\n%s", pinUsage)
+	}
+	if len(positional) != n {
+		return fmt.Errorf("wrong number of positional arguments\n\n%s", pinUsage)
+	}
+	pn, ok := store.(memstore.Pinner)
+	if !ok {
+		return errors.New("this store does not support pinned facts")
+	}
+
+	switch sub {
+	case "list":
+		var pins []memstore.PinnedFact
+		if cwd != "" {
+			aliases := memstore.LoadAliases(ctx, store)
+			pins = memstore.PinsFor(ctx, store, aliases.Canonical(memstore.ProjectNameFromCWD(cwd)), cwd, aliases)
+		} else {
+			var err error
+			if pins, err = pn.Pins(ctx); err != nil {
+				return err
+			}
+		}
+		if format == "json" {
+			if pins == nil {
+				pins = []memstore.PinnedFact{}
+			}
+			return writeJSON(out, pins)
+		}
+		if len(pins) == 0 {
+			fmt.Fprintln(out, "No pinned facts.")
+		}
+		for _, p := range pins {
+			scope := "subject " + p.Subject
+			if p.ProjectPath != "" {
+				scope = "path " + p.ProjectPath
+			}
+			fmt.Fprintf(out, "%d. [id=%d] %s\n   %s\n", p.Position, p.Fact.ID, scope, p.Fact.Content)
+		}
+	case "add":
+		id, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid fact ID %q", positional[0])
+		}
+		p.FactID = id
+		pin, err := pn.Pin(ctx, p)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			return writeJSON(out, pin)
+		}
+		fmt.Fprintf(out, "Pinned fact %d at position %d.\n", pin.FactID, pin.Position)
+	case "remove":
+		id, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid fact ID %q", positional[0])
+		}
+		if err := pn.Unpin(ctx, id); err != nil {
+			return err
+		}
+		if format != "json" {
+			fmt.Fprintf(out, "Unpinned fact %d.\n", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runRemind(args []string) {
+	fs := flag.NewFlagSet("remind", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	fs.Parse(args)
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing is scheduled
+	}
+	defer closeStore()
+
+	if err := remindCommand(context.Background(), store, *format, os.Stdout); err != nil {
+		log.Fatalf("remind: %v", err)
+	}
+}
+
+// remindCommand surfaces the deferred tasks whose reminder time has passed
+// and writes the ones it surfaced to out. Text output is hook-injectable
+// and empty when nothing came due.
+func remindCommand(ctx context.Context, store memstore.Store, format string, out io.Writer) error {
+	due, err := memstore.EvaluateReminders(ctx, store)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		if due == nil {
+			due = []memstore.Task{}
+		}
+		return writeJSON(out, due)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "[MEMSTORE - Reminders]")
+	for _, t := range due {
+		fmt.Fprintf(out, "• %s (due %s", t.Fact.Content, t.Due)
+		if t.Recur != "" {
+			fmt.Fprintf(out, ", repeats %s", t.Recur)
+		}
+		fmt.Fprintf(out, ", task %d)\n", t.Fact.ID)
+	}
+	return nil
+}
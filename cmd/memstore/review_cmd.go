@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runReview(args []string) {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	limit := fs.Int("limit", memstore.DefaultReviewLimit, "batch size")
+	minAge := fs.String("min-age", "30d", "only facts unconfirmed for at least this long (e.g. 30d, 2w, 720h)")
+	subject := fs.String("subject", "", "restrict to one subject")
+	format := fs.String("format", "text", "output format: text|json")
+	apply := fs.Bool("apply", false, "walk the batch and confirm, supersede or delete each fact interactively")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore review [flags]")
+		fmt.Fprintln(os.Stderr, "Lists active facts due for re-confirmation, highest priority first.")
+		fmt.Fprintln(os.Stderr, "With --apply, each fact can be confirmed, superseded by an edited version, or deleted.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	age, err := memstore.ParseTTL(*minAge)
+	if err != nil {
+		log.Fatalf("review: %v", err)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing to review
+	}
+	defer closeStore()
+
+	rv, ok := store.(memstore.Reviewer)
+	if !ok {
+		log.Fatal("review: this store has no review queue")
+	}
+	ctx := context.Background()
+	items, err := rv.ReviewQueue(ctx, memstore.ReviewOpts{Limit: *limit, MinAge: age, Subject: *subject})
+	if err != nil {
+		log.Fatalf("review: %v", err)
+	}
+
+	if *apply {
+		if err := applyReview(ctx, store, items, bufio.NewReader(os.Stdin), os.Stderr); err != nil {
+			log.Fatalf("review: %v", err)
+		}
+		return
+	}
+	switch *format {
+	case "json":
+		if items == nil {
+			items = []memstore.ReviewItem{}
+		}
+		if err := writeJSON(os.Stdout, items); err != nil {
+			log.Fatalf("review: %v", err)
+		}
+	default:
+		if len(items) == 0 {
+			fmt.Fprintln(os.Stderr, "No facts are due for review.")
+			return
+		}
+		for _, it := range items {
+			writeReviewItem(os.Stdout, it)
+		}
+	}
+}
+
+// writeReviewItem prints one review-queue entry: the fact, its priority and
+// the reasons behind it.
+func writeReviewItem(w io.Writer, it memstore.ReviewItem) {
+	f := it.Fact
+	kind := f.Kind
+	if kind == "" {
+		kind = f.Category
+	}
+	fmt.Fprintf(w, "[id=%d] %s | %s | priority %.2f: %s\n    %s\n", f.ID, f.Subject, kind, it.Priority, strings.Join(it.Reasons, ", "), f.Content)
+}
+
+// applyReview walks items, asking on out and reading answers from in.
+// Superseding opens the fact in $EDITOR and stores the edit as a new version
+// via Store.Revise.
+func applyReview(ctx context.Context, store memstore.Store, items []memstore.ReviewItem, in *bufio.Reader, out io.Writer) error {
+	for _, it := range items {
+		writeReviewItem(out, it)
+		id := it.Fact.ID
+		fmt.Fprint(out, "[c]onfirm, [s]upersede, [d]elete, [n]ext, [q]uit? ")
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "c", "confirm":
+			if err := store.Confirm(ctx, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Confirmed id=%d\n\n", id)
+		case "s", "supersede":
+			content, err := editText(editorCommand(), it.Fact.Content)
+			if err != nil {
+				return err
+			}
+			if content == "" || content == strings.TrimSpace(it.Fact.Content) {
+				fmt.Fprintln(out, "Content unchanged; fact skipped.")
+				continue
+			}
+			newID, err := store.Revise(ctx, id, content, nil)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Superseded id=%d by id=%d\n\n", id, newID)
+		case "d", "delete":
+			if err := store.Delete(ctx, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Deleted id=%d (restorable from the trash)\n\n", id)
+		case "q", "quit":
+			return nil
+		default:
+			fmt.Fprintln(out)
+		}
+	}
+	return nil
+}
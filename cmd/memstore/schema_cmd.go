@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+const schemaUsage = `Usage: memstore schema <subcommand> [flags]
+
+Subcommands:
+  list                          List the kinds with a metadata schema.
+  show <kind>                   Print a kind's metadata schema.
+  audit [--kind <k>]            List active facts whose metadata fails its kind's schema.
+
+Schemas are the built-ins overlaid with the schemas_file config key
+(MEMSTORE_SCHEMAS_FILE). In remote mode audit checks the daemon's facts
+against the local schemas.`
+
+func runSchema(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, schemaUsage)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("schema "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	kind := fs.String("kind", "", "audit: only check facts of this kind")
+	positional, err := parseAdminArgs(fs, args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schemas, err := memstore.LoadSchemas(cliConfig.SchemasFile)
+	if err != nil {
+		log.Fatalf("schema: %v", err)
+	}
+
+	var store memstore.Store
+	if args[0] == "audit" {
+		s, closeStore, err := openStore(*dbPath, *namespace)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if s == nil {
+			return // DB not initialized yet; nothing to audit
+		}
+		defer closeStore()
+		store = s
+	}
+
+	if err := schemaCommand(context.Background(), store, schemas, args[0], positional, *kind, *format, os.Stdout); err != nil {
+		log.Fatalf("schema %s: %v", args[0], err)
+	}
+}
+
+// schemaCommand runs one schema subcommand and writes its outcome to out.
+// store is only used by audit.
+func schemaCommand(ctx context.Context, store memstore.Store, schemas *memstore.SchemaRegistry, sub string, positional []string, kind, format string, out io.Writer) error {
+	switch sub {
+	case "list":
+		if len(positional) != 0 {
+			return fmt.Errorf("wrong number of positional arguments\n\n%s", schemaUsage)
+		}
+		kinds := schemas.Kinds()
+		if format == "json" {
+			if kinds == nil {
+				kinds = []string{}
+			}
+			return writeJSON(out, kinds)
+		}
+		for _, k := range kinds {
+			fmt.Fprintln(out, k)
+		}
+	case "show":
+		if len(positional) != 1 {
+			return fmt.Errorf("want a kind\n\n%s", schemaUsage)
+		}
+		raw := schemas.Schema(positional[0])
+		if raw == nil {
+			return fmt.Errorf("no schema is registered for kind %q", positional[0])
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, buf.String())
+	case "audit":
+		if len(positional) != 0 {
+			return fmt.Errorf("wrong number of positional arguments\n\n%s", schemaUsage)
+		}
+		violations, err := memstore.AuditSchemas(ctx, store, schemas, kind)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			if violations == nil {
+				violations = []memstore.SchemaViolation{}
+			}
+			return writeJSON(out, violations)
+		}
+		if len(violations) == 0 {
+			fmt.Fprintln(out, "Every fact matches its kind's schema.")
+			return nil
+		}
+		for _, v := range violations {
+			fmt.Fprintf(out, "[id=%d] %s | kind=%s\n  %s\n  %s\n\n", v.FactID, v.Subject, v.Kind, strings.TrimSpace(v.Content), v.Reason)
+		}
+		fmt.Fprintf(out, "%d facts fail their schema. Fix them with `memstore edit --metadata` or memory_update.\n", len(violations))
+	default:
+		return fmt.Errorf("unknown subcommand %q\n\n%s", sub, schemaUsage)
+	}
+	return nil
+}
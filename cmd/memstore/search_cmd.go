@@ -23,6 +23,7 @@ func runSearch(args []string) {
 	limit := fs.Int("limit", 5, "max results")
 	onlyActive := fs.Bool("active", true, "exclude superseded facts")
 	hybrid := fs.Bool("hybrid", false, "use hybrid FTS+vector search (requires an embedder)")
+	tags := tagFilterFlags(fs)
 	fs.Parse(args)
 
 	if *query == "" {
@@ -35,6 +36,7 @@ func runSearch(args []string) {
 		Subject:    *subject,
 		Category:   *category,
 		OnlyActive: *onlyActive,
+		Tags:       tags(),
 	}
 
 	var store memstore.Store
@@ -94,8 +96,8 @@ func runSearch(args []string) {
 func writeSearchText(w io.Writer, results []memstore.SearchResult) {
 	for _, r := range results {
 		f := r.Fact
-		fmt.Fprintf(w, "[id=%d] %s | %s | %s\n  %s\n\n",
-			f.ID, f.Subject, f.Category, f.CreatedAt.Format("2006-01-02"),
+		fmt.Fprintf(w, "[id=%d] %s | %s | %s%s\n  %s\n\n",
+			f.ID, f.Subject, f.Category, f.CreatedAt.Format("2006-01-02"), tagSuffix(f.Tags),
 			f.Content)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/matthewjhunter/memstore"
 )
@@ -21,6 +22,8 @@ func runStore(args []string) {
 	kind := fs.String("kind", "", "structural type (convention, failure_mode, invariant, pattern, decision, trigger)")
 	subsystem := fs.String("subsystem", "", "project subsystem (e.g. feeds, auth)")
 	metadataStr := fs.String("metadata", "", `JSON metadata object (e.g. '{"key":"val"}')`)
+	ttl := fs.String("ttl", "", "time-to-live after which the fact stops being active (e.g. 3d, 2w, 36h)")
+	tags := fs.String("tags", "", "comma-separated tags to attach (e.g. perf,todo)")
 	var supersedes int64
 	fs.Int64Var(&supersedes, "supersedes", 0, "ID of the fact this replaces")
 	fs.Parse(args)
@@ -37,6 +40,15 @@ func runStore(args []string) {
 			log.Fatalf("store: invalid --metadata JSON: %v", err)
 		}
 	}
+	var expiresAt *time.Time
+	if *ttl != "" {
+		d, err := memstore.ParseTTL(*ttl)
+		if err != nil {
+			log.Fatalf("store: --ttl: %v", err)
+		}
+		t := time.Now().UTC().Add(d)
+		expiresAt = &t
+	}
 
 	store, closeStore, err := openStore(*dbPath, *namespace)
 	if err != nil {
@@ -53,6 +65,8 @@ func runStore(args []string) {
 		Category:  *category,
 		Kind:      *kind,
 		Subsystem: *subsystem,
+		ExpiresAt: expiresAt,
+		Tags:      tagList(*tags),
 	}
 	if len(meta) > 0 {
 		raw, _ := json.Marshal(meta)
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+const tagUsage = `Usage: memstore tag <subcommand> [flags]
+
+Subcommands:
+  list [--subject <s>]        List the tags on active facts with how many facts carry each.
+  add <id> <tag>...           Attach tags to a fact.
+  remove <id> <tag>...        Detach tags from a fact.
+  rename <from> <to>          Rename a tag on every fact, merging it into <to> where both are present.
+  delete <tag>                Remove a tag from every fact.`
+
+func runTag(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tagUsage)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("tag "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	subject := fs.String("subject", "", "list: only count facts about this subject")
+	positional, err := parseAdminArgs(fs, args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		if args[0] == "list" {
+			return // DB not initialized yet; nothing is tagged
+		}
+		log.Fatalf("tag: database not found at %s", *dbPath)
+	}
+	defer closeStore()
+
+	if err := tagCommand(context.Background(), store, args[0], positional, *subject, *format, os.Stdout); err != nil {
+		log.Fatalf("tag %s: %v", args[0], err)
+	}
+}
+
+// tagCommand runs one tag subcommand against store and writes its outcome
+// to out.
+func tagCommand(ctx context.Context, store memstore.Store, sub string, positional []string, subject, format string, out io.Writer) error {
+	switch sub {
+	case "list":
+		if len(positional) != 0 {
+			return fmt.Errorf("wrong number of positional arguments\n\n%s", tagUsage)
+		}
+		counts, err := store.TagCounts(ctx, subject)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			if counts == nil {
+				counts = []memstore.TagCount{}
+			}
+			return writeJSON(out, counts)
+		}
+		if len(counts) == 0 {
+			fmt.Fprintln(out, "No tags in use.")
+		}
+		for _, tc := range counts {
+			fmt.Fprintf(out, "%-24s %d\n", tc.Tag, tc.Count)
+		}
+	case "add", "remove":
+		if len(positional) < 2 {
+			return fmt.Errorf("want a fact ID and at least one tag\n\n%s", tagUsage)
+		}
+		id, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid fact ID %q", positional[0])
+		}
+		if sub == "add" {
+			err = store.TagFact(ctx, id, positional[1:]...)
+		} else {
+			err = store.UntagFact(ctx, id, positional[1:]...)
+		}
+		if err != nil {
+			return err
+		}
+		f, err := store.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return fmt.Errorf("fact %d not found", id)
+		}
+		if format == "json" {
+			tags := f.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			return writeJSON(out, map[string]any{"id": id, "tags": tags})
+		}
+		fmt.Fprintf(out, "Fact %d tags: %s\n", id, strings.Join(f.Tags, ", "))
+	case "rename", "delete":
+		want := map[string]int{"rename": 2, "delete": 1}[sub]
+		if len(positional) != want {
+			return fmt.Errorf("wrong number of positional arguments\n\n%s", tagUsage)
+		}
+		var (
+			n   int64
+			err error
+		)
+		if sub == "rename" {
+			n, err = store.RenameTag(ctx, positional[0], positional[1])
+		} else {
+			n, err = store.DeleteTag(ctx, positional[0])
+		}
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			return writeJSON(out, map[string]any{"facts": n})
+		}
+		if sub == "rename" {
+			fmt.Fprintf(out, "Renamed %q to %q on %d facts.\n", positional[0], positional[1], n)
+		} else {
+			fmt.Fprintf(out, "Removed %q from %d facts.\n", positional[0], n)
+		}
+	default:
+		return errors.New("unknown subcommand\n\n" + tagUsage)
+	}
+	return nil
+}
+
+// tagList splits a comma-separated --tag flag value.
+func tagList(v string) []string {
+	var tags []string
+	for part := range strings.SplitSeq(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return tags
+}
+
+// tagFilterFlags registers the --tag, --tag-all and --tag-none filter flags
+// on fs and returns a func that builds the filter once fs is parsed.
+func tagFilterFlags(fs *flag.FlagSet) func() memstore.TagFilter {
+	anyOf := fs.String("tag", "", "only facts carrying at least one of these comma-separated tags")
+	allOf := fs.String("tag-all", "", "only facts carrying every one of these comma-separated tags")
+	noneOf := fs.String("tag-none", "", "exclude facts carrying any of these comma-separated tags")
+	return func() memstore.TagFilter {
+		return memstore.TagFilter{Any: tagList(*anyOf), All: tagList(*allOf), None: tagList(*noneOf)}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/matthewjhunter/memstore"
 )
@@ -21,6 +22,9 @@ func runTasks(args []string) {
 	status := fs.String("status", "", "filter by status (pending|in_progress|completed|cancelled)")
 	scope := fs.String("scope", "", "filter by scope (matthew|claude|collaborative)")
 	project := fs.String("project", "", "filter by project name")
+	owner := fs.String("owner", "", "filter by owner")
+	state := fs.String("state", "", "filter by derived state (blocked|ready|in_progress|done|overdue|due_soon)")
+	groupBy := fs.String("group-by", "", "group into a board: state|project|owner|priority|scope")
 	fs.Parse(args)
 
 	store, closeStore, err := openStore(*dbPath, *namespace)
@@ -32,9 +36,7 @@ func runTasks(args []string) {
 	}
 	defer closeStore()
 
-	filters := []memstore.MetadataFilter{
-		{Key: "kind", Op: "=", Value: "task"},
-	}
+	var filters []memstore.MetadataFilter
 	if *surface != "" {
 		filters = append(filters, memstore.MetadataFilter{Key: "surface", Op: "=", Value: *surface})
 	}
@@ -47,22 +49,111 @@ func runTasks(args []string) {
 	if *project != "" {
 		filters = append(filters, memstore.MetadataFilter{Key: "project", Op: "=", Value: *project})
 	}
+	if *owner != "" {
+		filters = append(filters, memstore.MetadataFilter{Key: "owner", Op: "=", Value: *owner})
+	}
+	if err := tasksCommand(context.Background(), store, filters, *state, *groupBy, *format, os.Stdout); err != nil {
+		log.Fatalf("tasks: %v", err)
+	}
+}
 
-	facts, err := store.List(context.Background(), memstore.QueryOpts{
-		OnlyActive:      true,
-		MetadataFilters: filters,
-	})
+// tasksCommand lists the tasks matching filters, narrowed to a derived
+// state when state is set, and writes them to out as a flat list or, with
+// groupBy, as a board.
+func tasksCommand(ctx context.Context, store memstore.Store, filters []memstore.MetadataFilter, state, groupBy, format string, out io.Writer) error {
+	all, err := memstore.LoadTasks(ctx, store, filters...)
 	if err != nil {
-		log.Fatalf("tasks: %v", err)
+		return err
+	}
+	var tasks []memstore.Task
+	for _, t := range all {
+		switch state {
+		case "":
+		case "overdue":
+			if !t.Overdue {
+				continue
+			}
+		case "due_soon":
+			if !t.DueSoon {
+				continue
+			}
+		case string(memstore.TaskStateBlocked), string(memstore.TaskStateReady), string(memstore.TaskStateInProgress), string(memstore.TaskStateDone):
+			if string(t.State) != state {
+				continue
+			}
+		default:
+			return fmt.Errorf("unknown state %q (want blocked, ready, in_progress, done, overdue or due_soon)", state)
+		}
+		tasks = append(tasks, t)
 	}
 
-	switch *format {
-	case "json":
-		if err := writeJSON(os.Stdout, facts); err != nil {
-			log.Fatalf("tasks: %v", err)
+	if groupBy != "" {
+		groups, err := memstore.GroupTasks(tasks, groupBy)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			if groups == nil {
+				groups = []memstore.TaskGroup{}
+			}
+			return writeJSON(out, groups)
+		}
+		writeTaskBoard(out, groups)
+		return nil
+	}
+
+	facts := make([]memstore.Fact, len(tasks))
+	for i, t := range tasks {
+		facts[i] = t.Fact
+	}
+	if format == "json" {
+		return writeJSON(out, facts)
+	}
+	writeTasksText(out, facts)
+	return nil
+}
+
+// writeTaskBoard writes grouped tasks as a plain-text board, flagging
+// overdue tasks and the open tasks each blocked one waits on.
+func writeTaskBoard(w io.Writer, groups []memstore.TaskGroup) {
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%d)\n", strings.ToUpper(g.Name), len(g.Tasks))
+		for _, t := range g.Tasks {
+			fmt.Fprintf(w, "  [%d] %s", t.Fact.ID, t.Fact.Content)
+			var notes []string
+			if t.Priority == "high" {
+				notes = append(notes, "high")
+			}
+			if t.Owner != "" {
+				notes = append(notes, "owner: "+t.Owner)
+			}
+			if t.Due != "" {
+				notes = append(notes, "due: "+t.Due)
+			}
+			if t.Overdue {
+				notes = append(notes, "OVERDUE")
+			}
+			if t.DueSoon {
+				notes = append(notes, "due soon")
+			}
+			if t.Recur != "" {
+				notes = append(notes, "repeats "+t.Recur)
+			}
+			if t.State == memstore.TaskStateBlocked {
+				ids := make([]string, len(t.BlockedBy))
+				for j, id := range t.BlockedBy {
+					ids[j] = fmt.Sprint(id)
+				}
+				notes = append(notes, "blocked by "+strings.Join(ids, ", "))
+			}
+			if len(notes) > 0 {
+				fmt.Fprintf(w, " (%s)", strings.Join(notes, "; "))
+			}
+			fmt.Fprintln(w)
 		}
-	default:
-		writeTasksText(os.Stdout, facts)
 	}
 }
 
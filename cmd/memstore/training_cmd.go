@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/pgstore"
+)
+
+// --- export-training ---
+
+// runExportTraining writes the retrieval logs as a JSONL training dataset
+// (docs/training-data-design.md). Without --user it reads every user's logs
+// through the session store's service scope; the dataset goes to --out or
+// stdout, and the line count to stderr so stdout stays pure JSONL.
+func runExportTraining(args []string, out io.Writer) {
+	fs := flag.NewFlagSet("export-training", flag.ExitOnError)
+	pgDSN := fs.String("pg", "", "PostgreSQL DSN (defaults to MEMSTORE_PG_SECRET / config)")
+	namespace := fs.String("namespace", defaultAdminNamespace(), namespaceFlagUsage)
+	userName := fs.String("user", "", "export only this user's logs (default: all users)")
+	format := fs.String("format", string(memstore.TrainingPointwise), "dataset shape: pointwise, pairwise, or listwise")
+	rankerVersion := fs.String("ranker-version", "", "only events produced by this ranker version (default: all)")
+	since := fs.String("since", "", "only events at or after this time (RFC 3339 or YYYY-MM-DD)")
+	until := fs.String("until", "", "only events before this time (RFC 3339 or YYYY-MM-DD)")
+	limit := fs.Int("limit", 0, "max retrieval events, oldest first (0 = all)")
+	minMargin := fs.Float64("min-margin", 0, "pairwise: drop pairs whose label gap is below this")
+	maxPerQuery := fs.Int("max-pairs-per-query", 0, "pairwise: keep at most this many widest-margin pairs per event (0 = no cap)")
+	maxPerDoc := fs.Int("max-pairs-per-doc", 0, "pairwise: cap the pairs any one fact may appear in (0 = no cap)")
+	outPath := fs.String("out", "", "write the dataset here instead of stdout")
+	if _, err := parseAdminArgs(fs, args); err != nil {
+		fail(err)
+	}
+
+	f, err := memstore.ParseTrainingFormat(*format)
+	if err != nil {
+		fail(err)
+	}
+	filter := memstore.TrainingFilter{RankerVersion: *rankerVersion, Limit: *limit}
+	if filter.Since, err = memstore.ParseTrainingTime(*since); err != nil {
+		fail(fmt.Errorf("export-training: --since: %w", err))
+	}
+	if filter.Until, err = memstore.ParseTrainingTime(*until); err != nil {
+		fail(fmt.Errorf("export-training: --until: %w", err))
+	}
+
+	pool, closePool, err := openPool(*pgDSN)
+	if err != nil {
+		fail(err)
+	}
+	defer closePool()
+
+	ctx := context.Background()
+	ss, err := pgstore.NewSessionStore(ctx, pool)
+	if err != nil {
+		fail(err)
+	}
+	var src memstore.TrainingSource = ss.ServiceScope()
+	if *userName != "" {
+		uid, err := pgstore.LookupUserID(ctx, pool, *namespace, *userName)
+		if err != nil {
+			fail(err)
+		}
+		scoped, err := ss.ForUser(uid)
+		if err != nil {
+			fail(err)
+		}
+		src = scoped.(memstore.TrainingSource)
+	}
+
+	events, err := src.TrainingEvents(ctx, filter)
+	if err != nil {
+		fail(err)
+	}
+
+	w := out
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+		w = file
+	}
+	n, err := memstore.WriteTrainingData(w, events, memstore.TrainingExportOpts{
+		Format:           f,
+		MinMargin:        *minMargin,
+		MaxPairsPerQuery: *maxPerQuery,
+		MaxPairsPerDoc:   *maxPerDoc,
+	})
+	if err != nil {
+		fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d %s example(s) from %d retrieval event(s).\n", n, f, len(events))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func runTrash(args []string) {
+	fs := flag.NewFlagSet("trash", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	format := fs.String("format", "text", "output format: text|json")
+	subject := fs.String("subject", "", "filter by subject")
+	limit := fs.Int("limit", 0, "max results (0 = no limit)")
+	fs.Parse(args)
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing has been deleted
+	}
+	defer closeStore()
+
+	facts, err := store.Trash(context.Background(), memstore.QueryOpts{Subject: *subject, Limit: *limit})
+	if err != nil {
+		log.Fatalf("trash: %v", err)
+	}
+
+	switch *format {
+	case "json":
+		if err := writeJSON(os.Stdout, facts); err != nil {
+			log.Fatalf("trash: %v", err)
+		}
+	default:
+		for _, f := range facts {
+			var deleted string
+			if f.DeletedAt != nil {
+				deleted = f.DeletedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("[id=%d] %s | %s | deleted %s\n  %s\n\n", f.ID, f.Subject, f.Category, deleted, f.Content)
+		}
+	}
+}
+
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: memstore restore [flags] <id>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	ids := make([]int64, fs.NArg())
+	for i, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			log.Fatalf("restore: invalid fact ID %q", arg)
+		}
+		ids[i] = id
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		log.Fatalf("restore: database not found at %s", *dbPath)
+	}
+	defer closeStore()
+
+	for _, id := range ids {
+		if err := store.Restore(context.Background(), id); err != nil {
+			log.Fatalf("restore: %v", err)
+		}
+		fmt.Printf("restored %d\n", id)
+	}
+}
+
+func runPurge(args []string) {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	dbPath := fs.String("db", cliConfig.DB, "path to memstore database")
+	namespace := fs.String("namespace", cliConfig.Namespace, "namespace")
+	olderThan := fs.String("older-than", "", "purge facts deleted longer ago than this (e.g. 30d, 2w, 36h)")
+	all := fs.Bool("all", false, "purge the whole trash regardless of age")
+	fs.Parse(args)
+
+	if (*olderThan == "") == !*all {
+		fmt.Fprintln(os.Stderr, "purge: exactly one of --older-than or --all is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+	before := time.Now()
+	if *olderThan != "" {
+		d, err := memstore.ParseTTL(*olderThan)
+		if err != nil {
+			log.Fatalf("purge: --older-than: %v", err)
+		}
+		before = before.Add(-d)
+	}
+
+	store, closeStore, err := openStore(*dbPath, *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if store == nil {
+		return // DB not initialized yet; nothing to purge
+	}
+	defer closeStore()
+
+	n, err := store.Purge(context.Background(), before)
+	if err != nil {
+		log.Fatalf("purge: %v", err)
+	}
+	fmt.Printf("purged %d facts\n", n)
+}
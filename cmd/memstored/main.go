@@ -64,6 +64,10 @@ func run(ctx context.Context, args []string, stderr io.Writer, onListening func(
 	genURL := fs.String("gen-url", cfg.GenURL, "separate LLM URL for generation (defaults to --ollama)")
 	embedInterval := fs.Duration("embed-interval", 2*time.Second, "embed queue poll interval")
 	embedBatch := fs.Int("embed-batch", 32, "embed queue batch size")
+	experimentsFile := fs.String("experiments", cfg.ExperimentsFile,
+		"JSON file of online ranking experiments to run (empty = none; running ones are stopped)")
+	schemasFile := fs.String("schemas", cfg.SchemasFile,
+		"JSON file of per-kind metadata schemas layered over the built-ins (empty = built-ins only)")
 	tlsCertFile := fs.String("tls-cert-file", cfg.TLSCertFile, "TLS certificate file (PEM)")
 	tlsKeyFile := fs.String("tls-key-file", cfg.TLSKeyFile, "TLS private key file (PEM)")
 	tlsClientCA := fs.String("tls-client-ca-file", cfg.TLSClientCAFile,
@@ -114,8 +118,34 @@ func run(ctx context.Context, args []string, stderr io.Writer, onListening func(
 	if err != nil {
 		return fmt.Errorf("init postgres store: %w", err)
 	}
+	queryCachePolicy, err := memstore.QueryCachePolicyFromEnv("MEMSTORE_QUERY_CACHE_PERSIST")
+	if err != nil {
+		return err
+	}
+	pgStore.SetQueryCache(queryCachePolicy)
+	schemas, err := memstore.LoadSchemas(*schemasFile)
+	if err != nil {
+		return err
+	}
+	pgStore.SetSchemas(schemas)
+	expiryPolicy, err := memstore.ExpiryPolicyFromEnv("MEMSTORE_EXPIRY")
+	if err != nil {
+		return err
+	}
+	trashPolicy, err := memstore.TrashPolicyFromEnv("MEMSTORE_TRASH")
+	if err != nil {
+		return err
+	}
+	contradictionPolicy, err := memstore.ContradictionPolicyFromEnv("MEMSTORE_CONTRADICTION_AUDIT")
+	if err != nil {
+		return err
+	}
+	reminderPolicy, err := memstore.ReminderPolicyFromEnv("MEMSTORE_REMINDERS")
+	if err != nil {
+		return err
+	}
 	var store memstore.Store = pgStore
-	log.Printf("using PostgreSQL store (dim=%d, query-cache=%d)", *vecDim, cacheSize)
+	log.Printf("using PostgreSQL store (dim=%d, query-cache=%d, persistent-query-cache=%d)", *vecDim, cacheSize, max(queryCachePolicy.MaxEntries, 0))
 
 	rr, rcfg, err := memstore.RerankerFromEnv("MEMSTORE_RERANK")
 	if err != nil {
@@ -170,6 +200,64 @@ func run(ctx context.Context, args []string, stderr io.Writer, onListening func(
 		log.Printf("session store init failed: %v", err)
 	}
 
+	// Rating history scales recall scores and, when MEMSTORE_FEEDBACK_SEARCH
+	// is true, Store.Search results too, under one policy.
+	feedbackPolicy, err := memstore.FeedbackPolicyFromEnv("MEMSTORE_FEEDBACK")
+	if err != nil {
+		return err
+	}
+	handlerOpts = append(handlerOpts, httpapi.WithFeedbackPolicy(feedbackPolicy))
+	searchFeedback, err := searchFeedbackEnabled()
+	if err != nil {
+		return err
+	}
+	if sessionStore != nil && searchFeedback {
+		pgStore.SetFeedback(sessionStore, feedbackPolicy)
+		halfLife := "off"
+		if feedbackPolicy.HalfLife > 0 {
+			halfLife = feedbackPolicy.HalfLife.String()
+		}
+		log.Printf("search feedback enabled (base=%.2f, cap=%.0f, max-factor=%.1f, half-life=%s)",
+			feedbackPolicy.BaseWeight, feedbackPolicy.ConfidenceCap, feedbackPolicy.MaxFactor, halfLife)
+	}
+
+	// Position-randomized exploration in recall, for debiasing feedback.
+	// Off unless MEMSTORE_EXPLORE_* set a rate.
+	exploration, err := explorationPolicy()
+	if err != nil {
+		return err
+	}
+	if exploration.Enabled() {
+		handlerOpts = append(handlerOpts, httpapi.WithExploration(exploration))
+		log.Printf("recall exploration enabled (swap=%.3f, top-k=%d, epsilon=%.3f)",
+			exploration.SwapRate, exploration.SwapTopK, exploration.Epsilon)
+	}
+
+	// Online ranking experiments. The configured definitions are synced into
+	// the ranking_experiments table, which recall and hint generation poll, so
+	// `memstore admin stop-experiment` takes effect without a restart.
+	var experiments []memstore.Experiment
+	if *experimentsFile != "" {
+		if experiments, err = memstore.LoadExperiments(*experimentsFile); err != nil {
+			return err
+		}
+	}
+	es, err := pgstore.NewExperimentStore(ctx, pgPool, *namespace)
+	if err != nil {
+		return fmt.Errorf("init experiment store: %w", err)
+	}
+	started, stopped, err := es.Sync(ctx, experiments)
+	if err != nil {
+		return err
+	}
+	for _, name := range started {
+		log.Printf("experiment %s: started", name)
+	}
+	for _, name := range stopped {
+		log.Printf("experiment %s: stopped (removed from config)", name)
+	}
+	handlerOpts = append(handlerOpts, httpapi.WithExperiments(es))
+
 	// Token-based auth. Bootstrap from MEMSTORE_API_KEY if set so existing
 	// single-key deployments keep working without operator action.
 	ts, err := pgstore.NewTokenStore(ctx, pgPool)
@@ -186,6 +274,7 @@ func run(ctx context.Context, args []string, stderr io.Writer, onListening func(
 	handlerOpts = append(handlerOpts, httpapi.WithTokenVerifier(tokenVerifier{ts}))
 	log.Printf("bearer-token auth enabled (api_tokens table)")
 	var xq *httpapi.ExtractQueue
+	var auditGen memstore.JSONSchemaGenerator
 	if *genModel != "" {
 		genBaseURL := *ollamaURL
 		if *genURL != "" {
@@ -194,8 +283,10 @@ func run(ctx context.Context, args []string, stderr io.Writer, onListening func(
 		gen := memstore.NewOpenAIGenerator(genBaseURL, *llmAPIKey, *genModel)
 		handlerOpts = append(handlerOpts, httpapi.WithGenerator(gen))
 		log.Printf("generation enabled (model=%s, url=%s)", *genModel, genBaseURL)
+		auditGen = gen
 		if sessionStore != nil {
 			xq = httpapi.NewExtractQueue(store, embedder, gen, sessionStore)
+			xq.SetExperiments(es)
 			xq.Start()
 			handlerOpts = append(handlerOpts, httpapi.WithExtractQueue(xq))
 			log.Printf("extract queue enabled with hint generation (gen-model=%s)", *genModel)
@@ -243,6 +334,49 @@ func run(ctx context.Context, args []string, stderr io.Writer, onListening func(
 	eq.Start()
 	defer eq.Stop()
 
+	// Document chunks embed on the same terms: ingest stores them unembedded
+	// and this queue fills them in behind it, across all users.
+	cq := httpapi.NewChunkEmbedQueue(pgStore.ServiceScope(), embedder, *embedInterval, *embedBatch)
+	cq.Start()
+	defer cq.Stop()
+
+	// Expiry is enforced at query time; the reaper archives or deletes what
+	// has expired, across all users.
+	if expiryPolicy.Enabled() {
+		reaper := memstore.NewExpirySweeper(pgStore.ServiceScope(), expiryPolicy, log.Printf)
+		reaper.Start()
+		defer reaper.Stop()
+	}
+
+	// Deleted facts are purged once they outlive the trash retention, across
+	// all users.
+	if trashPolicy.Enabled() {
+		purger := memstore.NewTrashPurger(pgStore.ServiceScope(), trashPolicy, log.Printf)
+		purger.Start()
+		defer purger.Stop()
+	}
+
+	// Recurring and scheduled tasks surface when their reminder time comes,
+	// across all users.
+	if reminderPolicy.Enabled() {
+		reminders := memstore.NewReminderEvaluator(pgStore.ServiceScope(), reminderPolicy, log.Printf)
+		reminders.Start()
+		defer reminders.Stop()
+	}
+
+	// The contradiction audit costs an LLM call per candidate pair, so it
+	// runs only when explicitly enabled. It pairs facts within one user.
+	if contradictionPolicy.Enabled {
+		if auditGen == nil {
+			log.Printf("contradiction audit disabled: requires --gen-model")
+		} else {
+			auditor := memstore.NewContradictionAuditor(pgStore.ServiceScope(), auditGen, contradictionPolicy, log.Printf)
+			auditor.Start()
+			defer auditor.Stop()
+			log.Printf("contradiction audit enabled (model=%s)", auditGen.Model())
+		}
+	}
+
 	srv := &http.Server{
 		Addr:              *addr,
 		Handler:           handler,
@@ -330,6 +464,51 @@ func queryCacheSize() (int, error) {
 	return n, nil
 }
 
+// searchFeedbackEnabled reports whether Store.Search applies the feedback
+// stage: MEMSTORE_FEEDBACK_SEARCH, default false, so upgrading leaves search
+// ranking alone until it is turned on.
+func searchFeedbackEnabled() (bool, error) {
+	v := os.Getenv("MEMSTORE_FEEDBACK_SEARCH")
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid MEMSTORE_FEEDBACK_SEARCH %q: must be a boolean", v)
+	}
+	return b, nil
+}
+
+// explorationPolicy reads the recall exploration policy from
+// MEMSTORE_EXPLORE_SWAP_RATE, MEMSTORE_EXPLORE_SWAP_TOP_K, and
+// MEMSTORE_EXPLORE_EPSILON. Unset, it never explores.
+func explorationPolicy() (httpapi.ExplorationPolicy, error) {
+	var p httpapi.ExplorationPolicy
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"MEMSTORE_EXPLORE_SWAP_RATE", &p.SwapRate},
+		{"MEMSTORE_EXPLORE_EPSILON", &p.Epsilon},
+	} {
+		if v := os.Getenv(f.name); v != "" {
+			x, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return p, fmt.Errorf("invalid %s %q: must be a number", f.name, v)
+			}
+			*f.dst = x
+		}
+	}
+	if v := os.Getenv("MEMSTORE_EXPLORE_SWAP_TOP_K"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return p, fmt.Errorf("invalid MEMSTORE_EXPLORE_SWAP_TOP_K %q: must be an integer", v)
+		}
+		p.SwapTopK = n
+	}
+	return p, p.Validate()
+}
+
 // loadClientCAs reads a PEM bundle and returns a CertPool suitable for
 // tls.Config.ClientCAs.
 func loadClientCAs(path string) (*x509.CertPool, error) {
@@ -36,6 +36,14 @@ type AppConfig struct {
 	PG     string
 	VecDim int // embedding vector dimension for Postgres (e.g. 768)
 
+	// ExperimentsFile is a JSON array of online ranking experiments for
+	// memstored to run (see LoadExperiments and docs/ranking-experiments.md).
+	ExperimentsFile string
+
+	// SchemasFile is a JSON object of per-kind metadata schemas layered over
+	// the built-in ones (see LoadSchemas and docs/kind-schemas.md).
+	SchemasFile string
+
 	// TLS configuration for memstored (server side). The daemon requires TLS
 	// by default; TLSDisabled is the explicit opt-out for proxy-fronted
 	// deployments.
@@ -217,6 +225,10 @@ func LoadConfig() AppConfig {
 					if cfg.PG == "" {
 						cfg.PG = value
 					}
+				case "experiments_file":
+					cfg.ExperimentsFile = expandTilde(value)
+				case "schemas_file":
+					cfg.SchemasFile = expandTilde(value)
 				case "tls_cert_file":
 					cfg.TLSCertFile = expandTilde(value)
 				case "tls_key_file":
@@ -274,6 +286,12 @@ func LoadConfig() AppConfig {
 		cfg.PG = v
 		warnDeprecatedPGEnv()
 	}
+	if v := os.Getenv("MEMSTORE_EXPERIMENTS_FILE"); v != "" {
+		cfg.ExperimentsFile = expandTilde(v)
+	}
+	if v := os.Getenv("MEMSTORE_SCHEMAS_FILE"); v != "" {
+		cfg.SchemasFile = expandTilde(v)
+	}
 	if v := os.Getenv("MEMSTORE_TLS_CERT_FILE"); v != "" {
 		cfg.TLSCertFile = expandTilde(v)
 	}
@@ -0,0 +1,383 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/matthewjhunter/go-embedding"
+)
+
+// Dedupe defaults. The two thresholds are on different scales: cosine
+// similarity of embeddings of near-identical claims sits well above 0.9,
+// while the Jaccard overlap of two phrasings of one claim rarely reaches it;
+// a paraphrase that swaps one word in a dozen already scores about 0.85.
+const (
+	DefaultDedupeThreshold        = 0.92
+	DefaultDedupeLexicalThreshold = 0.6
+	DefaultDedupeNeighbors        = 8
+)
+
+// Methods a DuplicatePair can be scored by.
+const (
+	DedupeEmbedding = "embedding" // cosine similarity of the stored embeddings
+	DedupeLexical   = "lexical"   // Jaccard overlap of content terms (FTS fallback)
+)
+
+// DedupeOpts configures a store-wide near-duplicate scan.
+type DedupeOpts struct {
+	Threshold        float64 // minimum cosine similarity for an embedded pair; 0 = DefaultDedupeThreshold
+	LexicalThreshold float64 // minimum term overlap for a pair found by the FTS fallback; 0 = DefaultDedupeLexicalThreshold
+	Neighbors        int     // nearest neighbours examined per fact; 0 = DefaultDedupeNeighbors
+	Limit            int     // max clusters returned, best first; 0 = all
+}
+
+func (o DedupeOpts) withDefaults() DedupeOpts {
+	if o.Threshold <= 0 {
+		o.Threshold = DefaultDedupeThreshold
+	}
+	if o.LexicalThreshold <= 0 {
+		o.LexicalThreshold = DefaultDedupeLexicalThreshold
+	}
+	if o.Neighbors <= 0 {
+		o.Neighbors = DefaultDedupeNeighbors
+	}
+	return o
+}
+
+// DuplicatePair is one pair of facts found to be near-duplicates.
+type DuplicatePair struct {
+	A, B       int64   // A < B
+	Similarity float64 // cosine for DedupeEmbedding, Jaccard for DedupeLexical
+	Method     string
+}
+
+// DuplicateCluster is a connected group of near-duplicate facts.
+type DuplicateCluster struct {
+	Facts      []Fact          // newest first, embeddings stripped
+	Pairs      []DuplicatePair // the pairs that connect them, most similar first
+	Similarity float64         // highest pair similarity in the cluster
+}
+
+// Deduper is optionally implemented by stores that can scan every active
+// fact in their scope for near-duplicates, across subjects. Embedded facts
+// are compared through a nearest-neighbour index, never all-pairs;
+// facts still waiting for an embedding fall back to full-text candidates
+// scored by term overlap.
+type Deduper interface {
+	FindDuplicates(ctx context.Context, opts DedupeOpts) ([]DuplicateCluster, error)
+}
+
+// DedupeTerms returns the distinct lower-cased words of content that are
+// long enough to carry meaning, in first-occurrence order. It is the term
+// set LexicalSimilarity compares and the FTS fallback queries with.
+func DedupeTerms(content string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) < 3 || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// LexicalSimilarity is the Jaccard overlap of the DedupeTerms of a and b.
+func LexicalSimilarity(a, b string) float64 {
+	ta, tb := DedupeTerms(a), DedupeTerms(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	inA := make(map[string]bool, len(ta))
+	for _, t := range ta {
+		inA[t] = true
+	}
+	shared := 0
+	for _, t := range tb {
+		if inA[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// ClusterDuplicates groups pairs into connected clusters and attaches the
+// facts, which must include every ID the pairs mention. A pair reported
+// more than once keeps its highest similarity. Clusters are ordered by
+// their best similarity, then size, and cut to limit when limit > 0.
+func ClusterDuplicates(pairs []DuplicatePair, facts map[int64]Fact, limit int) []DuplicateCluster {
+	best := make(map[[2]int64]DuplicatePair, len(pairs))
+	for _, p := range pairs {
+		if p.A > p.B {
+			p.A, p.B = p.B, p.A
+		}
+		if p.A == p.B {
+			continue
+		}
+		k := [2]int64{p.A, p.B}
+		if cur, ok := best[k]; !ok || p.Similarity > cur.Similarity {
+			best[k] = p
+		}
+	}
+
+	parent := make(map[int64]int64)
+	var find func(int64) int64
+	find = func(x int64) int64 {
+		p, ok := parent[x]
+		if !ok || p == x {
+			parent[x] = x
+			return x
+		}
+		r := find(p)
+		parent[x] = r
+		return r
+	}
+	for k := range best {
+		if ra, rb := find(k[0]), find(k[1]); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	byRoot := make(map[int64]*DuplicateCluster)
+	for _, p := range best {
+		root := find(p.A)
+		c := byRoot[root]
+		if c == nil {
+			c = &DuplicateCluster{}
+			byRoot[root] = c
+		}
+		c.Pairs = append(c.Pairs, p)
+		c.Similarity = math.Max(c.Similarity, p.Similarity)
+	}
+	for id := range parent {
+		c := byRoot[find(id)]
+		f := facts[id]
+		f.Embedding = nil
+		c.Facts = append(c.Facts, f)
+	}
+
+	out := make([]DuplicateCluster, 0, len(byRoot))
+	for _, c := range byRoot {
+		sort.Slice(c.Pairs, func(i, j int) bool {
+			if c.Pairs[i].Similarity != c.Pairs[j].Similarity {
+				return c.Pairs[i].Similarity > c.Pairs[j].Similarity
+			}
+			return c.Pairs[i].A < c.Pairs[j].A || (c.Pairs[i].A == c.Pairs[j].A && c.Pairs[i].B < c.Pairs[j].B)
+		})
+		sort.Slice(c.Facts, func(i, j int) bool {
+			if !c.Facts[i].CreatedAt.Equal(c.Facts[j].CreatedAt) {
+				return c.Facts[i].CreatedAt.After(c.Facts[j].CreatedAt)
+			}
+			return c.Facts[i].ID > c.Facts[j].ID
+		})
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Similarity != out[j].Similarity {
+			return out[i].Similarity > out[j].Similarity
+		}
+		if len(out[i].Facts) != len(out[j].Facts) {
+			return len(out[i].Facts) > len(out[j].Facts)
+		}
+		return out[i].Facts[0].ID > out[j].Facts[0].ID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Random-hyperplane LSH parameters for the SQLite dedupe scan. A pair at
+// cosine 0.92 shares a 16-bit signature in a given table with probability
+// ~0.11, so across 24 tables it is found ~94% of the time, and above 0.95
+// more than 99%; a loosely related pair at 0.6 meets in any table under 9%
+// of the time, and unrelated facts almost never.
+const (
+	lshBits   = 16
+	lshTables = 24
+	lshSeed   = 0x6d656d73 // fixed, so repeated scans report the same pairs
+)
+
+// lshIndex buckets embeddings by the sign pattern of random hyperplane
+// projections: SQLite has no vector index, and this keeps the scan close to
+// linear instead of comparing every pair.
+type lshIndex struct {
+	planes  map[int][][]float32 // per dimension: lshTables*lshBits hyperplanes
+	buckets map[lshKey][]int
+}
+
+type lshKey struct {
+	dim, table int
+	sig        uint32
+}
+
+func newLSHIndex() *lshIndex {
+	return &lshIndex{planes: make(map[int][][]float32), buckets: make(map[lshKey][]int)}
+}
+
+func (x *lshIndex) hyperplanes(dim int) [][]float32 {
+	if p, ok := x.planes[dim]; ok {
+		return p
+	}
+	rng := rand.New(rand.NewPCG(lshSeed, uint64(dim)))
+	p := make([][]float32, lshTables*lshBits)
+	for i := range p {
+		p[i] = make([]float32, dim)
+		for j := range p[i] {
+			p[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	x.planes[dim] = p
+	return p
+}
+
+// keys returns the bucket key of emb in each table.
+func (x *lshIndex) keys(emb []float32) []lshKey {
+	planes := x.hyperplanes(len(emb))
+	keys := make([]lshKey, lshTables)
+	for t := range keys {
+		var sig uint32
+		for b := 0; b < lshBits; b++ {
+			var dot float32
+			for i, v := range planes[t*lshBits+b] {
+				dot += v * emb[i]
+			}
+			if dot > 0 {
+				sig |= 1 << b
+			}
+		}
+		keys[t] = lshKey{dim: len(emb), table: t, sig: sig}
+	}
+	return keys
+}
+
+// FindDuplicates reports clusters of near-duplicate active facts in the
+// store's namespace. See Deduper.
+func (s *SQLiteStore) FindDuplicates(ctx context.Context, opts DedupeOpts) ([]DuplicateCluster, error) {
+	opts = opts.withDefaults()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q := `SELECT ` + factColumns + ` FROM memstore_facts
+	      WHERE namespace = ? AND superseded_by IS NULL` + notDeleted("")
+	args := []any{s.namespace}
+	appendUnexpiredFilter(&q, &args, "")
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: dedupe scan: %w", err)
+	}
+	facts := make(map[int64]Fact)
+	var embedded []Fact
+	var unembedded []Fact
+	for rows.Next() {
+		f, err := scanFact(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("memstore: scanning dedupe candidate: %w", err)
+		}
+		facts[f.ID] = *f
+		if len(f.Embedding) > 0 {
+			embedded = append(embedded, *f)
+		} else {
+			unembedded = append(unembedded, *f)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memstore: dedupe scan: %w", err)
+	}
+
+	var pairs []DuplicatePair
+
+	// Embedded facts: compare each against the facts sharing an LSH bucket,
+	// keeping its best opts.Neighbors matches.
+	idx := newLSHIndex()
+	keys := make([][]lshKey, len(embedded))
+	for i, f := range embedded {
+		keys[i] = idx.keys(f.Embedding)
+		for _, k := range keys[i] {
+			idx.buckets[k] = append(idx.buckets[k], i)
+		}
+	}
+	for i, f := range embedded {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		seen := map[int]bool{i: true}
+		var near []DuplicatePair
+		for _, k := range keys[i] {
+			for _, j := range idx.buckets[k] {
+				if seen[j] {
+					continue
+				}
+				seen[j] = true
+				if sim := embedding.CosineSimilarity(f.Embedding, embedded[j].Embedding); sim >= opts.Threshold {
+					near = append(near, DuplicatePair{A: f.ID, B: embedded[j].ID, Similarity: sim, Method: DedupeEmbedding})
+				}
+			}
+		}
+		pairs = append(pairs, nearest(near, opts.Neighbors)...)
+	}
+
+	// Unembedded facts: full-text candidates on any shared term, scored by
+	// term overlap.
+	for _, f := range unembedded {
+		terms := DedupeTerms(f.Content)
+		if len(terms) == 0 {
+			continue
+		}
+		quoted := make([]string, len(terms))
+		for i, t := range terms {
+			quoted[i] = `"` + t + `"`
+		}
+		fq := `SELECT f.id FROM memstore_facts_fts fts
+		       JOIN memstore_facts f ON f.id = fts.rowid
+		       WHERE memstore_facts_fts MATCH ? AND f.namespace = ? AND f.id != ?
+		         AND f.superseded_by IS NULL` + notDeleted("f.")
+		fargs := []any{strings.Join(quoted, " OR "), s.namespace, f.ID}
+		appendUnexpiredFilter(&fq, &fargs, "f.")
+		fq += ` ORDER BY rank LIMIT ?`
+		rows, err := s.db.QueryContext(ctx, fq, append(fargs, opts.Neighbors)...)
+		if err != nil {
+			return nil, fmt.Errorf("memstore: dedupe FTS fallback: %w", err)
+		}
+		var near []DuplicatePair
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("memstore: scanning dedupe FTS candidate: %w", err)
+			}
+			if g, ok := facts[id]; ok {
+				if sim := LexicalSimilarity(f.Content, g.Content); sim >= opts.LexicalThreshold {
+					near = append(near, DuplicatePair{A: f.ID, B: id, Similarity: sim, Method: DedupeLexical})
+				}
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("memstore: dedupe FTS fallback: %w", err)
+		}
+		pairs = append(pairs, near...)
+	}
+
+	return ClusterDuplicates(pairs, facts, opts.Limit), nil
+}
+
+// nearest returns the n most similar pairs.
+func nearest(pairs []DuplicatePair, n int) []DuplicatePair {
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Similarity > pairs[j].Similarity })
+	if len(pairs) > n {
+		pairs = pairs[:n]
+	}
+	return pairs
+}
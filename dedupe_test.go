@@ -0,0 +1,73 @@
+package memstore_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestDedupeTerms(t *testing.T) {
+	got := memstore.DedupeTerms("The API listens on port 8230; the api, port-8230!")
+	want := []string{"the", "api", "listens", "port", "8230"}
+	if !slices.Equal(got, want) {
+		t.Errorf("DedupeTerms = %v, want %v", got, want)
+	}
+}
+
+func TestLexicalSimilarity(t *testing.T) {
+	if got := memstore.LexicalSimilarity("deploys ship tuesday", "Tuesday: deploys ship!"); got != 1 {
+		t.Errorf("same terms = %v, want 1", got)
+	}
+	if got := memstore.LexicalSimilarity("deploys ship tuesday", "deploys ship friday"); got != 0.5 {
+		t.Errorf("two of four terms shared = %v, want 0.5", got)
+	}
+	if got := memstore.LexicalSimilarity("", "anything"); got != 0 {
+		t.Errorf("empty side = %v, want 0", got)
+	}
+}
+
+func TestClusterDuplicates(t *testing.T) {
+	now := time.Now()
+	facts := map[int64]memstore.Fact{}
+	for id := int64(1); id <= 6; id++ {
+		facts[id] = memstore.Fact{ID: id, CreatedAt: now.Add(time.Duration(id) * time.Minute), Embedding: []float32{1}}
+	}
+	pairs := []memstore.DuplicatePair{
+		{A: 2, B: 1, Similarity: 0.93, Method: memstore.DedupeEmbedding},
+		{A: 1, B: 2, Similarity: 0.95, Method: memstore.DedupeEmbedding}, // same pair, better score
+		{A: 2, B: 3, Similarity: 0.94, Method: memstore.DedupeEmbedding}, // chains 3 into 1-2
+		{A: 5, B: 6, Similarity: 0.99, Method: memstore.DedupeLexical},
+		{A: 4, B: 4, Similarity: 1, Method: memstore.DedupeEmbedding}, // self-pair ignored
+	}
+
+	clusters := memstore.ClusterDuplicates(pairs, facts, 0)
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters, want 2: %+v", len(clusters), clusters)
+	}
+	if c := clusters[0]; c.Similarity != 0.99 || len(c.Facts) != 2 || c.Facts[0].ID != 6 {
+		t.Errorf("first cluster = %+v, want the 0.99 pair, newest first", c)
+	}
+	c := clusters[1]
+	var ids []int64
+	for _, f := range c.Facts {
+		ids = append(ids, f.ID)
+		if f.Embedding != nil {
+			t.Errorf("fact %d kept its embedding", f.ID)
+		}
+	}
+	if !slices.Equal(ids, []int64{3, 2, 1}) {
+		t.Errorf("second cluster facts = %v, want [3 2 1]", ids)
+	}
+	if len(c.Pairs) != 2 || c.Pairs[0].Similarity != 0.95 || c.Pairs[0].A != 1 || c.Pairs[1].Similarity != 0.94 {
+		t.Errorf("second cluster pairs = %+v, want 1~2 at 0.95 then 2~3", c.Pairs)
+	}
+
+	if got := memstore.ClusterDuplicates(pairs, facts, 1); len(got) != 1 || got[0].Similarity != 0.99 {
+		t.Errorf("limit 1 = %+v, want only the best cluster", got)
+	}
+	if got := memstore.ClusterDuplicates(nil, facts, 0); len(got) != 0 {
+		t.Errorf("no pairs = %+v, want no clusters", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -78,6 +79,26 @@ type DocumentChunk struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// EmbedText assembles the embedding input for a chunk using the labeled-prefix
+// convention from docs/document-chunking.md: one "label: value" line per
+// non-empty derived context field, then the verbatim span. Content is read,
+// never modified -- the verbatim invariant is a property of the stored row,
+// and the context exists only in the embedder's input.
+func (c DocumentChunk) EmbedText() string {
+	var b strings.Builder
+	if c.HeadingPath != "" {
+		b.WriteString("section: " + c.HeadingPath + "\n")
+	}
+	if c.ScopePath != "" {
+		b.WriteString("scope: " + c.ScopePath + "\n")
+	}
+	if c.Signature != "" {
+		b.WriteString("signature: " + c.Signature + "\n")
+	}
+	b.WriteString(c.Content)
+	return b.String()
+}
+
 // DocumentInfo is the manifest-sync view of a stored document: enough to
 // compute need/skip/orphaned against a client manifest without shipping
 // content.
@@ -88,6 +109,33 @@ type DocumentInfo struct {
 	Dirty      bool   `json:"dirty"`
 }
 
+// DocumentSearchMode selects which retrieval arms a document search runs.
+type DocumentSearchMode string
+
+const (
+	// DocSearchFTS is the exact-then-decomposed full-text search. It is the
+	// default: the zero value selects it.
+	DocSearchFTS DocumentSearchMode = "fts"
+	// DocSearchVector ranks chunks by cosine similarity to the query
+	// embedding. Chunks the embed queue has not reached yet are invisible.
+	DocSearchVector DocumentSearchMode = "vector"
+	// DocSearchHybrid runs both arms and fuses them: FTS rank normalized to
+	// [0,1] and cosine similarity, weighted like fact search.
+	DocSearchHybrid DocumentSearchMode = "hybrid"
+)
+
+// ParseDocumentSearchMode validates a mode string. "" maps to DocSearchFTS.
+func ParseDocumentSearchMode(s string) (DocumentSearchMode, error) {
+	switch m := DocumentSearchMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return DocSearchFTS, nil
+	case DocSearchFTS, DocSearchVector, DocSearchHybrid:
+		return m, nil
+	default:
+		return "", fmt.Errorf("memstore: unknown document search mode %q (want fts, vector, or hybrid)", s)
+	}
+}
+
 // DocumentSearchOpts filters and sizes a document-chunk search.
 //
 // Basename exists because "show me sqlite.go" is a metadata lookup, not a
@@ -100,6 +148,25 @@ type DocumentSearchOpts struct {
 	Basename         string // exact match on document basename
 	Lang             string // exact match on document lang
 	IncludeGenerated bool
+
+	// Mode selects FTS (default), vector, or hybrid retrieval. Vector and
+	// hybrid need an embedder on the store.
+	Mode DocumentSearchMode
+	// FTSWeight and VecWeight weight the two arms in hybrid mode. Both zero
+	// means the store default (0.6/0.4, matching fact search).
+	FTSWeight float64
+	VecWeight float64
+
+	// Second-stage rerank, with the same semantics as the SearchOpts fields of
+	// the same names: off unless RerankMode is set and the store has a
+	// reranker, a threshold that only filters when rerank actually ran, and a
+	// degraded backend that leaves first-stage order untouched. The rerank
+	// document is DocumentSearchResult.RerankText.
+	RerankMode       RerankMode
+	RerankCandidates int     // first-stage results sent to the reranker; 0 = DefaultRerankCandidates
+	RerankWeight     float64 // rerank's share in RerankBalanced; 0 = DefaultRerankWeight
+	RerankDocBytes   int     // per-document truncation; 0 = DefaultRerankDocBytes
+	RerankThreshold  float64 // drop chunks whose normalized rerank score is below this
 }
 
 // DocumentSearchResult is one chunk hit with the document identity needed for
@@ -118,6 +185,14 @@ type DocumentSearchResult struct {
 	IsTest    bool          `json:"is_test,omitempty"`
 	Score     float64       `json:"score"`
 	Fallback  bool          `json:"fallback,omitempty"` // matched via decomposed-identifier fallback; ranked below exact hits
+
+	// Per-arm scores, set in hybrid mode so a caller can see why a chunk
+	// ranked where it did. FTSScore is normalized to [0,1] there.
+	FTSScore float64 `json:"fts_score,omitempty"`
+	VecScore float64 `json:"vec_score,omitempty"`
+	// RerankScore is the reranker's normalized [0,1] relevance, set only when
+	// a reranker rescored this chunk.
+	RerankScore float64 `json:"rerank_score,omitempty"`
 }
 
 // Citation renders the mandatory traceability string for a chunk result:
@@ -133,6 +208,30 @@ func (r DocumentSearchResult) Citation() string {
 	return r.RepoURL + "@" + r.Commit + " " + loc
 }
 
+// RerankText is the document a cross-encoder scores for this hit: labeled
+// path, symbol, and heading-path lines over the verbatim span, the same
+// labeled-prefix shape as DocumentChunk.EmbedText. A bare span loses the file
+// and declaration it came from, and for code those are often what the query
+// names. The context goes first so truncation (RerankDocBytes) cuts content,
+// never the labels.
+func (r DocumentSearchResult) RerankText() string {
+	var b strings.Builder
+	if r.Path != "" {
+		b.WriteString("path: " + r.Path + "\n")
+	}
+	if sym := r.Chunk.Symbol; sym != "" {
+		if r.Chunk.Receiver != "" {
+			sym = "(" + r.Chunk.Receiver + ")." + sym
+		}
+		b.WriteString("symbol: " + sym + "\n")
+	}
+	if r.Chunk.HeadingPath != "" {
+		b.WriteString("section: " + r.Chunk.HeadingPath + "\n")
+	}
+	b.WriteString(r.Chunk.Content)
+	return b.String()
+}
+
 // DocumentStore is the document-corpus storage interface. It is separate from
 // Store on purpose: documents are written only by the ingest path (never by
 // model-facing tools), do not participate in Export/Import, and have no
@@ -162,9 +261,21 @@ type DocumentStore interface {
 	// GetDocumentChunks returns a document's chunks ordered by ordinal.
 	GetDocumentChunks(ctx context.Context, documentID int64) ([]DocumentChunk, error)
 
-	// SearchDocumentChunks is FTS over chunk content: an exact pass first,
-	// then -- only if the exact pass leaves room -- a decomposed-identifier
-	// fallback appended below it (docs/embedding-model-routing.md, measured:
-	// fall back, do not blend).
+	// SearchDocumentChunks searches chunk content. In the default FTS mode it
+	// runs an exact pass first, then -- only if the exact pass leaves room --
+	// a decomposed-identifier fallback appended below it
+	// (docs/embedding-model-routing.md, measured: fall back, do not blend).
+	// opts.Mode selects vector-only or hybrid retrieval instead.
 	SearchDocumentChunks(ctx context.Context, query string, opts DocumentSearchOpts) ([]DocumentSearchResult, error)
+
+	// NeedingChunkEmbedding returns chunks without an embedding that have not
+	// been quarantined, oldest first. Used by the background chunk embed queue.
+	NeedingChunkEmbedding(ctx context.Context, limit int) ([]DocumentChunk, error)
+
+	// SetChunkEmbedding stores a computed embedding for a chunk.
+	SetChunkEmbedding(ctx context.Context, chunkID int64, emb []float32) error
+
+	// MarkChunkEmbedFailed quarantines a chunk whose embedding failed
+	// permanently so NeedingChunkEmbedding stops returning it.
+	MarkChunkEmbedFailed(ctx context.Context, chunkID int64, reason string) error
 }
@@ -0,0 +1,53 @@
+package memstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocumentChunkEmbedText(t *testing.T) {
+	c := DocumentChunk{
+		Content:     "func (s *Store) Close() error { return nil }",
+		HeadingPath: "",
+		ScopePath:   "memstore > Store > Close",
+		Signature:   "func (s *Store) Close() error",
+	}
+	want := "scope: memstore > Store > Close\n" +
+		"signature: func (s *Store) Close() error\n" +
+		"func (s *Store) Close() error { return nil }"
+	if got := c.EmbedText(); got != want {
+		t.Errorf("EmbedText() =\n%q\nwant\n%q", got, want)
+	}
+
+	md := DocumentChunk{Content: "## Vectors come later\nbody", HeadingPath: "Schema"}
+	if got := md.EmbedText(); got != "section: Schema\n## Vectors come later\nbody" {
+		t.Errorf("markdown EmbedText() = %q", got)
+	}
+
+	// The verbatim invariant: assembling embed text never touches Content.
+	if c.Content != "func (s *Store) Close() error { return nil }" {
+		t.Error("EmbedText mutated Content")
+	}
+
+	bare := DocumentChunk{Content: "plain text"}
+	if got := bare.EmbedText(); got != "plain text" {
+		t.Errorf("chunk with no derived context: EmbedText() = %q, want content unchanged", got)
+	}
+}
+
+func TestParseDocumentSearchMode(t *testing.T) {
+	for in, want := range map[string]DocumentSearchMode{
+		"":        DocSearchFTS,
+		"fts":     DocSearchFTS,
+		"Vector":  DocSearchVector,
+		" hybrid": DocSearchHybrid,
+	} {
+		got, err := ParseDocumentSearchMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDocumentSearchMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseDocumentSearchMode("semantic"); err == nil || !strings.Contains(err.Error(), "semantic") {
+		t.Errorf("unknown mode: err = %v, want an error naming the input", err)
+	}
+}
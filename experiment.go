@@ -0,0 +1,262 @@
+package memstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// ExperimentMode selects how an online ranking experiment compares its arms.
+type ExperimentMode string
+
+const (
+	// ExperimentSplit assigns each enrolled session to one arm for its whole
+	// life. Every injection in the session is credited to that arm, so the
+	// per-arm feedback averages compare whole-session experiences. Split
+	// experiments also drive the hint pipeline.
+	ExperimentSplit ExperimentMode = "split"
+	// ExperimentInterleave runs both of its two arms on every recall and
+	// team-draft interleaves their rankings (TeamDraftInterleave), crediting
+	// each injected fact to the arm that contributed it. It needs far fewer
+	// sessions than a split to detect a preference, at the cost of running
+	// the recall pipeline twice per prompt. Recall only.
+	ExperimentInterleave ExperimentMode = "interleave"
+)
+
+// RankerParams are the retrieval knobs an experiment arm may override. Nil
+// pointers and empty strings inherit the daemon's configuration, so an arm
+// with no params is the control.
+type RankerParams struct {
+	// Recall scoring (/v1/recall); see httpapi.RecallTuning.
+	MinScoreRatio       *float64 `json:"min_score_ratio,omitempty"`
+	MinAbsoluteScore    *float64 `json:"min_absolute_score,omitempty"`
+	VecBoostWeight      *float64 `json:"vec_boost_weight,omitempty"`
+	VecOnlyWeight       *float64 `json:"vec_only_weight,omitempty"`
+	ProjectSurfaceBoost *float64 `json:"project_surface_boost,omitempty"`
+
+	// Rerank, for recall and the hint searcher. "off" disables rerank for the
+	// arm even when the daemon enables it.
+	RerankMode      string   `json:"rerank_mode,omitempty"`
+	RerankThreshold *float64 `json:"rerank_threshold,omitempty"`
+
+	// Hint searcher (Store.Search) fusion weights; 0 = the store default.
+	FTSWeight float64 `json:"fts_weight,omitempty"`
+	VecWeight float64 `json:"vec_weight,omitempty"`
+}
+
+// ExperimentArm is one retrieval configuration under test.
+type ExperimentArm struct {
+	Name string `json:"name"`
+	// RankerVersion is stamped on hints generated under this arm
+	// (ContextHint.RankerVersion), so training exports can be segmented by
+	// arm. Empty defaults to "<experiment>/<arm>".
+	RankerVersion string       `json:"ranker_version,omitempty"`
+	Params        RankerParams `json:"params"`
+}
+
+// Experiment is an online comparison of ranker configurations. Definitions
+// live in the daemon config (LoadExperiments); the daemon records them in its
+// database, where admin commands list their per-arm feedback and stop them.
+type Experiment struct {
+	Name string          `json:"name"`
+	Mode ExperimentMode  `json:"mode"`
+	Arms []ExperimentArm `json:"arms"`
+	// Traffic is the fraction of sessions enrolled, in (0,1]; 0 means all.
+	// Unenrolled sessions run the daemon's configuration and are not logged
+	// against the experiment.
+	Traffic float64 `json:"traffic,omitempty"`
+
+	StartedAt time.Time  `json:"started_at,omitzero"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+}
+
+// ArmAssignment identifies the experiment arm an injection or hint was
+// produced under. The zero value means no experiment.
+type ArmAssignment struct {
+	Experiment string `json:"experiment,omitempty"`
+	Arm        string `json:"arm,omitempty"`
+}
+
+// ExperimentSource supplies the experiments currently running. The daemon's
+// recall handler and hint pipeline poll it, so a stop issued by an admin
+// command takes effect without a restart.
+type ExperimentSource interface {
+	ActiveExperiments(ctx context.Context) ([]Experiment, error)
+}
+
+// ArmInjectionRecorder is implemented by session stores that can attribute
+// an injection to an experiment arm. It is RecordInjection plus the arm.
+type ArmInjectionRecorder interface {
+	RecordArmInjection(ctx context.Context, sessionID, refID, refType string, rank int, arm ArmAssignment) error
+}
+
+// ArmFeedback aggregates context_feedback for one arm and ref type of an
+// experiment. Unrated injections count toward Injections but not the averages.
+type ArmFeedback struct {
+	Arm        string  `json:"arm"`
+	RefType    string  `json:"ref_type"`
+	Sessions   int     `json:"sessions"`
+	Injections int     `json:"injections"`
+	Rated      int     `json:"rated"`
+	Positive   int     `json:"positive"`
+	Negative   int     `json:"negative"`
+	AvgScore   float64 `json:"avg_score"` // mean rating in [-1,1] over rated injections
+}
+
+// Validate checks an experiment definition: a name, a known mode, uniquely
+// named arms (exactly two when interleaving), parseable rerank modes, and a
+// traffic fraction in [0,1].
+func (e Experiment) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("memstore: experiment name is required")
+	}
+	switch e.Mode {
+	case ExperimentSplit:
+		if len(e.Arms) < 2 {
+			return fmt.Errorf("memstore: experiment %q: a split needs at least two arms", e.Name)
+		}
+	case ExperimentInterleave:
+		if len(e.Arms) != 2 {
+			return fmt.Errorf("memstore: experiment %q: interleaving needs exactly two arms", e.Name)
+		}
+	default:
+		return fmt.Errorf("memstore: experiment %q: unknown mode %q (want split|interleave)", e.Name, e.Mode)
+	}
+	if e.Traffic < 0 || e.Traffic > 1 {
+		return fmt.Errorf("memstore: experiment %q: traffic %v is outside [0,1]", e.Name, e.Traffic)
+	}
+	seen := make(map[string]bool, len(e.Arms))
+	for _, a := range e.Arms {
+		if a.Name == "" {
+			return fmt.Errorf("memstore: experiment %q: arm name is required", e.Name)
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("memstore: experiment %q: duplicate arm %q", e.Name, a.Name)
+		}
+		seen[a.Name] = true
+		if _, err := ParseRerankMode(a.Params.RerankMode); err != nil {
+			return fmt.Errorf("memstore: experiment %q arm %q: %w", e.Name, a.Name, err)
+		}
+	}
+	return nil
+}
+
+// Enrolls reports whether the session takes part in the experiment. It is a
+// pure function of the experiment name and session ID, so every daemon and
+// every request agrees without shared state.
+func (e Experiment) Enrolls(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	if e.Traffic <= 0 || e.Traffic >= 1 {
+		return true
+	}
+	u := float64(experimentHash(e.Name, "traffic", sessionID)>>11) / (1 << 53)
+	return u < e.Traffic
+}
+
+// ArmFor returns the index of the arm an enrolled session is assigned to in
+// a split experiment. Like Enrolls it is deterministic per session.
+func (e Experiment) ArmFor(sessionID string) int {
+	return int(experimentHash(e.Name, "arm", sessionID) % uint64(len(e.Arms)))
+}
+
+// Assignment returns the ArmAssignment for the experiment's i'th arm.
+func (e Experiment) Assignment(i int) ArmAssignment {
+	return ArmAssignment{Experiment: e.Name, Arm: e.Arms[i].Name}
+}
+
+// RankerVersionFor returns the ranker version stamped on hints generated
+// under the i'th arm.
+func (e Experiment) RankerVersionFor(i int) string {
+	if v := e.Arms[i].RankerVersion; v != "" {
+		return v
+	}
+	return e.Name + "/" + e.Arms[i].Name
+}
+
+func experimentHash(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// TeamDraftInterleave merges two rankings by team-draft interleaving
+// (Radlinski, Kurup & Joachims, 2008). In each round the team with fewer
+// picks -- or, on a tie, a coin flip -- takes its highest-ranked item not yet
+// in the merged list. team[i] is 0 or 1: which ranking contributed merged[i].
+// Items both rankings share are credited to whichever team picked them
+// first, which is what makes the comparison unbiased.
+//
+// The coin flips come from seed, so a given (session, prompt) interleaves
+// the same way on retry.
+func TeamDraftInterleave(a, b []int64, seed uint64) (merged []int64, team []int) {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	in := make(map[int64]bool, len(a)+len(b))
+	lists := [2][]int64{a, b}
+	var next [2]int
+	var picks [2]int
+
+	take := func(t int) bool {
+		l := lists[t]
+		for next[t] < len(l) && in[l[next[t]]] {
+			next[t]++
+		}
+		if next[t] >= len(l) {
+			return false
+		}
+		id := l[next[t]]
+		next[t]++
+		in[id] = true
+		merged = append(merged, id)
+		team = append(team, t)
+		picks[t]++
+		return true
+	}
+
+	for {
+		first := 0
+		if picks[1] < picks[0] || (picks[0] == picks[1] && rng.IntN(2) == 1) {
+			first = 1
+		}
+		if !take(first) && !take(1-first) {
+			return merged, team
+		}
+	}
+}
+
+// ExperimentSeed derives the interleaving seed for one recall.
+func ExperimentSeed(sessionID, prompt string) uint64 {
+	return experimentHash("interleave", sessionID, prompt)
+}
+
+// LoadExperiments reads a JSON array of experiment definitions from path and
+// validates each, rejecting duplicate names.
+func LoadExperiments(path string) ([]Experiment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: experiments: %w", err)
+	}
+	var exps []Experiment
+	if err := json.Unmarshal(data, &exps); err != nil {
+		return nil, fmt.Errorf("memstore: experiments: parse %s: %w", path, err)
+	}
+	names := make(map[string]bool, len(exps))
+	for _, e := range exps {
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		if names[e.Name] {
+			return nil, fmt.Errorf("memstore: experiments: duplicate experiment %q", e.Name)
+		}
+		names[e.Name] = true
+	}
+	return exps, nil
+}
@@ -0,0 +1,158 @@
+package memstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExperimentValidate(t *testing.T) {
+	two := []ExperimentArm{{Name: "a"}, {Name: "b"}}
+	tests := []struct {
+		name    string
+		exp     Experiment
+		wantErr string
+	}{
+		{"split", Experiment{Name: "x", Mode: ExperimentSplit, Arms: two}, ""},
+		{"three-way split", Experiment{Name: "x", Mode: ExperimentSplit, Arms: append(two, ExperimentArm{Name: "c"})}, ""},
+		{"interleave", Experiment{Name: "x", Mode: ExperimentInterleave, Arms: two, Traffic: 0.5}, ""},
+		{"no name", Experiment{Mode: ExperimentSplit, Arms: two}, "name is required"},
+		{"unknown mode", Experiment{Name: "x", Mode: "bandit", Arms: two}, "unknown mode"},
+		{"one arm", Experiment{Name: "x", Mode: ExperimentSplit, Arms: two[:1]}, "at least two arms"},
+		{"interleave three", Experiment{Name: "x", Mode: ExperimentInterleave, Arms: append(two, ExperimentArm{Name: "c"})}, "exactly two arms"},
+		{"traffic", Experiment{Name: "x", Mode: ExperimentSplit, Arms: two, Traffic: 1.5}, "outside [0,1]"},
+		{"duplicate arm", Experiment{Name: "x", Mode: ExperimentSplit, Arms: []ExperimentArm{{Name: "a"}, {Name: "a"}}}, "duplicate arm"},
+		{"bad rerank", Experiment{Name: "x", Mode: ExperimentSplit, Arms: []ExperimentArm{{Name: "a"}, {Name: "b", Params: RankerParams{RerankMode: "loud"}}}}, "unknown rerank mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.exp.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExperimentAssignment(t *testing.T) {
+	e := Experiment{Name: "x", Mode: ExperimentSplit, Arms: []ExperimentArm{{Name: "a"}, {Name: "b"}}, Traffic: 0.5}
+
+	if e.Enrolls("") {
+		t.Error("an empty session ID must never enroll")
+	}
+	enrolled := 0
+	perArm := make([]int, len(e.Arms))
+	for i := range 4000 {
+		id := fmt.Sprintf("session-%d", i)
+		if e.Enrolls(id) != e.Enrolls(id) || e.ArmFor(id) != e.ArmFor(id) {
+			t.Fatalf("assignment of %s is not deterministic", id)
+		}
+		if e.Enrolls(id) {
+			enrolled++
+			perArm[e.ArmFor(id)]++
+		}
+	}
+	if enrolled < 1800 || enrolled > 2200 {
+		t.Errorf("enrolled %d of 4000 at traffic 0.5", enrolled)
+	}
+	if perArm[0] < enrolled*2/5 || perArm[1] < enrolled*2/5 {
+		t.Errorf("arms unbalanced: %v", perArm)
+	}
+
+	if got := e.RankerVersionFor(0); got != "x/a" {
+		t.Errorf("default ranker version = %q, want x/a", got)
+	}
+	e.Arms[1].RankerVersion = "hint-v2"
+	if got := e.RankerVersionFor(1); got != "hint-v2" {
+		t.Errorf("ranker version = %q, want hint-v2", got)
+	}
+}
+
+func TestTeamDraftInterleave(t *testing.T) {
+	a := []int64{1, 2, 3, 4, 5}
+	b := []int64{3, 6, 1, 7}
+	for seed := range uint64(50) {
+		merged, team := TeamDraftInterleave(a, b, seed)
+		if len(merged) != 7 || len(team) != len(merged) {
+			t.Fatalf("seed %d: merged %v, team %v; want the 7 distinct IDs", seed, merged, team)
+		}
+		seen := map[int64]bool{}
+		var picks [2]int
+		for i, id := range merged {
+			if seen[id] {
+				t.Fatalf("seed %d: %d merged twice", seed, id)
+			}
+			seen[id] = true
+			list := a
+			if team[i] == 1 {
+				list = b
+			}
+			if !containsID(list, id) {
+				t.Fatalf("seed %d: %d credited to team %d, which did not rank it", seed, id, team[i])
+			}
+			picks[team[i]]++
+			// While both teams still have unpicked items, neither leads by more than one.
+			if i < 6 && (picks[0]-picks[1] > 1 || picks[1]-picks[0] > 1) {
+				t.Fatalf("seed %d: unbalanced picks %v at %d", seed, picks, i)
+			}
+		}
+		again, _ := TeamDraftInterleave(a, b, seed)
+		if fmt.Sprint(again) != fmt.Sprint(merged) {
+			t.Fatalf("seed %d: not deterministic", seed)
+		}
+	}
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadExperiments(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	good := write("good.json", `[
+		{"name": "floor", "mode": "split", "traffic": 0.2, "arms": [
+			{"name": "control"},
+			{"name": "loose", "ranker_version": "recall-loose", "params": {"min_score_ratio": 0.1, "rerank_mode": "off"}}
+		]}
+	]`)
+	exps, err := LoadExperiments(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exps) != 1 || exps[0].Traffic != 0.2 || *exps[0].Arms[1].Params.MinScoreRatio != 0.1 {
+		t.Fatalf("loaded %+v", exps)
+	}
+
+	dup := write("dup.json", `[
+		{"name": "x", "mode": "split", "arms": [{"name": "a"}, {"name": "b"}]},
+		{"name": "x", "mode": "interleave", "arms": [{"name": "a"}, {"name": "b"}]}
+	]`)
+	if _, err := LoadExperiments(dup); err == nil || !strings.Contains(err.Error(), "duplicate experiment") {
+		t.Errorf("duplicate names: err = %v", err)
+	}
+	invalid := write("invalid.json", `[{"name": "x", "mode": "split", "arms": [{"name": "a"}]}]`)
+	if _, err := LoadExperiments(invalid); err == nil {
+		t.Error("expected a validation error")
+	}
+}
@@ -0,0 +1,266 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExpiryInterval is how often the expiry reaper sweeps when
+// ExpiryPolicy.Interval is unset. Expired facts are already hidden from
+// active queries the moment they expire, so the sweep only needs to keep
+// the table tidy; minutes of lag are invisible.
+const DefaultExpiryInterval = 10 * time.Minute
+
+// ExpiryAction selects what the expiry reaper does with a fact whose
+// ExpiresAt has passed.
+type ExpiryAction string
+
+const (
+	// ExpiryArchive stamps ArchivedAt and keeps the row, so the fact stays
+	// readable through Get and History. The default.
+	ExpiryArchive ExpiryAction = "archive"
+	// ExpiryDelete moves the fact to the trash, like Store.Delete, so it can
+	// be restored until the trash retention purges it. A fact that superseded
+	// another is archived instead, so the supersession chain it ends stays
+	// intact.
+	ExpiryDelete ExpiryAction = "delete"
+	// ExpiryOff disables the reaper. Expired facts are still excluded from
+	// OnlyActive queries.
+	ExpiryOff ExpiryAction = "off"
+)
+
+// ParseExpiryAction validates an action string (case-insensitive). The empty
+// string maps to ExpiryArchive.
+func ParseExpiryAction(s string) (ExpiryAction, error) {
+	switch a := ExpiryAction(strings.ToLower(strings.TrimSpace(s))); a {
+	case "":
+		return ExpiryArchive, nil
+	case ExpiryArchive, ExpiryDelete, ExpiryOff:
+		return a, nil
+	default:
+		return "", fmt.Errorf("memstore: unknown expiry action %q (want archive|delete|off)", s)
+	}
+}
+
+// ParseTTL parses a fact time-to-live: a Go duration ("36h", "90m") or a
+// whole number of days or weeks ("3d", "2w"). The result must be positive.
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	var err error
+	if n, unit := strings.TrimRight(s, "dw"), strings.TrimLeft(s, "0123456789"); (unit == "d" || unit == "w") && n != "" {
+		var v int
+		v, err = strconv.Atoi(n)
+		d = time.Duration(v) * 24 * time.Hour
+		if unit == "w" {
+			d *= 7
+		}
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("memstore: invalid TTL %q: want a positive duration such as 36h, 3d, or 2w", s)
+	}
+	return d, nil
+}
+
+// ExpiryResult reports what one reaper sweep did.
+type ExpiryResult struct {
+	Archived []int64 // facts stamped with ArchivedAt
+	Deleted  []int64 // facts moved to the trash
+}
+
+// ExpiryReaper is implemented by stores that can retire expired facts in
+// bulk. Both built-in backends implement it; the sweep is scoped like any
+// other write (namespace, and user unless the store is service-scoped).
+type ExpiryReaper interface {
+	// ReapExpired applies action to facts whose ExpiresAt is at or before now.
+	// ExpiryArchive stamps ArchivedAt on facts not yet archived; ExpiryDelete
+	// trashes expired facts, archived or not, except those that superseded
+	// another fact, which it archives.
+	ReapExpired(ctx context.Context, action ExpiryAction, now time.Time) (ExpiryResult, error)
+}
+
+// ExpiryPolicy configures the background expiry reaper.
+type ExpiryPolicy struct {
+	Action   ExpiryAction  // "" = ExpiryArchive
+	Interval time.Duration // 0 = DefaultExpiryInterval
+}
+
+// Enabled reports whether the reaper runs at all.
+func (p ExpiryPolicy) Enabled() bool { return p.Action != ExpiryOff }
+
+// ExpiryPolicyFromEnv reads an ExpiryPolicy from {prefix}_ACTION (archive,
+// delete, or off) and {prefix}_INTERVAL (a Go duration). Unset variables keep
+// the defaults.
+func ExpiryPolicyFromEnv(prefix string) (ExpiryPolicy, error) {
+	var pol ExpiryPolicy
+	a, err := ParseExpiryAction(os.Getenv(prefix + "_ACTION"))
+	if err != nil {
+		return ExpiryPolicy{}, err
+	}
+	pol.Action = a
+	if v := os.Getenv(prefix + "_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return ExpiryPolicy{}, fmt.Errorf("memstore: invalid %s_INTERVAL %q: must be a positive duration", prefix, v)
+		}
+		pol.Interval = d
+	}
+	return pol, nil
+}
+
+// ExpirySweeper runs an ExpiryReaper on a timer.
+type ExpirySweeper struct {
+	reaper ExpiryReaper
+	policy ExpiryPolicy
+	logf   func(format string, args ...any)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewExpirySweeper creates a background expiry reaper. logf receives one line
+// per sweep that changed anything and one per error; nil discards them.
+func NewExpirySweeper(r ExpiryReaper, policy ExpiryPolicy, logf func(format string, args ...any)) *ExpirySweeper {
+	if policy.Action == "" {
+		policy.Action = ExpiryArchive
+	}
+	if policy.Interval == 0 {
+		policy.Interval = DefaultExpiryInterval
+	}
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	return &ExpirySweeper{reaper: r, policy: policy, logf: logf, done: make(chan struct{})}
+}
+
+// Start sweeps once immediately, then every interval.
+func (es *ExpirySweeper) Start() {
+	es.wg.Add(1)
+	go es.loop()
+}
+
+// Stop signals the loop to stop and waits for it to finish.
+func (es *ExpirySweeper) Stop() {
+	close(es.done)
+	es.wg.Wait()
+}
+
+func (es *ExpirySweeper) loop() {
+	defer es.wg.Done()
+	ticker := time.NewTicker(es.policy.Interval)
+	defer ticker.Stop()
+
+	es.SweepOnce(context.Background())
+	for {
+		select {
+		case <-es.done:
+			return
+		case <-ticker.C:
+			es.SweepOnce(context.Background())
+		}
+	}
+}
+
+// SweepOnce reaps the facts that have expired by now. Called from the
+// background loop and exposed for tests.
+func (es *ExpirySweeper) SweepOnce(ctx context.Context) (ExpiryResult, error) {
+	if !es.policy.Enabled() {
+		return ExpiryResult{}, nil
+	}
+	res, err := es.reaper.ReapExpired(ctx, es.policy.Action, time.Now())
+	if err != nil {
+		es.logf("expiry reaper: %v", err)
+		return res, err
+	}
+	if len(res.Archived) > 0 {
+		es.logf("expiry reaper: archived %d expired facts: %v", len(res.Archived), res.Archived)
+	}
+	if len(res.Deleted) > 0 {
+		es.logf("expiry reaper: trashed %d expired facts: %v", len(res.Deleted), res.Deleted)
+	}
+	return res, nil
+}
+
+// migrateV14 adds fact expiry. expires_at is the caller-set deadline;
+// archived_at is stamped by the reaper. Both are RFC3339 TEXT like the other
+// fact timestamps, so they compare lexically.
+func (s *SQLiteStore) migrateV14() error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN expires_at TEXT`,
+		`ALTER TABLE memstore_facts ADD COLUMN archived_at TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_expires ON memstore_facts(expires_at) WHERE expires_at IS NOT NULL`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("memstore V14 migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReapExpired implements ExpiryReaper.
+func (s *SQLiteStore) ReapExpired(ctx context.Context, action ExpiryAction, now time.Time) (ExpiryResult, error) {
+	var res ExpiryResult
+	if action == ExpiryOff {
+		return res, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return res, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ts := now.UTC().Format(time.RFC3339)
+	if action == ExpiryDelete {
+		res.Deleted, err = queryIDs(ctx, tx,
+			`UPDATE memstore_facts SET deleted_at = ?
+			 WHERE namespace = ? AND expires_at IS NOT NULL AND expires_at <= ? AND deleted_at IS NULL
+			   AND NOT EXISTS (SELECT 1 FROM memstore_facts p WHERE p.superseded_by = memstore_facts.id)
+			 RETURNING id`,
+			ts, s.namespace, ts)
+		if err != nil {
+			return ExpiryResult{}, fmt.Errorf("memstore: trashing expired facts: %w", err)
+		}
+	}
+	res.Archived, err = queryIDs(ctx, tx,
+		`UPDATE memstore_facts SET archived_at = ?
+		 WHERE namespace = ? AND expires_at IS NOT NULL AND expires_at <= ? AND archived_at IS NULL AND deleted_at IS NULL
+		 RETURNING id`,
+		ts, s.namespace, ts)
+	if err != nil {
+		return ExpiryResult{}, fmt.Errorf("memstore: archiving expired facts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return ExpiryResult{}, fmt.Errorf("memstore: committing expiry: %w", err)
+	}
+	return res, nil
+}
+
+// queryIDs runs a statement that returns a single id column.
+func queryIDs(ctx context.Context, tx *sql.Tx, q string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,83 @@
+package memstore_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestParseTTL(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"36h", 36 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"3d", 72 * time.Hour},
+		{" 2w ", 14 * 24 * time.Hour},
+	} {
+		if got, err := memstore.ParseTTL(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseTTL(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "0d", "-1h", "d", "3x", "1.5d", "soon"} {
+		if _, err := memstore.ParseTTL(bad); err == nil {
+			t.Errorf("ParseTTL(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestExpiryPolicyFromEnv(t *testing.T) {
+	p, err := memstore.ExpiryPolicyFromEnv("TESTEXP")
+	if err != nil || p.Action != memstore.ExpiryArchive || !p.Enabled() {
+		t.Errorf("default policy = %+v, %v; want archive", p, err)
+	}
+	t.Setenv("TESTEXP_ACTION", "Delete")
+	t.Setenv("TESTEXP_INTERVAL", "1m")
+	if p, err = memstore.ExpiryPolicyFromEnv("TESTEXP"); err != nil || p.Action != memstore.ExpiryDelete || p.Interval != time.Minute {
+		t.Errorf("policy = %+v, %v", p, err)
+	}
+	t.Setenv("TESTEXP_ACTION", "off")
+	if p, _ = memstore.ExpiryPolicyFromEnv("TESTEXP"); p.Enabled() {
+		t.Error("off policy reports enabled")
+	}
+	t.Setenv("TESTEXP_ACTION", "shred")
+	if _, err := memstore.ExpiryPolicyFromEnv("TESTEXP"); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
+
+func TestExpirySweeper_SweepOnce(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute)
+	id, err := store.Insert(ctx, memstore.Fact{Content: "PR #42 is waiting on review", Subject: "memstore", Category: "project", ExpiresAt: &past})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logged []string
+	logf := func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+	off := memstore.NewExpirySweeper(store, memstore.ExpiryPolicy{Action: memstore.ExpiryOff}, logf)
+	if res, err := off.SweepOnce(ctx); err != nil || len(res.Archived)+len(res.Deleted) != 0 {
+		t.Errorf("disabled sweep = %+v, %v", res, err)
+	}
+
+	sweeper := memstore.NewExpirySweeper(store, memstore.ExpiryPolicy{}, logf)
+	res, err := sweeper.SweepOnce(ctx)
+	if err != nil || len(res.Archived) != 1 || res.Archived[0] != id {
+		t.Fatalf("sweep = %+v, %v; want fact %d archived", res, err, id)
+	}
+	if len(logged) != 1 {
+		t.Errorf("logged %q, want one line for the archive", logged)
+	}
+	if res, _ := sweeper.SweepOnce(ctx); len(res.Archived) != 0 {
+		t.Errorf("second sweep re-archived %v", res.Archived)
+	}
+
+	sweeper.Start()
+	sweeper.Stop()
+}
@@ -0,0 +1,181 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Feedback stage defaults. At one rating the multiplier's exponent is
+// avg*DefaultFeedbackBaseWeight; at DefaultFeedbackConfidenceCap ratings or
+// more it is the full avg. With DefaultFeedbackMaxFactor that is ×0.68/×1.47
+// for a single -1/+1 and ×0.5/×2.0 at full confidence, so one bad rating in
+// one session cannot crush a fact that is useful elsewhere.
+const (
+	DefaultFeedbackBaseWeight    = 0.4
+	DefaultFeedbackConfidenceCap = 5.0
+	DefaultFeedbackMaxFactor     = 2.0
+	// DefaultFeedbackHalfLife is how long a rating takes to lose half its
+	// weight. A fact that drew complaints and was then fixed recovers as the
+	// old ratings fade and new ones outweigh them.
+	DefaultFeedbackHalfLife = 90 * 24 * time.Hour
+)
+
+// FeedbackPolicy turns a fact's rating history into a ranking multiplier.
+// Zero fields take the Default* values, except HalfLife, where a negative
+// value disables decay.
+type FeedbackPolicy struct {
+	BaseWeight    float64       // confidence weight of a single rating, in (0,1]
+	ConfidenceCap float64       // rating count at which full confidence applies
+	MaxFactor     float64       // multiplier for avg ±1 at full confidence (×MaxFactor / ÷MaxFactor)
+	HalfLife      time.Duration // ratings lose half their weight per HalfLife; < 0 = never
+}
+
+// DefaultFeedbackPolicy returns the policy recall has always used, plus
+// rating decay.
+func DefaultFeedbackPolicy() FeedbackPolicy {
+	return FeedbackPolicy{
+		BaseWeight:    DefaultFeedbackBaseWeight,
+		ConfidenceCap: DefaultFeedbackConfidenceCap,
+		MaxFactor:     DefaultFeedbackMaxFactor,
+		HalfLife:      DefaultFeedbackHalfLife,
+	}
+}
+
+func (p FeedbackPolicy) withDefaults() FeedbackPolicy {
+	if p.BaseWeight <= 0 {
+		p.BaseWeight = DefaultFeedbackBaseWeight
+	}
+	if p.ConfidenceCap <= 0 {
+		p.ConfidenceCap = DefaultFeedbackConfidenceCap
+	}
+	if p.MaxFactor <= 0 {
+		p.MaxFactor = DefaultFeedbackMaxFactor
+	}
+	if p.HalfLife == 0 {
+		p.HalfLife = DefaultFeedbackHalfLife
+	}
+	return p
+}
+
+// Multiplier returns the score multiplier for a fact's feedback: above 1 for
+// a positive history, below 1 for a negative one, 1 for none. Confidence grows
+// with the (decay-weighted) rating count up to ConfidenceCap. When ratings
+// have decayed to less than one rating's worth of weight the effect shrinks
+// with them, so a fact with only stale ratings returns to neutral.
+func (p FeedbackPolicy) Multiplier(stat FeedbackStat) float64 {
+	weight := stat.weight()
+	if weight <= 0 {
+		return 1
+	}
+	p = p.withDefaults()
+	conf := math.Min(weight, p.ConfidenceCap) / p.ConfidenceCap
+	exponent := stat.Avg * (p.BaseWeight + (1-p.BaseWeight)*conf) * math.Min(weight, 1)
+	return math.Pow(p.MaxFactor, exponent)
+}
+
+// Validate checks the policy's ranges.
+func (p FeedbackPolicy) Validate() error {
+	if p.BaseWeight < 0 || p.BaseWeight > 1 {
+		return fmt.Errorf("memstore: feedback base weight %v out of range [0,1]", p.BaseWeight)
+	}
+	if p.ConfidenceCap < 0 {
+		return fmt.Errorf("memstore: feedback confidence cap %v must not be negative", p.ConfidenceCap)
+	}
+	if p.MaxFactor != 0 && p.MaxFactor < 1 {
+		return fmt.Errorf("memstore: feedback max factor %v must be at least 1", p.MaxFactor)
+	}
+	return nil
+}
+
+// FeedbackPolicyFromEnv reads a FeedbackPolicy from {prefix}_BASE_WEIGHT,
+// {prefix}_CONFIDENCE_CAP, {prefix}_MAX_FACTOR, and {prefix}_HALF_LIFE (a Go
+// duration; "off" or a negative value disables decay). Unset variables keep
+// the defaults.
+func FeedbackPolicyFromEnv(prefix string) (FeedbackPolicy, error) {
+	pol := DefaultFeedbackPolicy()
+	for _, f := range []struct {
+		suffix string
+		dst    *float64
+	}{
+		{"_BASE_WEIGHT", &pol.BaseWeight},
+		{"_CONFIDENCE_CAP", &pol.ConfidenceCap},
+		{"_MAX_FACTOR", &pol.MaxFactor},
+	} {
+		if v := os.Getenv(prefix + f.suffix); v != "" {
+			x, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return FeedbackPolicy{}, fmt.Errorf("memstore: invalid %s%s %q: %w", prefix, f.suffix, v, err)
+			}
+			*f.dst = x
+		}
+	}
+	if v := os.Getenv(prefix + "_HALF_LIFE"); v != "" {
+		if v == "off" {
+			pol.HalfLife = -1
+		} else {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return FeedbackPolicy{}, fmt.Errorf("memstore: invalid %s_HALF_LIFE %q: %w", prefix, v, err)
+			}
+			if d == 0 {
+				d = -1 // an explicit 0 means no decay, not "the default"
+			}
+			pol.HalfLife = d
+		}
+	}
+	return pol, pol.Validate()
+}
+
+// DecayedFeedbackScorer is implemented by feedback sources that can weight
+// each rating by its age: a rating halfLife old counts half as much as a new
+// one, in both the average and the returned Weight.
+type DecayedFeedbackScorer interface {
+	DecayedFeedbackScores(ctx context.Context, refIDs []string, refType string, halfLife time.Duration) (map[string]FeedbackStat, error)
+}
+
+// FetchFeedback returns the feedback stats for refIDs under policy: decayed
+// when the policy decays and the scorer supports it, plain otherwise.
+func FetchFeedback(ctx context.Context, scorer FeedbackScorer, refIDs []string, refType string, policy FeedbackPolicy) (map[string]FeedbackStat, error) {
+	if len(refIDs) == 0 {
+		return nil, nil
+	}
+	policy = policy.withDefaults()
+	if ds, ok := scorer.(DecayedFeedbackScorer); ok && policy.HalfLife > 0 {
+		return ds.DecayedFeedbackScores(ctx, refIDs, refType, policy.HalfLife)
+	}
+	return scorer.FeedbackScores(ctx, refIDs, refType)
+}
+
+// FeedbackStage is the optional feedback step of ScoreResults: a source of
+// historical ratings and the policy that turns them into multipliers. Stores
+// hold one when configured with SetFeedback.
+type FeedbackStage struct {
+	Scorer FeedbackScorer
+	Policy FeedbackPolicy
+}
+
+// apply multiplies each result's Combined by its fact's feedback multiplier.
+// A failing scorer leaves the results untouched: feedback refines ranking but
+// is never a reason for search to fail.
+func (fs *FeedbackStage) apply(ctx context.Context, results []SearchResult) {
+	if fs == nil || fs.Scorer == nil || len(results) == 0 {
+		return
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = strconv.FormatInt(r.Fact.ID, 10)
+	}
+	stats, err := FetchFeedback(ctx, fs.Scorer, ids, RefTypeFact, fs.Policy)
+	if err != nil {
+		return
+	}
+	for i, id := range ids {
+		if stat, ok := stats[id]; ok {
+			results[i].Combined *= fs.Policy.Multiplier(stat)
+		}
+	}
+}
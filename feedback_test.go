@@ -0,0 +1,154 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeFeedback is a FeedbackScorer over fixed stats. Wrapped in
+// fakeDecayedFeedback it is also a DecayedFeedbackScorer serving decayed.
+type fakeFeedback struct {
+	plain, decayed map[string]FeedbackStat
+	err            error
+}
+
+func (f *fakeFeedback) FeedbackScores(_ context.Context, refIDs []string, _ string) (map[string]FeedbackStat, error) {
+	return f.pick(f.plain, refIDs), f.err
+}
+
+type fakeDecayedFeedback struct{ *fakeFeedback }
+
+func (f fakeDecayedFeedback) DecayedFeedbackScores(_ context.Context, refIDs []string, _ string, _ time.Duration) (map[string]FeedbackStat, error) {
+	return f.pick(f.decayed, refIDs), f.err
+}
+
+func (f *fakeFeedback) pick(stats map[string]FeedbackStat, refIDs []string) map[string]FeedbackStat {
+	out := make(map[string]FeedbackStat)
+	for _, id := range refIDs {
+		if s, ok := stats[id]; ok {
+			out[id] = s
+		}
+	}
+	return out
+}
+
+func TestFeedbackPolicyMultiplier(t *testing.T) {
+	p := DefaultFeedbackPolicy()
+	tests := []struct {
+		name string
+		stat FeedbackStat
+		want float64
+	}{
+		{"no ratings", FeedbackStat{}, 1},
+		{"one negative", FeedbackStat{Avg: -1, Count: 1}, math.Pow(2, -0.52)},
+		{"one positive", FeedbackStat{Avg: 1, Count: 1}, math.Pow(2, 0.52)},
+		{"full confidence negative", FeedbackStat{Avg: -1, Count: 5}, 0.5},
+		{"beyond the cap", FeedbackStat{Avg: 1, Count: 50}, 2},
+		{"fresh decayed equals plain", FeedbackStat{Avg: -1, Count: 5, Weight: 5}, 0.5},
+		{"stale ratings fade", FeedbackStat{Avg: -1, Count: 5, Weight: 0.01}, math.Pow(2, -(0.4+0.6*0.002)*0.01)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Multiplier(tt.stat); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Multiplier(%+v) = %.6f, want %.6f", tt.stat, got, tt.want)
+			}
+		})
+	}
+
+	// Custom policy: harsher factor, single rating at full confidence.
+	custom := FeedbackPolicy{BaseWeight: 1, MaxFactor: 4}
+	if got := custom.Multiplier(FeedbackStat{Avg: -1, Count: 1}); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("custom Multiplier = %v, want 0.25", got)
+	}
+}
+
+func TestFeedbackPolicyFromEnv(t *testing.T) {
+	t.Setenv("TESTFB_BASE_WEIGHT", "0.5")
+	t.Setenv("TESTFB_MAX_FACTOR", "3")
+	t.Setenv("TESTFB_HALF_LIFE", "720h")
+	p, err := FeedbackPolicyFromEnv("TESTFB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.BaseWeight != 0.5 || p.MaxFactor != 3 || p.ConfidenceCap != DefaultFeedbackConfidenceCap || p.HalfLife != 720*time.Hour {
+		t.Errorf("policy = %+v", p)
+	}
+
+	t.Setenv("TESTFB_HALF_LIFE", "off")
+	if p, err = FeedbackPolicyFromEnv("TESTFB"); err != nil || p.HalfLife >= 0 {
+		t.Errorf("half-life off: %+v, %v", p, err)
+	}
+	t.Setenv("TESTFB_MAX_FACTOR", "0.5")
+	if _, err := FeedbackPolicyFromEnv("TESTFB"); err == nil {
+		t.Error("expected an error for a max factor below 1")
+	}
+	t.Setenv("TESTFB_MAX_FACTOR", "x")
+	if _, err := FeedbackPolicyFromEnv("TESTFB"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestScoreResults_FeedbackStage(t *testing.T) {
+	fts := ftsHits(1, "a", 10.0, 2, "b", 9.0, 3, "c", 8.0)
+	opts := SearchOpts{MaxResults: 10, FTSWeight: 1}
+	fb := &fakeFeedback{plain: map[string]FeedbackStat{
+		"1": {Avg: -1, Count: 10}, // long history of complaints
+		"3": {Avg: 1, Count: 10},
+	}}
+
+	got, err := ScoreResults(context.Background(), nil, &FeedbackStage{Scorer: fb, Policy: DefaultFeedbackPolicy()}, "q", fts, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{3, 2, 1}; !equalIDs(ids(got), want) {
+		t.Errorf("order = %v, want %v", ids(got), want)
+	}
+
+	// Decay: the same complaints, long ago, barely count.
+	dfb := fakeDecayedFeedback{&fakeFeedback{
+		plain:   fb.plain,
+		decayed: map[string]FeedbackStat{"1": {Avg: -1, Count: 10, Weight: 0.05}},
+	}}
+	got, err = ScoreResults(context.Background(), nil, &FeedbackStage{Scorer: dfb, Policy: DefaultFeedbackPolicy()}, "q", fts, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !equalIDs(ids(got), want) {
+		t.Errorf("decayed order = %v, want %v", ids(got), want)
+	}
+
+	// A failing scorer leaves first-stage order intact.
+	broken := &fakeFeedback{err: errors.New("db down")}
+	got, err = ScoreResults(context.Background(), nil, &FeedbackStage{Scorer: broken}, "q", fts, nil, opts)
+	if err != nil {
+		t.Fatalf("scorer failure must not fail search: %v", err)
+	}
+	if want := []int64{1, 2, 3}; !equalIDs(ids(got), want) {
+		t.Errorf("order on scorer failure = %v, want %v", ids(got), want)
+	}
+}
+
+func TestFetchFeedback_DecayFallback(t *testing.T) {
+	plain := &fakeFeedback{plain: map[string]FeedbackStat{"1": {Avg: 1, Count: 2}}}
+	stats, err := FetchFeedback(context.Background(), plain, []string{"1"}, RefTypeFact, DefaultFeedbackPolicy())
+	if err != nil || stats["1"].Count != 2 {
+		t.Fatalf("plain scorer: %v, %v", stats, err)
+	}
+
+	inner := &fakeFeedback{
+		plain:   map[string]FeedbackStat{"1": {Avg: 1, Count: 2}},
+		decayed: map[string]FeedbackStat{"1": {Avg: 1, Count: 2, Weight: 1.5}},
+	}
+	decayed := fakeDecayedFeedback{inner}
+	if stats, _ = FetchFeedback(context.Background(), decayed, []string{"1"}, RefTypeFact, DefaultFeedbackPolicy()); stats["1"].Weight != 1.5 {
+		t.Errorf("decaying policy should use decayed stats, got %+v", stats["1"])
+	}
+	noDecay := DefaultFeedbackPolicy()
+	noDecay.HalfLife = -1
+	if stats, _ = FetchFeedback(context.Background(), decayed, []string{"1"}, RefTypeFact, noDecay); stats["1"].Weight != 0 {
+		t.Errorf("non-decaying policy should use plain stats, got %+v", stats["1"])
+	}
+}
@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// aliasRequest is the body of POST /v1/aliases.
+type aliasRequest struct {
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+}
+
+// aliasRewriteRequest is the body of POST /v1/aliases/rewrite.
+type aliasRewriteRequest struct {
+	Alias string `json:"alias,omitempty"` // empty rewrites every alias
+}
+
+// subjectAliaser returns the request's store as a memstore.SubjectAliaser,
+// writing 501 if the backend keeps no aliases.
+func (h *Handler) subjectAliaser(w http.ResponseWriter, r *http.Request) (memstore.SubjectAliaser, bool) {
+	sa, ok := storeFromCtx(r.Context(), h.store).(memstore.SubjectAliaser)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend does not support subject aliases")
+	}
+	return sa, ok
+}
+
+// handleListAliases implements GET /v1/aliases.
+func (h *Handler) handleListAliases(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.subjectAliaser(w, r)
+	if !ok {
+		return
+	}
+	aliases, err := sa.Aliases(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if aliases == nil {
+		aliases = []memstore.SubjectAlias{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"aliases": aliases})
+}
+
+// handleAddAlias implements POST /v1/aliases: make alias another name for
+// canonical. The response carries the canonical subject the alias was
+// actually filed under.
+func (h *Handler) handleAddAlias(w http.ResponseWriter, r *http.Request) {
+	var req aliasRequest
+	if !readJSON(r, w, &req) {
+		return
+	}
+	if err := memstore.CheckAlias(req.Alias, req.Canonical); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sa, ok := h.subjectAliaser(w, r)
+	if !ok {
+		return
+	}
+	a, err := sa.AddAlias(r.Context(), req.Alias, req.Canonical)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleRemoveAlias implements DELETE /v1/aliases/{alias}.
+func (h *Handler) handleRemoveAlias(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.subjectAliaser(w, r)
+	if !ok {
+		return
+	}
+	err := sa.RemoveAlias(r.Context(), r.PathValue("alias"))
+	if errors.Is(err, memstore.ErrAliasNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleRewriteAliases implements POST /v1/aliases/rewrite: move facts
+// filed under an alias, or under every alias, to the canonical subject.
+func (h *Handler) handleRewriteAliases(w http.ResponseWriter, r *http.Request) {
+	var req aliasRewriteRequest
+	if !readJSON(r, w, &req) {
+		return
+	}
+	sa, ok := h.subjectAliaser(w, r)
+	if !ok {
+		return
+	}
+	res, err := sa.RewriteAliasedFacts(r.Context(), req.Alias)
+	if errors.Is(err, memstore.ErrAliasNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
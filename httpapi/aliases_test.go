@@ -0,0 +1,162 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestSubjectAliases(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	id, err := store.Insert(ctx, memstore.Fact{Content: "the fork adds a redis cache", Subject: "memstore-fork", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := doJSON(t, h, "POST", "/v1/aliases", map[string]string{"alias": "memstore-fork", "canonical": "memstore"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("add: expected 200, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "POST", "/v1/aliases", map[string]string{"alias": "memstore", "canonical": "MEMSTORE"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("self-alias: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/aliases", nil)
+	var list struct {
+		Aliases []memstore.SubjectAlias `json:"aliases"`
+	}
+	decodeJSON(t, resp, &list)
+	if len(list.Aliases) != 1 || list.Aliases[0].Canonical != "memstore" {
+		t.Fatalf("aliases = %+v, want memstore-fork -> memstore", list.Aliases)
+	}
+
+	resp = doJSON(t, h, "POST", "/v1/aliases/rewrite", map[string]string{})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("rewrite: expected 200, got %d", resp.StatusCode)
+	}
+	var res memstore.AliasRewriteResult
+	decodeJSON(t, resp, &res)
+	if res.Facts != 1 {
+		t.Errorf("rewrite = %+v, want 1 fact", res)
+	}
+	if f, err := store.Get(ctx, id); err != nil || f.Subject != "memstore" {
+		t.Errorf("rewritten fact: subject %q, %v; want memstore", f.Subject, err)
+	}
+
+	resp = doJSON(t, h, "DELETE", "/v1/aliases/memstore-fork", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("remove: expected 200, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "DELETE", "/v1/aliases/memstore-fork", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second remove: expected 404, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "POST", "/v1/aliases/rewrite", map[string]string{"alias": "memstore-fork"})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("rewrite of a removed alias: expected 404, got %d", resp.StatusCode)
+	}
+
+	// Aliases may hold a slash, escaped in the path.
+	if _, err := store.AddAlias(ctx, "infodancer/oidclient", "oidclient"); err != nil {
+		t.Fatal(err)
+	}
+	resp = doJSON(t, h, "DELETE", "/v1/aliases/infodancer%2Foidclient", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("remove an alias with a slash: expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestRecall_ProjectAlias(t *testing.T) {
+	h, store, _ := newTestHandlerWithRecall(t)
+	ctx := context.Background()
+
+	for i := range 10 {
+		store.Insert(ctx, memstore.Fact{
+			Content:  fmt.Sprintf("Background fact %d about various items", i),
+			Subject:  "filler",
+			Category: "project",
+		})
+	}
+	// Filed under the repo's old name, before it was renamed.
+	store.Insert(ctx, memstore.Fact{
+		Content:  "oauthkit wraps go-oidc for OIDC authentication",
+		Subject:  "oidclient",
+		Category: "project",
+		Kind:     "decision",
+	})
+	store.Insert(ctx, memstore.Fact{
+		Content:  "wrapping oauthkit OAuth2 authentication requires client credentials",
+		Subject:  "other",
+		Category: "project",
+	})
+	if _, err := store.AddAlias(ctx, "oidclient", "oauthkit"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := doJSON(t, h, "POST", "/v1/recall", map[string]any{
+		"prompt": "oauthkit oauth2 authentication wrapping",
+		"cwd":    "/home/matthew/go/src/github.com/infodancer/oauthkit",
+	})
+	var result struct {
+		Facts []struct {
+			Subject string `json:"subject"`
+		} `json:"facts"`
+	}
+	decodeJSON(t, resp, &result)
+	if len(result.Facts) == 0 || result.Facts[0].Subject != "oidclient" {
+		t.Errorf("recall = %+v, want the fact filed under the old name first", result.Facts)
+	}
+}
+
+func TestRecall_CWDTriggerAlias(t *testing.T) {
+	h, store, _ := newTestHandlerWithRecall(t)
+	ctx := context.Background()
+
+	triggerMeta, _ := json.Marshal(map[string]any{
+		"signal_type":  "cwd_pattern",
+		"signal":       "**/oauthkit/**",
+		"load_subject": "oauthkit",
+	})
+	store.Insert(ctx, memstore.Fact{
+		Content:  "Load oauthkit conventions",
+		Subject:  "global",
+		Category: "project",
+		Kind:     "trigger",
+		Metadata: triggerMeta,
+	})
+	// Filed under the old name; the trigger names the new one.
+	store.Insert(ctx, memstore.Fact{
+		Content:  "Token refresh always goes through the shared transport",
+		Subject:  "oidclient",
+		Category: "project",
+		Kind:     "convention",
+	})
+	if _, err := store.AddAlias(ctx, "oidclient", "oauthkit"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A checkout still under the old directory name.
+	resp := doJSON(t, h, "POST", "/v1/recall", map[string]any{
+		"prompt": "working on shortcodes",
+		"cwd":    "/home/matthew/src/oidclient/cmd",
+	})
+	var result struct {
+		Facts []struct {
+			Content string `json:"content"`
+		} `json:"facts"`
+	}
+	decodeJSON(t, resp, &result)
+	for _, f := range result.Facts {
+		if f.Content == "Token refresh always goes through the shared transport" {
+			return
+		}
+	}
+	t.Errorf("recall = %+v, want the trigger's convention loaded", result.Facts)
+}
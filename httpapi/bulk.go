@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// handleBulkUpdate implements POST /v1/admin/bulk-update: re-classify every
+// fact a List filter matches. The body is a memstore.BulkUpdateRequest;
+// send it with dry_run first and pass the preview's token back to apply, so
+// the apply is refused (409) if the matched set moved in between.
+func (h *Handler) handleBulkUpdate(w http.ResponseWriter, r *http.Request) {
+	var req memstore.BulkUpdateRequest
+	if !readJSON(r, w, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	bu, ok := storeFromCtx(r.Context(), h.store).(memstore.BulkUpdater)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend cannot bulk-update facts")
+		return
+	}
+	res, err := bu.BulkUpdate(r.Context(), req)
+	if errors.Is(err, memstore.ErrBulkPreviewStale) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// handleAuditLog implements GET /v1/admin/audit: the most recent audit
+// entries, newest first.
+//
+// Query parameters: limit (max entries; 0 or absent = all).
+func (h *Handler) handleAuditLog(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+v)
+			return
+		}
+		limit = n
+	}
+	ar, ok := storeFromCtx(r.Context(), h.store).(memstore.AuditReader)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend does not keep an audit log")
+		return
+	}
+	entries, err := ar.AuditLog(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []memstore.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
@@ -0,0 +1,75 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestBulkUpdate(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	for _, content := range []string{"ci runs on push", "ci caches modules"} {
+		if _, err := store.Insert(ctx, memstore.Fact{Content: content, Subject: "build", Category: "note"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Insert(ctx, memstore.Fact{Content: "tabs, not spaces", Subject: "style", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ci := "ci"
+	req := memstore.BulkUpdateRequest{
+		Filter: memstore.QueryOpts{Subject: "build"},
+		Change: memstore.BulkChange{Subject: &ci},
+		DryRun: true,
+	}
+
+	resp := doJSON(t, h, "POST", "/v1/admin/bulk-update", memstore.BulkUpdateRequest{Change: req.Change})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unfiltered: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "POST", "/v1/admin/bulk-update", req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("preview: expected 200, got %d", resp.StatusCode)
+	}
+	var preview memstore.BulkResult
+	decodeJSON(t, resp, &preview)
+	if preview.Matched != 2 || preview.Applied || preview.Token == "" {
+		t.Fatalf("preview = %+v, want 2 matched, not applied, with a token", preview)
+	}
+
+	req.DryRun = false
+	req.Token = "0000"
+	resp = doJSON(t, h, "POST", "/v1/admin/bulk-update", req)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("stale token: expected 409, got %d", resp.StatusCode)
+	}
+
+	req.Token = preview.Token
+	resp = doJSON(t, h, "POST", "/v1/admin/bulk-update", req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("apply: expected 200, got %d", resp.StatusCode)
+	}
+	var applied memstore.BulkResult
+	decodeJSON(t, resp, &applied)
+	if !applied.Applied || applied.AuditID == 0 {
+		t.Fatalf("apply = %+v, want applied with an audit entry", applied)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/admin/audit", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("audit: expected 200, got %d", resp.StatusCode)
+	}
+	var log struct {
+		Entries []memstore.AuditEntry `json:"entries"`
+	}
+	decodeJSON(t, resp, &log)
+	if len(log.Entries) != 1 || log.Entries[0].ID != applied.AuditID || len(log.Entries[0].FactIDs) != 2 {
+		t.Fatalf("audit log = %+v, want the one bulk update over 2 facts", log.Entries)
+	}
+}
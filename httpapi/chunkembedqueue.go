@@ -0,0 +1,121 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/matthewjhunter/go-embedding"
+	"github.com/matthewjhunter/memstore"
+)
+
+// ChunkEmbedQueue embeds document chunks in the background: the corpus
+// counterpart of EmbedQueue. Ingest stays model-free (docs/document-ingest.md)
+// and stores chunks unembedded; this queue fills the vector column afterwards,
+// so a chunk is FTS-searchable immediately and vector-searchable once reached.
+type ChunkEmbedQueue struct {
+	store    memstore.DocumentStore
+	embedder embedding.Embedder
+	interval time.Duration
+	batch    int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewChunkEmbedQueue creates a background chunk embedding processor. It polls
+// for unembedded chunks every interval and processes them in batches.
+func NewChunkEmbedQueue(store memstore.DocumentStore, embedder embedding.Embedder, interval time.Duration, batchSize int) *ChunkEmbedQueue {
+	if interval == 0 {
+		interval = 2 * time.Second
+	}
+	if batchSize == 0 {
+		batchSize = 32
+	}
+	return &ChunkEmbedQueue{
+		store:    store,
+		embedder: embedder,
+		interval: interval,
+		batch:    batchSize,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins the background embedding loop.
+func (cq *ChunkEmbedQueue) Start() {
+	cq.wg.Add(1)
+	go cq.loop()
+}
+
+// Stop signals the loop to stop and waits for it to finish.
+func (cq *ChunkEmbedQueue) Stop() {
+	close(cq.done)
+	cq.wg.Wait()
+}
+
+func (cq *ChunkEmbedQueue) loop() {
+	defer cq.wg.Done()
+	ticker := time.NewTicker(cq.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cq.done:
+			return
+		case <-ticker.C:
+			cq.ProcessOnce()
+		}
+	}
+}
+
+// ProcessOnce drains one tick's worth of unembedded chunks. Called from the
+// background loop and exposed for tests.
+//
+// Chunks are embedded one at a time for the same reason EmbedQueue embeds
+// facts one at a time: a single unembeddable input must not fail, and so
+// stall, the whole batch. The embed input is DocumentChunk.EmbedText -- the
+// derived heading/scope/signature context plus the verbatim span -- which is
+// assembled here and never written back.
+func (cq *ChunkEmbedQueue) ProcessOnce() {
+	ctx := context.Background()
+	chunks, err := cq.store.NeedingChunkEmbedding(ctx, cq.batch)
+	if err != nil {
+		log.Printf("chunk embed queue: NeedingChunkEmbedding: %v", err)
+		return
+	}
+	if len(chunks) == 0 {
+		return
+	}
+
+	embedded := 0
+	for _, c := range chunks {
+		embs, err := cq.embedder.Embed(ctx, []string{c.EmbedText()})
+		if err != nil {
+			// Transient failures keep the NULL embedding and retry next tick;
+			// permanent ones are quarantined so the head of the queue cannot
+			// pin it forever.
+			if !embedding.IsRetryable(err) {
+				log.Printf("chunk embed queue: quarantining chunk id=%d (permanent embed failure): %v", c.ID, err)
+				if mErr := cq.store.MarkChunkEmbedFailed(ctx, c.ID, err.Error()); mErr != nil {
+					log.Printf("chunk embed queue: MarkChunkEmbedFailed id=%d: %v", c.ID, mErr)
+				}
+				continue
+			}
+			log.Printf("chunk embed queue: Embed chunk id=%d: %v", c.ID, err)
+			continue
+		}
+		if len(embs) != 1 {
+			log.Printf("chunk embed queue: Embed chunk id=%d: got %d embeddings, want 1", c.ID, len(embs))
+			continue
+		}
+		if err := cq.store.SetChunkEmbedding(ctx, c.ID, embs[0]); err != nil {
+			log.Printf("chunk embed queue: SetChunkEmbedding id=%d: %v", c.ID, err)
+			continue
+		}
+		embedded++
+	}
+	if embedded > 0 {
+		log.Printf("chunk embed queue: embedded %d/%d chunks", embedded, len(chunks))
+	}
+}
@@ -0,0 +1,103 @@
+package httpapi_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpapi"
+)
+
+// fakeChunkStore implements the embed-queue side of memstore.DocumentStore
+// in memory. The SQLite backend does not carry the corpus and the Postgres
+// one needs MEMSTORE_TEST_PG, so the queue's own logic is exercised here.
+type fakeChunkStore struct {
+	memstore.DocumentStore // unimplemented methods panic if reached
+
+	chunks      []memstore.DocumentChunk
+	embeddings  map[int64][]float32
+	quarantined map[int64]string
+}
+
+func (f *fakeChunkStore) NeedingChunkEmbedding(_ context.Context, limit int) ([]memstore.DocumentChunk, error) {
+	var out []memstore.DocumentChunk
+	for _, c := range f.chunks {
+		if _, ok := f.embeddings[c.ID]; ok {
+			continue
+		}
+		if _, ok := f.quarantined[c.ID]; ok {
+			continue
+		}
+		out = append(out, c)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeChunkStore) SetChunkEmbedding(_ context.Context, id int64, emb []float32) error {
+	f.embeddings[id] = emb
+	return nil
+}
+
+func (f *fakeChunkStore) MarkChunkEmbedFailed(_ context.Context, id int64, reason string) error {
+	f.quarantined[id] = reason
+	return nil
+}
+
+// recordingEmbedder captures every embed input so the test can assert what
+// text the queue assembled.
+type recordingEmbedder struct {
+	permanentEmbedder
+	inputs []string
+}
+
+func (r *recordingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	r.inputs = append(r.inputs, texts...)
+	return r.permanentEmbedder.Embed(ctx, texts)
+}
+
+func TestChunkEmbedQueue_EmbedsDerivedContextAndQuarantines(t *testing.T) {
+	store := &fakeChunkStore{
+		chunks: []memstore.DocumentChunk{
+			{ID: 1, Content: "UNEMBEDDABLE span"},
+			{ID: 2, Content: "func Close() error", ScopePath: "pkg > Close", Signature: "func Close() error"},
+			{ID: 3, Content: "Body text.", HeadingPath: "Design > Storage"},
+		},
+		embeddings:  map[int64][]float32{},
+		quarantined: map[int64]string{},
+	}
+	emb := &recordingEmbedder{permanentEmbedder: permanentEmbedder{dim: 4, marker: "UNEMBEDDABLE"}}
+
+	q := httpapi.NewChunkEmbedQueue(store, emb, 0, 32)
+	q.ProcessOnce()
+
+	if _, ok := store.quarantined[1]; !ok {
+		t.Error("permanently failing chunk was not quarantined")
+	}
+	if len(store.embeddings) != 2 {
+		t.Fatalf("expected the 2 healthy chunks embedded past the poisoned head, got %d", len(store.embeddings))
+	}
+
+	want := map[string]bool{
+		"scope: pkg > Close\nsignature: func Close() error\nfunc Close() error": true,
+		"section: Design > Storage\nBody text.":                                 true,
+	}
+	for _, in := range emb.inputs {
+		delete(want, in)
+	}
+	if len(want) != 0 {
+		t.Errorf("embed inputs %q missing expected texts %v", emb.inputs, want)
+	}
+	if store.chunks[2].Content != "Body text." {
+		t.Error("queue mutated chunk Content")
+	}
+
+	// Nothing left to do: a second tick must not re-attempt the quarantined chunk.
+	before := len(emb.inputs)
+	q.ProcessOnce()
+	if len(emb.inputs) != before {
+		t.Errorf("second tick re-embedded %d chunks; want 0", len(emb.inputs)-before)
+	}
+}
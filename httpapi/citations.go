@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// citer narrows the per-request scoped store to memstore.Citer. Citations
+// point into the document corpus, which the SQLite backend does not carry.
+func (h *Handler) citer(w http.ResponseWriter, r *http.Request) (memstore.Citer, bool) {
+	c, ok := storeFromCtx(r.Context(), h.store).(memstore.Citer)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend does not carry the document corpus (Postgres required)")
+		return nil, false
+	}
+	return c, true
+}
+
+// handleCite implements POST /v1/facts/{id}/citations: cite a document chunk
+// (body {"chunk_id": N}, from a document search hit) from the fact.
+func (h *Handler) handleCite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	var input struct {
+		ChunkID int64 `json:"chunk_id"`
+	}
+	if !readJSON(r, w, &input) {
+		return
+	}
+	if input.ChunkID <= 0 {
+		writeError(w, http.StatusBadRequest, "chunk_id is required")
+		return
+	}
+	ct, ok := h.citer(w, r)
+	if !ok {
+		return
+	}
+	c, err := ct.CiteChunk(r.Context(), id, input.ChunkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// handleCitations implements GET /v1/facts/{id}/citations: the fact's
+// citations, each with the chunk it resolves to in the latest ingest.
+func (h *Handler) handleCitations(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	ct, ok := h.citer(w, r)
+	if !ok {
+		return
+	}
+	cs, err := ct.Citations(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if cs == nil {
+		cs = []memstore.Citation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"citations": cs})
+}
+
+// handleStaleCitations implements GET /v1/citations/stale: citations on
+// active facts whose code changed or vanished, longest stale first.
+//
+// Query parameters: limit (max entries; 0 or absent = all).
+func (h *Handler) handleStaleCitations(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+v)
+			return
+		}
+		limit = n
+	}
+	ct, ok := h.citer(w, r)
+	if !ok {
+		return
+	}
+	cs, err := ct.StaleCitations(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if cs == nil {
+		cs = []memstore.Citation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"citations": cs})
+}
+
+// handleAcceptCitation implements POST /v1/citations/{id}/accept: the fact
+// still holds against the changed code, so re-snapshot the citation.
+func (h *Handler) handleAcceptCitation(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	ct, ok := h.citer(w, r)
+	if !ok {
+		return
+	}
+	c, err := ct.AcceptCitation(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// handleDeleteCitation implements DELETE /v1/citations/{id}.
+func (h *Handler) handleDeleteCitation(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	ct, ok := h.citer(w, r)
+	if !ok {
+		return
+	}
+	if err := ct.DeleteCitation(r.Context(), id); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
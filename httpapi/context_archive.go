@@ -23,6 +23,8 @@ func (h *Handler) handleStoreHint(w http.ResponseWriter, r *http.Request) {
 		CandidateScores map[string]float64 `json:"candidate_scores"`
 		SearchQuery     string             `json:"search_query"`
 		RankerVersion   string             `json:"ranker_version"`
+		Experiment      string             `json:"experiment"`
+		Arm             string             `json:"arm"`
 		Relevance       float64            `json:"relevance"`
 		Desirability    float64            `json:"desirability"`
 	}
@@ -47,6 +49,8 @@ func (h *Handler) handleStoreHint(w http.ResponseWriter, r *http.Request) {
 		CandidateScores: input.CandidateScores,
 		SearchQuery:     input.SearchQuery,
 		RankerVersion:   input.RankerVersion,
+		Experiment:      input.Experiment,
+		Arm:             input.Arm,
 		Relevance:       input.Relevance,
 		Desirability:    input.Desirability,
 	}
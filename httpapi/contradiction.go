@@ -0,0 +1,43 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// handleContradictions implements GET /v1/contradictions: the contradiction
+// review queue -- contradicts links raised by the background audit that are
+// not dismissed and whose facts are both still active, oldest first.
+// Dismissing an entry goes through PATCH /v1/links/{id}; resolving one by
+// superseding or deleting a fact takes it off the queue.
+//
+// Query parameters: limit (max entries; 0 or absent = all).
+func (h *Handler) handleContradictions(w http.ResponseWriter, r *http.Request) {
+	cq, ok := storeFromCtx(r.Context(), h.store).(memstore.ContradictionQueue)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend has no contradiction queue")
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+v)
+			return
+		}
+		limit = n
+	}
+
+	items, err := cq.Contradictions(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []memstore.Contradiction{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"contradictions": items})
+}
@@ -0,0 +1,52 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestContradictions(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	a, _ := store.Insert(ctx, memstore.Fact{Content: "retries are capped at 3", Subject: "retries", Category: "project"})
+	b, _ := store.Insert(ctx, memstore.Fact{Content: "retries are unlimited", Subject: "retries", Category: "project"})
+	link, err := store.LinkFacts(ctx, a, b, memstore.LinkContradicts, true, "capped vs unlimited",
+		map[string]any{"status": memstore.ContradictionOpen, "model": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := doJSON(t, h, "GET", "/v1/contradictions?limit=-1", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("negative limit: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/contradictions", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("contradictions: expected 200, got %d", resp.StatusCode)
+	}
+	var queue struct {
+		Contradictions []memstore.Contradiction `json:"contradictions"`
+	}
+	decodeJSON(t, resp, &queue)
+	if len(queue.Contradictions) != 1 {
+		t.Fatalf("contradictions = %+v, want one", queue.Contradictions)
+	}
+	if c := queue.Contradictions[0]; c.LinkID != link || c.A.ID != a || c.B.ID != b || c.Reason != "capped vs unlimited" || c.Model != "test" {
+		t.Errorf("entry = %+v", c)
+	}
+
+	resp = doJSON(t, h, "PATCH", "/v1/links/"+itoa(link), map[string]any{"metadata": map[string]any{"status": memstore.ContradictionDismissed}})
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("dismiss via PATCH: got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "GET", "/v1/contradictions", nil)
+	decodeJSON(t, resp, &queue)
+	if len(queue.Contradictions) != 0 {
+		t.Errorf("after dismissal = %+v, want empty", queue.Contradictions)
+	}
+}
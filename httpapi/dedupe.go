@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// handleDuplicates implements GET /v1/admin/duplicates: a ranked report of
+// clusters of near-duplicate active facts across every subject in the
+// caller's scope. It is admin-scoped because it is a whole-store scan; acting
+// on a cluster goes through the ordinary merge and supersede routes.
+//
+// Query parameters: threshold (minimum cosine similarity, default
+// memstore.DefaultDedupeThreshold), lexical_threshold (minimum term overlap
+// for unembedded facts, default memstore.DefaultDedupeLexicalThreshold),
+// neighbors (candidates per fact), limit (max clusters).
+func (h *Handler) handleDuplicates(w http.ResponseWriter, r *http.Request) {
+	d, ok := storeFromCtx(r.Context(), h.store).(memstore.Deduper)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend cannot scan for duplicates")
+		return
+	}
+
+	q := r.URL.Query()
+	var opts memstore.DedupeOpts
+	for _, p := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"threshold", &opts.Threshold},
+		{"lexical_threshold", &opts.LexicalThreshold},
+	} {
+		if v := q.Get(p.name); v != "" {
+			t, err := strconv.ParseFloat(v, 64)
+			if err != nil || t <= 0 || t > 1 {
+				writeError(w, http.StatusBadRequest, "invalid "+p.name+": "+v)
+				return
+			}
+			*p.dst = t
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"neighbors", &opts.Neighbors},
+		{"limit", &opts.Limit},
+	} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid "+p.name+": "+v)
+				return
+			}
+			*p.dst = n
+		}
+	}
+
+	clusters, err := d.FindDuplicates(r.Context(), opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if clusters == nil {
+		clusters = []memstore.DuplicateCluster{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"clusters": clusters})
+}
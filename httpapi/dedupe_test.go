@@ -0,0 +1,53 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestDuplicates(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for _, f := range []struct {
+		content string
+		emb     []float32
+	}{
+		{"the api listens on port 8230", []float32{1, 0, 0, 0}},
+		{"api port is 8230", []float32{0.99, 0.05, 0, 0}},
+		{"tabs, not spaces", []float32{0, 1, 0, 0}},
+	} {
+		id, err := store.Insert(ctx, memstore.Fact{Content: f.content, Subject: "test", Category: "note"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetEmbedding(ctx, id, f.emb); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	resp := doJSON(t, h, "GET", "/v1/admin/duplicates?threshold=2", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("threshold out of range: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/admin/duplicates?threshold=0.9", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("duplicates: expected 200, got %d", resp.StatusCode)
+	}
+	var report struct {
+		Clusters []memstore.DuplicateCluster `json:"clusters"`
+	}
+	decodeJSON(t, resp, &report)
+	if len(report.Clusters) != 1 || len(report.Clusters[0].Facts) != 2 {
+		t.Fatalf("duplicates = %+v, want one cluster of two", report.Clusters)
+	}
+	if p := report.Clusters[0].Pairs[0]; p.A != ids[0] || p.B != ids[1] || p.Method != memstore.DedupeEmbedding {
+		t.Errorf("pair = %+v, want %d~%d by embedding", p, ids[0], ids[1])
+	}
+}
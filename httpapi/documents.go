@@ -355,13 +355,19 @@ func (h *Handler) handleDocumentUpload(w http.ResponseWriter, r *http.Request) {
 // index, separate tool -- and every hit carries its citation.
 func (h *Handler) handleDocumentSearch(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Query            string `json:"query"`
-		MaxResults       int    `json:"max_results"`
-		RepoURL          string `json:"repo_url"`
-		PathPrefix       string `json:"path_prefix"`
-		Basename         string `json:"basename"`
-		Lang             string `json:"lang"`
-		IncludeGenerated bool   `json:"include_generated"`
+		Query            string  `json:"query"`
+		MaxResults       int     `json:"max_results"`
+		RepoURL          string  `json:"repo_url"`
+		PathPrefix       string  `json:"path_prefix"`
+		Basename         string  `json:"basename"`
+		Lang             string  `json:"lang"`
+		IncludeGenerated bool    `json:"include_generated"`
+		Mode             string  `json:"mode"` // fts (default) | vector | hybrid
+		RerankMode       string  `json:"rerank_mode"`
+		RerankThreshold  float64 `json:"rerank_threshold"`
+		RerankCandidates int     `json:"rerank_candidates"`
+		RerankWeight     float64 `json:"rerank_weight"`
+		RerankDocBytes   int     `json:"rerank_doc_bytes"`
 	}
 	if !readJSON(r, w, &input) {
 		return
@@ -370,19 +376,51 @@ func (h *Handler) handleDocumentSearch(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "query is required")
 		return
 	}
+	mode, err := memstore.ParseDocumentSearchMode(input.Mode)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Strict, like mode: this route is new enough to have no clients relying
+	// on fact search's lenient unknown-mode-means-off behaviour.
+	rerankMode, err := memstore.ParseRerankMode(input.RerankMode)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.RerankThreshold < 0 || input.RerankThreshold > 1 {
+		writeError(w, http.StatusBadRequest, "rerank_threshold must be in [0,1]")
+		return
+	}
 	ds, ok := h.docStore(w, r)
 	if !ok {
 		return
 	}
 
-	results, err := ds.SearchDocumentChunks(r.Context(), input.Query, memstore.DocumentSearchOpts{
+	opts := memstore.DocumentSearchOpts{
 		MaxResults:       input.MaxResults,
 		RepoURL:          input.RepoURL,
 		PathPrefix:       input.PathPrefix,
 		Basename:         input.Basename,
 		Lang:             input.Lang,
 		IncludeGenerated: input.IncludeGenerated,
-	})
+		Mode:             mode,
+		RerankMode:       rerankMode,
+		RerankThreshold:  input.RerankThreshold,
+		RerankCandidates: input.RerankCandidates,
+		RerankWeight:     input.RerankWeight,
+		RerankDocBytes:   input.RerankDocBytes,
+	}
+	// The daemon's search pool and doc budget apply when the request leaves
+	// them unset, exactly as for fact search.
+	if opts.RerankCandidates <= 0 && h.rerankPoolSize > 0 {
+		opts.RerankCandidates = h.rerankPoolSize
+	}
+	if opts.RerankDocBytes <= 0 && h.rerankDocBytes > 0 {
+		opts.RerankDocBytes = h.rerankDocBytes
+	}
+
+	results, err := ds.SearchDocumentChunks(r.Context(), input.Query, opts)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -398,6 +436,7 @@ func (h *Handler) handleDocumentSearch(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, map[string]any{
 		"query":   input.Query,
+		"mode":    mode,
 		"results": hits,
 	})
 }
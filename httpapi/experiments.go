@@ -0,0 +1,163 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// experimentRefresh is how often the active experiment list is re-read from
+// its source, and so how long an admin stop takes to reach a running daemon.
+const experimentRefresh = 30 * time.Second
+
+// experimentSet caches the active experiments from an ExperimentSource. A
+// nil *experimentSet has no experiments, so callers need not check.
+type experimentSet struct {
+	src memstore.ExperimentSource
+
+	mu      sync.Mutex
+	exps    []memstore.Experiment
+	fetched time.Time
+}
+
+func newExperimentSet(src memstore.ExperimentSource) *experimentSet {
+	if src == nil {
+		return nil
+	}
+	return &experimentSet{src: src}
+}
+
+// active returns the running experiments, refreshing at most every
+// experimentRefresh. A failed refresh keeps the previous list: an experiment
+// should neither start nor stop because the database blinked.
+func (s *experimentSet) active(ctx context.Context) []memstore.Experiment {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.fetched.IsZero() && time.Since(s.fetched) < experimentRefresh {
+		return s.exps
+	}
+	exps, err := s.src.ActiveExperiments(ctx)
+	if err != nil {
+		log.Printf("experiments: refresh failed, keeping %d cached: %v", len(s.exps), err)
+	} else {
+		s.exps = exps
+	}
+	s.fetched = time.Now()
+	return s.exps
+}
+
+// enrolled returns the experiment a session takes part in: the oldest
+// running experiment that enrolls it. Sessions without an ID never enroll,
+// since nothing could be attributed to them.
+func (s *experimentSet) enrolled(ctx context.Context, sessionID string) (memstore.Experiment, bool) {
+	if sessionID == "" {
+		return memstore.Experiment{}, false
+	}
+	for _, e := range s.active(ctx) {
+		if e.Enrolls(sessionID) {
+			return e, true
+		}
+	}
+	return memstore.Experiment{}, false
+}
+
+// WithExperiments enables online ranking experiments in /v1/recall, read
+// from src (typically a pgstore.ExperimentStore) and refreshed periodically.
+func WithExperiments(src memstore.ExperimentSource) HandlerOpt {
+	return func(h *Handler) { h.experiments = newExperimentSet(src) }
+}
+
+// recallRanker is one configuration of the recall scoring pipeline: the
+// daemon's own, or an experiment arm's overlay on it.
+type recallRanker struct {
+	tuning          RecallTuning
+	rerankMode      memstore.RerankMode
+	rerankThreshold float64
+	arm             string // experiment arm name; "" for the daemon's configuration
+}
+
+func (h *Handler) baseRanker() recallRanker {
+	return recallRanker{
+		tuning:          h.recallTuning,
+		rerankMode:      h.rerankMode,
+		rerankThreshold: h.rerankThreshold,
+	}
+}
+
+// armRanker overlays the i'th arm's params on the daemon's configuration.
+func (h *Handler) armRanker(e memstore.Experiment, i int) recallRanker {
+	rk := h.baseRanker()
+	p := e.Arms[i].Params
+	for _, o := range []struct {
+		src *float64
+		dst *float64
+	}{
+		{p.MinScoreRatio, &rk.tuning.MinScoreRatio},
+		{p.MinAbsoluteScore, &rk.tuning.MinAbsoluteScore},
+		{p.VecBoostWeight, &rk.tuning.VecBoostWeight},
+		{p.VecOnlyWeight, &rk.tuning.VecOnlyWeight},
+		{p.ProjectSurfaceBoost, &rk.tuning.ProjectSurfaceBoost},
+		{p.RerankThreshold, &rk.rerankThreshold},
+	} {
+		if o.src != nil {
+			*o.dst = *o.src
+		}
+	}
+	if p.RerankMode != "" {
+		rk.rerankMode, _ = memstore.ParseRerankMode(p.RerankMode) // validated at load
+	}
+	rk.arm = e.Arms[i].Name
+	return rk
+}
+
+// applyArmSearch overlays an arm's params on the hint searcher's options.
+func applyArmSearch(opts *memstore.SearchOpts, p memstore.RankerParams) {
+	opts.FTSWeight = p.FTSWeight
+	opts.VecWeight = p.VecWeight
+	if p.RerankMode != "" {
+		opts.RerankMode, _ = memstore.ParseRerankMode(p.RerankMode) // validated at load
+	}
+	if p.RerankThreshold != nil {
+		opts.RerankThreshold = *p.RerankThreshold
+	}
+}
+
+// recallRankers returns the ranker configurations a recall runs under and
+// the experiment they belong to: the daemon's own ("" experiment), the
+// session's arm in a split, or both arms of an interleaving experiment.
+func (h *Handler) recallRankers(ctx context.Context, sessionID string) ([]recallRanker, string) {
+	e, ok := h.experiments.enrolled(ctx, sessionID)
+	if !ok {
+		return []recallRanker{h.baseRanker()}, ""
+	}
+	if e.Mode == memstore.ExperimentInterleave {
+		return []recallRanker{h.armRanker(e, 0), h.armRanker(e, 1)}, e.Name
+	}
+	return []recallRanker{h.armRanker(e, e.ArmFor(sessionID))}, e.Name
+}
+
+// interleaveCandidates team-draft interleaves two arms' rankings. Each
+// merged candidate keeps the score and arm of the ranking that contributed
+// it, so the injection is credited to that arm.
+func interleaveCandidates(a, b []scoredFact, seed uint64) []scoredFact {
+	byID := [2]map[int64]scoredFact{make(map[int64]scoredFact, len(a)), make(map[int64]scoredFact, len(b))}
+	var ids [2][]int64
+	for t, list := range [2][]scoredFact{a, b} {
+		for _, c := range list {
+			byID[t][c.fact.ID] = c
+			ids[t] = append(ids[t], c.fact.ID)
+		}
+	}
+	merged, team := memstore.TeamDraftInterleave(ids[0], ids[1], seed)
+	out := make([]scoredFact, len(merged))
+	for i, id := range merged {
+		out[i] = byID[team[i]][id]
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package httpapi_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpapi"
+)
+
+// staticExperiments is an ExperimentSource with a fixed experiment list.
+type staticExperiments []memstore.Experiment
+
+func (s staticExperiments) ActiveExperiments(context.Context) ([]memstore.Experiment, error) {
+	return s, nil
+}
+
+// armSessionStore is a mockSessionStore that also records arm attribution.
+type armSessionStore struct {
+	mockSessionStore
+	arms []memstore.ArmAssignment // parallel to injections recorded via RecordArmInjection
+}
+
+func (m *armSessionStore) RecordArmInjection(ctx context.Context, sessionID, refID, refType string, rank int, arm memstore.ArmAssignment) error {
+	m.arms = append(m.arms, arm)
+	return m.RecordInjection(ctx, sessionID, refID, refType, rank)
+}
+
+func newExperimentHandler(t *testing.T, ss *armSessionStore, exps ...memstore.Experiment) *httpapi.Handler {
+	t.Helper()
+	return newRecallCorpusHandler(t,
+		httpapi.WithSessionStore(ss),
+		httpapi.WithExperiments(staticExperiments(exps)),
+	)
+}
+
+// newRecallCorpusHandler returns a handler over a small corpus in which the
+// prompt recallAs sends matches four facts, amid unrelated filler and one
+// match too weak to pass the score floors.
+func newRecallCorpusHandler(t *testing.T, opts ...httpapi.HandlerOpt) *httpapi.Handler {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	embedder := &mockEmbedder{dim: 4}
+	store, err := memstore.NewSQLiteStore(db, embedder, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for i := range 10 {
+		store.Insert(ctx, memstore.Fact{
+			Content:  fmt.Sprintf("Background fact %d about miscellaneous topics", i),
+			Subject:  "filler",
+			Category: "project",
+		})
+	}
+	for _, c := range []string{
+		"The extraction pipeline parses markdown frontmatter",
+		"The extraction pipeline batches markdown documents for embedding",
+		"Markdown headings drive the extraction pipeline chunker",
+		"The pipeline retries extraction when markdown parsing fails",
+	} {
+		if _, err := store.Insert(ctx, memstore.Fact{Content: c, Subject: "memstore", Category: "project"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A weak, off-project match that the score floors drop.
+	if _, err := store.Insert(ctx, memstore.Fact{Content: "Markdown tables render poorly in narrow terminals", Subject: "terminals", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+	sc := httpapi.NewSessionContext()
+	t.Cleanup(sc.Stop)
+	return httpapi.New(store, embedder, "", append([]httpapi.HandlerOpt{httpapi.WithSessionContext(sc)}, opts...)...)
+}
+
+type experimentRecall struct {
+	Experiment string `json:"experiment"`
+	Facts      []struct {
+		ID  int64  `json:"id"`
+		Arm string `json:"arm"`
+	} `json:"facts"`
+}
+
+func recallAs(t *testing.T, h *httpapi.Handler, sessionID string) experimentRecall {
+	t.Helper()
+	resp := doJSON(t, h, "POST", "/v1/recall", map[string]any{
+		"prompt":     "extraction pipeline markdown",
+		"session_id": sessionID,
+		"cwd":        "/home/matthew/go/src/github.com/matthewjhunter/memstore",
+	})
+	var out experimentRecall
+	decodeJSON(t, resp, &out)
+	if len(out.Facts) == 0 {
+		t.Fatal("expected facts")
+	}
+	return out
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestRecall_SplitExperimentTagsSessionArm(t *testing.T) {
+	exp := memstore.Experiment{
+		Name: "floor",
+		Mode: memstore.ExperimentSplit,
+		Arms: []memstore.ExperimentArm{
+			{Name: "control"},
+			{Name: "loose", Params: memstore.RankerParams{MinScoreRatio: floatPtr(0.05)}},
+		},
+	}
+	ss := &armSessionStore{}
+	h := newExperimentHandler(t, ss, exp)
+
+	const session = "split-session"
+	want := exp.Arms[exp.ArmFor(session)].Name
+	got := recallAs(t, h, session)
+	if got.Experiment != "floor" {
+		t.Errorf("experiment = %q, want floor", got.Experiment)
+	}
+	for _, f := range got.Facts {
+		if f.Arm != want {
+			t.Errorf("fact %d arm = %q, want the session's arm %q", f.ID, f.Arm, want)
+		}
+	}
+	if len(ss.arms) != len(got.Facts) || len(ss.injections) != len(got.Facts) {
+		t.Fatalf("recorded %d arm injections for %d facts", len(ss.arms), len(got.Facts))
+	}
+	for _, a := range ss.arms {
+		if a != (memstore.ArmAssignment{Experiment: "floor", Arm: want}) {
+			t.Errorf("recorded assignment %+v, want floor/%s", a, want)
+		}
+	}
+}
+
+func TestRecall_InterleaveCreditsBothArms(t *testing.T) {
+	exp := memstore.Experiment{
+		Name: "vec",
+		Mode: memstore.ExperimentInterleave,
+		Arms: []memstore.ExperimentArm{
+			{Name: "a"},
+			{Name: "b", Params: memstore.RankerParams{VecOnlyWeight: floatPtr(2)}},
+		},
+	}
+	ss := &armSessionStore{}
+	h := newExperimentHandler(t, ss, exp)
+
+	got := recallAs(t, h, "interleave-session")
+	if len(got.Facts) < 2 {
+		t.Fatalf("need at least 2 facts to interleave, got %d", len(got.Facts))
+	}
+	counts := map[string]int{}
+	for _, f := range got.Facts {
+		counts[f.Arm]++
+	}
+	if counts["a"] == 0 || counts["b"] == 0 || counts["a"]+counts["b"] != len(got.Facts) {
+		t.Errorf("arm credits = %v, want both arms and nothing else", counts)
+	}
+	for i, a := range ss.arms {
+		if a.Experiment != "vec" || a.Arm != got.Facts[i].Arm {
+			t.Errorf("injection %d recorded %+v, want vec/%s", i, a, got.Facts[i].Arm)
+		}
+	}
+}
+
+func TestRecall_UnenrolledSessionNotAttributed(t *testing.T) {
+	exp := memstore.Experiment{
+		Name:    "tiny",
+		Mode:    memstore.ExperimentSplit,
+		Traffic: 0.01,
+		Arms:    []memstore.ExperimentArm{{Name: "x"}, {Name: "y"}},
+	}
+	session := ""
+	for i := range 1000 {
+		if id := fmt.Sprintf("s%d", i); !exp.Enrolls(id) {
+			session = id
+			break
+		}
+	}
+	if session == "" {
+		t.Fatal("no unenrolled session found")
+	}
+	ss := &armSessionStore{}
+	h := newExperimentHandler(t, ss, exp)
+
+	got := recallAs(t, h, session)
+	if got.Experiment != "" {
+		t.Errorf("experiment = %q for an unenrolled session", got.Experiment)
+	}
+	if len(ss.arms) != 0 {
+		t.Errorf("recorded %d arm injections, want none", len(ss.arms))
+	}
+	if len(ss.injections) != len(got.Facts) {
+		t.Errorf("recorded %d plain injections for %d facts", len(ss.injections), len(got.Facts))
+	}
+}
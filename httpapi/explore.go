@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// defaultExploreTopK bounds swap exploration to the top three displayed facts
+// when ExplorationPolicy.SwapTopK is unset: deep enough to vary the position
+// of the facts that matter, shallow enough that a swap never buries the best
+// fact below the fold.
+const defaultExploreTopK = 3
+
+// ExplorationPolicy randomizes a small fraction of recalls so the injection
+// log carries position-varied data. Without it the ranker always shows its
+// best facts first, and the position bias in the resulting feedback cannot be
+// told apart from relevance (see docs/training-data-design.md).
+//
+// A recall explores at most once: with probability SwapRate it swaps the top
+// fact with one drawn uniformly from the rest of the top SwapTopK; otherwise,
+// with probability Epsilon, it includes the best candidate that fell below the
+// score floors in the last displayed slot. Either way a recall makes one
+// exploratory change at most, moving or adding a single item, and the
+// displayed and original ranks of what it touched are logged through
+// memstore.ExplorationRecorder. The zero value never explores.
+type ExplorationPolicy struct {
+	SwapRate float64 // fraction of recalls that swap within the top SwapTopK
+	SwapTopK int     // swap depth; 0 = defaultExploreTopK
+	Epsilon  float64 // fraction of recalls that include one below-floor candidate
+}
+
+// Enabled reports whether the policy ever explores.
+func (p ExplorationPolicy) Enabled() bool {
+	return p.SwapRate > 0 || p.Epsilon > 0
+}
+
+// Validate checks the policy's ranges.
+func (p ExplorationPolicy) Validate() error {
+	if p.SwapRate < 0 || p.SwapRate > 1 {
+		return fmt.Errorf("httpapi: exploration swap rate %v outside [0,1]", p.SwapRate)
+	}
+	if p.Epsilon < 0 || p.Epsilon > 1 {
+		return fmt.Errorf("httpapi: exploration epsilon %v outside [0,1]", p.Epsilon)
+	}
+	if p.SwapRate+p.Epsilon > 1 {
+		return fmt.Errorf("httpapi: exploration swap rate + epsilon = %v exceeds 1", p.SwapRate+p.Epsilon)
+	}
+	if p.SwapTopK < 0 || p.SwapTopK == 1 {
+		return fmt.Errorf("httpapi: exploration swap top-k %d must be 0 (default) or at least 2", p.SwapTopK)
+	}
+	return nil
+}
+
+// explore applies at most one exploratory change to the ranked candidates, of
+// which the first shown are displayed. below holds the candidates the score
+// floors dropped, best first. It returns the candidate list to select from;
+// the exploratory items are tagged with their exploration kind and keep the
+// original ranks they were given by the ranker.
+func (p ExplorationPolicy) explore(candidates, below []scoredFact, shown, limit int, rng *rand.Rand) []scoredFact {
+	u := rng.Float64()
+	switch {
+	case u < p.SwapRate:
+		k := p.SwapTopK
+		if k == 0 {
+			k = defaultExploreTopK
+		}
+		k = min(k, shown)
+		if k < 2 {
+			return candidates
+		}
+		out := append([]scoredFact(nil), candidates...)
+		j := 1 + rng.IntN(k-1)
+		out[0], out[j] = out[j], out[0]
+		out[0].explore = memstore.ExploreSwap
+		out[j].explore = memstore.ExploreSwap
+		return out
+	case u < p.SwapRate+p.Epsilon:
+		if len(below) == 0 || shown == 0 {
+			return candidates
+		}
+		keep := min(shown, limit-1)
+		out := append([]scoredFact(nil), candidates[:keep]...)
+		pick := below[0]
+		pick.explore = memstore.ExploreEpsilon
+		return append(out, pick)
+	}
+	return candidates
+}
+
+// exploreRand returns the exploration RNG for one recall. It is seeded from
+// the session and prompt, so a retried prompt explores the same way.
+func exploreRand(sessionID, prompt string) *rand.Rand {
+	seed := memstore.ExperimentSeed(sessionID, prompt)
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
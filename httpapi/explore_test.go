@@ -0,0 +1,122 @@
+package httpapi_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpapi"
+)
+
+// exploreSessionStore is a mockSessionStore that also logs explorations.
+type exploreSessionStore struct {
+	mockSessionStore
+	explored []exploredInjection
+}
+
+type exploredInjection struct {
+	RefID              string
+	Rank, OriginalRank int
+	Kind               string
+}
+
+func (m *exploreSessionStore) RecordExploration(ctx context.Context, sessionID, refID, refType string, rank, originalRank int, kind string) error {
+	m.explored = append(m.explored, exploredInjection{refID, rank, originalRank, kind})
+	return m.RecordInjection(ctx, sessionID, refID, refType, rank)
+}
+
+func TestRecall_ExplorationOffByDefault(t *testing.T) {
+	ss := &exploreSessionStore{}
+	h := newRecallCorpusHandler(t, httpapi.WithSessionStore(ss))
+	for i := range 20 {
+		got := recallAs(t, h, fmt.Sprintf("default-%d", i))
+		if len(ss.injections) == 0 || len(got.Facts) == 0 {
+			t.Fatal("expected injections")
+		}
+	}
+	if len(ss.explored) != 0 {
+		t.Errorf("recorded %d explorations with no policy", len(ss.explored))
+	}
+}
+
+func TestRecall_SwapExploration(t *testing.T) {
+	baseline := recallAs(t, newRecallCorpusHandler(t), "swap-session")
+
+	ss := &exploreSessionStore{}
+	h := newRecallCorpusHandler(t, httpapi.WithSessionStore(ss),
+		httpapi.WithExploration(httpapi.ExplorationPolicy{SwapRate: 1, SwapTopK: 3}))
+	got := recallAs(t, h, "swap-session")
+	if len(baseline.Facts) < 2 || len(got.Facts) != len(baseline.Facts) {
+		t.Fatalf("baseline %d facts, explored %d", len(baseline.Facts), len(got.Facts))
+	}
+	if len(ss.explored) != 2 {
+		t.Fatalf("recorded %d explorations, want the swapped pair: %+v", len(ss.explored), ss.explored)
+	}
+	top, other := ss.explored[0], ss.explored[1]
+	if top.Rank != 0 || other.OriginalRank != 0 || top.OriginalRank != other.Rank || other.Rank < 1 || other.Rank > 2 {
+		t.Errorf("swap logged as %+v, want rank 0 and a top-3 rank exchanged", ss.explored)
+	}
+	for _, e := range ss.explored {
+		if e.Kind != memstore.ExploreSwap {
+			t.Errorf("kind = %q, want swap", e.Kind)
+		}
+		if want := strconv.FormatInt(baseline.Facts[e.OriginalRank].ID, 10); e.RefID != want {
+			t.Errorf("fact at original rank %d = %s, want %s", e.OriginalRank, e.RefID, want)
+		}
+	}
+	// Everything else is shown, and logged, in score order.
+	if len(ss.injections) != len(got.Facts) {
+		t.Errorf("recorded %d injections for %d facts", len(ss.injections), len(got.Facts))
+	}
+	for i := range got.Facts {
+		if i != top.Rank && i != other.Rank && got.Facts[i].ID != baseline.Facts[i].ID {
+			t.Errorf("rank %d moved: %d, want %d", i, got.Facts[i].ID, baseline.Facts[i].ID)
+		}
+	}
+}
+
+func TestRecall_EpsilonExploration(t *testing.T) {
+	baseline := recallAs(t, newRecallCorpusHandler(t), "epsilon-session")
+
+	ss := &exploreSessionStore{}
+	h := newRecallCorpusHandler(t, httpapi.WithSessionStore(ss),
+		httpapi.WithExploration(httpapi.ExplorationPolicy{Epsilon: 1}))
+	got := recallAs(t, h, "epsilon-session")
+	if len(ss.explored) != 1 {
+		t.Fatalf("recorded %d explorations, want exactly one: %+v", len(ss.explored), ss.explored)
+	}
+	e := ss.explored[0]
+	if e.Kind != memstore.ExploreEpsilon || e.Rank != len(got.Facts)-1 || e.OriginalRank < len(baseline.Facts) {
+		t.Errorf("epsilon logged as %+v with %d facts shown, %d in baseline", e, len(got.Facts), len(baseline.Facts))
+	}
+	for _, f := range baseline.Facts {
+		if strconv.FormatInt(f.ID, 10) == e.RefID {
+			t.Errorf("explored fact %s was already in the unexplored result", e.RefID)
+		}
+	}
+	for i := range len(got.Facts) - 1 {
+		if got.Facts[i].ID != baseline.Facts[i].ID {
+			t.Errorf("rank %d moved: %d, want %d", i, got.Facts[i].ID, baseline.Facts[i].ID)
+		}
+	}
+}
+
+func TestExplorationPolicyValidate(t *testing.T) {
+	for _, tt := range []struct {
+		p  httpapi.ExplorationPolicy
+		ok bool
+	}{
+		{httpapi.ExplorationPolicy{}, true},
+		{httpapi.ExplorationPolicy{SwapRate: 0.02, Epsilon: 0.01, SwapTopK: 5}, true},
+		{httpapi.ExplorationPolicy{SwapRate: -0.1}, false},
+		{httpapi.ExplorationPolicy{Epsilon: 1.5}, false},
+		{httpapi.ExplorationPolicy{SwapRate: 0.6, Epsilon: 0.6}, false},
+		{httpapi.ExplorationPolicy{SwapRate: 0.1, SwapTopK: 1}, false},
+	} {
+		if err := tt.p.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok=%v", tt.p, err, tt.ok)
+		}
+	}
+}
@@ -26,12 +26,28 @@ import (
 // UserID is the database ID of the owning user, stamped at enqueue time
 // from the request identity. A zero UserID means the job was enqueued on
 // the legacy single-key path and falls back to the queue's base store.
+// Origin is that identity's name, recorded as the provenance origin of every
+// fact the job writes.
 type extractJob struct {
 	SessionID string
 	CWD       string
 	Persona   string
 	Turns     []memstore.SessionTurn
 	UserID    int64
+	Origin    string
+}
+
+// provenance returns the provenance stamped on facts the job writes with
+// the given source kind.
+func (job extractJob) provenance(source string) memstore.Provenance {
+	first, last := memstore.TurnRange(job.Turns)
+	return memstore.Provenance{
+		Source:    source,
+		SessionID: job.SessionID,
+		TurnFirst: first,
+		TurnLast:  last,
+		Origin:    job.Origin,
+	}
 }
 
 // Hint generation constants.
@@ -186,15 +202,16 @@ func (q *ExtractQueue) backfillFeedback(ctx context.Context, store memstore.Stor
 // If hintStore also implements hintRater, a third stage auto-rates hints from
 // the previous session that were injected into this one.
 type ExtractQueue struct {
-	extractor *memstore.FactExtractor
-	store     memstore.Store
-	embedder  embedding.Embedder // retained so per-job extractors can be constructed
-	generator memstore.Generator
-	hintStore hintWriter // nil = hint generation disabled
-	rater     hintRater  // nil = auto-rating disabled; set when hintStore implements hintRater
-	jobs      chan extractJob
-	done      chan struct{}
-	wg        sync.WaitGroup
+	extractor   *memstore.FactExtractor
+	store       memstore.Store
+	embedder    embedding.Embedder // retained so per-job extractors can be constructed
+	generator   memstore.Generator
+	hintStore   hintWriter     // nil = hint generation disabled
+	rater       hintRater      // nil = auto-rating disabled; set when hintStore implements hintRater
+	experiments *experimentSet // nil = hints always use the default search
+	jobs        chan extractJob
+	done        chan struct{}
+	wg          sync.WaitGroup
 }
 
 // NewExtractQueue creates an ExtractQueue with a buffered job channel.
@@ -216,6 +233,16 @@ func NewExtractQueue(store memstore.Store, embedder embedding.Embedder, generato
 	return q
 }
 
+// SetExperiments runs hint generation under online ranking experiments read
+// from src. A session enrolled in a split experiment has its hints searched
+// with its arm's params and stamped with the arm's ranker version, so hint
+// feedback can be compared per arm. Interleaving experiments are recall-only:
+// a hint is a single synthesized text that cannot credit two rankers.
+// Call before Start.
+func (q *ExtractQueue) SetExperiments(src memstore.ExperimentSource) {
+	q.experiments = newExperimentSet(src)
+}
+
 // Start launches the background worker goroutine.
 func (q *ExtractQueue) Start() {
 	q.wg.Go(func() {
@@ -275,6 +302,7 @@ func (q *ExtractQueue) ProcessOnce() bool {
 func (q *ExtractQueue) processJob(job extractJob) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
+	ctx = memstore.WithProvenance(ctx, job.provenance(memstore.SourceExtraction))
 
 	// Resolve per-job scoped store. When the backend implements UserScoper and
 	// the job carries a non-zero UserID (bearer-token path), all fact reads and
@@ -437,14 +465,24 @@ func (q *ExtractQueue) generateHintsScoped(_ context.Context, job extractJob, pr
 
 	snippet := buildScoreSnippet(job.Turns)
 
+	searchOpts := memstore.SearchOpts{
+		Subject:    projectName,
+		MaxResults: 5,
+		OnlyActive: true,
+	}
+	rankerVersion := hintRankerVersion
+	var arm memstore.ArmAssignment
+	if e, ok := q.experiments.enrolled(ctx, job.SessionID); ok && e.Mode == memstore.ExperimentSplit {
+		i := e.ArmFor(job.SessionID)
+		applyArmSearch(&searchOpts, e.Arms[i].Params)
+		rankerVersion = e.RankerVersionFor(i)
+		arm = e.Assignment(i)
+	}
+
 	var searchResults []memstore.SearchResult
 	searchQuery := buildSearchQuery(job.Turns)
 	if searchQuery != "" {
-		results, err := store.Search(ctx, searchQuery, memstore.SearchOpts{
-			Subject:    projectName,
-			MaxResults: 5,
-			OnlyActive: true,
-		})
+		results, err := store.Search(ctx, searchQuery, searchOpts)
 		if err != nil {
 			log.Printf("hint: session %s: searcher: %v", job.SessionID, err)
 		} else {
@@ -487,7 +525,9 @@ func (q *ExtractQueue) generateHintsScoped(_ context.Context, job extractJob, pr
 		RetrievedIDs:    retrievedIDs,
 		CandidateScores: candidateScores,
 		SearchQuery:     searchQuery,
-		RankerVersion:   hintRankerVersion,
+		RankerVersion:   rankerVersion,
+		Experiment:      arm.Experiment,
+		Arm:             arm.Arm,
 		Relevance:       avgVecScore(searchResults),
 		Desirability:    score,
 	}
@@ -1063,11 +1103,12 @@ func (q *ExtractQueue) summarizeAndPersistScoped(ctx context.Context, job extrac
 		"project":    projectName,
 	})
 	summaryFact := memstore.Fact{
-		Content:  rendered,
-		Subject:  subject,
-		Category: category,
-		Kind:     "summary",
-		Metadata: json.RawMessage(summaryMeta),
+		Content:    rendered,
+		Subject:    subject,
+		Category:   category,
+		Kind:       "summary",
+		Metadata:   json.RawMessage(summaryMeta),
+		Provenance: job.provenance(memstore.SourceSummary),
 	}
 	if _, err := store.Insert(ctx, summaryFact); err != nil {
 		log.Printf("summary: session %s: insert failed: %v", job.SessionID, err)
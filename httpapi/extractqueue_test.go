@@ -402,7 +402,8 @@ func TestSummarizeAndPersist_OKInserts(t *testing.T) {
 	job := extractJob{
 		SessionID: "sess-ok",
 		CWD:       "/tmp/foo",
-		Turns:     []memstore.SessionTurn{{Role: "user", Content: "let's work"}},
+		Turns:     []memstore.SessionTurn{{UUID: "u1", Role: "user", Content: "let's work"}, {UUID: "u2", Role: "assistant", Content: "on it"}},
+		Origin:    "laptop",
 	}
 	q.summarizeAndPersist(context.Background(), job, "foo")
 	if len(store.inserts) != 1 {
@@ -418,6 +419,10 @@ func TestSummarizeAndPersist_OKInserts(t *testing.T) {
 	if !strings.Contains(string(got.Metadata), `"scope":"project"`) {
 		t.Errorf("expected scope=project in metadata: %s", got.Metadata)
 	}
+	want := memstore.Provenance{Source: memstore.SourceSummary, SessionID: "sess-ok", TurnFirst: "u1", TurnLast: "u2", Origin: "laptop"}
+	if got.Provenance != want {
+		t.Errorf("provenance = %+v, want %+v", got.Provenance, want)
+	}
 }
 
 func TestSummarizeAndPersist_GeneralScopeRoutes(t *testing.T) {
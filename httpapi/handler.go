@@ -73,6 +73,10 @@ type Handler struct {
 	recallPoolSize  int // recall candidate pool cap; 0 = built-in default
 	rerankDocBytes  int // search per-doc truncation budget; 0 = built-in default
 	recallDocBytes  int // recall per-doc truncation budget; 0 = built-in default
+	recallTuning    RecallTuning
+	feedbackPolicy  memstore.FeedbackPolicy
+	experiments     *experimentSet // nil = no online experiments
+	exploration     ExplorationPolicy
 
 	maxBodyBytes int64 // cap applied to every request body; default 64 MB
 }
@@ -118,6 +122,25 @@ func WithReranker(rr embedding.Reranker, pol memstore.RerankPolicy) HandlerOpt {
 	}
 }
 
+// WithRecallTuning overrides the /v1/recall scoring knobs. Used by the
+// offline evaluation harness; the daemon runs DefaultRecallTuning.
+func WithRecallTuning(t RecallTuning) HandlerOpt {
+	return func(h *Handler) { h.recallTuning = t }
+}
+
+// WithFeedbackPolicy sets how rating history scales recall scores. The
+// default is memstore.DefaultFeedbackPolicy; memstored reads it from
+// MEMSTORE_FEEDBACK_* so recall and Store.Search share one policy.
+func WithFeedbackPolicy(p memstore.FeedbackPolicy) HandlerOpt {
+	return func(h *Handler) { h.feedbackPolicy = p }
+}
+
+// WithExploration enables position-randomized exploration in /v1/recall
+// under the given policy. The zero policy, the default, never explores.
+func WithExploration(p ExplorationPolicy) HandlerOpt {
+	return func(h *Handler) { h.exploration = p }
+}
+
 // WithTokenVerifier enables bearer-token auth backed by the given verifier
 // (typically a pgstore.TokenStore). When set, requests must carry a valid
 // token; the legacy single-key check is bypassed.
@@ -134,11 +157,13 @@ func WithMaxBodyBytes(n int64) HandlerOpt {
 // If apiKey is non-empty, requests must include Authorization: Bearer <key>.
 func New(store memstore.Store, embedder embedding.Embedder, apiKey string, opts ...HandlerOpt) *Handler {
 	h := &Handler{
-		store:        store,
-		embedder:     embedder,
-		apiKey:       apiKey,
-		mux:          smoke.NewMux(),
-		maxBodyBytes: 64 << 20,
+		store:          store,
+		embedder:       embedder,
+		apiKey:         apiKey,
+		mux:            smoke.NewMux(),
+		recallTuning:   DefaultRecallTuning(),
+		feedbackPolicy: memstore.DefaultFeedbackPolicy(),
+		maxBodyBytes:   64 << 20,
 	}
 	for _, opt := range opts {
 		opt(h)
@@ -214,6 +239,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx := context.WithValue(r.Context(), scopedStoreKey{}, scopedStore)
 	ctx = context.WithValue(ctx, scopedSessionKey{}, scopedSess)
+	if id.Name != "" {
+		// Every fact written under this request is attributed to the caller.
+		ctx = memstore.WithProvenance(ctx, memstore.Provenance{Origin: id.Name})
+	}
 	r = r.WithContext(ctx)
 
 	if r.Body != nil {
@@ -250,25 +279,54 @@ func (h *Handler) registerRoutes() {
 	h.mux.HandleFunc("GET /v1/facts/{id}", h.requireScope(ScopeRead, h.handleGet), smoke.Example("id", "1"))
 	h.mux.HandleFunc("GET /v1/facts", h.requireScope(ScopeRead, h.handleList))
 	h.mux.HandleFunc("DELETE /v1/facts/{id}", h.requireScope(ScopeWrite, h.handleDelete), smoke.Write())
+	h.mux.HandleFunc("PATCH /v1/facts/{id}", h.requireScope(ScopeWrite, h.handleRevise), smoke.Write())
 	h.mux.HandleFunc("PATCH /v1/facts/{id}/metadata", h.requireScope(ScopeWrite, h.handleUpdateMetadata), smoke.Write())
 	h.mux.HandleFunc("POST /v1/facts/{id}/supersede", h.requireScope(ScopeWrite, h.handleSupersede), smoke.Write())
 	h.mux.HandleFunc("POST /v1/facts/{id}/confirm", h.requireScope(ScopeWrite, h.handleConfirm), smoke.Write())
+	h.mux.HandleFunc("POST /v1/facts/merge", h.requireScope(ScopeWrite, h.handleMerge), smoke.Write())
 	h.mux.HandleFunc("POST /v1/facts/touch", h.requireScope(ScopeWrite, h.handleTouch), smoke.Write())
 	h.mux.HandleFunc("POST /v1/facts/exists", h.requireScope(ScopeRead, h.handleExists), smoke.Skip("POST read; needs a JSON body (phase 2)"))
 	h.mux.HandleFunc("GET /v1/facts/count", h.requireScope(ScopeRead, h.handleActiveCount))
+	h.mux.HandleFunc("GET /v1/review", h.requireScope(ScopeRead, h.handleReview))
 	h.mux.HandleFunc("GET /v1/facts/{id}/history", h.requireScope(ScopeRead, h.handleHistoryByID), smoke.Example("id", "1"))
 	h.mux.HandleFunc("GET /v1/history/{subject}", h.requireScope(ScopeRead, h.handleHistoryBySubject), smoke.Example("subject", "smoke"))
 
+	h.mux.HandleFunc("GET /v1/trash", h.requireScope(ScopeRead, h.handleTrash))
+	h.mux.HandleFunc("POST /v1/facts/{id}/restore", h.requireScope(ScopeWrite, h.handleRestore), smoke.Write())
+	h.mux.HandleFunc("POST /v1/trash/purge", h.requireScope(ScopeWrite, h.handlePurge), smoke.Write())
+
 	h.mux.HandleFunc("POST /v1/search", h.requireScope(ScopeRead, h.handleSearch), smoke.Skip("POST read; needs a JSON body (phase 2)"))
 	h.mux.HandleFunc("POST /v1/search/fts", h.requireScope(ScopeRead, h.handleSearchFTS), smoke.Skip("POST read; needs a JSON body (phase 2)"))
 
 	h.mux.HandleFunc("GET /v1/subsystems", h.requireScope(ScopeRead, h.handleListSubsystems))
 
+	h.mux.HandleFunc("GET /v1/tags", h.requireScope(ScopeRead, h.handleTagCounts))
+	h.mux.HandleFunc("POST /v1/facts/{id}/tags", h.requireScope(ScopeWrite, h.handleTagFact), smoke.Write())
+	h.mux.HandleFunc("DELETE /v1/facts/{id}/tags", h.requireScope(ScopeWrite, h.handleUntagFact), smoke.Write())
+	h.mux.HandleFunc("POST /v1/tags/{tag}/rename", h.requireScope(ScopeWrite, h.handleRenameTag), smoke.Write())
+	h.mux.HandleFunc("DELETE /v1/tags/{tag}", h.requireScope(ScopeWrite, h.handleDeleteTag), smoke.Write())
+
+	h.mux.HandleFunc("GET /v1/aliases", h.requireScope(ScopeRead, h.handleListAliases))
+	h.mux.HandleFunc("POST /v1/aliases", h.requireScope(ScopeWrite, h.handleAddAlias), smoke.Write())
+	h.mux.HandleFunc("DELETE /v1/aliases/{alias}", h.requireScope(ScopeWrite, h.handleRemoveAlias), smoke.Write())
+	h.mux.HandleFunc("POST /v1/aliases/rewrite", h.requireScope(ScopeWrite, h.handleRewriteAliases), smoke.Write())
+
+	h.mux.HandleFunc("GET /v1/pins", h.requireScope(ScopeRead, h.handleListPins))
+	h.mux.HandleFunc("POST /v1/pins", h.requireScope(ScopeWrite, h.handlePin), smoke.Write())
+	h.mux.HandleFunc("DELETE /v1/pins/{id}", h.requireScope(ScopeWrite, h.handleUnpin), smoke.Write())
+
 	h.mux.HandleFunc("POST /v1/links", h.requireScope(ScopeWrite, h.handleLinkFacts), smoke.Write())
 	h.mux.HandleFunc("GET /v1/links/{id}", h.requireScope(ScopeRead, h.handleGetLink), smoke.Example("id", "1"))
 	h.mux.HandleFunc("GET /v1/facts/{id}/links", h.requireScope(ScopeRead, h.handleGetLinks), smoke.Example("id", "1"))
 	h.mux.HandleFunc("PATCH /v1/links/{id}", h.requireScope(ScopeWrite, h.handleUpdateLink), smoke.Write())
 	h.mux.HandleFunc("DELETE /v1/links/{id}", h.requireScope(ScopeWrite, h.handleDeleteLink), smoke.Write())
+	h.mux.HandleFunc("GET /v1/contradictions", h.requireScope(ScopeRead, h.handleContradictions))
+
+	h.mux.HandleFunc("POST /v1/facts/{id}/citations", h.requireScope(ScopeWrite, h.handleCite), smoke.Write())
+	h.mux.HandleFunc("GET /v1/facts/{id}/citations", h.requireScope(ScopeRead, h.handleCitations), smoke.Skip("citations live in the Postgres document corpus; no corpus in the probe"))
+	h.mux.HandleFunc("GET /v1/citations/stale", h.requireScope(ScopeRead, h.handleStaleCitations), smoke.Skip("citations live in the Postgres document corpus; no corpus in the probe"))
+	h.mux.HandleFunc("POST /v1/citations/{id}/accept", h.requireScope(ScopeWrite, h.handleAcceptCitation), smoke.Write())
+	h.mux.HandleFunc("DELETE /v1/citations/{id}", h.requireScope(ScopeWrite, h.handleDeleteCitation), smoke.Write())
 
 	h.mux.HandleFunc("POST /v1/generate", h.requireScope(ScopeRead, h.handleGenerate), smoke.Skip("calls the LLM; needs a JSON body (phase 2)"))
 	h.mux.HandleFunc("POST /v1/generate/json", h.requireScope(ScopeRead, h.handleGenerateJSON), smoke.Skip("calls the LLM; needs a JSON body (phase 2)"))
@@ -289,6 +347,14 @@ func (h *Handler) registerRoutes() {
 	h.mux.HandleFunc("POST /v1/context/injections", h.requireScope(ScopeWrite, h.handleRecordInjection), smoke.Write())
 	h.mux.HandleFunc("POST /v1/context/feedback", h.requireScope(ScopeWrite, h.handleRecordFeedback), smoke.Write())
 	h.mux.HandleFunc("POST /v1/context/backfill-feedback", h.requireScope(ScopeWrite, h.handleBackfillFeedback), smoke.Write())
+
+	h.mux.HandleFunc("GET /v1/admin/duplicates", h.requireScope(ScopeAdmin, h.handleDuplicates))
+	h.mux.HandleFunc("POST /v1/admin/bulk-update", h.requireScope(ScopeAdmin, h.handleBulkUpdate), smoke.Write())
+	h.mux.HandleFunc("GET /v1/admin/audit", h.requireScope(ScopeAdmin, h.handleAuditLog))
+	h.mux.HandleFunc("GET /v1/admin/namespaces", h.requireScope(ScopeAdmin, h.handleListNamespaces))
+	h.mux.HandleFunc("POST /v1/admin/namespaces/{ns}/{op}", h.requireScope(ScopeAdmin, h.handleNamespaceOp), smoke.Write())
+	h.mux.HandleFunc("DELETE /v1/admin/namespaces/{ns}", h.requireScope(ScopeAdmin, h.handleDeleteNamespace), smoke.Write())
+	h.mux.HandleFunc("GET /v1/admin/training", h.requireScope(ScopeAdmin, h.handleTrainingExport), smoke.Skip("streams a JSONL dataset from the Postgres session log; no session store in the probe"))
 }
 
 // Manifest returns the smoke route manifest recorded at registration time:
@@ -325,6 +391,17 @@ func (h *Handler) handleInsert(w http.ResponseWriter, r *http.Request) {
 		Kind      string         `json:"kind"`
 		Subsystem string         `json:"subsystem"`
 		Metadata  map[string]any `json:"metadata"`
+		ExpiresAt *time.Time     `json:"expires_at"` // absolute expiry (RFC3339)
+		TTL       string         `json:"ttl"`        // relative expiry: a Go duration, "3d", or "2w"
+		Tags      []string       `json:"tags"`
+
+		// Provenance. The origin is always the authenticated caller and
+		// cannot be asserted here.
+		Source     string `json:"source"`
+		SessionID  string `json:"session_id"`
+		TurnFirst  string `json:"turn_first"`
+		TurnLast   string `json:"turn_last"`
+		DocumentID int64  `json:"document_id"`
 	}
 	if !readJSON(r, w, &input) {
 		return
@@ -333,6 +410,19 @@ func (h *Handler) handleInsert(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "content and subject are required")
 		return
 	}
+	if input.ExpiresAt != nil && input.TTL != "" {
+		writeError(w, http.StatusBadRequest, "expires_at and ttl are mutually exclusive")
+		return
+	}
+	if input.TTL != "" {
+		ttl, err := memstore.ParseTTL(input.TTL)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		t := time.Now().UTC().Add(ttl)
+		input.ExpiresAt = &t
+	}
 
 	f := memstore.Fact{
 		Content:   input.Content,
@@ -340,15 +430,34 @@ func (h *Handler) handleInsert(w http.ResponseWriter, r *http.Request) {
 		Category:  input.Category,
 		Kind:      input.Kind,
 		Subsystem: input.Subsystem,
+		ExpiresAt: input.ExpiresAt,
 	}
+	tags, err := memstore.NormalizeTags(input.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f.Tags = tags
 	if input.Metadata != nil {
 		raw, _ := json.Marshal(input.Metadata)
 		f.Metadata = raw
 	}
+	prov, err := memstore.ResolveProvenance(r.Context(), memstore.Provenance{
+		Source:     input.Source,
+		SessionID:  input.SessionID,
+		TurnFirst:  input.TurnFirst,
+		TurnLast:   input.TurnLast,
+		DocumentID: input.DocumentID,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f.Provenance = prov
 
 	id, err := storeFromCtx(r.Context(), h.store).Insert(r.Context(), f)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
@@ -379,6 +488,7 @@ func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
 		Kind:       q.Get("kind"),
 		Subsystem:  q.Get("subsystem"),
 		OnlyActive: q.Get("active") != "false",
+		Tags:       tagFilterFromQuery(r),
 	}
 	if v := q.Get("limit"); v != "" {
 		n, _ := strconv.Atoi(v)
@@ -416,6 +526,19 @@ func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
 		}
 		opts.CreatedBefore = &t
 	}
+	opts.Provenance = memstore.ProvenanceFilter{
+		Source:    q.Get("source"),
+		SessionID: q.Get("session_id"),
+		Origin:    q.Get("origin"),
+	}
+	if v := q.Get("document_id"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid document_id: "+err.Error())
+			return
+		}
+		opts.Provenance.DocumentID = n
+	}
 
 	facts, err := storeFromCtx(r.Context(), h.store).List(r.Context(), opts)
 	if err != nil {
@@ -447,7 +570,7 @@ func (h *Handler) handleUpdateMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := storeFromCtx(r.Context(), h.store).UpdateMetadata(r.Context(), id, patch); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
@@ -475,6 +598,56 @@ func (h *Handler) handleSupersede(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "superseded"})
 }
 
+func (h *Handler) handleRevise(w http.ResponseWriter, r *http.Request) {
+	oldID, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	var input struct {
+		Content  string         `json:"content"`
+		Metadata map[string]any `json:"metadata"` // patch merged into the copied metadata
+	}
+	if !readJSON(r, w, &input) {
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+	id, err := storeFromCtx(r.Context(), h.store).Revise(r.Context(), oldID, input.Content, input.Metadata)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"id": id, "superseded": oldID})
+}
+
+func (h *Handler) handleMerge(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		IDs      []int64        `json:"ids"`
+		Content  string         `json:"content"`
+		Metadata map[string]any `json:"metadata"` // patch merged into the first source's metadata
+	}
+	if !readJSON(r, w, &input) {
+		return
+	}
+	ids, err := memstore.MergeSources(input.IDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+	id, err := storeFromCtx(r.Context(), h.store).Merge(r.Context(), ids, input.Content, input.Metadata)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id, "merged": ids})
+}
+
 func (h *Handler) handleConfirm(w http.ResponseWriter, r *http.Request) {
 	id, ok := pathInt64(r, w, "id")
 	if !ok {
@@ -526,6 +699,61 @@ func (h *Handler) handleActiveCount(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]int64{"count": count})
 }
 
+// --- Trash ---
+
+func (h *Handler) handleTrash(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := memstore.QueryOpts{
+		Subject:   q.Get("subject"),
+		Category:  q.Get("category"),
+		Kind:      q.Get("kind"),
+		Subsystem: q.Get("subsystem"),
+	}
+	if v := q.Get("limit"); v != "" {
+		n, _ := strconv.Atoi(v)
+		opts.Limit = n
+	}
+	facts, err := storeFromCtx(r.Context(), h.store).Trash(r.Context(), opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, facts)
+}
+
+func (h *Handler) handleRestore(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	if err := storeFromCtx(r.Context(), h.store).Restore(r.Context(), id); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "restored"})
+}
+
+func (h *Handler) handlePurge(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Before *time.Time `json:"before"`
+	}
+	if !readJSON(r, w, &input) {
+		return
+	}
+	// Required rather than defaulted: a missing cutoff must not empty the
+	// whole trash.
+	if input.Before == nil {
+		writeError(w, http.StatusBadRequest, "before is required")
+		return
+	}
+	n, err := storeFromCtx(r.Context(), h.store).Purge(r.Context(), *input.Before)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"purged": n})
+}
+
 // --- History ---
 
 func (h *Handler) handleHistoryByID(w http.ResponseWriter, r *http.Request) {
@@ -620,6 +848,7 @@ type searchRequest struct {
 	MetadataFilters  []memstore.MetadataFilter `json:"metadata_filters"`
 	CreatedAfter     string                    `json:"created_after"`
 	CreatedBefore    string                    `json:"created_before"`
+	Tags             memstore.TagFilter        `json:"tags"`
 }
 
 func (s *searchRequest) opts() memstore.SearchOpts {
@@ -638,6 +867,7 @@ func (s *searchRequest) opts() memstore.SearchOpts {
 		RerankDocBytes:   s.RerankDocBytes,
 		OnlyActive:       s.OnlyActive,
 		MetadataFilters:  s.MetadataFilters,
+		Tags:             s.Tags,
 	}
 	// Lenient: an unrecognized mode disables rerank rather than failing search.
 	o.RerankMode, _ = memstore.ParseRerankMode(s.RerankMode)
@@ -841,6 +1071,22 @@ func writeError(w http.ResponseWriter, code int, msg string) {
 	writeJSON(w, code, map[string]string{"error": msg})
 }
 
+// writeStoreError writes the error from a store write: 422 when the fact's
+// metadata fails its kind's schema, which the caller can fix, 409 when a
+// revision targets an expired fact, and 500 otherwise.
+func writeStoreError(w http.ResponseWriter, err error) {
+	var se *memstore.SchemaError
+	if errors.As(err, &se) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if errors.Is(err, memstore.ErrFactExpired) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
 func pathInt64(r *http.Request, w http.ResponseWriter, name string) (int64, bool) {
 	s := r.PathValue(name)
 	id, err := strconv.ParseInt(s, 10, 64)
@@ -10,7 +10,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/matthewjhunter/go-embedding"
 	"github.com/matthewjhunter/memstore"
@@ -122,6 +124,35 @@ func TestInsert_MissingFields(t *testing.T) {
 	}
 }
 
+func TestInsert_TTL(t *testing.T) {
+	h, _ := newTestHandler(t)
+	resp := doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "currently debugging the embed queue",
+		"subject": "memstore",
+		"ttl":     "2d",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("insert: expected 201, got %d", resp.StatusCode)
+	}
+	var created map[string]any
+	decodeJSON(t, resp, &created)
+
+	var fact memstore.Fact
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/facts/"+itoa(int64(created["id"].(float64))), nil), &fact)
+	if fact.ExpiresAt == nil || time.Until(*fact.ExpiresAt) < 47*time.Hour {
+		t.Errorf("ExpiresAt = %v, want ~2 days out", fact.ExpiresAt)
+	}
+
+	for _, body := range []map[string]any{
+		{"content": "x", "subject": "memstore", "ttl": "never"},
+		{"content": "x", "subject": "memstore", "ttl": "1h", "expires_at": "2030-01-01T00:00:00Z"},
+	} {
+		if resp := doJSON(t, h, "POST", "/v1/facts", body); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%v: expected 400, got %d", body, resp.StatusCode)
+		}
+	}
+}
+
 func TestGet_NotFound(t *testing.T) {
 	h, _ := newTestHandler(t)
 	resp := doJSON(t, h, "GET", "/v1/facts/999", nil)
@@ -183,6 +214,131 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestTrashRestorePurge(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	resp := doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "restore me", "subject": "test",
+	})
+	var created map[string]any
+	decodeJSON(t, resp, &created)
+	id := int64(created["id"].(float64))
+	doJSON(t, h, "DELETE", "/v1/facts/"+itoa(id), nil)
+
+	resp = doJSON(t, h, "GET", "/v1/trash?subject=test", nil)
+	var trash []memstore.Fact
+	decodeJSON(t, resp, &trash)
+	if len(trash) != 1 || trash[0].ID != id {
+		t.Fatalf("trash = %+v, want fact %d", trash, id)
+	}
+
+	resp = doJSON(t, h, "POST", "/v1/facts/"+itoa(id)+"/restore", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("restore: expected 200, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "GET", "/v1/facts/"+itoa(id), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get after restore: expected 200, got %d", resp.StatusCode)
+	}
+
+	// A purge with no cutoff is refused rather than emptying the trash.
+	resp = doJSON(t, h, "POST", "/v1/trash/purge", map[string]any{})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("purge without before: expected 400, got %d", resp.StatusCode)
+	}
+	doJSON(t, h, "DELETE", "/v1/facts/"+itoa(id), nil)
+	resp = doJSON(t, h, "POST", "/v1/trash/purge", map[string]any{"before": time.Now().Add(time.Minute)})
+	var purged map[string]int64
+	decodeJSON(t, resp, &purged)
+	if purged["purged"] != 1 {
+		t.Errorf("purge = %v, want 1 purged", purged)
+	}
+}
+
+// --- Revise ---
+
+func TestRevise(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	resp := doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "the daemon listens on 8230", "subject": "test", "category": "project",
+	})
+	var created map[string]any
+	decodeJSON(t, resp, &created)
+	id := int64(created["id"].(float64))
+
+	resp = doJSON(t, h, "PATCH", "/v1/facts/"+itoa(id), map[string]any{})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("revise without content: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "PATCH", "/v1/facts/"+itoa(id), map[string]any{
+		"content": "the daemon listens on 8231", "metadata": map[string]any{"source": "ops"},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("revise: expected 200, got %d", resp.StatusCode)
+	}
+	var revised map[string]int64
+	decodeJSON(t, resp, &revised)
+	if revised["superseded"] != id || revised["id"] == id {
+		t.Fatalf("revise = %v, want a new id superseding %d", revised, id)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/facts/"+itoa(revised["id"]), nil)
+	var f memstore.Fact
+	decodeJSON(t, resp, &f)
+	if f.Content != "the daemon listens on 8231" || f.Category != "project" || string(f.Metadata) != `{"source":"ops"}` {
+		t.Errorf("new version = %+v", f)
+	}
+
+	// The old version is superseded, so a second revision of it fails.
+	resp = doJSON(t, h, "PATCH", "/v1/facts/"+itoa(id), map[string]any{"content": "stale edit"})
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("revise superseded fact: expected 500, got %d", resp.StatusCode)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	var ids []int64
+	for _, c := range []string{"deploys go out on tuesdays", "releases ship tuesday mornings"} {
+		resp := doJSON(t, h, "POST", "/v1/facts", map[string]any{"content": c, "subject": "deploy", "category": "project"})
+		var created map[string]any
+		decodeJSON(t, resp, &created)
+		ids = append(ids, int64(created["id"].(float64)))
+	}
+
+	resp := doJSON(t, h, "POST", "/v1/facts/merge", map[string]any{"ids": ids[:1], "content": "just one"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("merge one fact: expected 400, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "POST", "/v1/facts/merge", map[string]any{"ids": ids})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("merge without content: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "POST", "/v1/facts/merge", map[string]any{"ids": ids, "content": "deploys ship tuesday mornings"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("merge: expected 201, got %d", resp.StatusCode)
+	}
+	var merged struct {
+		ID     int64   `json:"id"`
+		Merged []int64 `json:"merged"`
+	}
+	decodeJSON(t, resp, &merged)
+	if merged.ID == 0 || len(merged.Merged) != 2 {
+		t.Fatalf("merge = %+v", merged)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/facts/"+itoa(ids[1]), nil)
+	var old memstore.Fact
+	decodeJSON(t, resp, &old)
+	if old.SupersededBy == nil || *old.SupersededBy != merged.ID {
+		t.Errorf("source after merge = %+v, want superseded by %d", old, merged.ID)
+	}
+}
+
 // --- Confirm ---
 
 func TestConfirm(t *testing.T) {
@@ -468,3 +624,33 @@ func TestBodyLimit_NormalRequestPasses(t *testing.T) {
 		t.Fatalf("expected 201, got %d", resp.StatusCode)
 	}
 }
+
+func TestInsert_MetadataSchemaViolation(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	resp := doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "load web rules", "subject": "global", "category": "project", "kind": "trigger",
+		"metadata": map[string]any{"signal_type": "cwd_pattern", "pattern": "**/web/**", "load_subsystem": "web"},
+	})
+	var body map[string]string
+	decodeJSON(t, resp, &body)
+	if resp.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(body["error"], `"trigger" schema`) {
+		t.Fatalf("insert malformed trigger: status %d, body %v; want 422 naming the trigger schema", resp.StatusCode, body)
+	}
+
+	resp = doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "load web rules", "subject": "global", "category": "project", "kind": "trigger",
+		"metadata": map[string]any{"signal_type": "cwd_pattern", "signal": "**/web/**", "load_subsystem": "web"},
+	})
+	var created map[string]int64
+	decodeJSON(t, resp, &created)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("insert valid trigger: status %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "PATCH", fmt.Sprintf("/v1/facts/%d/metadata", created["id"]), map[string]any{"signal": nil})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("metadata patch dropping the signal: status %d, want 422", resp.StatusCode)
+	}
+}
@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// namespaceOpRequest is the body of the rename, copy and merge routes.
+type namespaceOpRequest struct {
+	To         string `json:"to"`
+	Duplicates string `json:"duplicates,omitempty"` // merge only: supersede (default) or keep
+}
+
+// namespaceAdmin returns the request's store as a memstore.NamespaceAdmin,
+// writing 501 if the backend cannot manage namespaces.
+func (h *Handler) namespaceAdmin(w http.ResponseWriter, r *http.Request) (memstore.NamespaceAdmin, bool) {
+	na, ok := storeFromCtx(r.Context(), h.store).(memstore.NamespaceAdmin)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend cannot manage namespaces")
+	}
+	return na, ok
+}
+
+// handleListNamespaces implements GET /v1/admin/namespaces: every namespace
+// with its fact, link and document counts.
+func (h *Handler) handleListNamespaces(w http.ResponseWriter, r *http.Request) {
+	na, ok := h.namespaceAdmin(w, r)
+	if !ok {
+		return
+	}
+	infos, err := na.Namespaces(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if infos == nil {
+		infos = []memstore.NamespaceInfo{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"namespaces": infos})
+}
+
+// handleNamespaceOp implements POST /v1/admin/namespaces/{ns}/{op} for op
+// rename, copy and merge. The body is {"to": ..., "duplicates": ...}; a
+// rename or copy onto a namespace that holds data is refused with 409.
+func (h *Handler) handleNamespaceOp(w http.ResponseWriter, r *http.Request) {
+	var req namespaceOpRequest
+	if !readJSON(r, w, &req) {
+		return
+	}
+	from, op := r.PathValue("ns"), r.PathValue("op")
+	if req.To == "" {
+		writeError(w, http.StatusBadRequest, "to is required")
+		return
+	}
+	if err := memstore.CheckNamespacePair(from, req.To); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dup, err := memstore.ParseDuplicatePolicy(req.Duplicates)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	na, ok := h.namespaceAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var res *memstore.NamespaceResult
+	switch op {
+	case "rename":
+		res, err = na.RenameNamespace(r.Context(), from, req.To)
+	case "copy":
+		res, err = na.CopyNamespace(r.Context(), from, req.To)
+	case "merge":
+		res, err = na.MergeNamespace(r.Context(), from, req.To, dup)
+	default:
+		writeError(w, http.StatusNotFound, "unknown namespace operation: "+op)
+		return
+	}
+	if errors.Is(err, memstore.ErrNamespaceNotEmpty) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// handleDeleteNamespace implements DELETE /v1/admin/namespaces/{ns}:
+// permanently remove everything in the namespace.
+func (h *Handler) handleDeleteNamespace(w http.ResponseWriter, r *http.Request) {
+	na, ok := h.namespaceAdmin(w, r)
+	if !ok {
+		return
+	}
+	res, err := na.DeleteNamespace(r.Context(), r.PathValue("ns"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
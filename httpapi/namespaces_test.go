@@ -0,0 +1,65 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestNamespaceAdmin(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	for _, content := range []string{"ci runs on push", "ci caches modules"} {
+		if _, err := store.Insert(ctx, memstore.Fact{Content: content, Subject: "build", Category: "note"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp := doJSON(t, h, "POST", "/v1/admin/namespaces/test/copy", map[string]string{"to": "backup"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("copy: expected 200, got %d", resp.StatusCode)
+	}
+	var copied memstore.NamespaceResult
+	decodeJSON(t, resp, &copied)
+	if copied.Facts != 2 {
+		t.Errorf("copy = %+v, want 2 facts", copied)
+	}
+
+	resp = doJSON(t, h, "POST", "/v1/admin/namespaces/test/rename", map[string]string{"to": "backup"})
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("rename onto a non-empty namespace: expected 409, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "POST", "/v1/admin/namespaces/test/merge", map[string]string{"to": "backup", "duplicates": "sometimes"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown duplicate policy: expected 400, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "POST", "/v1/admin/namespaces/test/shuffle", map[string]string{"to": "backup"})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown operation: expected 404, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/admin/namespaces", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("list: expected 200, got %d", resp.StatusCode)
+	}
+	var list struct {
+		Namespaces []memstore.NamespaceInfo `json:"namespaces"`
+	}
+	decodeJSON(t, resp, &list)
+	if len(list.Namespaces) != 2 || list.Namespaces[0].Namespace != "backup" || list.Namespaces[1].Active != 2 {
+		t.Fatalf("namespaces = %+v, want backup and test with 2 facts each", list.Namespaces)
+	}
+
+	resp = doJSON(t, h, "DELETE", "/v1/admin/namespaces/backup", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", resp.StatusCode)
+	}
+	var deleted memstore.NamespaceResult
+	decodeJSON(t, resp, &deleted)
+	if deleted.Facts != 2 {
+		t.Errorf("delete = %+v, want 2 facts", deleted)
+	}
+}
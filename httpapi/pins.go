@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// pinner returns the request's store as a memstore.Pinner, writing 501 if
+// the backend keeps no pins.
+func (h *Handler) pinner(w http.ResponseWriter, r *http.Request) (memstore.Pinner, bool) {
+	pn, ok := storeFromCtx(r.Context(), h.store).(memstore.Pinner)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend does not support pinned facts")
+	}
+	return pn, ok
+}
+
+// handleListPins implements GET /v1/pins. With ?cwd= it returns only the
+// pins that apply to a session there, as recall would inject them.
+func (h *Handler) handleListPins(w http.ResponseWriter, r *http.Request) {
+	pn, ok := h.pinner(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	var pins []memstore.PinnedFact
+	if cwd := r.URL.Query().Get("cwd"); cwd != "" {
+		store := storeFromCtx(ctx, h.store)
+		aliases := memstore.LoadAliases(ctx, store)
+		pins = memstore.PinsFor(ctx, store, aliases.Canonical(memstore.ProjectNameFromCWD(cwd)), cwd, aliases)
+	} else {
+		var err error
+		if pins, err = pn.Pins(ctx); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if pins == nil {
+		pins = []memstore.PinnedFact{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pins": pins})
+}
+
+// handlePin implements POST /v1/pins: pin a fact, or re-scope or re-order
+// its pin.
+func (h *Handler) handlePin(w http.ResponseWriter, r *http.Request) {
+	var req memstore.Pin
+	if !readJSON(r, w, &req) {
+		return
+	}
+	if err := memstore.CheckPin(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pn, ok := h.pinner(w, r)
+	if !ok {
+		return
+	}
+	p, err := pn.Pin(r.Context(), req)
+	if errors.Is(err, memstore.ErrNotPinnable) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// handleUnpin implements DELETE /v1/pins/{id}.
+func (h *Handler) handleUnpin(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return
+	}
+	pn, ok := h.pinner(w, r)
+	if !ok {
+		return
+	}
+	err := pn.Unpin(r.Context(), id)
+	if errors.Is(err, memstore.ErrPinNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "unpinned"})
+}
@@ -0,0 +1,104 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestPins(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	id, err := store.Insert(ctx, memstore.Fact{Content: "never commit generated protobufs", Subject: "memstore", Category: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := doJSON(t, h, "POST", "/v1/pins", map[string]any{"fact_id": id, "subject": "memstore"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pin: expected 200, got %d", resp.StatusCode)
+	}
+	var p memstore.Pin
+	decodeJSON(t, resp, &p)
+	if p.FactID != id || p.Position != 1 {
+		t.Errorf("pin = %+v, want fact %d at position 1", p, id)
+	}
+	resp = doJSON(t, h, "POST", "/v1/pins", map[string]any{"fact_id": id})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("pin without a scope: expected 400, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "POST", "/v1/pins", map[string]any{"fact_id": id + 100, "subject": "memstore"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("pin of a missing fact: expected 400, got %d", resp.StatusCode)
+	}
+
+	var list struct {
+		Pins []memstore.PinnedFact `json:"pins"`
+	}
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/pins", nil), &list)
+	if len(list.Pins) != 1 || list.Pins[0].Fact.Content != "never commit generated protobufs" {
+		t.Fatalf("pins = %+v, want the one pin with its fact", list.Pins)
+	}
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/pins?cwd=/src/herald", nil), &list)
+	if len(list.Pins) != 0 {
+		t.Errorf("pins for another project = %+v, want none", list.Pins)
+	}
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/pins?cwd=/src/memstore", nil), &list)
+	if len(list.Pins) != 1 {
+		t.Errorf("pins for the project = %+v, want one", list.Pins)
+	}
+
+	resp = doJSON(t, h, "DELETE", "/v1/pins/"+itoa(id), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unpin: expected 200, got %d", resp.StatusCode)
+	}
+	resp = doJSON(t, h, "DELETE", "/v1/pins/"+itoa(id), nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second unpin: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestRecall_Pinned(t *testing.T) {
+	h, store, _ := newTestHandlerWithRecall(t)
+	ctx := context.Background()
+
+	rule, err := store.Insert(ctx, memstore.Fact{Content: "never commit generated protobufs", Subject: "memstore", Category: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Pin(ctx, memstore.Pin{FactID: rule, Subject: "memstore"}); err != nil {
+		t.Fatal(err)
+	}
+
+	recall := func() (resp struct {
+		Context string `json:"context"`
+		Pinned  []struct {
+			ID int64 `json:"id"`
+		} `json:"pinned"`
+	}) {
+		decodeJSON(t, doJSON(t, h, "POST", "/v1/recall", map[string]any{
+			"prompt":     "ok",
+			"session_id": "s1",
+			"cwd":        "/src/memstore",
+		}), &resp)
+		return resp
+	}
+
+	// The prompt has no keywords; the pin is injected regardless.
+	got := recall()
+	if len(got.Pinned) != 1 || got.Pinned[0].ID != rule {
+		t.Fatalf("pinned = %+v, want fact %d", got.Pinned, rule)
+	}
+	if !strings.Contains(got.Context, "pinned") || !strings.Contains(got.Context, "never commit generated protobufs") {
+		t.Errorf("context = %q, want the pinned fact marked", got.Context)
+	}
+
+	// The session has seen it; it is not injected again.
+	if got := recall(); len(got.Pinned) != 0 {
+		t.Errorf("second recall pinned = %+v, want none", got.Pinned)
+	}
+}
@@ -0,0 +1,67 @@
+package httpapi_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpapi"
+)
+
+func TestInsert_ProvenanceFromIdentity(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	embedder := &mockEmbedder{dim: 4}
+	store, err := memstore.NewSQLiteStore(db, embedder, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := httpapi.New(store, embedder, "", httpapi.WithTokenVerifier(stubVerifier{
+		wantToken: "mst_alice",
+		id:        httpapi.Identity{Name: "alice-laptop", Scopes: []string{"read", "write"}, Source: "bearer"},
+	}))
+	do := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer mst_alice")
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	// The origin is the token's identity; an origin in the body is ignored.
+	resp := do("POST", "/v1/facts", `{"content":"c","subject":"s","category":"note",
+		"source":"extraction","session_id":"sess-9","turn_first":"a","turn_last":"b","origin":"forged"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("insert: status %d", resp.StatusCode)
+	}
+	do("POST", "/v1/facts", `{"content":"other","subject":"s","category":"note"}`)
+
+	resp = do("GET", "/v1/facts?session_id=sess-9", "")
+	var facts []memstore.Fact
+	decodeJSON(t, resp, &facts)
+	want := memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "sess-9", TurnFirst: "a", TurnLast: "b", Origin: "alice-laptop"}
+	if len(facts) != 1 || facts[0].Provenance != want {
+		t.Fatalf("facts for sess-9 = %+v, want one with provenance %+v", facts, want)
+	}
+
+	resp = do("GET", "/v1/facts?source=manual&origin=alice-laptop", "")
+	decodeJSON(t, resp, &facts)
+	if len(facts) != 1 || facts[0].Content != "other" {
+		t.Errorf("manual facts = %+v, want the second insert", facts)
+	}
+
+	if resp := do("POST", "/v1/facts", `{"content":"c","subject":"s","source":"gossip"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown source: status %d, want 400", resp.StatusCode)
+	}
+	if resp := do("GET", "/v1/facts?document_id=x", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad document_id: status %d, want 400", resp.StatusCode)
+	}
+}
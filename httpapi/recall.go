@@ -35,15 +35,18 @@ type recallRequest struct {
 	Prompt    string `json:"prompt"`
 	SessionID string `json:"session_id"`
 	CWD       string `json:"cwd"`
-	Limit     int    `json:"limit"`  // max facts (default 5)
-	Budget    int    `json:"budget"` // max chars for formatted output (default 2000)
+	Limit     int    `json:"limit"`      // max facts (default 5)
+	Budget    int    `json:"budget"`     // max chars for formatted output (default 2000)
+	PinBudget int    `json:"pin_budget"` // max chars for pinned facts, on top of budget (default 1500)
 }
 
 // recallResponse is the output of POST /v1/recall.
 type recallResponse struct {
-	Context  string       `json:"context"`  // pre-formatted text block for hook injection
-	Facts    []recallFact `json:"facts"`    // structured results
-	Keywords []string     `json:"keywords"` // IDF-extracted keywords used for search
+	Context    string       `json:"context"`              // pre-formatted text block for hook injection
+	Pinned     []recallFact `json:"pinned,omitempty"`     // facts pinned for the project, in pin order
+	Facts      []recallFact `json:"facts"`                // structured results
+	Keywords   []string     `json:"keywords"`             // IDF-extracted keywords used for search
+	Experiment string       `json:"experiment,omitempty"` // online experiment the session is enrolled in
 }
 
 type recallFact struct {
@@ -52,31 +55,57 @@ type recallFact struct {
 	Category string  `json:"category"`
 	Content  string  `json:"content"`
 	Score    float64 `json:"score"`
+	Arm      string  `json:"arm,omitempty"` // experiment arm that contributed the fact
+
+	origRank int    // position the ranker gave the fact
+	explore  string // exploration kind that placed it; "" for score order
 }
 
 // recallDefaults
 const (
 	defaultRecallLimit  = 5
 	defaultRecallBudget = 2000
+	defaultPinBudget    = 1500
+	maxPinnedFactChars  = 1000 // pinned rules are kept whole where possible
 	maxRecallLimit      = 20
 	maxFactChars        = 300
 	maxKeywords         = 5
 	minIDFFloor         = 0.5  // absolute minimum IDF threshold
 	minIDFFraction      = 0.15 // fraction of log(N) used as IDF threshold
+
+	// Scoring defaults; see RecallTuning.
 	minScoreRatio       = 0.3  // facts scoring below 30% of the top fact are dropped
 	minAbsoluteScore    = 0.35 // absolute score floor — facts below this are dropped regardless of top
 	vecBoostWeight      = 0.5  // weight for vector score when blended with FTS match
 	vecOnlyWeight       = 1.5  // weight for vector-only matches (no FTS hit)
 	projectSurfaceBoost = 4.0  // multiplier when fact is surface=project and project_path matches CWD
 
-	// Confidence-weighted feedback tuning.
-	// At count=1, exponent is avg*baseWeight. At count≥cap, exponent is avg*1.0.
-	// Multiplier = maxFactor^exponent — symmetric: +1 boosts, -1 demotes inversely.
-	feedbackBaseWeight    = 0.4 // floor confidence weight for a single rating
-	feedbackConfidenceCap = 5.0 // rating count at which full confidence applies
-	feedbackMaxFactor     = 2.0 // multiplier when avg=±1 at full confidence (×2.0 / ×0.5)
 )
 
+// RecallTuning holds the /v1/recall scoring knobs that are worth tuning
+// against a golden set (memstore eval) rather than by gut feel. The daemon
+// always runs DefaultRecallTuning; WithRecallTuning exists so the offline
+// evaluation harness can score alternative configurations through the real
+// pipeline instead of a copy of it.
+type RecallTuning struct {
+	MinScoreRatio       float64 // drop facts scoring below this fraction of the top fact
+	MinAbsoluteScore    float64 // drop facts scoring below this, regardless of the top fact
+	VecBoostWeight      float64 // weight of the vector score when a fact also matched FTS
+	VecOnlyWeight       float64 // weight of the vector score for vector-only matches
+	ProjectSurfaceBoost float64 // multiplier for surface=project facts whose project_path contains the CWD
+}
+
+// DefaultRecallTuning returns the built-in recall scoring knobs.
+func DefaultRecallTuning() RecallTuning {
+	return RecallTuning{
+		MinScoreRatio:       minScoreRatio,
+		MinAbsoluteScore:    minAbsoluteScore,
+		VecBoostWeight:      vecBoostWeight,
+		VecOnlyWeight:       vecOnlyWeight,
+		ProjectSurfaceBoost: projectSurfaceBoost,
+	}
+}
+
 // stopWords are filtered from keyword extraction. Kept small — IDF scoring
 // handles most frequency-based filtering, this just removes the obvious noise.
 var stopWords = map[string]bool{
@@ -130,6 +159,9 @@ func (h *Handler) handleRecall(w http.ResponseWriter, r *http.Request) {
 	if req.Budget <= 0 {
 		req.Budget = defaultRecallBudget
 	}
+	if req.PinBudget <= 0 {
+		req.PinBudget = defaultPinBudget
+	}
 
 	resp, err := h.recall(r.Context(), req)
 	if err != nil {
@@ -140,22 +172,29 @@ func (h *Handler) handleRecall(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallResponse, error) {
+	// Derive project context from CWD, resolved through the user's subject
+	// aliases so a renamed checkout or a fork finds its project's memory.
+	aliases := memstore.LoadAliases(ctx, storeFromCtx(ctx, h.store))
+	project := ""
+	if req.CWD != "" {
+		project = aliases.Canonical(memstore.ProjectNameFromCWD(req.CWD))
+	}
+
+	// Facts pinned for the project come first whatever the prompt says, so
+	// they are loaded before the prompt can turn out to have no keywords.
+	pinned := h.recallPins(ctx, req, project, aliases)
+	pinnedOnly := &recallResponse{Context: formatRecallContext(pinned, nil), Pinned: pinned}
+
 	// Extract candidate words from prompt.
 	words := extractCandidateWords(req.Prompt)
 	if len(words) == 0 {
-		return &recallResponse{}, nil
+		return pinnedOnly, nil
 	}
 
 	// Score by IDF if the store supports it.
 	keywords := scoreAndSelectKeywords(ctx, storeFromCtx(ctx, h.store), words)
 	if len(keywords) == 0 {
-		return &recallResponse{}, nil
-	}
-
-	// Derive project context from CWD.
-	project := ""
-	if req.CWD != "" {
-		project = filepath.Base(req.CWD)
+		return pinnedOnly, nil
 	}
 
 	// Get recent files for context boosting.
@@ -164,6 +203,159 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 		recentFiles = h.sessionCtx.RecentFiles(req.SessionID)
 	}
 
+	// Rank under the daemon's configuration or, for a session enrolled in an
+	// online experiment, its arm's -- both arms, interleaved, when the
+	// experiment interleaves.
+	rankers, experiment := h.recallRankers(ctx, req.SessionID)
+	var candidates, below []scoredFact
+	if len(rankers) == 2 {
+		a, _ := h.rankRecall(ctx, req, keywords, project, aliases, recentFiles, rankers[0])
+		b, _ := h.rankRecall(ctx, req, keywords, project, aliases, recentFiles, rankers[1])
+		candidates = interleaveCandidates(a, b, memstore.ExperimentSeed(req.SessionID, req.Prompt))
+	} else {
+		candidates, below = h.rankRecall(ctx, req, keywords, project, aliases, recentFiles, rankers[0])
+	}
+	// Pinned facts already lead the response; the session filter catches
+	// them too, but only where the request names a session.
+	candidates, below = dropPinned(candidates, pinned), dropPinned(below, pinned)
+	for i := range candidates {
+		candidates[i].origRank = i
+	}
+	for i := range below {
+		below[i].origRank = len(candidates) + i
+	}
+	facts := selectRecallFacts(candidates, req.Limit, req.Budget)
+
+	// Occasionally show a ranking out of score order, so feedback can be
+	// debiased for position. Only where the exploration can be logged, and
+	// never inside an experiment, whose arms must be compared as ranked.
+	sess := sessionFromCtx(ctx, h.sessionStore)
+	explorer, _ := sess.(memstore.ExplorationRecorder)
+	if h.exploration.Enabled() && explorer != nil && experiment == "" && req.SessionID != "" && len(facts) > 0 {
+		explored := h.exploration.explore(candidates, below, len(facts), req.Limit, exploreRand(req.SessionID, req.Prompt))
+		facts = selectRecallFacts(explored, req.Limit, req.Budget)
+	}
+
+	// Record returned facts so they won't be injected again this session.
+	if h.sessionCtx != nil && req.SessionID != "" && len(facts) > 0 {
+		seenIDs := make([]int64, len(facts))
+		for i, f := range facts {
+			seenIDs[i] = f.ID
+		}
+		h.sessionCtx.MarkSeen(req.SessionID, seenIDs)
+	}
+
+	// Record fact injections server-side for feedback tracking, crediting
+	// each to the experiment arm that served it and logging where exploration
+	// moved it from.
+	if h.sessionStore != nil && req.SessionID != "" && len(facts) > 0 {
+		armRec, _ := sess.(memstore.ArmInjectionRecorder)
+		for rank, f := range facts {
+			refID := strconv.FormatInt(f.ID, 10)
+			if f.explore != "" && explorer != nil {
+				explorer.RecordExploration(ctx, req.SessionID, refID, memstore.RefTypeFact, rank, f.origRank, f.explore)
+			} else if experiment != "" && armRec != nil {
+				armRec.RecordArmInjection(ctx, req.SessionID, refID, memstore.RefTypeFact, rank,
+					memstore.ArmAssignment{Experiment: experiment, Arm: f.Arm})
+			} else {
+				sess.RecordInjection(ctx, req.SessionID, refID, memstore.RefTypeFact, rank)
+			}
+		}
+	}
+
+	// Format the context block.
+	contextBlock := formatRecallContext(pinned, facts)
+
+	return &recallResponse{
+		Context:    contextBlock,
+		Pinned:     pinned,
+		Facts:      facts,
+		Keywords:   keywords,
+		Experiment: experiment,
+	}, nil
+}
+
+// recallPins returns the facts pinned for the request's project, in pin
+// order and within req.PinBudget, leaving out any the session has already
+// been shown; those returned are marked seen, so a session gets each pin
+// once. Pinned facts are not recorded as injections: where they land is not
+// a ranking decision, and their feedback would skew the ranker's.
+func (h *Handler) recallPins(ctx context.Context, req recallRequest, project string, aliases memstore.AliasMap) []recallFact {
+	pins := memstore.PinsFor(ctx, storeFromCtx(ctx, h.store), project, req.CWD, aliases)
+	if len(pins) == 0 {
+		return nil
+	}
+	track := h.sessionCtx != nil && req.SessionID != ""
+	unseen := make(map[int64]bool, len(pins))
+	ids := make([]int64, len(pins))
+	for i, p := range pins {
+		ids[i] = p.Fact.ID
+	}
+	if track {
+		ids = h.sessionCtx.FilterSeen(req.SessionID, ids)
+	}
+	for _, id := range ids {
+		unseen[id] = true
+	}
+
+	var (
+		out   []recallFact
+		shown []int64
+		total int
+	)
+	for _, p := range pins {
+		if !unseen[p.Fact.ID] {
+			continue
+		}
+		content := p.Fact.Content
+		if len(content) > maxPinnedFactChars {
+			content = content[:maxPinnedFactChars] + "..."
+		}
+		block := formatFactBlock(p.Fact, content)
+		if total+len(block) > req.PinBudget {
+			break
+		}
+		out = append(out, recallFact{
+			ID:       p.Fact.ID,
+			Subject:  p.Fact.Subject,
+			Category: p.Fact.Category,
+			Content:  content,
+		})
+		shown = append(shown, p.Fact.ID)
+		total += len(block)
+	}
+	if track && len(shown) > 0 {
+		h.sessionCtx.MarkSeen(req.SessionID, shown)
+	}
+	return out
+}
+
+// dropPinned removes the pinned facts from ranked candidates.
+func dropPinned(cs []scoredFact, pinned []recallFact) []scoredFact {
+	if len(pinned) == 0 {
+		return cs
+	}
+	ids := make(map[int64]bool, len(pinned))
+	for _, p := range pinned {
+		ids[p.ID] = true
+	}
+	out := cs[:0]
+	for _, c := range cs {
+		if !ids[c.fact.ID] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// rankRecall scores and orders the recall candidates for one ranker
+// configuration: keyword FTS plus the vector pass, the context boosts and
+// skip rules, optional rerank, session de-duplication, and the relative and
+// absolute score floors. Limit and budget are applied later, by
+// selectRecallFacts, so interleaving sees each arm's full ranking. The
+// candidates the floors dropped are returned separately, best first, as the
+// pool for epsilon exploration.
+func (h *Handler) rankRecall(ctx context.Context, req recallRequest, keywords []string, project string, aliases memstore.AliasMap, recentFiles []string, rk recallRanker) (ranked, below []scoredFact) {
 	// Search: one FTS query per keyword, merge results.
 	seen := make(map[int64]*scoredFact)
 	for _, kw := range keywords {
@@ -203,12 +395,12 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 			for _, r := range vecResults {
 				if existing, ok := seen[r.Fact.ID]; ok {
 					// Fact found by both FTS and vector — blend in vector score.
-					existing.score += r.VecScore * vecBoostWeight
+					existing.score += r.VecScore * rk.tuning.VecBoostWeight
 				} else {
 					// Semantic-only match — use vector score as base.
 					seen[r.Fact.ID] = &scoredFact{
 						fact:  r.Fact,
-						score: r.VecScore * vecOnlyWeight,
+						score: r.VecScore * rk.tuning.VecOnlyWeight,
 					}
 				}
 			}
@@ -217,7 +409,7 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 
 	// Evaluate CWD-pattern triggers and merge their loaded facts.
 	if req.CWD != "" {
-		cwdFacts := h.evalCWDTriggers(ctx, req.CWD)
+		cwdFacts := h.evalCWDTriggers(ctx, req.CWD, aliases)
 		for _, f := range cwdFacts {
 			if _, ok := seen[f.ID]; !ok {
 				seen[f.ID] = &scoredFact{
@@ -236,7 +428,7 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 		for id := range seen {
 			refIDs = append(refIDs, strconv.FormatInt(id, 10))
 		}
-		if stats, err := scorer.FeedbackScores(ctx, refIDs, memstore.RefTypeFact); err == nil {
+		if stats, err := memstore.FetchFeedback(ctx, scorer, refIDs, memstore.RefTypeFact, h.feedbackPolicy); err == nil {
 			feedbackStats = stats
 		}
 	}
@@ -271,7 +463,7 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 		// In feedback data they were the largest single source of bad injections
 		// (37% of negative ratings in a single observed session). With no project
 		// context, we can't make the judgment, so keep them.
-		if sf.fact.Kind == "summary" && project != "" && !subjectMatchesProject(sf.fact.Subject, project) {
+		if sf.fact.Kind == "summary" && project != "" && !subjectMatchesProject(sf.fact.Subject, project, aliases) {
 			continue
 		}
 
@@ -293,7 +485,7 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 
 		// Boost for project match, demote unrelated facts.
 		if project != "" {
-			if subjectMatchesProject(sf.fact.Subject, project) {
+			if subjectMatchesProject(sf.fact.Subject, project, aliases) {
 				if !sym {
 					sf.score *= 2.5
 				}
@@ -309,7 +501,7 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 		// These are curated repo-level summaries and should dominate when you're
 		// inside that project's tree, regardless of whether the subject matches.
 		if matchesProjectSurface(sf.fact, req.CWD) {
-			sf.score *= projectSurfaceBoost
+			sf.score *= rk.tuning.ProjectSurfaceBoost
 		}
 
 		// Boost for file context match.
@@ -324,14 +516,10 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 			sf.score *= 1.0 + 0.2*float64(sf.keywordHits-1)
 		}
 
-		// Apply confidence-weighted feedback multiplier.
-		// One rating gently nudges (avg=-1 → ×0.68, avg=+1 → ×1.47); at 5+ ratings
-		// the full effect applies (×0.5 / ×2.0). Prevents a single bad rating in
-		// one session from crushing a fact that's legitimately useful elsewhere.
-		if stat, ok := feedbackStats[strconv.FormatInt(sf.fact.ID, 10)]; ok && stat.Count > 0 {
-			conf := math.Min(float64(stat.Count), feedbackConfidenceCap) / feedbackConfidenceCap
-			exponent := stat.Avg * (feedbackBaseWeight + (1.0-feedbackBaseWeight)*conf)
-			sf.score *= math.Pow(feedbackMaxFactor, exponent)
+		// Apply the confidence-weighted feedback multiplier -- the same one
+		// Store.Search applies (memstore.FeedbackPolicy).
+		if stat, ok := feedbackStats[strconv.FormatInt(sf.fact.ID, 10)]; ok {
+			sf.score *= h.feedbackPolicy.Multiplier(stat)
 		}
 
 		candidates = append(candidates, *sf)
@@ -342,14 +530,12 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 	// keyword heuristics under-ranked can rise, and below-threshold facts drop
 	// out. Skipped (first-stage order preserved) when no reranker is wired or
 	// the mode is off.
-	if h.reranker != nil && h.rerankMode.Enabled() && len(candidates) > 0 {
-		candidates = h.rerankCandidates(ctx, req.Prompt, candidates)
+	if h.reranker != nil && rk.rerankMode.Enabled() && len(candidates) > 0 {
+		candidates = h.rerankCandidates(ctx, req.Prompt, candidates, rk)
 	}
 
 	// Sort by score descending.
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].score > candidates[j].score
-	})
+	sortCandidates(candidates)
 
 	// Filter out facts already returned in this session.
 	if h.sessionCtx != nil && req.SessionID != "" {
@@ -370,23 +556,35 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 		candidates = filtered
 	}
 
-	// Compute minimum score threshold relative to the top result.
+	// Drop candidates below the floor relative to the top result, and below
+	// the absolute floor.
 	var minScore float64
 	if len(candidates) > 0 {
-		minScore = candidates[0].score * minScoreRatio
+		minScore = candidates[0].score * rk.tuning.MinScoreRatio
+	}
+	for i, c := range candidates {
+		if c.score < minScore || c.score < rk.tuning.MinAbsoluteScore {
+			// Sorted descending, the rest will also be below threshold.
+			candidates, below = candidates[:i], candidates[i:]
+			break
+		}
 	}
+	for i := range candidates {
+		candidates[i].arm = rk.arm
+	}
+	return candidates, below
+}
 
-	// Enforce relevance threshold, limit, and budget.
+// selectRecallFacts enforces the limit and character budget over ranked
+// candidates and truncates each fact's content for injection.
+func selectRecallFacts(candidates []scoredFact, limit, budget int) []recallFact {
 	var facts []recallFact
 	totalChars := 0
 	for _, c := range candidates {
-		if c.score < minScore || c.score < minAbsoluteScore {
-			break // sorted descending, rest will also be below threshold
-		}
-		if len(facts) >= req.Limit {
+		if len(facts) >= limit {
 			break
 		}
-		if totalChars >= req.Budget {
+		if totalChars >= budget {
 			break
 		}
 
@@ -395,7 +593,7 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 			content = content[:maxFactChars] + "..."
 		}
 
-		remaining := req.Budget - totalChars
+		remaining := budget - totalChars
 		block := formatFactBlock(c.fact, content)
 		if len(block) > remaining {
 			break
@@ -407,48 +605,26 @@ func (h *Handler) recall(ctx context.Context, req recallRequest) (*recallRespons
 			Category: c.fact.Category,
 			Content:  content,
 			Score:    c.score,
+			Arm:      c.arm,
+			origRank: c.origRank,
+			explore:  c.explore,
 		})
 		totalChars += len(block)
 	}
-
-	// Record returned facts so they won't be injected again this session.
-	if h.sessionCtx != nil && req.SessionID != "" && len(facts) > 0 {
-		seenIDs := make([]int64, len(facts))
-		for i, f := range facts {
-			seenIDs[i] = f.ID
-		}
-		h.sessionCtx.MarkSeen(req.SessionID, seenIDs)
-	}
-
-	// Record fact injections server-side for feedback tracking.
-	if h.sessionStore != nil && req.SessionID != "" && len(facts) > 0 {
-		sess := sessionFromCtx(ctx, h.sessionStore)
-		for rank, f := range facts {
-			sess.RecordInjection(ctx, req.SessionID, strconv.FormatInt(f.ID, 10), memstore.RefTypeFact, rank)
-		}
-	}
-
-	// Format the context block.
-	contextBlock := formatRecallContext(facts)
-
-	return &recallResponse{
-		Context:  contextBlock,
-		Facts:    facts,
-		Keywords: keywords,
-	}, nil
+	return facts
 }
 
 // rerankCandidates rescores the top-by-heuristic candidates against the prompt
 // with the cross-encoder and fuses each rerank score with the (normalized)
-// heuristic score per h.rerankMode. When h.rerankThreshold > 0 it then drops
+// heuristic score per rk.rerankMode. When rk.rerankThreshold > 0 it then drops
 // every fact scoring below it. The heuristic boosts and skip-rules are
 // preserved — they shaped the score that fusion now blends.
 //
 // It degrades gracefully: if the backend is unavailable (or any rerank error
 // occurs) it returns the candidates with their heuristic scores intact and
 // applies no threshold, so context injection never fails on a rerank outage.
-func (h *Handler) rerankCandidates(ctx context.Context, prompt string, candidates []scoredFact) []scoredFact {
-	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+func (h *Handler) rerankCandidates(ctx context.Context, prompt string, candidates []scoredFact, rk recallRanker) []scoredFact {
+	sortCandidates(candidates)
 	// Cap the pass at the configured candidate count (RERANK_CANDIDATES), else
 	// the recall default. Each candidate is a CPU cross-encoder forward pass, so
 	// this bounds per-recall latency on every context injection.
@@ -496,13 +672,13 @@ func (h *Handler) rerankCandidates(ctx context.Context, prompt string, candidate
 			hNorm /= maxH
 		}
 		pool[res.Index].rerankScore = res.Score
-		pool[res.Index].score = memstore.FuseScore(h.rerankMode, w, res.Score, hNorm)
+		pool[res.Index].score = memstore.FuseScore(rk.rerankMode, w, res.Score, hNorm)
 	}
 
-	if h.rerankThreshold > 0 {
+	if rk.rerankThreshold > 0 {
 		kept := make([]scoredFact, 0, n)
 		for i := range pool {
-			if pool[i].rerankScore >= h.rerankThreshold {
+			if pool[i].rerankScore >= rk.rerankThreshold {
 				kept = append(kept, pool[i])
 			}
 		}
@@ -516,6 +692,20 @@ type scoredFact struct {
 	score       float64
 	keywordHits int
 	rerankScore float64 // normalized [0,1] cross-encoder relevance; 0 if not reranked
+	arm         string  // experiment arm that ranked it; "" outside an experiment
+	origRank    int     // position in the ranker's order, floors ignored
+	explore     string  // exploration kind that moved it; "" for score order
+}
+
+// sortCandidates orders candidates by score descending. Candidates are
+// collected from a map, so ties break on fact ID to keep recall reproducible.
+func sortCandidates(cs []scoredFact) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].score != cs[j].score {
+			return cs[i].score > cs[j].score
+		}
+		return cs[i].fact.ID < cs[j].fact.ID
+	})
 }
 
 // extractCandidateWords splits the prompt into lowercase words, removes
@@ -610,17 +800,39 @@ func isSymbol(f memstore.Fact) bool {
 }
 
 // subjectMatchesProject checks if a fact's subject matches the CWD-derived project name.
-// Handles org/repo subjects like "infodancer/oidclient" matching project "oidclient".
-func subjectMatchesProject(subject, project string) bool {
-	if strings.EqualFold(subject, project) {
+// Handles org/repo subjects like "infodancer/oidclient" matching project "oidclient",
+// and subjects that are aliases of the project, like a fork filed under its old name.
+func subjectMatchesProject(subject, project string, aliases memstore.AliasMap) bool {
+	if aliases.Same(subject, project) {
 		return true
 	}
 	if i := strings.LastIndex(subject, "/"); i >= 0 {
-		return strings.EqualFold(subject[i+1:], project)
+		return aliases.Same(subject[i+1:], project)
 	}
 	return false
 }
 
+// canonicalCWD rewrites each component of cwd that is a subject alias to
+// its canonical subject, so a cwd_pattern trigger written for a project's
+// directory also fires in a renamed checkout or a fork. It returns "" when
+// nothing was rewritten.
+func canonicalCWD(cwd string, aliases memstore.AliasMap) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(cwd), "/")
+	changed := false
+	for i, p := range parts {
+		if c := aliases.Canonical(p); p != "" && c != p {
+			parts[i], changed = c, true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
+
 // isPatternFact reports whether the fact is a learn-generated code/doc pattern
 // with a non-project surface (file, symbol, package, section, etc.). These
 // dominate FTS and semantic search by volume but are noise in general recall.
@@ -693,8 +905,9 @@ func matchesFileContext(f memstore.Fact, recentFiles []string) bool {
 }
 
 // evalCWDTriggers finds kind=trigger facts with signal_type=cwd_pattern,
-// matches them against the CWD, and loads the referenced context facts.
-func (h *Handler) evalCWDTriggers(ctx context.Context, cwd string) []memstore.Fact {
+// matches them against the CWD, or the CWD with aliased directories under
+// their canonical names, and loads the referenced context facts.
+func (h *Handler) evalCWDTriggers(ctx context.Context, cwd string, aliases memstore.AliasMap) []memstore.Fact {
 	store := storeFromCtx(ctx, h.store)
 	triggers, err := store.List(ctx, memstore.QueryOpts{
 		Kind:       "trigger",
@@ -709,6 +922,7 @@ func (h *Handler) evalCWDTriggers(ctx context.Context, cwd string) []memstore.Fa
 
 	seen := make(map[int64]bool)
 	var result []memstore.Fact
+	canonical := canonicalCWD(cwd, aliases)
 
 	for _, t := range triggers {
 		var meta map[string]any
@@ -719,7 +933,7 @@ func (h *Handler) evalCWDTriggers(ctx context.Context, cwd string) []memstore.Fa
 			continue
 		}
 		signal, _ := meta["signal"].(string)
-		if signal == "" || !memstore.MatchFilePattern(signal, cwd) {
+		if signal == "" || !(memstore.MatchFilePattern(signal, cwd) || canonical != "" && memstore.MatchFilePattern(signal, canonical)) {
 			continue
 		}
 
@@ -781,13 +995,18 @@ func formatFactBlock(f memstore.Fact, content string) string {
 		f.ID, f.Subject, f.Category, f.CreatedAt.Format("2006-01-02"), content)
 }
 
-func formatRecallContext(facts []recallFact) string {
-	if len(facts) == 0 {
-		return ""
-	}
+// formatRecallContext renders the pinned facts, marked as such, followed
+// by the recalled ones.
+func formatRecallContext(pinned, facts []recallFact) string {
 	var b strings.Builder
-	for i, f := range facts {
-		if i > 0 {
+	for _, f := range pinned {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "[id=%d, pinned] %s | %s\n  %s\n", f.ID, f.Subject, f.Category, f.Content)
+	}
+	for _, f := range facts {
+		if b.Len() > 0 {
 			b.WriteByte('\n')
 		}
 		fmt.Fprintf(&b, "[id=%d] %s | %s\n  %s\n", f.ID, f.Subject, f.Category, f.Content)
@@ -0,0 +1,52 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// handleReview implements GET /v1/review: a prioritized batch of active facts
+// due for re-confirmation. Acting on an entry goes through the ordinary
+// confirm, revise, supersede and delete routes.
+//
+// Query parameters: limit (batch size, default memstore.DefaultReviewLimit),
+// min_age (time since last confirmation, e.g. 30d or 720h; default
+// memstore.DefaultReviewMinAge), subject.
+func (h *Handler) handleReview(w http.ResponseWriter, r *http.Request) {
+	rv, ok := storeFromCtx(r.Context(), h.store).(memstore.Reviewer)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend has no review queue")
+		return
+	}
+
+	q := r.URL.Query()
+	opts := memstore.ReviewOpts{Subject: q.Get("subject")}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+v)
+			return
+		}
+		opts.Limit = n
+	}
+	if v := q.Get("min_age"); v != "" {
+		d, err := memstore.ParseTTL(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid min_age: "+v)
+			return
+		}
+		opts.MinAge = d
+	}
+
+	items, err := rv.ReviewQueue(r.Context(), opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []memstore.ReviewItem{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
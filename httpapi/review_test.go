@@ -0,0 +1,42 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestReview(t *testing.T) {
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	stale, _ := store.Insert(ctx, memstore.Fact{Content: "builds use make", Subject: "ci", Category: "project", Kind: "convention", CreatedAt: old})
+	store.Insert(ctx, memstore.Fact{Content: "fresh fact", Subject: "ci", Category: "project"})
+
+	resp := doJSON(t, h, "GET", "/v1/review?min_age=soon", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad min_age: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/review?min_age=30d&limit=5", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("review: expected 200, got %d", resp.StatusCode)
+	}
+	var queue struct {
+		Items []memstore.ReviewItem `json:"items"`
+	}
+	decodeJSON(t, resp, &queue)
+	if len(queue.Items) != 1 || queue.Items[0].Fact.ID != stale || queue.Items[0].Priority <= 0 {
+		t.Fatalf("items = %+v, want the stale convention", queue.Items)
+	}
+
+	resp = doJSON(t, h, "GET", "/v1/review?min_age=90d", nil)
+	decodeJSON(t, resp, &queue)
+	if len(queue.Items) != 0 {
+		t.Errorf("min_age 90d = %+v, want none", queue.Items)
+	}
+}
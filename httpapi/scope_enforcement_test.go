@@ -66,6 +66,30 @@ func TestScopeEnforcement(t *testing.T) {
 		{"legacy unscoped token can write", "tok-legacy", "POST", "/v1/facts", false},
 		{"ingest-only token cannot read facts", "tok-ingest", "GET", "/v1/facts", true},
 		{"ingest-only token cannot write facts", "tok-ingest", "POST", "/v1/facts", true},
+		{"read token cannot export training data", "tok-read", "GET", "/v1/admin/training", true},
+		{"write token cannot export training data", "tok-write", "GET", "/v1/admin/training", true},
+		{"admin can export training data", "tok-admin", "GET", "/v1/admin/training", false},
+		{"write token cannot scan for duplicates", "tok-write", "GET", "/v1/admin/duplicates", true},
+		{"admin can scan for duplicates", "tok-admin", "GET", "/v1/admin/duplicates", false},
+		{"write token cannot bulk-update", "tok-write", "POST", "/v1/admin/bulk-update", true},
+		{"write token cannot read the audit log", "tok-write", "GET", "/v1/admin/audit", true},
+		{"admin can read the audit log", "tok-admin", "GET", "/v1/admin/audit", false},
+		{"write token cannot list namespaces", "tok-write", "GET", "/v1/admin/namespaces", true},
+		{"write token cannot rename a namespace", "tok-write", "POST", "/v1/admin/namespaces/test/rename", true},
+		{"write token cannot delete a namespace", "tok-write", "DELETE", "/v1/admin/namespaces/test", true},
+		{"admin can list namespaces", "tok-admin", "GET", "/v1/admin/namespaces", false},
+		{"read token can list aliases", "tok-read", "GET", "/v1/aliases", false},
+		{"read token cannot add an alias", "tok-read", "POST", "/v1/aliases", true},
+		{"read token cannot rewrite aliased facts", "tok-read", "POST", "/v1/aliases/rewrite", true},
+		{"write token can remove an alias", "tok-write", "DELETE", "/v1/aliases/x", false},
+		{"read token can list pins", "tok-read", "GET", "/v1/pins", false},
+		{"read token cannot pin a fact", "tok-read", "POST", "/v1/pins", true},
+		{"read token cannot unpin a fact", "tok-read", "DELETE", "/v1/pins/1", true},
+		{"read token can count tags", "tok-read", "GET", "/v1/tags", false},
+		{"read token cannot tag a fact", "tok-read", "POST", "/v1/facts/1/tags", true},
+		{"read token cannot untag a fact", "tok-read", "DELETE", "/v1/facts/1/tags?tags=x", true},
+		{"read token cannot rename a tag", "tok-read", "POST", "/v1/tags/x/rename", true},
+		{"read token cannot delete a tag", "tok-read", "DELETE", "/v1/tags/x", true},
 	}
 
 	for _, tc := range tests {
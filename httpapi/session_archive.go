@@ -58,6 +58,7 @@ func (h *Handler) handleSessionTranscript(w http.ResponseWriter, r *http.Request
 				Persona:   input.Persona,
 				Turns:     turns,
 				UserID:    id.UserID,
+				Origin:    id.Name,
 			})
 		}
 	}
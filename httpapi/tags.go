@@ -0,0 +1,140 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// --- Tags ---
+
+// splitTags parses a comma-separated tag list from a query parameter. Tags
+// cannot contain commas, so the list is unambiguous.
+func splitTags(v string) []string {
+	if v == "" {
+		return nil
+	}
+	var tags []string
+	for part := range strings.SplitSeq(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return tags
+}
+
+// tagFilterFromQuery reads a tag filter from the tags, tags_all and
+// tags_none query parameters.
+func tagFilterFromQuery(r *http.Request) memstore.TagFilter {
+	q := r.URL.Query()
+	return memstore.TagFilter{
+		Any:  splitTags(q.Get("tags")),
+		All:  splitTags(q.Get("tags_all")),
+		None: splitTags(q.Get("tags_none")),
+	}
+}
+
+func (h *Handler) handleTagCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := storeFromCtx(r.Context(), h.store).TagCounts(r.Context(), r.URL.Query().Get("subject"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if counts == nil {
+		counts = []memstore.TagCount{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tags": counts})
+}
+
+// taggedFact resolves the {id} of a fact tag route, writing a 404 when the
+// fact does not exist in the caller's store.
+func (h *Handler) taggedFact(w http.ResponseWriter, r *http.Request) (memstore.Store, int64, bool) {
+	id, ok := pathInt64(r, w, "id")
+	if !ok {
+		return nil, 0, false
+	}
+	store := storeFromCtx(r.Context(), h.store)
+	f, err := store.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, 0, false
+	}
+	if f == nil {
+		writeError(w, http.StatusNotFound, "fact not found")
+		return nil, 0, false
+	}
+	return store, id, true
+}
+
+func (h *Handler) handleTagFact(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Tags []string `json:"tags"`
+	}
+	if !readJSON(r, w, &input) {
+		return
+	}
+	tags, err := memstore.NormalizeTags(input.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(tags) == 0 {
+		writeError(w, http.StatusBadRequest, "tags is required")
+		return
+	}
+	store, id, ok := h.taggedFact(w, r)
+	if !ok {
+		return
+	}
+	if err := store.TagFact(r.Context(), id, tags...); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "tags": tags})
+}
+
+func (h *Handler) handleUntagFact(w http.ResponseWriter, r *http.Request) {
+	tags := splitTags(r.URL.Query().Get("tags"))
+	if len(tags) == 0 {
+		writeError(w, http.StatusBadRequest, "tags query parameter is required")
+		return
+	}
+	store, id, ok := h.taggedFact(w, r)
+	if !ok {
+		return
+	}
+	if err := store.UntagFact(r.Context(), id, tags...); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (h *Handler) handleRenameTag(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		To string `json:"to"`
+	}
+	if !readJSON(r, w, &input) {
+		return
+	}
+	if _, err := memstore.NormalizeTags([]string{input.To}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n, err := storeFromCtx(r.Context(), h.store).RenameTag(r.Context(), r.PathValue("tag"), input.To)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"facts": n})
+}
+
+func (h *Handler) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	n, err := storeFromCtx(r.Context(), h.store).DeleteTag(r.Context(), r.PathValue("tag"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"facts": n})
+}
@@ -0,0 +1,89 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestTags(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	resp := doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "retry budget is three attempts", "subject": "memstore", "tags": []string{"Networking", "todo"},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("insert: expected 201, got %d", resp.StatusCode)
+	}
+	decodeJSON(t, resp, &created)
+	id := created.ID
+
+	resp = doJSON(t, h, "POST", "/v1/facts", map[string]any{
+		"content": "x", "subject": "memstore", "tags": []string{"two words"},
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("insert with an invalid tag: expected 400, got %d", resp.StatusCode)
+	}
+
+	var f memstore.Fact
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/facts/"+itoa(id), nil), &f)
+	if len(f.Tags) != 2 || f.Tags[0] != "networking" || f.Tags[1] != "todo" {
+		t.Errorf("tags = %v, want [networking todo]", f.Tags)
+	}
+
+	if resp := doJSON(t, h, "POST", "/v1/facts/"+itoa(id)+"/tags", map[string]any{"tags": []string{"perf"}}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("tag: expected 200, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, h, "POST", "/v1/facts/"+itoa(id+100)+"/tags", map[string]any{"tags": []string{"perf"}}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("tag of a missing fact: expected 404, got %d", resp.StatusCode)
+	}
+	if resp := doJSON(t, h, "DELETE", "/v1/facts/"+itoa(id)+"/tags?tags=todo", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("untag: expected 200, got %d", resp.StatusCode)
+	}
+
+	var facts []memstore.Fact
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/facts?tags_all=perf,networking", nil), &facts)
+	if len(facts) != 1 {
+		t.Errorf("list tags_all = %d facts, want 1", len(facts))
+	}
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/facts?tags=todo", nil), &facts)
+	if len(facts) != 0 {
+		t.Errorf("list by a removed tag = %d facts, want 0", len(facts))
+	}
+
+	var results []memstore.SearchResult
+	decodeJSON(t, doJSON(t, h, "POST", "/v1/search/fts", map[string]any{
+		"query": "retry", "tags": map[string]any{"none": []string{"perf"}},
+	}), &results)
+	if len(results) != 0 {
+		t.Errorf("search excluding perf = %d results, want 0", len(results))
+	}
+
+	var renamed struct {
+		Facts int64 `json:"facts"`
+	}
+	decodeJSON(t, doJSON(t, h, "POST", "/v1/tags/perf/rename", map[string]any{"to": "performance"}), &renamed)
+	if renamed.Facts != 1 {
+		t.Errorf("rename changed %d facts, want 1", renamed.Facts)
+	}
+
+	var counts struct {
+		Tags []memstore.TagCount `json:"tags"`
+	}
+	decodeJSON(t, doJSON(t, h, "GET", "/v1/tags?subject=memstore", nil), &counts)
+	if len(counts.Tags) != 2 || counts.Tags[0].Count != 1 {
+		t.Errorf("tag counts = %+v, want networking and performance once each", counts.Tags)
+	}
+
+	var deleted struct {
+		Facts int64 `json:"facts"`
+	}
+	decodeJSON(t, doJSON(t, h, "DELETE", "/v1/tags/networking", nil), &deleted)
+	if deleted.Facts != 1 {
+		t.Errorf("delete removed the tag from %d facts, want 1", deleted.Facts)
+	}
+}
@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// handleTrainingExport implements GET /v1/admin/training: the retrieval logs
+// rendered as a JSONL training dataset (docs/training-data-design.md). It is
+// admin-scoped because the dataset carries the full text of every candidate
+// fact alongside the queries that retrieved them.
+//
+// Query parameters: format (pointwise|pairwise|listwise), ranker_version,
+// since / until (RFC 3339 or YYYY-MM-DD; until is exclusive), limit (max
+// events), and for pairwise min_margin, max_pairs_per_query and
+// max_pairs_per_doc. The export reads through the caller's scoped session
+// store, so a token sees only its own user's logs.
+func (h *Handler) handleTrainingExport(w http.ResponseWriter, r *http.Request) {
+	if h.sessionStore == nil {
+		writeError(w, http.StatusServiceUnavailable, "session store not configured")
+		return
+	}
+	src, ok := sessionFromCtx(r.Context(), h.sessionStore).(memstore.TrainingSource)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "this backend cannot export training data (Postgres required)")
+		return
+	}
+
+	q := r.URL.Query()
+	format, err := memstore.ParseTrainingFormat(q.Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := memstore.TrainingFilter{RankerVersion: q.Get("ranker_version")}
+	if filter.Since, err = memstore.ParseTrainingTime(q.Get("since")); err != nil {
+		writeError(w, http.StatusBadRequest, "since: "+err.Error())
+		return
+	}
+	if filter.Until, err = memstore.ParseTrainingTime(q.Get("until")); err != nil {
+		writeError(w, http.StatusBadRequest, "until: "+err.Error())
+		return
+	}
+	opts := memstore.TrainingExportOpts{Format: format}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &filter.Limit},
+		{"max_pairs_per_query", &opts.MaxPairsPerQuery},
+		{"max_pairs_per_doc", &opts.MaxPairsPerDoc},
+	} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid "+p.name+": "+v)
+				return
+			}
+			*p.dst = n
+		}
+	}
+	if v := q.Get("min_margin"); v != "" {
+		m, err := strconv.ParseFloat(v, 64)
+		if err != nil || m < 0 {
+			writeError(w, http.StatusBadRequest, "invalid min_margin: "+v)
+			return
+		}
+		opts.MinMargin = m
+	}
+
+	events, err := src.TrainingEvents(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	// Headers are gone once the first line is written, so a mid-stream
+	// failure (client hung up) can only be dropped.
+	memstore.WriteTrainingData(w, events, opts)
+}
@@ -0,0 +1,98 @@
+package httpapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpapi"
+)
+
+// trainingSessionStore adds TrainingSource to the recall mock, recording the
+// filter it was asked for.
+type trainingSessionStore struct {
+	mockSessionStore
+	events []memstore.TrainingEvent
+	filter memstore.TrainingFilter
+}
+
+func (m *trainingSessionStore) TrainingEvents(_ context.Context, f memstore.TrainingFilter) ([]memstore.TrainingEvent, error) {
+	m.filter = f
+	return m.events, nil
+}
+
+func trainingGet(t *testing.T, h http.Handler, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/v1/admin/training"+query, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrainingExport_StreamsJSONL(t *testing.T) {
+	ss := &trainingSessionStore{events: []memstore.TrainingEvent{{
+		HintID: 1, Query: "flaky test", RankerVersion: "hint-v1",
+		Candidates: []memstore.TrainingCandidate{
+			{RefID: "10", Text: "ten", Retrieved: 0, Rank: -1, Selected: true},
+			{RefID: "11", Text: "eleven", Retrieved: 1, Rank: -1},
+		},
+	}}}
+	h := newTestHandlerWith(t, httpapi.WithSessionStore(ss))
+
+	w := trainingGet(t, h, "?format=pairwise&ranker_version=hint-v1&since=2026-01-01&until=2026-02-01T00:00:00Z&limit=50&min_margin=0.25")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	want := memstore.TrainingFilter{
+		RankerVersion: "hint-v1",
+		Since:         time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:         time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Limit:         50,
+	}
+	if !ss.filter.Since.Equal(want.Since) || !ss.filter.Until.Equal(want.Until) ||
+		ss.filter.RankerVersion != want.RankerVersion || ss.filter.Limit != want.Limit {
+		t.Errorf("filter = %+v, want %+v", ss.filter, want)
+	}
+
+	sc := bufio.NewScanner(w.Body)
+	var pairs []memstore.PairwiseExample
+	for sc.Scan() {
+		var p memstore.PairwiseExample
+		if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+			t.Fatalf("bad line %q: %v", sc.Text(), err)
+		}
+		pairs = append(pairs, p)
+	}
+	if len(pairs) != 1 || pairs[0].Chosen.RefID != "10" || pairs[0].Rejected.Document != "eleven" {
+		t.Errorf("pairs = %+v, want one 10>11 pair", pairs)
+	}
+}
+
+func TestTrainingExport_BadParams(t *testing.T) {
+	h := newTestHandlerWith(t, httpapi.WithSessionStore(&trainingSessionStore{}))
+	for _, q := range []string{
+		"?format=triplet",
+		"?since=yesterday",
+		"?limit=-1",
+		"?min_margin=abc",
+	} {
+		if w := trainingGet(t, h, q); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestTrainingExport_BackendWithoutSource(t *testing.T) {
+	h := newTestHandlerWith(t, httpapi.WithSessionStore(&mockSessionStore{}))
+	if w := trainingGet(t, h, ""); w.Code != http.StatusNotImplemented {
+		t.Errorf("status %d, want 501", w.Code)
+	}
+}
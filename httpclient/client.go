@@ -69,6 +69,31 @@ func (c *Client) Insert(ctx context.Context, f memstore.Fact) (int64, error) {
 		json.Unmarshal(f.Metadata, &m)
 		body["metadata"] = m
 	}
+	if f.ExpiresAt != nil {
+		body["expires_at"] = f.ExpiresAt.UTC()
+	}
+	if len(f.Tags) > 0 {
+		body["tags"] = f.Tags
+	}
+	// The server records its own authenticated identity as the origin, so
+	// only the remaining provenance fields travel.
+	prov, err := memstore.ResolveProvenance(ctx, f.Provenance)
+	if err != nil {
+		return 0, err
+	}
+	body["source"] = prov.Source
+	if prov.SessionID != "" {
+		body["session_id"] = prov.SessionID
+	}
+	if prov.TurnFirst != "" {
+		body["turn_first"] = prov.TurnFirst
+	}
+	if prov.TurnLast != "" {
+		body["turn_last"] = prov.TurnLast
+	}
+	if prov.DocumentID != 0 {
+		body["document_id"] = prov.DocumentID
+	}
 	var result struct {
 		ID int64 `json:"id"`
 	}
@@ -140,6 +165,27 @@ func (c *Client) List(ctx context.Context, opts memstore.QueryOpts) ([]memstore.
 	if opts.CreatedBefore != nil {
 		q.Set("created_before", opts.CreatedBefore.UTC().Format(time.RFC3339))
 	}
+	if opts.Provenance.Source != "" {
+		q.Set("source", opts.Provenance.Source)
+	}
+	if opts.Provenance.SessionID != "" {
+		q.Set("session_id", opts.Provenance.SessionID)
+	}
+	if opts.Provenance.Origin != "" {
+		q.Set("origin", opts.Provenance.Origin)
+	}
+	if opts.Provenance.DocumentID != 0 {
+		q.Set("document_id", strconv.FormatInt(opts.Provenance.DocumentID, 10))
+	}
+	if len(opts.Tags.Any) > 0 {
+		q.Set("tags", strings.Join(opts.Tags.Any, ","))
+	}
+	if len(opts.Tags.All) > 0 {
+		q.Set("tags_all", strings.Join(opts.Tags.All, ","))
+	}
+	if len(opts.Tags.None) > 0 {
+		q.Set("tags_none", strings.Join(opts.Tags.None, ","))
+	}
 	var facts []memstore.Fact
 	if err := c.get(ctx, "/v1/facts?"+q.Encode(), &facts); err != nil {
 		return nil, err
@@ -159,6 +205,34 @@ func (c *Client) Supersede(ctx context.Context, oldID, newID int64) error {
 	return c.post(ctx, fmt.Sprintf("/v1/facts/%d/supersede", oldID), map[string]any{"new_id": newID}, nil)
 }
 
+func (c *Client) Revise(ctx context.Context, id int64, content string, patch map[string]any) (int64, error) {
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	body := map[string]any{"content": content}
+	if len(patch) > 0 {
+		body["metadata"] = patch
+	}
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/v1/facts/%d", id), body, &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
+func (c *Client) Merge(ctx context.Context, ids []int64, content string, patch map[string]any) (int64, error) {
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	body := map[string]any{"ids": ids, "content": content}
+	if len(patch) > 0 {
+		body["metadata"] = patch
+	}
+	if err := c.post(ctx, "/v1/facts/merge", body, &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
 func (c *Client) Confirm(ctx context.Context, id int64) error {
 	return c.post(ctx, fmt.Sprintf("/v1/facts/%d/confirm", id), nil, nil)
 }
@@ -206,6 +280,106 @@ func (c *Client) History(ctx context.Context, id int64, subject string) ([]memst
 	return entries, nil
 }
 
+func (c *Client) Trash(ctx context.Context, opts memstore.QueryOpts) ([]memstore.Fact, error) {
+	q := url.Values{}
+	if opts.Subject != "" {
+		q.Set("subject", opts.Subject)
+	}
+	if opts.Category != "" {
+		q.Set("category", opts.Category)
+	}
+	if opts.Kind != "" {
+		q.Set("kind", opts.Kind)
+	}
+	if opts.Subsystem != "" {
+		q.Set("subsystem", opts.Subsystem)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var facts []memstore.Fact
+	if err := c.get(ctx, "/v1/trash?"+q.Encode(), &facts); err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
+
+// ReviewQueue implements memstore.Reviewer via GET /v1/review.
+func (c *Client) ReviewQueue(ctx context.Context, opts memstore.ReviewOpts) ([]memstore.ReviewItem, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.MinAge > 0 {
+		q.Set("min_age", opts.MinAge.String())
+	}
+	if opts.Subject != "" {
+		q.Set("subject", opts.Subject)
+	}
+	var result struct {
+		Items []memstore.ReviewItem `json:"items"`
+	}
+	if err := c.get(ctx, "/v1/review?"+q.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// Contradictions implements memstore.ContradictionQueue via
+// GET /v1/contradictions.
+func (c *Client) Contradictions(ctx context.Context, limit int) ([]memstore.Contradiction, error) {
+	path := "/v1/contradictions"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var result struct {
+		Contradictions []memstore.Contradiction `json:"contradictions"`
+	}
+	if err := c.get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return result.Contradictions, nil
+}
+
+// FindDuplicates implements memstore.Deduper via GET /v1/admin/duplicates,
+// which requires an admin-scoped token.
+func (c *Client) FindDuplicates(ctx context.Context, opts memstore.DedupeOpts) ([]memstore.DuplicateCluster, error) {
+	q := url.Values{}
+	if opts.Threshold > 0 {
+		q.Set("threshold", strconv.FormatFloat(opts.Threshold, 'f', -1, 64))
+	}
+	if opts.LexicalThreshold > 0 {
+		q.Set("lexical_threshold", strconv.FormatFloat(opts.LexicalThreshold, 'f', -1, 64))
+	}
+	if opts.Neighbors > 0 {
+		q.Set("neighbors", strconv.Itoa(opts.Neighbors))
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var result struct {
+		Clusters []memstore.DuplicateCluster `json:"clusters"`
+	}
+	if err := c.get(ctx, "/v1/admin/duplicates?"+q.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return result.Clusters, nil
+}
+
+func (c *Client) Restore(ctx context.Context, id int64) error {
+	return c.post(ctx, fmt.Sprintf("/v1/facts/%d/restore", id), nil, nil)
+}
+
+func (c *Client) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	var result struct {
+		Purged int64 `json:"purged"`
+	}
+	if err := c.post(ctx, "/v1/trash/purge", map[string]any{"before": olderThan.UTC()}, &result); err != nil {
+		return 0, err
+	}
+	return result.Purged, nil
+}
+
 func (c *Client) Search(ctx context.Context, query string, opts memstore.SearchOpts) ([]memstore.SearchResult, error) {
 	body := searchBody(query, opts)
 	var results []memstore.SearchResult
@@ -249,6 +423,56 @@ func (c *Client) ListSubsystems(ctx context.Context, subject string) ([]string,
 	return subs, nil
 }
 
+// --- Tags ---
+
+// TagFact implements memstore.Store via POST /v1/facts/{id}/tags.
+func (c *Client) TagFact(ctx context.Context, id int64, tags ...string) error {
+	return c.post(ctx, fmt.Sprintf("/v1/facts/%d/tags", id), map[string]any{"tags": tags}, nil)
+}
+
+// UntagFact implements memstore.Store via DELETE /v1/facts/{id}/tags.
+func (c *Client) UntagFact(ctx context.Context, id int64, tags ...string) error {
+	q := url.Values{"tags": {strings.Join(tags, ",")}}
+	return c.do(ctx, "DELETE", fmt.Sprintf("/v1/facts/%d/tags?%s", id, q.Encode()), nil, nil)
+}
+
+// TagCounts implements memstore.Store via GET /v1/tags.
+func (c *Client) TagCounts(ctx context.Context, subject string) ([]memstore.TagCount, error) {
+	q := ""
+	if subject != "" {
+		q = "?subject=" + url.QueryEscape(subject)
+	}
+	var result struct {
+		Tags []memstore.TagCount `json:"tags"`
+	}
+	if err := c.get(ctx, "/v1/tags"+q, &result); err != nil {
+		return nil, err
+	}
+	return result.Tags, nil
+}
+
+// RenameTag implements memstore.Store via POST /v1/tags/{tag}/rename.
+func (c *Client) RenameTag(ctx context.Context, from, to string) (int64, error) {
+	var result struct {
+		Facts int64 `json:"facts"`
+	}
+	if err := c.post(ctx, "/v1/tags/"+url.PathEscape(from)+"/rename", map[string]any{"to": to}, &result); err != nil {
+		return 0, err
+	}
+	return result.Facts, nil
+}
+
+// DeleteTag implements memstore.Store via DELETE /v1/tags/{tag}.
+func (c *Client) DeleteTag(ctx context.Context, tag string) (int64, error) {
+	var result struct {
+		Facts int64 `json:"facts"`
+	}
+	if err := c.do(ctx, "DELETE", "/v1/tags/"+url.PathEscape(tag), nil, &result); err != nil {
+		return 0, err
+	}
+	return result.Facts, nil
+}
+
 // Embedding methods are no-ops on the client — the daemon handles embeddings.
 
 func (c *Client) NeedingEmbedding(_ context.Context, _ int) ([]memstore.Fact, error) {
@@ -327,6 +551,230 @@ func (c *Client) DeleteLink(ctx context.Context, linkID int64) error {
 	return c.do(ctx, "DELETE", fmt.Sprintf("/v1/links/%d", linkID), nil, nil)
 }
 
+// --- Citations ---
+
+// CiteChunk implements memstore.Citer via POST /v1/facts/{id}/citations.
+func (c *Client) CiteChunk(ctx context.Context, factID, chunkID int64) (*memstore.Citation, error) {
+	var cit memstore.Citation
+	if err := c.post(ctx, fmt.Sprintf("/v1/facts/%d/citations", factID), map[string]int64{"chunk_id": chunkID}, &cit); err != nil {
+		return nil, err
+	}
+	return &cit, nil
+}
+
+// Citations implements memstore.Citer via GET /v1/facts/{id}/citations.
+func (c *Client) Citations(ctx context.Context, factID int64) ([]memstore.Citation, error) {
+	var result struct {
+		Citations []memstore.Citation `json:"citations"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/v1/facts/%d/citations", factID), &result); err != nil {
+		return nil, err
+	}
+	return result.Citations, nil
+}
+
+// StaleCitations implements memstore.Citer via GET /v1/citations/stale.
+func (c *Client) StaleCitations(ctx context.Context, limit int) ([]memstore.Citation, error) {
+	path := "/v1/citations/stale"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var result struct {
+		Citations []memstore.Citation `json:"citations"`
+	}
+	if err := c.get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return result.Citations, nil
+}
+
+// AcceptCitation implements memstore.Citer via POST /v1/citations/{id}/accept.
+func (c *Client) AcceptCitation(ctx context.Context, id int64) (*memstore.Citation, error) {
+	var cit memstore.Citation
+	if err := c.post(ctx, fmt.Sprintf("/v1/citations/%d/accept", id), nil, &cit); err != nil {
+		return nil, err
+	}
+	return &cit, nil
+}
+
+// DeleteCitation implements memstore.Citer via DELETE /v1/citations/{id}.
+func (c *Client) DeleteCitation(ctx context.Context, id int64) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/v1/citations/%d", id), nil, nil)
+}
+
+// --- Bulk update and audit ---
+
+// BulkUpdate implements memstore.BulkUpdater via POST /v1/admin/bulk-update.
+// It is not retried: a repeated apply could land on a different matched set.
+// A 409 from the daemon is returned as memstore.ErrBulkPreviewStale.
+func (c *Client) BulkUpdate(ctx context.Context, req memstore.BulkUpdateRequest) (*memstore.BulkResult, error) {
+	var res memstore.BulkResult
+	if err := c.do(ctx, "POST", "/v1/admin/bulk-update", req, &res); err != nil {
+		var he *HTTPError
+		if errors.As(err, &he) && he.Code == http.StatusConflict {
+			return nil, memstore.ErrBulkPreviewStale
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// AuditLog implements memstore.AuditReader via GET /v1/admin/audit.
+func (c *Client) AuditLog(ctx context.Context, limit int) ([]memstore.AuditEntry, error) {
+	path := "/v1/admin/audit"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var result struct {
+		Entries []memstore.AuditEntry `json:"entries"`
+	}
+	if err := c.get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+// --- Namespace administration ---
+
+// Namespaces implements memstore.NamespaceAdmin via GET /v1/admin/namespaces.
+func (c *Client) Namespaces(ctx context.Context) ([]memstore.NamespaceInfo, error) {
+	var result struct {
+		Namespaces []memstore.NamespaceInfo `json:"namespaces"`
+	}
+	if err := c.get(ctx, "/v1/admin/namespaces", &result); err != nil {
+		return nil, err
+	}
+	return result.Namespaces, nil
+}
+
+// RenameNamespace implements memstore.NamespaceAdmin via
+// POST /v1/admin/namespaces/{ns}/rename.
+func (c *Client) RenameNamespace(ctx context.Context, from, to string) (*memstore.NamespaceResult, error) {
+	return c.namespaceOp(ctx, from, "rename", to, "")
+}
+
+// CopyNamespace implements memstore.NamespaceAdmin via
+// POST /v1/admin/namespaces/{ns}/copy.
+func (c *Client) CopyNamespace(ctx context.Context, from, to string) (*memstore.NamespaceResult, error) {
+	return c.namespaceOp(ctx, from, "copy", to, "")
+}
+
+// MergeNamespace implements memstore.NamespaceAdmin via
+// POST /v1/admin/namespaces/{ns}/merge.
+func (c *Client) MergeNamespace(ctx context.Context, from, to string, dup memstore.DuplicatePolicy) (*memstore.NamespaceResult, error) {
+	return c.namespaceOp(ctx, from, "merge", to, dup)
+}
+
+// DeleteNamespace implements memstore.NamespaceAdmin via
+// DELETE /v1/admin/namespaces/{ns}.
+func (c *Client) DeleteNamespace(ctx context.Context, ns string) (*memstore.NamespaceResult, error) {
+	var res memstore.NamespaceResult
+	if err := c.do(ctx, "DELETE", "/v1/admin/namespaces/"+url.PathEscape(ns), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// namespaceOp posts a rename, copy or merge. It is not retried: a repeated
+// copy would fail on the namespace the first one filled. A 409 from the
+// daemon is returned as memstore.ErrNamespaceNotEmpty.
+func (c *Client) namespaceOp(ctx context.Context, from, op, to string, dup memstore.DuplicatePolicy) (*memstore.NamespaceResult, error) {
+	body := map[string]any{"to": to}
+	if dup != "" {
+		body["duplicates"] = dup
+	}
+	var res memstore.NamespaceResult
+	if err := c.do(ctx, "POST", "/v1/admin/namespaces/"+url.PathEscape(from)+"/"+op, body, &res); err != nil {
+		var he *HTTPError
+		if errors.As(err, &he) && he.Code == http.StatusConflict {
+			return nil, fmt.Errorf("%w (%q)", memstore.ErrNamespaceNotEmpty, to)
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// --- Subject aliases ---
+
+// AddAlias implements memstore.SubjectAliaser via POST /v1/aliases.
+func (c *Client) AddAlias(ctx context.Context, alias, canonical string) (*memstore.SubjectAlias, error) {
+	var a memstore.SubjectAlias
+	if err := c.post(ctx, "/v1/aliases", map[string]string{"alias": alias, "canonical": canonical}, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RemoveAlias implements memstore.SubjectAliaser via DELETE /v1/aliases/{alias}.
+// A 404 from the daemon is returned as memstore.ErrAliasNotFound.
+func (c *Client) RemoveAlias(ctx context.Context, alias string) error {
+	return aliasNotFound(c.do(ctx, "DELETE", "/v1/aliases/"+url.PathEscape(alias), nil, nil))
+}
+
+// Aliases implements memstore.SubjectAliaser via GET /v1/aliases.
+func (c *Client) Aliases(ctx context.Context) ([]memstore.SubjectAlias, error) {
+	var result struct {
+		Aliases []memstore.SubjectAlias `json:"aliases"`
+	}
+	if err := c.get(ctx, "/v1/aliases", &result); err != nil {
+		return nil, err
+	}
+	return result.Aliases, nil
+}
+
+// RewriteAliasedFacts implements memstore.SubjectAliaser via
+// POST /v1/aliases/rewrite. It is not retried: each run writes an audit
+// entry.
+func (c *Client) RewriteAliasedFacts(ctx context.Context, alias string) (*memstore.AliasRewriteResult, error) {
+	var res memstore.AliasRewriteResult
+	if err := c.do(ctx, "POST", "/v1/aliases/rewrite", map[string]string{"alias": alias}, &res); err != nil {
+		return nil, aliasNotFound(err)
+	}
+	return &res, nil
+}
+
+// aliasNotFound maps a 404 from the alias routes to memstore.ErrAliasNotFound.
+func aliasNotFound(err error) error {
+	var he *HTTPError
+	if errors.As(err, &he) && he.Code == http.StatusNotFound {
+		return memstore.ErrAliasNotFound
+	}
+	return err
+}
+
+// --- Pinned facts ---
+
+// Pin implements memstore.Pinner via POST /v1/pins.
+func (c *Client) Pin(ctx context.Context, p memstore.Pin) (*memstore.Pin, error) {
+	var out memstore.Pin
+	if err := c.post(ctx, "/v1/pins", p, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Unpin implements memstore.Pinner via DELETE /v1/pins/{id}. A 404 from the
+// daemon is returned as memstore.ErrPinNotFound.
+func (c *Client) Unpin(ctx context.Context, factID int64) error {
+	err := c.do(ctx, "DELETE", fmt.Sprintf("/v1/pins/%d", factID), nil, nil)
+	var he *HTTPError
+	if errors.As(err, &he) && he.Code == http.StatusNotFound {
+		return memstore.ErrPinNotFound
+	}
+	return err
+}
+
+// Pins implements memstore.Pinner via GET /v1/pins.
+func (c *Client) Pins(ctx context.Context) ([]memstore.PinnedFact, error) {
+	var result struct {
+		Pins []memstore.PinnedFact `json:"pins"`
+	}
+	if err := c.get(ctx, "/v1/pins", &result); err != nil {
+		return nil, err
+	}
+	return result.Pins, nil
+}
+
 // GetPendingHints returns unconsumed context hints matching sessionID or cwd (OR semantics).
 // Either may be empty; pass both for maximum coverage.
 func (c *Client) GetPendingHints(ctx context.Context, sessionID, cwd string) ([]memstore.ContextHint, error) {
@@ -592,6 +1040,9 @@ func searchBody(query string, opts memstore.SearchOpts) map[string]any {
 	if opts.CreatedBefore != nil {
 		body["created_before"] = opts.CreatedBefore.UTC().Format(time.RFC3339)
 	}
+	if !opts.Tags.IsZero() {
+		body["tags"] = opts.Tags
+	}
 	return body
 }
 
@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/matthewjhunter/go-embedding"
 	"github.com/matthewjhunter/memstore"
@@ -311,6 +315,40 @@ func TestClient_Delete(t *testing.T) {
 	}
 }
 
+func TestClient_TrashRestorePurge(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	id, _ := c.Insert(ctx, memstore.Fact{Content: "deleted by mistake", Subject: "test"})
+	if err := c.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	trash, err := c.Trash(ctx, memstore.QueryOpts{Subject: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != id || trash[0].DeletedAt == nil {
+		t.Fatalf("Trash = %+v, want fact %d", trash, id)
+	}
+	if err := c.Restore(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := c.Get(ctx, id); f == nil {
+		t.Fatal("expected the fact back after restore")
+	}
+
+	if err := c.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	n, err := c.Purge(ctx, time.Now().Add(time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("Purge = %d, %v; want 1", n, err)
+	}
+	if err := c.Restore(ctx, id); err == nil {
+		t.Error("expected an error restoring a purged fact")
+	}
+}
+
 func TestClient_ActiveCount(t *testing.T) {
 	c := newTestClient(t)
 	ctx := context.Background()
@@ -351,6 +389,128 @@ func TestClient_Supersede(t *testing.T) {
 	}
 }
 
+func TestClient_Revise(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	id, _ := c.Insert(ctx, memstore.Fact{Content: "v1", Subject: "test", Category: "project"})
+	newID, err := c.Revise(ctx, id, "v2", map[string]any{"source": "review"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	facts, _ := c.List(ctx, memstore.QueryOpts{Subject: "test", OnlyActive: true})
+	if len(facts) != 1 || facts[0].ID != newID {
+		t.Fatalf("active facts = %+v, want only %d", facts, newID)
+	}
+	if facts[0].Content != "v2" || facts[0].Category != "project" {
+		t.Errorf("new version = %+v", facts[0])
+	}
+	if _, err := c.Revise(ctx, id, "v3", nil); err == nil {
+		t.Error("expected an error revising a superseded fact")
+	}
+}
+
+func TestClient_Merge(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	a, _ := c.Insert(ctx, memstore.Fact{Content: "a", Subject: "test", Category: "project"})
+	b, _ := c.Insert(ctx, memstore.Fact{Content: "b", Subject: "test", Category: "project"})
+	newID, err := c.Merge(ctx, []int64{a, b}, "a and b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	facts, _ := c.List(ctx, memstore.QueryOpts{Subject: "test", OnlyActive: true})
+	if len(facts) != 1 || facts[0].ID != newID || facts[0].Content != "a and b" {
+		t.Fatalf("active facts = %+v, want only merged fact %d", facts, newID)
+	}
+	if _, err := c.Merge(ctx, []int64{a, newID}, "again", nil); err == nil {
+		t.Error("expected an error merging a superseded fact")
+	}
+}
+
+func TestClient_FindDuplicates(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	a, _ := c.Insert(ctx, memstore.Fact{Content: "the staging cluster runs postgres sixteen", Subject: "infra", Category: "note"})
+	b, _ := c.Insert(ctx, memstore.Fact{Content: "the staging cluster runs postgres sixteen", Subject: "ops", Category: "note"})
+	c.Insert(ctx, memstore.Fact{Content: "tabs, not spaces", Subject: "style", Category: "note"})
+
+	clusters, err := c.FindDuplicates(ctx, memstore.DedupeOpts{Threshold: 0.95, Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || len(clusters[0].Facts) != 2 {
+		t.Fatalf("clusters = %+v, want one cluster of two", clusters)
+	}
+	if p := clusters[0].Pairs[0]; p.A != a || p.B != b {
+		t.Errorf("pair = %+v, want %d~%d", p, a, b)
+	}
+}
+
+func TestClient_ReviewQueue(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/review" {
+			t.Errorf("path = %s, want /v1/review", r.URL.Path)
+		}
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(map[string]any{"items": []memstore.ReviewItem{
+			{Fact: memstore.Fact{ID: 7, Content: "builds use make"}, Priority: 2.5, Reasons: []string{"kind convention"}},
+		}})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	items, err := httpclient.New(srv.URL, "").ReviewQueue(ctx, memstore.ReviewOpts{Limit: 5, MinAge: 30 * 24 * time.Hour, Subject: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Fact.ID != 7 || items[0].Priority != 2.5 {
+		t.Fatalf("items = %+v", items)
+	}
+	if query.Get("limit") != "5" || query.Get("subject") != "ci" || query.Get("min_age") != "720h0m0s" {
+		t.Errorf("query = %v", query)
+	}
+
+	// Round trip through the real handler: a fresh fact is not due.
+	c := newTestClient(t)
+	c.Insert(ctx, memstore.Fact{Content: "fresh fact", Subject: "ci", Category: "project"})
+	if items, err := c.ReviewQueue(ctx, memstore.ReviewOpts{}); err != nil || len(items) != 0 {
+		t.Errorf("fresh store = %+v, %v; want none", items, err)
+	}
+}
+
+func TestClient_Contradictions(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	a, _ := c.Insert(ctx, memstore.Fact{Content: "retries are capped at 3", Subject: "retries", Category: "project"})
+	b, _ := c.Insert(ctx, memstore.Fact{Content: "retries are unlimited", Subject: "retries", Category: "project"})
+	link, err := c.LinkFacts(ctx, a, b, memstore.LinkContradicts, true, "capped vs unlimited", map[string]any{"status": memstore.ContradictionOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue, err := c.Contradictions(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 1 || queue[0].LinkID != link || queue[0].A.Content != "retries are capped at 3" {
+		t.Fatalf("queue = %+v, want the one contradiction", queue)
+	}
+
+	if err := memstore.DismissContradiction(ctx, c, link); err != nil {
+		t.Fatalf("DismissContradiction: %v", err)
+	}
+	if queue, err := c.Contradictions(ctx, 0); err != nil || len(queue) != 0 {
+		t.Errorf("queue after dismissal = %+v, %v; want empty", queue, err)
+	}
+}
+
 func TestClient_InsertWithMetadata(t *testing.T) {
 	c := newTestClient(t)
 	ctx := context.Background()
@@ -501,3 +661,301 @@ func TestClient_Auth(t *testing.T) {
 		t.Fatalf("expected success with correct key: %v", err)
 	}
 }
+
+func TestClient_Provenance(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	prov := memstore.Provenance{Source: memstore.SourceImport, SessionID: "sess-3", TurnFirst: "a", TurnLast: "b"}
+	if _, err := c.Insert(memstore.WithProvenance(ctx, prov), memstore.Fact{Content: "imported", Subject: "p", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Insert(ctx, memstore.Fact{Content: "typed", Subject: "p", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+
+	facts, err := c.List(ctx, memstore.QueryOpts{Provenance: memstore.ProvenanceFilter{SessionID: "sess-3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 1 || facts[0].Provenance != prov {
+		t.Fatalf("facts = %+v, want one with provenance %+v", facts, prov)
+	}
+	facts, err = c.List(ctx, memstore.QueryOpts{Provenance: memstore.ProvenanceFilter{Source: memstore.SourceManual}})
+	if err != nil || len(facts) != 1 || facts[0].Content != "typed" {
+		t.Errorf("manual facts = %+v, %v; want the second insert", facts, err)
+	}
+}
+
+func TestClient_Citations(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/v1/facts/7/citations":
+			if r.Method == http.MethodPost {
+				var body map[string]int64
+				json.NewDecoder(r.Body).Decode(&body)
+				json.NewEncoder(w).Encode(memstore.Citation{ID: 1, FactID: 7, ChunkID: body["chunk_id"], Status: memstore.CitationCurrent})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"citations": []memstore.Citation{
+				{ID: 1, FactID: 7, Status: memstore.CitationChanged, Current: &memstore.DocumentChunk{Content: "func Drain() {}"}},
+			}})
+		case "/v1/citations/stale":
+			json.NewEncoder(w).Encode(map[string]any{"citations": []memstore.Citation{{ID: 1, FactID: 7, Status: memstore.CitationChanged}}})
+		case "/v1/citations/1/accept":
+			json.NewEncoder(w).Encode(memstore.Citation{ID: 1, FactID: 7, Status: memstore.CitationCurrent})
+		default:
+			w.Write([]byte(`{"status":"deleted"}`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := httpclient.New(srv.URL, "")
+	if cit, err := c.CiteChunk(ctx, 7, 40); err != nil || cit.ChunkID != 40 {
+		t.Fatalf("CiteChunk = %+v, %v", cit, err)
+	}
+	if cs, err := c.Citations(ctx, 7); err != nil || len(cs) != 1 || cs[0].Current == nil || cs[0].Current.Content != "func Drain() {}" {
+		t.Fatalf("Citations = %+v, %v", cs, err)
+	}
+	if cs, err := c.StaleCitations(ctx, 5); err != nil || len(cs) != 1 {
+		t.Fatalf("StaleCitations = %+v, %v", cs, err)
+	}
+	if cit, err := c.AcceptCitation(ctx, 1); err != nil || cit.Status != memstore.CitationCurrent {
+		t.Fatalf("AcceptCitation = %+v, %v", cit, err)
+	}
+	if err := c.DeleteCitation(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"POST /v1/facts/7/citations", "GET /v1/facts/7/citations", "GET /v1/citations/stale?limit=5",
+		"POST /v1/citations/1/accept", "DELETE /v1/citations/1",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestClient_BulkUpdate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/admin/bulk-update":
+			var req memstore.BulkUpdateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Token == "stale" {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"error":"preview stale"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(memstore.BulkResult{Matched: 2, IDs: []int64{1, 2}, Token: "abc", Applied: !req.DryRun})
+		case "/v1/admin/audit":
+			json.NewEncoder(w).Encode(map[string]any{"entries": []memstore.AuditEntry{{ID: 3, Action: memstore.AuditBulkUpdate, FactIDs: []int64{1, 2}}}})
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := httpclient.New(srv.URL, "")
+	ci := "ci"
+	req := memstore.BulkUpdateRequest{Filter: memstore.QueryOpts{Subject: "build"}, Change: memstore.BulkChange{Subject: &ci}, DryRun: true}
+	if res, err := c.BulkUpdate(ctx, req); err != nil || res.Matched != 2 || res.Applied {
+		t.Fatalf("BulkUpdate preview = %+v, %v", res, err)
+	}
+	req.DryRun, req.Token = false, "stale"
+	if _, err := c.BulkUpdate(ctx, req); !errors.Is(err, memstore.ErrBulkPreviewStale) {
+		t.Fatalf("BulkUpdate stale = %v, want ErrBulkPreviewStale", err)
+	}
+	if es, err := c.AuditLog(ctx, 10); err != nil || len(es) != 1 || es[0].Action != memstore.AuditBulkUpdate {
+		t.Fatalf("AuditLog = %+v, %v", es, err)
+	}
+}
+
+func TestClient_NamespaceAdmin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/admin/namespaces":
+			json.NewEncoder(w).Encode(map[string]any{"namespaces": []memstore.NamespaceInfo{{Namespace: "work", Facts: 4, Active: 3}}})
+		case "POST /v1/admin/namespaces/work/merge":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["to"] != "home" || body["duplicates"] != "keep" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(memstore.NamespaceResult{Facts: 4, Links: 1})
+		case "POST /v1/admin/namespaces/work/rename":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":"destination namespace is not empty"}`))
+		case "DELETE /v1/admin/namespaces/work":
+			json.NewEncoder(w).Encode(memstore.NamespaceResult{Facts: 4})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := httpclient.New(srv.URL, "")
+	var _ memstore.NamespaceAdmin = c
+	if infos, err := c.Namespaces(ctx); err != nil || len(infos) != 1 || infos[0].Active != 3 {
+		t.Fatalf("Namespaces = %+v, %v", infos, err)
+	}
+	if res, err := c.MergeNamespace(ctx, "work", "home", memstore.DuplicatesKeep); err != nil || res.Facts != 4 {
+		t.Fatalf("MergeNamespace = %+v, %v", res, err)
+	}
+	if _, err := c.RenameNamespace(ctx, "work", "home"); !errors.Is(err, memstore.ErrNamespaceNotEmpty) {
+		t.Fatalf("RenameNamespace = %v, want ErrNamespaceNotEmpty", err)
+	}
+	if res, err := c.DeleteNamespace(ctx, "work"); err != nil || res.Facts != 4 {
+		t.Fatalf("DeleteNamespace = %+v, %v", res, err)
+	}
+}
+
+func TestClient_SubjectAliases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "POST /v1/aliases":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(memstore.SubjectAlias{Alias: body["alias"], Canonical: body["canonical"]})
+		case "GET /v1/aliases":
+			w.Write([]byte(`{"aliases":[{"alias":"memstore-fork","canonical":"memstore"}]}`))
+		case "DELETE /v1/aliases/infodancer%2Foidclient":
+			w.Write([]byte(`{"status":"deleted"}`))
+		case "POST /v1/aliases/rewrite":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["alias"] == "nope" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"subject alias not found"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(memstore.AliasRewriteResult{Facts: 2, AuditID: 7})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := httpclient.New(srv.URL, "")
+	var _ memstore.SubjectAliaser = c
+	if a, err := c.AddAlias(ctx, "memstore-fork", "memstore"); err != nil || a.Canonical != "memstore" {
+		t.Fatalf("AddAlias = %+v, %v", a, err)
+	}
+	if aliases, err := c.Aliases(ctx); err != nil || len(aliases) != 1 || aliases[0].Alias != "memstore-fork" {
+		t.Fatalf("Aliases = %+v, %v", aliases, err)
+	}
+	if err := c.RemoveAlias(ctx, "infodancer/oidclient"); err != nil {
+		t.Fatalf("RemoveAlias = %v", err)
+	}
+	if err := c.RemoveAlias(ctx, "missing"); !errors.Is(err, memstore.ErrAliasNotFound) {
+		t.Fatalf("RemoveAlias(missing) = %v, want ErrAliasNotFound", err)
+	}
+	if res, err := c.RewriteAliasedFacts(ctx, ""); err != nil || res.Facts != 2 {
+		t.Fatalf("RewriteAliasedFacts = %+v, %v", res, err)
+	}
+	if _, err := c.RewriteAliasedFacts(ctx, "nope"); !errors.Is(err, memstore.ErrAliasNotFound) {
+		t.Fatalf("RewriteAliasedFacts(nope) = %v, want ErrAliasNotFound", err)
+	}
+}
+
+func TestClient_Pins(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/pins":
+			var p memstore.Pin
+			json.NewDecoder(r.Body).Decode(&p)
+			p.Position = 1
+			json.NewEncoder(w).Encode(p)
+		case "GET /v1/pins":
+			w.Write([]byte(`{"pins":[{"fact_id":3,"subject":"memstore","position":1,"fact":{"id":4,"content":"never commit generated protobufs"}}]}`))
+		case "DELETE /v1/pins/3":
+			w.Write([]byte(`{"status":"unpinned"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"fact is not pinned"}`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := httpclient.New(srv.URL, "")
+	var _ memstore.Pinner = c
+	if p, err := c.Pin(ctx, memstore.Pin{FactID: 3, Subject: "memstore"}); err != nil || p.Position != 1 {
+		t.Fatalf("Pin = %+v, %v", p, err)
+	}
+	if pins, err := c.Pins(ctx); err != nil || len(pins) != 1 || pins[0].Fact.ID != 4 {
+		t.Fatalf("Pins = %+v, %v", pins, err)
+	}
+	if err := c.Unpin(ctx, 3); err != nil {
+		t.Fatalf("Unpin = %v", err)
+	}
+	if err := c.Unpin(ctx, 9); !errors.Is(err, memstore.ErrPinNotFound) {
+		t.Fatalf("Unpin(9) = %v, want ErrPinNotFound", err)
+	}
+}
+
+func TestClient_Tags(t *testing.T) {
+	var gotSearch map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/facts/3/tags":
+			w.Write([]byte(`{"id":3,"tags":["perf"]}`))
+		case "DELETE /v1/facts/3/tags":
+			if r.URL.Query().Get("tags") != "perf,todo" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"status":"ok"}`))
+		case "GET /v1/tags":
+			w.Write([]byte(`{"tags":[{"tag":"perf","count":2}]}`))
+		case "POST /v1/tags/perf/rename":
+			w.Write([]byte(`{"facts":2}`))
+		case "DELETE /v1/tags/perf":
+			w.Write([]byte(`{"facts":1}`))
+		case "GET /v1/facts":
+			if r.URL.Query().Get("tags_none") != "wip" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`[{"ID":1,"Tags":["perf"]}]`))
+		case "POST /v1/search":
+			json.NewDecoder(r.Body).Decode(&gotSearch)
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := httpclient.New(srv.URL, "")
+	if err := c.TagFact(ctx, 3, "perf"); err != nil {
+		t.Fatalf("TagFact = %v", err)
+	}
+	if err := c.UntagFact(ctx, 3, "perf", "todo"); err != nil {
+		t.Fatalf("UntagFact = %v", err)
+	}
+	if counts, err := c.TagCounts(ctx, ""); err != nil || len(counts) != 1 || counts[0].Count != 2 {
+		t.Fatalf("TagCounts = %+v, %v", counts, err)
+	}
+	if n, err := c.RenameTag(ctx, "perf", "performance"); err != nil || n != 2 {
+		t.Fatalf("RenameTag = %d, %v", n, err)
+	}
+	if n, err := c.DeleteTag(ctx, "perf"); err != nil || n != 1 {
+		t.Fatalf("DeleteTag = %d, %v", n, err)
+	}
+	facts, err := c.List(ctx, memstore.QueryOpts{Tags: memstore.TagFilter{None: []string{"wip"}}})
+	if err != nil || len(facts) != 1 || len(facts[0].Tags) != 1 {
+		t.Fatalf("List = %+v, %v", facts, err)
+	}
+	if _, err := c.Search(ctx, "q", memstore.SearchOpts{Tags: memstore.TagFilter{All: []string{"perf"}}}); err != nil {
+		t.Fatalf("Search = %v", err)
+	}
+	if tags, _ := gotSearch["tags"].(map[string]any); tags == nil || tags["all"] == nil {
+		t.Errorf("search body tags = %v, want the filter", gotSearch["tags"])
+	}
+}
@@ -15,10 +15,15 @@
 package conformance
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/matthewjhunter/memstore"
 )
@@ -91,6 +96,39 @@ func Run(t *testing.T, opts Options) {
 	t.Run("EmbedQuarantine", func(t *testing.T) {
 		testEmbedQuarantine(t, opts.NewStore(t))
 	})
+	t.Run("ExpiryAndReaper", func(t *testing.T) {
+		testExpiryAndReaper(t, opts.NewStore(t))
+	})
+	t.Run("TrashRestorePurge", func(t *testing.T) {
+		testTrashRestorePurge(t, opts.NewStore(t))
+	})
+	t.Run("Revise", func(t *testing.T) {
+		testRevise(t, opts.NewStore(t))
+	})
+	t.Run("Merge", func(t *testing.T) {
+		testMerge(t, opts.NewStore(t))
+	})
+	t.Run("FindDuplicates", func(t *testing.T) {
+		testFindDuplicates(t, opts.NewStore(t))
+	})
+	t.Run("FindDuplicatesParaphrases", func(t *testing.T) {
+		testFindDuplicatesParaphrases(t, opts.NewStore(t))
+	})
+	t.Run("ContradictionAudit", func(t *testing.T) {
+		testContradictionAudit(t, opts.NewStore(t))
+	})
+	t.Run("ReviewQueue", func(t *testing.T) {
+		testReviewQueue(t, opts.NewStore(t))
+	})
+	t.Run("Provenance", func(t *testing.T) {
+		testProvenance(t, opts.NewStore(t))
+	})
+	t.Run("Tags", func(t *testing.T) {
+		testTags(t, opts.NewStore(t))
+	})
+	t.Run("MetadataSchemas", func(t *testing.T) {
+		testMetadataSchemas(t, opts.NewStore(t))
+	})
 	t.Run("NamespaceIsolation", func(t *testing.T) {
 		if opts.NewStoreNS == nil {
 			t.Skip("NewStoreNS not provided; skipping namespace isolation test")
@@ -661,6 +699,933 @@ func testEmbedQuarantine(t *testing.T, s memstore.Store) {
 	}
 }
 
+func testExpiryAndReaper(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	insert := func(content string, expiresAt *time.Time) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: "expiry", Category: "test", ExpiresAt: expiresAt})
+		if err != nil {
+			t.Fatalf("Insert %q: %v", content, err)
+		}
+		return id
+	}
+	permanent := insert("durable fact", nil)
+	later := insert("expires later", &future)
+	expired := insert("already expired", &past)
+	// An expired fact that ends a supersession chain.
+	old := insert("chain head", nil)
+	chainEnd := insert("expired chain end", &past)
+	if err := s.Supersede(ctx, old, chainEnd); err != nil {
+		t.Fatalf("Supersede: %v", err)
+	}
+
+	got, err := s.Get(ctx, later)
+	if err != nil || got == nil || got.ExpiresAt == nil || !got.ExpiresAt.Equal(future) {
+		t.Fatalf("Get(later).ExpiresAt = %v (%v), want %v", got, err, future)
+	}
+
+	ids := func(facts []memstore.Fact) []int64 {
+		var out []int64
+		for _, f := range facts {
+			out = append(out, f.ID)
+		}
+		return out
+	}
+	listed, err := s.List(ctx, memstore.QueryOpts{Subject: "expiry", OnlyActive: true})
+	if err != nil {
+		t.Fatalf("List OnlyActive: %v", err)
+	}
+	if want := []int64{permanent, later}; !slices.Equal(ids(listed), want) {
+		t.Errorf("List OnlyActive = %v, want %v", ids(listed), want)
+	}
+	bySubject, err := s.BySubject(ctx, "expiry", true)
+	if err != nil {
+		t.Fatalf("BySubject: %v", err)
+	}
+	if want := []int64{permanent, later}; !slices.Equal(ids(bySubject), want) {
+		t.Errorf("BySubject active = %v, want %v", ids(bySubject), want)
+	}
+	if n, err := s.ActiveCount(ctx); err != nil || n != 2 {
+		t.Errorf("ActiveCount = %d (%v), want 2", n, err)
+	}
+	results, err := s.SearchFTS(ctx, "expired", memstore.SearchOpts{OnlyActive: true})
+	if err != nil {
+		t.Fatalf("SearchFTS: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("SearchFTS OnlyActive returned %d expired facts", len(results))
+	}
+	all, err := s.List(ctx, memstore.QueryOpts{Subject: "expiry"})
+	if err != nil || len(all) != 5 {
+		t.Errorf("List without OnlyActive = %d facts (%v), want all 5", len(all), err)
+	}
+
+	reaper, ok := s.(memstore.ExpiryReaper)
+	if !ok {
+		return
+	}
+	res, err := reaper.ReapExpired(ctx, memstore.ExpiryArchive, now)
+	if err != nil {
+		t.Fatalf("ReapExpired archive: %v", err)
+	}
+	slices.Sort(res.Archived)
+	if want := []int64{expired, chainEnd}; !slices.Equal(res.Archived, want) || len(res.Deleted) != 0 {
+		t.Errorf("archive sweep = %+v, want archived %v", res, want)
+	}
+	if f, _ := s.Get(ctx, expired); f == nil || f.ArchivedAt == nil {
+		t.Errorf("archived fact = %+v, want ArchivedAt set", f)
+	}
+	if res, err := reaper.ReapExpired(ctx, memstore.ExpiryArchive, now); err != nil || len(res.Archived) != 0 {
+		t.Errorf("second archive sweep = %+v (%v), want nothing", res, err)
+	}
+
+	res, err = reaper.ReapExpired(ctx, memstore.ExpiryDelete, now)
+	if err != nil {
+		t.Fatalf("ReapExpired delete: %v", err)
+	}
+	if !slices.Equal(res.Deleted, []int64{expired}) {
+		t.Errorf("delete sweep deleted %v, want only %d (the chain end is kept)", res.Deleted, expired)
+	}
+	if f, _ := s.Get(ctx, expired); f != nil {
+		t.Error("deleted fact is still readable")
+	}
+	trash, err := s.Trash(ctx, memstore.QueryOpts{Subject: "expiry"})
+	if err != nil || !slices.Equal(ids(trash), []int64{expired}) {
+		t.Errorf("Trash after delete sweep = %v (%v), want %d", ids(trash), err, expired)
+	}
+	history, err := s.History(ctx, old, "")
+	if err != nil || len(history) != 2 || history[1].Fact.ArchivedAt == nil {
+		t.Errorf("History after delete sweep = %d entries (%v), want the archived chain end kept", len(history), err)
+	}
+}
+
+// testTrashRestorePurge verifies soft deletion: a deleted fact and its links
+// disappear from every read, come back together on Restore, and are only
+// removed for good by Purge, which takes the deleted fact's superseded
+// predecessors with it.
+func testTrashRestorePurge(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	insert := func(content string) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: "trash", Category: "test"})
+		if err != nil {
+			t.Fatalf("Insert %q: %v", content, err)
+		}
+		return id
+	}
+	a := insert("the deploy key lives in vault")
+	b := insert("vault is at vault.internal")
+	old := insert("staging runs on port 8080")
+	replacement := insert("staging runs on port 9090")
+	if err := s.Supersede(ctx, old, replacement); err != nil {
+		t.Fatalf("Supersede: %v", err)
+	}
+	linkID, err := s.LinkFacts(ctx, a, b, "reference", false, "", nil)
+	if err != nil {
+		t.Fatalf("LinkFacts: %v", err)
+	}
+
+	if err := s.Delete(ctx, a); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if f, err := s.Get(ctx, a); err != nil || f != nil {
+		t.Errorf("Get(trashed) = %+v, %v; want nil, nil", f, err)
+	}
+	if facts, _ := s.List(ctx, memstore.QueryOpts{Subject: "trash"}); len(facts) != 3 {
+		t.Errorf("List after delete = %d facts, want 3", len(facts))
+	}
+	if ok, _ := s.Exists(ctx, "the deploy key lives in vault", "trash"); ok {
+		t.Error("Exists reports a trashed fact")
+	}
+	if results, _ := s.SearchFTS(ctx, "deploy key", memstore.SearchOpts{}); len(results) != 0 {
+		t.Errorf("SearchFTS returned %d results for a trashed fact", len(results))
+	}
+	if l, err := s.GetLink(ctx, linkID); err != nil || l != nil {
+		t.Errorf("GetLink with a trashed endpoint = %+v, %v; want nil, nil", l, err)
+	}
+	if links, _ := s.GetLinks(ctx, b, memstore.LinkBoth); len(links) != 0 {
+		t.Errorf("GetLinks on the live endpoint = %d links, want 0", len(links))
+	}
+	if err := s.Delete(ctx, a); err == nil {
+		t.Error("deleting a trashed fact again succeeded")
+	}
+
+	trash, err := s.Trash(ctx, memstore.QueryOpts{Subject: "trash"})
+	if err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != a || trash[0].DeletedAt == nil {
+		t.Fatalf("Trash = %+v, want fact %d with DeletedAt set", trash, a)
+	}
+
+	if err := s.Restore(ctx, a); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if f, _ := s.Get(ctx, a); f == nil || f.DeletedAt != nil {
+		t.Errorf("Get after restore = %+v, want the live fact", f)
+	}
+	if links, _ := s.GetLinks(ctx, b, memstore.LinkBoth); len(links) != 1 || links[0].ID != linkID {
+		t.Errorf("GetLinks after restore = %+v, want link %d back", links, linkID)
+	}
+	if err := s.Restore(ctx, a); err == nil {
+		t.Error("restoring a fact that is not in the trash succeeded")
+	}
+
+	if err := s.Delete(ctx, a); err != nil {
+		t.Fatalf("Delete again: %v", err)
+	}
+	if err := s.Delete(ctx, replacement); err != nil {
+		t.Fatalf("Delete replacement: %v", err)
+	}
+	if n, err := s.Purge(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("Purge(an hour ago) = %d (%v), want nothing old enough", n, err)
+	}
+	// The replacement's predecessor goes with it: superseded_by must never
+	// point at a missing fact.
+	if n, err := s.Purge(ctx, time.Now().Add(time.Minute)); err != nil || n != 3 {
+		t.Errorf("Purge = %d (%v), want 3 (two trashed facts and one predecessor)", n, err)
+	}
+	if trash, _ := s.Trash(ctx, memstore.QueryOpts{}); len(trash) != 0 {
+		t.Errorf("Trash after purge = %d facts, want 0", len(trash))
+	}
+	if err := s.Restore(ctx, a); err == nil {
+		t.Error("restoring a purged fact succeeded")
+	}
+	if facts, _ := s.List(ctx, memstore.QueryOpts{Subject: "trash"}); len(facts) != 1 || facts[0].ID != b {
+		t.Errorf("List after purge = %+v, want only fact %d", facts, b)
+	}
+}
+
+func testRevise(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	expires := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	id, err := s.Insert(ctx, memstore.Fact{
+		Content: "the build runs on go 1.24", Subject: "revise", Category: "project",
+		Kind: "convention", Subsystem: "ci", ExpiresAt: &expires,
+		Metadata: json.RawMessage(`{"source":"readme","stale":true}`),
+	})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	other, err := s.Insert(ctx, memstore.Fact{Content: "ci config lives in .github", Subject: "revise", Category: "project"})
+	if err != nil {
+		t.Fatalf("Insert other: %v", err)
+	}
+	if _, err := s.LinkFacts(ctx, other, id, "reference", false, "see", nil); err != nil {
+		t.Fatalf("LinkFacts: %v", err)
+	}
+
+	newID, err := s.Revise(ctx, id, "the build runs on go 1.25", map[string]any{"stale": nil, "reviewed": "yes"})
+	if err != nil {
+		t.Fatalf("Revise: %v", err)
+	}
+	if newID == id {
+		t.Fatal("Revise returned the old ID")
+	}
+
+	old, _ := s.Get(ctx, id)
+	if old == nil || old.SupersededBy == nil || *old.SupersededBy != newID {
+		t.Errorf("old fact = %+v, want superseded by %d", old, newID)
+	}
+	f, err := s.Get(ctx, newID)
+	if err != nil || f == nil {
+		t.Fatalf("Get(new) = %v, %v", f, err)
+	}
+	if f.Content != "the build runs on go 1.25" || f.Subject != "revise" || f.Category != "project" ||
+		f.Kind != "convention" || f.Subsystem != "ci" || f.SupersededBy != nil {
+		t.Errorf("new version = %+v, want the old fact's fields with new content", f)
+	}
+	if f.ExpiresAt == nil || !f.ExpiresAt.Equal(expires) {
+		t.Errorf("new version ExpiresAt = %v, want %v", f.ExpiresAt, expires)
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(f.Metadata, &meta); err != nil {
+		t.Fatalf("unmarshal metadata %s: %v", f.Metadata, err)
+	}
+	if meta["source"] != "readme" || meta["reviewed"] != "yes" || meta["stale"] != nil {
+		t.Errorf("new version metadata = %v, want source kept, reviewed set, stale removed", meta)
+	}
+	links, err := s.GetLinks(ctx, newID, memstore.LinkInbound)
+	if err != nil || len(links) != 1 || links[0].SourceID != other || links[0].Label != "see" {
+		t.Errorf("GetLinks(new, inbound) = %+v, %v; want the copied link from %d", links, err, other)
+	}
+	if hist, _ := s.History(ctx, newID, ""); len(hist) != 2 || hist[0].Fact.ID != id {
+		t.Errorf("History(new) = %d entries, want the old version then the new one", len(hist))
+	}
+
+	if _, err := s.Revise(ctx, id, "another try", nil); err == nil {
+		t.Error("revising a superseded fact succeeded")
+	}
+	if _, err := s.Revise(ctx, newID, "  ", nil); err == nil {
+		t.Error("revising with empty content succeeded")
+	}
+	if _, err := s.Revise(ctx, missingFactID, "nothing here", nil); err == nil {
+		t.Error("revising a missing fact succeeded")
+	}
+	// A revision would inherit the passed expiry and be inactive on arrival,
+	// while superseding the original.
+	past := time.Now().Add(-time.Hour)
+	expired, err := s.Insert(ctx, memstore.Fact{Content: "ci is down until noon", Subject: "revise", Category: "project", ExpiresAt: &past})
+	if err != nil {
+		t.Fatalf("Insert expired: %v", err)
+	}
+	if _, err := s.Revise(ctx, expired, "ci is down until 2pm", nil); !errors.Is(err, memstore.ErrFactExpired) {
+		t.Errorf("revising an expired fact = %v, want ErrFactExpired", err)
+	}
+	if f, _ := s.Get(ctx, expired); f == nil || f.SupersededBy != nil {
+		t.Errorf("expired fact after refused revision = %+v, want it unsuperseded", f)
+	}
+	if facts, _ := s.List(ctx, memstore.QueryOpts{Subject: "revise", OnlyActive: true}); len(facts) != 2 {
+		t.Errorf("active facts after failed revisions = %d, want 2", len(facts))
+	}
+}
+
+func testMerge(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	soon := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	later := soon.Add(48 * time.Hour)
+	a, err := s.Insert(ctx, memstore.Fact{
+		Content: "deploys go out on tuesdays", Subject: "merge", Category: "project",
+		Kind: "convention", Subsystem: "release", ExpiresAt: &soon,
+		Metadata: json.RawMessage(`{"source":"wiki","stale":true}`),
+	})
+	if err != nil {
+		t.Fatalf("Insert a: %v", err)
+	}
+	b, err := s.Insert(ctx, memstore.Fact{
+		Content: "releases ship tuesday mornings", Subject: "merge-other", Category: "note", ExpiresAt: &later,
+	})
+	if err != nil {
+		t.Fatalf("Insert b: %v", err)
+	}
+	neighbour, err := s.Insert(ctx, memstore.Fact{Content: "release checklist", Subject: "merge", Category: "project"})
+	if err != nil {
+		t.Fatalf("Insert neighbour: %v", err)
+	}
+	// a <-> b becomes a self-link and must vanish; both sources pointing at
+	// neighbour with the same edge must collapse to one.
+	for _, l := range []struct{ src, dst int64 }{{a, b}, {a, neighbour}, {b, neighbour}, {neighbour, b}} {
+		if _, err := s.LinkFacts(ctx, l.src, l.dst, "reference", false, "", nil); err != nil {
+			t.Fatalf("LinkFacts(%d, %d): %v", l.src, l.dst, err)
+		}
+	}
+
+	newID, err := s.Merge(ctx, []int64{a, b, a}, "deploys ship tuesday mornings", map[string]any{"stale": nil})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	for _, id := range []int64{a, b} {
+		old, _ := s.Get(ctx, id)
+		if old == nil || old.SupersededBy == nil || *old.SupersededBy != newID {
+			t.Errorf("source %d = %+v, want superseded by %d", id, old, newID)
+		}
+	}
+	f, err := s.Get(ctx, newID)
+	if err != nil || f == nil {
+		t.Fatalf("Get(merged) = %v, %v", f, err)
+	}
+	if f.Content != "deploys ship tuesday mornings" || f.Subject != "merge" || f.Category != "project" ||
+		f.Kind != "convention" || f.Subsystem != "release" {
+		t.Errorf("merged fact = %+v, want the first source's fields with new content", f)
+	}
+	if f.ExpiresAt == nil || !f.ExpiresAt.Equal(later) {
+		t.Errorf("merged ExpiresAt = %v, want the latest source expiry %v", f.ExpiresAt, later)
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(f.Metadata, &meta); err != nil {
+		t.Fatalf("unmarshal metadata %s: %v", f.Metadata, err)
+	}
+	from, _ := meta[memstore.MergedFromKey].([]any)
+	if meta["source"] != "wiki" || meta["stale"] != nil || len(from) != 2 ||
+		from[0] != float64(a) || from[1] != float64(b) {
+		t.Errorf("merged metadata = %v, want source kept, stale removed, %s=[%d %d]", meta, memstore.MergedFromKey, a, b)
+	}
+
+	out, err := s.GetLinks(ctx, newID, memstore.LinkOutbound)
+	if err != nil || len(out) != 1 || out[0].TargetID != neighbour {
+		t.Errorf("GetLinks(merged, outbound) = %+v, %v; want one link to %d", out, err, neighbour)
+	}
+	in, err := s.GetLinks(ctx, newID, memstore.LinkInbound)
+	if err != nil || len(in) != 1 || in[0].SourceID != neighbour {
+		t.Errorf("GetLinks(merged, inbound) = %+v, %v; want one link from %d", in, err, neighbour)
+	}
+
+	c, err := s.Insert(ctx, memstore.Fact{Content: "permanent fact", Subject: "merge", Category: "project"})
+	if err != nil {
+		t.Fatalf("Insert c: %v", err)
+	}
+	permID, err := s.Merge(ctx, []int64{newID, c}, "deploys ship tuesday mornings, permanently", nil)
+	if err != nil {
+		t.Fatalf("Merge with a permanent source: %v", err)
+	}
+	if pf, _ := s.Get(ctx, permID); pf == nil || pf.ExpiresAt != nil {
+		t.Errorf("merge including a permanent fact = %+v, want no expiry", pf)
+	}
+
+	if _, err := s.Merge(ctx, []int64{a, neighbour}, "already merged", nil); err == nil {
+		t.Error("merging a superseded fact succeeded")
+	}
+	if _, err := s.Merge(ctx, []int64{permID, permID}, "just one", nil); err == nil {
+		t.Error("merging a single fact succeeded")
+	}
+	if _, err := s.Merge(ctx, []int64{permID, neighbour}, " ", nil); err == nil {
+		t.Error("merging with empty content succeeded")
+	}
+	if _, err := s.Merge(ctx, []int64{permID, missingFactID}, "nothing here", nil); err == nil {
+		t.Error("merging a missing fact succeeded")
+	}
+	if nf, _ := s.Get(ctx, neighbour); nf == nil || nf.SupersededBy != nil {
+		t.Error("failed merge superseded the neighbour")
+	}
+}
+
+func testFindDuplicates(t *testing.T, s memstore.Store) {
+	t.Helper()
+	d, ok := s.(memstore.Deduper)
+	if !ok {
+		t.Skip("store does not implement memstore.Deduper")
+	}
+	ctx := context.Background()
+
+	insert := func(content, subject string, emb []float32) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: subject, Category: "note"})
+		if err != nil {
+			t.Fatalf("Insert %q: %v", content, err)
+		}
+		if emb != nil {
+			if err := s.SetEmbedding(ctx, id, emb); err != nil {
+				t.Fatalf("SetEmbedding %d: %v", id, err)
+			}
+		}
+		return id
+	}
+	// a and b are near-identical embeddings under different subjects; c is
+	// unrelated; e1 and e2 are unembedded and share most of their terms.
+	a := insert("the api listens on port 8230", "api", []float32{1, 0, 0, 0})
+	b := insert("api port is 8230", "daemon", []float32{0.99, 0.05, 0, 0})
+	insert("tabs, not spaces", "style", []float32{0, 1, 0, 0})
+	e1 := insert("the staging cluster runs postgres sixteen", "infra", nil)
+	e2 := insert("the staging cluster runs postgres sixteen now", "ops", nil)
+	// Trashed and superseded facts are never reported.
+	gone := insert("the api listens on port 8230 (copy)", "api", []float32{1, 0.01, 0, 0})
+	if err := s.Delete(ctx, gone); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	old := insert("the api port is 8230", "old", []float32{1, 0, 0.01, 0})
+	if err := s.Supersede(ctx, old, a); err != nil {
+		t.Fatalf("Supersede: %v", err)
+	}
+
+	clusters, err := d.FindDuplicates(ctx, memstore.DedupeOpts{Threshold: 0.8})
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("FindDuplicates = %d clusters, want 2: %+v", len(clusters), clusters)
+	}
+	ids := func(c memstore.DuplicateCluster) []int64 {
+		var out []int64
+		for _, f := range c.Facts {
+			out = append(out, f.ID)
+		}
+		slices.Sort(out)
+		return out
+	}
+	emb, lex := clusters[0], clusters[1]
+	if got := ids(emb); !slices.Equal(got, []int64{a, b}) {
+		t.Errorf("first cluster = %v, want [%d %d]", got, a, b)
+	}
+	if len(emb.Pairs) != 1 || emb.Pairs[0].Method != memstore.DedupeEmbedding || emb.Similarity < 0.99 {
+		t.Errorf("embedding cluster pairs = %+v, similarity %v", emb.Pairs, emb.Similarity)
+	}
+	if emb.Facts[0].ID != b || emb.Facts[0].Content != "api port is 8230" || emb.Facts[0].Embedding != nil {
+		t.Errorf("embedding cluster facts = %+v, want newest first without embeddings", emb.Facts)
+	}
+	if got := ids(lex); !slices.Equal(got, []int64{e1, e2}) {
+		t.Errorf("second cluster = %v, want [%d %d]", got, e1, e2)
+	}
+	if len(lex.Pairs) != 1 || lex.Pairs[0].Method != memstore.DedupeLexical {
+		t.Errorf("lexical cluster pairs = %+v", lex.Pairs)
+	}
+
+	if clusters, err := d.FindDuplicates(ctx, memstore.DedupeOpts{Threshold: 0.8, Limit: 1}); err != nil || len(clusters) != 1 {
+		t.Errorf("FindDuplicates(limit 1) = %d clusters, %v; want 1", len(clusters), err)
+	}
+	if clusters, err := d.FindDuplicates(ctx, memstore.DedupeOpts{Threshold: 0.999, LexicalThreshold: 0.999}); err != nil || len(clusters) != 0 {
+		t.Errorf("FindDuplicates(threshold 0.999) = %+v, %v; want none", clusters, err)
+	}
+}
+
+// testFindDuplicatesParaphrases checks that the FTS fallback, at default
+// options, pairs unembedded facts that state one claim in different words
+// and leaves facts that only share a topic alone.
+func testFindDuplicatesParaphrases(t *testing.T, s memstore.Store) {
+	t.Helper()
+	d, ok := s.(memstore.Deduper)
+	if !ok {
+		t.Skip("store does not implement memstore.Deduper")
+	}
+	ctx := context.Background()
+
+	insert := func(content, subject string) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: subject, Category: "note"})
+		if err != nil {
+			t.Fatalf("Insert %q: %v", content, err)
+		}
+		return id
+	}
+	deploy1 := insert("Deploys to production require approval from two reviewers", "process")
+	deploy2 := insert("Production deploys need approval from two reviewers", "release")
+	insert("Production logs are kept for thirty days", "ops")
+	backup1 := insert("The nightly backup job writes snapshots to the archive bucket", "infra")
+	backup2 := insert("Nightly backup job writes its snapshots into the archive bucket", "backups")
+	insert("The archive bucket is read-only for the web tier", "infra")
+
+	clusters, err := d.FindDuplicates(ctx, memstore.DedupeOpts{})
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+	var got [][]int64
+	for _, c := range clusters {
+		var ids []int64
+		for _, f := range c.Facts {
+			ids = append(ids, f.ID)
+		}
+		slices.Sort(ids)
+		got = append(got, ids)
+		if c.Pairs[0].Method != memstore.DedupeLexical || c.Similarity >= memstore.DefaultDedupeThreshold {
+			t.Errorf("cluster %v: pairs %+v; want a lexical pair below the embedding threshold", ids, c.Pairs)
+		}
+	}
+	slices.SortFunc(got, func(a, b []int64) int { return cmp.Compare(a[0], b[0]) })
+	want := [][]int64{{deploy1, deploy2}, {backup1, backup2}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("FindDuplicates at defaults = %v, want %v", got, want)
+	}
+}
+
+// conflictGenerator judges any pair whose prompt mentions both "capped" and
+// "unlimited" to conflict.
+type conflictGenerator struct{}
+
+func (conflictGenerator) Model() string { return "conformance" }
+
+func (g conflictGenerator) Generate(ctx context.Context, prompt string) (string, error) {
+	return g.GenerateJSONSchema(ctx, prompt, "", nil)
+}
+
+func (conflictGenerator) GenerateJSONSchema(_ context.Context, prompt, _ string, _ any) (string, error) {
+	if strings.Contains(prompt, "capped") && strings.Contains(prompt, "unlimited") {
+		return `{"conflict": true, "reason": "capped vs unlimited"}`, nil
+	}
+	return `{"conflict": false, "reason": ""}`, nil
+}
+
+func testContradictionAudit(t *testing.T, s memstore.Store) {
+	t.Helper()
+	as, ok := s.(memstore.ContradictionAuditStore)
+	if !ok {
+		t.Skip("store does not implement memstore.ContradictionAuditStore")
+	}
+	cq, ok := s.(memstore.ContradictionQueue)
+	if !ok {
+		t.Skip("store does not implement memstore.ContradictionQueue")
+	}
+	ctx := context.Background()
+
+	insert := func(content, subject string, emb []float32) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: subject, Category: "note"})
+		if err != nil {
+			t.Fatalf("Insert %q: %v", content, err)
+		}
+		if emb != nil {
+			if err := s.SetEmbedding(ctx, id, emb); err != nil {
+				t.Fatalf("SetEmbedding %d: %v", id, err)
+			}
+		}
+		return id
+	}
+	capped := insert("retries are capped at 3", "retries", []float32{1, 0, 0, 0})
+	unlimited := insert("retries are unlimited", "retries", []float32{0.95, 0.1, 0, 0})
+	insert("retries use jittered backoff", "retries", []float32{0, 1, 0, 0}) // not close enough
+	insert("uploads are unlimited", "uploads", []float32{1, 0, 0, 0})        // other subject
+	pending := insert("retries are capped, awaiting an embedding", "retries", nil)
+
+	auditor := memstore.NewContradictionAuditor(as, conflictGenerator{}, memstore.ContradictionPolicy{Enabled: true}, nil)
+	res, err := auditor.AuditOnce(ctx)
+	if err != nil {
+		t.Fatalf("AuditOnce: %v", err)
+	}
+	if len(res.Audited) != 4 || res.Compared != 1 || len(res.Links) != 1 {
+		t.Fatalf("AuditOnce = %+v; want 4 audited, 1 pair compared, 1 link", res)
+	}
+	if slices.Contains(res.Audited, pending) {
+		t.Errorf("unembedded fact %d was audited", pending)
+	}
+	if res, err := auditor.AuditOnce(ctx); err != nil || len(res.Audited) != 0 {
+		t.Errorf("second pass = %+v, %v; want nothing pending", res, err)
+	}
+
+	// Repeated failures take a fact out of the pending set.
+	withEmbedding := insert("retries are capped per host", "hosts", []float32{0, 0, 1, 0})
+	for i, want := range []bool{false, true} {
+		skipped, err := as.RecordAuditFailure(ctx, withEmbedding, 2)
+		if err != nil || skipped != want {
+			t.Fatalf("RecordAuditFailure #%d = %v, %v; want %v", i+1, skipped, err, want)
+		}
+	}
+	if left, err := as.PendingAudit(ctx, 0); err != nil || len(left) != 0 {
+		t.Errorf("PendingAudit after skipping = %+v, %v; want none", left, err)
+	}
+
+	queue, err := cq.Contradictions(ctx, 0)
+	if err != nil {
+		t.Fatalf("Contradictions: %v", err)
+	}
+	if len(queue) != 1 || queue[0].A.ID != capped || queue[0].B.ID != unlimited || queue[0].Reason != "capped vs unlimited" {
+		t.Fatalf("Contradictions = %+v; want capped vs unlimited", queue)
+	}
+	if err := memstore.DismissContradiction(ctx, s, queue[0].LinkID); err != nil {
+		t.Fatalf("DismissContradiction: %v", err)
+	}
+	if queue, err := cq.Contradictions(ctx, 0); err != nil || len(queue) != 0 {
+		t.Errorf("Contradictions after dismissal = %+v, %v; want empty", queue, err)
+	}
+}
+
+func testReviewQueue(t *testing.T, s memstore.Store) {
+	t.Helper()
+	rv, ok := s.(memstore.Reviewer)
+	if !ok {
+		t.Skip("store does not implement memstore.Reviewer")
+	}
+	ctx := context.Background()
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	insert := func(f memstore.Fact) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, f)
+		if err != nil {
+			t.Fatalf("Insert %q: %v", f.Content, err)
+		}
+		return id
+	}
+	plain := insert(memstore.Fact{Content: "the CI runner is named gort", Subject: "ci", Category: "project", CreatedAt: old})
+	rule := insert(memstore.Fact{Content: "builds use make", Subject: "ci", Category: "project", Kind: "invariant", CreatedAt: old})
+	insert(memstore.Fact{Content: "fresh fact", Subject: "ci", Category: "project"})
+	confirmed := insert(memstore.Fact{Content: "tabs not spaces", Subject: "style", Category: "project", CreatedAt: old})
+	replaced := insert(memstore.Fact{Content: "deploys on friday", Subject: "ci", Category: "project", CreatedAt: old})
+	if err := s.Supersede(ctx, replaced, plain); err != nil {
+		t.Fatalf("Supersede: %v", err)
+	}
+
+	items, err := rv.ReviewQueue(ctx, memstore.ReviewOpts{})
+	if err != nil {
+		t.Fatalf("ReviewQueue: %v", err)
+	}
+	var ids []int64
+	for _, it := range items {
+		ids = append(ids, it.Fact.ID)
+	}
+	if !slices.Equal(ids, []int64{rule, plain, confirmed}) {
+		t.Fatalf("ReviewQueue = %v, want [%d %d %d] (invariant first, no fresh or superseded facts)", ids, rule, plain, confirmed)
+	}
+
+	if err := s.Confirm(ctx, confirmed); err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	items, err = rv.ReviewQueue(ctx, memstore.ReviewOpts{Limit: 1})
+	if err != nil || len(items) != 1 || items[0].Fact.ID != rule {
+		t.Errorf("ReviewQueue(limit 1) after confirm = %+v, %v; want only %d", items, err, rule)
+	}
+	if items, err := rv.ReviewQueue(ctx, memstore.ReviewOpts{Subject: "style"}); err != nil || len(items) != 0 {
+		t.Errorf("ReviewQueue(subject style) = %+v, %v; want the confirmed fact gone", items, err)
+	}
+}
+
+func testTags(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	insert := func(content string, tags ...string) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: "tags", Category: "note", Tags: tags})
+		if err != nil {
+			t.Fatalf("Insert %q: %v", content, err)
+		}
+		return id
+	}
+	tagsOf := func(id int64) []string {
+		t.Helper()
+		f, err := s.Get(ctx, id)
+		if err != nil || f == nil {
+			t.Fatalf("Get(%d) = %+v, %v", id, f, err)
+		}
+		return f.Tags
+	}
+	listIDs := func(tf memstore.TagFilter) []int64 {
+		t.Helper()
+		facts, err := s.List(ctx, memstore.QueryOpts{Subject: "tags", OnlyActive: true, Tags: tf})
+		if err != nil {
+			t.Fatalf("List(%+v): %v", tf, err)
+		}
+		ids := make([]int64, len(facts))
+		for i, f := range facts {
+			ids[i] = f.ID
+		}
+		return ids
+	}
+
+	perf := insert("the poller backs off exponentially", "Perf", " perf ", "network")
+	sec := insert("tokens are hashed at rest", "security")
+	both := insert("tls handshakes are cached", "security", "perf")
+	if _, err := s.Insert(ctx, memstore.Fact{Content: "bad", Subject: "tags", Category: "note", Tags: []string{"two words"}}); err == nil {
+		t.Error("Insert accepted a tag containing whitespace")
+	}
+
+	if got := tagsOf(perf); !slices.Equal(got, []string{"network", "perf"}) {
+		t.Errorf("tags = %v, want normalized, deduplicated and sorted [network perf]", got)
+	}
+	if got := listIDs(memstore.TagFilter{Any: []string{"PERF"}}); !slices.Equal(got, []int64{perf, both}) {
+		t.Errorf("any perf = %v, want [%d %d]", got, perf, both)
+	}
+	if got := listIDs(memstore.TagFilter{All: []string{"perf", "security"}}); !slices.Equal(got, []int64{both}) {
+		t.Errorf("all perf+security = %v, want [%d]", got, both)
+	}
+	if got := listIDs(memstore.TagFilter{None: []string{"perf"}}); !slices.Equal(got, []int64{sec}) {
+		t.Errorf("none perf = %v, want [%d]", got, sec)
+	}
+	results, err := s.SearchFTS(ctx, "cached", memstore.SearchOpts{Tags: memstore.TagFilter{All: []string{"security", "perf"}}})
+	if err != nil {
+		t.Fatalf("SearchFTS: %v", err)
+	}
+	if len(results) != 1 || results[0].Fact.ID != both || len(results[0].Fact.Tags) != 2 {
+		t.Errorf("SearchFTS with tags = %+v, want fact %d with its tags", results, both)
+	}
+
+	if err := s.TagFact(ctx, sec, "audit"); err != nil {
+		t.Fatalf("TagFact: %v", err)
+	}
+	if err := s.UntagFact(ctx, sec, "security", "absent"); err != nil {
+		t.Fatalf("UntagFact: %v", err)
+	}
+	if got := tagsOf(sec); !slices.Equal(got, []string{"audit"}) {
+		t.Errorf("tags after tag/untag = %v, want [audit]", got)
+	}
+	if err := s.TagFact(ctx, 999999, "audit"); err == nil {
+		t.Error("TagFact on a missing fact should fail")
+	}
+
+	revised, err := s.Revise(ctx, perf, "the poller backs off exponentially, capped at 5m", nil)
+	if err != nil {
+		t.Fatalf("Revise: %v", err)
+	}
+	if got := tagsOf(revised); !slices.Equal(got, []string{"network", "perf"}) {
+		t.Errorf("revised tags = %v, want them carried over", got)
+	}
+	merged, err := s.Merge(ctx, []int64{sec, both}, "security notes", nil)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if got := tagsOf(merged); !slices.Equal(got, []string{"audit", "perf", "security"}) {
+		t.Errorf("merged tags = %v, want the union", got)
+	}
+
+	// Only active facts count: the revised and merged originals drop out.
+	counts, err := s.TagCounts(ctx, "tags")
+	if err != nil {
+		t.Fatalf("TagCounts: %v", err)
+	}
+	want := []memstore.TagCount{{Tag: "perf", Count: 2}, {Tag: "audit", Count: 1}, {Tag: "network", Count: 1}, {Tag: "security", Count: 1}}
+	if !slices.Equal(counts, want) {
+		t.Errorf("TagCounts = %+v, want %+v", counts, want)
+	}
+
+	// Renaming onto a tag the fact already has merges the two.
+	if err := s.TagFact(ctx, revised, "performance"); err != nil {
+		t.Fatalf("TagFact: %v", err)
+	}
+	if _, err := s.RenameTag(ctx, "perf", "performance"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if got := tagsOf(revised); !slices.Equal(got, []string{"network", "performance"}) {
+		t.Errorf("tags after rename = %v, want [network performance]", got)
+	}
+	if got := tagsOf(merged); !slices.Equal(got, []string{"audit", "performance", "security"}) {
+		t.Errorf("merged tags after rename = %v", got)
+	}
+	n, err := s.DeleteTag(ctx, "performance")
+	if err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if got := listIDs(memstore.TagFilter{Any: []string{"performance"}}); len(got) != 0 || n < 2 {
+		t.Errorf("after DeleteTag (%d removed) facts still tagged: %v", n, got)
+	}
+}
+
+func testProvenance(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	manual, err := s.Insert(ctx, memstore.Fact{Content: "plain write", Subject: "prov", Category: "note"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if f, _ := s.Get(ctx, manual); f == nil || f.Provenance != (memstore.Provenance{Source: memstore.SourceManual}) {
+		t.Errorf("unattributed insert provenance = %+v, want source manual only", f)
+	}
+
+	extracted := memstore.Provenance{
+		Source:    memstore.SourceExtraction,
+		SessionID: "sess-1",
+		TurnFirst: "turn-a",
+		TurnLast:  "turn-f",
+		Origin:    "laptop",
+	}
+	jobCtx := memstore.WithProvenance(ctx, extracted)
+	// The context's origin is authoritative; a fact cannot assert its own.
+	id, err := s.Insert(jobCtx, memstore.Fact{Content: "extracted fact", Subject: "prov", Category: "note",
+		Provenance: memstore.Provenance{Origin: "forged"}})
+	if err != nil {
+		t.Fatalf("Insert under provenance: %v", err)
+	}
+	f, err := s.Get(ctx, id)
+	if err != nil || f == nil || f.Provenance != extracted {
+		t.Fatalf("extracted fact provenance = %+v, %v; want %+v", f, err, extracted)
+	}
+	summary, err := s.Insert(jobCtx, memstore.Fact{Content: "session summary", Subject: "prov", Category: "note",
+		Provenance: memstore.Provenance{Source: memstore.SourceSummary}})
+	if err != nil {
+		t.Fatalf("Insert summary: %v", err)
+	}
+	if _, err := s.Insert(ctx, memstore.Fact{Content: "bad", Subject: "prov", Category: "note",
+		Provenance: memstore.Provenance{Source: "rumour"}}); err == nil {
+		t.Error("Insert accepted an unknown provenance source")
+	}
+
+	list := func(pf memstore.ProvenanceFilter) []int64 {
+		t.Helper()
+		facts, err := s.List(ctx, memstore.QueryOpts{Subject: "prov", Provenance: pf})
+		if err != nil {
+			t.Fatalf("List(%+v): %v", pf, err)
+		}
+		var ids []int64
+		for _, f := range facts {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+	if got := list(memstore.ProvenanceFilter{SessionID: "sess-1"}); !slices.Equal(got, []int64{id, summary}) {
+		t.Errorf("List(session sess-1) = %v, want [%d %d]", got, id, summary)
+	}
+	if got := list(memstore.ProvenanceFilter{Source: memstore.SourceExtraction, Origin: "laptop"}); !slices.Equal(got, []int64{id}) {
+		t.Errorf("List(extraction by laptop) = %v, want [%d]", got, id)
+	}
+	if got := list(memstore.ProvenanceFilter{Source: memstore.SourceManual}); !slices.Equal(got, []int64{manual}) {
+		t.Errorf("List(manual) = %v, want [%d]", got, manual)
+	}
+
+	// A revision is a new write with its own provenance; the superseded
+	// version keeps the one it was stored with.
+	revised, err := s.Revise(memstore.WithProvenance(ctx, memstore.Provenance{Origin: "desk"}), id, "extracted fact, corrected", nil)
+	if err != nil {
+		t.Fatalf("Revise: %v", err)
+	}
+	if f, _ := s.Get(ctx, revised); f == nil || f.Provenance != (memstore.Provenance{Source: memstore.SourceManual, Origin: "desk"}) {
+		t.Errorf("revision provenance = %+v, want manual by desk", f)
+	}
+	if err := s.UpdateMetadata(ctx, id, map[string]any{"note": "touched"}); err != nil {
+		t.Fatalf("UpdateMetadata: %v", err)
+	}
+	if f, _ := s.Get(ctx, id); f == nil || f.Provenance != extracted {
+		t.Errorf("superseded version provenance = %+v, want it unchanged", f)
+	}
+}
+
+func testMetadataSchemas(t *testing.T, s memstore.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	isSchemaErr := func(err error) bool {
+		var se *memstore.SchemaError
+		return errors.As(err, &se)
+	}
+	trigger := func(meta string) memstore.Fact {
+		return memstore.Fact{Content: "load frontend rules " + meta, Subject: "schemas", Category: "project",
+			Kind: "trigger", Metadata: json.RawMessage(meta)}
+	}
+
+	// A trigger that names a signal must be able to fire.
+	_, err := s.Insert(ctx, trigger(`{"signal_type":"cwd","signal":"**/web/**","load_subsystem":"frontend"}`))
+	if !isSchemaErr(err) {
+		t.Errorf("Insert with unknown signal_type: err = %v, want *SchemaError", err)
+	}
+	if _, err := s.Insert(ctx, trigger(`{"signal_type":"cwd_pattern","load_subsystem":"frontend"}`)); !isSchemaErr(err) {
+		t.Errorf("Insert without signal: err = %v, want *SchemaError", err)
+	}
+	if err := s.InsertBatch(ctx, []memstore.Fact{
+		{Content: "fine", Subject: "schemas", Category: "note"},
+		trigger(`{"signal":"**/web/**"}`),
+	}); !isSchemaErr(err) {
+		t.Errorf("InsertBatch with a malformed trigger: err = %v, want *SchemaError", err)
+	}
+	if facts, _ := s.List(ctx, memstore.QueryOpts{Subject: "schemas"}); len(facts) != 0 {
+		t.Fatalf("rejected writes stored %d facts", len(facts))
+	}
+
+	id, err := s.Insert(ctx, trigger(`{"signal_type":"cwd_pattern","signal":"**/web/**","load_subsystem":"frontend"}`))
+	if err != nil {
+		t.Fatalf("Insert valid trigger: %v", err)
+	}
+	// Content-only triggers and kinds without a schema are unconstrained.
+	if _, err := s.Insert(ctx, memstore.Fact{Content: "when touching auth, use PKCE", Subject: "schemas", Category: "note", Kind: "trigger"}); err != nil {
+		t.Errorf("Insert trigger without metadata: %v", err)
+	}
+	if _, err := s.Insert(ctx, memstore.Fact{Content: "custom", Subject: "schemas", Category: "note", Kind: "runbook",
+		Metadata: json.RawMessage(`{"anything":[1,2]}`)}); err != nil {
+		t.Errorf("Insert unregistered kind: %v", err)
+	}
+
+	err = s.UpdateMetadata(ctx, id, map[string]any{"signal_type": "glob"})
+	var se *memstore.SchemaError
+	if !errors.As(err, &se) || se.FactID != id || se.Kind != "trigger" || !strings.Contains(se.Reason, "signal_type") {
+		t.Errorf("UpdateMetadata breaking the trigger: err = %v, want *SchemaError naming fact %d and signal_type", err, id)
+	}
+	if f, _ := s.Get(ctx, id); f == nil || !strings.Contains(string(f.Metadata), `"cwd_pattern"`) {
+		t.Errorf("rejected UpdateMetadata changed the fact: %+v", f)
+	}
+	if _, err := s.Revise(ctx, id, "load frontend rules, revised", map[string]any{"load_subsystem": nil}); !isSchemaErr(err) {
+		t.Errorf("Revise dropping the load target: err = %v, want *SchemaError", err)
+	}
+	if _, err := s.Revise(ctx, id, "load frontend rules, revised", nil); err != nil {
+		t.Errorf("Revise without a metadata patch: %v", err)
+	}
+
+	if bu, ok := s.(memstore.BulkUpdater); ok {
+		task := "task"
+		_, err := bu.BulkUpdate(ctx, memstore.BulkUpdateRequest{
+			Filter: memstore.QueryOpts{Subject: "schemas", Kind: "runbook"},
+			Change: memstore.BulkChange{Kind: &task},
+		})
+		if !isSchemaErr(err) {
+			t.Errorf("BulkUpdate to a kind whose schema the metadata fails: err = %v, want *SchemaError", err)
+		}
+	}
+}
+
 func testNamespaceIsolation(t *testing.T, newStoreNS func(*testing.T, string) memstore.Store) {
 	t.Helper()
 	ctx := context.Background()
@@ -889,8 +1854,15 @@ func testMutationsIsolated(t *testing.T, a, b memstore.Store) {
 	check("Touch", func(x int64) error { return b.Touch(ctx, []int64{x}) })
 	check("UpdateMetadata", func(x int64) error { return b.UpdateMetadata(ctx, x, map[string]any{"k": "hacked"}) })
 	check("Supersede", func(x int64) error { return b.Supersede(ctx, x, x) })
+	check("Revise", func(x int64) error { _, err := b.Revise(ctx, x, "hacked", nil); return err })
+	bOwn, err := b.Insert(ctx, memstore.Fact{Content: "merge bait", Subject: "iso-mut", Category: "test"})
+	if err != nil {
+		t.Fatalf("Insert B: %v", err)
+	}
+	check("Merge", func(x int64) error { _, err := b.Merge(ctx, []int64{bOwn, x}, "hacked", nil); return err })
 	check("SetEmbedding", func(x int64) error { return b.SetEmbedding(ctx, x, []float32{0.1, 0.2, 0.3, 0.4}) })
 	check("MarkEmbedFailed", func(x int64) error { return b.MarkEmbedFailed(ctx, x, "isolation probe") })
+	check("Restore", func(x int64) error { return b.Restore(ctx, x) })
 	check("Delete", func(x int64) error { return b.Delete(ctx, x) })
 
 	after, err := a.Get(ctx, id)
@@ -0,0 +1,215 @@
+package eval_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matthewjhunter/memstore/internal/eval"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranked   []int64
+		relevant map[int64]float64
+		k        int
+		want     eval.Metrics
+	}{
+		{"perfect", []int64{1, 2}, map[int64]float64{1: 1, 2: 1}, 5, eval.Metrics{RecallAtK: 1, MRR: 1, NDCG: 1}},
+		{"no hits", []int64{3, 4}, map[int64]float64{1: 1}, 5, eval.Metrics{}},
+		{"empty relevant", []int64{1}, nil, 5, eval.Metrics{}},
+		{"second place", []int64{3, 1}, map[int64]float64{1: 1}, 5, eval.Metrics{RecallAtK: 1, MRR: 0.5, NDCG: 1 / math.Log2(3)}},
+		{"cut off by k", []int64{3, 4, 1}, map[int64]float64{1: 1}, 2, eval.Metrics{}},
+		{
+			// Grade-2 at rank 2, grade-1 at rank 1: DCG = 1 + 3/log2(3);
+			// ideal = 3 + 1/log2(3).
+			"graded order", []int64{2, 1}, map[int64]float64{1: 2, 2: 1}, 5,
+			eval.Metrics{RecallAtK: 1, MRR: 1, NDCG: (1 + 3/math.Log2(3)) / (3 + 1/math.Log2(3))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eval.Score(tt.ranked, tt.relevant, tt.k)
+			if !approx(got.RecallAtK, tt.want.RecallAtK) || !approx(got.MRR, tt.want.MRR) || !approx(got.NDCG, tt.want.NDCG) {
+				t.Errorf("Score = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoldenSetValidate(t *testing.T) {
+	fixture := []eval.Fixture{{Key: "a", Subject: "s", Content: "c"}}
+	tests := []struct {
+		name    string
+		set     eval.GoldenSet
+		wantErr string
+	}{
+		{"no queries", eval.GoldenSet{Fixtures: fixture}, "no queries"},
+		{"duplicate fixture", eval.GoldenSet{
+			Fixtures: append(fixture, fixture[0]),
+			Queries:  []eval.GoldenQuery{{ID: "q", Query: "x", Expect: []eval.Expectation{{Fixture: "a"}}}},
+		}, "duplicate key"},
+		{"unknown fixture", eval.GoldenSet{
+			Fixtures: fixture,
+			Queries:  []eval.GoldenQuery{{ID: "q", Query: "x", Expect: []eval.Expectation{{Fixture: "b"}}}},
+		}, "unknown fixture"},
+		{"two targets", eval.GoldenSet{
+			Fixtures: fixture,
+			Queries:  []eval.GoldenQuery{{ID: "q", Query: "x", Expect: []eval.Expectation{{Fixture: "a", ID: 3}}}},
+		}, "exactly one"},
+		{"no expectations", eval.GoldenSet{
+			Queries: []eval.GoldenQuery{{ID: "q", Query: "x"}},
+		}, "no expectations"},
+		{"bad rerank mode", eval.GoldenSet{
+			Queries: []eval.GoldenQuery{{ID: "q", Query: "x", Expect: []eval.Expectation{{ID: 1}}}},
+			Configs: []eval.Config{{Name: "c", Search: eval.SearchParams{RerankMode: "bogus"}}},
+		}, "unknown rerank mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.set.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func runTestdata(t *testing.T) *eval.Report {
+	t.Helper()
+	ctx := context.Background()
+	set, err := eval.LoadGoldenSet("testdata/golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := eval.NewFixtureStore(ctx, set.Fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	report, err := eval.Run(ctx, fs.Target(), set, set.Configs, eval.Options{K: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestRunFixtureDeterministic(t *testing.T) {
+	a := runTestdata(t)
+	b := runTestdata(t)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("two runs over the same fixture set produced different reports")
+	}
+
+	// 3 configs x 2 pipelines, pipeline-major.
+	if len(a.Runs) != 6 {
+		t.Fatalf("runs = %d, want 6", len(a.Runs))
+	}
+	if a.Runs[0].Pipeline != eval.PipelineSearch || a.Runs[3].Pipeline != eval.PipelineRecall {
+		t.Errorf("runs not pipeline-major: %s, %s", a.Runs[0].Pipeline, a.Runs[3].Pipeline)
+	}
+	for _, run := range []eval.RunResult{a.Runs[0], a.Runs[3]} {
+		if run.Mean.MRR == 0 {
+			t.Errorf("%s/%s: baseline MRR is zero; the fixture pipeline finds nothing", run.Pipeline, run.Config)
+		}
+		if len(run.Queries) != 6 {
+			t.Errorf("%s/%s: %d query results, want 6", run.Pipeline, run.Config, len(run.Queries))
+		}
+	}
+}
+
+func TestRunRerankNeedsReranker(t *testing.T) {
+	ctx := context.Background()
+	set, err := eval.LoadGoldenSet("testdata/golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := eval.NewFixtureStore(ctx, set.Fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	target := fs.Target()
+	target.Reranker = nil
+
+	for _, p := range []eval.Pipeline{eval.PipelineSearch, eval.PipelineRecall} {
+		configs := []eval.Config{{
+			Name:   "rr",
+			Search: eval.SearchParams{RerankMode: "gate"},
+			Recall: eval.RecallParams{RerankMode: "gate"},
+		}}
+		_, err := eval.Run(ctx, target, set, configs, eval.Options{Pipelines: []eval.Pipeline{p}})
+		if err == nil || !strings.Contains(err.Error(), "needs a reranker") {
+			t.Errorf("%s: err = %v, want a missing-reranker error", p, err)
+		}
+	}
+}
+
+func TestRunUnmatchedContains(t *testing.T) {
+	ctx := context.Background()
+	set, err := eval.LoadGoldenSet("testdata/golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := eval.NewFixtureStore(ctx, set.Fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	set.Queries = []eval.GoldenQuery{{ID: "q", Query: "anything", Expect: []eval.Expectation{{Contains: "no such text anywhere"}}}}
+	if _, err := eval.Run(ctx, fs.Target(), set, nil, eval.Options{}); err == nil {
+		t.Error("expected an error for a contains expectation that matches no fact")
+	}
+}
+
+func TestReportDiffsAndOutput(t *testing.T) {
+	r := &eval.Report{K: 5, Runs: []eval.RunResult{
+		{Config: "base", Pipeline: eval.PipelineSearch, Queries: []eval.QueryResult{
+			{ID: "q1", Ranked: []int64{1}, Metrics: eval.Metrics{RecallAtK: 1, MRR: 1, NDCG: 1}},
+			{ID: "q2", Ranked: []int64{3}, Missing: []int64{2}},
+		}},
+		{Config: "alt", Pipeline: eval.PipelineSearch, Queries: []eval.QueryResult{
+			{ID: "q1", Ranked: []int64{1}, Metrics: eval.Metrics{RecallAtK: 1, MRR: 1, NDCG: 1}},
+			{ID: "q2", Ranked: []int64{2}, Metrics: eval.Metrics{RecallAtK: 1, MRR: 1, NDCG: 1}},
+		}},
+	}}
+
+	diffs := r.Diffs()
+	if len(diffs) != 1 || diffs[0].QueryID != "q2" || diffs[0].Config != "alt" || diffs[0].Baseline != "base" {
+		t.Fatalf("Diffs = %+v, want one q2 alt-vs-base diff", diffs)
+	}
+
+	var text bytes.Buffer
+	if err := r.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"RECALL@5", "alt vs base  q2", "missing [2]"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := r.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		K     int              `json:"k"`
+		Runs  []eval.RunResult `json:"runs"`
+		Diffs []eval.QueryDiff `json:"diffs"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.K != 5 || len(decoded.Runs) != 2 || len(decoded.Diffs) != 1 {
+		t.Errorf("JSON round-trip = k %d, %d runs, %d diffs", decoded.K, len(decoded.Runs), len(decoded.Diffs))
+	}
+}
@@ -0,0 +1,181 @@
+package eval
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/matthewjhunter/go-embedding"
+	"github.com/matthewjhunter/memstore"
+	_ "modernc.org/sqlite"
+)
+
+// HashEmbedder is a deterministic, model-free embedder: each lowercase word
+// is hashed into one of Dim signed buckets and the vector is L2-normalized.
+// Cosine similarity is then bag-of-words overlap -- crude next to a real
+// model, but stable across machines and runs, which is what a CI gate needs.
+type HashEmbedder struct {
+	Dim int
+}
+
+// NewHashEmbedder returns a HashEmbedder of the given dimension (64 if <= 0).
+func NewHashEmbedder(dim int) *HashEmbedder {
+	if dim <= 0 {
+		dim = 64
+	}
+	return &HashEmbedder{Dim: dim}
+}
+
+// Embed implements embedding.Embedder.
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		v := make([]float32, e.Dim)
+		for _, w := range words(t) {
+			h := fnv.New32a()
+			h.Write([]byte(w))
+			sum := h.Sum32()
+			sign := float32(1)
+			if sum&1 == 1 {
+				sign = -1
+			}
+			v[int(sum>>1)%e.Dim] += sign
+		}
+		var norm float64
+		for _, x := range v {
+			norm += float64(x) * float64(x)
+		}
+		if norm > 0 {
+			n := float32(math.Sqrt(norm))
+			for j := range v {
+				v[j] /= n
+			}
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Model implements embedding.Embedder.
+func (e *HashEmbedder) Model() string { return "eval-hash" }
+
+// Fingerprint implements embedding.Embedder.
+func (e *HashEmbedder) Fingerprint() embedding.Fingerprint {
+	return embedding.Fingerprint{Model: e.Model(), Dim: e.Dim}
+}
+
+// LexicalReranker is a deterministic, model-free reranker: a document's score
+// is the fraction of distinct query words it contains, already in [0,1] as a
+// normalizing cross-encoder's would be. It lets rerank modes be evaluated in
+// CI; its judgments are a stand-in, not a prediction of a real model's.
+type LexicalReranker struct{}
+
+// Rerank implements embedding.Reranker.
+func (LexicalReranker) Rerank(_ context.Context, req embedding.RerankRequest) ([]embedding.RerankResult, error) {
+	query := make(map[string]bool)
+	for _, w := range words(req.Query) {
+		query[w] = true
+	}
+	out := make([]embedding.RerankResult, len(req.Documents))
+	for i, d := range req.Documents {
+		if req.MaxDocumentBytes > 0 && len(d) > req.MaxDocumentBytes {
+			d = d[:req.MaxDocumentBytes]
+		}
+		hit := make(map[string]bool)
+		for _, w := range words(d) {
+			if query[w] {
+				hit[w] = true
+			}
+		}
+		score := 0.0
+		if len(query) > 0 {
+			score = float64(len(hit)) / float64(len(query))
+		}
+		out[i] = embedding.RerankResult{Index: i, Score: score}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if req.TopN > 0 && req.TopN < len(out) {
+		out = out[:req.TopN]
+	}
+	return out, nil
+}
+
+// Model implements embedding.Reranker.
+func (LexicalReranker) Model() string { return "eval-lexical" }
+
+// words lowercases s and splits it into letter/digit runs.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// FixtureStore is an in-memory SQLite store loaded with a golden set's
+// fixtures, embedded by a HashEmbedder and reranked by a LexicalReranker.
+type FixtureStore struct {
+	Store    *memstore.SQLiteStore
+	Embedder *HashEmbedder
+	Reranker LexicalReranker
+	IDs      map[string]int64 // fixture key -> fact ID
+
+	db *sql.DB
+}
+
+// NewFixtureStore builds a fresh fixture store from fixtures. Fixtures are
+// inserted in order, so IDs are stable for a given set.
+func NewFixtureStore(ctx context.Context, fixtures []Fixture) (*FixtureStore, error) {
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("eval: golden set has no fixtures to build a fixture store from")
+	}
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("eval: open fixture db: %w", err)
+	}
+	// One connection: every new connection to :memory: is a new, empty DB.
+	db.SetMaxOpenConns(1)
+
+	emb := NewHashEmbedder(64)
+	store, err := memstore.NewSQLiteStore(db, emb, "eval")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("eval: fixture store: %w", err)
+	}
+	fs := &FixtureStore{Store: store, Embedder: emb, IDs: make(map[string]int64, len(fixtures)), db: db}
+	store.SetReranker(fs.Reranker)
+
+	for _, f := range fixtures {
+		id, err := store.Insert(ctx, memstore.Fact{
+			Subject:   f.Subject,
+			Content:   f.Content,
+			Category:  f.Category,
+			Kind:      f.Kind,
+			Subsystem: f.Subsystem,
+			Metadata:  f.Metadata,
+		})
+		if err != nil {
+			fs.Close()
+			return nil, fmt.Errorf("eval: insert fixture %q: %w", f.Key, err)
+		}
+		fs.IDs[f.Key] = id
+	}
+	if _, err := store.EmbedFacts(ctx, 0); err != nil {
+		fs.Close()
+		return nil, fmt.Errorf("eval: embed fixtures: %w", err)
+	}
+	return fs, nil
+}
+
+// Target returns the fixture store as an evaluation target.
+func (fs *FixtureStore) Target() Target {
+	return Target{Store: fs.Store, Embedder: fs.Embedder, Reranker: fs.Reranker, FixtureIDs: fs.IDs}
+}
+
+// Close releases the in-memory database.
+func (fs *FixtureStore) Close() error {
+	return fs.db.Close()
+}
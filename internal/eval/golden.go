@@ -0,0 +1,208 @@
+// Package eval is the offline retrieval evaluation harness behind
+// `memstore eval`. It runs a golden query set through Store.Search and the
+// /v1/recall pipeline under one or more parameter configurations and scores
+// the rankings with recall@k, MRR, and nDCG, so tuning the fusion weights,
+// recall thresholds, and rerank modes is a measured change rather than a
+// guess.
+//
+// A golden set can carry its own fixture corpus. Run against a fixture store
+// (NewFixtureStore), which uses a deterministic hashing embedder and a lexical
+// reranker, the harness needs no models and produces identical numbers on
+// every machine, so it can gate CI.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// GoldenSet is the on-disk evaluation input: an optional fixture corpus, the
+// queries with their expected results, and optional parameter configurations.
+type GoldenSet struct {
+	Fixtures []Fixture     `json:"fixtures,omitempty"`
+	Queries  []GoldenQuery `json:"queries"`
+	Configs  []Config      `json:"configs,omitempty"`
+}
+
+// Fixture is one fact of a golden set's corpus. Key names it so queries can
+// expect it without knowing the ID the store will assign.
+type Fixture struct {
+	Key       string          `json:"key"`
+	Subject   string          `json:"subject"`
+	Content   string          `json:"content"`
+	Category  string          `json:"category,omitempty"`
+	Kind      string          `json:"kind,omitempty"`
+	Subsystem string          `json:"subsystem,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+}
+
+// GoldenQuery is one evaluation query and the facts a good ranking returns.
+type GoldenQuery struct {
+	ID     string        `json:"id"`
+	Query  string        `json:"query"`
+	CWD    string        `json:"cwd,omitempty"` // recall only: the session's working directory
+	Expect []Expectation `json:"expect"`
+}
+
+// Expectation identifies relevant facts by exactly one of ID (a live store),
+// Fixture (a fixture key), or Contains (a case-insensitive substring of the
+// content; every active fact containing it is relevant). Grade is the graded
+// relevance used by nDCG; 0 means 1.
+type Expectation struct {
+	ID       int64   `json:"id,omitempty"`
+	Fixture  string  `json:"fixture,omitempty"`
+	Contains string  `json:"contains,omitempty"`
+	Grade    float64 `json:"grade,omitempty"`
+}
+
+// Config is one named parameter configuration. Search fields follow
+// memstore.SearchOpts (zero = the store's default); recall fields are
+// overrides on httpapi.DefaultRecallTuning, so nil leaves the daemon default.
+type Config struct {
+	Name   string       `json:"name"`
+	Search SearchParams `json:"search,omitempty"`
+	Recall RecallParams `json:"recall,omitempty"`
+}
+
+// SearchParams are the Store.Search knobs a configuration may set.
+type SearchParams struct {
+	FTSWeight        float64 `json:"fts_weight,omitempty"`
+	VecWeight        float64 `json:"vec_weight,omitempty"`
+	RerankMode       string  `json:"rerank_mode,omitempty"`
+	RerankWeight     float64 `json:"rerank_weight,omitempty"`
+	RerankThreshold  float64 `json:"rerank_threshold,omitempty"`
+	RerankCandidates int     `json:"rerank_candidates,omitempty"`
+}
+
+// RecallParams are the /v1/recall knobs a configuration may set.
+type RecallParams struct {
+	MinScoreRatio       *float64 `json:"min_score_ratio,omitempty"`
+	MinAbsoluteScore    *float64 `json:"min_absolute_score,omitempty"`
+	VecBoostWeight      *float64 `json:"vec_boost_weight,omitempty"`
+	VecOnlyWeight       *float64 `json:"vec_only_weight,omitempty"`
+	ProjectSurfaceBoost *float64 `json:"project_surface_boost,omitempty"`
+	RerankMode          string   `json:"rerank_mode,omitempty"`
+	RerankThreshold     float64  `json:"rerank_threshold,omitempty"`
+	Budget              int      `json:"budget,omitempty"` // formatted-context char budget; 0 = the daemon default
+}
+
+// DefaultConfigs is used when neither the golden set nor the caller supplies
+// configurations: the built-in defaults alone.
+func DefaultConfigs() []Config {
+	return []Config{{Name: "default"}}
+}
+
+// LoadGoldenSet reads and validates a golden set from a JSON file.
+func LoadGoldenSet(path string) (*GoldenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("eval: %w", err)
+	}
+	var set GoldenSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("eval: parse %s: %w", path, err)
+	}
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("eval: %s: %w", path, err)
+	}
+	return &set, nil
+}
+
+// LoadConfigs reads a JSON array of configurations from a file.
+func LoadConfigs(path string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("eval: %w", err)
+	}
+	var configs []Config
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("eval: parse %s: %w", path, err)
+	}
+	if err := validateConfigs(configs); err != nil {
+		return nil, fmt.Errorf("eval: %s: %w", path, err)
+	}
+	return configs, nil
+}
+
+// Validate checks the set is internally consistent: unique fixture keys and
+// query IDs, every query with at least one expectation, every expectation
+// naming exactly one target, and every fixture reference resolvable.
+func (g *GoldenSet) Validate() error {
+	keys := make(map[string]bool, len(g.Fixtures))
+	for i, f := range g.Fixtures {
+		if f.Key == "" {
+			return fmt.Errorf("fixture %d: key is required", i)
+		}
+		if keys[f.Key] {
+			return fmt.Errorf("fixture %q: duplicate key", f.Key)
+		}
+		if f.Subject == "" || f.Content == "" {
+			return fmt.Errorf("fixture %q: subject and content are required", f.Key)
+		}
+		keys[f.Key] = true
+	}
+	if len(g.Queries) == 0 {
+		return fmt.Errorf("no queries")
+	}
+	ids := make(map[string]bool, len(g.Queries))
+	for i, q := range g.Queries {
+		if q.ID == "" {
+			return fmt.Errorf("query %d: id is required", i)
+		}
+		if ids[q.ID] {
+			return fmt.Errorf("query %q: duplicate id", q.ID)
+		}
+		ids[q.ID] = true
+		if q.Query == "" {
+			return fmt.Errorf("query %q: query text is required", q.ID)
+		}
+		if len(q.Expect) == 0 {
+			return fmt.Errorf("query %q: no expectations", q.ID)
+		}
+		for _, e := range q.Expect {
+			n := 0
+			if e.ID != 0 {
+				n++
+			}
+			if e.Fixture != "" {
+				n++
+				if !keys[e.Fixture] {
+					return fmt.Errorf("query %q: unknown fixture %q", q.ID, e.Fixture)
+				}
+			}
+			if e.Contains != "" {
+				n++
+			}
+			if n != 1 {
+				return fmt.Errorf("query %q: each expectation needs exactly one of id, fixture, contains", q.ID)
+			}
+			if e.Grade < 0 {
+				return fmt.Errorf("query %q: negative grade", q.ID)
+			}
+		}
+	}
+	return validateConfigs(g.Configs)
+}
+
+func validateConfigs(configs []Config) error {
+	names := make(map[string]bool, len(configs))
+	for i, c := range configs {
+		if c.Name == "" {
+			return fmt.Errorf("config %d: name is required", i)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("config %q: duplicate name", c.Name)
+		}
+		names[c.Name] = true
+		if _, err := memstore.ParseRerankMode(c.Search.RerankMode); err != nil {
+			return fmt.Errorf("config %q: search: %w", c.Name, err)
+		}
+		if _, err := memstore.ParseRerankMode(c.Recall.RerankMode); err != nil {
+			return fmt.Errorf("config %q: recall: %w", c.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"math"
+	"sort"
+)
+
+// Metrics are the per-query (or averaged) ranking scores, all in [0,1].
+type Metrics struct {
+	RecallAtK float64 `json:"recall_at_k"`
+	MRR       float64 `json:"mrr"`
+	NDCG      float64 `json:"ndcg"`
+}
+
+// Score computes recall@k, reciprocal rank, and nDCG@k for one ranking.
+// ranked is the result order (only the first k count); relevant maps each
+// relevant fact ID to its grade. An empty relevant set scores zero.
+func Score(ranked []int64, relevant map[int64]float64, k int) Metrics {
+	if len(relevant) == 0 {
+		return Metrics{}
+	}
+	if k > 0 && len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	var m Metrics
+	found := 0
+	dcg := 0.0
+	for i, id := range ranked {
+		g, ok := relevant[id]
+		if !ok {
+			continue
+		}
+		found++
+		if m.MRR == 0 {
+			m.MRR = 1 / float64(i+1)
+		}
+		dcg += gain(g) / math.Log2(float64(i+2))
+	}
+	m.RecallAtK = float64(found) / float64(len(relevant))
+
+	// Ideal DCG: the k highest grades in the best order.
+	grades := make([]float64, 0, len(relevant))
+	for _, g := range relevant {
+		grades = append(grades, g)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(grades)))
+	if k > 0 && len(grades) > k {
+		grades = grades[:k]
+	}
+	idcg := 0.0
+	for i, g := range grades {
+		idcg += gain(g) / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		m.NDCG = dcg / idcg
+	}
+	return m
+}
+
+// gain is the standard exponential nDCG gain, so a grade-2 hit outweighs two
+// grade-1 hits at the same position.
+func gain(grade float64) float64 {
+	return math.Pow(2, grade) - 1
+}
+
+// mean averages per-query metrics; zero queries average to zero.
+func mean(ms []Metrics) Metrics {
+	var out Metrics
+	if len(ms) == 0 {
+		return out
+	}
+	for _, m := range ms {
+		out.RecallAtK += m.RecallAtK
+		out.MRR += m.MRR
+		out.NDCG += m.NDCG
+	}
+	n := float64(len(ms))
+	out.RecallAtK /= n
+	out.MRR /= n
+	out.NDCG /= n
+	return out
+}
@@ -0,0 +1,129 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// QueryDiff is one query whose score changed between a pipeline's baseline
+// configuration and another configuration.
+type QueryDiff struct {
+	Pipeline Pipeline `json:"pipeline"`
+	Config   string   `json:"config"`
+	Baseline string   `json:"baseline"`
+	QueryID  string   `json:"query_id"`
+	Before   Metrics  `json:"before"`
+	After    Metrics  `json:"after"`
+	RankedB  []int64  `json:"ranked_before"`
+	RankedA  []int64  `json:"ranked_after"`
+}
+
+// Diffs returns every query whose nDCG, MRR, or recall@k differs from the
+// baseline (the first configuration run on the same pipeline), in run then
+// query order.
+func (r *Report) Diffs() []QueryDiff {
+	baseline := make(map[Pipeline]*RunResult)
+	var out []QueryDiff
+	for i := range r.Runs {
+		run := &r.Runs[i]
+		base, ok := baseline[run.Pipeline]
+		if !ok {
+			baseline[run.Pipeline] = run
+			continue
+		}
+		for j, q := range run.Queries {
+			b := base.Queries[j]
+			if q.Metrics == b.Metrics {
+				continue
+			}
+			out = append(out, QueryDiff{
+				Pipeline: run.Pipeline,
+				Config:   run.Config,
+				Baseline: base.Config,
+				QueryID:  q.ID,
+				Before:   b.Metrics,
+				After:    q.Metrics,
+				RankedB:  b.Ranked,
+				RankedA:  q.Ranked,
+			})
+		}
+	}
+	return out
+}
+
+// WriteJSON writes the report and its diffs as one indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Report
+		Diffs []QueryDiff `json:"diffs"`
+	}{r, r.Diffs()})
+}
+
+// WriteText writes a summary table (one row per configuration and pipeline),
+// then the per-query diffs against each pipeline's baseline, then the queries
+// that missed relevant facts under the baseline.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "PIPELINE\tCONFIG\tRECALL@%d\tMRR\tNDCG@%d\n", r.K, r.K)
+	for _, run := range r.Runs {
+		fmt.Fprintf(tw, "%s\t%s\t%.3f\t%.3f\t%.3f\n",
+			run.Pipeline, run.Config, run.Mean.RecallAtK, run.Mean.MRR, run.Mean.NDCG)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if diffs := r.Diffs(); len(diffs) > 0 {
+		fmt.Fprintf(w, "\nPer-query changes vs. baseline:\n")
+		for _, d := range diffs {
+			fmt.Fprintf(w, "  [%s] %s vs %s  %s: ndcg %.3f -> %.3f (%+.3f), mrr %.3f -> %.3f, recall %.3f -> %.3f\n",
+				d.Pipeline, d.Config, d.Baseline, d.QueryID,
+				d.Before.NDCG, d.After.NDCG, d.After.NDCG-d.Before.NDCG,
+				d.Before.MRR, d.After.MRR,
+				d.Before.RecallAtK, d.After.RecallAtK)
+			fmt.Fprintf(w, "      before %s\n      after  %s\n", formatIDs(d.RankedB), formatIDs(d.RankedA))
+		}
+	}
+
+	seen := make(map[Pipeline]bool)
+	header := false
+	for _, run := range r.Runs {
+		if seen[run.Pipeline] {
+			continue
+		}
+		seen[run.Pipeline] = true
+		for _, q := range run.Queries {
+			if len(q.Missing) == 0 {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(w, "\nMisses under baseline:\n")
+				header = true
+			}
+			fmt.Fprintf(w, "  [%s] %s  %s: missing %s, got %s\n",
+				run.Pipeline, run.Config, q.ID, formatIDs(q.Missing), formatIDs(q.Ranked))
+		}
+	}
+	return nil
+}
+
+func formatIDs(ids []int64) string {
+	if len(ids) == 0 {
+		return "[]"
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprint(id)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+func sortInt64s(s []int64) {
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+}
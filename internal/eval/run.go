@@ -0,0 +1,334 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/matthewjhunter/go-embedding"
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/httpapi"
+)
+
+// Pipeline names a retrieval path the harness can evaluate.
+type Pipeline string
+
+const (
+	// PipelineSearch is Store.Search: FTS + vector fusion, plus rerank when
+	// the configuration enables it.
+	PipelineSearch Pipeline = "search"
+	// PipelineRecall is the /v1/recall hook pipeline, served in-process by a
+	// real httpapi.Handler over the target store.
+	PipelineRecall Pipeline = "recall"
+)
+
+// ParsePipelines parses a comma-separated pipeline list. Empty means both.
+func ParsePipelines(s string) ([]Pipeline, error) {
+	if strings.TrimSpace(s) == "" {
+		return []Pipeline{PipelineSearch, PipelineRecall}, nil
+	}
+	var out []Pipeline
+	for _, p := range strings.Split(s, ",") {
+		switch Pipeline(strings.TrimSpace(p)) {
+		case PipelineSearch:
+			out = append(out, PipelineSearch)
+		case PipelineRecall:
+			out = append(out, PipelineRecall)
+		default:
+			return nil, fmt.Errorf("eval: unknown pipeline %q (want search, recall)", p)
+		}
+	}
+	return out, nil
+}
+
+// Target is the store under evaluation and what the pipelines need around
+// it. Embedder nil runs Search as FTS-only and recall without its vector
+// pass, as a daemon without an embedder would. Reranker nil makes any
+// configuration that enables rerank an error rather than a silent no-op.
+// For search rerank the store itself must carry the same reranker.
+type Target struct {
+	Store      memstore.Store
+	Embedder   embedding.Embedder
+	Reranker   embedding.Reranker
+	FixtureIDs map[string]int64 // fixture key -> fact ID; nil outside fixture mode
+}
+
+// Options controls a run.
+type Options struct {
+	K         int        // ranking cutoff; 0 = 5
+	Pipelines []Pipeline // empty = both
+}
+
+// QueryResult is one query's outcome under one configuration and pipeline.
+type QueryResult struct {
+	ID      string  `json:"id"`
+	Query   string  `json:"query"`
+	Ranked  []int64 `json:"ranked"`
+	Missing []int64 `json:"missing,omitempty"` // relevant facts absent from the top k
+	Metrics
+}
+
+// RunResult is one (configuration, pipeline) pass over the whole set.
+type RunResult struct {
+	Config   string        `json:"config"`
+	Pipeline Pipeline      `json:"pipeline"`
+	Mean     Metrics       `json:"mean"`
+	Queries  []QueryResult `json:"queries"`
+}
+
+// Report is the full evaluation output. Runs are ordered pipeline-major in
+// configuration order, so the first run of each pipeline is its baseline.
+type Report struct {
+	K    int         `json:"k"`
+	Runs []RunResult `json:"runs"`
+}
+
+// Run evaluates every configuration on every pipeline. Expectations are
+// resolved to fact IDs once, up front; an expectation that matches no fact
+// is an error, because a query that cannot succeed measures nothing.
+func Run(ctx context.Context, t Target, set *GoldenSet, configs []Config, opts Options) (*Report, error) {
+	if opts.K <= 0 {
+		opts.K = 5
+	}
+	if len(opts.Pipelines) == 0 {
+		opts.Pipelines = []Pipeline{PipelineSearch, PipelineRecall}
+	}
+	if len(configs) == 0 {
+		configs = DefaultConfigs()
+	}
+	relevant, err := resolveExpectations(ctx, t, set.Queries)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{K: opts.K}
+	for _, p := range opts.Pipelines {
+		for _, c := range configs {
+			run, err := runConfig(ctx, t, set.Queries, relevant, c, p, opts.K)
+			if err != nil {
+				return nil, fmt.Errorf("eval: config %q, %s: %w", c.Name, p, err)
+			}
+			report.Runs = append(report.Runs, run)
+		}
+	}
+	return report, nil
+}
+
+func runConfig(ctx context.Context, t Target, queries []GoldenQuery, relevant []map[int64]float64, c Config, p Pipeline, k int) (RunResult, error) {
+	var rank func(GoldenQuery) ([]int64, error)
+	switch p {
+	case PipelineSearch:
+		opts, err := searchOpts(c.Search, k, t.Reranker != nil)
+		if err != nil {
+			return RunResult{}, err
+		}
+		rank = func(q GoldenQuery) ([]int64, error) { return searchRanking(ctx, t, q.Query, opts) }
+	case PipelineRecall:
+		h, err := recallHandler(t, c.Recall)
+		if err != nil {
+			return RunResult{}, err
+		}
+		rank = func(q GoldenQuery) ([]int64, error) { return recallRanking(h, q, k, c.Recall.Budget) }
+	default:
+		return RunResult{}, fmt.Errorf("unknown pipeline %q", p)
+	}
+
+	run := RunResult{Config: c.Name, Pipeline: p}
+	var all []Metrics
+	for i, q := range queries {
+		ranked, err := rank(q)
+		if err != nil {
+			return RunResult{}, fmt.Errorf("query %q: %w", q.ID, err)
+		}
+		m := Score(ranked, relevant[i], k)
+		run.Queries = append(run.Queries, QueryResult{
+			ID:      q.ID,
+			Query:   q.Query,
+			Ranked:  ranked,
+			Missing: missing(ranked, relevant[i], k),
+			Metrics: m,
+		})
+		all = append(all, m)
+	}
+	run.Mean = mean(all)
+	return run, nil
+}
+
+func searchOpts(p SearchParams, k int, haveReranker bool) (memstore.SearchOpts, error) {
+	mode, err := memstore.ParseRerankMode(p.RerankMode)
+	if err != nil {
+		return memstore.SearchOpts{}, err
+	}
+	if mode.Enabled() && !haveReranker {
+		return memstore.SearchOpts{}, fmt.Errorf("rerank_mode %q needs a reranker and none is configured", mode)
+	}
+	return memstore.SearchOpts{
+		MaxResults:       k,
+		OnlyActive:       true,
+		FTSWeight:        p.FTSWeight,
+		VecWeight:        p.VecWeight,
+		RerankMode:       mode,
+		RerankWeight:     p.RerankWeight,
+		RerankThreshold:  p.RerankThreshold,
+		RerankCandidates: p.RerankCandidates,
+	}, nil
+}
+
+func searchRanking(ctx context.Context, t Target, query string, opts memstore.SearchOpts) ([]int64, error) {
+	var results []memstore.SearchResult
+	var err error
+	if t.Embedder != nil {
+		results, err = t.Store.Search(ctx, query, opts)
+	} else {
+		results, err = t.Store.SearchFTS(ctx, query, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(results))
+	for i, r := range results {
+		ids[i] = r.Fact.ID
+	}
+	return ids, nil
+}
+
+// recallHandler builds a daemon handler for one configuration. There is no
+// session store or session context, so nothing is recorded and no
+// seen-this-session filtering applies: each query is a fresh session.
+func recallHandler(t Target, p RecallParams) (http.Handler, error) {
+	tuning := httpapi.DefaultRecallTuning()
+	for _, o := range []struct {
+		src *float64
+		dst *float64
+	}{
+		{p.MinScoreRatio, &tuning.MinScoreRatio},
+		{p.MinAbsoluteScore, &tuning.MinAbsoluteScore},
+		{p.VecBoostWeight, &tuning.VecBoostWeight},
+		{p.VecOnlyWeight, &tuning.VecOnlyWeight},
+		{p.ProjectSurfaceBoost, &tuning.ProjectSurfaceBoost},
+	} {
+		if o.src != nil {
+			*o.dst = *o.src
+		}
+	}
+	opts := []httpapi.HandlerOpt{httpapi.WithRecallTuning(tuning)}
+
+	mode, err := memstore.ParseRerankMode(p.RerankMode)
+	if err != nil {
+		return nil, err
+	}
+	if mode.Enabled() {
+		if t.Reranker == nil {
+			return nil, fmt.Errorf("rerank_mode %q needs a reranker and none is configured", mode)
+		}
+		opts = append(opts, httpapi.WithReranker(t.Reranker, memstore.RerankPolicy{Mode: mode, Threshold: p.RerankThreshold}))
+	}
+	return httpapi.New(t.Store, t.Embedder, "", opts...), nil
+}
+
+func recallRanking(h http.Handler, q GoldenQuery, k, budget int) ([]int64, error) {
+	body, _ := json.Marshal(map[string]any{
+		"prompt": q.Query,
+		"cwd":    q.CWD,
+		"limit":  k,
+		"budget": budget,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/recall", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return nil, fmt.Errorf("recall: status %d: %s", w.Code, strings.TrimSpace(w.Body.String()))
+	}
+	var resp struct {
+		Facts []struct {
+			ID int64 `json:"id"`
+		} `json:"facts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("recall: decode: %w", err)
+	}
+	ids := make([]int64, len(resp.Facts))
+	for i, f := range resp.Facts {
+		ids[i] = f.ID
+	}
+	return ids, nil
+}
+
+// resolveExpectations maps each query's expectations to graded fact IDs.
+// Contains expectations scan the active facts once, shared by every query.
+func resolveExpectations(ctx context.Context, t Target, queries []GoldenQuery) ([]map[int64]float64, error) {
+	var active []memstore.Fact
+	for _, q := range queries {
+		for _, e := range q.Expect {
+			if e.Contains != "" && active == nil {
+				facts, err := t.Store.List(ctx, memstore.QueryOpts{OnlyActive: true})
+				if err != nil {
+					return nil, fmt.Errorf("eval: list facts: %w", err)
+				}
+				active = facts
+			}
+		}
+	}
+
+	out := make([]map[int64]float64, len(queries))
+	for i, q := range queries {
+		rel := make(map[int64]float64)
+		for _, e := range q.Expect {
+			grade := e.Grade
+			if grade == 0 {
+				grade = 1
+			}
+			var ids []int64
+			switch {
+			case e.ID != 0:
+				ids = []int64{e.ID}
+			case e.Fixture != "":
+				id, ok := t.FixtureIDs[e.Fixture]
+				if !ok {
+					return nil, fmt.Errorf("eval: query %q: fixture %q is not loaded (fixture expectations need a fixture store)", q.ID, e.Fixture)
+				}
+				ids = []int64{id}
+			case e.Contains != "":
+				needle := strings.ToLower(e.Contains)
+				for _, f := range active {
+					if strings.Contains(strings.ToLower(f.Content), needle) {
+						ids = append(ids, f.ID)
+					}
+				}
+				if len(ids) == 0 {
+					return nil, fmt.Errorf("eval: query %q: no active fact contains %q", q.ID, e.Contains)
+				}
+			}
+			for _, id := range ids {
+				if grade > rel[id] {
+					rel[id] = grade
+				}
+			}
+		}
+		out[i] = rel
+	}
+	return out, nil
+}
+
+func missing(ranked []int64, relevant map[int64]float64, k int) []int64 {
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	in := make(map[int64]bool, len(ranked))
+	for _, id := range ranked {
+		in[id] = true
+	}
+	var out []int64
+	for id := range relevant {
+		if !in[id] {
+			out = append(out, id)
+		}
+	}
+	sortInt64s(out)
+	return out
+}
@@ -7,6 +7,7 @@ package mcpserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -200,6 +201,7 @@ type FactResult struct {
 	UseCount       int      `json:"use_count"`
 	ConfirmedCount int      `json:"confirmed_count"`
 	SupersededBy   *int64   `json:"superseded_by,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
 	Metadata       Metadata `json:"metadata,omitempty"`
 }
 
@@ -212,6 +214,7 @@ type ListResult struct {
 type GetContextResult struct {
 	Task         string       `json:"task"`
 	Subject      string       `json:"subject,omitempty"`
+	Pinned       []FactResult `json:"pinned,omitempty"`
 	Invariants   []FactResult `json:"invariants"`
 	FailureModes []FactResult `json:"failure_modes"`
 	Triggers     []FactResult `json:"triggers"`
@@ -291,12 +294,31 @@ type BatchResult struct {
 	Error      string `json:"error,omitempty"`
 }
 
-// DeleteResult is the structured output for memory_delete.
+// DeleteResult is the structured output for memory_delete and memory_restore.
 type DeleteResult struct {
 	Status string `json:"status"`
 	ID     int64  `json:"id"`
 }
 
+// TrashResult is the structured output for memory_trash.
+type TrashResult struct {
+	Facts []TrashedFact `json:"facts"`
+}
+
+// TrashedFact represents a single fact in the trash.
+type TrashedFact struct {
+	ID        int64  `json:"id"`
+	Subject   string `json:"subject"`
+	Category  string `json:"category"`
+	Content   string `json:"content"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+// PurgeResult is the structured output for memory_purge.
+type PurgeResult struct {
+	Purged int64 `json:"purged"`
+}
+
 // SupersedeResult is the structured output for memory_supersede.
 type SupersedeResult struct {
 	Status     string `json:"status"`
@@ -306,6 +328,29 @@ type SupersedeResult struct {
 	NewContent string `json:"new_content"`
 }
 
+// MergeResult is the structured output for memory_merge.
+type MergeResult struct {
+	Status  string       `json:"status"` // "merged" or "dry_run"
+	ID      int64        `json:"id,omitempty"`
+	Content string       `json:"content"`
+	Drafted bool         `json:"drafted,omitempty"` // content was written by the generator
+	Sources []FactResult `json:"sources"`
+}
+
+// ContradictionsResult is the structured output for memory_contradictions.
+type ContradictionsResult struct {
+	Dismissed      []int64             `json:"dismissed,omitempty"`
+	Contradictions []ContradictionItem `json:"contradictions"`
+}
+
+// ContradictionItem is one open entry in the contradiction review queue.
+type ContradictionItem struct {
+	LinkID int64      `json:"link_id"`
+	Reason string     `json:"reason"`
+	A      FactResult `json:"a"`
+	B      FactResult `json:"b"`
+}
+
 // HistoryResult is the structured output for memory_history.
 type HistoryResult struct {
 	Entries []HistoryEntry `json:"entries"`
@@ -324,6 +369,74 @@ type HistoryEntry struct {
 	Metadata       Metadata `json:"metadata,omitempty"`
 	UseCount       int      `json:"use_count"`
 	ConfirmedCount int      `json:"confirmed_count"`
+
+	Provenance *memstore.Provenance `json:"provenance,omitempty"` // nil for facts stored before provenance was tracked
+}
+
+// ReviewQueueResult is the structured output for memory_review_queue.
+type ReviewQueueResult struct {
+	Items []ReviewQueueItem `json:"items"`
+}
+
+// ReviewQueueItem is one fact due for re-confirmation.
+type ReviewQueueItem struct {
+	FactResult
+	Priority float64  `json:"priority"`
+	Since    string   `json:"since"` // last confirmation, or creation if never confirmed
+	Reasons  []string `json:"reasons"`
+
+	StaleCitations int `json:"stale_citations,omitempty"` // citations whose code changed or vanished
+}
+
+// CiteResult is the structured output for memory_cite.
+type CiteResult struct {
+	Status   string            `json:"status"`
+	Citation memstore.Citation `json:"citation"`
+}
+
+// CitedCodeResult is the structured output for memory_cited_code.
+type CitedCodeResult struct {
+	Fact      FactResult          `json:"fact"`
+	Accepted  []int64             `json:"accepted,omitempty"`
+	Removed   []int64             `json:"removed,omitempty"`
+	Citations []memstore.Citation `json:"citations"`
+}
+
+// BulkUpdateResult is the structured output for memory_bulk_update.
+type BulkUpdateResult struct {
+	Matched int     `json:"matched"`
+	IDs     []int64 `json:"ids"`
+	Token   string  `json:"token"`
+	Applied bool    `json:"applied"`
+	AuditID int64   `json:"audit_id,omitempty"`
+}
+
+// AliasResult is the structured output for memory_alias and memory_unalias.
+type AliasResult struct {
+	Status    string `json:"status"`
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical,omitempty"`
+}
+
+// ListAliasesResult is the structured output for memory_list_aliases.
+type ListAliasesResult struct {
+	Aliases []memstore.SubjectAlias `json:"aliases"`
+}
+
+// RewriteAliasesResult is the structured output for memory_rewrite_aliases.
+type RewriteAliasesResult struct {
+	Facts    int               `json:"facts"`
+	Subjects map[string]string `json:"subjects,omitempty"`
+	AuditID  int64             `json:"audit_id,omitempty"`
+}
+
+// PinResult is the structured output for memory_pin and memory_unpin.
+type PinResult struct {
+	Status      string `json:"status"`
+	FactID      int64  `json:"fact_id"`
+	Subject     string `json:"subject,omitempty"`
+	ProjectPath string `json:"project_path,omitempty"`
+	Position    int    `json:"position,omitempty"`
 }
 
 // ConfirmResult is the structured output for memory_confirm.
@@ -340,6 +453,9 @@ type StatusResult struct {
 	Categories  map[string]int `json:"categories,omitempty"`
 	Kinds       map[string]int `json:"kinds,omitempty"`
 	Subjects    map[string]int `json:"subjects,omitempty"`
+
+	StaleCitedFacts int `json:"stale_cited_facts,omitempty"` // active facts whose cited code changed or vanished
+	StaleCitations  int `json:"stale_citations,omitempty"`
 }
 
 // UpdateResult is the structured output for memory_update.
@@ -358,25 +474,43 @@ type TaskCreateResult struct {
 
 // TaskUpdateResult is the structured output for memory_task_update.
 type TaskUpdateResult struct {
-	Status    string `json:"status"`
-	ID        int64  `json:"id"`
-	OldStatus string `json:"old_status,omitempty"`
-	NewStatus string `json:"new_status"`
+	Status    string       `json:"status"`
+	ID        int64        `json:"id"`
+	OldStatus string       `json:"old_status,omitempty"`
+	NewStatus string       `json:"new_status,omitempty"`
+	Unblocked []TaskResult `json:"unblocked,omitempty"` // tasks this update left with no open blocker
+	Next      *TaskResult  `json:"next,omitempty"`      // next occurrence of a completed recurring task
 }
 
 // TaskListResult is the structured output for memory_task_list.
 type TaskListResult struct {
+	Tasks  []TaskResult      `json:"tasks"`
+	Groups []TaskGroupResult `json:"groups,omitempty"` // set when group_by is given
+}
+
+// TaskGroupResult is one column of a grouped memory_task_list view.
+type TaskGroupResult struct {
+	Name  string       `json:"name"`
 	Tasks []TaskResult `json:"tasks"`
 }
 
 // TaskResult represents a single task fact.
 type TaskResult struct {
-	ID       int64  `json:"id"`
-	Status   string `json:"status"`
-	Scope    string `json:"scope"`
-	Priority string `json:"priority"`
-	Content  string `json:"content"`
-	Due      string `json:"due,omitempty"`
+	ID        int64   `json:"id"`
+	Status    string  `json:"status"`
+	Scope     string  `json:"scope"`
+	Priority  string  `json:"priority"`
+	Content   string  `json:"content"`
+	Due       string  `json:"due,omitempty"`
+	Project   string  `json:"project,omitempty"`
+	Owner     string  `json:"owner,omitempty"`
+	State     string  `json:"state,omitempty"`
+	Overdue   bool    `json:"overdue,omitempty"`
+	Recur     string  `json:"recur,omitempty"`
+	RemindAt  string  `json:"remind_at,omitempty"`
+	DueSoon   bool    `json:"due_soon,omitempty"`
+	BlockedBy []int64 `json:"blocked_by,omitempty"`
+	Blocks    []int64 `json:"blocks,omitempty"`
 }
 
 // LinkResult is the structured output for memory_link.
@@ -429,6 +563,33 @@ type StoreInput struct {
 	Subsystem  string   `json:"subsystem,omitempty" jsonschema:"optional project subsystem this fact belongs to (e.g. feeds, auth, storage)"`
 	Metadata   Metadata `json:"metadata,omitempty" jsonschema:"optional key-value metadata to attach"`
 	Supersedes *int64   `json:"supersedes,omitempty" jsonschema:"ID of an existing fact that this new fact replaces (preserves history unlike delete)"`
+	TTL        string   `json:"ttl,omitempty" jsonschema:"optional time-to-live after which the fact stops being active, e.g. 3d, 2w, or 36h"`
+	ExpiresAt  string   `json:"expires_at,omitempty" jsonschema:"optional absolute expiry time (RFC3339); mutually exclusive with ttl"`
+	Tags       []string `json:"tags,omitempty" jsonschema:"optional labels to attach (lower-cased; no spaces or commas), e.g. [\"perf\", \"todo\"]"`
+}
+
+// expiry resolves the input's ttl or expires_at into an expiry time; nil
+// when neither is set.
+func (in StoreInput) expiry() (*time.Time, error) {
+	ttl, at := strings.TrimSpace(in.TTL), strings.TrimSpace(in.ExpiresAt)
+	switch {
+	case ttl != "" && at != "":
+		return nil, fmt.Errorf("ttl and expires_at are mutually exclusive")
+	case ttl != "":
+		d, err := memstore.ParseTTL(ttl)
+		if err != nil {
+			return nil, err
+		}
+		t := time.Now().UTC().Add(d)
+		return &t, nil
+	case at != "":
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at %q: want RFC3339", at)
+		}
+		return &t, nil
+	}
+	return nil, nil
 }
 
 // StoreBatchInput is the input schema for the memory_store_batch tool.
@@ -448,6 +609,9 @@ type SearchInput struct {
 	Metadata          Metadata `json:"metadata,omitempty" jsonschema:"filter by metadata fields (equality match, e.g. {\"source\": \"conversation\"})"`
 	RerankMode        string   `json:"rerank_mode,omitempty" jsonschema:"override the server's rerank mode for this call: off|balanced|dominant|gate (empty = server default)"`
 	Threshold         *float64 `json:"threshold,omitempty" jsonschema:"override the relevance threshold [0,1] for this call; facts scoring below it are dropped (omit = server default)"`
+	Tags              []string `json:"tags,omitempty" jsonschema:"only facts carrying at least one of these tags"`
+	TagsAll           []string `json:"tags_all,omitempty" jsonschema:"only facts carrying every one of these tags"`
+	TagsNone          []string `json:"tags_none,omitempty" jsonschema:"exclude facts carrying any of these tags"`
 }
 
 // ListInput is the input schema for the memory_list tool.
@@ -458,6 +622,11 @@ type ListInput struct {
 	Subsystem string   `json:"subsystem,omitempty" jsonschema:"filter by subsystem (e.g. feeds, auth)"`
 	Limit     int      `json:"limit,omitempty" jsonschema:"maximum number of results (default 20)"`
 	Metadata  Metadata `json:"metadata,omitempty" jsonschema:"filter by metadata fields (equality match, e.g. {\"source\": \"conversation\"})"`
+	Source    string   `json:"source,omitempty" jsonschema:"filter by provenance source: manual, extraction, import or summary"`
+	SessionID string   `json:"session_id,omitempty" jsonschema:"filter to facts that originated in this session"`
+	Tags      []string `json:"tags,omitempty" jsonschema:"only facts carrying at least one of these tags"`
+	TagsAll   []string `json:"tags_all,omitempty" jsonschema:"only facts carrying every one of these tags"`
+	TagsNone  []string `json:"tags_none,omitempty" jsonschema:"exclude facts carrying any of these tags"`
 }
 
 // ListSubsystemsInput is the input schema for the memory_list_subsystems tool.
@@ -476,6 +645,7 @@ type CurateContextInput struct {
 type GetContextInput struct {
 	Task       string   `json:"task" jsonschema:"description of the task or feature being worked on"`
 	Subject    string   `json:"subject,omitempty" jsonschema:"optional subject to scope context loading (e.g. a project name)"`
+	CWD        string   `json:"cwd,omitempty" jsonschema:"working directory of the session; selects facts pinned to the project path"`
 	Limit      int      `json:"limit,omitempty" jsonschema:"max total facts in the relevant context section (default 20)"`
 	RerankMode string   `json:"rerank_mode,omitempty" jsonschema:"override the server's rerank mode for this call: off|balanced|dominant|gate (empty = server default)"`
 	Threshold  *float64 `json:"threshold,omitempty" jsonschema:"override the relevance threshold [0,1] for this call (omit = server default)"`
@@ -498,18 +668,151 @@ type DeleteInput struct {
 	ID int64 `json:"id" jsonschema:"the fact ID to delete"`
 }
 
+// TrashInput is the input schema for the memory_trash tool.
+type TrashInput struct {
+	Subject string `json:"subject,omitempty" jsonschema:"filter by subject entity"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"maximum number of results (default 20)"`
+}
+
+// RestoreInput is the input schema for the memory_restore tool.
+type RestoreInput struct {
+	ID int64 `json:"id" jsonschema:"the trashed fact ID to restore"`
+}
+
+// PurgeInput is the input schema for the memory_purge tool.
+type PurgeInput struct {
+	OlderThan string `json:"older_than,omitempty" jsonschema:"purge facts trashed longer ago than this, e.g. 7d, 2w, 36h"`
+	All       bool   `json:"all,omitempty" jsonschema:"purge the whole trash regardless of age (instead of older_than)"`
+}
+
 // SupersedeInput is the input schema for the memory_supersede tool.
 type SupersedeInput struct {
 	OldID int64 `json:"old_id" jsonschema:"ID of the fact being replaced"`
 	NewID int64 `json:"new_id" jsonschema:"ID of the fact that replaces it"`
 }
 
+// ReviseInput is the input schema for the memory_revise tool.
+type ReviseInput struct {
+	ID       int64    `json:"id" jsonschema:"ID of the fact to revise"`
+	Content  string   `json:"content" jsonschema:"the corrected content"`
+	Metadata Metadata `json:"metadata,omitempty" jsonschema:"metadata keys to set (non-nil) or delete (nil) on the new version"`
+}
+
+// MergeInput is the input schema for the memory_merge tool.
+type MergeInput struct {
+	IDs      []int64  `json:"ids" jsonschema:"IDs of the active facts to merge (at least two); the first is the template for subject, category, kind, subsystem, and metadata"`
+	Content  string   `json:"content,omitempty" jsonschema:"the consolidated content; omit to have the configured LLM draft it"`
+	Metadata Metadata `json:"metadata,omitempty" jsonschema:"metadata keys to set (non-nil) or delete (nil) on the merged fact"`
+	DryRun   bool     `json:"dry_run,omitempty" jsonschema:"preview the sources and the (drafted) content without changing anything"`
+}
+
+// ContradictionsInput is the input schema for the memory_contradictions tool.
+type ContradictionsInput struct {
+	Limit   int     `json:"limit,omitempty" jsonschema:"maximum number of queue entries (default 20)"`
+	Dismiss []int64 `json:"dismiss,omitempty" jsonschema:"link IDs of entries reviewed and found not to conflict; they leave the queue for good"`
+}
+
 // HistoryInput is the input schema for the memory_history tool.
 type HistoryInput struct {
 	ID      int64  `json:"id,omitempty" jsonschema:"fact ID to show the supersession chain for"`
 	Subject string `json:"subject,omitempty" jsonschema:"subject to show all facts for (including superseded)"`
 }
 
+// ReviewQueueInput is the input schema for the memory_review_queue tool.
+type ReviewQueueInput struct {
+	Limit   int    `json:"limit,omitempty" jsonschema:"batch size (default 10)"`
+	MinAge  string `json:"min_age,omitempty" jsonschema:"only facts unconfirmed for at least this long, e.g. 30d, 2w, 720h (default 30d)"`
+	Subject string `json:"subject,omitempty" jsonschema:"restrict to one subject"`
+}
+
+// CiteInput is the input schema for the memory_cite tool.
+type CiteInput struct {
+	FactID  int64 `json:"fact_id" jsonschema:"the fact that was drawn from the code"`
+	ChunkID int64 `json:"chunk_id" jsonschema:"the document chunk ID, from a document search hit"`
+}
+
+// CitedCodeInput is the input schema for the memory_cited_code tool.
+type CitedCodeInput struct {
+	ID     int64   `json:"id" jsonschema:"the fact ID"`
+	Accept []int64 `json:"accept,omitempty" jsonschema:"citation IDs whose changed code you checked the fact against and it still holds; they are re-anchored to the current code"`
+	Remove []int64 `json:"remove,omitempty" jsonschema:"citation IDs to delete: vanished ones, or ones that no longer support the fact"`
+}
+
+// BulkUpdateInput is the input schema for the memory_bulk_update tool.
+type BulkUpdateInput struct {
+	Subject        string   `json:"subject,omitempty" jsonschema:"match facts with this subject"`
+	Category       string   `json:"category,omitempty" jsonschema:"match facts with this category"`
+	Kind           string   `json:"kind,omitempty" jsonschema:"match facts with this kind"`
+	Subsystem      string   `json:"subsystem,omitempty" jsonschema:"match facts with this subsystem"`
+	Metadata       Metadata `json:"metadata,omitempty" jsonschema:"match facts on metadata fields (equality match)"`
+	Source         string   `json:"source,omitempty" jsonschema:"match facts by provenance source: manual, extraction, import or summary"`
+	SessionID      string   `json:"session_id,omitempty" jsonschema:"match facts that originated in this session"`
+	IncludeHistory bool     `json:"include_history,omitempty" jsonschema:"also re-classify superseded and expired versions (default: active facts only)"`
+
+	SetSubject   string   `json:"set_subject,omitempty" jsonschema:"new subject for every matched fact"`
+	SetCategory  string   `json:"set_category,omitempty" jsonschema:"new category for every matched fact"`
+	SetKind      *string  `json:"set_kind,omitempty" jsonschema:"new kind for every matched fact (empty string clears it)"`
+	SetSubsystem *string  `json:"set_subsystem,omitempty" jsonschema:"new subsystem for every matched fact (empty string clears it)"`
+	SetMetadata  Metadata `json:"set_metadata,omitempty" jsonschema:"metadata patch for every matched fact; keys with null values are deleted"`
+
+	Confirm string `json:"confirm,omitempty" jsonschema:"the token from a preview of this exact update; omit to preview"`
+}
+
+// AliasInput is the input schema for the memory_alias tool.
+type AliasInput struct {
+	Alias     string `json:"alias" jsonschema:"the other name: an old repo name, a fork, an abbreviation"`
+	Canonical string `json:"canonical" jsonschema:"the subject facts filed under alias belong to"`
+}
+
+// UnaliasInput is the input schema for the memory_unalias tool.
+type UnaliasInput struct {
+	Alias string `json:"alias" jsonschema:"the alias to remove"`
+}
+
+// ListAliasesInput is the input schema for the memory_list_aliases tool.
+type ListAliasesInput struct{}
+
+// RewriteAliasesInput is the input schema for the memory_rewrite_aliases tool.
+type RewriteAliasesInput struct {
+	Alias string `json:"alias,omitempty" jsonschema:"rewrite only facts filed under this alias (empty = every alias)"`
+}
+
+// PinInput is the input schema for the memory_pin tool.
+type PinInput struct {
+	ID          int64  `json:"id" jsonschema:"the fact to pin; must be the active version"`
+	Subject     string `json:"subject,omitempty" jsonschema:"pin for this project subject (set this or project_path)"`
+	ProjectPath string `json:"project_path,omitempty" jsonschema:"pin for sessions in this absolute directory or below it (set this or subject)"`
+	Position    int    `json:"position,omitempty" jsonschema:"order among the pins, ascending (0 = after the others, or unchanged for an existing pin)"`
+}
+
+// UnpinInput is the input schema for the memory_unpin tool.
+type UnpinInput struct {
+	ID int64 `json:"id" jsonschema:"the pinned fact, or any later version of it"`
+}
+
+// TagInput is the input schema for the memory_tag tool.
+type TagInput struct {
+	ID     int64    `json:"id" jsonschema:"the fact to tag"`
+	Add    []string `json:"add,omitempty" jsonschema:"tags to attach"`
+	Remove []string `json:"remove,omitempty" jsonschema:"tags to detach"`
+}
+
+// TagResult is the structured output for memory_tag.
+type TagResult struct {
+	ID   int64    `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+// TagsInput is the input schema for the memory_tags tool.
+type TagsInput struct {
+	Subject string `json:"subject,omitempty" jsonschema:"count only facts about this subject (empty = all subjects)"`
+}
+
+// TagsResult is the structured output for memory_tags.
+type TagsResult struct {
+	Tags []memstore.TagCount `json:"tags"`
+}
+
 // ConfirmInput is the input schema for the memory_confirm tool.
 type ConfirmInput struct {
 	ID int64 `json:"id" jsonschema:"the fact ID to confirm"`
@@ -523,25 +826,38 @@ type UpdateInput struct {
 
 // TaskCreateInput is the input schema for the memory_task_create tool.
 type TaskCreateInput struct {
-	Content  string `json:"content" jsonschema:"what needs to be done"`
-	Scope    string `json:"scope" jsonschema:"task owner: matthew, claude, or collaborative"`
-	Priority string `json:"priority,omitempty" jsonschema:"task priority: high, normal, or low (default: normal)"`
-	Project  string `json:"project,omitempty" jsonschema:"project name for grouping tasks"`
-	Due      string `json:"due,omitempty" jsonschema:"due date (free-form, e.g. 2026-03-01)"`
+	Content   string  `json:"content" jsonschema:"what needs to be done"`
+	Scope     string  `json:"scope" jsonschema:"task owner: matthew, claude, or collaborative"`
+	Priority  string  `json:"priority,omitempty" jsonschema:"task priority: high, normal, or low (default: normal)"`
+	Project   string  `json:"project,omitempty" jsonschema:"project name for grouping tasks"`
+	Due       string  `json:"due,omitempty" jsonschema:"due date: YYYY-MM-DD (due by the end of that day, UTC) or an RFC3339 time"`
+	Owner     string  `json:"owner,omitempty" jsonschema:"who is doing the task, when narrower than scope (e.g. a person or agent name)"`
+	BlockedBy []int64 `json:"blocked_by,omitempty" jsonschema:"IDs of tasks that must be completed or cancelled before this one can start"`
+	Recur     string  `json:"recur,omitempty" jsonschema:"recurrence: daily, weekly, monthly, yearly, every <duration> (e.g. every 90d), or a 5-field cron expression in UTC (e.g. 0 9 * * 1)"`
+	Remind    string  `json:"remind,omitempty" jsonschema:"how long before the due date the task surfaces, e.g. 3d (default for recurring tasks: 1d)"`
 }
 
 // TaskUpdateInput is the input schema for the memory_task_update tool.
 type TaskUpdateInput struct {
-	ID     int64  `json:"id" jsonschema:"the task fact ID to update"`
-	Status string `json:"status" jsonschema:"new status: pending, in_progress, completed, or cancelled"`
-	Note   string `json:"note,omitempty" jsonschema:"optional transition note (e.g. reason for cancellation)"`
+	ID              int64   `json:"id" jsonschema:"the task fact ID to update"`
+	Status          string  `json:"status,omitempty" jsonschema:"new status: pending, in_progress, completed, or cancelled"`
+	Note            string  `json:"note,omitempty" jsonschema:"optional transition note (e.g. reason for cancellation)"`
+	Due             string  `json:"due,omitempty" jsonschema:"new due date (YYYY-MM-DD or RFC3339); \"none\" clears it"`
+	Owner           string  `json:"owner,omitempty" jsonschema:"new owner"`
+	Recur           string  `json:"recur,omitempty" jsonschema:"new recurrence rule; \"none\" ends the series"`
+	Remind          string  `json:"remind,omitempty" jsonschema:"new reminder lead before the due date (e.g. 3d); \"none\" clears it"`
+	AddBlockedBy    []int64 `json:"add_blocked_by,omitempty" jsonschema:"IDs of tasks this task now waits on; rejected if it would create a cycle"`
+	RemoveBlockedBy []int64 `json:"remove_blocked_by,omitempty" jsonschema:"IDs of tasks this task no longer waits on"`
 }
 
 // TaskListInput is the input schema for the memory_task_list tool.
 type TaskListInput struct {
 	Scope   string `json:"scope,omitempty" jsonschema:"filter by scope: matthew, claude, or collaborative"`
-	Status  string `json:"status,omitempty" jsonschema:"filter by status (default: pending)"`
+	Status  string `json:"status,omitempty" jsonschema:"filter by status (default: pending, or all open tasks when state or group_by is set)"`
 	Project string `json:"project,omitempty" jsonschema:"filter by project name"`
+	Owner   string `json:"owner,omitempty" jsonschema:"filter by owner"`
+	State   string `json:"state,omitempty" jsonschema:"filter by derived state: blocked, ready, in_progress, done, overdue, or due_soon (reminder time passed, not yet overdue)"`
+	GroupBy string `json:"group_by,omitempty" jsonschema:"group the tasks: state (a board of blocked/ready/in_progress/done columns), project, owner, priority, or scope"`
 }
 
 // StatusInput is the input schema for the memory_status tool.
@@ -615,8 +931,9 @@ Conventions:
   - project: project decisions, repos, work-in-progress
   - world: facts about external entities — authors they read, books, hardware they own, places, organizations. Use this for durable interests and reference data about the world outside themselves.
   - note: catch-all when nothing else fits
-- metadata: attribution (source), confidence, temporal bounds (valid_from/valid_until), or any structured data.
-- supersedes: pass the ID of the fact this replaces. The old fact is preserved in history. Always prefer superseding over deleting.`,
+- metadata: attribution (source), confidence, temporal bounds (valid_from/valid_until), or any structured data. Metadata must match the schema of the fact's kind: a trigger that names a signal_type (file_pattern or cwd_pattern) also needs a signal pattern and load_subsystem or load_subject; failure_mode takes optional symptom/cause/fix strings. A mismatch is rejected with the failing key and the kind's schema.
+- supersedes: pass the ID of the fact this replaces. The old fact is preserved in history. Always prefer superseding over deleting.
+- ttl / expires_at: for facts that are only true for a while ("currently debugging X", "PR #42 is waiting on review"). Once expired the fact drops out of search and lists, and is archived in the background.`,
 	}, ms.HandleStore)
 
 	mcp.AddTool(s, &mcp.Tool{
@@ -668,16 +985,56 @@ Use this when you want a complete picture of a subject rather than matching a sp
 		Name: "memory_delete",
 		Description: `Delete a specific memory by its ID. Use this to remove outdated or incorrect information.
 
-Prefer memory_supersede or memory_store with the 'supersedes' parameter instead — these preserve the old fact in history. Only delete facts that are genuinely wrong or harmful, not just outdated.`,
+Prefer memory_supersede or memory_store with the 'supersedes' parameter instead — these preserve the old fact in history. Only delete facts that are genuinely wrong or harmful, not just outdated.
+
+Deleted facts go to the trash: they and their links vanish from every search, list, and history, but memory_restore brings them back until the trash is purged.`,
 	}, ms.HandleDelete)
 
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "memory_trash",
+		Description: "List deleted memories still in the trash, most recently deleted first. Use it to find the ID of a fact deleted by mistake before calling memory_restore.",
+	}, ms.HandleTrash)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "memory_restore",
+		Description: "Restore a deleted memory from the trash by its ID. The fact and its links reappear exactly as they were before deletion.",
+	}, ms.HandleRestore)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_purge",
+		Description: `Permanently remove memories from the trash. This cannot be undone. Pass older_than (e.g. "7d") to purge only facts deleted before then, or all=true to empty the trash.
+
+The trash is also purged automatically after its retention period, so you rarely need this; only use it when the user asks for deleted facts to be gone for good.`,
+	}, ms.HandlePurge)
+
 	mcp.AddTool(s, &mcp.Tool{
 		Name: "memory_supersede",
 		Description: `Mark an existing fact as superseded by a newer fact. Both facts must already exist. The old fact is preserved in history but excluded from normal search results.
 
-Use this when you discover a stored fact is outdated and you've already stored the replacement. For a single-step "store and supersede", use memory_store with the supersedes parameter instead.`,
+Use this when you discover a stored fact is outdated and you've already stored the replacement. For a single-step "store and supersede", use memory_store with the supersedes parameter instead, or memory_revise to rewrite an existing fact's content.`,
 	}, ms.HandleSupersede)
 
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_revise",
+		Description: `Change the content of an existing fact in one step. A new version is stored with the same subject, category, kind, subsystem, expiry, metadata, and links, and the old fact is superseded by it — atomically, so a failure never leaves both versions active.
+
+Use this instead of memory_store + memory_supersede when correcting or refining a fact. Pass metadata to set (non-nil) or delete (nil) keys on the new version; use memory_update for metadata-only changes.`,
+	}, ms.HandleRevise)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_merge",
+		Description: `Consolidate several overlapping facts into one. The merged fact takes its subject, category, kind, subsystem, and metadata from the first ID; every source is superseded by it, their links are moved onto it, and metadata.merged_from records which facts went in. Atomic: either everything happens or nothing does.
+
+Omit content to have the configured LLM draft the consolidated text from the sources. Run with dry_run=true first to review the sources and the draft, then call again with the content you want.`,
+	}, ms.HandleMerge)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_contradictions",
+		Description: `Review pairs of active facts that the background contradiction audit judged to conflict, oldest first. Each entry shows both facts and the model's reason.
+
+Resolve a real conflict by superseding or revising the wrong fact (memory_supersede, memory_revise) or deleting it; the entry then leaves the queue. Pass dismiss with the link IDs of entries that are not real conflicts. The audit itself is opt-in and runs on the server; an empty queue may just mean it is disabled.`,
+	}, ms.HandleContradictions)
+
 	mcp.AddTool(s, &mcp.Tool{
 		Name: "memory_history",
 		Description: `Show the supersession history for a fact (by ID) or all facts for a subject (by subject). Reveals how knowledge has evolved over time, including superseded facts with their replacement chain.
@@ -697,6 +1054,94 @@ Use this when:
 Facts with high confirmation counts are well-tested knowledge. Facts with zero confirmations are unverified. This signal helps prioritize what to trust.`,
 	}, ms.HandleConfirm)
 
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_review_queue",
+		Description: `List facts due for re-verification, highest priority first. Priority grows with time since the fact was last confirmed (or created, if never), and is boosted for invariants and conventions, for facts recall uses often, and for facts with negative feedback.
+
+For each fact, check it against the current code or ask the user, then act: memory_confirm if it still holds, memory_revise or memory_supersede if it changed, memory_delete if it is wrong. Each action takes the fact off the queue.
+
+Facts whose cited code changed or vanished on re-ingest are queued whatever their age, with a strong priority boost. Inspect them with memory_cited_code, which also accepts or removes the stale citations; until then they stay queued.`,
+	}, ms.HandleReviewQueue)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_cite",
+		Description: `Cite a document-corpus chunk from a fact: record that the fact was drawn from that code or documentation. The chunk's repo, path, symbol, line span and content hash are snapshotted.
+
+Whenever the document is re-ingested, the citation is checked again. If the cited code changed or vanished, the fact is flagged for review (memory_review_queue, memory_cited_code). Requires the Postgres backend, which carries the document corpus.`,
+	}, ms.HandleCite)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_cited_code",
+		Description: `Show a fact together with the code it cites, as it is in the latest ingest, and each citation's status: current, changed (the symbol or span is still there but differs), or vanished.
+
+Check the fact against the current code. If it still holds, pass accept with the changed citation IDs. If it does not, revise the fact (memory_revise). Pass remove to delete citations that no longer apply, including vanished ones.`,
+	}, ms.HandleCitedCode)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_bulk_update",
+		Description: `Re-classify every fact matching a filter in one step: set subject, category, kind or subsystem, or patch metadata. Facts are edited in place — no new versions — and the change is recorded in the store's audit log.
+
+This always takes two calls. Call it first without confirm: nothing is written, and it reports how many facts match, a sample of them, and a token. Check the sample, then call again with the same filter and change and confirm set to that token. If the matching facts changed in between, the apply is refused; preview again.
+
+At least one filter field is required. Only active facts are matched unless include_history is set. Use memory_revise to change what a fact says; this tool is for how facts are filed.`,
+	}, ms.HandleBulkUpdate)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_alias",
+		Description: `Make one subject another name for another: a renamed repo, a fork, an abbreviation.
+
+From then on facts stored under the alias are filed under the canonical subject, a subject filter on either name finds the whole group, and recall treats a working directory named after the alias as the canonical project.
+If canonical is itself an alias, the new alias joins its group. Naming a group's canonical subject as the alias of one of its members makes that member canonical instead.
+
+Example: memory_alias(alias="memstore-fork", canonical="memstore")`,
+	}, ms.HandleAlias)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "memory_unalias",
+		Description: `Remove a subject alias. Facts already rewritten to the canonical subject keep it.`,
+	}, ms.HandleUnalias)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "memory_list_aliases",
+		Description: `List every subject alias and the canonical subject it resolves to.`,
+	}, ms.HandleListAliases)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_rewrite_aliases",
+		Description: `Rewrite the subject of facts filed under an alias -- typically stored before the alias existed -- to its canonical subject.
+
+Facts are edited in place, history included, and the change is recorded in the store's audit log. Omit alias to rewrite every alias.`,
+	}, ms.HandleRewriteAliases)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_pin",
+		Description: `Pin a fact so it is injected at the top of every recall and memory_get_context for a project, whatever the prompt says.
+
+Pin by subject (the project name, matched through subject aliases) or by project_path (sessions in that directory or below). Pins are ordered by position, and follow the fact through memory_revise and supersession. Pinning an already-pinned fact re-scopes or re-orders it.
+Use pins sparingly, for rules that must be in every session; they come out of a separate budget.
+
+Example: memory_pin(id=42, subject="memstore")`,
+	}, ms.HandlePin)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "memory_unpin",
+		Description: `Remove a fact's pin. The fact itself is kept.`,
+	}, ms.HandleUnpin)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "memory_tag",
+		Description: `Attach tags to a fact and/or detach tags from it. Tags are short labels (lower-cased, no spaces or commas) for cross-cutting concerns that subject and category do not capture, e.g. "perf", "security", "todo".
+
+Tags carry over to the new version on memory_revise and are unioned on memory_merge. Filter by them with the tags, tags_all and tags_none arguments of memory_search and memory_list.
+
+Example: memory_tag(id=42, add=["perf"], remove=["todo"])`,
+	}, ms.HandleTag)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "memory_tags",
+		Description: `List the tags in use on active facts with how many facts carry each, most used first, optionally for one subject.`,
+	}, ms.HandleTags)
+
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "memory_status",
 		Description: "Show memory store statistics: total active facts, and breakdown by subject and category.",
@@ -706,7 +1151,7 @@ Facts with high confirmation counts are well-tested knowledge. Facts with zero c
 		Name: "memory_update",
 		Description: `Update metadata on an existing fact without replacing the fact itself. Keys with non-nil values are set; keys with nil values are deleted.
 
-Use this for status transitions, adding surface flags, or updating structured metadata. Does not create supersession history — use memory_store with supersedes for content changes.`,
+Use this for status transitions, adding surface flags, or updating structured metadata. Does not create supersession history — use memory_revise for content changes.`,
 	}, ms.HandleUpdate)
 
 	mcp.AddTool(s, &mcp.Tool{
@@ -718,7 +1163,11 @@ Scope controls ownership:
 - "claude" — agent's task (follow-ups, deferred work)
 - "collaborative" — shared between user and agent
 
-Tasks with status "pending" or "in_progress" have surface="startup" so they appear at session start via memory_list(metadata: {surface: "startup"}).`,
+Tasks with status "pending" or "in_progress" have surface="startup" so they appear at session start via memory_list(metadata: {surface: "startup"}).
+
+Optional: due (YYYY-MM-DD or RFC3339), owner, and blocked_by — IDs of tasks that must be completed or cancelled before this one can start.
+
+Recurring tasks and reminders: recur takes daily, weekly, monthly, yearly, "every 90d", or a 5-field UTC cron expression ("0 9 * * 1"); remind is a lead time before the due date ("3d"). Such a task stays off the startup list until its reminder time (due minus remind, default 1 day), when the reminder evaluator surfaces it. A recurring task without a due date is first due one step from now.`,
 	}, ms.HandleTaskCreate)
 
 	mcp.AddTool(s, &mcp.Tool{
@@ -727,15 +1176,21 @@ Tasks with status "pending" or "in_progress" have surface="startup" so they appe
 
 Valid statuses: pending, in_progress, completed, cancelled.
 Completing or cancelling a task removes the "surface" flag so it no longer appears at startup.
-Optional note is stored as metadata.note for transition context.`,
+Optional note is stored as metadata.note for transition context.
+
+Also changes due and owner, and dependencies: add_blocked_by / remove_blocked_by take task IDs. A dependency that would create a cycle is rejected. Status may be omitted when changing only these.
+Completing or cancelling a task reports the tasks it was the last open blocker of, which are now unblocked.
+Completing a recurring task creates its next occurrence, due one step after the completed deadline (skipping steps already in the past); cancelling it ends the series. recur and remind can be changed, or cleared with "none".`,
 	}, ms.HandleTaskUpdate)
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name: "memory_task_list",
 		Description: `List tasks with optional filters. Defaults to showing pending tasks across all scopes.
 
-Filters: scope (matthew/claude/collaborative), status, project.
-Output is task-focused: shows status, scope, priority, content, and due date.`,
+Filters: scope (matthew/claude/collaborative), status, project, owner, and state.
+Each task has a derived state: blocked (waiting on an open task), ready, in_progress, or done; open tasks past their due date are also overdue. state filters on these, on "overdue", or on "due_soon" (reminder time passed, not yet overdue).
+group_by renders a grouped view: "state" gives a board of blocked/ready/in_progress columns; project, owner, priority, and scope are also accepted. With state or group_by set, status defaults to all open tasks instead of pending.
+Output is task-focused: shows status, scope, priority, owner, content, due date, and blockers. Tasks are ordered overdue first, then by priority and due date.`,
 	}, ms.HandleTaskList)
 
 	mcp.AddTool(s, &mcp.Tool{
@@ -875,6 +1330,10 @@ func (ms *MemoryServer) HandleStore(ctx context.Context, _ *mcp.CallToolRequest,
 	if category == "" {
 		category = "note"
 	}
+	expiresAt, err := input.expiry()
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), StoreResult{}, nil
+	}
 
 	// Dedup check.
 	exists, err := ms.store.Exists(ctx, input.Content, input.Subject)
@@ -901,7 +1360,9 @@ func (ms *MemoryServer) HandleStore(ctx context.Context, _ *mcp.CallToolRequest,
 		Category:  category,
 		Kind:      strings.TrimSpace(input.Kind),
 		Subsystem: strings.TrimSpace(input.Subsystem),
+		ExpiresAt: expiresAt,
 		Embedding: emb,
+		Tags:      input.Tags,
 	}
 	if len(input.Metadata) > 0 {
 		metaJSON, err := json.Marshal(input.Metadata)
@@ -913,10 +1374,13 @@ func (ms *MemoryServer) HandleStore(ctx context.Context, _ *mcp.CallToolRequest,
 
 	id, err := ms.store.Insert(ctx, fact)
 	if err != nil {
-		return textResult(fmt.Sprintf("Error storing fact: %v", err), true), StoreResult{}, nil
+		return textResult(storeErrorText("Error storing fact", err), true), StoreResult{}, nil
 	}
 
 	msg := fmt.Sprintf("Stored (id=%d, subject=%q, category=%q).", id, input.Subject, category)
+	if expiresAt != nil {
+		msg += fmt.Sprintf(" Expires %s.", expiresAt.Format(time.RFC3339))
+	}
 
 	// Handle supersession after successful insert.
 	var supersededBy *int64
@@ -957,6 +1421,11 @@ func (ms *MemoryServer) HandleStoreBatch(ctx context.Context, _ *mcp.CallToolReq
 		if category == "" {
 			category = "note"
 		}
+		expiresAt, err := f.expiry()
+		if err != nil {
+			results = append(results, BatchResult{Index: i + 1, Status: "skipped", Error: err.Error()})
+			continue
+		}
 
 		exists, err := ms.store.Exists(ctx, f.Content, f.Subject)
 		if err != nil {
@@ -983,6 +1452,7 @@ func (ms *MemoryServer) HandleStoreBatch(ctx context.Context, _ *mcp.CallToolReq
 			Category:  category,
 			Kind:      strings.TrimSpace(f.Kind),
 			Subsystem: strings.TrimSpace(f.Subsystem),
+			ExpiresAt: expiresAt,
 			Embedding: emb,
 		}
 		if len(f.Metadata) > 0 {
@@ -1056,6 +1526,7 @@ func (ms *MemoryServer) HandleSearch(ctx context.Context, _ *mcp.CallToolRequest
 		Subsystem:        input.Subsystem,
 		OnlyActive:       !input.IncludeSuperseded,
 		MetadataFilters:  metadataFilters(input.Metadata),
+		Tags:             memstore.TagFilter{Any: input.Tags, All: input.TagsAll, None: input.TagsNone},
 		RerankMode:       mode,
 		RerankThreshold:  threshold,
 		RerankCandidates: tun.searchCandidates,
@@ -1118,6 +1589,9 @@ func (ms *MemoryServer) HandleSearch(ctx context.Context, _ *mcp.CallToolRequest
 		if r.Fact.Subsystem != "" {
 			fmt.Fprintf(&b, " | subsystem=%s", r.Fact.Subsystem)
 		}
+		if len(r.Fact.Tags) > 0 {
+			fmt.Fprintf(&b, " | tags=%s", strings.Join(r.Fact.Tags, ","))
+		}
 		if r.Fact.SupersededBy != nil {
 			fmt.Fprintf(&b, " [SUPERSEDED by %d]", *r.Fact.SupersededBy)
 		}
@@ -1140,6 +1614,7 @@ func (ms *MemoryServer) HandleSearch(ctx context.Context, _ *mcp.CallToolRequest
 			UseCount:       r.Fact.UseCount + 1,
 			ConfirmedCount: r.Fact.ConfirmedCount,
 			SupersededBy:   r.Fact.SupersededBy,
+			Tags:           r.Fact.Tags,
 			Metadata:       decodeMetadata(r.Fact.Metadata),
 		})
 	}
@@ -1291,6 +1766,8 @@ func (ms *MemoryServer) HandleList(ctx context.Context, _ *mcp.CallToolRequest,
 		OnlyActive:      true,
 		Limit:           limit,
 		MetadataFilters: metadataFilters(input.Metadata),
+		Provenance:      memstore.ProvenanceFilter{Source: input.Source, SessionID: input.SessionID},
+		Tags:            memstore.TagFilter{Any: input.Tags, All: input.TagsAll, None: input.TagsNone},
 	}
 
 	facts, err := ms.store.List(ctx, opts)
@@ -1314,6 +1791,9 @@ func (ms *MemoryServer) HandleList(ctx context.Context, _ *mcp.CallToolRequest,
 		if f.Subsystem != "" {
 			fmt.Fprintf(&b, " | subsystem=%s", f.Subsystem)
 		}
+		if len(f.Tags) > 0 {
+			fmt.Fprintf(&b, " | tags=%s", strings.Join(f.Tags, ","))
+		}
 		fmt.Fprintf(&b, " | %s\n", f.CreatedAt.Format("2006-01-02"))
 		fmt.Fprintf(&b, "  %s\n", f.Content)
 		if len(f.Metadata) > 0 && string(f.Metadata) != "null" {
@@ -1331,6 +1811,7 @@ func (ms *MemoryServer) HandleList(ctx context.Context, _ *mcp.CallToolRequest,
 			Score:          0,
 			UseCount:       f.UseCount,
 			ConfirmedCount: f.ConfirmedCount,
+			Tags:           f.Tags,
 			Metadata:       decodeMetadata(f.Metadata),
 		})
 	}
@@ -1351,7 +1832,73 @@ func (ms *MemoryServer) HandleDelete(ctx context.Context, _ *mcp.CallToolRequest
 	}
 
 	out := DeleteResult{Status: "deleted", ID: input.ID}
-	return textResult(fmt.Sprintf("Deleted memory %d.", input.ID), false), out, nil
+	return textResult(fmt.Sprintf("Deleted memory %d (moved to trash; memory_restore brings it back).", input.ID), false), out, nil
+}
+
+func (ms *MemoryServer) HandleTrash(ctx context.Context, _ *mcp.CallToolRequest, input TrashInput) (*mcp.CallToolResult, TrashResult, error) {
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	facts, err := ms.store.Trash(ctx, memstore.QueryOpts{Subject: input.Subject, Limit: limit})
+	if err != nil {
+		return textResult(fmt.Sprintf("Error listing trash: %v", err), true), TrashResult{}, nil
+	}
+	if len(facts) == 0 {
+		return textResult("The trash is empty.", false), TrashResult{}, nil
+	}
+
+	var b strings.Builder
+	out := TrashResult{Facts: make([]TrashedFact, 0, len(facts))}
+	for _, f := range facts {
+		var deleted string
+		if f.DeletedAt != nil {
+			deleted = f.DeletedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(&b, "[id=%d] %s | %s | deleted %s\n", f.ID, f.Subject, f.Category, deleted)
+		fmt.Fprintf(&b, "  %s\n\n", f.Content)
+		out.Facts = append(out.Facts, TrashedFact{
+			ID:        f.ID,
+			Subject:   f.Subject,
+			Category:  f.Category,
+			Content:   f.Content,
+			DeletedAt: deleted,
+		})
+	}
+	fmt.Fprintf(&b, "%d memories in trash.", len(facts))
+	return textResult(b.String(), false), out, nil
+}
+
+func (ms *MemoryServer) HandleRestore(ctx context.Context, _ *mcp.CallToolRequest, input RestoreInput) (*mcp.CallToolResult, DeleteResult, error) {
+	if input.ID <= 0 {
+		return textResult("Error: id must be a positive integer", true), DeleteResult{}, nil
+	}
+	if err := ms.store.Restore(ctx, input.ID); err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), DeleteResult{}, nil
+	}
+	out := DeleteResult{Status: "restored", ID: input.ID}
+	return textResult(fmt.Sprintf("Restored memory %d.", input.ID), false), out, nil
+}
+
+func (ms *MemoryServer) HandlePurge(ctx context.Context, _ *mcp.CallToolRequest, input PurgeInput) (*mcp.CallToolResult, PurgeResult, error) {
+	before := time.Now()
+	switch {
+	case input.All && input.OlderThan != "":
+		return textResult("Error: pass older_than or all, not both", true), PurgeResult{}, nil
+	case input.OlderThan != "":
+		d, err := memstore.ParseTTL(input.OlderThan)
+		if err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), PurgeResult{}, nil
+		}
+		before = before.Add(-d)
+	case !input.All:
+		return textResult("Error: older_than or all=true is required", true), PurgeResult{}, nil
+	}
+	n, err := ms.store.Purge(ctx, before)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), PurgeResult{}, nil
+	}
+	return textResult(fmt.Sprintf("Purged %d memories from the trash.", n), false), PurgeResult{Purged: n}, nil
 }
 
 func (ms *MemoryServer) HandleStatus(ctx context.Context, _ *mcp.CallToolRequest, _ StatusInput) (*mcp.CallToolResult, StatusResult, error) {
@@ -1406,6 +1953,20 @@ func (ms *MemoryServer) HandleStatus(ctx context.Context, _ *mcp.CallToolRequest
 		Kinds:       kinds,
 		Subjects:    subjects,
 	}
+
+	// A citer whose backend has no corpus (a client of a SQLite daemon)
+	// errors here; there is nothing to report then.
+	if ct, ok := ms.store.(memstore.Citer); ok {
+		if stale, err := ct.StaleCitations(ctx, 0); err == nil && len(stale) > 0 {
+			flagged := make(map[int64]bool)
+			for _, c := range stale {
+				flagged[c.FactID] = true
+			}
+			out.StaleCitedFacts, out.StaleCitations = len(flagged), len(stale)
+			fmt.Fprintf(&b, "\nStale code citations: %d facts (%d citations) cite code that changed or vanished; see memory_review_queue.\n",
+				out.StaleCitedFacts, out.StaleCitations)
+		}
+	}
 	return textResult(b.String(), false), out, nil
 }
 
@@ -1452,6 +2013,163 @@ func (ms *MemoryServer) HandleSupersede(ctx context.Context, _ *mcp.CallToolRequ
 		input.OldID, input.NewID, oldFact.Content, newFact.Content), false), out, nil
 }
 
+func (ms *MemoryServer) HandleRevise(ctx context.Context, _ *mcp.CallToolRequest, input ReviseInput) (*mcp.CallToolResult, SupersedeResult, error) {
+	if input.ID <= 0 {
+		return textResult("Error: id must be a positive integer", true), SupersedeResult{}, nil
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		return textResult("Error: content is required", true), SupersedeResult{}, nil
+	}
+
+	oldFact, err := ms.store.Get(ctx, input.ID)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error looking up fact %d: %v", input.ID, err), true), SupersedeResult{}, nil
+	}
+	if oldFact == nil {
+		return textResult(fmt.Sprintf("Error: fact %d not found", input.ID), true), SupersedeResult{}, nil
+	}
+	if oldFact.SupersededBy != nil {
+		return textResult(fmt.Sprintf("Error: fact %d is already superseded by fact %d; revise that one instead", input.ID, *oldFact.SupersededBy), true), SupersedeResult{}, nil
+	}
+
+	newID, err := ms.store.Revise(ctx, input.ID, input.Content, input.Metadata)
+	if err != nil {
+		return textResult(storeErrorText("Error", err), true), SupersedeResult{}, nil
+	}
+
+	out := SupersedeResult{
+		Status:     "revised",
+		OldID:      input.ID,
+		NewID:      newID,
+		OldContent: oldFact.Content,
+		NewContent: input.Content,
+	}
+	return textResult(fmt.Sprintf("Revised fact %d as fact %d.\n  Old: %s\n  New: %s",
+		input.ID, newID, oldFact.Content, input.Content), false), out, nil
+}
+
+func (ms *MemoryServer) HandleMerge(ctx context.Context, _ *mcp.CallToolRequest, input MergeInput) (*mcp.CallToolResult, MergeResult, error) {
+	ids, err := memstore.MergeSources(input.IDs)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), MergeResult{}, nil
+	}
+
+	facts, err := ms.store.List(ctx, memstore.QueryOpts{IDs: ids})
+	if err != nil {
+		return textResult(fmt.Sprintf("Error looking up facts: %v", err), true), MergeResult{}, nil
+	}
+	byID := make(map[int64]memstore.Fact, len(facts))
+	for _, f := range facts {
+		byID[f.ID] = f
+	}
+	sources := make([]memstore.Fact, 0, len(ids))
+	for _, id := range ids {
+		f, ok := byID[id]
+		if !ok {
+			return textResult(fmt.Sprintf("Error: fact %d not found", id), true), MergeResult{}, nil
+		}
+		if f.SupersededBy != nil {
+			return textResult(fmt.Sprintf("Error: fact %d is already superseded by fact %d", id, *f.SupersededBy), true), MergeResult{}, nil
+		}
+		sources = append(sources, f)
+	}
+
+	out := MergeResult{Content: strings.TrimSpace(input.Content)}
+	for _, f := range sources {
+		out.Sources = append(out.Sources, FactResult{
+			ID: f.ID, Subject: f.Subject, Category: f.Category, Kind: f.Kind, Subsystem: f.Subsystem,
+			Content: f.Content, UseCount: f.UseCount, ConfirmedCount: f.ConfirmedCount,
+		})
+	}
+	if out.Content == "" {
+		if ms.generator == nil {
+			return textResult("Error: content is required (no LLM is configured to draft it)", true), MergeResult{}, nil
+		}
+		// Oldest first, so the model can prefer newer claims on conflict.
+		byAge := append([]memstore.Fact(nil), sources...)
+		sort.SliceStable(byAge, func(i, j int) bool { return byAge[i].CreatedAt.Before(byAge[j].CreatedAt) })
+		out.Content, err = memstore.DraftMerge(ctx, ms.generator, byAge)
+		if err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), MergeResult{}, nil
+		}
+		out.Drafted = true
+	}
+
+	var b strings.Builder
+	if input.DryRun {
+		out.Status = "dry_run"
+		fmt.Fprintf(&b, "Dry run: would merge %d facts (nothing changed).\n", len(sources))
+	} else {
+		out.ID, err = ms.store.Merge(ctx, ids, out.Content, input.Metadata)
+		if err != nil {
+			return textResult(storeErrorText("Error", err), true), MergeResult{}, nil
+		}
+		out.Status = "merged"
+		fmt.Fprintf(&b, "Merged %d facts into fact %d.\n", len(sources), out.ID)
+	}
+	for _, f := range sources {
+		fmt.Fprintf(&b, "  [id=%d] %s\n", f.ID, f.Content)
+	}
+	label := "Merged"
+	if out.Drafted {
+		label = "Drafted"
+	}
+	fmt.Fprintf(&b, "%s content: %s", label, out.Content)
+	return textResult(b.String(), false), out, nil
+}
+
+func (ms *MemoryServer) HandleContradictions(ctx context.Context, _ *mcp.CallToolRequest, input ContradictionsInput) (*mcp.CallToolResult, ContradictionsResult, error) {
+	cq, ok := ms.store.(memstore.ContradictionQueue)
+	if !ok {
+		return textResult("Error: this store has no contradiction queue", true), ContradictionsResult{}, nil
+	}
+	var out ContradictionsResult
+	for _, id := range input.Dismiss {
+		if err := memstore.DismissContradiction(ctx, ms.store, id); err != nil {
+			return textResult(fmt.Sprintf("Error dismissing %d: %v", id, err), true), ContradictionsResult{}, nil
+		}
+		out.Dismissed = append(out.Dismissed, id)
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	items, err := cq.Contradictions(ctx, limit)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error listing contradictions: %v", err), true), ContradictionsResult{}, nil
+	}
+
+	var b strings.Builder
+	if len(out.Dismissed) > 0 {
+		fmt.Fprintf(&b, "Dismissed %d: %v\n\n", len(out.Dismissed), out.Dismissed)
+	}
+	out.Contradictions = make([]ContradictionItem, 0, len(items))
+	for _, c := range items {
+		fmt.Fprintf(&b, "[link=%d] %s\n", c.LinkID, c.Reason)
+		item := ContradictionItem{LinkID: c.LinkID, Reason: c.Reason}
+		for _, pair := range []struct {
+			f   memstore.Fact
+			dst *FactResult
+		}{{c.A, &item.A}, {c.B, &item.B}} {
+			f := pair.f
+			fmt.Fprintf(&b, "  [id=%d] %s | %s\n    %s\n", f.ID, f.Subject, f.CreatedAt.Format("2006-01-02"), f.Content)
+			*pair.dst = FactResult{
+				ID: f.ID, Subject: f.Subject, Category: f.Category, Kind: f.Kind, Subsystem: f.Subsystem,
+				Content: f.Content, UseCount: f.UseCount, ConfirmedCount: f.ConfirmedCount,
+			}
+		}
+		b.WriteString("\n")
+		out.Contradictions = append(out.Contradictions, item)
+	}
+	if len(items) == 0 {
+		b.WriteString("No open contradictions.")
+	} else {
+		fmt.Fprintf(&b, "%d open contradictions.", len(items))
+	}
+	return textResult(b.String(), false), out, nil
+}
+
 func (ms *MemoryServer) HandleHistory(ctx context.Context, _ *mcp.CallToolRequest, input HistoryInput) (*mcp.CallToolResult, HistoryResult, error) {
 	if input.ID <= 0 && strings.TrimSpace(input.Subject) == "" {
 		return textResult("Error: provide either id or subject", true), HistoryResult{}, nil
@@ -1468,11 +2186,9 @@ func (ms *MemoryServer) HandleHistory(ctx context.Context, _ *mcp.CallToolReques
 
 	var b strings.Builder
 	historyEntries := make([]HistoryEntry, 0, len(entries))
+	now := time.Now()
 	for _, e := range entries {
-		status := "ACTIVE"
-		if e.Fact.SupersededBy != nil {
-			status = fmt.Sprintf("SUPERSEDED by %d", *e.Fact.SupersededBy)
-		}
+		status := historyStatus(e.Fact, now)
 		fmt.Fprintf(&b, "[%d/%d] (id=%d, used=%d, confirmed=%d) %s | %s | %s | %s\n",
 			e.Position+1, e.ChainLength, e.Fact.ID,
 			e.Fact.UseCount, e.Fact.ConfirmedCount,
@@ -1482,6 +2198,11 @@ func (ms *MemoryServer) HandleHistory(ctx context.Context, _ *mcp.CallToolReques
 		if len(e.Fact.Metadata) > 0 && string(e.Fact.Metadata) != "null" {
 			fmt.Fprintf(&b, "  metadata: %s\n", string(e.Fact.Metadata))
 		}
+		var prov *memstore.Provenance
+		if !e.Fact.Provenance.IsZero() {
+			prov = &e.Fact.Provenance
+			fmt.Fprintf(&b, "  provenance: %s\n", prov)
+		}
 		fmt.Fprintln(&b)
 
 		historyEntries = append(historyEntries, HistoryEntry{
@@ -1496,6 +2217,7 @@ func (ms *MemoryServer) HandleHistory(ctx context.Context, _ *mcp.CallToolReques
 			Metadata:       decodeMetadata(e.Fact.Metadata),
 			UseCount:       e.Fact.UseCount,
 			ConfirmedCount: e.Fact.ConfirmedCount,
+			Provenance:     prov,
 		})
 	}
 
@@ -1503,6 +2225,441 @@ func (ms *MemoryServer) HandleHistory(ctx context.Context, _ *mcp.CallToolReques
 	return textResult(b.String(), false), out, nil
 }
 
+// historyStatus describes a fact's lifecycle for memory_history: active,
+// superseded, and any expiry, including the reaper's archive stamp.
+func historyStatus(f memstore.Fact, now time.Time) string {
+	const stamp = "2006-01-02 15:04"
+	var parts []string
+	if f.SupersededBy != nil {
+		parts = append(parts, fmt.Sprintf("SUPERSEDED by %d", *f.SupersededBy))
+	}
+	switch {
+	case f.ArchivedAt != nil:
+		parts = append(parts, "ARCHIVED "+f.ArchivedAt.Format(stamp)+" (expired)")
+	case f.ExpiresAt != nil && !f.ExpiresAt.After(now):
+		parts = append(parts, "EXPIRED "+f.ExpiresAt.Format(stamp))
+	case f.ExpiresAt != nil && f.SupersededBy == nil:
+		parts = append(parts, "ACTIVE until "+f.ExpiresAt.Format(stamp))
+	}
+	if len(parts) == 0 {
+		return "ACTIVE"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (ms *MemoryServer) HandleReviewQueue(ctx context.Context, _ *mcp.CallToolRequest, input ReviewQueueInput) (*mcp.CallToolResult, ReviewQueueResult, error) {
+	rv, ok := ms.store.(memstore.Reviewer)
+	if !ok {
+		return textResult("Error: this store has no review queue", true), ReviewQueueResult{}, nil
+	}
+	opts := memstore.ReviewOpts{Limit: input.Limit, Subject: input.Subject}
+	if input.MinAge != "" {
+		d, err := memstore.ParseTTL(input.MinAge)
+		if err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), ReviewQueueResult{}, nil
+		}
+		opts.MinAge = d
+	}
+	items, err := rv.ReviewQueue(ctx, opts)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error building review queue: %v", err), true), ReviewQueueResult{}, nil
+	}
+	if len(items) == 0 {
+		return textResult("No facts are due for review.", false), ReviewQueueResult{Items: []ReviewQueueItem{}}, nil
+	}
+
+	var b strings.Builder
+	out := ReviewQueueResult{Items: make([]ReviewQueueItem, 0, len(items))}
+	for _, it := range items {
+		f := it.Fact
+		fmt.Fprintf(&b, "[id=%d] %s | %s | priority %.2f: %s\n", f.ID, f.Subject, f.Kind, it.Priority, strings.Join(it.Reasons, ", "))
+		fmt.Fprintf(&b, "  %s\n", f.Content)
+		if it.StaleCitations > 0 {
+			fmt.Fprintf(&b, "  cited code changed: inspect with memory_cited_code id=%d\n", f.ID)
+		}
+		b.WriteString("\n")
+		out.Items = append(out.Items, ReviewQueueItem{
+			FactResult: FactResult{
+				ID: f.ID, Subject: f.Subject, Category: f.Category, Kind: f.Kind, Subsystem: f.Subsystem,
+				Content: f.Content, UseCount: f.UseCount, ConfirmedCount: f.ConfirmedCount,
+			},
+			Priority:       it.Priority,
+			Since:          it.Since.Format(time.RFC3339),
+			Reasons:        it.Reasons,
+			StaleCitations: it.StaleCitations,
+		})
+	}
+	fmt.Fprintf(&b, "%d facts due for review. Confirm, revise, or delete each one.", len(items))
+	return textResult(b.String(), false), out, nil
+}
+
+func (ms *MemoryServer) HandleCite(ctx context.Context, _ *mcp.CallToolRequest, input CiteInput) (*mcp.CallToolResult, CiteResult, error) {
+	ct, ok := ms.store.(memstore.Citer)
+	if !ok {
+		return textResult("Error: this store does not carry the document corpus (Postgres required)", true), CiteResult{}, nil
+	}
+	if input.FactID <= 0 || input.ChunkID <= 0 {
+		return textResult("Error: fact_id and chunk_id must be positive integers", true), CiteResult{}, nil
+	}
+	c, err := ct.CiteChunk(ctx, input.FactID, input.ChunkID)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), CiteResult{}, nil
+	}
+	return textResult(fmt.Sprintf("Fact %d now cites %s (citation %d).", c.FactID, c.Location(), c.ID), false),
+		CiteResult{Status: "cited", Citation: *c}, nil
+}
+
+func (ms *MemoryServer) HandleCitedCode(ctx context.Context, _ *mcp.CallToolRequest, input CitedCodeInput) (*mcp.CallToolResult, CitedCodeResult, error) {
+	ct, ok := ms.store.(memstore.Citer)
+	if !ok {
+		return textResult("Error: this store does not carry the document corpus (Postgres required)", true), CitedCodeResult{}, nil
+	}
+	if input.ID <= 0 {
+		return textResult("Error: id must be a positive integer", true), CitedCodeResult{}, nil
+	}
+	f, err := ms.store.Get(ctx, input.ID)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), CitedCodeResult{}, nil
+	}
+	if f == nil {
+		return textResult(fmt.Sprintf("Error: fact %d not found", input.ID), true), CitedCodeResult{}, nil
+	}
+
+	// Accept and remove only citations of this fact, so a stray ID cannot
+	// touch another fact's evidence.
+	cs, err := ct.Citations(ctx, f.ID)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), CitedCodeResult{}, nil
+	}
+	own := make(map[int64]bool, len(cs))
+	for _, c := range cs {
+		own[c.ID] = true
+	}
+	out := CitedCodeResult{Fact: FactResult{
+		ID: f.ID, Subject: f.Subject, Category: f.Category, Kind: f.Kind, Subsystem: f.Subsystem,
+		Content: f.Content, UseCount: f.UseCount, ConfirmedCount: f.ConfirmedCount, SupersededBy: f.SupersededBy,
+	}}
+	for _, id := range input.Accept {
+		if !own[id] {
+			return textResult(fmt.Sprintf("Error: citation %d does not belong to fact %d", id, f.ID), true), CitedCodeResult{}, nil
+		}
+		if _, err := ct.AcceptCitation(ctx, id); err != nil {
+			return textResult(fmt.Sprintf("Error accepting citation %d: %v", id, err), true), CitedCodeResult{}, nil
+		}
+		out.Accepted = append(out.Accepted, id)
+	}
+	for _, id := range input.Remove {
+		if !own[id] {
+			return textResult(fmt.Sprintf("Error: citation %d does not belong to fact %d", id, f.ID), true), CitedCodeResult{}, nil
+		}
+		if err := ct.DeleteCitation(ctx, id); err != nil {
+			return textResult(fmt.Sprintf("Error removing citation %d: %v", id, err), true), CitedCodeResult{}, nil
+		}
+		out.Removed = append(out.Removed, id)
+	}
+	if len(out.Accepted) > 0 || len(out.Removed) > 0 {
+		if cs, err = ct.Citations(ctx, f.ID); err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), CitedCodeResult{}, nil
+		}
+	}
+	if cs == nil {
+		cs = []memstore.Citation{}
+	}
+	out.Citations = cs
+
+	var b strings.Builder
+	if len(out.Accepted) > 0 {
+		fmt.Fprintf(&b, "Accepted %d: %v\n", len(out.Accepted), out.Accepted)
+	}
+	if len(out.Removed) > 0 {
+		fmt.Fprintf(&b, "Removed %d: %v\n", len(out.Removed), out.Removed)
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[id=%d] %s | %s | %s\n  %s\n", f.ID, f.Subject, f.Category, historyStatus(*f, time.Now()), f.Content)
+	if len(cs) == 0 {
+		b.WriteString("\nThis fact cites no code.")
+		return textResult(b.String(), false), out, nil
+	}
+	stale := 0
+	for _, c := range cs {
+		fmt.Fprintf(&b, "\n[citation=%d] %s %s, cited %s\n", c.ID, strings.ToUpper(c.Status), c.Location(), c.CitedAt.Format("2006-01-02"))
+		if c.Stale() {
+			stale++
+		}
+		if c.Current == nil {
+			b.WriteString("  the cited code is gone from the latest ingest\n")
+			continue
+		}
+		if c.Status == memstore.CitationChanged {
+			fmt.Fprintf(&b, "  now at L%d-%d:\n", c.Current.LineStart, c.Current.LineEnd)
+		}
+		fmt.Fprintf(&b, "```\n%s\n```\n", strings.TrimRight(c.Current.Content, "\n"))
+	}
+	fmt.Fprintf(&b, "\n%d citations, %d stale.", len(cs), stale)
+	if stale > 0 {
+		b.WriteString(" Accept a changed citation once the fact is checked against the new code; revise the fact if it no longer holds; remove citations that no longer apply.")
+	}
+	return textResult(b.String(), false), out, nil
+}
+
+// bulkPreviewSample caps how many matched facts a bulk update preview shows.
+const bulkPreviewSample = 10
+
+// HandleBulkUpdate handles the memory_bulk_update tool. Without a confirm
+// token it only previews; the apply must carry the preview's token, so an
+// agent cannot skip looking at what it is about to change.
+func (ms *MemoryServer) HandleBulkUpdate(ctx context.Context, _ *mcp.CallToolRequest, input BulkUpdateInput) (*mcp.CallToolResult, BulkUpdateResult, error) {
+	bu, ok := ms.store.(memstore.BulkUpdater)
+	if !ok {
+		return textResult("Error: this store does not support bulk updates", true), BulkUpdateResult{}, nil
+	}
+	req := memstore.BulkUpdateRequest{
+		Filter: memstore.QueryOpts{
+			Subject:         input.Subject,
+			Category:        input.Category,
+			Kind:            input.Kind,
+			Subsystem:       input.Subsystem,
+			OnlyActive:      !input.IncludeHistory,
+			MetadataFilters: metadataFilters(input.Metadata),
+			Provenance:      memstore.ProvenanceFilter{Source: input.Source, SessionID: input.SessionID},
+		},
+		Change: memstore.BulkChange{
+			Kind:      input.SetKind,
+			Subsystem: input.SetSubsystem,
+			Metadata:  input.SetMetadata,
+		},
+		DryRun: input.Confirm == "",
+		Token:  input.Confirm,
+	}
+	if input.SetSubject != "" {
+		req.Change.Subject = &input.SetSubject
+	}
+	if input.SetCategory != "" {
+		req.Change.Category = &input.SetCategory
+	}
+
+	res, err := bu.BulkUpdate(ctx, req)
+	if errors.Is(err, memstore.ErrBulkPreviewStale) {
+		return textResult("The facts matching this filter changed since the preview, or the change differs from what was previewed. Call again without confirm to preview the update as it stands now.", true), BulkUpdateResult{}, nil
+	}
+	if err != nil {
+		return textResult(storeErrorText("Error", err), true), BulkUpdateResult{}, nil
+	}
+	out := BulkUpdateResult{Matched: res.Matched, IDs: res.IDs, Token: res.Token, Applied: res.Applied, AuditID: res.AuditID}
+
+	if res.Applied {
+		return textResult(fmt.Sprintf("Updated %d facts (%s). Recorded as audit entry %d.", res.Matched, req.Change, res.AuditID), false), out, nil
+	}
+	if res.Matched == 0 {
+		return textResult("No facts match this filter; nothing to update.", false), out, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Preview: %d facts match; each would get %s.\n\n", res.Matched, req.Change)
+	sample := res.IDs[:min(len(res.IDs), bulkPreviewSample)]
+	facts, err := ms.store.List(ctx, memstore.QueryOpts{IDs: sample})
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), BulkUpdateResult{}, nil
+	}
+	for _, f := range facts {
+		fmt.Fprintf(&b, "[id=%d] %s | %s", f.ID, f.Subject, f.Category)
+		if f.Kind != "" {
+			fmt.Fprintf(&b, " | kind=%s", f.Kind)
+		}
+		if f.Subsystem != "" {
+			fmt.Fprintf(&b, " | subsystem=%s", f.Subsystem)
+		}
+		fmt.Fprintf(&b, "\n  %s\n", f.Content)
+	}
+	if n := res.Matched - len(facts); n > 0 {
+		fmt.Fprintf(&b, "... and %d more\n", n)
+	}
+	fmt.Fprintf(&b, "\nNothing was changed. To apply, call memory_bulk_update again with the same filter and change and confirm=%q.", res.Token)
+	return textResult(b.String(), false), out, nil
+}
+
+// HandleAlias handles the memory_alias tool.
+func (ms *MemoryServer) HandleAlias(ctx context.Context, _ *mcp.CallToolRequest, input AliasInput) (*mcp.CallToolResult, AliasResult, error) {
+	if err := memstore.CheckAlias(input.Alias, input.Canonical); err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), AliasResult{}, nil
+	}
+	sa, ok := ms.store.(memstore.SubjectAliaser)
+	if !ok {
+		return textResult("Error: this store does not support subject aliases", true), AliasResult{}, nil
+	}
+	a, err := sa.AddAlias(ctx, input.Alias, input.Canonical)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), AliasResult{}, nil
+	}
+	out := AliasResult{Status: "added", Alias: a.Alias, Canonical: a.Canonical}
+	msg := fmt.Sprintf("%q is now an alias of %q.", a.Alias, a.Canonical)
+	if !strings.EqualFold(a.Canonical, input.Canonical) {
+		msg += fmt.Sprintf(" (%q is itself an alias of %q.)", input.Canonical, a.Canonical)
+	}
+	return textResult(msg+" Use memory_rewrite_aliases to move facts already filed under the alias.", false), out, nil
+}
+
+// HandleUnalias handles the memory_unalias tool.
+func (ms *MemoryServer) HandleUnalias(ctx context.Context, _ *mcp.CallToolRequest, input UnaliasInput) (*mcp.CallToolResult, AliasResult, error) {
+	if input.Alias == "" {
+		return textResult("Error: alias is required", true), AliasResult{}, nil
+	}
+	sa, ok := ms.store.(memstore.SubjectAliaser)
+	if !ok {
+		return textResult("Error: this store does not support subject aliases", true), AliasResult{}, nil
+	}
+	err := sa.RemoveAlias(ctx, input.Alias)
+	if errors.Is(err, memstore.ErrAliasNotFound) {
+		return textResult(fmt.Sprintf("Error: %q is not an alias", input.Alias), true), AliasResult{}, nil
+	}
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), AliasResult{}, nil
+	}
+	out := AliasResult{Status: "deleted", Alias: input.Alias}
+	return textResult(fmt.Sprintf("Removed alias %q.", input.Alias), false), out, nil
+}
+
+// HandleListAliases handles the memory_list_aliases tool.
+func (ms *MemoryServer) HandleListAliases(ctx context.Context, _ *mcp.CallToolRequest, _ ListAliasesInput) (*mcp.CallToolResult, ListAliasesResult, error) {
+	sa, ok := ms.store.(memstore.SubjectAliaser)
+	if !ok {
+		return textResult("Error: this store does not support subject aliases", true), ListAliasesResult{}, nil
+	}
+	aliases, err := sa.Aliases(ctx)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), ListAliasesResult{}, nil
+	}
+	if len(aliases) == 0 {
+		return textResult("No subject aliases.", false), ListAliasesResult{}, nil
+	}
+	var b strings.Builder
+	for _, a := range aliases {
+		fmt.Fprintf(&b, "%s -> %s\n", a.Alias, a.Canonical)
+	}
+	fmt.Fprintf(&b, "\n%d alias(es).", len(aliases))
+	return textResult(b.String(), false), ListAliasesResult{Aliases: aliases}, nil
+}
+
+// HandleRewriteAliases handles the memory_rewrite_aliases tool.
+func (ms *MemoryServer) HandleRewriteAliases(ctx context.Context, _ *mcp.CallToolRequest, input RewriteAliasesInput) (*mcp.CallToolResult, RewriteAliasesResult, error) {
+	sa, ok := ms.store.(memstore.SubjectAliaser)
+	if !ok {
+		return textResult("Error: this store does not support subject aliases", true), RewriteAliasesResult{}, nil
+	}
+	res, err := sa.RewriteAliasedFacts(ctx, input.Alias)
+	if errors.Is(err, memstore.ErrAliasNotFound) {
+		return textResult(fmt.Sprintf("Error: %q is not an alias", input.Alias), true), RewriteAliasesResult{}, nil
+	}
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), RewriteAliasesResult{}, nil
+	}
+	out := RewriteAliasesResult{Facts: res.Facts, Subjects: res.Subjects, AuditID: res.AuditID}
+	if res.Facts == 0 {
+		return textResult("No facts are filed under an alias; nothing to rewrite.", false), out, nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Rewrote %d facts to their canonical subject. Recorded as audit entry %d.\n", res.Facts, res.AuditID)
+	aliases := make([]string, 0, len(res.Subjects))
+	for alias := range res.Subjects {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		fmt.Fprintf(&b, "  %s -> %s\n", alias, res.Subjects[alias])
+	}
+	return textResult(b.String(), false), out, nil
+}
+
+// HandlePin handles the memory_pin tool.
+func (ms *MemoryServer) HandlePin(ctx context.Context, _ *mcp.CallToolRequest, input PinInput) (*mcp.CallToolResult, PinResult, error) {
+	p := memstore.Pin{FactID: input.ID, Subject: input.Subject, ProjectPath: input.ProjectPath, Position: input.Position}
+	if err := memstore.CheckPin(p); err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), PinResult{}, nil
+	}
+	pn, ok := ms.store.(memstore.Pinner)
+	if !ok {
+		return textResult("Error: this store does not support pinned facts", true), PinResult{}, nil
+	}
+	pin, err := pn.Pin(ctx, p)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), PinResult{}, nil
+	}
+	out := PinResult{Status: "pinned", FactID: pin.FactID, Subject: pin.Subject, ProjectPath: pin.ProjectPath, Position: pin.Position}
+	scope := "subject " + pin.Subject
+	if pin.ProjectPath != "" {
+		scope = "sessions under " + pin.ProjectPath
+	}
+	return textResult(fmt.Sprintf("Pinned fact %d for %s at position %d.", pin.FactID, scope, pin.Position), false), out, nil
+}
+
+// HandleUnpin handles the memory_unpin tool.
+func (ms *MemoryServer) HandleUnpin(ctx context.Context, _ *mcp.CallToolRequest, input UnpinInput) (*mcp.CallToolResult, PinResult, error) {
+	if input.ID <= 0 {
+		return textResult("Error: id must be a positive integer", true), PinResult{}, nil
+	}
+	pn, ok := ms.store.(memstore.Pinner)
+	if !ok {
+		return textResult("Error: this store does not support pinned facts", true), PinResult{}, nil
+	}
+	err := pn.Unpin(ctx, input.ID)
+	if errors.Is(err, memstore.ErrPinNotFound) {
+		return textResult(fmt.Sprintf("Error: fact %d is not pinned", input.ID), true), PinResult{}, nil
+	}
+	if err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), PinResult{}, nil
+	}
+	out := PinResult{Status: "unpinned", FactID: input.ID}
+	return textResult(fmt.Sprintf("Unpinned fact %d.", input.ID), false), out, nil
+}
+
+// HandleTag handles the memory_tag tool.
+func (ms *MemoryServer) HandleTag(ctx context.Context, _ *mcp.CallToolRequest, input TagInput) (*mcp.CallToolResult, TagResult, error) {
+	if len(input.Add) == 0 && len(input.Remove) == 0 {
+		return textResult("Error: add or remove is required", true), TagResult{}, nil
+	}
+	if len(input.Add) > 0 {
+		if err := ms.store.TagFact(ctx, input.ID, input.Add...); err != nil {
+			return textResult(fmt.Sprintf("Error tagging fact %d: %v", input.ID, err), true), TagResult{}, nil
+		}
+	}
+	if len(input.Remove) > 0 {
+		if err := ms.store.UntagFact(ctx, input.ID, input.Remove...); err != nil {
+			return textResult(fmt.Sprintf("Error untagging fact %d: %v", input.ID, err), true), TagResult{}, nil
+		}
+	}
+	f, err := ms.store.Get(ctx, input.ID)
+	if err != nil || f == nil {
+		return textResult(fmt.Sprintf("Error reading fact %d: %v", input.ID, err), true), TagResult{}, nil
+	}
+	tags := f.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	msg := fmt.Sprintf("Fact %d has no tags.", input.ID)
+	if len(tags) > 0 {
+		msg = fmt.Sprintf("Fact %d tags: %s.", input.ID, strings.Join(tags, ", "))
+	}
+	return textResult(msg, false), TagResult{ID: input.ID, Tags: tags}, nil
+}
+
+// HandleTags handles the memory_tags tool.
+func (ms *MemoryServer) HandleTags(ctx context.Context, _ *mcp.CallToolRequest, input TagsInput) (*mcp.CallToolResult, TagsResult, error) {
+	counts, err := ms.store.TagCounts(ctx, input.Subject)
+	if err != nil {
+		return textResult(fmt.Sprintf("Error counting tags: %v", err), true), TagsResult{}, nil
+	}
+	if len(counts) == 0 {
+		return textResult("No tags in use.", false), TagsResult{Tags: []memstore.TagCount{}}, nil
+	}
+	var b strings.Builder
+	for _, tc := range counts {
+		fmt.Fprintf(&b, "%s (%d)\n", tc.Tag, tc.Count)
+	}
+	return textResult(b.String(), false), TagsResult{Tags: counts}, nil
+}
+
 func (ms *MemoryServer) HandleConfirm(ctx context.Context, _ *mcp.CallToolRequest, input ConfirmInput) (*mcp.CallToolResult, ConfirmResult, error) {
 	if input.ID <= 0 {
 		return textResult("Error: id must be a positive integer", true), ConfirmResult{}, nil
@@ -1561,7 +2718,7 @@ func (ms *MemoryServer) HandleUpdate(ctx context.Context, _ *mcp.CallToolRequest
 	}
 
 	if err := ms.store.UpdateMetadata(ctx, input.ID, input.Metadata); err != nil {
-		return textResult(fmt.Sprintf("Error: %v", err), true), UpdateResult{}, nil
+		return textResult(storeErrorText("Error", err), true), UpdateResult{}, nil
 	}
 
 	out := UpdateResult{Status: "updated", ID: input.ID}
@@ -1595,9 +2752,23 @@ func (ms *MemoryServer) HandleTaskCreate(ctx context.Context, _ *mcp.CallToolReq
 		meta["project"] = input.Project
 	}
 	if input.Due != "" {
-		meta["due"] = input.Due
+		if _, err := memstore.ParseDue(input.Due); err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), TaskCreateResult{}, nil
+		}
+		meta["due"] = strings.TrimSpace(input.Due)
+	}
+	if input.Owner != "" {
+		meta["owner"] = input.Owner
+	}
+	if input.Recur != "" {
+		meta["recur"] = input.Recur
+	}
+	if input.Remind != "" {
+		meta["remind"] = input.Remind
+	}
+	if err := memstore.ScheduleTask(meta, time.Now()); err != nil {
+		return textResult(fmt.Sprintf("Error: %v", err), true), TaskCreateResult{}, nil
 	}
-
 	metaJSON, err := json.Marshal(meta)
 	if err != nil {
 		return textResult(fmt.Sprintf("Error encoding metadata: %v", err), true), TaskCreateResult{}, nil
@@ -1612,29 +2783,41 @@ func (ms *MemoryServer) HandleTaskCreate(ctx context.Context, _ *mcp.CallToolReq
 		}
 	}
 
-	id, err := ms.store.Insert(ctx, memstore.Fact{
+	// Blockers are checked before the insert, so a bad ID creates nothing.
+	id, err := memstore.CreateTask(ctx, ms.store, memstore.Fact{
 		Content:   input.Content,
 		Subject:   "todo",
 		Category:  "note",
 		Kind:      "task",
 		Metadata:  metaJSON,
 		Embedding: emb,
-	})
+	}, input.BlockedBy)
 	if err != nil {
-		return textResult(fmt.Sprintf("Error creating task: %v", err), true), TaskCreateResult{}, nil
+		return textResult(storeErrorText("Error creating task", err), true), TaskCreateResult{}, nil
 	}
 
 	out := TaskCreateResult{Status: "created", ID: id, Scope: input.Scope, Priority: priority}
-	return textResult(fmt.Sprintf("Created task (id=%d, scope=%s, priority=%s).", id, input.Scope, priority), false), out, nil
+	msg := fmt.Sprintf("Created task (id=%d, scope=%s, priority=%s).", id, input.Scope, priority)
+	if len(input.BlockedBy) > 0 {
+		msg += fmt.Sprintf(" Blocked by %s.", joinIDs(input.BlockedBy))
+	}
+	if remindAt, ok := meta["remind_at"].(string); ok && meta["surface"] == nil {
+		msg += fmt.Sprintf(" Due %s; surfaces at %s.", meta["due"], remindAt)
+	}
+	return textResult(msg, false), out, nil
 }
 
 func (ms *MemoryServer) HandleTaskUpdate(ctx context.Context, _ *mcp.CallToolRequest, input TaskUpdateInput) (*mcp.CallToolResult, TaskUpdateResult, error) {
 	if input.ID <= 0 {
 		return textResult("Error: id must be a positive integer", true), TaskUpdateResult{}, nil
 	}
-	if !validTaskStatuses[input.Status] {
+	if input.Status != "" && !validTaskStatuses[input.Status] {
 		return textResult(fmt.Sprintf("Error: status must be one of: pending, in_progress, completed, cancelled (got %q)", input.Status), true), TaskUpdateResult{}, nil
 	}
+	changesFields := input.Status != "" || input.Note != "" || input.Due != "" || input.Owner != "" || input.Recur != "" || input.Remind != ""
+	if !changesFields && len(input.AddBlockedBy) == 0 && len(input.RemoveBlockedBy) == 0 {
+		return textResult("Error: give at least one of status, note, due, owner, recur, remind, add_blocked_by or remove_blocked_by", true), TaskUpdateResult{}, nil
+	}
 
 	// Verify the fact is a task.
 	fact, err := ms.store.Get(ctx, input.ID)
@@ -1649,34 +2832,85 @@ func (ms *MemoryServer) HandleTaskUpdate(ctx context.Context, _ *mcp.CallToolReq
 	if kind, _ := meta.String("kind"); kind != "task" {
 		return textResult(fmt.Sprintf("Error: fact %d is not a task", input.ID), true), TaskUpdateResult{}, nil
 	}
+	oldStatus, _ := meta.String("status")
 
-	patch := map[string]any{"status": input.Status}
-
-	// Remove surface flag on terminal statuses.
-	if input.Status == "completed" || input.Status == "cancelled" {
-		patch["surface"] = nil
+	// Dependencies first, so a rejected cycle leaves the task untouched.
+	for _, blocker := range input.AddBlockedBy {
+		if err := memstore.AddTaskDependency(ctx, ms.store, input.ID, blocker); err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), TaskUpdateResult{}, nil
+		}
 	}
-
-	if input.Note != "" {
-		patch["note"] = input.Note
+	for _, blocker := range input.RemoveBlockedBy {
+		if err := memstore.RemoveTaskDependency(ctx, ms.store, input.ID, blocker); err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), TaskUpdateResult{}, nil
+		}
 	}
 
-	if err := ms.store.UpdateMetadata(ctx, input.ID, patch); err != nil {
-		return textResult(fmt.Sprintf("Error: %v", err), true), TaskUpdateResult{}, nil
+	var outcome memstore.TaskUpdateOutcome
+	if changesFields {
+		outcome, err = memstore.UpdateTask(ctx, ms.store, input.ID, memstore.TaskUpdate{
+			Status: input.Status,
+			Note:   input.Note,
+			Owner:  input.Owner,
+			Due:    input.Due,
+			Recur:  input.Recur,
+			Remind: input.Remind,
+		})
+		if err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), TaskUpdateResult{}, nil
+		}
 	}
 
 	out := TaskUpdateResult{Status: "updated", ID: input.ID, NewStatus: input.Status}
-	return textResult(fmt.Sprintf("Task %d → %s.", input.ID, input.Status), false), out, nil
+	var b strings.Builder
+	if input.Status != "" {
+		out.OldStatus = oldStatus
+		fmt.Fprintf(&b, "Task %d → %s.", input.ID, input.Status)
+	} else {
+		fmt.Fprintf(&b, "Updated task %d.", input.ID)
+	}
+	for _, t := range outcome.Unblocked {
+		out.Unblocked = append(out.Unblocked, taskResult(t))
+	}
+	if len(outcome.Unblocked) > 0 {
+		fmt.Fprintf(&b, "\n\nNow unblocked:\n")
+		for _, t := range outcome.Unblocked {
+			b.WriteString(formatTask(t))
+		}
+	}
+	if next := outcome.Next; next != nil {
+		r := taskResult(*next)
+		out.Next = &r
+		fmt.Fprintf(&b, "\n\nNext occurrence: task %d, due %s.", next.Fact.ID, next.Due)
+	}
+	return textResult(strings.TrimRight(b.String(), "\n"), false), out, nil
+}
+
+// taskStates lists the values memory_task_list accepts for state.
+var taskStates = map[string]bool{
+	string(memstore.TaskStateBlocked):    true,
+	string(memstore.TaskStateReady):      true,
+	string(memstore.TaskStateInProgress): true,
+	string(memstore.TaskStateDone):       true,
+	"overdue":                            true,
+	"due_soon":                           true,
 }
 
 func (ms *MemoryServer) HandleTaskList(ctx context.Context, _ *mcp.CallToolRequest, input TaskListInput) (*mcp.CallToolResult, TaskListResult, error) {
+	if input.State != "" && !taskStates[input.State] {
+		return textResult(fmt.Sprintf("Error: state must be one of: blocked, ready, in_progress, done, overdue, due_soon (got %q)", input.State), true), TaskListResult{}, nil
+	}
+
+	// The flat list keeps its historical pending default; a state filter or
+	// a board covers every open task instead.
 	status := input.Status
-	if status == "" {
+	if status == "" && input.State == "" && input.GroupBy == "" {
 		status = "pending"
 	}
 
-	filters := []memstore.MetadataFilter{
-		{Key: "status", Op: "=", Value: status},
+	var filters []memstore.MetadataFilter
+	if status != "" {
+		filters = append(filters, memstore.MetadataFilter{Key: "status", Op: "=", Value: status})
 	}
 	if input.Scope != "" {
 		filters = append(filters, memstore.MetadataFilter{Key: "scope", Op: "=", Value: input.Scope})
@@ -1684,45 +2918,121 @@ func (ms *MemoryServer) HandleTaskList(ctx context.Context, _ *mcp.CallToolReque
 	if input.Project != "" {
 		filters = append(filters, memstore.MetadataFilter{Key: "project", Op: "=", Value: input.Project})
 	}
+	if input.Owner != "" {
+		filters = append(filters, memstore.MetadataFilter{Key: "owner", Op: "=", Value: input.Owner})
+	}
 
-	facts, err := ms.store.List(ctx, memstore.QueryOpts{
-		Kind:            "task",
-		OnlyActive:      true,
-		MetadataFilters: filters,
-	})
+	all, err := memstore.LoadTasks(ctx, ms.store, filters...)
 	if err != nil {
 		return textResult(fmt.Sprintf("Error: %v", err), true), TaskListResult{}, nil
 	}
+	var tasks []memstore.Task
+	for _, t := range all {
+		switch input.State {
+		case "":
+			if status == "" && t.State == memstore.TaskStateDone {
+				continue
+			}
+		case "overdue":
+			if !t.Overdue {
+				continue
+			}
+		case "due_soon":
+			if !t.DueSoon {
+				continue
+			}
+		default:
+			if string(t.State) != input.State {
+				continue
+			}
+		}
+		tasks = append(tasks, t)
+	}
 
-	if len(facts) == 0 {
+	if len(tasks) == 0 {
 		return textResult("No tasks found.", false), TaskListResult{}, nil
 	}
 
 	var b strings.Builder
-	taskResults := make([]TaskResult, 0, len(facts))
-	for _, f := range facts {
-		row := FormatTaskRow(f)
-		b.WriteString(row)
+	out := TaskListResult{Tasks: make([]TaskResult, 0, len(tasks))}
+	for _, t := range tasks {
+		out.Tasks = append(out.Tasks, taskResult(t))
+	}
+	if input.GroupBy != "" {
+		groups, err := memstore.GroupTasks(tasks, input.GroupBy)
+		if err != nil {
+			return textResult(fmt.Sprintf("Error: %v", err), true), TaskListResult{}, nil
+		}
+		for _, g := range groups {
+			group := TaskGroupResult{Name: g.Name}
+			fmt.Fprintf(&b, "## %s (%d)\n", g.Name, len(g.Tasks))
+			for _, t := range g.Tasks {
+				group.Tasks = append(group.Tasks, taskResult(t))
+				b.WriteString(formatTask(t))
+			}
+			b.WriteString("\n")
+			out.Groups = append(out.Groups, group)
+		}
+		fmt.Fprintf(&b, "%d task(s).", len(tasks))
+	} else {
+		for _, t := range tasks {
+			b.WriteString(formatTask(t))
+		}
+		fmt.Fprintf(&b, "\n%d task(s).", len(tasks))
+	}
+	return textResult(b.String(), false), out, nil
+}
 
-		meta := decodeMetadata(f.Metadata)
-		statusVal, _ := meta.String("status")
-		scopeVal, _ := meta.String("scope")
-		priorityVal, _ := meta.String("priority")
-		dueVal, _ := meta.String("due")
-
-		taskResults = append(taskResults, TaskResult{
-			ID:       f.ID,
-			Status:   statusVal,
-			Scope:    scopeVal,
-			Priority: priorityVal,
-			Content:  f.Content,
-			Due:      dueVal,
-		})
+// taskResult converts a loaded task to its structured output.
+func taskResult(t memstore.Task) TaskResult {
+	r := TaskResult{
+		ID:        t.Fact.ID,
+		Status:    t.Status,
+		Scope:     t.Scope,
+		Priority:  t.Priority,
+		Content:   t.Fact.Content,
+		Due:       t.Due,
+		Project:   t.Project,
+		Owner:     t.Owner,
+		State:     string(t.State),
+		Overdue:   t.Overdue,
+		Recur:     t.Recur,
+		DueSoon:   t.DueSoon,
+		BlockedBy: t.BlockedBy,
+		Blocks:    t.Blocks,
 	}
-	fmt.Fprintf(&b, "\n%d task(s).", len(facts))
+	if t.RemindAt != nil {
+		r.RemindAt = t.RemindAt.Format(time.RFC3339)
+	}
+	return r
+}
 
-	out := TaskListResult{Tasks: taskResults}
-	return textResult(b.String(), false), out, nil
+// formatTask renders a loaded task as FormatTaskRow does, flagged when it
+// is overdue, due soon, not yet surfaced, or waiting on open blockers.
+func formatTask(t memstore.Task) string {
+	row := strings.TrimSuffix(FormatTaskRow(t.Fact), "\n")
+	if t.Overdue {
+		row += " [overdue]"
+	}
+	if t.DueSoon {
+		row += " [due soon]"
+	}
+	if t.RemindAt != nil && t.State != memstore.TaskStateDone && time.Now().Before(*t.RemindAt) {
+		row += " [surfaces " + t.RemindAt.Format(time.DateOnly) + "]"
+	}
+	if t.State == memstore.TaskStateBlocked {
+		row += " [blocked by " + joinIDs(t.BlockedBy) + "]"
+	}
+	return row + "\n"
+}
+
+// joinIDs renders fact IDs as a comma-separated list.
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ", ")
 }
 
 // FormatTaskRow renders a single task fact for display in HandleTaskList output.
@@ -1733,14 +3043,22 @@ func FormatTaskRow(f memstore.Fact) string {
 	status, _ := meta.String("status")
 	scope, _ := meta.String("scope")
 	priority, _ := meta.String("priority")
+	owner, _ := meta.String("owner")
 	due, _ := meta.String("due")
+	recur, _ := meta.String("recur")
 
 	var b strings.Builder
 	fmt.Fprintf(&b, "[id=%d] [%s] %s (scope=%s, priority=%s",
 		f.ID, status, f.Content, scope, priority)
+	if owner != "" {
+		fmt.Fprintf(&b, ", owner=%s", owner)
+	}
 	if due != "" {
 		fmt.Fprintf(&b, ", due=%s", due)
 	}
+	if recur != "" {
+		fmt.Fprintf(&b, ", recur=%s", recur)
+	}
 	b.WriteString(")\n")
 	return b.String()
 }
@@ -1948,6 +3266,9 @@ func (ms *MemoryServer) HandleGetContext(ctx context.Context, _ *mcp.CallToolReq
 		limit = 50
 	}
 
+	// Facts pinned for the project lead the context whatever the task says.
+	pinned := ms.contextPins(ctx, input.Subject, input.CWD)
+
 	// Hybrid search for the task description; fall back to FTS if no embedder configured.
 	tun := ms.tunables()
 	mode, threshold := ms.resolveRerank(input.RerankMode, input.Threshold)
@@ -1985,6 +3306,9 @@ func (ms *MemoryServer) HandleGetContext(ctx context.Context, _ *mcp.CallToolReq
 	}
 
 	seen := make(map[int64]bool)
+	for _, f := range pinned {
+		seen[f.ID] = true
+	}
 
 	// Load invariants and failure_mode facts for each touched subsystem.
 	var invariants, failureModes []memstore.Fact
@@ -2040,7 +3364,7 @@ func (ms *MemoryServer) HandleGetContext(ctx context.Context, _ *mcp.CallToolReq
 		}
 	}
 
-	total := len(invariants) + len(failureModes) + len(triggers) + len(relevant)
+	total := len(pinned) + len(invariants) + len(failureModes) + len(triggers) + len(relevant)
 	if total == 0 {
 		return textResult("No relevant context found for this task.", false), GetContextResult{}, nil
 	}
@@ -2109,6 +3433,25 @@ func (ms *MemoryServer) HandleGetContext(ctx context.Context, _ *mcp.CallToolReq
 	}
 	fmt.Fprintln(&b)
 
+	pinnedResults := make([]FactResult, 0, len(pinned))
+	if len(pinned) > 0 {
+		fmt.Fprintf(&b, "--- pinned (always apply in this project) ---\n")
+		for _, f := range pinned {
+			writeContextFact(&b, f)
+			pinnedResults = append(pinnedResults, FactResult{
+				ID:             f.ID,
+				Subject:        f.Subject,
+				Category:       f.Category,
+				Kind:           f.Kind,
+				Subsystem:      f.Subsystem,
+				Content:        f.Content,
+				UseCount:       f.UseCount,
+				ConfirmedCount: f.ConfirmedCount,
+				Metadata:       decodeMetadata(f.Metadata),
+			})
+		}
+	}
+
 	if len(invariants) > 0 {
 		fmt.Fprintf(&b, "--- invariants (always apply when touching these subsystems) ---\n")
 		for _, f := range invariants {
@@ -2165,6 +3508,7 @@ func (ms *MemoryServer) HandleGetContext(ctx context.Context, _ *mcp.CallToolReq
 	out := GetContextResult{
 		Task:         task,
 		Subject:      input.Subject,
+		Pinned:       pinnedResults,
 		Invariants:   invariantResults,
 		FailureModes: failureModeResults,
 		Triggers:     triggerResults,
@@ -2174,6 +3518,33 @@ func (ms *MemoryServer) HandleGetContext(ctx context.Context, _ *mcp.CallToolReq
 	return textResult(b.String(), false), out, nil
 }
 
+// pinnedContextBudget caps the characters of pinned facts memory_get_context
+// includes, on top of its other sections.
+const pinnedContextBudget = 1500
+
+// contextPins returns the facts pinned for the project named by subject, or
+// derived from cwd when subject is empty, in pin order and within
+// pinnedContextBudget. A pin that would overrun the budget is left out
+// along with those after it.
+func (ms *MemoryServer) contextPins(ctx context.Context, subject, cwd string) []memstore.Fact {
+	aliases := memstore.LoadAliases(ctx, ms.store)
+	project := subject
+	if project == "" && cwd != "" {
+		project = memstore.ProjectNameFromCWD(cwd)
+	}
+	var (
+		out   []memstore.Fact
+		total int
+	)
+	for _, p := range memstore.PinsFor(ctx, ms.store, aliases.Canonical(project), cwd, aliases) {
+		if total += len(p.Fact.Content); total > pinnedContextBudget {
+			break
+		}
+		out = append(out, p.Fact)
+	}
+	return out
+}
+
 // writeContextFact writes a single fact line for the get_context output.
 func writeContextFact(b *strings.Builder, f memstore.Fact) {
 	fmt.Fprintf(b, "[id=%d] %s | %s", f.ID, f.Subject, f.Category)
@@ -2446,6 +3817,17 @@ func (ms *MemoryServer) HandleRateContext(ctx context.Context, _ *mcp.CallToolRe
 	return textResult("Feedback recorded.", false), out, nil
 }
 
+// storeErrorText formats the error from a store write as prefix: err. When
+// the metadata failed its kind's schema, the schema follows, so the caller
+// can correct the metadata and retry without looking it up.
+func storeErrorText(prefix string, err error) string {
+	var se *memstore.SchemaError
+	if errors.As(err, &se) && len(se.Schema) > 0 {
+		return fmt.Sprintf("%s: %v\nSchema for kind %q: %s", prefix, err, se.Kind, se.Schema)
+	}
+	return fmt.Sprintf("%s: %v", prefix, err)
+}
+
 func textResult(text string, isError bool) *mcp.CallToolResult {
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
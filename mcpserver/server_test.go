@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/matthewjhunter/go-embedding"
 	"github.com/matthewjhunter/memstore"
@@ -183,6 +185,69 @@ func TestHandleStore_WithMetadata(t *testing.T) {
 	}
 }
 
+func TestHandleStore_MetadataSchemaViolation(t *testing.T) {
+	srv, store, _ := newTestServer(t)
+	ctx := context.Background()
+
+	result, _, err := srv.HandleStore(ctx, nil, mcpserver.StoreInput{
+		Content:  "Load frontend conventions in web repos",
+		Subject:  "global",
+		Category: "project",
+		Kind:     "trigger",
+		Metadata: mcpserver.Metadata{"signal_type": "cwd_pattern", "pattern": "**/web/**", "load_subsystem": "frontend"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Fatal("expected a malformed trigger to be rejected")
+	}
+	// The error names the missing key and carries the schema to fix it by.
+	text := resultText(t, result)
+	for _, want := range []string{`missing properties ["signal"]`, `Schema for kind "trigger"`, `"cwd_pattern"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("error text missing %q: %s", want, text)
+		}
+	}
+	if facts, _ := store.List(ctx, memstore.QueryOpts{Subject: "global"}); len(facts) != 0 {
+		t.Errorf("rejected fact was stored: %+v", facts)
+	}
+}
+
+func TestHandleStore_TTL(t *testing.T) {
+	srv, store, _ := newTestServer(t)
+	ctx := context.Background()
+
+	result, out, err := srv.HandleStore(ctx, nil, mcpserver.StoreInput{
+		Content: "PR #42 is waiting on review",
+		Subject: "memstore",
+		TTL:     "3d",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("HandleStore: %v %s", err, resultText(t, result))
+	}
+	f, err := store.Get(ctx, out.ID)
+	if err != nil || f == nil || f.ExpiresAt == nil {
+		t.Fatalf("stored fact = %+v (%v), want ExpiresAt set", f, err)
+	}
+	if d := time.Until(*f.ExpiresAt); d < 71*time.Hour || d > 72*time.Hour {
+		t.Errorf("ExpiresAt is %v away, want ~3 days", d)
+	}
+
+	result, _, _ = srv.HandleStore(ctx, nil, mcpserver.StoreInput{
+		Content: "both", Subject: "memstore", TTL: "1h", ExpiresAt: "2030-01-01T00:00:00Z",
+	})
+	if !result.IsError {
+		t.Error("ttl with expires_at should be rejected")
+	}
+	result, _, _ = srv.HandleStore(ctx, nil, mcpserver.StoreInput{
+		Content: "bad", Subject: "memstore", TTL: "soon",
+	})
+	if !result.IsError {
+		t.Error("unparseable ttl should be rejected")
+	}
+}
+
 func TestHandleStore_Duplicate(t *testing.T) {
 	srv, _, emb := newTestServer(t)
 	ctx := context.Background()
@@ -652,6 +717,215 @@ func TestHandleDelete_InvalidID(t *testing.T) {
 	}
 }
 
+func TestHandleTrashRestorePurge(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	id := insertFact(t, store, emb, "Deleted by mistake", "test", "note")
+	srv.HandleDelete(ctx, nil, mcpserver.DeleteInput{ID: id})
+
+	result, out, _ := srv.HandleTrash(ctx, nil, mcpserver.TrashInput{})
+	if result.IsError || len(out.Facts) != 1 || out.Facts[0].ID != id || out.Facts[0].DeletedAt == "" {
+		t.Fatalf("trash = %+v: %s", out, resultText(t, result))
+	}
+
+	result, _, _ = srv.HandleRestore(ctx, nil, mcpserver.RestoreInput{ID: id})
+	if result.IsError {
+		t.Fatalf("restore: %s", resultText(t, result))
+	}
+	if count, _ := store.ActiveCount(ctx); count != 1 {
+		t.Errorf("expected the fact back after restore, active count %d", count)
+	}
+
+	srv.HandleDelete(ctx, nil, mcpserver.DeleteInput{ID: id})
+	if result, _, _ := srv.HandlePurge(ctx, nil, mcpserver.PurgeInput{}); !result.IsError {
+		t.Error("expected an error purging without older_than or all")
+	}
+	if _, out, _ := srv.HandlePurge(ctx, nil, mcpserver.PurgeInput{OlderThan: "1d"}); out.Purged != 0 {
+		t.Errorf("purge older_than=1d removed %d freshly deleted facts", out.Purged)
+	}
+	if _, out, _ := srv.HandlePurge(ctx, nil, mcpserver.PurgeInput{All: true}); out.Purged != 1 {
+		t.Errorf("purge all = %d, want 1", out.Purged)
+	}
+	if result, _, _ := srv.HandleTrash(ctx, nil, mcpserver.TrashInput{}); !strings.Contains(resultText(t, result), "empty") {
+		t.Errorf("expected an empty trash, got: %s", resultText(t, result))
+	}
+}
+
+func TestHandleRevise(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	id := insertFact(t, store, emb, "Go version is 1.24", "test", "project")
+
+	if result, _, _ := srv.HandleRevise(ctx, nil, mcpserver.ReviseInput{ID: id}); !result.IsError {
+		t.Error("expected an error revising without content")
+	}
+
+	result, out, _ := srv.HandleRevise(ctx, nil, mcpserver.ReviseInput{
+		ID: id, Content: "Go version is 1.25", Metadata: mcpserver.Metadata{"source": "go.mod"},
+	})
+	if result.IsError {
+		t.Fatalf("revise: %s", resultText(t, result))
+	}
+	if out.Status != "revised" || out.OldID != id || out.NewID == id || out.OldContent != "Go version is 1.24" {
+		t.Errorf("revise result = %+v", out)
+	}
+
+	f, _ := store.Get(ctx, out.NewID)
+	if f == nil || f.Content != "Go version is 1.25" || f.Category != "project" {
+		t.Fatalf("new version = %+v", f)
+	}
+	if len(f.Embedding) == 0 {
+		t.Error("expected the new version to be embedded")
+	}
+	if count, _ := store.ActiveCount(ctx); count != 1 {
+		t.Errorf("active count = %d, want 1", count)
+	}
+
+	result, _, _ = srv.HandleRevise(ctx, nil, mcpserver.ReviseInput{ID: id, Content: "Go version is 1.26"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "superseded") {
+		t.Errorf("expected an already-superseded error, got: %s", resultText(t, result))
+	}
+}
+
+// stubGenerator returns a fixed reply and records the prompt it was given.
+type stubGenerator struct {
+	reply  string
+	prompt string
+}
+
+func (g *stubGenerator) Model() string { return "stub" }
+
+func (g *stubGenerator) Generate(_ context.Context, prompt string) (string, error) {
+	g.prompt = prompt
+	return g.reply, nil
+}
+
+func TestHandleMerge(t *testing.T) {
+	gen := &stubGenerator{reply: "Deploys ship on Tuesday mornings."}
+	srv, store, emb := newTestServerWithConfig(t, mcpserver.Config{Generator: gen})
+	ctx := context.Background()
+
+	a := insertFact(t, store, emb, "Deploys go out on Tuesdays", "deploy", "project")
+	b := insertFact(t, store, emb, "Releases ship Tuesday mornings", "deploy", "project")
+
+	if result, _, _ := srv.HandleMerge(ctx, nil, mcpserver.MergeInput{IDs: []int64{a}}); !result.IsError {
+		t.Error("expected an error merging a single fact")
+	}
+
+	// Dry run with drafted content changes nothing.
+	result, out, _ := srv.HandleMerge(ctx, nil, mcpserver.MergeInput{IDs: []int64{a, b}, DryRun: true})
+	if result.IsError {
+		t.Fatalf("dry run: %s", resultText(t, result))
+	}
+	if out.Status != "dry_run" || !out.Drafted || out.Content != gen.reply || len(out.Sources) != 2 || out.ID != 0 {
+		t.Errorf("dry run result = %+v", out)
+	}
+	if !strings.Contains(gen.prompt, "Releases ship Tuesday mornings") {
+		t.Errorf("draft prompt is missing source content:\n%s", gen.prompt)
+	}
+	if count, _ := store.ActiveCount(ctx); count != 2 {
+		t.Errorf("active count after dry run = %d, want 2", count)
+	}
+
+	result, out, _ = srv.HandleMerge(ctx, nil, mcpserver.MergeInput{IDs: []int64{a, b}, Content: "Deploys ship Tuesday mornings."})
+	if result.IsError {
+		t.Fatalf("merge: %s", resultText(t, result))
+	}
+	if out.Status != "merged" || out.Drafted || out.ID == 0 {
+		t.Errorf("merge result = %+v", out)
+	}
+	f, _ := store.Get(ctx, out.ID)
+	if f == nil || f.Content != "Deploys ship Tuesday mornings." || len(f.Embedding) == 0 {
+		t.Fatalf("merged fact = %+v, want the given content, embedded", f)
+	}
+	if count, _ := store.ActiveCount(ctx); count != 1 {
+		t.Errorf("active count after merge = %d, want 1", count)
+	}
+
+	result, _, _ = srv.HandleMerge(ctx, nil, mcpserver.MergeInput{IDs: []int64{a, out.ID}, Content: "again"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "superseded") {
+		t.Errorf("expected an already-superseded error, got: %s", resultText(t, result))
+	}
+}
+
+func TestHandleMerge_NoGenerator(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	a := insertFact(t, store, emb, "one", "deploy", "project")
+	b := insertFact(t, store, emb, "two", "deploy", "project")
+	result, _, _ := srv.HandleMerge(ctx, nil, mcpserver.MergeInput{IDs: []int64{a, b}})
+	if !result.IsError || !strings.Contains(resultText(t, result), "content is required") {
+		t.Errorf("expected a content-required error, got: %s", resultText(t, result))
+	}
+}
+
+func TestHandleContradictions(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	result, _, _ := srv.HandleContradictions(ctx, nil, mcpserver.ContradictionsInput{})
+	if result.IsError || !strings.Contains(resultText(t, result), "No open contradictions") {
+		t.Errorf("empty queue: %s", resultText(t, result))
+	}
+
+	a := insertFact(t, store, emb, "retries are capped at 3", "retries", "project")
+	b := insertFact(t, store, emb, "retries are unlimited", "retries", "project")
+	link, err := store.LinkFacts(ctx, a, b, memstore.LinkContradicts, true, "capped vs unlimited", map[string]any{"status": memstore.ContradictionOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, out, _ := srv.HandleContradictions(ctx, nil, mcpserver.ContradictionsInput{})
+	if result.IsError || len(out.Contradictions) != 1 {
+		t.Fatalf("queue = %+v: %s", out, resultText(t, result))
+	}
+	if c := out.Contradictions[0]; c.LinkID != link || c.A.ID != a || c.B.ID != b || c.Reason != "capped vs unlimited" {
+		t.Errorf("entry = %+v", c)
+	}
+
+	result, out, _ = srv.HandleContradictions(ctx, nil, mcpserver.ContradictionsInput{Dismiss: []int64{link}})
+	if result.IsError || len(out.Dismissed) != 1 || len(out.Contradictions) != 0 {
+		t.Errorf("after dismissal = %+v: %s", out, resultText(t, result))
+	}
+
+	other, _ := store.LinkFacts(ctx, a, b, "related", false, "", nil)
+	if result, _, _ := srv.HandleContradictions(ctx, nil, mcpserver.ContradictionsInput{Dismiss: []int64{other}}); !result.IsError {
+		t.Error("dismissing a non-contradicts link succeeded")
+	}
+}
+
+func TestHandleReviewQueue(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	result, out, _ := srv.HandleReviewQueue(ctx, nil, mcpserver.ReviewQueueInput{})
+	if result.IsError || len(out.Items) != 0 || !strings.Contains(resultText(t, result), "No facts are due") {
+		t.Errorf("empty queue: %s", resultText(t, result))
+	}
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	stale := insertFactFull(t, store, emb, memstore.Fact{Content: "builds use make", Subject: "ci", Category: "project", Kind: "invariant", CreatedAt: old})
+	insertFact(t, store, emb, "fresh fact", "ci", "project")
+
+	result, out, _ = srv.HandleReviewQueue(ctx, nil, mcpserver.ReviewQueueInput{MinAge: "30d"})
+	if result.IsError || len(out.Items) != 1 {
+		t.Fatalf("queue = %+v: %s", out, resultText(t, result))
+	}
+	if it := out.Items[0]; it.ID != stale || it.Kind != "invariant" || len(it.Reasons) == 0 || it.Since == "" {
+		t.Errorf("item = %+v", it)
+	}
+	if !strings.Contains(resultText(t, result), "kind invariant") {
+		t.Errorf("text is missing the reasons: %s", resultText(t, result))
+	}
+
+	if result, _, _ := srv.HandleReviewQueue(ctx, nil, mcpserver.ReviewQueueInput{MinAge: "whenever"}); !result.IsError {
+		t.Error("an invalid min_age was accepted")
+	}
+}
+
 // --- memory_status tests ---
 
 func TestHandleStatus_Empty(t *testing.T) {
@@ -954,6 +1228,32 @@ func TestHandleHistory_BySubject(t *testing.T) {
 	}
 }
 
+func TestHandleHistory_Provenance(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	id := insertFactFull(t, store, emb, memstore.Fact{Content: "extracted", Subject: "prov", Category: "test",
+		Provenance: memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "sess-1", TurnFirst: "a", TurnLast: "b"}})
+	insertFact(t, store, emb, "typed", "prov", "test")
+
+	result, out, err := srv.HandleHistory(ctx, nil, mcpserver.HistoryInput{Subject: "prov"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "provenance: extraction session=sess-1 turns=a..b") {
+		t.Errorf("expected provenance line, got: %s", text)
+	}
+	if len(out.Entries) != 2 || out.Entries[0].ID != id || out.Entries[0].Provenance == nil ||
+		out.Entries[0].Provenance.SessionID != "sess-1" || out.Entries[1].Provenance.Source != memstore.SourceManual {
+		t.Errorf("entries = %+v", out.Entries)
+	}
+
+	_, list, _ := srv.HandleList(ctx, nil, mcpserver.ListInput{Source: memstore.SourceExtraction})
+	if len(list.Facts) != 1 || list.Facts[0].ID != id {
+		t.Errorf("memory_list(source extraction) = %+v, want only %d", list.Facts, id)
+	}
+}
+
 func TestHandleHistory_NeitherIDNorSubject(t *testing.T) {
 	srv, _, _ := newTestServer(t)
 	result, _, _ := srv.HandleHistory(context.Background(), nil, mcpserver.HistoryInput{})
@@ -1089,6 +1389,96 @@ func TestHandleUpdate_Basic(t *testing.T) {
 	}
 }
 
+func TestHandleBulkUpdate(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	a := insertFact(t, store, emb, "ci runs on push", "build", "note")
+	b := insertFact(t, store, emb, "ci caches modules", "build", "note")
+	insertFact(t, store, emb, "tabs, not spaces", "style", "note")
+
+	input := mcpserver.BulkUpdateInput{Subject: "build", SetSubject: "ci"}
+	result, preview, err := srv.HandleBulkUpdate(ctx, nil, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("preview: %s", resultText(t, result))
+	}
+	if preview.Applied || preview.Matched != 2 || !strings.Contains(resultText(t, result), "confirm=") {
+		t.Fatalf("preview = %+v\n%s", preview, resultText(t, result))
+	}
+	if f, _ := store.Get(ctx, a); f.Subject != "build" {
+		t.Fatalf("preview wrote: subject = %q", f.Subject)
+	}
+
+	// Applying a different change than the one previewed is refused.
+	wrong := input
+	wrong.SetSubject, wrong.Confirm = "cd", preview.Token
+	if result, _, _ := srv.HandleBulkUpdate(ctx, nil, wrong); !result.IsError {
+		t.Fatalf("mismatched confirm applied: %s", resultText(t, result))
+	}
+
+	input.Confirm = preview.Token
+	result, applied, err := srv.HandleBulkUpdate(ctx, nil, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || !applied.Applied || applied.AuditID == 0 {
+		t.Fatalf("apply = %+v\n%s", applied, resultText(t, result))
+	}
+	for _, id := range []int64{a, b} {
+		if f, _ := store.Get(ctx, id); f.Subject != "ci" {
+			t.Errorf("fact %d subject = %q, want ci", id, f.Subject)
+		}
+	}
+
+	if result, _, _ := srv.HandleBulkUpdate(ctx, nil, mcpserver.BulkUpdateInput{SetSubject: "ci"}); !result.IsError {
+		t.Error("expected error for an unfiltered bulk update")
+	}
+}
+
+func TestHandleAliases(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	id := insertFact(t, store, emb, "the fork adds a redis cache", "memstore-fork", "note")
+
+	result, added, err := srv.HandleAlias(ctx, nil, mcpserver.AliasInput{Alias: "memstore-fork", Canonical: "memstore"})
+	if err != nil || result.IsError {
+		t.Fatalf("alias: %v %s", err, resultText(t, result))
+	}
+	if added.Canonical != "memstore" {
+		t.Errorf("alias = %+v, want canonical memstore", added)
+	}
+	if result, _, _ := srv.HandleAlias(ctx, nil, mcpserver.AliasInput{Alias: "memstore", Canonical: "memstore"}); !result.IsError {
+		t.Error("expected error for a self-alias")
+	}
+
+	_, list, err := srv.HandleListAliases(ctx, nil, mcpserver.ListAliasesInput{})
+	if err != nil || len(list.Aliases) != 1 {
+		t.Fatalf("list = %+v, %v; want 1 alias", list, err)
+	}
+
+	result, rewritten, err := srv.HandleRewriteAliases(ctx, nil, mcpserver.RewriteAliasesInput{})
+	if err != nil || result.IsError {
+		t.Fatalf("rewrite: %v %s", err, resultText(t, result))
+	}
+	if rewritten.Facts != 1 || !strings.Contains(resultText(t, result), "memstore-fork -> memstore") {
+		t.Errorf("rewrite = %+v\n%s", rewritten, resultText(t, result))
+	}
+	if f, _ := store.Get(ctx, id); f.Subject != "memstore" {
+		t.Errorf("rewritten fact subject = %q, want memstore", f.Subject)
+	}
+
+	if result, _, _ := srv.HandleUnalias(ctx, nil, mcpserver.UnaliasInput{Alias: "memstore-fork"}); result.IsError {
+		t.Fatalf("unalias: %s", resultText(t, result))
+	}
+	if result, _, _ := srv.HandleUnalias(ctx, nil, mcpserver.UnaliasInput{Alias: "memstore-fork"}); !result.IsError {
+		t.Error("expected error removing a missing alias")
+	}
+}
+
 func TestHandleUpdate_EmptyMetadata(t *testing.T) {
 	srv, _, _ := newTestServer(t)
 	result, _, _ := srv.HandleUpdate(context.Background(), nil, mcpserver.UpdateInput{
@@ -1196,6 +1586,33 @@ func TestHandleTaskCreate_InvalidPriority(t *testing.T) {
 	}
 }
 
+func TestHandleTaskCreate_BadBlockerCreatesNothing(t *testing.T) {
+	srv, store, _ := newTestServer(t)
+	ctx := context.Background()
+
+	note, err := store.Insert(ctx, memstore.Fact{Content: "a plain note", Subject: "todo", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, blockers := range [][]int64{{99999}, {note}} {
+		result, _, _ := srv.HandleTaskCreate(ctx, nil, mcpserver.TaskCreateInput{
+			Content:   "Ship the release",
+			Scope:     "claude",
+			BlockedBy: blockers,
+		})
+		if !result.IsError {
+			t.Errorf("blocked_by %v: expected an error", blockers)
+		}
+	}
+	tasks, err := memstore.LoadTasks(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("rejected creates left %d tasks behind", len(tasks))
+	}
+}
+
 // --- memory_task_update tests ---
 
 func TestHandleTaskUpdate_Complete(t *testing.T) {
@@ -1508,6 +1925,99 @@ func TestHandleTaskList_Empty(t *testing.T) {
 	}
 }
 
+func TestHandleTask_Recurring(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+	ctx := context.Background()
+
+	if result, _, _ := srv.HandleTaskCreate(ctx, nil, mcpserver.TaskCreateInput{Content: "x", Scope: "claude", Recur: "fortnightly"}); !result.IsError {
+		t.Error("expected error for an invalid recurrence")
+	}
+
+	result, _, err := srv.HandleTaskCreate(ctx, nil, mcpserver.TaskCreateInput{
+		Content: "Rotate the memstored TLS certs", Scope: "collaborative", Recur: "every 90d",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("create: %v %s", err, resultText(t, result))
+	}
+	if !strings.Contains(resultText(t, result), "surfaces at") {
+		t.Errorf("create should report the reminder time: %s", resultText(t, result))
+	}
+	id := extractTaskID(t, resultText(t, result))
+
+	list, out, _ := srv.HandleTaskList(ctx, nil, mcpserver.TaskListInput{})
+	if text := resultText(t, list); !strings.Contains(text, "recur=every 90d") || !strings.Contains(text, "[surfaces ") {
+		t.Errorf("list should show the schedule: %s", text)
+	}
+	if len(out.Tasks) != 1 || out.Tasks[0].RemindAt == "" {
+		t.Errorf("tasks = %+v, want the reminder time", out.Tasks)
+	}
+
+	result, upd, _ := srv.HandleTaskUpdate(ctx, nil, mcpserver.TaskUpdateInput{ID: id, Status: "completed"})
+	if result.IsError {
+		t.Fatalf("complete: %s", resultText(t, result))
+	}
+	if upd.Next == nil || upd.Next.ID == id || upd.Next.Recur != "every 90d" || upd.Next.Status != "pending" {
+		t.Fatalf("next occurrence = %+v", upd.Next)
+	}
+	if !strings.Contains(resultText(t, result), fmt.Sprintf("Next occurrence: task %d", upd.Next.ID)) {
+		t.Errorf("update text should name the next occurrence: %s", resultText(t, result))
+	}
+}
+
+func TestHandleTask_Dependencies(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+	ctx := context.Background()
+
+	create := func(in mcpserver.TaskCreateInput) int64 {
+		t.Helper()
+		in.Scope = "claude"
+		result, _, err := srv.HandleTaskCreate(ctx, nil, in)
+		if err != nil || result.IsError {
+			t.Fatalf("create %q: %v %s", in.Content, err, resultText(t, result))
+		}
+		return extractTaskID(t, resultText(t, result))
+	}
+	schema := create(mcpserver.TaskCreateInput{Content: "Write the schema"})
+	api := create(mcpserver.TaskCreateInput{Content: "Build the API", BlockedBy: []int64{schema}, Owner: "ana"})
+	create(mcpserver.TaskCreateInput{Content: "Old chore", Due: "2000-01-01"})
+
+	if result, _, _ := srv.HandleTaskCreate(ctx, nil, mcpserver.TaskCreateInput{Content: "x", Scope: "claude", Due: "soon"}); !result.IsError {
+		t.Error("expected error for an unparseable due date")
+	}
+	// schema -> api would be a cycle.
+	result, _, _ := srv.HandleTaskUpdate(ctx, nil, mcpserver.TaskUpdateInput{ID: schema, AddBlockedBy: []int64{api}})
+	if !result.IsError || !strings.Contains(resultText(t, result), "cycle") {
+		t.Errorf("expected cycle error, got: %s", resultText(t, result))
+	}
+
+	result, out, _ := srv.HandleTaskList(ctx, nil, mcpserver.TaskListInput{GroupBy: "state"})
+	text := resultText(t, result)
+	for _, want := range []string{"## blocked (1)", "[blocked by " + strconv.FormatInt(schema, 10) + "]", "## ready (2)", "[overdue]", "owner=ana"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("board missing %q:\n%s", want, text)
+		}
+	}
+	if len(out.Groups) != 2 || out.Groups[0].Name != "blocked" || out.Groups[0].Tasks[0].ID != api {
+		t.Errorf("groups = %+v, want api alone in blocked", out.Groups)
+	}
+
+	result, _, _ = srv.HandleTaskList(ctx, nil, mcpserver.TaskListInput{State: "overdue"})
+	if text := resultText(t, result); !strings.Contains(text, "Old chore") || strings.Contains(text, "schema") {
+		t.Errorf("overdue filter: %s", text)
+	}
+
+	result, upd, _ := srv.HandleTaskUpdate(ctx, nil, mcpserver.TaskUpdateInput{ID: schema, Status: "completed"})
+	if result.IsError {
+		t.Fatalf("complete: %s", resultText(t, result))
+	}
+	if len(upd.Unblocked) != 1 || upd.Unblocked[0].ID != api || upd.OldStatus != "pending" {
+		t.Errorf("update result = %+v, want api unblocked", upd)
+	}
+	if !strings.Contains(resultText(t, result), "Now unblocked") {
+		t.Errorf("expected unblocked tasks in text: %s", resultText(t, result))
+	}
+}
+
 func TestHandleList_MetadataFilter(t *testing.T) {
 	srv, store, _ := newTestServer(t)
 	ctx := context.Background()
@@ -1812,6 +2322,50 @@ func insertFactFull(t *testing.T, store *memstore.SQLiteStore, embedder *mockEmb
 	return id
 }
 
+func TestHandlePins(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	rule := insertFact(t, store, emb, "never commit generated protobufs", "memstore", "project")
+	insertFact(t, store, emb, "the release pipeline signs every tag", "memstore", "project")
+
+	result, pinned, err := srv.HandlePin(ctx, nil, mcpserver.PinInput{ID: rule, Subject: "memstore"})
+	if err != nil || result.IsError {
+		t.Fatalf("pin: %v %s", err, resultText(t, result))
+	}
+	if pinned.Position != 1 {
+		t.Errorf("pin = %+v, want position 1", pinned)
+	}
+	if result, _, _ := srv.HandlePin(ctx, nil, mcpserver.PinInput{ID: rule}); !result.IsError {
+		t.Error("expected error for a pin without a subject or path")
+	}
+
+	// The pin leads the context though the task does not mention it, and
+	// is not repeated further down.
+	result, got, err := srv.HandleGetContext(ctx, nil, mcpserver.GetContextInput{Task: "release pipeline tags", CWD: "/src/memstore"})
+	if err != nil || result.IsError {
+		t.Fatalf("get_context: %v %s", err, resultText(t, result))
+	}
+	if len(got.Pinned) != 1 || got.Pinned[0].ID != rule {
+		t.Fatalf("pinned = %+v, want fact %d", got.Pinned, rule)
+	}
+	for _, f := range got.Relevant {
+		if f.ID == rule {
+			t.Error("pinned fact repeated in the relevant section")
+		}
+	}
+	if _, got, _ := srv.HandleGetContext(ctx, nil, mcpserver.GetContextInput{Task: "release pipeline tags", Subject: "herald"}); len(got.Pinned) != 0 {
+		t.Errorf("pinned for another subject = %+v, want none", got.Pinned)
+	}
+
+	if result, _, _ := srv.HandleUnpin(ctx, nil, mcpserver.UnpinInput{ID: rule}); result.IsError {
+		t.Fatalf("unpin: %s", resultText(t, result))
+	}
+	if result, _, _ := srv.HandleUnpin(ctx, nil, mcpserver.UnpinInput{ID: rule}); !result.IsError {
+		t.Error("expected error unpinning a fact that is not pinned")
+	}
+}
+
 func TestHandleGetContext_EmptyTask(t *testing.T) {
 	srv, _, _ := newTestServer(t)
 	result, _, _ := srv.HandleGetContext(context.Background(), nil, mcpserver.GetContextInput{})
@@ -2305,3 +2859,153 @@ func TestHandleSuggestAgent_ConfidenceLevels(t *testing.T) {
 		t.Fatalf("expected high confidence for top match, got: %s", text)
 	}
 }
+
+// citingStore adds an in-memory memstore.Citer to a Store, standing in for
+// the Postgres corpus the SQLite test store does not carry.
+type citingStore struct {
+	memstore.Store
+	cites []memstore.Citation
+}
+
+func (c *citingStore) CiteChunk(_ context.Context, factID, chunkID int64) (*memstore.Citation, error) {
+	cit := memstore.Citation{ID: int64(len(c.cites) + 1), FactID: factID, ChunkID: chunkID, Path: "q.go", Status: memstore.CitationCurrent}
+	c.cites = append(c.cites, cit)
+	return &cit, nil
+}
+
+func (c *citingStore) Citations(_ context.Context, factID int64) ([]memstore.Citation, error) {
+	var out []memstore.Citation
+	for _, cit := range c.cites {
+		if cit.FactID == factID {
+			out = append(out, cit)
+		}
+	}
+	return out, nil
+}
+
+func (c *citingStore) StaleCitations(_ context.Context, _ int) ([]memstore.Citation, error) {
+	var out []memstore.Citation
+	for _, cit := range c.cites {
+		if cit.Stale() {
+			out = append(out, cit)
+		}
+	}
+	return out, nil
+}
+
+func (c *citingStore) AcceptCitation(_ context.Context, id int64) (*memstore.Citation, error) {
+	for i := range c.cites {
+		if c.cites[i].ID == id {
+			c.cites[i].Status = memstore.CitationCurrent
+			return &c.cites[i], nil
+		}
+	}
+	return nil, fmt.Errorf("citation %d not found", id)
+}
+
+func (c *citingStore) DeleteCitation(_ context.Context, id int64) error {
+	for i := range c.cites {
+		if c.cites[i].ID == id {
+			c.cites = append(c.cites[:i], c.cites[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("citation %d not found", id)
+}
+
+func TestHandleCitedCode(t *testing.T) {
+	ctx := context.Background()
+	_, store, emb := newTestServer(t)
+	id := insertFact(t, store, emb, "Drain waits for in-flight jobs", "queue", "project")
+	other := insertFact(t, store, emb, "Retries are capped", "queue", "project")
+	cs := &citingStore{Store: store}
+	srv := mcpserver.NewMemoryServer(cs, nil)
+
+	if _, _, err := srv.HandleCite(ctx, nil, mcpserver.CiteInput{FactID: id, ChunkID: 40}); err != nil {
+		t.Fatal(err)
+	}
+	cs.CiteChunk(ctx, id, 41)
+	cs.CiteChunk(ctx, other, 42)
+	cs.cites[0].Status = memstore.CitationChanged
+	cs.cites[0].Current = &memstore.DocumentChunk{Content: "func (q *Queue) Drain() { q.wg.Wait() }", LineStart: 12, LineEnd: 12}
+	cs.cites[1].Status = memstore.CitationVanished
+
+	result, out, err := srv.HandleCitedCode(ctx, nil, mcpserver.CitedCodeInput{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := resultText(t, result)
+	for _, want := range []string{"Drain waits for in-flight jobs", "[citation=1] CHANGED", "now at L12-12", "q.wg.Wait()", "[citation=2] VANISHED", "gone from the latest ingest", "2 citations, 2 stale."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if len(out.Citations) != 2 || out.Fact.ID != id {
+		t.Errorf("structured output = %+v", out)
+	}
+
+	_, status, _ := srv.HandleStatus(ctx, nil, mcpserver.StatusInput{})
+	if status.StaleCitedFacts != 1 || status.StaleCitations != 2 {
+		t.Errorf("status = %d facts / %d citations, want 1 / 2", status.StaleCitedFacts, status.StaleCitations)
+	}
+
+	// Another fact's citation cannot be touched through this one.
+	if result, _, _ := srv.HandleCitedCode(ctx, nil, mcpserver.CitedCodeInput{ID: id, Remove: []int64{3}}); !result.IsError {
+		t.Error("removing another fact's citation succeeded")
+	}
+
+	result, out, _ = srv.HandleCitedCode(ctx, nil, mcpserver.CitedCodeInput{ID: id, Accept: []int64{1}, Remove: []int64{2}})
+	if result.IsError {
+		t.Fatalf("accept/remove: %s", resultText(t, result))
+	}
+	if len(out.Citations) != 1 || out.Citations[0].Status != memstore.CitationCurrent || !strings.Contains(resultText(t, result), "1 citations, 0 stale.") {
+		t.Errorf("after accept/remove = %+v\n%s", out.Citations, resultText(t, result))
+	}
+
+	// Stores without the corpus say so.
+	plain, _, _ := newTestServer(t)
+	if result, _, _ := plain.HandleCitedCode(ctx, nil, mcpserver.CitedCodeInput{ID: id}); !result.IsError {
+		t.Error("memory_cited_code on SQLite did not report an error")
+	}
+}
+
+func TestHandleTags(t *testing.T) {
+	srv, store, emb := newTestServer(t)
+	ctx := context.Background()
+
+	result, stored, err := srv.HandleStore(ctx, nil, mcpserver.StoreInput{
+		Content: "the feed poller backs off exponentially", Subject: "herald", Tags: []string{"Perf"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("store: %v %s", err, resultText(t, result))
+	}
+	other := insertFact(t, store, emb, "the feed poller logs every fetch", "herald", "project")
+
+	result, tagged, err := srv.HandleTag(ctx, nil, mcpserver.TagInput{ID: other, Add: []string{"perf", "logging"}})
+	if err != nil || result.IsError {
+		t.Fatalf("tag: %v %s", err, resultText(t, result))
+	}
+	if len(tagged.Tags) != 2 {
+		t.Errorf("tags = %v, want [logging perf]", tagged.Tags)
+	}
+	if result, _, _ := srv.HandleTag(ctx, nil, mcpserver.TagInput{ID: other}); !result.IsError {
+		t.Error("expected error for a tag call with nothing to add or remove")
+	}
+
+	_, list, _ := srv.HandleList(ctx, nil, mcpserver.ListInput{Subject: "herald", TagsNone: []string{"logging"}})
+	if len(list.Facts) != 1 || list.Facts[0].ID != stored.ID || len(list.Facts[0].Tags) != 1 {
+		t.Errorf("list without logging = %+v, want fact %d tagged perf", list.Facts, stored.ID)
+	}
+	_, found, _ := srv.HandleSearch(ctx, nil, mcpserver.SearchInput{Query: "feed poller", TagsAll: []string{"perf", "logging"}})
+	if len(found.Results) != 1 || found.Results[0].ID != other {
+		t.Errorf("search with perf and logging = %+v, want fact %d", found.Results, other)
+	}
+
+	if _, tagged, _ := srv.HandleTag(ctx, nil, mcpserver.TagInput{ID: other, Remove: []string{"logging"}}); len(tagged.Tags) != 1 {
+		t.Errorf("tags after remove = %v, want [perf]", tagged.Tags)
+	}
+	_, counts, err := srv.HandleTags(ctx, nil, mcpserver.TagsInput{Subject: "herald"})
+	if err != nil || len(counts.Tags) != 1 || counts.Tags[0] != (memstore.TagCount{Tag: "perf", Count: 2}) {
+		t.Errorf("tag counts = %+v, want perf on 2 facts", counts.Tags)
+	}
+}
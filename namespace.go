@@ -0,0 +1,609 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNamespaceNotEmpty is returned by RenameNamespace and CopyNamespace when
+// the destination already holds data; MergeNamespace combines the two.
+var ErrNamespaceNotEmpty = errors.New("memstore: destination namespace is not empty; merge into it instead")
+
+// NamespaceInfo summarizes one namespace.
+type NamespaceInfo struct {
+	Namespace string `json:"namespace"`
+	Facts     int64  `json:"facts"`     // facts outside the trash, superseded versions included
+	Active    int64  `json:"active"`    // facts neither superseded, expired nor trashed
+	Trashed   int64  `json:"trashed"`   // facts in the trash
+	Links     int64  `json:"links"`     // graph links
+	Documents int64  `json:"documents"` // document-corpus files; always 0 on SQLite
+}
+
+// NamespaceResult reports what a namespace operation touched.
+type NamespaceResult struct {
+	Facts      int   `json:"facts"`                // facts moved, copied or deleted, trash included
+	Links      int   `json:"links"`                // links moved, copied or deleted
+	Documents  int   `json:"documents,omitempty"`  // documents moved or deleted (Postgres)
+	Duplicates int   `json:"duplicates,omitempty"` // MergeNamespace: moved facts superseded by an identical destination fact
+	AuditID    int64 `json:"audit_id,omitempty"`
+}
+
+// DuplicatePolicy says what MergeNamespace does with a moved active fact
+// whose subject and content match an active fact already in the
+// destination, under the same owner.
+type DuplicatePolicy string
+
+const (
+	// DuplicatesSupersede (the default) supersedes the moved fact by the
+	// destination's, keeping its history and links.
+	DuplicatesSupersede DuplicatePolicy = "supersede"
+	// DuplicatesKeep moves duplicates as they are.
+	DuplicatesKeep DuplicatePolicy = "keep"
+)
+
+// ParseDuplicatePolicy validates a duplicate policy name; "" is
+// DuplicatesSupersede.
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(s); p {
+	case "", DuplicatesSupersede:
+		return DuplicatesSupersede, nil
+	case DuplicatesKeep:
+		return p, nil
+	}
+	return "", fmt.Errorf("memstore: unknown duplicate policy %q (want supersede or keep)", s)
+}
+
+// NamespaceAdmin is implemented by stores that can manage whole namespaces.
+// Both built-in backends and the HTTP client implement it. Unlike the rest
+// of the store, these methods act on the namespaces they are given rather
+// than the store's own.
+//
+// Facts and links keep their owner across namespaces: a user belongs to one
+// namespace, so rows are re-owned by the user of the same name in the
+// destination, which is created if need be. On Postgres a user-scoped store
+// acts only on its own user's data; service scope acts on everyone's. Each
+// operation runs in one transaction and is recorded in the audit log of the
+// namespace whose contents changed.
+type NamespaceAdmin interface {
+	// Namespaces lists every namespace holding facts, links or documents,
+	// by name.
+	Namespaces(ctx context.Context) ([]NamespaceInfo, error)
+
+	// RenameNamespace moves everything in from -- facts (trash included),
+	// links, documents, subject aliases, audit entries -- to to, which
+	// must be empty. IDs are kept, and pins and tags go with their facts.
+	RenameNamespace(ctx context.Context, from, to string) (*NamespaceResult, error)
+
+	// CopyNamespace copies from's facts, superseded and trashed versions
+	// included, its links, pins, tags and subject aliases into to, which
+	// must be empty. Supersession chains, link endpoints, pins and tags are
+	// remapped to the new IDs. The document corpus and fact citations are not copied.
+	CopyNamespace(ctx context.Context, from, to string) (*NamespaceResult, error)
+
+	// MergeNamespace moves everything in from into to, which may hold data
+	// already. Duplicates are handled per dup, and an alias both define
+	// keeps the destination's canonical subject. On Postgres, a document
+	// present in both keeps the destination's copy, and citations of the
+	// source's are re-resolved against it.
+	MergeNamespace(ctx context.Context, from, to string, dup DuplicatePolicy) (*NamespaceResult, error)
+
+	// DeleteNamespace permanently removes everything in ns: facts, trash
+	// included, links, pins, tags, documents and subject aliases. Audit
+	// entries are kept.
+	DeleteNamespace(ctx context.Context, ns string) (*NamespaceResult, error)
+}
+
+// namespaceAuditDetail is the Detail recorded for a namespace operation.
+type namespaceAuditDetail struct {
+	From       string          `json:"from,omitempty"`
+	To         string          `json:"to,omitempty"`
+	Duplicates DuplicatePolicy `json:"duplicates,omitempty"`
+	Links      int             `json:"links"`
+	Documents  int             `json:"documents,omitempty"`
+}
+
+// CheckNamespacePair validates the source and destination of a rename, copy
+// or merge.
+func CheckNamespacePair(from, to string) error {
+	if from == to {
+		return fmt.Errorf("memstore: source and destination namespace are both %q", from)
+	}
+	if strings.TrimSpace(to) != to {
+		return fmt.Errorf("memstore: namespace %q has surrounding whitespace", to)
+	}
+	return nil
+}
+
+// Namespaces implements NamespaceAdmin.
+func (s *SQLiteStore) Namespaces(ctx context.Context) ([]NamespaceInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT ns, SUM(facts), SUM(active), SUM(trashed), SUM(links) FROM (
+			SELECT namespace AS ns,
+				SUM(deleted_at IS NULL) AS facts,
+				SUM(deleted_at IS NULL AND superseded_by IS NULL AND (expires_at IS NULL OR expires_at > ?)) AS active,
+				SUM(deleted_at IS NOT NULL) AS trashed,
+				0 AS links
+			FROM memstore_facts GROUP BY namespace
+			UNION ALL
+			SELECT namespace, 0, 0, 0, COUNT(*) FROM memstore_links GROUP BY namespace
+		) GROUP BY ns ORDER BY ns`,
+		time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("memstore: listing namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	var out []NamespaceInfo
+	for rows.Next() {
+		var n NamespaceInfo
+		if err := rows.Scan(&n.Namespace, &n.Facts, &n.Active, &n.Trashed, &n.Links); err != nil {
+			return nil, fmt.Errorf("memstore: scanning namespace: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// RenameNamespace implements NamespaceAdmin.
+func (s *SQLiteStore) RenameNamespace(ctx context.Context, from, to string) (*NamespaceResult, error) {
+	return s.moveNamespace(ctx, from, to, false, "")
+}
+
+// MergeNamespace implements NamespaceAdmin.
+func (s *SQLiteStore) MergeNamespace(ctx context.Context, from, to string, dup DuplicatePolicy) (*NamespaceResult, error) {
+	dup, err := ParseDuplicatePolicy(string(dup))
+	if err != nil {
+		return nil, err
+	}
+	return s.moveNamespace(ctx, from, to, true, dup)
+}
+
+// moveNamespace re-labels from's rows as to's in place: a rename when
+// merge is false (to must be empty), a merge otherwise. The FTS index
+// follows through the update trigger.
+func (s *SQLiteStore) moveNamespace(ctx context.Context, from, to string, merge bool, dup DuplicatePolicy) (*NamespaceResult, error) {
+	if err := CheckNamespacePair(from, to); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkNamespaceDest(ctx, tx, from, to, merge); err != nil {
+		return nil, err
+	}
+	ids, err := namespaceFactIDs(ctx, tx, from)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := remapNamespaceUsers(ctx, tx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pair duplicates before the move, while the two sides are still
+	// told apart by namespace.
+	var dups [][2]int64
+	if merge && dup == DuplicatesSupersede {
+		if dups, err = namespaceDuplicates(ctx, tx, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &NamespaceResult{Facts: len(ids)}
+	ownerExpr, ownerArgs := userIDCase(owners)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE memstore_facts SET namespace = ?, user_id = `+ownerExpr+` WHERE namespace = ?`,
+		append(append([]any{to}, ownerArgs...), from)...,
+	); err != nil {
+		return nil, fmt.Errorf("memstore: moving facts to namespace %q: %w", to, err)
+	}
+	r, err := tx.ExecContext(ctx,
+		`UPDATE memstore_links SET namespace = ?, user_id = `+ownerExpr+` WHERE namespace = ?`,
+		append(append([]any{to}, ownerArgs...), from)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: moving links to namespace %q: %w", to, err)
+	}
+	n, _ := r.RowsAffected()
+	res.Links = int(n)
+	if _, err := tx.ExecContext(ctx, `UPDATE memstore_audit SET namespace = ? WHERE namespace = ?`, to, from); err != nil {
+		return nil, fmt.Errorf("memstore: moving audit log to namespace %q: %w", to, err)
+	}
+	if err := copyNamespaceAliases(ctx, tx, from, to, owners); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memstore_subject_aliases WHERE namespace = ?`, from); err != nil {
+		return nil, fmt.Errorf("memstore: moving aliases to namespace %q: %w", to, err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, d := range dups {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE memstore_facts SET superseded_by = ?, superseded_at = ? WHERE id = ?`, d[1], now, d[0],
+		); err != nil {
+			return nil, fmt.Errorf("memstore: superseding duplicate fact %d: %w", d[0], err)
+		}
+	}
+	res.Duplicates = len(dups)
+
+	action, detail := AuditNamespaceRename, namespaceAuditDetail{From: from, To: to, Links: res.Links}
+	if merge {
+		action, detail.Duplicates = AuditNamespaceMerge, dup
+	}
+	if res.AuditID, err = s.recordAudit(ctx, tx, to, action, detail, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing namespace %s: %w", action, err)
+	}
+	return res, nil
+}
+
+// CopyNamespace implements NamespaceAdmin.
+func (s *SQLiteStore) CopyNamespace(ctx context.Context, from, to string) (*NamespaceResult, error) {
+	if err := CheckNamespacePair(from, to); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkNamespaceDest(ctx, tx, from, to, false); err != nil {
+		return nil, err
+	}
+	owners, err := remapNamespaceUsers(ctx, tx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type source struct {
+		id           int64
+		userID       sql.NullInt64
+		supersededBy sql.NullInt64
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, user_id, superseded_by FROM memstore_facts WHERE namespace = ? ORDER BY id`, from)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: reading namespace %q: %w", from, err)
+	}
+	var facts []source
+	for rows.Next() {
+		var f source
+		if err := rows.Scan(&f.id, &f.userID, &f.supersededBy); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("memstore: scanning fact: %w", err)
+		}
+		facts = append(facts, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memstore: reading namespace %q: %w", from, err)
+	}
+
+	// Copy every fact first, then re-point the chains at the copies.
+	newID := make(map[int64]int64, len(facts))
+	var ids []int64
+	for _, f := range facts {
+		var owner any
+		if f.userID.Valid {
+			owner = owners[f.userID.Int64]
+		}
+		r, err := tx.ExecContext(ctx,
+			`INSERT INTO memstore_facts (namespace, user_id, `+copiedFactColumns+`)
+			 SELECT ?, ?, `+copiedFactColumns+` FROM memstore_facts WHERE id = ?`,
+			to, owner, f.id)
+		if err != nil {
+			return nil, fmt.Errorf("memstore: copying fact %d: %w", f.id, err)
+		}
+		if newID[f.id], err = r.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("memstore: copying fact %d: %w", f.id, err)
+		}
+		ids = append(ids, newID[f.id])
+	}
+	for _, f := range facts {
+		if next, ok := newID[f.supersededBy.Int64]; f.supersededBy.Valid && ok {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE memstore_facts SET superseded_by = ? WHERE id = ?`, next, newID[f.id],
+			); err != nil {
+				return nil, fmt.Errorf("memstore: remapping chain of fact %d: %w", f.id, err)
+			}
+		}
+	}
+
+	type link struct {
+		userID          sql.NullInt64
+		source, target  int64
+		linkType, label string
+		bidirectional   bool
+		metadata        sql.NullString
+		createdAt       string
+	}
+	rows, err = tx.QueryContext(ctx,
+		`SELECT user_id, source_id, target_id, link_type, bidirectional, label, metadata, created_at
+		 FROM memstore_links WHERE namespace = ? ORDER BY id`, from)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: reading links of namespace %q: %w", from, err)
+	}
+	var links []link
+	for rows.Next() {
+		var l link
+		if err := rows.Scan(&l.userID, &l.source, &l.target, &l.linkType, &l.bidirectional, &l.label, &l.metadata, &l.createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("memstore: scanning link: %w", err)
+		}
+		links = append(links, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memstore: reading links of namespace %q: %w", from, err)
+	}
+	res := &NamespaceResult{Facts: len(ids)}
+	for _, l := range links {
+		src, okS := newID[l.source]
+		dst, okT := newID[l.target]
+		if !okS || !okT {
+			continue // an endpoint outside the namespace; nothing to copy it to
+		}
+		var owner any
+		if l.userID.Valid {
+			owner = owners[l.userID.Int64]
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO memstore_links (namespace, user_id, source_id, target_id, link_type, bidirectional, label, metadata, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			to, owner, src, dst, l.linkType, l.bidirectional, l.label, l.metadata, l.createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("memstore: copying link %d->%d: %w", l.source, l.target, err)
+		}
+		res.Links++
+	}
+	if err := copyNamespaceAliases(ctx, tx, from, to, owners); err != nil {
+		return nil, err
+	}
+	if err := copyNamespacePins(ctx, tx, from, newID); err != nil {
+		return nil, err
+	}
+	if err := copyNamespaceTags(ctx, tx, from, newID); err != nil {
+		return nil, err
+	}
+
+	if res.AuditID, err = s.recordAudit(ctx, tx, to, AuditNamespaceCopy, namespaceAuditDetail{From: from, To: to, Links: res.Links}, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing namespace copy: %w", err)
+	}
+	return res, nil
+}
+
+// DeleteNamespace implements NamespaceAdmin.
+func (s *SQLiteStore) DeleteNamespace(ctx context.Context, ns string) (*NamespaceResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := namespaceFactIDs(ctx, tx, ns)
+	if err != nil {
+		return nil, err
+	}
+	r, err := tx.ExecContext(ctx, `DELETE FROM memstore_links WHERE namespace = ?`, ns)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: deleting links of namespace %q: %w", ns, err)
+	}
+	n, _ := r.RowsAffected()
+	res := &NamespaceResult{Facts: len(ids), Links: int(n)}
+	if res.Facts == 0 && res.Links == 0 {
+		return nil, fmt.Errorf("memstore: namespace %q is empty", ns)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM memstore_pins WHERE fact_id IN (SELECT id FROM memstore_facts WHERE namespace = ?)`, ns); err != nil {
+		return nil, fmt.Errorf("memstore: deleting pins of namespace %q: %w", ns, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM memstore_fact_tags WHERE fact_id IN (SELECT id FROM memstore_facts WHERE namespace = ?)`, ns); err != nil {
+		return nil, fmt.Errorf("memstore: deleting tags of namespace %q: %w", ns, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memstore_facts WHERE namespace = ?`, ns); err != nil {
+		return nil, fmt.Errorf("memstore: deleting facts of namespace %q: %w", ns, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memstore_subject_aliases WHERE namespace = ?`, ns); err != nil {
+		return nil, fmt.Errorf("memstore: deleting aliases of namespace %q: %w", ns, err)
+	}
+
+	if res.AuditID, err = s.recordAudit(ctx, tx, ns, AuditNamespaceDelete, namespaceAuditDetail{From: ns, Links: res.Links}, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing namespace delete: %w", err)
+	}
+	return res, nil
+}
+
+// copiedFactColumns are the memstore_facts columns CopyNamespace carries
+// over verbatim: all but the id, the namespace and owner it rewrites, and
+// the chain pointer it remaps.
+var copiedFactColumns = func() string {
+	var cols []string
+	for _, c := range strings.Split(factColumns, ", ") {
+		switch c {
+		case "id", "namespace", "user_id", "superseded_by":
+		default:
+			cols = append(cols, c)
+		}
+	}
+	return strings.Join(cols, ", ")
+}()
+
+// checkNamespaceDest checks that from holds something and, unless merging,
+// that to holds nothing.
+func checkNamespaceDest(ctx context.Context, tx *sql.Tx, from, to string, merge bool) error {
+	count := func(ns string) (int64, error) {
+		var n int64
+		err := tx.QueryRowContext(ctx,
+			`SELECT (SELECT COUNT(*) FROM memstore_facts WHERE namespace = ?) + (SELECT COUNT(*) FROM memstore_links WHERE namespace = ?)`,
+			ns, ns).Scan(&n)
+		if err != nil {
+			return 0, fmt.Errorf("memstore: sizing namespace %q: %w", ns, err)
+		}
+		return n, nil
+	}
+	n, err := count(from)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("memstore: namespace %q is empty", from)
+	}
+	if merge {
+		return nil
+	}
+	if n, err = count(to); err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%w (%q)", ErrNamespaceNotEmpty, to)
+	}
+	return nil
+}
+
+// namespaceFactIDs returns the IDs of every fact in ns, trash included.
+func namespaceFactIDs(ctx context.Context, tx *sql.Tx, ns string) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM memstore_facts WHERE namespace = ? ORDER BY id`, ns)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: reading namespace %q: %w", ns, err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("memstore: scanning fact id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// remapNamespaceUsers maps each user owning rows in from to the user of the
+// same name in to, creating it if need be.
+func remapNamespaceUsers(ctx context.Context, tx *sql.Tx, from, to string) (map[int64]int64, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, name FROM memstore_users WHERE id IN (
+			SELECT user_id FROM memstore_facts WHERE namespace = ?
+			UNION SELECT user_id FROM memstore_links WHERE namespace = ?
+			UNION SELECT user_id FROM memstore_subject_aliases WHERE namespace = ?)`,
+		from, from, from)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: reading owners in namespace %q: %w", from, err)
+	}
+	names := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("memstore: scanning owner: %w", err)
+		}
+		names[id] = name
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memstore: reading owners in namespace %q: %w", from, err)
+	}
+
+	owners := make(map[int64]int64, len(names))
+	for id, name := range names {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO memstore_users (namespace, name, created_at) VALUES (?, ?, datetime('now'))`,
+			to, name); err != nil {
+			return nil, fmt.Errorf("memstore: creating user %q in namespace %q: %w", name, to, err)
+		}
+		var dst int64
+		if err := tx.QueryRowContext(ctx,
+			`SELECT id FROM memstore_users WHERE namespace = ? AND name = ?`, to, name,
+		).Scan(&dst); err != nil {
+			return nil, fmt.Errorf("memstore: resolving user %q in namespace %q: %w", name, to, err)
+		}
+		owners[id] = dst
+	}
+	return owners, nil
+}
+
+// userIDCase renders the owner remapping as a SQL expression over user_id.
+func userIDCase(owners map[int64]int64) (string, []any) {
+	if len(owners) == 0 {
+		return "user_id", nil
+	}
+	expr, args := "CASE user_id", make([]any, 0, 2*len(owners))
+	for src, dst := range owners {
+		expr += " WHEN ? THEN ?"
+		args = append(args, src, dst)
+	}
+	return expr + " ELSE user_id END", args
+}
+
+// copyNamespaceAliases copies the subject aliases of from into to under
+// the remapped owners. An alias the destination already has keeps the
+// destination's canonical subject.
+func copyNamespaceAliases(ctx context.Context, tx *sql.Tx, from, to string, owners map[int64]int64) error {
+	ownerExpr, ownerArgs := userIDCase(owners)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO memstore_subject_aliases (namespace, user_id, alias, canonical, created_at)
+		 SELECT ?, `+ownerExpr+`, alias, canonical, created_at FROM memstore_subject_aliases WHERE namespace = ?`,
+		append(append([]any{to}, ownerArgs...), from)...,
+	); err != nil {
+		return fmt.Errorf("memstore: copying aliases to namespace %q: %w", to, err)
+	}
+	return nil
+}
+
+// namespaceDuplicates pairs each active fact in from with the oldest active
+// fact in to that has the same subject, content and owner name.
+func namespaceDuplicates(ctx context.Context, tx *sql.Tx, from, to string) ([][2]int64, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT s.id, MIN(d.id) FROM memstore_facts s
+		 JOIN memstore_facts d ON d.namespace = ? AND d.subject = s.subject AND d.content = s.content
+		     AND d.superseded_by IS NULL AND d.deleted_at IS NULL
+		 LEFT JOIN memstore_users su ON su.id = s.user_id
+		 LEFT JOIN memstore_users du ON du.id = d.user_id
+		 WHERE s.namespace = ? AND s.superseded_by IS NULL AND s.deleted_at IS NULL
+		   AND su.name IS du.name
+		 GROUP BY s.id ORDER BY s.id`,
+		to, from)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: finding duplicates: %w", err)
+	}
+	defer rows.Close()
+	var pairs [][2]int64
+	for rows.Next() {
+		var p [2]int64
+		if err := rows.Scan(&p[0], &p[1]); err != nil {
+			return nil, fmt.Errorf("memstore: scanning duplicate: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
@@ -0,0 +1,235 @@
+package memstore_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// openNamespaceStores opens stores over one database for each namespace.
+func openNamespaceStores(t *testing.T, namespaces ...string) []*memstore.SQLiteStore {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(on)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	var stores []*memstore.SQLiteStore
+	for _, ns := range namespaces {
+		s, err := memstore.NewSQLiteStore(db, &mockEmbedder{dim: 4}, ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores = append(stores, s)
+	}
+	return stores
+}
+
+// seedNamespace inserts a two-version chain, a linked fact and a trashed
+// fact, returning the IDs of the chain's head and the linked fact.
+func seedNamespace(t *testing.T, s *memstore.SQLiteStore) (head, linked int64) {
+	t.Helper()
+	ctx := context.Background()
+	old := insertTestFact(t, s, "deploys go out on Fridays", "release")
+	head = insertTestFact(t, s, "deploys go out on Tuesdays", "release")
+	if err := s.Supersede(ctx, old, head); err != nil {
+		t.Fatal(err)
+	}
+	linked = insertTestFact(t, s, "the release train is owned by ops", "ops")
+	if _, err := s.LinkFacts(ctx, head, linked, "reference", false, "owner", nil); err != nil {
+		t.Fatal(err)
+	}
+	trashed := insertTestFact(t, s, "scratch note", "scratch")
+	if err := s.Delete(ctx, trashed); err != nil {
+		t.Fatal(err)
+	}
+	return head, linked
+}
+
+func TestSQLiteNamespaces(t *testing.T) {
+	stores := openNamespaceStores(t, "a", "b")
+	seedNamespace(t, stores[0])
+	insertTestFact(t, stores[1], "lone fact", "misc")
+
+	got, err := stores[0].Namespaces(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []memstore.NamespaceInfo{
+		{Namespace: "a", Facts: 3, Active: 2, Trashed: 1, Links: 1},
+		{Namespace: "b", Facts: 1, Active: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Namespaces = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Namespaces[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSQLiteRenameNamespace(t *testing.T) {
+	ctx := context.Background()
+	stores := openNamespaceStores(t, "a", "b", "c")
+	a, b, c := stores[0], stores[1], stores[2]
+	head, linked := seedNamespace(t, a)
+	insertTestFact(t, c, "occupied", "misc")
+
+	if _, err := a.RenameNamespace(ctx, "a", "c"); !errors.Is(err, memstore.ErrNamespaceNotEmpty) {
+		t.Fatalf("rename onto non-empty = %v, want ErrNamespaceNotEmpty", err)
+	}
+	if _, err := a.RenameNamespace(ctx, "a", "a"); err == nil {
+		t.Fatal("rename onto itself succeeded")
+	}
+	if _, err := a.RenameNamespace(ctx, "empty", "d"); err == nil {
+		t.Fatal("rename of an empty namespace succeeded")
+	}
+
+	res, err := a.RenameNamespace(ctx, "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Facts != 4 || res.Links != 1 || res.AuditID == 0 {
+		t.Errorf("result = %+v, want 4 facts and 1 link", res)
+	}
+
+	if n, err := a.ActiveCount(ctx); err != nil || n != 0 {
+		t.Errorf("source ActiveCount = %d, %v; want 0", n, err)
+	}
+	f, err := b.Get(ctx, head)
+	if err != nil || f == nil || f.Namespace != "b" {
+		t.Fatalf("Get(%d) in b = %+v, %v", head, f, err)
+	}
+	hits, err := b.Search(ctx, "Tuesdays", memstore.SearchOpts{MaxResults: 5, OnlyActive: true})
+	if err != nil || len(hits) != 1 || hits[0].Fact.ID != head {
+		t.Errorf("search in b = %+v, %v; want fact %d", hits, err, head)
+	}
+	links, err := b.GetLinks(ctx, head, memstore.LinkOutbound)
+	if err != nil || len(links) != 1 || links[0].TargetID != linked {
+		t.Errorf("links in b = %+v, %v", links, err)
+	}
+	log, err := b.AuditLog(ctx, 1)
+	if err != nil || len(log) != 1 || log[0].Action != memstore.AuditNamespaceRename {
+		t.Errorf("AuditLog in b = %+v, %v", log, err)
+	}
+}
+
+func TestSQLiteCopyNamespace(t *testing.T) {
+	ctx := context.Background()
+	stores := openNamespaceStores(t, "a", "b")
+	a, b := stores[0], stores[1]
+	head, linked := seedNamespace(t, a)
+	if _, err := a.Pin(ctx, memstore.Pin{FactID: linked, Subject: "ops"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := a.CopyNamespace(ctx, "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Facts != 4 || res.Links != 1 {
+		t.Errorf("result = %+v, want 4 facts and 1 link", res)
+	}
+
+	// The source is untouched.
+	if f, err := a.Get(ctx, head); err != nil || f == nil || f.Namespace != "a" {
+		t.Fatalf("source fact = %+v, %v", f, err)
+	}
+
+	hits, err := b.Search(ctx, "Tuesdays", memstore.SearchOpts{MaxResults: 5, OnlyActive: true})
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("search in b = %+v, %v; want 1 hit", hits, err)
+	}
+	copied := hits[0].Fact
+	if copied.ID == head {
+		t.Fatal("copy kept the source ID")
+	}
+	history, err := b.History(ctx, copied.ID, "")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("History of copy = %+v, %v; want 2 versions", history, err)
+	}
+	for _, h := range history {
+		if h.Fact.Namespace != "b" {
+			t.Errorf("history entry %d is in namespace %q", h.Fact.ID, h.Fact.Namespace)
+		}
+	}
+	links, err := b.GetLinks(ctx, copied.ID, memstore.LinkOutbound)
+	if err != nil || len(links) != 1 || links[0].TargetID == linked {
+		t.Fatalf("links of copy = %+v, %v; want one link to the copied target", links, err)
+	}
+	if target, err := b.Get(ctx, links[0].TargetID); err != nil || target == nil || target.Content != "the release train is owned by ops" {
+		t.Errorf("copied link target = %+v, %v", target, err)
+	}
+	if pins, err := b.Pins(ctx); err != nil || len(pins) != 1 || pins[0].Fact.ID != links[0].TargetID {
+		t.Errorf("pins of copy = %+v, %v; want the copied target pinned", pins, err)
+	}
+
+	if _, err := a.CopyNamespace(ctx, "a", "b"); !errors.Is(err, memstore.ErrNamespaceNotEmpty) {
+		t.Errorf("second copy = %v, want ErrNamespaceNotEmpty", err)
+	}
+}
+
+func TestSQLiteMergeNamespace(t *testing.T) {
+	ctx := context.Background()
+	stores := openNamespaceStores(t, "a", "b")
+	a, b := stores[0], stores[1]
+	dupSrc := insertTestFact(t, a, "the wiki lives on the intranet", "wiki")
+	unique := insertTestFact(t, a, "staging resets nightly", "staging")
+	dupDst := insertTestFact(t, b, "the wiki lives on the intranet", "wiki")
+
+	if _, err := a.MergeNamespace(ctx, "a", "b", "sometimes"); err == nil {
+		t.Fatal("merge with an unknown policy succeeded")
+	}
+	res, err := a.MergeNamespace(ctx, "a", "b", memstore.DuplicatesSupersede)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Facts != 2 || res.Duplicates != 1 {
+		t.Errorf("result = %+v, want 2 facts and 1 duplicate", res)
+	}
+
+	f, err := b.Get(ctx, dupSrc)
+	if err != nil || f == nil || f.SupersededBy == nil || *f.SupersededBy != dupDst {
+		t.Errorf("moved duplicate = %+v, %v; want superseded by %d", f, err, dupDst)
+	}
+	if f, err := b.Get(ctx, unique); err != nil || f == nil || f.SupersededBy != nil {
+		t.Errorf("moved unique fact = %+v, %v", f, err)
+	}
+	if n, err := b.ActiveCount(ctx); err != nil || n != 2 {
+		t.Errorf("destination ActiveCount = %d, %v; want 2", n, err)
+	}
+}
+
+func TestSQLiteDeleteNamespace(t *testing.T) {
+	ctx := context.Background()
+	stores := openNamespaceStores(t, "a", "b")
+	a, b := stores[0], stores[1]
+	seedNamespace(t, a)
+	keep := insertTestFact(t, b, "survivor", "misc")
+
+	res, err := b.DeleteNamespace(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Facts != 4 || res.Links != 1 {
+		t.Errorf("result = %+v, want 4 facts and 1 link", res)
+	}
+	if hits, err := a.Search(ctx, "Tuesdays", memstore.SearchOpts{MaxResults: 5}); err != nil || len(hits) != 0 {
+		t.Errorf("search in deleted namespace = %+v, %v", hits, err)
+	}
+	if f, err := b.Get(ctx, keep); err != nil || f == nil {
+		t.Errorf("other namespace lost fact %d: %v", keep, err)
+	}
+	if log, err := a.AuditLog(ctx, 0); err != nil || len(log) != 1 || log[0].Action != memstore.AuditNamespaceDelete {
+		t.Errorf("AuditLog after delete = %+v, %v", log, err)
+	}
+	if _, err := b.DeleteNamespace(ctx, "a"); err == nil {
+		t.Error("deleting an empty namespace succeeded")
+	}
+}
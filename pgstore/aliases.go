@@ -0,0 +1,255 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.SubjectAliaser = (*PostgresStore)(nil)
+
+// errAliasServiceScope is returned by alias writes on a service-scope
+// store: aliases belong to a user.
+var errAliasServiceScope = errors.New("pgstore: subject aliases belong to a user; use a user-scoped store")
+
+// migrateV14 creates the subject alias table. Aliases are unique per user
+// regardless of case.
+func (s *PostgresStore) migrateV14(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_subject_aliases (
+			namespace  TEXT NOT NULL,
+			user_id    BIGINT NOT NULL REFERENCES memstore_users(id),
+			alias      TEXT NOT NULL,
+			canonical  TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_memstore_subject_aliases_alias ON memstore_subject_aliases (namespace, user_id, lower(alias))`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_subject_aliases_canonical ON memstore_subject_aliases (namespace, user_id, canonical)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V14 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// canonicalSubjectExpr resolves the subject placeholder through the alias
+// table of the given namespace and user placeholders, in SQL, so writes
+// pick up the canonical subject inside the statement they are already
+// running.
+func canonicalSubjectExpr(ns, user, subject string) string {
+	return `COALESCE((SELECT canonical FROM memstore_subject_aliases
+		WHERE namespace = ` + ns + ` AND user_id = ` + user + ` AND lower(alias) = lower(` + subject + `::text)), ` + subject + `::text)`
+}
+
+// appendSubjectFilter matches col against subject's alias group: subject
+// itself, its canonical subject, and every alias of that canonical subject.
+// Service scope spans users, whose aliases differ, so it matches subject
+// literally.
+func (s *PostgresStore) appendSubjectFilter(b *queryBuilder, col, subject string) {
+	if s.userID == 0 {
+		b.write(` AND `+col+` = `, subject)
+		return
+	}
+	b.args = append(b.args, subject, s.namespace, s.userID)
+	n := len(b.args)
+	subj, ns, user := fmt.Sprintf("$%d", n-2), fmt.Sprintf("$%d", n-1), fmt.Sprintf("$%d", n)
+	b.q += ` AND ` + col + ` IN (SELECT ` + subj + `::text
+		UNION SELECT canonical FROM memstore_subject_aliases WHERE namespace = ` + ns + ` AND user_id = ` + user + ` AND lower(alias) = lower(` + subj + `::text)
+		UNION SELECT alias FROM memstore_subject_aliases WHERE namespace = ` + ns + ` AND user_id = ` + user + `
+		    AND canonical = ` + canonicalSubjectExpr(ns, user, subj) + `)`
+}
+
+// AddAlias implements memstore.SubjectAliaser.
+func (s *PostgresStore) AddAlias(ctx context.Context, alias, canonical string) (*memstore.SubjectAlias, error) {
+	if err := memstore.CheckAlias(alias, canonical); err != nil {
+		return nil, err
+	}
+	if s.userID == 0 {
+		return nil, errAliasServiceScope
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	requested := canonical
+	if err := tx.QueryRow(ctx, `SELECT `+canonicalSubjectExpr("$1", "$2", "$3"),
+		s.namespace, s.userID, canonical).Scan(&canonical); err != nil {
+		return nil, fmt.Errorf("pgstore: resolving subject %q: %w", canonical, err)
+	}
+	if strings.EqualFold(canonical, alias) {
+		// alias is the canonical subject of requested's group: requested
+		// takes its place and stops being an alias.
+		canonical = requested
+		if _, err := tx.Exec(ctx,
+			`DELETE FROM memstore_subject_aliases WHERE namespace = $1 AND user_id = $2 AND lower(alias) = lower($3)`,
+			s.namespace, s.userID, requested); err != nil {
+			return nil, fmt.Errorf("pgstore: swapping canonical subject: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE memstore_subject_aliases SET canonical = $1 WHERE namespace = $2 AND user_id = $3 AND lower(canonical) = lower($4)`,
+		canonical, s.namespace, s.userID, alias); err != nil {
+		return nil, fmt.Errorf("pgstore: re-pointing aliases of %q: %w", alias, err)
+	}
+	a := memstore.SubjectAlias{Alias: alias}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO memstore_subject_aliases (namespace, user_id, alias, canonical) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (namespace, user_id, lower(alias)) DO UPDATE SET canonical = EXCLUDED.canonical
+		 RETURNING canonical, created_at`,
+		s.namespace, s.userID, alias, canonical).Scan(&a.Canonical, &a.CreatedAt); err != nil {
+		return nil, fmt.Errorf("pgstore: adding alias %q: %w", alias, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing alias: %w", err)
+	}
+	return &a, nil
+}
+
+// RemoveAlias implements memstore.SubjectAliaser.
+func (s *PostgresStore) RemoveAlias(ctx context.Context, alias string) error {
+	if s.userID == 0 {
+		return errAliasServiceScope
+	}
+	ct, err := s.pool.Exec(ctx,
+		`DELETE FROM memstore_subject_aliases WHERE namespace = $1 AND user_id = $2 AND lower(alias) = lower($3)`,
+		s.namespace, s.userID, alias)
+	if err != nil {
+		return fmt.Errorf("pgstore: removing alias %q: %w", alias, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return memstore.ErrAliasNotFound
+	}
+	return nil
+}
+
+// Aliases implements memstore.SubjectAliaser. Service scope lists every
+// user's aliases.
+func (s *PostgresStore) Aliases(ctx context.Context) ([]memstore.SubjectAlias, error) {
+	var b queryBuilder
+	b.write(`SELECT alias, canonical, created_at FROM memstore_subject_aliases WHERE namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	b.q += ` ORDER BY canonical, alias`
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: listing aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var out []memstore.SubjectAlias
+	for rows.Next() {
+		var a memstore.SubjectAlias
+		if err := rows.Scan(&a.Alias, &a.Canonical, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning alias: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// aliasRewriteDetail is the Detail recorded for an AuditAliasRewrite entry.
+type aliasRewriteDetail struct {
+	Alias    string            `json:"alias,omitempty"`
+	Subjects map[string]string `json:"subjects"`
+}
+
+// RewriteAliasedFacts implements memstore.SubjectAliaser. Service scope
+// rewrites every user's facts, each by that user's aliases.
+func (s *PostgresStore) RewriteAliasedFacts(ctx context.Context, alias string) (*memstore.AliasRewriteResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var b queryBuilder
+	b.write(`SELECT f.id, f.subject, a.canonical FROM memstore_facts f
+	         JOIN memstore_subject_aliases a ON a.namespace = f.namespace AND a.user_id = f.user_id AND lower(a.alias) = lower(f.subject)
+	         WHERE f.subject <> a.canonical AND f.namespace = `, s.namespace)
+	s.appendUserFilter(&b, "f.user_id")
+	if alias != "" {
+		var found bool
+		q, args := s.userPredicate(
+			`SELECT EXISTS (SELECT 1 FROM memstore_subject_aliases WHERE namespace = $1 AND lower(alias) = lower($2)`,
+			[]any{s.namespace, alias})
+		if err := tx.QueryRow(ctx, q+`)`, args...).Scan(&found); err != nil {
+			return nil, fmt.Errorf("pgstore: looking up alias %q: %w", alias, err)
+		}
+		if !found {
+			return nil, memstore.ErrAliasNotFound
+		}
+		b.write(` AND lower(a.alias) = lower(`, alias)
+		b.q += `::text)`
+	}
+	b.q += ` ORDER BY f.id FOR UPDATE OF f`
+	rows, err := tx.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: selecting aliased facts: %w", err)
+	}
+	var (
+		ids       []int64
+		canonical []string
+		subjects  = map[string]string{}
+	)
+	for rows.Next() {
+		var (
+			id      int64
+			subject string
+			c       string
+		)
+		if err := rows.Scan(&id, &subject, &c); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("pgstore: scanning aliased fact: %w", err)
+		}
+		ids, canonical = append(ids, id), append(canonical, c)
+		subjects[subject] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: selecting aliased facts: %w", err)
+	}
+
+	res := &memstore.AliasRewriteResult{Facts: len(ids), Subjects: subjects}
+	if len(ids) == 0 {
+		return res, nil
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE memstore_facts f SET subject = m.canonical
+		 FROM unnest($1::bigint[], $2::text[]) AS m(id, canonical)
+		 WHERE f.id = m.id`,
+		ids, canonical); err != nil {
+		return nil, fmt.Errorf("pgstore: rewriting aliased subjects: %w", err)
+	}
+	if res.AuditID, err = s.recordAudit(ctx, tx, s.namespace, s.userID, memstore.AuditAliasRewrite, aliasRewriteDetail{alias, subjects}, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing alias rewrite: %w", err)
+	}
+	return res, nil
+}
+
+// copyNamespaceAliases copies the subject aliases the src owners hold in
+// from into to, re-owned by the matching dst users. An alias the
+// destination already has keeps the destination's canonical subject.
+func copyNamespaceAliases(ctx context.Context, tx pgx.Tx, from, to string, src, dst []int64) error {
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memstore_subject_aliases (namespace, user_id, alias, canonical, created_at)
+		 SELECT $1, m.dst, a.alias, a.canonical, a.created_at
+		 FROM memstore_subject_aliases a
+		 JOIN unnest($2::bigint[], $3::bigint[]) AS m(src, dst) ON m.src = a.user_id
+		 WHERE a.namespace = $4
+		 ON CONFLICT (namespace, user_id, lower(alias)) DO NOTHING`,
+		to, src, dst, from); err != nil {
+		return fmt.Errorf("pgstore: copying aliases to namespace %q: %w", to, err)
+	}
+	return nil
+}
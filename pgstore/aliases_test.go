@@ -0,0 +1,53 @@
+package pgstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestSubjectAliases(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	before, err := store.Insert(ctx, memstore.Fact{Content: "the fork adds a redis cache", Subject: "memstore-fork", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddAlias(ctx, "memstore-fork", "memstore"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := store.Insert(ctx, memstore.Fact{Content: "the fork rewrote the cache layer", Subject: "Memstore-Fork", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err := store.Get(ctx, after); err != nil || f.Subject != "memstore" {
+		t.Fatalf("fact written under an alias: subject %q, %v; want memstore", f.Subject, err)
+	}
+	for _, subject := range []string{"memstore-fork", "memstore"} {
+		if ok, err := store.Exists(ctx, "the fork rewrote the cache layer", subject); err != nil || !ok {
+			t.Errorf("Exists(subject=%s) = %v, %v; want true", subject, ok, err)
+		}
+	}
+	if facts, err := store.List(ctx, memstore.QueryOpts{Subject: "memstore"}); err != nil || len(facts) != 2 {
+		t.Errorf("List(subject=memstore) = %d facts, %v; want 2", len(facts), err)
+	}
+	if a, err := store.AddAlias(ctx, "ms", "memstore-fork"); err != nil || a.Canonical != "memstore" {
+		t.Errorf("AddAlias(ms, memstore-fork) = %+v, %v; want canonical memstore", a, err)
+	}
+
+	res, err := store.RewriteAliasedFacts(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Facts != 1 || res.Subjects["memstore-fork"] != "memstore" {
+		t.Errorf("RewriteAliasedFacts = %+v, want 1 fact from memstore-fork", res)
+	}
+	if f, err := store.Get(ctx, before); err != nil || f.Subject != "memstore" {
+		t.Errorf("rewritten fact: subject %q, %v; want memstore", f.Subject, err)
+	}
+	if _, err := store.ServiceScope().AddAlias(ctx, "a", "b"); err == nil {
+		t.Error("service-scope AddAlias should be refused")
+	}
+}
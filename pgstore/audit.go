@@ -0,0 +1,90 @@
+package pgstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.AuditReader = (*PostgresStore)(nil)
+
+// migrateV13 creates the audit log. user_id is the scope the change was made
+// under; NULL for service-scope changes, which may span users.
+func (s *PostgresStore) migrateV13(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_audit (
+			id         BIGSERIAL PRIMARY KEY,
+			namespace  TEXT NOT NULL,
+			user_id    BIGINT REFERENCES memstore_users(id),
+			action     TEXT NOT NULL,
+			actor      TEXT NOT NULL DEFAULT '',
+			detail     JSONB,
+			fact_ids   BIGINT[],
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_audit_namespace ON memstore_audit (namespace, user_id, id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V13 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// recordAudit writes an audit entry for namespace ns under user scope owner
+// (0 for service scope) inside tx, attributing it to the context's
+// provenance origin, and returns its ID.
+func (s *PostgresStore) recordAudit(ctx context.Context, tx pgx.Tx, ns string, owner int64, action string, detail any, factIDs []int64) (int64, error) {
+	d, err := json.Marshal(detail)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: encoding audit detail: %w", err)
+	}
+	p, _ := memstore.ProvenanceFromContext(ctx)
+	var id int64
+	err = tx.QueryRow(ctx,
+		`INSERT INTO memstore_audit (namespace, user_id, action, actor, detail, fact_ids)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		ns, nullableID(owner), action, p.Origin, d, factIDs).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: recording %s audit entry: %w", action, err)
+	}
+	return id, nil
+}
+
+// AuditLog implements memstore.AuditReader. A user-scoped store sees the
+// entries made under its own scope; service scope sees every entry in the
+// namespace.
+func (s *PostgresStore) AuditLog(ctx context.Context, limit int) ([]memstore.AuditEntry, error) {
+	var b queryBuilder
+	b.write(`SELECT id, action, actor, detail, fact_ids, created_at FROM memstore_audit WHERE namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	b.q += ` ORDER BY id DESC`
+	if limit > 0 {
+		b.write(` LIMIT `, limit)
+	}
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: reading audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var out []memstore.AuditEntry
+	for rows.Next() {
+		var (
+			e      memstore.AuditEntry
+			detail []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Action, &e.Actor, &detail, &e.FactIDs, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning audit entry: %w", err)
+		}
+		if detail != nil {
+			e.Detail = json.RawMessage(detail)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,124 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.BulkUpdater = (*PostgresStore)(nil)
+
+// bulkAuditDetail is the Detail recorded for a memstore.AuditBulkUpdate entry.
+type bulkAuditDetail struct {
+	Filter memstore.QueryOpts  `json:"filter"`
+	Change memstore.BulkChange `json:"change"`
+}
+
+// BulkUpdate implements memstore.BulkUpdater. Matched rows are locked for
+// the length of the transaction, so the preview token is checked against
+// exactly the set that gets updated.
+func (s *PostgresStore) BulkUpdate(ctx context.Context, req memstore.BulkUpdateRequest) (*memstore.BulkResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var b queryBuilder
+	b.write(`SELECT id, kind, metadata FROM memstore_facts`)
+	if err := s.appendListFilter(&b, req.Filter); err != nil {
+		return nil, err
+	}
+	b.q += ` ORDER BY id FOR UPDATE`
+	rows, err := tx.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: selecting facts for bulk update: %w", err)
+	}
+	var (
+		ids   []int64
+		kinds []string
+		meta  [][]byte
+	)
+	for rows.Next() {
+		var id int64
+		var k string
+		var m []byte
+		if err := rows.Scan(&id, &k, &m); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("pgstore: scanning bulk update match: %w", err)
+		}
+		ids = append(ids, id)
+		kinds = append(kinds, k)
+		meta = append(meta, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: selecting facts for bulk update: %w", err)
+	}
+
+	res := &memstore.BulkResult{Matched: len(ids), IDs: ids, Token: memstore.BulkToken(ids, req.Change)}
+	if res.IDs == nil {
+		res.IDs = []int64{}
+	}
+	if req.Token != "" && req.Token != res.Token {
+		return nil, memstore.ErrBulkPreviewStale
+	}
+	if req.DryRun || len(ids) == 0 {
+		return res, nil
+	}
+
+	c := req.Change
+	for i, id := range ids {
+		var u queryBuilder
+		u.q = `UPDATE memstore_facts SET `
+		sep := ``
+		if c.Subject != nil {
+			// File under the canonical subject, by the fact owner's aliases,
+			// when the new one is an alias.
+			u.args = append(u.args, *c.Subject, s.namespace)
+			subj, ns := fmt.Sprintf("$%d", len(u.args)-1), fmt.Sprintf("$%d", len(u.args))
+			u.q += `subject = ` + canonicalSubjectExpr(ns, "memstore_facts.user_id", subj)
+			sep = `, `
+		}
+		for _, f := range []struct {
+			col string
+			v   *string
+		}{{"category", c.Category}, {"kind", c.Kind}, {"subsystem", c.Subsystem}} {
+			if f.v != nil {
+				u.write(sep+f.col+` = `, *f.v)
+				sep = `, `
+			}
+		}
+		merged := meta[i]
+		if len(c.Metadata) > 0 {
+			if merged, err = mergeMetadata(merged, c.Metadata); err != nil {
+				return nil, fmt.Errorf("pgstore: merging metadata for fact %d: %w", id, err)
+			}
+			if merged == nil {
+				merged = []byte("{}")
+			}
+			u.write(sep+`metadata = `, merged)
+		}
+		if err := s.schemas.ValidateBulkChange(id, kinds[i], merged, c); err != nil {
+			return nil, err
+		}
+		u.write(` WHERE id = `, id)
+		if _, err := tx.Exec(ctx, u.q, u.args...); err != nil {
+			return nil, fmt.Errorf("pgstore: bulk updating fact %d: %w", id, err)
+		}
+	}
+
+	if res.AuditID, err = s.recordAudit(ctx, tx, s.namespace, s.userID, memstore.AuditBulkUpdate, bulkAuditDetail{req.Filter, c}, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing bulk update: %w", err)
+	}
+	res.Applied = true
+	return res, nil
+}
@@ -0,0 +1,68 @@
+package pgstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestBulkUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	a, err := store.Insert(ctx, memstore.Fact{Content: "ci runs on push", Subject: "build", Category: "note", Metadata: json.RawMessage(`{"owner":"ops"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := store.Insert(ctx, memstore.Fact{Content: "ci caches modules", Subject: "build", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Insert(ctx, memstore.Fact{Content: "tabs, not spaces", Subject: "style", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ci := "ci"
+	req := memstore.BulkUpdateRequest{
+		Filter: memstore.QueryOpts{Subject: "build"},
+		Change: memstore.BulkChange{Subject: &ci, Metadata: map[string]any{"owner": nil}},
+		DryRun: true,
+	}
+	preview, err := store.BulkUpdate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Applied || !slices.Equal(preview.IDs, []int64{a, b}) {
+		t.Fatalf("preview = %+v", preview)
+	}
+
+	req.DryRun, req.Token = false, "stale"
+	if _, err := store.BulkUpdate(ctx, req); !errors.Is(err, memstore.ErrBulkPreviewStale) {
+		t.Fatalf("stale apply = %v, want ErrBulkPreviewStale", err)
+	}
+	req.Token = preview.Token
+	res, err := store.BulkUpdate(ctx, req)
+	if err != nil || !res.Applied {
+		t.Fatalf("apply = %+v, %v", res, err)
+	}
+
+	f, err := store.Get(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Subject != "ci" || string(f.Metadata) != "{}" {
+		t.Errorf("fact %d = subject %q metadata %s, want ci and {}", a, f.Subject, f.Metadata)
+	}
+	if hits, err := store.Search(ctx, "caches", memstore.SearchOpts{Subject: "ci", MaxResults: 5}); err != nil || len(hits) != 1 {
+		t.Errorf("search under the new subject = %d hits, %v; want 1", len(hits), err)
+	}
+
+	log, err := store.AuditLog(ctx, 10)
+	if err != nil || len(log) != 1 || log[0].ID != res.AuditID || !slices.Equal(log[0].FactIDs, []int64{a, b}) {
+		t.Fatalf("AuditLog = %+v, %v", log, err)
+	}
+}
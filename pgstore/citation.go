@@ -0,0 +1,351 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.Citer = (*PostgresStore)(nil)
+
+// citationColumns is the canonical SELECT list for citation queries.
+const citationColumns = `id, fact_id, document_id, chunk_id, repo_url, commit, path, symbol, receiver, line_start, line_end, file_sha256, content_sha256, status, cited_at, stale_at`
+
+// migrateV12 creates the fact-to-chunk citation table. Chunk ids do not
+// survive a re-ingest (the chunk set is replaced), so chunk_id is only the
+// latest resolution; the snapshot columns are what a citation is re-resolved
+// from. Deleting the document or chunk nulls the reference and keeps the
+// row, so the fact can still be reviewed against what it once cited.
+func (s *PostgresStore) migrateV12(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_fact_citations (
+			id             BIGSERIAL PRIMARY KEY,
+			namespace      TEXT NOT NULL,
+			user_id        BIGINT NOT NULL REFERENCES memstore_users(id),
+			fact_id        BIGINT NOT NULL REFERENCES memstore_facts(id) ON DELETE CASCADE,
+			document_id    BIGINT REFERENCES memstore_documents(id) ON DELETE SET NULL,
+			chunk_id       BIGINT REFERENCES memstore_document_chunks(id) ON DELETE SET NULL,
+			repo_url       TEXT,
+			commit         TEXT NOT NULL DEFAULT '',
+			path           TEXT NOT NULL,
+			symbol         TEXT NOT NULL DEFAULT '',
+			receiver       TEXT NOT NULL DEFAULT '',
+			line_start     INTEGER NOT NULL,
+			line_end       INTEGER NOT NULL,
+			file_sha256    BYTEA NOT NULL,
+			content_sha256 BYTEA NOT NULL,
+			status         TEXT NOT NULL DEFAULT 'current',
+			cited_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			stale_at       TIMESTAMPTZ
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_citations_fact ON memstore_fact_citations (fact_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_citations_document ON memstore_fact_citations (document_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_citations_stale
+			ON memstore_fact_citations (namespace, user_id, stale_at) WHERE status <> 'current'`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V12 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// CiteChunk implements memstore.Citer.
+func (s *PostgresStore) CiteChunk(ctx context.Context, factID, chunkID int64) (*memstore.Citation, error) {
+	f, err := s.Get(ctx, factID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("pgstore: fact %d not found", factID)
+	}
+	chunk, err := s.getDocumentChunk(ctx, chunkID)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.GetDocument(ctx, chunk.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("pgstore: chunk %d not found", chunkID)
+	}
+	if doc.UserID != f.UserID {
+		return nil, fmt.Errorf("pgstore: fact %d and chunk %d belong to different users", factID, chunkID)
+	}
+
+	c := memstore.NewCitation(factID, *doc, *chunk)
+	err = s.pool.QueryRow(ctx,
+		`INSERT INTO memstore_fact_citations
+			(namespace, user_id, fact_id, document_id, chunk_id, repo_url, commit, path,
+			 symbol, receiver, line_start, line_end, file_sha256, content_sha256, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		 RETURNING id, cited_at`,
+		s.namespace, f.UserID, c.FactID, c.DocumentID, c.ChunkID, nullableText(c.RepoURL), c.Commit, c.Path,
+		c.Symbol, c.Receiver, c.LineStart, c.LineEnd, c.FileSHA256, c.ContentSHA256, c.Status,
+	).Scan(&c.ID, &c.CitedAt)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: citing chunk %d from fact %d: %w", chunkID, factID, err)
+	}
+	return &c, nil
+}
+
+// Citations implements memstore.Citer.
+func (s *PostgresStore) Citations(ctx context.Context, factID int64) ([]memstore.Citation, error) {
+	q, args := s.userPredicate(
+		`SELECT `+citationColumns+` FROM memstore_fact_citations WHERE fact_id = $1 AND namespace = $2`,
+		[]any{factID, s.namespace})
+	q += ` ORDER BY id`
+	cs, err := s.queryCitations(ctx, q, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunkIDs []int64
+	for _, c := range cs {
+		if c.ChunkID != 0 {
+			chunkIDs = append(chunkIDs, c.ChunkID)
+		}
+	}
+	if len(chunkIDs) == 0 {
+		return cs, nil
+	}
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+docChunkColumns+` FROM memstore_document_chunks WHERE id = ANY($1)`, chunkIDs)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: loading cited chunks: %w", err)
+	}
+	defer rows.Close()
+	chunks := make(map[int64]*memstore.DocumentChunk)
+	for rows.Next() {
+		ch, err := scanDocumentChunk(rows)
+		if err != nil {
+			return nil, err
+		}
+		chunks[ch.ID] = ch
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range cs {
+		cs[i].Current = chunks[cs[i].ChunkID]
+	}
+	return cs, nil
+}
+
+// StaleCitations implements memstore.Citer.
+func (s *PostgresStore) StaleCitations(ctx context.Context, limit int) ([]memstore.Citation, error) {
+	var b queryBuilder
+	b.q = `SELECT c.` + strings.ReplaceAll(citationColumns, ", ", ", c.") + `
+	       FROM memstore_fact_citations c
+	       JOIN memstore_facts f ON f.id = c.fact_id
+	       WHERE c.status <> 'current' AND f.superseded_by IS NULL` + unexpired("f.") + notDeleted("f.")
+	b.write(` AND c.namespace = `, s.namespace)
+	s.appendUserFilter(&b, "c.user_id")
+	b.q += ` ORDER BY c.stale_at, c.id`
+	if limit > 0 {
+		b.write(` LIMIT `, limit)
+	}
+	return s.queryCitations(ctx, b.q, b.args)
+}
+
+// AcceptCitation implements memstore.Citer.
+func (s *PostgresStore) AcceptCitation(ctx context.Context, id int64) (*memstore.Citation, error) {
+	c, err := s.getCitation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == memstore.CitationVanished || c.ChunkID == 0 || c.DocumentID == 0 {
+		return nil, fmt.Errorf("pgstore: citation %d has vanished and cannot be accepted; delete it instead", id)
+	}
+	chunk, err := s.getDocumentChunk(ctx, c.ChunkID)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.GetDocument(ctx, c.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("pgstore: citation %d: document %d not found", id, c.DocumentID)
+	}
+
+	n := memstore.NewCitation(c.FactID, *doc, *chunk)
+	n.ID = c.ID
+	err = s.pool.QueryRow(ctx,
+		`UPDATE memstore_fact_citations SET
+			repo_url = $2, commit = $3, path = $4, symbol = $5, receiver = $6,
+			line_start = $7, line_end = $8, file_sha256 = $9, content_sha256 = $10,
+			status = $11, cited_at = NOW(), stale_at = NULL
+		 WHERE id = $1
+		 RETURNING cited_at`,
+		id, nullableText(n.RepoURL), n.Commit, n.Path, n.Symbol, n.Receiver,
+		n.LineStart, n.LineEnd, n.FileSHA256, n.ContentSHA256, n.Status,
+	).Scan(&n.CitedAt)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: accepting citation %d: %w", id, err)
+	}
+	n.Current = chunk
+	return &n, nil
+}
+
+// DeleteCitation implements memstore.Citer.
+func (s *PostgresStore) DeleteCitation(ctx context.Context, id int64) error {
+	q, args := s.userPredicate(
+		`DELETE FROM memstore_fact_citations WHERE id = $1 AND namespace = $2`,
+		[]any{id, s.namespace})
+	ct, err := s.pool.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("pgstore: deleting citation %d: %w", id, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("pgstore: citation %d not found", id)
+	}
+	return nil
+}
+
+// staleCitationCounts returns the number of stale citations per fact, for
+// the review queue.
+func (s *PostgresStore) staleCitationCounts(ctx context.Context) (map[int64]int, error) {
+	q, args := s.userPredicate(
+		`SELECT fact_id, COUNT(*) FROM memstore_fact_citations WHERE status <> 'current' AND namespace = $1`,
+		[]any{s.namespace})
+	rows, err := s.pool.Query(ctx, q+` GROUP BY fact_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: counting stale citations: %w", err)
+	}
+	defer rows.Close()
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// resolveCitations re-resolves the citations on a just-replaced document
+// against its new chunks, which must carry their new ids. Citations whose
+// document was deleted and is now back under the same identity are picked
+// up again too. Runs inside UpsertDocument's transaction.
+func resolveCitations(ctx context.Context, tx pgx.Tx, namespace string, owner, docID int64, doc memstore.Document, chunks []memstore.DocumentChunk) error {
+	rows, err := tx.Query(ctx,
+		`SELECT `+citationColumns+` FROM memstore_fact_citations
+		 WHERE document_id = $1
+		    OR (document_id IS NULL AND namespace = $2 AND user_id = $3
+		        AND repo_url IS NOT DISTINCT FROM $4 AND path = $5)`,
+		docID, namespace, owner, nullableText(doc.RepoURL), doc.Path)
+	if err != nil {
+		return fmt.Errorf("pgstore: UpsertDocument: loading citations: %w", err)
+	}
+	cs, err := scanCitations(rows)
+	if err != nil {
+		return fmt.Errorf("pgstore: UpsertDocument: %w", err)
+	}
+
+	for _, c := range cs {
+		i, status := memstore.ResolveCitation(c, chunks)
+		var chunkID *int64
+		if i >= 0 {
+			chunkID = &chunks[i].ID
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE memstore_fact_citations SET
+				document_id = $2, chunk_id = $3, status = $4,
+				stale_at = CASE WHEN $4 = 'current' THEN NULL ELSE COALESCE(stale_at, NOW()) END
+			 WHERE id = $1`,
+			c.ID, docID, chunkID, status,
+		); err != nil {
+			return fmt.Errorf("pgstore: UpsertDocument: resolving citation %d: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// vanishCitations marks every citation on the given documents vanished,
+// ahead of their deletion.
+func vanishCitations(ctx context.Context, tx pgx.Tx, docIDs []int64) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE memstore_fact_citations SET status = 'vanished', stale_at = COALESCE(stale_at, NOW())
+		 WHERE document_id = ANY($1)`, docIDs)
+	if err != nil {
+		return fmt.Errorf("pgstore: marking citations vanished: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) getCitation(ctx context.Context, id int64) (*memstore.Citation, error) {
+	q, args := s.userPredicate(
+		`SELECT `+citationColumns+` FROM memstore_fact_citations WHERE id = $1 AND namespace = $2`,
+		[]any{id, s.namespace})
+	cs, err := s.queryCitations(ctx, q, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("pgstore: citation %d not found", id)
+	}
+	return &cs[0], nil
+}
+
+// getDocumentChunk returns a chunk visible in the caller's scope.
+func (s *PostgresStore) getDocumentChunk(ctx context.Context, id int64) (*memstore.DocumentChunk, error) {
+	q, args := s.userPredicate(
+		`SELECT `+docChunkColumns+` FROM memstore_document_chunks WHERE id = $1 AND namespace = $2`,
+		[]any{id, s.namespace})
+	ch, err := scanDocumentChunk(s.pool.QueryRow(ctx, q, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("pgstore: chunk %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: loading chunk %d: %w", id, err)
+	}
+	return ch, nil
+}
+
+func (s *PostgresStore) queryCitations(ctx context.Context, q string, args []any) ([]memstore.Citation, error) {
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: querying citations: %w", err)
+	}
+	return scanCitations(rows)
+}
+
+func scanCitations(rows pgx.Rows) ([]memstore.Citation, error) {
+	defer rows.Close()
+	var cs []memstore.Citation
+	for rows.Next() {
+		var (
+			c              memstore.Citation
+			docID, chunkID *int64
+			repoURL        *string
+			staleAt        *time.Time
+		)
+		if err := rows.Scan(&c.ID, &c.FactID, &docID, &chunkID, &repoURL, &c.Commit, &c.Path,
+			&c.Symbol, &c.Receiver, &c.LineStart, &c.LineEnd, &c.FileSHA256, &c.ContentSHA256,
+			&c.Status, &c.CitedAt, &staleAt); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning citation: %w", err)
+		}
+		if docID != nil {
+			c.DocumentID = *docID
+		}
+		if chunkID != nil {
+			c.ChunkID = *chunkID
+		}
+		if repoURL != nil {
+			c.RepoURL = *repoURL
+		}
+		c.StaleAt = staleAt
+		cs = append(cs, c)
+	}
+	return cs, rows.Err()
+}
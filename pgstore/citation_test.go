@@ -0,0 +1,87 @@
+package pgstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestCitations_ResolvedOnReingest(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	ds := docStore(t, store)
+	const repo = "https://github.com/matthewjhunter/memstore"
+
+	v1 := "retries are capped at 3\nthe queue drains on shutdown\n"
+	docID := mustUpsert(t, ds, testDoc(repo, "DESIGN.md", v1), chunksOf(v1))
+	chunks, err := ds.GetDocumentChunks(ctx, docID)
+	if err != nil || len(chunks) != 2 {
+		t.Fatalf("GetDocumentChunks = %+v, %v", chunks, err)
+	}
+	factID, err := store.Insert(ctx, memstore.Fact{Content: "retries are capped at three", Subject: "queue", Category: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := store.CiteChunk(ctx, factID, chunks[0].ID)
+	if err != nil {
+		t.Fatalf("CiteChunk: %v", err)
+	}
+	if c.Path != "DESIGN.md" || c.LineStart != 1 || c.Status != memstore.CitationCurrent || c.Commit != "abc123" {
+		t.Errorf("citation = %+v", c)
+	}
+
+	// The cited line moves down but is unchanged: still current, re-anchored.
+	v2 := "# Design\nretries are capped at 3\nthe queue drains on shutdown\n"
+	mustUpsert(t, ds, testDoc(repo, "DESIGN.md", v2), chunksOf(v2))
+	cs, err := store.Citations(ctx, factID)
+	if err != nil || len(cs) != 1 {
+		t.Fatalf("Citations = %+v, %v", cs, err)
+	}
+	if cs[0].Status != memstore.CitationCurrent || cs[0].Current == nil || cs[0].Current.LineStart != 2 {
+		t.Errorf("moved citation = %+v (current %+v), want current at line 2", cs[0], cs[0].Current)
+	}
+
+	// The cited line changes: flagged, and the fact jumps the review queue.
+	v3 := "# Design\nretries are capped at 5\nthe queue drains on shutdown\n"
+	mustUpsert(t, ds, testDoc(repo, "DESIGN.md", v3), chunksOf(v3))
+	stale, err := store.StaleCitations(ctx, 0)
+	if err != nil || len(stale) != 1 || stale[0].Status != memstore.CitationChanged || stale[0].StaleAt == nil {
+		t.Fatalf("StaleCitations = %+v, %v; want one changed", stale, err)
+	}
+	items, err := store.ReviewQueue(ctx, memstore.ReviewOpts{})
+	if err != nil || len(items) != 1 || items[0].Fact.ID != factID || items[0].StaleCitations != 1 {
+		t.Fatalf("ReviewQueue = %+v, %v; want the cited fact despite its age", items, err)
+	}
+
+	// Accepting re-snapshots the changed code.
+	acc, err := store.AcceptCitation(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("AcceptCitation: %v", err)
+	}
+	if acc.Status != memstore.CitationCurrent || acc.LineStart != 2 || acc.StaleAt != nil {
+		t.Errorf("accepted = %+v", acc)
+	}
+	if items, _ := store.ReviewQueue(ctx, memstore.ReviewOpts{}); len(items) != 0 {
+		t.Errorf("review queue after accept = %+v, want empty", items)
+	}
+
+	// Deleting the document vanishes the citation; it cannot be accepted.
+	if _, err := ds.DeleteDocuments(ctx, repo, []string{"DESIGN.md"}); err != nil {
+		t.Fatal(err)
+	}
+	cs, _ = store.Citations(ctx, factID)
+	if len(cs) != 1 || cs[0].Status != memstore.CitationVanished || cs[0].DocumentID != 0 || cs[0].Current != nil {
+		t.Fatalf("after delete = %+v, want vanished", cs)
+	}
+	if _, err := store.AcceptCitation(ctx, c.ID); err == nil {
+		t.Error("AcceptCitation accepted a vanished citation")
+	}
+	if err := store.DeleteCitation(ctx, c.ID); err != nil {
+		t.Fatalf("DeleteCitation: %v", err)
+	}
+	if stale, _ := store.StaleCitations(ctx, 0); len(stale) != 0 {
+		t.Errorf("stale after delete = %+v", stale)
+	}
+}
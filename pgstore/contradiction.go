@@ -0,0 +1,159 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matthewjhunter/memstore"
+	pgvector "github.com/pgvector/pgvector-go"
+)
+
+var (
+	_ memstore.ContradictionAuditStore = (*PostgresStore)(nil)
+	_ memstore.ContradictionQueue      = (*PostgresStore)(nil)
+)
+
+// migrateV10 adds audited_at, the contradiction audit's per-fact watermark.
+// The partial index serves PendingAudit's scan for facts not yet audited.
+func (s *PostgresStore) migrateV10(ctx context.Context) error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS audited_at TIMESTAMPTZ`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_unaudited ON memstore_facts (id) WHERE audited_at IS NULL`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V10 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// migrateV17 adds audit_attempts, the contradiction audit's count of failed
+// audits per fact (see RecordAuditFailure).
+func (s *PostgresStore) migrateV17(ctx context.Context) error {
+	stmt := `ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS audit_attempts INTEGER NOT NULL DEFAULT 0`
+	if _, err := s.pool.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("pgstore V17 migration: %w\nstatement: %s", err, stmt)
+	}
+	return nil
+}
+
+// PendingAudit implements memstore.ContradictionAuditStore.
+func (s *PostgresStore) PendingAudit(ctx context.Context, limit int) ([]memstore.Fact, error) {
+	if limit <= 0 {
+		limit = memstore.DefaultContradictionBatch
+	}
+	var b queryBuilder
+	b.q = `SELECT ` + factColumns + ` FROM memstore_facts
+	       WHERE audited_at IS NULL AND embedding IS NOT NULL AND superseded_by IS NULL` + unexpired("") + notDeleted("")
+	b.write(` AND namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	b.write(` ORDER BY id LIMIT `, limit)
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: querying facts pending audit: %w", err)
+	}
+	defer rows.Close()
+	return scanFacts(rows)
+}
+
+// AuditNeighbors implements memstore.ContradictionAuditStore. Candidates are
+// restricted to f's owner, since a contradicts link may not span users.
+func (s *PostgresStore) AuditNeighbors(ctx context.Context, f memstore.Fact, minSim float64, limit int) ([]memstore.SearchResult, error) {
+	if len(f.Embedding) == 0 {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = memstore.DefaultContradictionNeighbors
+	}
+	var b queryBuilder
+	b.write(`SELECT `+factColumns+`, similarity FROM (
+	           SELECT *, 1 - (embedding <=> `, pgvector.NewVector(f.Embedding))
+	b.q += `) AS similarity FROM memstore_facts
+	           WHERE audited_at IS NOT NULL AND embedding IS NOT NULL AND superseded_by IS NULL` + unexpired("") + notDeleted("")
+	b.write(` AND namespace = `, s.namespace)
+	b.write(` AND user_id = `, f.UserID)
+	b.write(` AND subject = `, f.Subject)
+	b.write(` AND subsystem = `, f.Subsystem)
+	b.write(` AND id <> `, f.ID)
+	b.q += `) c`
+	b.write(` WHERE similarity >= `, minSim)
+	b.write(` ORDER BY similarity DESC LIMIT `, limit)
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: querying audit neighbours of %d: %w", f.ID, err)
+	}
+	defer rows.Close()
+	var out []memstore.SearchResult
+	for rows.Next() {
+		var sim float64
+		fact, err := scanFact(rows, &sim)
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: scanning audit neighbour: %w", err)
+		}
+		out = append(out, memstore.SearchResult{Fact: *fact, VecScore: sim, Combined: sim})
+	}
+	return out, rows.Err()
+}
+
+// MarkAudited implements memstore.ContradictionAuditStore.
+func (s *PostgresStore) MarkAudited(ctx context.Context, id int64) error {
+	var b queryBuilder
+	b.q = `UPDATE memstore_facts SET audited_at = NOW()`
+	b.write(` WHERE id = `, id)
+	b.write(` AND namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	if _, err := s.pool.Exec(ctx, b.q, b.args...); err != nil {
+		return fmt.Errorf("pgstore: marking fact %d audited: %w", id, err)
+	}
+	return nil
+}
+
+// RecordAuditFailure implements memstore.ContradictionAuditStore.
+func (s *PostgresStore) RecordAuditFailure(ctx context.Context, id int64, maxAttempts int) (bool, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = memstore.DefaultContradictionAttempts
+	}
+	var b queryBuilder
+	b.write(`UPDATE memstore_facts SET audit_attempts = audit_attempts + 1,
+	        audited_at = CASE WHEN audit_attempts + 1 >= `, maxAttempts)
+	b.q += ` THEN NOW() ELSE audited_at END`
+	b.write(` WHERE id = `, id)
+	b.write(` AND namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	b.q += ` RETURNING audit_attempts`
+	var attempts int
+	if err := s.pool.QueryRow(ctx, b.q, b.args...).Scan(&attempts); err != nil {
+		return false, fmt.Errorf("pgstore: recording failed audit of fact %d: %w", id, err)
+	}
+	return attempts >= maxAttempts, nil
+}
+
+// Contradictions implements memstore.ContradictionQueue.
+func (s *PostgresStore) Contradictions(ctx context.Context, limit int) ([]memstore.Contradiction, error) {
+	var b queryBuilder
+	b.write(`SELECT `+linkColumns+` FROM memstore_links WHERE namespace = `, s.namespace)
+	b.write(` AND link_type = `, memstore.LinkContradicts)
+	b.q += linkEndpointsLive
+	s.appendUserFilter(&b, "user_id")
+	b.write(` AND COALESCE(metadata->>'status', '') <> `, memstore.ContradictionDismissed)
+	b.q += ` AND NOT EXISTS (SELECT 1 FROM memstore_facts cf WHERE cf.id IN (source_id, target_id)
+	                         AND (cf.superseded_by IS NOT NULL OR cf.expires_at <= NOW()))
+	         ORDER BY id`
+	if limit > 0 {
+		b.write(` LIMIT `, limit)
+	}
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: listing contradictions: %w", err)
+	}
+	links, err := scanLinks(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	return memstore.ContradictionsFromLinks(ctx, s, links)
+}
@@ -0,0 +1,163 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.Deduper = (*PostgresStore)(nil)
+
+// FindDuplicates reports clusters of near-duplicate active facts in the
+// store's scope. See memstore.Deduper.
+//
+// Embedded facts are paired by a LATERAL k-nearest-neighbour query, so each
+// probe is an HNSW index scan rather than a comparison against every fact.
+// Pairs never span users: merging or superseding across users is refused
+// anyway, so a service-scoped scan reports each user's duplicates apart.
+func (s *PostgresStore) FindDuplicates(ctx context.Context, opts memstore.DedupeOpts) ([]memstore.DuplicateCluster, error) {
+	if opts.Threshold <= 0 {
+		opts.Threshold = memstore.DefaultDedupeThreshold
+	}
+	if opts.LexicalThreshold <= 0 {
+		opts.LexicalThreshold = memstore.DefaultDedupeLexicalThreshold
+	}
+	if opts.Neighbors <= 0 {
+		opts.Neighbors = memstore.DefaultDedupeNeighbors
+	}
+
+	var b queryBuilder
+	b.q = `SELECT f.id, n.id, n.similarity
+	       FROM memstore_facts f
+	       CROSS JOIN LATERAL (
+	           SELECT g.id, 1 - (g.embedding <=> f.embedding) AS similarity
+	           FROM memstore_facts g
+	           WHERE g.namespace = f.namespace AND g.user_id = f.user_id AND g.id <> f.id
+	             AND g.embedding IS NOT NULL AND g.superseded_by IS NULL` + unexpired("g.") + notDeleted("g.") + `
+	           ORDER BY g.embedding <=> f.embedding`
+	b.write(` LIMIT `, opts.Neighbors)
+	b.q += `) n
+	       WHERE f.embedding IS NOT NULL AND f.superseded_by IS NULL` + unexpired("f.") + notDeleted("f.")
+	b.write(` AND f.namespace = `, s.namespace)
+	s.appendUserFilter(&b, "f.user_id")
+	b.write(` AND n.similarity >= `, opts.Threshold)
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: dedupe scan: %w", err)
+	}
+	var pairs []memstore.DuplicatePair
+	for rows.Next() {
+		p := memstore.DuplicatePair{Method: memstore.DedupeEmbedding}
+		if err := rows.Scan(&p.A, &p.B, &p.Similarity); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("pgstore: scanning dedupe pair: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: dedupe scan: %w", err)
+	}
+
+	lexical, err := s.lexicalDuplicates(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	pairs = append(pairs, lexical...)
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	ids := make(map[int64]bool)
+	for _, p := range pairs {
+		ids[p.A], ids[p.B] = true, true
+	}
+	idList := make([]int64, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+	facts, err := s.List(ctx, memstore.QueryOpts{IDs: idList})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]memstore.Fact, len(facts))
+	for _, f := range facts {
+		byID[f.ID] = f
+	}
+	return memstore.ClusterDuplicates(pairs, byID, opts.Limit), nil
+}
+
+// lexicalDuplicates pairs each active fact still waiting for an embedding
+// with its best full-text matches on any shared term, scored by
+// memstore.LexicalSimilarity. These are normally few -- the embedding
+// pipeline's backlog -- so one query per fact is fine.
+func (s *PostgresStore) lexicalDuplicates(ctx context.Context, opts memstore.DedupeOpts) ([]memstore.DuplicatePair, error) {
+	var b queryBuilder
+	b.q = `SELECT id, user_id, content FROM memstore_facts
+	       WHERE embedding IS NULL AND superseded_by IS NULL` + unexpired("") + notDeleted("")
+	b.write(` AND namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: dedupe FTS fallback: %w", err)
+	}
+	type probe struct {
+		id, userID int64
+		content    string
+	}
+	var probes []probe
+	for rows.Next() {
+		var p probe
+		if err := rows.Scan(&p.id, &p.userID, &p.content); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("pgstore: scanning dedupe FTS probe: %w", err)
+		}
+		probes = append(probes, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: dedupe FTS fallback: %w", err)
+	}
+
+	var pairs []memstore.DuplicatePair
+	for _, p := range probes {
+		terms := memstore.DedupeTerms(p.content)
+		if len(terms) == 0 {
+			continue
+		}
+		// DedupeTerms yields bare words, so websearch syntax sees only the
+		// "or" operators placed here.
+		query := strings.Join(terms, " or ")
+		rows, err := s.pool.Query(ctx,
+			`SELECT id, content FROM memstore_facts
+			 WHERE fts @@ websearch_to_tsquery('english', $1)
+			   AND namespace = $2 AND user_id = $3 AND id <> $4
+			   AND superseded_by IS NULL`+unexpired("")+notDeleted("")+`
+			 ORDER BY ts_rank(fts, websearch_to_tsquery('english', $1)) DESC
+			 LIMIT $5`,
+			query, s.namespace, p.userID, p.id, opts.Neighbors)
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: dedupe FTS fallback: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var content string
+			if err := rows.Scan(&id, &content); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("pgstore: scanning dedupe FTS candidate: %w", err)
+			}
+			if sim := memstore.LexicalSimilarity(p.content, content); sim >= opts.LexicalThreshold {
+				pairs = append(pairs, memstore.DuplicatePair{A: p.id, B: id, Similarity: sim, Method: memstore.DedupeLexical})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("pgstore: dedupe FTS fallback: %w", err)
+		}
+	}
+	return pairs, nil
+}
@@ -0,0 +1,79 @@
+package pgstore
+
+import (
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func docHit(id int64, score float64) memstore.DocumentSearchResult {
+	return memstore.DocumentSearchResult{
+		Chunk: memstore.DocumentChunk{ID: id, DocumentID: 1, Ordinal: int(id)},
+		Score: score,
+	}
+}
+
+// fuseDocumentArms is the whole hybrid ranking policy and is pure, so it is
+// tested directly rather than through a live database.
+func TestFuseDocumentArms(t *testing.T) {
+	fts := []memstore.DocumentSearchResult{docHit(1, 0.4), docHit(2, 0.2)}
+	vec := []memstore.DocumentSearchResult{docHit(2, 0.9), docHit(3, 0.8)}
+	vec[0].VecScore, vec[1].VecScore = 0.9, 0.8
+
+	got := fuseDocumentArms(fts, vec, 0.6, 0.4, 10)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 fused results (deduplicated by chunk), got %d", len(got))
+	}
+
+	byID := map[int64]memstore.DocumentSearchResult{}
+	for _, r := range got {
+		byID[r.Chunk.ID] = r
+	}
+	// Chunk 1: FTS only, the arm's best rank -> normalized 1.0.
+	if r := byID[1]; r.FTSScore != 1.0 || r.VecScore != 0 || !near(r.Score, 0.6) {
+		t.Errorf("chunk 1 = fts %.3f vec %.3f score %.3f; want 1.0/0/0.6", r.FTSScore, r.VecScore, r.Score)
+	}
+	// Chunk 2: both arms. 0.6*0.5 + 0.4*0.9 = 0.66.
+	if r := byID[2]; r.FTSScore != 0.5 || r.VecScore != 0.9 || !near(r.Score, 0.66) {
+		t.Errorf("chunk 2 = fts %.3f vec %.3f score %.3f; want 0.5/0.9/0.66", r.FTSScore, r.VecScore, r.Score)
+	}
+	// Chunk 3: vector only. 0.4*0.8 = 0.32.
+	if r := byID[3]; r.FTSScore != 0 || !near(r.Score, 0.32) {
+		t.Errorf("chunk 3 = fts %.3f score %.3f; want 0/0.32", r.FTSScore, r.Score)
+	}
+	if got[0].Chunk.ID != 2 || got[1].Chunk.ID != 1 || got[2].Chunk.ID != 3 {
+		t.Errorf("order = %d,%d,%d; want 2,1,3", got[0].Chunk.ID, got[1].Chunk.ID, got[2].Chunk.ID)
+	}
+
+	if trimmed := fuseDocumentArms(fts, vec, 0.6, 0.4, 2); len(trimmed) != 2 {
+		t.Errorf("limit not applied: got %d results", len(trimmed))
+	}
+}
+
+func TestFuseDocumentArms_KeepsFallbackMark(t *testing.T) {
+	fallback := docHit(5, 0.01)
+	fallback.Fallback = true
+	vec := docHit(5, 0.7)
+	vec.VecScore = 0.7
+
+	got := fuseDocumentArms([]memstore.DocumentSearchResult{fallback}, []memstore.DocumentSearchResult{vec}, 0.6, 0.4, 10)
+	if len(got) != 1 || !got[0].Fallback {
+		t.Fatalf("a decomposed-fallback FTS hit lost its mark after fusion: %+v", got)
+	}
+}
+
+func TestFuseDocumentArms_StableTieBreak(t *testing.T) {
+	a := docHit(7, 0.5)
+	a.Chunk.Ordinal = 3
+	b := docHit(8, 0.5)
+	b.Chunk.Ordinal = 1
+	got := fuseDocumentArms(nil, []memstore.DocumentSearchResult{a, b}, 0, 1, 10)
+	if got[0].Chunk.ID != 8 {
+		t.Errorf("equal scores should order by ordinal: got chunk %d first", got[0].Chunk.ID)
+	}
+}
+
+func near(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
@@ -6,11 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/matthewjhunter/memstore"
+	pgvector "github.com/pgvector/pgvector-go"
 )
 
 // This file implements memstore.DocumentStore: the verbatim document corpus
@@ -127,6 +131,39 @@ func (s *PostgresStore) migrateV5(ctx context.Context) error {
 	return nil
 }
 
+// migrateV6 adds chunk embeddings: the vector column the embed queue fills,
+// plus the same quarantine pair migrateV3 gave facts, so a chunk that can never
+// embed stops coming back every poll. The column shares the fact embedding's
+// dimension and model -- chunks live in the text space until the code space
+// from docs/embedding-model-routing.md exists. Chunks ingested before this
+// migration start NULL and are picked up by the queue like any new chunk.
+func (s *PostgresStore) migrateV6(ctx context.Context) error {
+	vecType := "vector"
+	if s.vecDim > 0 {
+		vecType = fmt.Sprintf("vector(%d)", s.vecDim)
+	}
+	stmts := []string{
+		fmt.Sprintf(`ALTER TABLE memstore_document_chunks
+			ADD COLUMN IF NOT EXISTS embedding %s,
+			ADD COLUMN IF NOT EXISTS embed_failed_at TIMESTAMPTZ,
+			ADD COLUMN IF NOT EXISTS embed_error TEXT`, vecType),
+		`CREATE INDEX IF NOT EXISTS idx_memstore_document_chunks_unembedded
+			ON memstore_document_chunks (id) WHERE embedding IS NULL AND embed_failed_at IS NULL`,
+	}
+	if s.vecDim > 0 {
+		stmts = append(stmts,
+			`CREATE INDEX IF NOT EXISTS idx_memstore_document_chunks_embedding
+				ON memstore_document_chunks USING hnsw (embedding vector_cosine_ops)`,
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V6 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
 // docOwnerFor resolves the user_id an incoming document should be written
 // under -- the DocumentStore analogue of ownerFor, with the same contract: a
 // scoped store writes its own user's documents and no one else's, a mismatch
@@ -182,6 +219,16 @@ func validateDocumentChunks(chunks []memstore.DocumentChunk) error {
 // Replacement is keyed on (namespace, user, repo_url, path) -- commit is
 // deliberately outside the key, so re-ingesting at a new commit replaces the
 // row. The previous chunk set is deleted, never merged.
+//
+// Embeddings are the one thing carried across a replace: when the stored
+// document has the same FileSHA256 and ChunkerVersion as the incoming one,
+// the chunker saw the same bytes with the same rules, so each chunk's embed
+// text is unchanged and its vector (or quarantine) moves to the new row by
+// ordinal. Anything else starts unembedded and goes back through the queue.
+//
+// Fact citations of the document are re-resolved against the new chunks in
+// the same transaction (see memstore.ResolveCitation), flagging the facts
+// whose cited code changed or vanished.
 func (s *PostgresStore) UpsertDocument(ctx context.Context, doc memstore.Document, chunks []memstore.DocumentChunk) (int64, error) {
 	owner, err := s.docOwnerFor(doc)
 	if err != nil {
@@ -196,6 +243,9 @@ func (s *PostgresStore) UpsertDocument(ctx context.Context, doc memstore.Documen
 	if err := validateDocumentChunks(chunks); err != nil {
 		return 0, err
 	}
+	// The chunks get their new ids written back for citation resolution;
+	// don't write them into the caller's slice.
+	chunks = slices.Clone(chunks)
 
 	// The conflict target must name the index that actually enforces
 	// uniqueness for this identity: the loose-file case is covered by the
@@ -211,6 +261,12 @@ func (s *PostgresStore) UpsertDocument(ctx context.Context, doc memstore.Documen
 	}
 	defer tx.Rollback(ctx)
 
+	// Read before the upsert overwrites file_sha256 and chunker_version.
+	carried, err := priorChunkEmbeddings(ctx, tx, s.namespace, owner, doc)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int64
 	err = tx.QueryRow(ctx,
 		`INSERT INTO memstore_documents
@@ -250,24 +306,31 @@ func (s *PostgresStore) UpsertDocument(ctx context.Context, doc memstore.Documen
 	if len(chunks) > 0 {
 		b := &pgx.Batch{}
 		for _, c := range chunks {
+			var prev carriedEmbedding
+			if p, ok := carried[c.Ordinal]; ok && p.content == c.Content {
+				prev = p
+			}
 			b.Queue(
 				`INSERT INTO memstore_document_chunks
 					(namespace, user_id, document_id, ordinal, content,
 					 byte_start, byte_end, line_start, line_end,
 					 heading_path, heading_level, lang,
 					 package, import_path, symbol, receiver, decl_kind, exported,
-					 signature, scope_path, imports_used)
-				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)`,
+					 signature, scope_path, imports_used,
+					 embedding, embed_failed_at, embed_error)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+				 RETURNING id`,
 				s.namespace, owner, id, c.Ordinal, c.Content,
 				c.ByteStart, c.ByteEnd, c.LineStart, c.LineEnd,
 				c.HeadingPath, c.HeadingLevel, c.Lang,
 				c.Package, c.ImportPath, c.Symbol, c.Receiver, c.DeclKind, c.Exported,
 				c.Signature, c.ScopePath, c.ImportsUsed,
+				prev.embedding, prev.failedAt, prev.embedError,
 			)
 		}
 		br := tx.SendBatch(ctx, b)
 		for i := range chunks {
-			if _, err := br.Exec(); err != nil {
+			if err := br.QueryRow().Scan(&chunks[i].ID); err != nil {
 				br.Close()
 				return 0, fmt.Errorf("pgstore: UpsertDocument: inserting chunk %d: %w", i, err)
 			}
@@ -277,12 +340,59 @@ func (s *PostgresStore) UpsertDocument(ctx context.Context, doc memstore.Documen
 		}
 	}
 
+	if err := resolveCitations(ctx, tx, s.namespace, owner, id, doc, chunks); err != nil {
+		return 0, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return 0, fmt.Errorf("pgstore: UpsertDocument: commit: %w", err)
 	}
 	return id, nil
 }
 
+// carriedEmbedding is one stored chunk's embedding state, eligible to move to
+// the replacement chunk at the same ordinal.
+type carriedEmbedding struct {
+	content    string
+	embedding  *pgvector.Vector
+	failedAt   *time.Time
+	embedError *string
+}
+
+// priorChunkEmbeddings returns the embedding state of the stored chunks for
+// doc's identity, keyed by ordinal -- but only when the stored document was
+// cut from the same bytes (file_sha256) by the same chunker version. Either
+// differing means the chunks, or their derived context, may have changed, and
+// nothing is carried. Content is returned too so the caller can refuse a
+// carry whose span does not match after all.
+func priorChunkEmbeddings(ctx context.Context, tx pgx.Tx, namespace string, owner int64, doc memstore.Document) (map[int]carriedEmbedding, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT c.ordinal, c.content, c.embedding, c.embed_failed_at, c.embed_error
+		 FROM memstore_document_chunks c
+		 JOIN memstore_documents d ON d.id = c.document_id
+		 WHERE d.namespace = $1 AND d.user_id = $2
+		   AND d.repo_url IS NOT DISTINCT FROM $3 AND d.path = $4
+		   AND d.file_sha256 = $5 AND d.chunker_version = $6
+		   AND (c.embedding IS NOT NULL OR c.embed_failed_at IS NOT NULL)`,
+		namespace, owner, nullableText(doc.RepoURL), doc.Path, doc.FileSHA256, doc.ChunkerVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: UpsertDocument: reading prior embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	carried := make(map[int]carriedEmbedding)
+	for rows.Next() {
+		var ordinal int
+		var ce carriedEmbedding
+		if err := rows.Scan(&ordinal, &ce.content, &ce.embedding, &ce.failedAt, &ce.embedError); err != nil {
+			return nil, fmt.Errorf("pgstore: UpsertDocument: scanning prior embedding: %w", err)
+		}
+		carried[ordinal] = ce
+	}
+	return carried, rows.Err()
+}
+
 // ListDocuments returns the manifest view of every stored document for a repo
 // identity, ordered by path. repoURL "" selects loose files (repo_url IS
 // NULL) -- unlike search, where "" means unfiltered, a manifest is always
@@ -317,13 +427,13 @@ func (s *PostgresStore) ListDocuments(ctx context.Context, repoURL string) ([]me
 }
 
 // DeleteDocuments removes the named documents for a repo identity; chunks
-// cascade. Returns the number of documents actually deleted -- paths that were
+// cascade, and fact citations of them are marked vanished. Returns the number of documents actually deleted -- paths that were
 // never stored (or belong to another user) simply do not count.
 func (s *PostgresStore) DeleteDocuments(ctx context.Context, repoURL string, paths []string) (int64, error) {
 	if len(paths) == 0 {
 		return 0, nil
 	}
-	q := `DELETE FROM memstore_documents WHERE namespace = $1 AND path = ANY($2::text[])`
+	q := `SELECT id FROM memstore_documents WHERE namespace = $1 AND path = ANY($2::text[])`
 	args := []any{s.namespace, paths}
 	if repoURL == "" {
 		q += ` AND repo_url IS NULL`
@@ -333,10 +443,36 @@ func (s *PostgresStore) DeleteDocuments(ctx context.Context, repoURL string, pat
 	}
 	q, args = s.userPredicate(q, args)
 
-	ct, err := s.pool.Exec(ctx, q, args...)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: DeleteDocuments: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, q+` FOR UPDATE`, args...)
 	if err != nil {
 		return 0, fmt.Errorf("pgstore: DeleteDocuments: %w", err)
 	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: DeleteDocuments: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Before the delete: its ON DELETE SET NULL loses the document link
+	// the citations are found by.
+	if err := vanishCitations(ctx, tx, ids); err != nil {
+		return 0, err
+	}
+	ct, err := tx.Exec(ctx, `DELETE FROM memstore_documents WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: DeleteDocuments: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("pgstore: DeleteDocuments: commit: %w", err)
+	}
 	return ct.RowsAffected(), nil
 }
 
@@ -383,28 +519,140 @@ func (s *PostgresStore) GetDocumentChunks(ctx context.Context, documentID int64)
 	return chunks, rows.Err()
 }
 
-// SearchDocumentChunks is FTS over chunk content, per the measured design in
+// Hybrid document search weights when DocumentSearchOpts leaves both at zero.
+// They match fact search's first-stage defaults so the two indexes fuse the
+// same way.
+const (
+	defaultDocFTSWeight = 0.6
+	defaultDocVecWeight = 0.4
+)
+
+// docSearchColumns is the SELECT list shared by both document search arms:
+// chunk columns prefixed with c., then the document identity each result
+// carries for its citation. scanDocSearchResults reads it, plus one trailing
+// score column.
+const docSearchColumns = `c.id, c.document_id, c.ordinal, c.content, c.byte_start, c.byte_end, c.line_start, c.line_end,
+			c.heading_path, c.heading_level, c.lang,
+			c.package, c.import_path, c.symbol, c.receiver, c.decl_kind, c.exported, c.signature, c.scope_path, c.imports_used,
+			c.created_at,
+			d.repo_url, d.commit, d.path, d.basename, d.lang, d.trusted, d.dirty, d.generated, d.is_test`
+
+// SearchDocumentChunks searches chunk content in the mode opts.Mode selects.
+//
+// The default FTS mode follows the measured design in
 // docs/embedding-model-routing.md: an exact english pass first; then, only
 // when the exact pass leaves room below MaxResults, a decomposed-identifier
 // pass against the 'simple' weight-D lexemes, appended after the exact hits
 // and marked Fallback. Appending rather than blending is the measured
 // property: the fallback can only fill space an exact match was not using, so
 // queries that already work keep their ranking.
+//
+// Vector mode ranks embedded chunks by cosine similarity to the query; hybrid
+// runs both arms and fuses them (see fuseDocumentArms). Both need an embedder.
+//
+// Any mode may be followed by a cross-encoder rerank of the top candidates
+// when the store has a reranker and opts.RerankMode is set
+// (memstore.RerankDocumentResults); an unreachable reranker leaves the
+// first-stage ranking as is.
 func (s *PostgresStore) SearchDocumentChunks(ctx context.Context, query string, opts memstore.DocumentSearchOpts) ([]memstore.DocumentSearchResult, error) {
 	if opts.MaxResults <= 0 {
 		opts.MaxResults = 20
 	}
+	// The first stage must cover the rerank pool, not just the page.
+	limit := opts.MaxResults
+	if s.reranker != nil && opts.RerankMode.Enabled() {
+		if opts.RerankCandidates <= 0 {
+			opts.RerankCandidates = memstore.DefaultRerankCandidates
+		}
+		limit = max(limit, opts.RerankCandidates)
+	}
+
+	results, err := s.searchDocChunksMode(ctx, query, opts, limit)
+	if err != nil {
+		return nil, err
+	}
+	results, err = memstore.RerankDocumentResults(ctx, s.reranker, query, results, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > opts.MaxResults {
+		results = results[:opts.MaxResults]
+	}
+	return results, nil
+}
+
+// searchDocChunksMode runs the first stage opts.Mode selects, returning at
+// most limit results.
+func (s *PostgresStore) searchDocChunksMode(ctx context.Context, query string, opts memstore.DocumentSearchOpts, limit int) ([]memstore.DocumentSearchResult, error) {
+	mode := opts.Mode
+	if mode == "" {
+		mode = memstore.DocSearchFTS
+	}
+
+	switch mode {
+	case memstore.DocSearchFTS:
+		return s.searchDocChunksFTS(ctx, query, opts, limit)
+
+	case memstore.DocSearchVector:
+		queryEmb, err := s.docQueryEmbedding(ctx, query)
+		if err != nil || len(queryEmb) == 0 {
+			return nil, err
+		}
+		return s.searchDocChunksVector(ctx, queryEmb, opts, limit)
+
+	case memstore.DocSearchHybrid:
+		queryEmb, err := s.docQueryEmbedding(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		// Each arm fetches twice the page so the fusion has headroom, the
+		// same sizing fact search uses (memstore.FetchLimit).
+		fetch := limit * 2
+		fts, err := s.searchDocChunksFTS(ctx, query, opts, fetch)
+		if err != nil {
+			return nil, err
+		}
+		var vec []memstore.DocumentSearchResult
+		if len(queryEmb) > 0 {
+			vec, err = s.searchDocChunksVector(ctx, queryEmb, opts, fetch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fw, vw := opts.FTSWeight, opts.VecWeight
+		if fw == 0 && vw == 0 {
+			fw, vw = defaultDocFTSWeight, defaultDocVecWeight
+		}
+		return fuseDocumentArms(fts, vec, fw, vw, limit), nil
+
+	default:
+		return nil, fmt.Errorf("pgstore: unknown document search mode %q", opts.Mode)
+	}
+}
+
+// docQueryEmbedding embeds a document-search query through the shared query
+// cache, so a query already run against facts costs nothing here.
+func (s *PostgresStore) docQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	if s.embedder == nil {
+		return nil, errors.New("pgstore: vector document search requires an embedder")
+	}
+	return s.queryCache.Single(ctx, s.queryEmbedder, query)
+}
+
+// searchDocChunksFTS is the exact-then-decomposed FTS arm, returning at most
+// limit results with fallback hits appended below exact ones.
+func (s *PostgresStore) searchDocChunksFTS(ctx context.Context, query string, opts memstore.DocumentSearchOpts, limit int) ([]memstore.DocumentSearchResult, error) {
 	tsquery := quoteFTSQuery(query)
 	if tsquery == "" {
 		return nil, nil
 	}
 
-	results, err := s.searchDocChunks(ctx, "english", tsquery, opts, opts.MaxResults, nil)
+	results, err := s.searchDocChunks(ctx, "english", tsquery, opts, limit, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if room := opts.MaxResults - len(results); room > 0 {
+	if room := limit - len(results); room > 0 {
 		if decomposed := decomposeQuery(query); decomposed != "" && decomposed != strings.ToLower(strings.Join(strings.Fields(query), " ")) {
 			exclude := make([]int64, 0, len(results))
 			for _, r := range results {
@@ -429,11 +677,7 @@ func (s *PostgresStore) SearchDocumentChunks(ctx context.Context, query string,
 // purpose), the join condition keeps the document row honest.
 func (s *PostgresStore) searchDocChunks(ctx context.Context, config, tsquery string, opts memstore.DocumentSearchOpts, limit int, excludeIDs []int64) ([]memstore.DocumentSearchResult, error) {
 	var b queryBuilder
-	b.write(`SELECT c.id, c.document_id, c.ordinal, c.content, c.byte_start, c.byte_end, c.line_start, c.line_end,
-			c.heading_path, c.heading_level, c.lang,
-			c.package, c.import_path, c.symbol, c.receiver, c.decl_kind, c.exported, c.signature, c.scope_path, c.imports_used,
-			c.created_at,
-			d.repo_url, d.commit, d.path, d.basename, d.lang, d.trusted, d.dirty, d.generated, d.is_test,
+	b.write(`SELECT `+docSearchColumns+`,
 			ts_rank(c.fts, plainto_tsquery('`+config+`', `, tsquery)
 	b.q += `)) AS rank
 		FROM memstore_document_chunks c
@@ -443,8 +687,52 @@ func (s *PostgresStore) searchDocChunks(ctx context.Context, config, tsquery str
 	b.write(``, tsquery)
 	b.q += `)`
 
+	s.appendDocSearchFilters(&b, opts)
+	if len(excludeIDs) > 0 {
+		b.args = append(b.args, excludeIDs)
+		b.q += fmt.Sprintf(` AND NOT (c.id = ANY($%d::bigint[]))`, len(b.args))
+	}
+	b.write(` ORDER BY rank DESC, c.document_id, c.ordinal LIMIT `, limit)
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: document search: %w", err)
+	}
+	defer rows.Close()
+	return scanDocSearchResults(rows, false)
+}
+
+// searchDocChunksVector ranks embedded chunks by cosine similarity using
+// pgvector's <=> operator, with the same join and filters as the FTS arm.
+// Chunks not yet embedded are simply absent.
+func (s *PostgresStore) searchDocChunksVector(ctx context.Context, queryEmb []float32, opts memstore.DocumentSearchOpts, limit int) ([]memstore.DocumentSearchResult, error) {
+	qv := pgvector.NewVector(queryEmb)
+
+	var b queryBuilder
+	b.write(`SELECT `+docSearchColumns+`, 1 - (c.embedding <=> `, qv)
+	b.q += `) AS similarity
+		FROM memstore_document_chunks c
+		JOIN memstore_documents d
+		  ON d.id = c.document_id AND d.namespace = c.namespace AND d.user_id = c.user_id
+		WHERE c.embedding IS NOT NULL`
+
+	s.appendDocSearchFilters(&b, opts)
+	b.write(` ORDER BY c.embedding <=> `, qv)
+	b.write(` LIMIT `, limit)
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: document vector search: %w", err)
+	}
+	defer rows.Close()
+	return scanDocSearchResults(rows, true)
+}
+
+// appendDocSearchFilters adds the isolation predicate and the opts filters
+// shared by both search arms.
+func (s *PostgresStore) appendDocSearchFilters(b *queryBuilder, opts memstore.DocumentSearchOpts) {
 	b.write(` AND c.namespace = `, s.namespace)
-	s.appendUserFilter(&b, "c.user_id")
+	s.appendUserFilter(b, "c.user_id")
 	if !opts.IncludeGenerated {
 		b.q += ` AND NOT d.generated`
 	}
@@ -461,18 +749,12 @@ func (s *PostgresStore) searchDocChunks(ctx context.Context, config, tsquery str
 	if opts.Lang != "" {
 		b.write(` AND d.lang = `, opts.Lang)
 	}
-	if len(excludeIDs) > 0 {
-		b.args = append(b.args, excludeIDs)
-		b.q += fmt.Sprintf(` AND NOT (c.id = ANY($%d::bigint[]))`, len(b.args))
-	}
-	b.write(` ORDER BY rank DESC, c.document_id, c.ordinal LIMIT `, limit)
-
-	rows, err := s.pool.Query(ctx, b.q, b.args...)
-	if err != nil {
-		return nil, fmt.Errorf("pgstore: document search: %w", err)
-	}
-	defer rows.Close()
+}
 
+// scanDocSearchResults scans rows of docSearchColumns plus a trailing score.
+// For the vector arm the score is cosine similarity, also recorded as
+// VecScore; non-positive similarities are dropped, as on the fact side.
+func scanDocSearchResults(rows pgx.Rows, vector bool) ([]memstore.DocumentSearchResult, error) {
 	var results []memstore.DocumentSearchResult
 	for rows.Next() {
 		var r memstore.DocumentSearchResult
@@ -492,11 +774,130 @@ func (s *PostgresStore) searchDocChunks(ctx context.Context, config, tsquery str
 		if repoURL != nil {
 			r.RepoURL = *repoURL
 		}
+		if vector {
+			if r.Score <= 0 {
+				continue
+			}
+			r.VecScore = r.Score
+		}
 		results = append(results, r)
 	}
 	return results, rows.Err()
 }
 
+// fuseDocumentArms merges FTS and vector hits by chunk ID into one ranking:
+// FTS rank is normalized by the arm's maximum (cosine is already [0,1]) and
+// Score becomes ftsWeight*fts + vecWeight*vec. Decomposed-fallback ranks come
+// from weight-D lexemes and so normalize low on their own; a fallback hit the
+// vector arm also found keeps its Fallback mark, since the FTS match is still
+// only a decomposed one. Ties break on document and ordinal so the order is
+// stable across calls.
+func fuseDocumentArms(fts, vec []memstore.DocumentSearchResult, ftsWeight, vecWeight float64, limit int) []memstore.DocumentSearchResult {
+	var maxFTS float64
+	for _, r := range fts {
+		maxFTS = max(maxFTS, r.Score)
+	}
+
+	byID := make(map[int64]*memstore.DocumentSearchResult, len(fts)+len(vec))
+	order := make([]int64, 0, len(fts)+len(vec))
+	for _, r := range fts {
+		r.FTSScore = r.Score
+		if maxFTS > 0 {
+			r.FTSScore = r.Score / maxFTS
+		}
+		byID[r.Chunk.ID] = &r
+		order = append(order, r.Chunk.ID)
+	}
+	for _, r := range vec {
+		if existing, ok := byID[r.Chunk.ID]; ok {
+			existing.VecScore = r.VecScore
+			continue
+		}
+		r.VecScore = r.Score
+		byID[r.Chunk.ID] = &r
+		order = append(order, r.Chunk.ID)
+	}
+
+	merged := make([]memstore.DocumentSearchResult, 0, len(order))
+	for _, id := range order {
+		r := byID[id]
+		r.Score = ftsWeight*r.FTSScore + vecWeight*r.VecScore
+		merged = append(merged, *r)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Chunk.DocumentID != b.Chunk.DocumentID {
+			return a.Chunk.DocumentID < b.Chunk.DocumentID
+		}
+		return a.Chunk.Ordinal < b.Chunk.Ordinal
+	})
+	if len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
+// NeedingChunkEmbedding returns chunks that have neither an embedding nor a
+// quarantine mark, oldest first. Like NeedingEmbedding it runs under the
+// store's scope; the daemon's queue uses ServiceScope to span all users.
+func (s *PostgresStore) NeedingChunkEmbedding(ctx context.Context, limit int) ([]memstore.DocumentChunk, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	q, args := s.userPredicate(
+		`SELECT `+docChunkColumns+`
+		 FROM memstore_document_chunks
+		 WHERE embedding IS NULL AND embed_failed_at IS NULL AND namespace = $1`,
+		[]any{s.namespace})
+	args = append(args, limit)
+	q += fmt.Sprintf(` ORDER BY id LIMIT $%d`, len(args))
+
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: querying unembedded chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []memstore.DocumentChunk
+	for rows.Next() {
+		c, err := scanDocumentChunk(rows)
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: scanning chunk: %w", err)
+		}
+		chunks = append(chunks, *c)
+	}
+	return chunks, rows.Err()
+}
+
+// SetChunkEmbedding stores a computed embedding for a chunk.
+func (s *PostgresStore) SetChunkEmbedding(ctx context.Context, chunkID int64, emb []float32) error {
+	q, args := s.userPredicate(
+		`UPDATE memstore_document_chunks SET embedding = $1 WHERE id = $2 AND namespace = $3`,
+		[]any{pgvector.NewVector(emb), chunkID, s.namespace})
+	if _, err := s.pool.Exec(ctx, q, args...); err != nil {
+		return fmt.Errorf("pgstore: setting embedding for chunk %d: %w", chunkID, err)
+	}
+	return nil
+}
+
+// MarkChunkEmbedFailed quarantines a chunk whose embedding failed
+// permanently. A re-ingest of changed bytes replaces the row and so clears
+// the mark; an unchanged re-ingest carries it (see UpsertDocument).
+func (s *PostgresStore) MarkChunkEmbedFailed(ctx context.Context, chunkID int64, reason string) error {
+	q, args := s.userPredicate(
+		`UPDATE memstore_document_chunks
+		 SET embed_failed_at = now(), embed_error = $1
+		 WHERE id = $2 AND namespace = $3`,
+		[]any{reason, chunkID, s.namespace})
+	if _, err := s.pool.Exec(ctx, q, args...); err != nil {
+		return fmt.Errorf("pgstore: marking embed failed for chunk %d: %w", chunkID, err)
+	}
+	return nil
+}
+
 // scanDocument scans one row of docColumns.
 func scanDocument(row scanner) (*memstore.Document, error) {
 	var d memstore.Document
@@ -624,3 +624,157 @@ func TestDocuments_ServiceScope(t *testing.T) {
 		}
 	})
 }
+
+// pendingChunkIDs returns the ids NeedingChunkEmbedding hands the queue.
+func pendingChunkIDs(t *testing.T, ds memstore.DocumentStore) []int64 {
+	t.Helper()
+	chunks, err := ds.NeedingChunkEmbedding(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("NeedingChunkEmbedding: %v", err)
+	}
+	ids := make([]int64, 0, len(chunks))
+	for _, c := range chunks {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// embedAllChunks stands in for the chunk embed queue: every pending chunk
+// gets the same vector.
+func embedAllChunks(t *testing.T, ds memstore.DocumentStore, vec []float32) {
+	t.Helper()
+	for _, id := range pendingChunkIDs(t, ds) {
+		if err := ds.SetChunkEmbedding(context.Background(), id, vec); err != nil {
+			t.Fatalf("SetChunkEmbedding(%d): %v", id, err)
+		}
+	}
+}
+
+// Re-embedding is keyed on (file_sha256, chunker_version): an unchanged file
+// re-ingested at a new commit keeps its vectors; a chunker bump or changed
+// bytes sends the chunks back through the queue.
+func TestDocuments_EmbeddingCarriedAcrossUnchangedReingest(t *testing.T) {
+	ds := docStore(t, newTestStore(t))
+	const repo = "https://github.com/matthewjhunter/memstore"
+
+	content := "first line of the file\nsecond line of the file\n"
+	mustUpsert(t, ds, testDoc(repo, "notes.txt", content), chunksOf(content))
+	if got := pendingChunkIDs(t, ds); len(got) != 2 {
+		t.Fatalf("fresh ingest: expected 2 unembedded chunks, got %d", len(got))
+	}
+	embedAllChunks(t, ds, []float32{0.1, 0.2, 0.3, 0.4})
+
+	same := testDoc(repo, "notes.txt", content)
+	same.Commit = "def456"
+	mustUpsert(t, ds, same, chunksOf(content))
+	if got := pendingChunkIDs(t, ds); len(got) != 0 {
+		t.Errorf("unchanged re-ingest queued %d chunks for re-embedding; want 0", len(got))
+	}
+
+	bumped := testDoc(repo, "notes.txt", content)
+	bumped.ChunkerVersion = 2
+	mustUpsert(t, ds, bumped, chunksOf(content))
+	if got := pendingChunkIDs(t, ds); len(got) != 2 {
+		t.Errorf("chunker version bump: expected 2 chunks re-queued, got %d", len(got))
+	}
+	embedAllChunks(t, ds, []float32{0.1, 0.2, 0.3, 0.4})
+
+	changed := "first line of the file\nsecond line, edited\n"
+	edited := testDoc(repo, "notes.txt", changed)
+	edited.ChunkerVersion = 2
+	mustUpsert(t, ds, edited, chunksOf(changed))
+	if got := pendingChunkIDs(t, ds); len(got) != 2 {
+		t.Errorf("changed bytes: expected 2 chunks re-queued, got %d", len(got))
+	}
+}
+
+func TestDocuments_ChunkEmbedQuarantine(t *testing.T) {
+	ctx := context.Background()
+	ds := docStore(t, newTestStore(t))
+
+	content := "only line\n"
+	mustUpsert(t, ds, testDoc("", "one.txt", content), chunksOf(content))
+	ids := pendingChunkIDs(t, ds)
+	if len(ids) != 1 {
+		t.Fatalf("expected 1 pending chunk, got %d", len(ids))
+	}
+	if err := ds.MarkChunkEmbedFailed(ctx, ids[0], "permanent"); err != nil {
+		t.Fatalf("MarkChunkEmbedFailed: %v", err)
+	}
+	if got := pendingChunkIDs(t, ds); len(got) != 0 {
+		t.Errorf("quarantined chunk still pending: %v", got)
+	}
+
+	// An unchanged re-ingest carries the quarantine too; otherwise every sync
+	// would re-attempt a chunk already known to be unembeddable.
+	mustUpsert(t, ds, testDoc("", "one.txt", content), chunksOf(content))
+	if got := pendingChunkIDs(t, ds); len(got) != 0 {
+		t.Errorf("unchanged re-ingest lost the quarantine: %v pending", got)
+	}
+}
+
+func TestDocuments_SearchVectorAndHybrid(t *testing.T) {
+	ctx := context.Background()
+	ds := docStore(t, newTestStore(t))
+	const repo = "https://github.com/matthewjhunter/memstore"
+
+	near := "Tokens are hashed before they reach the database.\n"
+	far := "The chunker splits markdown at headings.\n"
+	mustUpsert(t, ds, testDoc(repo, "near.md", near), chunksOf(near))
+	mustUpsert(t, ds, testDoc(repo, "far.md", far), chunksOf(far))
+
+	// mockEmbedder embeds every single query as {0.1, 0.2, 0.3, 0.4}, so the
+	// chunk given that direction is the nearest neighbour.
+	for _, c := range mustPending(t, ds) {
+		vec := []float32{0.4, -0.3, 0.2, -0.1}
+		if c.Content == strings.TrimSuffix(near, "\n") {
+			vec = []float32{0.1, 0.2, 0.3, 0.4}
+		}
+		if err := ds.SetChunkEmbedding(ctx, c.ID, vec); err != nil {
+			t.Fatalf("SetChunkEmbedding: %v", err)
+		}
+	}
+
+	// No shared tokens with either chunk: FTS finds nothing, vector finds both.
+	const query = "credential storage"
+	fts, err := ds.SearchDocumentChunks(ctx, query, memstore.DocumentSearchOpts{})
+	if err != nil {
+		t.Fatalf("SearchDocumentChunks(fts): %v", err)
+	}
+	if len(fts) != 0 {
+		t.Fatalf("FTS unexpectedly matched: %+v", fts)
+	}
+
+	vec, err := ds.SearchDocumentChunks(ctx, query, memstore.DocumentSearchOpts{Mode: memstore.DocSearchVector})
+	if err != nil {
+		t.Fatalf("SearchDocumentChunks(vector): %v", err)
+	}
+	if len(vec) == 0 || vec[0].Path != "near.md" {
+		t.Fatalf("vector search did not rank the nearest chunk first: %+v", vec)
+	}
+	if vec[0].VecScore <= 0 || vec[0].Citation() == "" {
+		t.Errorf("vector hit missing score or citation: %+v", vec[0])
+	}
+
+	hybrid, err := ds.SearchDocumentChunks(ctx, "markdown headings", memstore.DocumentSearchOpts{Mode: memstore.DocSearchHybrid})
+	if err != nil {
+		t.Fatalf("SearchDocumentChunks(hybrid): %v", err)
+	}
+	if len(hybrid) != 2 {
+		t.Fatalf("hybrid should union both arms: got %d results", len(hybrid))
+	}
+	for _, r := range hybrid {
+		if r.Path == "far.md" && r.FTSScore != 1.0 {
+			t.Errorf("FTS-matched chunk not normalized to the arm's top: %+v", r)
+		}
+	}
+}
+
+func mustPending(t *testing.T, ds memstore.DocumentStore) []memstore.DocumentChunk {
+	t.Helper()
+	chunks, err := ds.NeedingChunkEmbedding(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("NeedingChunkEmbedding: %v", err)
+	}
+	return chunks
+}
@@ -0,0 +1,194 @@
+package pgstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/matthewjhunter/memstore"
+)
+
+// ExperimentStore records the online ranking experiments a daemon runs, per
+// namespace. memstored syncs its configured definitions into it at startup
+// and polls ActiveExperiments; admin commands list and stop experiments
+// through it. It is independent of PostgresStore -- its table is isolated.
+//
+// A stopped experiment stays stopped: re-syncing the same definition does not
+// restart it. To run it again, give it a new name, which also keeps the old
+// run's injections separate from the new one's.
+type ExperimentStore struct {
+	pool      *pgxpool.Pool
+	namespace string
+}
+
+// NewExperimentStore creates an ExperimentStore and runs its migrations.
+func NewExperimentStore(ctx context.Context, pool *pgxpool.Pool, namespace string) (*ExperimentStore, error) {
+	s := &ExperimentStore{pool: pool, namespace: namespace}
+	return s, s.migrate(ctx)
+}
+
+func (s *ExperimentStore) migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS ranking_experiments (
+			namespace   TEXT NOT NULL,
+			name        TEXT NOT NULL,
+			definition  JSONB NOT NULL,
+			started_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			stopped_at  TIMESTAMPTZ,
+			stop_reason TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (namespace, name)
+		)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore: experiment store migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// ExperimentStore feeds the daemon's recall handler and hint pipeline.
+var _ memstore.ExperimentSource = (*ExperimentStore)(nil)
+
+// experimentDefinition is the stored shape of an experiment: the parts that
+// define it, without its lifecycle timestamps.
+func experimentDefinition(e memstore.Experiment) ([]byte, error) {
+	e.StartedAt = time.Time{}
+	e.StoppedAt = nil
+	return json.Marshal(e)
+}
+
+// Sync makes the store match the daemon's configured experiments. New
+// definitions start; running experiments missing from defs stop with reason
+// "removed from config". A running experiment whose definition changed is an
+// error -- changing arms mid-run would mix two configurations under one
+// name -- and nothing is written. It returns the names it started and stopped.
+func (s *ExperimentStore) Sync(ctx context.Context, defs []memstore.Experiment) (started, stopped []string, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pgstore: sync experiments: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	names := make([]string, 0, len(defs))
+	for _, e := range defs {
+		if err := e.Validate(); err != nil {
+			return nil, nil, err
+		}
+		def, err := experimentDefinition(e)
+		if err != nil {
+			return nil, nil, fmt.Errorf("pgstore: sync experiments: %w", err)
+		}
+		names = append(names, e.Name)
+
+		var changed, running bool
+		err = tx.QueryRow(ctx, `
+			SELECT definition <> $3::jsonb, stopped_at IS NULL
+			FROM ranking_experiments WHERE namespace = $1 AND name = $2
+		`, s.namespace, e.Name, def).Scan(&changed, &running)
+		switch {
+		case err == pgx.ErrNoRows:
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO ranking_experiments(namespace, name, definition)
+				VALUES ($1, $2, $3)
+			`, s.namespace, e.Name, def); err != nil {
+				return nil, nil, fmt.Errorf("pgstore: start experiment %q: %w", e.Name, err)
+			}
+			started = append(started, e.Name)
+		case err != nil:
+			return nil, nil, fmt.Errorf("pgstore: sync experiment %q: %w", e.Name, err)
+		case running && changed:
+			return nil, nil, fmt.Errorf("pgstore: experiment %q is running with a different definition; stop it or rename the new one", e.Name)
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE ranking_experiments
+		SET stopped_at = NOW(), stop_reason = 'removed from config'
+		WHERE namespace = $1 AND stopped_at IS NULL AND NOT (name = ANY($2))
+		RETURNING name
+	`, s.namespace, names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pgstore: sync experiments: %w", err)
+	}
+	stopped, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, nil, fmt.Errorf("pgstore: sync experiments: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("pgstore: sync experiments: %w", err)
+	}
+	return started, stopped, nil
+}
+
+// ExperimentInfo is an experiment with its lifecycle, as admin tooling sees it.
+type ExperimentInfo struct {
+	memstore.Experiment
+	StopReason string
+}
+
+// ActiveExperiments returns the namespace's running experiments, oldest
+// first. When several enroll the same session, callers honor the oldest.
+func (s *ExperimentStore) ActiveExperiments(ctx context.Context) ([]memstore.Experiment, error) {
+	infos, err := s.list(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]memstore.Experiment, len(infos))
+	for i, info := range infos {
+		out[i] = info.Experiment
+	}
+	return out, nil
+}
+
+// List returns every experiment in the namespace, running or stopped, oldest
+// first.
+func (s *ExperimentStore) List(ctx context.Context) ([]ExperimentInfo, error) {
+	return s.list(ctx, false)
+}
+
+func (s *ExperimentStore) list(ctx context.Context, activeOnly bool) ([]ExperimentInfo, error) {
+	query := `
+		SELECT definition, started_at, stopped_at, stop_reason
+		FROM ranking_experiments WHERE namespace = $1`
+	if activeOnly {
+		query += ` AND stopped_at IS NULL`
+	}
+	query += ` ORDER BY started_at, name`
+	rows, err := s.pool.Query(ctx, query, s.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: list experiments: %w", err)
+	}
+	defer rows.Close()
+	var out []ExperimentInfo
+	for rows.Next() {
+		var def []byte
+		var info ExperimentInfo
+		if err := rows.Scan(&def, &info.StartedAt, &info.StoppedAt, &info.StopReason); err != nil {
+			return nil, fmt.Errorf("pgstore: list experiments: %w", err)
+		}
+		started, stoppedAt := info.StartedAt, info.StoppedAt
+		if err := json.Unmarshal(def, &info.Experiment); err != nil {
+			return nil, fmt.Errorf("pgstore: experiment definition: %w", err)
+		}
+		info.StartedAt, info.StoppedAt = started, stoppedAt
+		out = append(out, info)
+	}
+	return out, rows.Err()
+}
+
+// Stop ends a running experiment. It reports false when no running
+// experiment has that name. Daemons notice within their poll interval.
+func (s *ExperimentStore) Stop(ctx context.Context, name, reason string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE ranking_experiments SET stopped_at = NOW(), stop_reason = $3
+		WHERE namespace = $1 AND name = $2 AND stopped_at IS NULL
+	`, s.namespace, name, reason)
+	if err != nil {
+		return false, fmt.Errorf("pgstore: stop experiment %q: %w", name, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,140 @@
+package pgstore_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+	"github.com/matthewjhunter/memstore/pgstore"
+)
+
+func testExperiment(name string, arms ...string) memstore.Experiment {
+	e := memstore.Experiment{Name: name, Mode: memstore.ExperimentSplit}
+	for _, a := range arms {
+		e.Arms = append(e.Arms, memstore.ExperimentArm{Name: a})
+	}
+	return e
+}
+
+func TestExperimentStore_SyncAndStop(t *testing.T) {
+	_, pool := newTestSessionStore(t)
+	ctx := context.Background()
+	pool.Exec(ctx, `DROP TABLE IF EXISTS ranking_experiments`)
+	es, err := pgstore.NewExperimentStore(ctx, pool, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started, stopped, err := es.Sync(ctx, []memstore.Experiment{testExperiment("one", "a", "b"), testExperiment("two", "a", "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(started, []string{"one", "two"}) || len(stopped) != 0 {
+		t.Fatalf("first sync: started %v, stopped %v", started, stopped)
+	}
+
+	// Re-syncing the same definitions is a no-op.
+	if started, stopped, err = es.Sync(ctx, []memstore.Experiment{testExperiment("one", "a", "b"), testExperiment("two", "a", "b")}); err != nil || len(started)+len(stopped) != 0 {
+		t.Fatalf("re-sync: started %v, stopped %v, err %v", started, stopped, err)
+	}
+
+	// Changing a running experiment's arms is refused.
+	if _, _, err := es.Sync(ctx, []memstore.Experiment{testExperiment("one", "a", "c")}); err == nil {
+		t.Fatal("expected an error for a changed running definition")
+	}
+
+	// Dropping "two" from config stops it.
+	if _, stopped, err = es.Sync(ctx, []memstore.Experiment{testExperiment("one", "a", "b")}); err != nil || !slices.Equal(stopped, []string{"two"}) {
+		t.Fatalf("drop sync: stopped %v, err %v", stopped, err)
+	}
+
+	ok, err := es.Stop(ctx, "one", "arm b regressed")
+	if err != nil || !ok {
+		t.Fatalf("Stop: %v, %v", ok, err)
+	}
+	if ok, _ := es.Stop(ctx, "one", "again"); ok {
+		t.Error("stopping a stopped experiment reported success")
+	}
+	active, err := es.ActiveExperiments(ctx)
+	if err != nil || len(active) != 0 {
+		t.Fatalf("active after stop: %v, %v", active, err)
+	}
+
+	// A stopped experiment stays stopped on re-sync.
+	if started, _, err = es.Sync(ctx, []memstore.Experiment{testExperiment("one", "a", "b")}); err != nil || len(started) != 0 {
+		t.Fatalf("re-sync after stop: started %v, err %v", started, err)
+	}
+
+	infos, err := es.List(ctx)
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("List: %v, %v", infos, err)
+	}
+	for _, info := range infos {
+		if info.StoppedAt == nil || info.StartedAt.IsZero() || len(info.Arms) != 2 {
+			t.Errorf("experiment %s: %+v", info.Name, info)
+		}
+		if info.Name == "one" && info.StopReason != "arm b regressed" {
+			t.Errorf("stop reason = %q", info.StopReason)
+		}
+	}
+
+	// Namespaces are independent.
+	other, err := pgstore.NewExperimentStore(ctx, pool, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos, _ := other.List(ctx); len(infos) != 0 {
+		t.Errorf("other namespace sees %d experiments", len(infos))
+	}
+}
+
+func TestSessionStore_ExperimentFeedback(t *testing.T) {
+	ss, _ := newTestSessionStore(t)
+	ctx := context.Background()
+
+	ctl := memstore.ArmAssignment{Experiment: "floor", Arm: "control"}
+	loose := memstore.ArmAssignment{Experiment: "floor", Arm: "loose"}
+	for _, inj := range []struct {
+		session, ref string
+		arm          memstore.ArmAssignment
+	}{
+		{"s1", "1", ctl}, {"s1", "2", ctl},
+		{"s2", "1", loose}, {"s2", "3", loose}, {"s3", "4", loose},
+	} {
+		if err := ss.RecordArmInjection(ctx, inj.session, inj.ref, memstore.RefTypeFact, 0, inj.arm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Outside the experiment: must not be counted.
+	if err := ss.RecordInjection(ctx, "s4", "1", memstore.RefTypeFact, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, fb := range []memstore.ContextFeedback{
+		{RefID: "1", RefType: memstore.RefTypeFact, SessionID: "s1", Score: -1},
+		{RefID: "1", RefType: memstore.RefTypeFact, SessionID: "s2", Score: 1},
+		{RefID: "3", RefType: memstore.RefTypeFact, SessionID: "s2", Score: 1},
+		{RefID: "1", RefType: memstore.RefTypeFact, SessionID: "s4", Score: 1},
+	} {
+		if err := ss.RecordFeedback(ctx, fb); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	arms, err := ss.ExperimentFeedback(ctx, "floor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arms) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(arms), arms)
+	}
+	want := []memstore.ArmFeedback{
+		{Arm: "control", RefType: memstore.RefTypeFact, Sessions: 1, Injections: 2, Rated: 1, Negative: 1, AvgScore: -1},
+		{Arm: "loose", RefType: memstore.RefTypeFact, Sessions: 2, Injections: 3, Rated: 2, Positive: 2, AvgScore: 1},
+	}
+	for i := range want {
+		if arms[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, arms[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/matthewjhunter/memstore"
+)
+
+// migrateV8 adds fact expiry: expires_at is the caller-set deadline and
+// archived_at is stamped by the expiry reaper. The partial index keeps the
+// reaper's sweep off the (mostly non-expiring) bulk of the table.
+func (s *PostgresStore) migrateV8(ctx context.Context) error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ`,
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_expires ON memstore_facts (expires_at) WHERE expires_at IS NOT NULL`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V8 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+var _ memstore.ExpiryReaper = (*PostgresStore)(nil)
+
+// ReapExpired implements memstore.ExpiryReaper. A service-scoped store sweeps
+// every user's facts in its namespace.
+func (s *PostgresStore) ReapExpired(ctx context.Context, action memstore.ExpiryAction, now time.Time) (memstore.ExpiryResult, error) {
+	var res memstore.ExpiryResult
+	if action == memstore.ExpiryOff {
+		return res, nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return res, fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if action == memstore.ExpiryDelete {
+		q, args := s.userPredicate(
+			`UPDATE memstore_facts f SET deleted_at = $2
+			 WHERE namespace = $1 AND expires_at <= $2 AND deleted_at IS NULL
+			   AND NOT EXISTS (SELECT 1 FROM memstore_facts p WHERE p.superseded_by = f.id)`,
+			[]any{s.namespace, now})
+		if res.Deleted, err = collectIDs(ctx, tx, q+` RETURNING id`, args); err != nil {
+			return memstore.ExpiryResult{}, fmt.Errorf("pgstore: trashing expired facts: %w", err)
+		}
+	}
+	q, args := s.userPredicate(
+		`UPDATE memstore_facts SET archived_at = $2
+		 WHERE namespace = $1 AND expires_at <= $2 AND archived_at IS NULL AND deleted_at IS NULL`,
+		[]any{s.namespace, now})
+	if res.Archived, err = collectIDs(ctx, tx, q+` RETURNING id`, args); err != nil {
+		return memstore.ExpiryResult{}, fmt.Errorf("pgstore: archiving expired facts: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return memstore.ExpiryResult{}, fmt.Errorf("pgstore: committing expiry: %w", err)
+	}
+	return res, nil
+}
+
+// collectIDs runs a statement returning a single id column.
+func collectIDs(ctx context.Context, tx pgx.Tx, q string, args []any) ([]int64, error) {
+	rows, err := tx.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
@@ -0,0 +1,149 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/matthewjhunter/go-embedding"
+	"github.com/matthewjhunter/memstore"
+	pgvector "github.com/pgvector/pgvector-go"
+)
+
+// Merge consolidates several active facts into one new fact in a single
+// transaction. See memstore.Store.Merge. The sources must all belong to one
+// user; a service-scoped store cannot merge across users.
+func (s *PostgresStore) Merge(ctx context.Context, ids []int64, content string, patch map[string]any) (int64, error) {
+	ids, err := memstore.MergeSources(ids)
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(content) == "" {
+		return 0, errors.New("pgstore: merge: content is required")
+	}
+	prov, err := memstore.ResolveProvenance(ctx, memstore.Provenance{})
+	if err != nil {
+		return 0, err
+	}
+	var emb *pgvector.Vector
+	if s.embedder != nil {
+		if vec, err := embedding.Single(ctx, s.embedder, content); err == nil {
+			v := pgvector.NewVector(vec)
+			emb = &v
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	type source struct {
+		userID                             int64
+		subject, category, kind, subsystem string
+		metadata                           []byte
+		expiresAt                          *time.Time
+	}
+	q, args := s.userPredicate(
+		`SELECT id, user_id, subject, category, kind, subsystem, metadata, expires_at FROM memstore_facts
+		 WHERE namespace = $1 AND id = ANY($2::bigint[]) AND superseded_by IS NULL`+notDeleted(""),
+		[]any{s.namespace, ids})
+	rows, err := tx.Query(ctx, q+` FOR UPDATE`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: reading merge sources: %w", err)
+	}
+	sources := make(map[int64]source, len(ids))
+	for rows.Next() {
+		var id int64
+		var src source
+		if err := rows.Scan(&id, &src.userID, &src.subject, &src.category, &src.kind, &src.subsystem, &src.metadata, &src.expiresAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("pgstore: scanning merge source: %w", err)
+		}
+		sources[id] = src
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("pgstore: reading merge sources: %w", err)
+	}
+
+	expiries := make([]*time.Time, 0, len(ids))
+	for _, id := range ids {
+		src, ok := sources[id]
+		if !ok {
+			return 0, fmt.Errorf("pgstore: fact %d not found or already superseded", id)
+		}
+		if src.userID != sources[ids[0]].userID {
+			return 0, errors.New("pgstore: merge: facts belong to different users")
+		}
+		expiries = append(expiries, src.expiresAt)
+	}
+	first := sources[ids[0]]
+
+	provenance := maps.Clone(patch)
+	if provenance == nil {
+		provenance = make(map[string]any, 1)
+	}
+	provenance[memstore.MergedFromKey] = ids
+	merged, err := mergeMetadata(first.metadata, provenance)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: merging metadata for fact %d: %w", ids[0], err)
+	}
+	if len(patch) > 0 {
+		if err := s.schemas.Validate(first.kind, merged); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now().UTC()
+	var newID int64
+	err = tx.QueryRow(ctx,
+		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, expires_at, embedding, created_at,
+		                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		 RETURNING id`,
+		s.namespace, first.userID, content, first.subject, first.category, first.kind, first.subsystem,
+		merged, memstore.MergedExpiry(expiries), emb, now,
+		prov.Source, prov.SessionID, prov.TurnFirst, prov.TurnLast, prov.Origin, nullableID(prov.DocumentID),
+	).Scan(&newID)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: inserting merged fact: %w", err)
+	}
+
+	// Links between two sources would become self-links on the merged fact;
+	// drop them, re-point the rest, then collapse edges that became exact
+	// duplicates (two sources both referencing the same neighbour).
+	stmts := []struct {
+		q    string
+		args []any
+	}{
+		{`DELETE FROM memstore_links WHERE namespace = $1 AND source_id = ANY($2::bigint[]) AND target_id = ANY($2::bigint[])`,
+			[]any{s.namespace, ids}},
+		{`UPDATE memstore_links SET source_id = $1 WHERE namespace = $2 AND source_id = ANY($3::bigint[])`,
+			[]any{newID, s.namespace, ids}},
+		{`UPDATE memstore_links SET target_id = $1 WHERE namespace = $2 AND target_id = ANY($3::bigint[])`,
+			[]any{newID, s.namespace, ids}},
+		{`DELETE FROM memstore_links WHERE namespace = $1 AND (source_id = $2 OR target_id = $2) AND id NOT IN (
+			SELECT MIN(id) FROM memstore_links WHERE namespace = $1 AND (source_id = $2 OR target_id = $2)
+			GROUP BY source_id, target_id, link_type, bidirectional, label)`,
+			[]any{s.namespace, newID}},
+		{`INSERT INTO memstore_fact_tags (fact_id, tag) SELECT DISTINCT $1::bigint, tag FROM memstore_fact_tags WHERE fact_id = ANY($2::bigint[])`,
+			[]any{newID, ids}},
+		{`UPDATE memstore_facts SET superseded_by = $1, superseded_at = $2 WHERE namespace = $3 AND id = ANY($4::bigint[])`,
+			[]any{newID, now, s.namespace, ids}},
+	}
+	for _, st := range stmts {
+		if _, err := tx.Exec(ctx, st.q, st.args...); err != nil {
+			return 0, fmt.Errorf("pgstore: merging facts %v: %w", ids, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("pgstore: committing merge: %w", err)
+	}
+	return newID, nil
+}
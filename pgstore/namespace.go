@@ -0,0 +1,527 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.NamespaceAdmin = (*PostgresStore)(nil)
+
+// namespaceAuditDetail is the Detail recorded for a namespace operation.
+type namespaceAuditDetail struct {
+	From       string                   `json:"from,omitempty"`
+	To         string                   `json:"to,omitempty"`
+	Duplicates memstore.DuplicatePolicy `json:"duplicates,omitempty"`
+	Links      int                      `json:"links"`
+	Documents  int                      `json:"documents,omitempty"`
+}
+
+// copiedFactColumns are the memstore_facts columns CopyNamespace carries
+// over verbatim: all but the id, the namespace and owner it rewrites, and
+// the chain pointer it remaps.
+var copiedFactColumns = func() []string {
+	var cols []string
+	for _, c := range strings.Split(factColumns, ", ") {
+		switch c {
+		case "id", "namespace", "user_id", "superseded_by":
+		default:
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}()
+
+// ownerFilter appends the namespace operations' ownership predicate on col:
+// a user-scoped store covers its own user and that user's namesakes in
+// other namespaces; service scope covers everyone.
+func (s *PostgresStore) ownerFilter(b *queryBuilder, col string) {
+	if s.userID == 0 {
+		return
+	}
+	b.write(` AND `+col+` IN (SELECT id FROM memstore_users WHERE name = (SELECT name FROM memstore_users WHERE id = `, s.userID)
+	b.q += `))`
+}
+
+// Namespaces implements memstore.NamespaceAdmin. A user-scoped store counts
+// only its own user's data.
+func (s *PostgresStore) Namespaces(ctx context.Context) ([]memstore.NamespaceInfo, error) {
+	var b queryBuilder
+	b.q = `SELECT ns, SUM(facts)::bigint, SUM(active)::bigint, SUM(trashed)::bigint, SUM(links)::bigint, SUM(docs)::bigint FROM (
+		SELECT namespace AS ns,
+			COUNT(*) FILTER (WHERE deleted_at IS NULL) AS facts,
+			COUNT(*) FILTER (WHERE deleted_at IS NULL AND superseded_by IS NULL AND (expires_at IS NULL OR expires_at > NOW())) AS active,
+			COUNT(*) FILTER (WHERE deleted_at IS NOT NULL) AS trashed,
+			0 AS links, 0 AS docs
+		FROM memstore_facts WHERE TRUE`
+	s.ownerFilter(&b, "user_id")
+	b.q += ` GROUP BY namespace
+		UNION ALL
+		SELECT namespace, 0, 0, 0, COUNT(*), 0 FROM memstore_links WHERE TRUE`
+	s.ownerFilter(&b, "user_id")
+	b.q += ` GROUP BY namespace
+		UNION ALL
+		SELECT namespace, 0, 0, 0, 0, COUNT(*) FROM memstore_documents WHERE TRUE`
+	s.ownerFilter(&b, "user_id")
+	b.q += ` GROUP BY namespace
+	) t GROUP BY ns ORDER BY ns`
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: listing namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	var out []memstore.NamespaceInfo
+	for rows.Next() {
+		var n memstore.NamespaceInfo
+		if err := rows.Scan(&n.Namespace, &n.Facts, &n.Active, &n.Trashed, &n.Links, &n.Documents); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning namespace: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// RenameNamespace implements memstore.NamespaceAdmin.
+func (s *PostgresStore) RenameNamespace(ctx context.Context, from, to string) (*memstore.NamespaceResult, error) {
+	return s.moveNamespace(ctx, from, to, false, "")
+}
+
+// MergeNamespace implements memstore.NamespaceAdmin.
+func (s *PostgresStore) MergeNamespace(ctx context.Context, from, to string, dup memstore.DuplicatePolicy) (*memstore.NamespaceResult, error) {
+	dup, err := memstore.ParseDuplicatePolicy(string(dup))
+	if err != nil {
+		return nil, err
+	}
+	return s.moveNamespace(ctx, from, to, true, dup)
+}
+
+// moveNamespace re-labels from's rows in the store's scope as to's: a
+// rename when merge is false (to must be empty), a merge otherwise.
+func (s *PostgresStore) moveNamespace(ctx context.Context, from, to string, merge bool, dup memstore.DuplicatePolicy) (*memstore.NamespaceResult, error) {
+	if err := memstore.CheckNamespacePair(from, to); err != nil {
+		return nil, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	src, dst, err := s.namespaceOwners(ctx, tx, from, to, merge)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := namespaceFactIDs(ctx, tx, from, src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pair duplicates before the move, while the two sides are still
+	// told apart by namespace.
+	var dups [][2]int64
+	if merge && dup == memstore.DuplicatesSupersede {
+		if dups, err = namespaceDuplicates(ctx, tx, from, to, src, dst); err != nil {
+			return nil, err
+		}
+	}
+	var kept []int64
+	if merge {
+		if kept, err = mergeNamespaceDocuments(ctx, tx, from, to, src, dst); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &memstore.NamespaceResult{Facts: len(ids)}
+	for _, table := range []string{"memstore_facts", "memstore_links", "memstore_documents", "memstore_document_chunks", "memstore_fact_citations", "memstore_audit"} {
+		ct, err := tx.Exec(ctx,
+			`UPDATE `+table+` t SET namespace = $1, user_id = m.dst
+			 FROM unnest($2::bigint[], $3::bigint[]) AS m(src, dst)
+			 WHERE t.namespace = $4 AND t.user_id = m.src`,
+			to, src, dst, from)
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: moving %s to namespace %q: %w", table, to, err)
+		}
+		switch table {
+		case "memstore_links":
+			res.Links = int(ct.RowsAffected())
+		case "memstore_documents":
+			res.Documents = int(ct.RowsAffected())
+		}
+	}
+	if s.userID == 0 {
+		// Service-scope entries belong to no user and move with the namespace.
+		if _, err := tx.Exec(ctx,
+			`UPDATE memstore_audit SET namespace = $1 WHERE namespace = $2 AND user_id IS NULL`, to, from,
+		); err != nil {
+			return nil, fmt.Errorf("pgstore: moving audit log to namespace %q: %w", to, err)
+		}
+	}
+	if err := copyNamespaceAliases(ctx, tx, from, to, src, dst); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM memstore_subject_aliases WHERE namespace = $1 AND user_id = ANY($2::bigint[])`, from, src,
+	); err != nil {
+		return nil, fmt.Errorf("pgstore: moving aliases to namespace %q: %w", to, err)
+	}
+
+	for _, docID := range kept {
+		if err := reresolveDocument(ctx, tx, docID); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range dups {
+		if _, err := tx.Exec(ctx,
+			`UPDATE memstore_facts SET superseded_by = $1, superseded_at = NOW() WHERE id = $2`, d[1], d[0],
+		); err != nil {
+			return nil, fmt.Errorf("pgstore: superseding duplicate fact %d: %w", d[0], err)
+		}
+	}
+	res.Duplicates = len(dups)
+
+	action := memstore.AuditNamespaceRename
+	detail := namespaceAuditDetail{From: from, To: to, Links: res.Links, Documents: res.Documents}
+	if merge {
+		action, detail.Duplicates = memstore.AuditNamespaceMerge, dup
+	}
+	if res.AuditID, err = s.recordAudit(ctx, tx, to, s.auditOwner(dst), action, detail, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing namespace %s: %w", action, err)
+	}
+	return res, nil
+}
+
+// CopyNamespace implements memstore.NamespaceAdmin. New fact IDs are drawn
+// up front so the chains and links can be remapped in single statements.
+func (s *PostgresStore) CopyNamespace(ctx context.Context, from, to string) (*memstore.NamespaceResult, error) {
+	if err := memstore.CheckNamespacePair(from, to); err != nil {
+		return nil, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	src, dst, err := s.namespaceOwners(ctx, tx, from, to, false)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx,
+		`SELECT id, nextval(pg_get_serial_sequence('memstore_facts', 'id')) FROM memstore_facts
+		 WHERE namespace = $1 AND user_id = ANY($2::bigint[]) ORDER BY id`, from, src)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: reading namespace %q: %w", from, err)
+	}
+	var oldIDs, newIDs []int64
+	for rows.Next() {
+		var o, n int64
+		if err := rows.Scan(&o, &n); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("pgstore: scanning fact id: %w", err)
+		}
+		oldIDs, newIDs = append(oldIDs, o), append(newIDs, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: reading namespace %q: %w", from, err)
+	}
+
+	// Foreign keys are checked at the end of the statement, so a chain
+	// may point at a copy inserted later in the same INSERT.
+	cols := strings.Join(copiedFactColumns, ", ")
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memstore_facts (id, namespace, user_id, superseded_by, `+cols+`)
+		 SELECT m.new, $1, u.dst, sm.new, f.`+strings.Join(copiedFactColumns, ", f.")+`
+		 FROM memstore_facts f
+		 JOIN unnest($2::bigint[], $3::bigint[]) AS m(old, new) ON m.old = f.id
+		 JOIN unnest($4::bigint[], $5::bigint[]) AS u(src, dst) ON u.src = f.user_id
+		 LEFT JOIN unnest($2::bigint[], $3::bigint[]) AS sm(old, new) ON sm.old = f.superseded_by`,
+		to, oldIDs, newIDs, src, dst,
+	); err != nil {
+		return nil, fmt.Errorf("pgstore: copying facts to namespace %q: %w", to, err)
+	}
+	ct, err := tx.Exec(ctx,
+		`INSERT INTO memstore_links (namespace, user_id, source_id, target_id, link_type, bidirectional, label, metadata, created_at)
+		 SELECT $1, u.dst, ms.new, mt.new, l.link_type, l.bidirectional, l.label, l.metadata, l.created_at
+		 FROM memstore_links l
+		 JOIN unnest($2::bigint[], $3::bigint[]) AS ms(old, new) ON ms.old = l.source_id
+		 JOIN unnest($2::bigint[], $3::bigint[]) AS mt(old, new) ON mt.old = l.target_id
+		 JOIN unnest($4::bigint[], $5::bigint[]) AS u(src, dst) ON u.src = l.user_id
+		 WHERE l.namespace = $6
+		 ORDER BY l.id`,
+		to, oldIDs, newIDs, src, dst, from)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: copying links to namespace %q: %w", to, err)
+	}
+	if err := copyNamespaceAliases(ctx, tx, from, to, src, dst); err != nil {
+		return nil, err
+	}
+	if err := copyNamespacePins(ctx, tx, oldIDs, newIDs); err != nil {
+		return nil, err
+	}
+	if err := copyNamespaceTags(ctx, tx, oldIDs, newIDs); err != nil {
+		return nil, err
+	}
+
+	res := &memstore.NamespaceResult{Facts: len(newIDs), Links: int(ct.RowsAffected())}
+	detail := namespaceAuditDetail{From: from, To: to, Links: res.Links}
+	if res.AuditID, err = s.recordAudit(ctx, tx, to, s.auditOwner(dst), memstore.AuditNamespaceCopy, detail, newIDs); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing namespace copy: %w", err)
+	}
+	return res, nil
+}
+
+// DeleteNamespace implements memstore.NamespaceAdmin. A user-scoped store
+// deletes only its own user's data.
+func (s *PostgresStore) DeleteNamespace(ctx context.Context, ns string) (*memstore.NamespaceResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	owners, _, err := s.namespaceUsers(ctx, tx, ns)
+	if err != nil {
+		return nil, err
+	}
+	if len(owners) == 0 {
+		return nil, fmt.Errorf("pgstore: namespace %q is empty", ns)
+	}
+	ids, err := namespaceFactIDs(ctx, tx, ns, owners)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &memstore.NamespaceResult{Facts: len(ids)}
+	// Links first, then facts (their citations cascade), then documents
+	// (their chunks cascade), then aliases. Supersession chains never leave
+	// an owner, so each chain goes in the one statement.
+	for _, table := range []string{"memstore_links", "memstore_facts", "memstore_documents", "memstore_subject_aliases"} {
+		ct, err := tx.Exec(ctx,
+			`DELETE FROM `+table+` WHERE namespace = $1 AND user_id = ANY($2::bigint[])`, ns, owners)
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: deleting %s of namespace %q: %w", table, ns, err)
+		}
+		switch table {
+		case "memstore_links":
+			res.Links = int(ct.RowsAffected())
+		case "memstore_documents":
+			res.Documents = int(ct.RowsAffected())
+		}
+	}
+
+	detail := namespaceAuditDetail{From: ns, Links: res.Links, Documents: res.Documents}
+	if res.AuditID, err = s.recordAudit(ctx, tx, ns, s.auditOwner(owners), memstore.AuditNamespaceDelete, detail, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing namespace delete: %w", err)
+	}
+	return res, nil
+}
+
+// namespaceUsers returns the users owning facts, links, documents or aliases in ns
+// that the store's scope covers.
+func (s *PostgresStore) namespaceUsers(ctx context.Context, tx pgx.Tx, ns string) (ids []int64, names []string, err error) {
+	var b queryBuilder
+	b.write(`SELECT id, name FROM memstore_users WHERE id IN (
+		SELECT user_id FROM memstore_facts WHERE namespace = `, ns)
+	b.q += ` UNION SELECT user_id FROM memstore_links WHERE namespace = $1
+		UNION SELECT user_id FROM memstore_documents WHERE namespace = $1
+		UNION SELECT user_id FROM memstore_subject_aliases WHERE namespace = $1)`
+	s.ownerFilter(&b, "id")
+	b.q += ` ORDER BY id`
+	rows, err := tx.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pgstore: reading owners in namespace %q: %w", ns, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, fmt.Errorf("pgstore: scanning owner: %w", err)
+		}
+		ids, names = append(ids, id), append(names, name)
+	}
+	return ids, names, rows.Err()
+}
+
+// namespaceOwners pairs each user owning data in from, within the store's
+// scope, with the user of the same name in to, creating it if need be. It
+// fails if from holds nothing in scope and, unless merge, if to already
+// holds something in scope.
+func (s *PostgresStore) namespaceOwners(ctx context.Context, tx pgx.Tx, from, to string, merge bool) (src, dst []int64, err error) {
+	src, names, err := s.namespaceUsers(ctx, tx, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(src) == 0 {
+		return nil, nil, fmt.Errorf("pgstore: namespace %q is empty", from)
+	}
+	for _, name := range names {
+		var id int64
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO memstore_users (namespace, name) VALUES ($1, $2)
+			 ON CONFLICT (namespace, name) DO UPDATE SET name = EXCLUDED.name
+			 RETURNING id`, to, name,
+		).Scan(&id); err != nil {
+			return nil, nil, fmt.Errorf("pgstore: resolving user %q in namespace %q: %w", name, to, err)
+		}
+		dst = append(dst, id)
+	}
+	if merge {
+		return src, dst, nil
+	}
+
+	occupied, _, err := s.namespaceUsers(ctx, tx, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(occupied) > 0 {
+		return nil, nil, fmt.Errorf("%w (%q)", memstore.ErrNamespaceNotEmpty, to)
+	}
+	return src, dst, nil
+}
+
+// auditOwner returns the user scope to record a namespace operation under:
+// the store's user's namesake among users -- the only user a user-scoped
+// store acts on -- or 0 for service scope.
+func (s *PostgresStore) auditOwner(users []int64) int64 {
+	if s.userID == 0 {
+		return 0
+	}
+	return users[0]
+}
+
+// namespaceFactIDs returns the IDs of the facts the owners hold in ns,
+// trash included.
+func namespaceFactIDs(ctx context.Context, tx pgx.Tx, ns string, owners []int64) ([]int64, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM memstore_facts WHERE namespace = $1 AND user_id = ANY($2::bigint[]) ORDER BY id FOR UPDATE`,
+		ns, owners)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: reading namespace %q: %w", ns, err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: reading namespace %q: %w", ns, err)
+	}
+	return ids, nil
+}
+
+// namespaceDuplicates pairs each active fact in from with the oldest active
+// fact in to that has the same subject and content under the paired owner.
+func namespaceDuplicates(ctx context.Context, tx pgx.Tx, from, to string, src, dst []int64) ([][2]int64, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT s.id, MIN(d.id) FROM memstore_facts s
+		 JOIN unnest($1::bigint[], $2::bigint[]) AS m(src, dst) ON m.src = s.user_id
+		 JOIN memstore_facts d ON d.namespace = $3 AND d.user_id = m.dst
+		     AND d.subject = s.subject AND d.content = s.content
+		     AND d.superseded_by IS NULL AND d.deleted_at IS NULL
+		 WHERE s.namespace = $4 AND s.superseded_by IS NULL AND s.deleted_at IS NULL
+		 GROUP BY s.id ORDER BY s.id`,
+		src, dst, to, from)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: finding duplicates: %w", err)
+	}
+	defer rows.Close()
+	var pairs [][2]int64
+	for rows.Next() {
+		var p [2]int64
+		if err := rows.Scan(&p[0], &p[1]); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning duplicate: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// mergeNamespaceDocuments resolves documents present under the same
+// identity on both sides of a merge: the destination's copy is kept, the
+// source's citations and fact provenance are re-pointed at it, and the
+// source's copy is deleted. It returns the kept documents, whose citations
+// are re-resolved once they have moved.
+func mergeNamespaceDocuments(ctx context.Context, tx pgx.Tx, from, to string, src, dst []int64) ([]int64, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT s.id, d.id FROM memstore_documents s
+		 JOIN unnest($1::bigint[], $2::bigint[]) AS m(src, dst) ON m.src = s.user_id
+		 JOIN memstore_documents d ON d.namespace = $3 AND d.user_id = m.dst
+		     AND d.repo_url IS NOT DISTINCT FROM s.repo_url AND d.path = s.path
+		 WHERE s.namespace = $4
+		 ORDER BY s.id`,
+		src, dst, to, from)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: matching documents: %w", err)
+	}
+	var dropped, kept []int64
+	for rows.Next() {
+		var s, d int64
+		if err := rows.Scan(&s, &d); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("pgstore: scanning document pair: %w", err)
+		}
+		dropped, kept = append(dropped, s), append(kept, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(dropped) == 0 {
+		return nil, err
+	}
+
+	stmts := []struct {
+		q    string
+		args []any
+	}{
+		{`UPDATE memstore_fact_citations c SET document_id = m.kept, chunk_id = NULL
+		  FROM unnest($1::bigint[], $2::bigint[]) AS m(dropped, kept) WHERE c.document_id = m.dropped`,
+			[]any{dropped, kept}},
+		{`UPDATE memstore_facts f SET source_document_id = m.kept
+		  FROM unnest($1::bigint[], $2::bigint[]) AS m(dropped, kept) WHERE f.source_document_id = m.dropped`,
+			[]any{dropped, kept}},
+		{`DELETE FROM memstore_documents WHERE id = ANY($1::bigint[])`, []any{dropped}},
+	}
+	for _, st := range stmts {
+		if _, err := tx.Exec(ctx, st.q, st.args...); err != nil {
+			return nil, fmt.Errorf("pgstore: merging documents: %w", err)
+		}
+	}
+	return kept, nil
+}
+
+// reresolveDocument re-resolves the citations of a document against its
+// current chunks.
+func reresolveDocument(ctx context.Context, tx pgx.Tx, docID int64) error {
+	doc, err := scanDocument(tx.QueryRow(ctx, `SELECT `+docColumns+` FROM memstore_documents WHERE id = $1`, docID))
+	if err != nil {
+		return fmt.Errorf("pgstore: loading document %d: %w", docID, err)
+	}
+	rows, err := tx.Query(ctx, `SELECT `+docChunkColumns+` FROM memstore_document_chunks WHERE document_id = $1 ORDER BY ordinal`, docID)
+	if err != nil {
+		return fmt.Errorf("pgstore: loading chunks of document %d: %w", docID, err)
+	}
+	var chunks []memstore.DocumentChunk
+	for rows.Next() {
+		c, err := scanDocumentChunk(rows)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("pgstore: scanning chunk: %w", err)
+		}
+		chunks = append(chunks, *c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("pgstore: loading chunks of document %d: %w", docID, err)
+	}
+	return resolveCitations(ctx, tx, doc.Namespace, doc.UserID, docID, *doc, chunks)
+}
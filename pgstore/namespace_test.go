@@ -0,0 +1,86 @@
+package pgstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestNamespaceAdmin(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	old, err := store.Insert(ctx, memstore.Fact{Content: "deploys go out on Fridays", Subject: "release", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := store.Insert(ctx, memstore.Fact{Content: "deploys go out on Tuesdays", Subject: "release", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Supersede(ctx, old, head); err != nil {
+		t.Fatal(err)
+	}
+	owner, err := store.Insert(ctx, memstore.Fact{Content: "ops owns the release train", Subject: "ops", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.LinkFacts(ctx, head, owner, "reference", false, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := store.CopyNamespace(ctx, "test", "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied.Facts != 3 || copied.Links != 1 {
+		t.Errorf("copy = %+v, want 3 facts and 1 link", copied)
+	}
+	if _, err := store.CopyNamespace(ctx, "test", "copy"); !errors.Is(err, memstore.ErrNamespaceNotEmpty) {
+		t.Errorf("second copy = %v, want ErrNamespaceNotEmpty", err)
+	}
+
+	infos, err := store.Namespaces(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []memstore.NamespaceInfo{
+		{Namespace: "copy", Facts: 3, Active: 2, Links: 1},
+		{Namespace: "test", Facts: 3, Active: 2, Links: 1},
+	}
+	if len(infos) != 2 || infos[0] != want[0] || infos[1] != want[1] {
+		t.Fatalf("Namespaces = %+v, want %+v", infos, want)
+	}
+
+	// Every active copy duplicates an original.
+	merged, err := store.MergeNamespace(ctx, "copy", "test", memstore.DuplicatesSupersede)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Facts != 3 || merged.Links != 1 || merged.Duplicates != 2 {
+		t.Errorf("merge = %+v, want 3 facts, 1 link, 2 duplicates", merged)
+	}
+	if n, err := store.ActiveCount(ctx); err != nil || n != 2 {
+		t.Errorf("ActiveCount after merge = %d, %v; want 2", n, err)
+	}
+	if hits, err := store.Search(ctx, "Tuesdays", memstore.SearchOpts{MaxResults: 5, OnlyActive: true}); err != nil || len(hits) != 1 || hits[0].Fact.ID != head {
+		t.Errorf("search after merge = %+v, %v; want fact %d", hits, err, head)
+	}
+	log, err := store.AuditLog(ctx, 1)
+	if err != nil || len(log) != 1 || log[0].Action != memstore.AuditNamespaceMerge {
+		t.Errorf("AuditLog = %+v, %v", log, err)
+	}
+
+	deleted, err := store.DeleteNamespace(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.Facts != 6 || deleted.Links != 2 {
+		t.Errorf("delete = %+v, want 6 facts and 2 links", deleted)
+	}
+	if infos, err := store.Namespaces(ctx); err != nil || len(infos) != 0 {
+		t.Errorf("Namespaces after delete = %+v, %v", infos, err)
+	}
+}
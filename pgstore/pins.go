@@ -0,0 +1,158 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.Pinner = (*PostgresStore)(nil)
+
+// migrateV15 creates the pin table. A pin is keyed by the fact it pins and
+// goes with it when the fact is purged; namespace and owner come from the
+// fact.
+func (s *PostgresStore) migrateV15(ctx context.Context) error {
+	stmt := `CREATE TABLE IF NOT EXISTS memstore_pins (
+		fact_id      BIGINT PRIMARY KEY REFERENCES memstore_facts(id) ON DELETE CASCADE,
+		subject      TEXT NOT NULL DEFAULT '',
+		project_path TEXT NOT NULL DEFAULT '',
+		position     INTEGER NOT NULL,
+		created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`
+	if _, err := s.pool.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("pgstore V15 migration: %w\nstatement: %s", err, stmt)
+	}
+	return nil
+}
+
+// Pin implements memstore.Pinner.
+func (s *PostgresStore) Pin(ctx context.Context, p memstore.Pin) (*memstore.Pin, error) {
+	if err := memstore.CheckPin(p); err != nil {
+		return nil, err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		owner        int64
+		supersededBy *int64
+	)
+	q, args := s.userPredicate(
+		`SELECT user_id, superseded_by FROM memstore_facts WHERE id = $1 AND namespace = $2`+notDeleted(""),
+		[]any{p.FactID, s.namespace})
+	err = tx.QueryRow(ctx, q+` FOR UPDATE`, args...).Scan(&owner, &supersededBy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: fact %d not found", memstore.ErrNotPinnable, p.FactID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: looking up fact %d: %w", p.FactID, err)
+	}
+	if supersededBy != nil {
+		return nil, fmt.Errorf("%w: fact %d is superseded by %d", memstore.ErrNotPinnable, p.FactID, *supersededBy)
+	}
+
+	if p.Position == 0 {
+		err := tx.QueryRow(ctx, `SELECT position FROM memstore_pins WHERE fact_id = $1`, p.FactID).Scan(&p.Position)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Append after the fact owner's other pins.
+			err = tx.QueryRow(ctx,
+				`SELECT COALESCE(MAX(p.position), 0) + 1 FROM memstore_pins p
+				 JOIN memstore_facts f ON f.id = p.fact_id WHERE f.namespace = $1 AND f.user_id = $2`,
+				s.namespace, owner).Scan(&p.Position)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: placing pin: %w", err)
+		}
+	}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO memstore_pins (fact_id, subject, project_path, position) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (fact_id) DO UPDATE SET subject = EXCLUDED.subject, project_path = EXCLUDED.project_path, position = EXCLUDED.position
+		 RETURNING created_at`,
+		p.FactID, p.Subject, p.ProjectPath, p.Position).Scan(&p.CreatedAt); err != nil {
+		return nil, fmt.Errorf("pgstore: pinning fact %d: %w", p.FactID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("pgstore: committing pin: %w", err)
+	}
+	return &p, nil
+}
+
+// pinHeads starts a query with the CTE that walks each pin in the store's
+// scope down its supersession chain; chain(pin_id, id) holds every version.
+func (s *PostgresStore) pinHeads(b *queryBuilder) {
+	b.write(`WITH RECURSIVE chain(pin_id, id) AS (
+		SELECT p.fact_id, p.fact_id FROM memstore_pins p JOIN memstore_facts f ON f.id = p.fact_id
+		WHERE f.namespace = `, s.namespace)
+	s.appendUserFilter(b, "f.user_id")
+	b.q += `
+		UNION
+		SELECT c.pin_id, f.superseded_by FROM chain c JOIN memstore_facts f ON f.id = c.id WHERE f.superseded_by IS NOT NULL
+	) `
+}
+
+// Unpin implements memstore.Pinner. factID may also be a later version of
+// the pinned fact, as Pins reports it.
+func (s *PostgresStore) Unpin(ctx context.Context, factID int64) error {
+	var b queryBuilder
+	s.pinHeads(&b)
+	b.write(`DELETE FROM memstore_pins WHERE fact_id IN (SELECT pin_id FROM chain WHERE pin_id = `, factID)
+	b.write(` OR id = `, factID)
+	b.q += `)`
+	ct, err := s.pool.Exec(ctx, b.q, b.args...)
+	if err != nil {
+		return fmt.Errorf("pgstore: unpinning fact %d: %w", factID, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return memstore.ErrPinNotFound
+	}
+	return nil
+}
+
+// Pins implements memstore.Pinner. Service scope lists every user's pins.
+func (s *PostgresStore) Pins(ctx context.Context) ([]memstore.PinnedFact, error) {
+	var b queryBuilder
+	s.pinHeads(&b)
+	b.q += `SELECT ` + qualifiedFactColumns("f.") + `, p.fact_id, p.subject, p.project_path, p.position, p.created_at
+		FROM memstore_pins p
+		JOIN chain c ON c.pin_id = p.fact_id
+		JOIN memstore_facts f ON f.id = c.id
+		WHERE f.superseded_by IS NULL` + notDeleted("f.") + unexpired("f.") + `
+		ORDER BY p.position, p.fact_id`
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: listing pins: %w", err)
+	}
+	defer rows.Close()
+
+	var out []memstore.PinnedFact
+	for rows.Next() {
+		var pf memstore.PinnedFact
+		f, err := scanFact(rows, &pf.FactID, &pf.Subject, &pf.ProjectPath, &pf.Position, &pf.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("pgstore: scanning pin: %w", err)
+		}
+		pf.Fact = *f
+		out = append(out, pf)
+	}
+	return out, rows.Err()
+}
+
+// copyNamespacePins copies the pins on facts copied to another namespace,
+// re-keyed from oldIDs to the matching newIDs.
+func copyNamespacePins(ctx context.Context, tx pgx.Tx, oldIDs, newIDs []int64) error {
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memstore_pins (fact_id, subject, project_path, position, created_at)
+		 SELECT m.new, p.subject, p.project_path, p.position, p.created_at
+		 FROM memstore_pins p
+		 JOIN unnest($1::bigint[], $2::bigint[]) AS m(old, new) ON m.old = p.fact_id`,
+		oldIDs, newIDs); err != nil {
+		return fmt.Errorf("pgstore: copying pins: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package pgstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestPins(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	rule, err := store.Insert(ctx, memstore.Fact{Content: "never commit generated protobufs", Subject: "memstore", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := store.Insert(ctx, memstore.Fact{Content: "run make lint before pushing", Subject: "tooling", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Pin(ctx, memstore.Pin{FactID: rule, Subject: "memstore", Position: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := store.Pin(ctx, memstore.Pin{FactID: path, ProjectPath: "/src/memstore", Position: 1}); err != nil || p.Position != 1 {
+		t.Fatalf("Pin(path) = %+v, %v", p, err)
+	}
+
+	revised, err := store.Revise(ctx, rule, "never commit generated protobufs or mocks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins := memstore.PinsFor(ctx, store, "memstore", "/src/memstore/cmd", nil)
+	if len(pins) != 2 || pins[0].Fact.ID != path || pins[1].Fact.ID != revised {
+		t.Fatalf("PinsFor = %+v, want path then the revised rule", pins)
+	}
+
+	if err := store.Unpin(ctx, revised); err != nil {
+		t.Fatalf("Unpin by the revised ID: %v", err)
+	}
+	if err := store.Unpin(ctx, rule); !errors.Is(err, memstore.ErrPinNotFound) {
+		t.Errorf("second Unpin = %v, want ErrPinNotFound", err)
+	}
+}
@@ -0,0 +1,56 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// migrateV11 adds the typed provenance columns (see memstore.Provenance).
+// Existing rows keep the empty defaults, which read back as an unknown
+// provenance. A cited document that is later deleted drops the citation
+// rather than the fact.
+func (s *PostgresStore) migrateV11(ctx context.Context) error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS source_session TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS source_turn_first TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS source_turn_last TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS source_origin TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS source_document_id BIGINT
+			REFERENCES memstore_documents(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_source_session ON memstore_facts (source_session) WHERE source_session <> ''`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V11 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// appendProvenanceFilter appends WHERE clauses for pf. alias is a table
+// alias prefix such as "f." or "".
+func appendProvenanceFilter(b *queryBuilder, alias string, pf memstore.ProvenanceFilter) {
+	if pf.Source != "" {
+		b.write(` AND `+alias+`source = `, pf.Source)
+	}
+	if pf.SessionID != "" {
+		b.write(` AND `+alias+`source_session = `, pf.SessionID)
+	}
+	if pf.Origin != "" {
+		b.write(` AND `+alias+`source_origin = `, pf.Origin)
+	}
+	if pf.DocumentID != 0 {
+		b.write(` AND `+alias+`source_document_id = `, pf.DocumentID)
+	}
+}
+
+// nullableID maps 0 to NULL for optional ID columns.
+func nullableID(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
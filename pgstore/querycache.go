@@ -0,0 +1,123 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// migrateV7 creates the persistent query-embedding cache behind the
+// in-process LRU, so a daemon restart or a second process sharing the
+// database does not re-embed prompts it has already seen. Rows carry no
+// namespace: an embedding depends only on the model and the text. The vector
+// is a REAL[] rather than a pgvector column because it is only ever read
+// back whole, never searched.
+func (s *PostgresStore) migrateV7(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_query_cache (
+			model        TEXT NOT NULL,
+			query_hash   TEXT NOT NULL,
+			embedding    REAL[] NOT NULL,
+			created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (model, query_hash)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_query_cache_used ON memstore_query_cache (last_used_at)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V7 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// SetQueryCache replaces the persistent query-embedding cache policy used by
+// Search, SearchBatch, and vector document search. Stores start with
+// memstore.DefaultQueryCachePolicy; a policy with a negative MaxEntries
+// disables the table, leaving only the in-process LRU. Intended to be called
+// once at startup.
+func (s *PostgresStore) SetQueryCache(policy memstore.QueryCachePolicy) {
+	s.queryEmbedder = memstore.NewQueryCacheEmbedder(s.embedder, pgQueryCache{s}, policy)
+}
+
+// pgQueryCache is the Postgres memstore.QueryCacheTable, in
+// memstore_query_cache.
+type pgQueryCache struct{ s *PostgresStore }
+
+func (c pgQueryCache) GetQueryEmbeddings(ctx context.Context, model string, hashes []string, notBefore time.Time) (map[string][]float32, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	rows, err := c.s.pool.Query(ctx, `
+		UPDATE memstore_query_cache SET last_used_at = NOW()
+		WHERE model = $1 AND query_hash = ANY($2) AND created_at >= $3
+		RETURNING query_hash, embedding`,
+		model, hashes, notBefore,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string][]float32)
+	for rows.Next() {
+		var h string
+		var emb []float32
+		if err := rows.Scan(&h, &emb); err != nil {
+			return nil, err
+		}
+		out[h] = emb
+	}
+	return out, rows.Err()
+}
+
+func (c pgQueryCache) PutQueryEmbeddings(ctx context.Context, model string, embs map[string][]float32) error {
+	tx, err := c.s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for h, emb := range embs {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO memstore_query_cache (model, query_hash, embedding)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (model, query_hash) DO UPDATE SET
+				embedding = EXCLUDED.embedding, created_at = NOW(), last_used_at = NOW()`,
+			model, h, emb,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (c pgQueryCache) TrimQueryEmbeddings(ctx context.Context, notBefore time.Time, maxEntries int) error {
+	if _, err := c.s.pool.Exec(ctx,
+		`DELETE FROM memstore_query_cache WHERE created_at < $1`, notBefore,
+	); err != nil {
+		return err
+	}
+	_, err := c.s.pool.Exec(ctx, `
+		DELETE FROM memstore_query_cache WHERE ctid IN (
+			SELECT ctid FROM memstore_query_cache
+			ORDER BY last_used_at DESC
+			OFFSET $1
+		)`, maxEntries,
+	)
+	return err
+}
+
+// purgeQueryCacheModels deletes cached query embeddings from models other
+// than the database's recorded embedding model, which every process that
+// opens the database must embed with. Called once at open.
+func (s *PostgresStore) purgeQueryCacheModels(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM memstore_query_cache
+		WHERE model <> (SELECT value FROM memstore_meta WHERE key = 'embedding_model')`)
+	if err != nil {
+		return fmt.Errorf("pgstore: purging stale query cache: %w", err)
+	}
+	return nil
+}
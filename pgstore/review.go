@@ -0,0 +1,48 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+var _ memstore.Reviewer = (*PostgresStore)(nil)
+
+// ReviewQueue implements memstore.Reviewer. Candidates are selected here and
+// ranked by memstore.RankReviewCited with the store's feedback stage, so
+// negative ratings from the session log raise a fact's priority, as do
+// citations whose code changed or vanished.
+func (s *PostgresStore) ReviewQueue(ctx context.Context, opts memstore.ReviewOpts) ([]memstore.ReviewItem, error) {
+	if opts.MinAge <= 0 {
+		opts.MinAge = memstore.DefaultReviewMinAge
+	}
+	now := time.Now()
+
+	var b queryBuilder
+	b.q = `SELECT ` + factColumns + ` FROM memstore_facts
+	       WHERE superseded_by IS NULL` + unexpired("") + notDeleted("")
+	b.write(` AND namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	b.write(` AND (COALESCE(last_confirmed_at, created_at) <= `, now.Add(-opts.MinAge).UTC())
+	b.q += ` OR id IN (SELECT fact_id FROM memstore_fact_citations WHERE status <> 'current'))`
+	if opts.Subject != "" {
+		b.write(` AND subject = `, opts.Subject)
+	}
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: querying review candidates: %w", err)
+	}
+	facts, err := scanFacts(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	stale, err := s.staleCitationCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return memstore.RankReviewCited(ctx, facts, stale, s.feedback, opts, now), nil
+}
@@ -2,9 +2,7 @@ package pgstore
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/matthewjhunter/memstore"
 	pgvector "github.com/pgvector/pgvector-go"
@@ -28,7 +26,7 @@ func (s *PostgresStore) Search(ctx context.Context, query string, opts memstore.
 		opts.RerankCandidates = memstore.DefaultRerankCandidates
 	}
 
-	queryEmb, err := s.queryCache.Single(ctx, s.embedder, query)
+	queryEmb, err := s.queryCache.Single(ctx, s.queryEmbedder, query)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +44,11 @@ func (s *PostgresStore) Search(ctx context.Context, query string, opts memstore.
 		}
 	}
 
-	return memstore.ScoreResults(ctx, s.reranker, query, ftsResults, vecResults, opts)
+	results, err := memstore.ScoreResults(ctx, s.reranker, s.feedback, query, ftsResults, vecResults, opts)
+	if err != nil {
+		return nil, err
+	}
+	return results, attachResultTags(ctx, s.pool, results)
 }
 
 // SearchFTS performs tsvector-only search without requiring an embedder.
@@ -64,8 +66,13 @@ func (s *PostgresStore) SearchFTS(ctx context.Context, query string, opts memsto
 		return nil, err
 	}
 
-	// FTS-only path does not rerank: no embedder context, administrative fallback.
-	return memstore.ScoreResults(ctx, nil, query, ftsResults, nil, opts)
+	// FTS-only path does not rerank or apply feedback: no embedder context,
+	// administrative fallback, and recall's keyword pass applies feedback itself.
+	results, err := memstore.ScoreResults(ctx, nil, nil, query, ftsResults, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return results, attachResultTags(ctx, s.pool, results)
 }
 
 // SearchBatch performs hybrid search for multiple queries with shared embedding.
@@ -85,7 +92,7 @@ func (s *PostgresStore) SearchBatch(ctx context.Context, queries []string, opts
 		opts.VecWeight = 0.4
 	}
 
-	queryEmbs, err := s.queryCache.Embed(ctx, s.embedder, queries)
+	queryEmbs, err := s.queryCache.Embed(ctx, s.queryEmbedder, queries)
 	if err != nil {
 		return nil, err
 	}
@@ -106,10 +113,14 @@ func (s *PostgresStore) SearchBatch(ctx context.Context, queries []string, opts
 		}
 
 		// Batch search does not rerank: bulk/backfill path, latency-sensitive.
-		scored, err := memstore.ScoreResults(ctx, nil, query, ftsResults, vecResults, opts)
+		// Feedback is one query, so it applies.
+		scored, err := memstore.ScoreResults(ctx, nil, s.feedback, query, ftsResults, vecResults, opts)
 		if err != nil {
 			return nil, err
 		}
+		if err := attachResultTags(ctx, s.pool, scored); err != nil {
+			return nil, err
+		}
 		results[i] = scored
 	}
 
@@ -124,23 +135,21 @@ func (s *PostgresStore) searchFTS(ctx context.Context, query string, opts memsto
 	}
 
 	var b queryBuilder
-	b.write(`SELECT f.id, f.namespace, f.user_id, f.content, f.subject, f.category, f.kind, f.subsystem, f.metadata,
-	                f.superseded_by, f.superseded_at, f.confirmed_count, f.last_confirmed_at,
-	                f.use_count, f.last_used_at, f.embedding, f.created_at,
+	b.write(`SELECT `+qualifiedFactColumns("f.")+`,
 	                ts_rank(f.fts, plainto_tsquery('english', `, tsquery)
 	b.q += `)) AS rank
 	         FROM memstore_facts f
 	         WHERE f.fts @@ plainto_tsquery('english', `
 	b.write(``, tsquery)
-	b.q += `)`
+	b.q += `)` + notDeleted("f.")
 
 	s.appendNamespaceFilter(&b, "f.namespace", opts.AllNamespaces, opts.Namespaces)
 	s.appendUserFilter(&b, "f.user_id")
 	if opts.OnlyActive {
-		b.q += ` AND f.superseded_by IS NULL`
+		b.q += ` AND f.superseded_by IS NULL` + unexpired("f.")
 	}
 	if opts.Subject != "" {
-		b.write(` AND f.subject = `, opts.Subject)
+		s.appendSubjectFilter(&b, "f.subject", opts.Subject)
 	}
 	if opts.Category != "" {
 		b.write(` AND f.category = `, opts.Category)
@@ -155,6 +164,7 @@ func (s *PostgresStore) searchFTS(ctx context.Context, query string, opts memsto
 		return nil, err
 	}
 	appendTemporalFilters(&b, "f.", opts.CreatedAfter, opts.CreatedBefore)
+	appendTagFilter(&b, "f.", opts.Tags)
 
 	b.write(` ORDER BY rank DESC LIMIT `, memstore.FetchLimit(opts))
 
@@ -166,40 +176,15 @@ func (s *PostgresStore) searchFTS(ctx context.Context, query string, opts memsto
 
 	var results []memstore.SearchResult
 	for rows.Next() {
-		var f memstore.Fact
-		var metadata []byte
-		var supersededBy *int64
-		var supersededAt *time.Time
-		var lastConfirmedAt *time.Time
-		var lastUsedAt *time.Time
-		var emb *pgvector.Vector
 		var rank float64
-
-		err := rows.Scan(
-			&f.ID, &f.Namespace, &f.UserID, &f.Content, &f.Subject, &f.Category, &f.Kind, &f.Subsystem,
-			&metadata, &supersededBy, &supersededAt,
-			&f.ConfirmedCount, &lastConfirmedAt,
-			&f.UseCount, &lastUsedAt,
-			&emb, &f.CreatedAt, &rank,
-		)
+		f, err := scanFact(rows, &rank)
 		if err != nil {
 			return nil, fmt.Errorf("pgstore: scanning FTS result: %w", err)
 		}
 
-		if len(metadata) > 0 {
-			f.Metadata = json.RawMessage(metadata)
-		}
-		f.SupersededBy = supersededBy
-		f.SupersededAt = supersededAt
-		f.LastConfirmedAt = lastConfirmedAt
-		f.LastUsedAt = lastUsedAt
-		if emb != nil {
-			f.Embedding = emb.Slice()
-		}
-
 		// ts_rank returns positive scores (higher = better match).
 		results = append(results, memstore.SearchResult{
-			Fact:     f,
+			Fact:     *f,
 			FTSScore: rank,
 		})
 	}
@@ -215,15 +200,15 @@ func (s *PostgresStore) searchVector(ctx context.Context, queryEmb []float32, op
 	b.write(`SELECT `+factColumns+`, 1 - (embedding <=> `, qv)
 	b.q += `) AS similarity
 	         FROM memstore_facts
-	         WHERE embedding IS NOT NULL`
+	         WHERE embedding IS NOT NULL` + notDeleted("")
 
 	s.appendNamespaceFilter(&b, "namespace", opts.AllNamespaces, opts.Namespaces)
 	s.appendUserFilter(&b, "user_id")
 	if opts.OnlyActive {
-		b.q += ` AND superseded_by IS NULL`
+		b.q += ` AND superseded_by IS NULL` + unexpired("")
 	}
 	if opts.Subject != "" {
-		b.write(` AND subject = `, opts.Subject)
+		s.appendSubjectFilter(&b, "subject", opts.Subject)
 	}
 	if opts.Category != "" {
 		b.write(` AND category = `, opts.Category)
@@ -238,6 +223,7 @@ func (s *PostgresStore) searchVector(ctx context.Context, queryEmb []float32, op
 		return nil, err
 	}
 	appendTemporalFilters(&b, "", opts.CreatedAfter, opts.CreatedBefore)
+	appendTagFilter(&b, "", opts.Tags)
 
 	b.write(` ORDER BY embedding <=> `, qv)
 	b.write(` LIMIT `, memstore.FetchLimit(opts))
@@ -250,40 +236,15 @@ func (s *PostgresStore) searchVector(ctx context.Context, queryEmb []float32, op
 
 	var results []memstore.SearchResult
 	for rows.Next() {
-		var f memstore.Fact
-		var metadata []byte
-		var supersededBy *int64
-		var supersededAt *time.Time
-		var lastConfirmedAt *time.Time
-		var lastUsedAt *time.Time
-		var emb *pgvector.Vector
 		var similarity float64
-
-		err := rows.Scan(
-			&f.ID, &f.Namespace, &f.UserID, &f.Content, &f.Subject, &f.Category, &f.Kind, &f.Subsystem,
-			&metadata, &supersededBy, &supersededAt,
-			&f.ConfirmedCount, &lastConfirmedAt,
-			&f.UseCount, &lastUsedAt,
-			&emb, &f.CreatedAt, &similarity,
-		)
+		f, err := scanFact(rows, &similarity)
 		if err != nil {
 			return nil, fmt.Errorf("pgstore: scanning vector result: %w", err)
 		}
 
-		if len(metadata) > 0 {
-			f.Metadata = json.RawMessage(metadata)
-		}
-		f.SupersededBy = supersededBy
-		f.SupersededAt = supersededAt
-		f.LastConfirmedAt = lastConfirmedAt
-		f.LastUsedAt = lastUsedAt
-		if emb != nil {
-			f.Embedding = emb.Slice()
-		}
-
 		if similarity > 0 {
 			results = append(results, memstore.SearchResult{
-				Fact:     f,
+				Fact:     *f,
 				VecScore: similarity,
 			})
 		}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -190,6 +192,21 @@ func (s *SessionStore) migrate(ctx context.Context) error {
 		`ALTER TABLE context_injections ADD COLUMN IF NOT EXISTS rank INT NOT NULL DEFAULT -1`,
 		`CREATE INDEX IF NOT EXISTS idx_context_injections_session ON context_injections(session_id)`,
 
+		// Online ranking experiments: the arm each hint was generated under and
+		// each injection was served under. '' = no experiment.
+		`ALTER TABLE context_hints      ADD COLUMN IF NOT EXISTS experiment TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE context_hints      ADD COLUMN IF NOT EXISTS arm        TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE context_injections ADD COLUMN IF NOT EXISTS experiment TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE context_injections ADD COLUMN IF NOT EXISTS arm        TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_context_injections_experiment ON context_injections(experiment) WHERE experiment <> ''`,
+
+		// Exploration: where the ranker put an item recall displayed out of
+		// score order, and how it was moved. original_rank equals rank for
+		// items shown in score order; -1 where unknown.
+		`ALTER TABLE context_injections ADD COLUMN IF NOT EXISTS original_rank INT  NOT NULL DEFAULT -1`,
+		`ALTER TABLE context_injections ADD COLUMN IF NOT EXISTS exploration   TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_context_injections_exploration ON context_injections(exploration) WHERE exploration <> ''`,
+
 		`CREATE TABLE IF NOT EXISTS context_feedback (
 			id         BIGSERIAL PRIMARY KEY,
 			ref_id     TEXT NOT NULL,
@@ -469,13 +486,13 @@ func (s *SessionStore) StoreHint(ctx context.Context, hint memstore.ContextHint)
 		INSERT INTO context_hints(
 			session_id, cwd, turn_index, hint_text,
 			ref_ids, retrieved_ids, candidate_scores,
-			search_query, ranker_version,
+			search_query, ranker_version, experiment, arm,
 			relevance, desirability, user_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`, hint.SessionID, normalizeCWD(hint.CWD), hint.TurnIndex, hint.HintText,
 		refIDs, retrievedIDs, candidateScores,
-		hint.SearchQuery, hint.RankerVersion,
+		hint.SearchQuery, hint.RankerVersion, hint.Experiment, hint.Arm,
 		hint.Relevance, hint.Desirability, s.userID).Scan(&id)
 	return id, err
 }
@@ -494,7 +511,7 @@ func scanHints(rows interface {
 		if err := rows.Scan(
 			&h.ID, &h.SessionID, &h.CWD, &h.TurnIndex, &h.HintText,
 			&refIDsRaw, &retrievedIDsRaw, &candidateScoresRaw,
-			&h.SearchQuery, &h.RankerVersion,
+			&h.SearchQuery, &h.RankerVersion, &h.Experiment, &h.Arm,
 			&h.Relevance, &h.Desirability, &h.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -516,7 +533,7 @@ func (s *SessionStore) GetPendingHints(ctx context.Context, sessionID, cwd strin
 	rows, err := s.pool.Query(ctx, `
 		SELECT id, session_id, cwd, turn_index, hint_text,
 		       ref_ids, retrieved_ids, candidate_scores,
-		       search_query, ranker_version,
+		       search_query, ranker_version, experiment, arm,
 		       relevance, desirability, created_at
 		FROM context_hints
 		WHERE consumed_at IS NULL
@@ -546,15 +563,101 @@ func (s *SessionStore) MarkHintConsumed(ctx context.Context, hintID int64) error
 // RecordInjection records that a ref was injected into a session.
 // rank is the 0-based position of the item in the candidate list; -1 if unknown.
 // Stamped-write: stamps user_id = s.userID. Ignores conflicts (idempotent).
+//
+// A hint injection inherits the experiment arm its hint was generated under,
+// so hint feedback is credited to that arm even when the hint is consumed by
+// a later session than the one that produced it.
 func (s *SessionStore) RecordInjection(ctx context.Context, sessionID, refID, refType string, rank int) error {
+	var hintID int64 // 0 matches no hint
+	if refType == memstore.RefTypeHint {
+		hintID, _ = strconv.ParseInt(refID, 10, 64)
+	}
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO context_injections(session_id, ref_id, ref_type, rank, original_rank, user_id, experiment, arm)
+		VALUES ($1, $2, $3, $4, $4, $5,
+			COALESCE((SELECT experiment FROM context_hints WHERE id = $6), ''),
+			COALESCE((SELECT arm FROM context_hints WHERE id = $6), ''))
+		ON CONFLICT (user_id, session_id, ref_id, ref_type) DO NOTHING
+	`, sessionID, refID, refType, rank, s.userID, hintID)
+	return err
+}
+
+// SessionStore attributes injections to experiment arms.
+var _ memstore.ArmInjectionRecorder = (*SessionStore)(nil)
+
+// RecordArmInjection is RecordInjection for an injection served under an
+// online experiment arm.
+// Stamped-write: stamps user_id = s.userID. Ignores conflicts (idempotent).
+func (s *SessionStore) RecordArmInjection(ctx context.Context, sessionID, refID, refType string, rank int, arm memstore.ArmAssignment) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO context_injections(session_id, ref_id, ref_type, rank, original_rank, user_id, experiment, arm)
+		VALUES ($1, $2, $3, $4, $4, $5, $6, $7)
+		ON CONFLICT (user_id, session_id, ref_id, ref_type) DO NOTHING
+	`, sessionID, refID, refType, rank, s.userID, arm.Experiment, arm.Arm)
+	return err
+}
+
+// SessionStore logs exploratory injections.
+var _ memstore.ExplorationRecorder = (*SessionStore)(nil)
+
+// RecordExploration is RecordInjection for an item recall displayed out of
+// score order, recording where the ranker put it and how it was moved.
+// Stamped-write: stamps user_id = s.userID. Ignores conflicts (idempotent).
+func (s *SessionStore) RecordExploration(ctx context.Context, sessionID, refID, refType string, rank, originalRank int, kind string) error {
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO context_injections(session_id, ref_id, ref_type, rank, user_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO context_injections(session_id, ref_id, ref_type, rank, original_rank, exploration, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (user_id, session_id, ref_id, ref_type) DO NOTHING
-	`, sessionID, refID, refType, rank, s.userID)
+	`, sessionID, refID, refType, rank, originalRank, kind, s.userID)
 	return err
 }
 
+// ExperimentFeedback aggregates the feedback on an experiment's injections
+// per arm and ref type. An injection's rating is the context_feedback row for
+// the same user, session, and ref.
+// Scoped-read: filters AND ci.user_id = s.userID when userID != 0.
+// Service-conditional: at userID 0 (service scope) spans all users.
+func (s *SessionStore) ExperimentFeedback(ctx context.Context, experiment string) ([]memstore.ArmFeedback, error) {
+	args := []any{experiment}
+	userWhere := ""
+	if s.userID != 0 {
+		args = append(args, s.userID)
+		userWhere = fmt.Sprintf(" AND ci.user_id = $%d", len(args))
+	}
+	rows, err := s.pool.Query(ctx,
+		`SELECT ci.arm, ci.ref_type,
+		       COUNT(DISTINCT ci.session_id)::int,
+		       COUNT(*)::int,
+		       COUNT(cf.id)::int,
+		       COUNT(*) FILTER (WHERE cf.score > 0)::int,
+		       COUNT(*) FILTER (WHERE cf.score < 0)::int,
+		       COALESCE(AVG(cf.score), 0)::float8
+		FROM context_injections ci
+		LEFT JOIN context_feedback cf
+		  ON cf.user_id = ci.user_id AND cf.session_id = ci.session_id
+		 AND cf.ref_id = ci.ref_id AND cf.ref_type = ci.ref_type
+		WHERE ci.experiment = $1`+
+			userWhere+`
+		GROUP BY ci.arm, ci.ref_type
+		ORDER BY ci.arm, ci.ref_type`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []memstore.ArmFeedback
+	for rows.Next() {
+		var f memstore.ArmFeedback
+		if err := rows.Scan(&f.Arm, &f.RefType, &f.Sessions, &f.Injections,
+			&f.Rated, &f.Positive, &f.Negative, &f.AvgScore); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
 // WasInjected returns true if refID+refType was already injected this session.
 // Scoped-read: filters AND user_id = s.userID when userID != 0.
 func (s *SessionStore) WasInjected(ctx context.Context, sessionID, refID, refType string) (bool, error) {
@@ -623,7 +726,7 @@ func (s *SessionStore) GetInjectedHints(ctx context.Context, sessionID string) (
 	rows, err := s.pool.Query(ctx,
 		`SELECT ch.id, ch.session_id, ch.cwd, ch.turn_index, ch.hint_text,
 		       ch.ref_ids, ch.retrieved_ids, ch.candidate_scores,
-		       ch.search_query, ch.ranker_version,
+		       ch.search_query, ch.ranker_version, ch.experiment, ch.arm,
 		       ch.relevance, ch.desirability, ch.created_at
 		FROM context_hints ch
 		JOIN context_injections ci
@@ -672,6 +775,50 @@ func (s *SessionStore) FeedbackScores(ctx context.Context, refIDs []string, refT
 	return stats, rows.Err()
 }
 
+var _ memstore.DecayedFeedbackScorer = (*SessionStore)(nil)
+
+// DecayedFeedbackScores is FeedbackScores with each rating weighted by
+// 0.5^(age/halfLife). Avg is the weighted mean and Weight the summed weights.
+// Ages are capped at 60 half-lives so the weight never underflows to zero.
+// Scoped-read: filters AND user_id = s.userID when userID != 0.
+// Service-conditional: at userID 0 (service scope) spans all users.
+func (s *SessionStore) DecayedFeedbackScores(ctx context.Context, refIDs []string, refType string, halfLife time.Duration) (map[string]memstore.FeedbackStat, error) {
+	if len(refIDs) == 0 {
+		return nil, nil
+	}
+	if halfLife <= 0 {
+		return s.FeedbackScores(ctx, refIDs, refType)
+	}
+	args := []any{refIDs, refType, halfLife.Seconds()}
+	userWhere, args := s.userClause("AND", args)
+	rows, err := s.pool.Query(ctx,
+		`SELECT ref_id, SUM(w * score) / SUM(w), COUNT(*)::int, SUM(w)
+		FROM (
+			SELECT ref_id, score,
+			       power(0.5, LEAST(EXTRACT(EPOCH FROM NOW() - created_at) / $3, 60))::float8 AS w
+			FROM context_feedback
+			WHERE ref_id = ANY($1) AND ref_type = $2`+
+			userWhere+`
+		) rated
+		GROUP BY ref_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := make(map[string]memstore.FeedbackStat)
+	for rows.Next() {
+		var refID string
+		var stat memstore.FeedbackStat
+		if err := rows.Scan(&refID, &stat.Avg, &stat.Count, &stat.Weight); err != nil {
+			return nil, err
+		}
+		stats[refID] = stat
+	}
+	return stats, rows.Err()
+}
+
 // UnratedFactSessions returns session IDs that have fact injections with no
 // corresponding feedback. Used by the backfill-feedback command.
 // Scoped-read: filters AND ci.user_id = s.userID when userID != 0.
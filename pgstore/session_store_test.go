@@ -2,6 +2,7 @@ package pgstore_test
 
 import (
 	"context"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -47,6 +48,7 @@ func newTestSessionStore(t *testing.T) (*pgstore.SessionStore, *pgxpool.Pool) {
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 
@@ -332,6 +334,7 @@ func TestSessionConformance_SessionIsolation(t *testing.T) {
 			pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 			pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 			pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+			pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 			pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 			pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 
@@ -365,3 +368,75 @@ func TestSessionConformance_SessionIsolation(t *testing.T) {
 		},
 	})
 }
+
+func TestSessionStore_DecayedFeedbackScores(t *testing.T) {
+	ss, pool := newTestSessionStore(t)
+	ctx := context.Background()
+
+	// Fact 1 drew two complaints half a year ago and a thumbs-up today.
+	for _, fb := range []memstore.ContextFeedback{
+		{RefID: "1", RefType: memstore.RefTypeFact, SessionID: "old-a", Score: -1},
+		{RefID: "1", RefType: memstore.RefTypeFact, SessionID: "old-b", Score: -1},
+		{RefID: "1", RefType: memstore.RefTypeFact, SessionID: "new", Score: 1},
+	} {
+		if err := ss.RecordFeedback(ctx, fb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pool.Exec(ctx, `UPDATE context_feedback SET created_at = NOW() - INTERVAL '180 days' WHERE session_id LIKE 'old-%'`); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := ss.FeedbackScores(ctx, []string{"1"}, memstore.RefTypeFact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := plain["1"]; s.Count != 3 || s.Avg >= 0 {
+		t.Fatalf("plain stats = %+v, want 3 ratings averaging negative", s)
+	}
+
+	decayed, err := ss.DecayedFeedbackScores(ctx, []string{"1"}, memstore.RefTypeFact, 90*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := decayed["1"]
+	// Weights: 0.25 + 0.25 + 1 = 1.5; weighted avg = (−0.5 + 1) / 1.5 = 1/3.
+	if s.Count != 3 || math.Abs(s.Weight-1.5) > 0.01 || math.Abs(s.Avg-1.0/3) > 0.01 {
+		t.Errorf("decayed stats = %+v, want Count 3, Weight ~1.5, Avg ~0.33", s)
+	}
+}
+
+func TestSessionStore_RecordExploration(t *testing.T) {
+	ss, pool := newTestSessionStore(t)
+	ctx := context.Background()
+
+	if err := ss.RecordInjection(ctx, "s1", "1", memstore.RefTypeFact, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.RecordExploration(ctx, "s1", "9", memstore.RefTypeFact, 1, 6, memstore.ExploreEpsilon); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := pool.Query(ctx, `SELECT ref_id, rank, original_rank, exploration FROM context_injections WHERE session_id = 's1' ORDER BY ref_id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type row struct {
+		ref            string
+		rank, origRank int
+		exploration    string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.ref, &r.rank, &r.origRank, &r.exploration); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	want := []row{{"1", 0, 0, ""}, {"9", 1, 6, memstore.ExploreEpsilon}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("injections = %+v, want %+v", got, want)
+	}
+}
@@ -17,24 +17,32 @@ import (
 	pgvector "github.com/pgvector/pgvector-go"
 )
 
-const schemaVersion = 5
+const schemaVersion = 17
 
 // factColumns is the canonical SELECT list for fact queries.
-// searchFTS has its own column list because it joins and adds ts_rank.
-const factColumns = `id, namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, superseded_at, confirmed_count, last_confirmed_at, use_count, last_used_at, embedding, created_at`
+const factColumns = `id, namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, superseded_at, confirmed_count, last_confirmed_at, use_count, last_used_at, expires_at, archived_at, deleted_at, embedding, created_at, source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id`
+
+// qualifiedFactColumns returns factColumns with each column prefixed by alias
+// (e.g. "f."), for queries that join memstore_facts to another table.
+func qualifiedFactColumns(alias string) string {
+	return alias + strings.ReplaceAll(factColumns, ", ", ", "+alias)
+}
 
 // PostgresStore implements memstore.Store backed by PostgreSQL.
 // It uses pgvector for vector similarity search and tsvector with GIN
 // indexing for full-text search. No mutex is needed -- Postgres handles
 // concurrency natively via MVCC.
 type PostgresStore struct {
-	pool       *pgxpool.Pool
-	embedder   embedding.Embedder
-	namespace  string
-	userID     int64                 // resolved owner for this store; set after migrateV4
-	vecDim     int                   // embedding dimension, set at construction or first embed
-	queryCache *embedding.QueryCache // caches query embeddings on the search path; nil if disabled
-	reranker   embedding.Reranker    // nil means no second-stage rerank; set via SetReranker
+	pool          *pgxpool.Pool
+	embedder      embedding.Embedder
+	namespace     string
+	userID        int64                    // resolved owner for this store; set after migrateV4
+	vecDim        int                      // embedding dimension, set at construction or first embed
+	queryCache    *embedding.QueryCache    // caches query embeddings on the search path; nil if disabled
+	queryEmbedder embedding.Embedder       // embedder behind the persistent query cache; set via SetQueryCache
+	reranker      embedding.Reranker       // nil means no second-stage rerank; set via SetReranker
+	feedback      *memstore.FeedbackStage  // nil means no feedback stage; set via SetFeedback
+	schemas       *memstore.SchemaRegistry // metadata schemas checked on write; nil checks nothing; set via SetSchemas
 }
 
 // SetReranker configures a second-stage cross-encoder reranker for Search.
@@ -43,6 +51,27 @@ type PostgresStore struct {
 // once at startup before the store serves queries; nil disables reranking.
 func (s *PostgresStore) SetReranker(rr embedding.Reranker) { s.reranker = rr }
 
+// SetFeedback enables the feedback stage of Search and SearchBatch: results
+// are scaled by their facts' rating history from scorer under policy (see
+// memstore.FeedbackPolicy). SearchFTS is unaffected. Intended to be called
+// once at startup; a nil scorer disables the stage.
+//
+// Ratings are per user, so a *SessionStore scorer is re-scoped along with the
+// store by ForUser and ServiceScope.
+func (s *PostgresStore) SetFeedback(scorer memstore.FeedbackScorer, policy memstore.FeedbackPolicy) {
+	s.feedback = nil
+	if scorer != nil {
+		s.feedback = &memstore.FeedbackStage{Scorer: scorer, Policy: policy}
+	}
+}
+
+// SetSchemas replaces the metadata schemas that Insert, InsertBatch,
+// UpdateMetadata, Revise, Merge and BulkUpdate check a fact's metadata
+// against (see memstore.SchemaRegistry). The store starts with
+// memstore.DefaultSchemas. Intended to be called once at startup; nil
+// disables the check.
+func (s *PostgresStore) SetSchemas(r *memstore.SchemaRegistry) { s.schemas = r }
+
 // New creates a new PostgresStore using the given connection pool.
 // It creates memstore_* tables if needed and runs any pending migrations.
 //
@@ -51,7 +80,9 @@ func (s *PostgresStore) SetReranker(rr embedding.Reranker) { s.reranker = rr }
 // If vecDim is 0, embedding columns are created without a dimension constraint.
 //
 // cacheSize bounds the in-process LRU that caches query embeddings on the
-// search path; a value <= 0 disables it.
+// search path; a value <= 0 disables it. Misses in the LRU go to the
+// persistent memstore_query_cache table (see SetQueryCache) before the
+// embedder.
 func New(ctx context.Context, pool *pgxpool.Pool, embedder embedding.Embedder, namespace string, vecDim, cacheSize int) (*PostgresStore, error) {
 	s := &PostgresStore{
 		pool:       pool,
@@ -59,6 +90,7 @@ func New(ctx context.Context, pool *pgxpool.Pool, embedder embedding.Embedder, n
 		namespace:  namespace,
 		vecDim:     vecDim,
 		queryCache: embedding.NewQueryCache(cacheSize),
+		schemas:    memstore.DefaultSchemas(),
 	}
 	if err := s.migrate(ctx); err != nil {
 		return nil, fmt.Errorf("pgstore: migration: %w", err)
@@ -76,6 +108,10 @@ func New(ctx context.Context, pool *pgxpool.Pool, embedder embedding.Embedder, n
 			return nil, err
 		}
 	}
+	if err := s.purgeQueryCacheModels(ctx); err != nil {
+		return nil, err
+	}
+	s.SetQueryCache(memstore.DefaultQueryCachePolicy())
 	return s, nil
 }
 
@@ -126,9 +162,26 @@ func (s *PostgresStore) ForUser(userID int64) (memstore.Store, error) {
 	}
 	c := *s
 	c.userID = userID
+	if ss, ok := feedbackSessions(s.feedback); ok {
+		scoped, err := ss.ForUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		c.feedback = &memstore.FeedbackStage{Scorer: scoped.(*SessionStore), Policy: s.feedback.Policy}
+	}
 	return &c, nil
 }
 
+// feedbackSessions returns the session store behind a feedback stage, if
+// that is its scorer.
+func feedbackSessions(fb *memstore.FeedbackStage) (*SessionStore, bool) {
+	if fb == nil {
+		return nil, false
+	}
+	ss, ok := fb.Scorer.(*SessionStore)
+	return ss, ok
+}
+
 // ServiceScope returns a clone of the store with NO user predicate: it sees
 // and can touch every user's facts and links in the namespace.
 //
@@ -138,6 +191,9 @@ func (s *PostgresStore) ForUser(userID int64) (memstore.Store, error) {
 func (s *PostgresStore) ServiceScope() *PostgresStore {
 	c := *s
 	c.userID = 0
+	if ss, ok := feedbackSessions(s.feedback); ok {
+		c.feedback = &memstore.FeedbackStage{Scorer: ss.ServiceScope(), Policy: s.feedback.Policy}
+	}
 	return &c
 }
 
@@ -384,6 +440,76 @@ func (s *PostgresStore) migrate(ctx context.Context) error {
 		}
 	}
 
+	if version < 6 {
+		if err := s.migrateV6(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 7 {
+		if err := s.migrateV7(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 8 {
+		if err := s.migrateV8(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 9 {
+		if err := s.migrateV9(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 10 {
+		if err := s.migrateV10(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 11 {
+		if err := s.migrateV11(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 12 {
+		if err := s.migrateV12(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 13 {
+		if err := s.migrateV13(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 14 {
+		if err := s.migrateV14(ctx); err != nil {
+			return err
+		}
+	}
+
+	if version < 15 {
+		if err := s.migrateV15(ctx); err != nil {
+			return err
+		}
+	}
+	if version < 16 {
+		if err := s.migrateV16(ctx); err != nil {
+			return err
+		}
+	}
+	if version < 17 {
+		if err := s.migrateV17(ctx); err != nil {
+			return err
+		}
+	}
+
 	if version == 0 {
 		_, err = s.pool.Exec(ctx, `INSERT INTO memstore_version (version) VALUES ($1)`, schemaVersion)
 	} else {
@@ -772,6 +898,17 @@ func (s *PostgresStore) recordEmbedder(ctx context.Context, dim int) error {
 
 // Insert adds a single fact and returns its ID.
 func (s *PostgresStore) Insert(ctx context.Context, f memstore.Fact) (int64, error) {
+	prov, err := memstore.ResolveProvenance(ctx, f.Provenance)
+	if err != nil {
+		return 0, err
+	}
+	tags, err := memstore.NormalizeTags(f.Tags)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.schemas.Validate(f.Kind, f.Metadata); err != nil {
+		return 0, err
+	}
 	if f.CreatedAt.IsZero() {
 		f.CreatedAt = time.Now().UTC()
 	}
@@ -787,17 +924,31 @@ func (s *PostgresStore) Insert(ctx context.Context, f memstore.Fact) (int64, err
 		return 0, err
 	}
 
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var id int64
-	err = s.pool.QueryRow(ctx,
-		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, embedding, created_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, expires_at, embedding, created_at,
+		                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+		 VALUES ($1, $2, $3, `+canonicalSubjectExpr("$1", "$2", "$4")+`, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		 RETURNING id`,
 		s.namespace, userID, f.Content, f.Subject, f.Category, f.Kind, f.Subsystem,
-		nullableJSON(f.Metadata), f.SupersededBy, emb, f.CreatedAt,
+		nullableJSON(f.Metadata), f.SupersededBy, f.ExpiresAt, emb, f.CreatedAt,
+		prov.Source, prov.SessionID, prov.TurnFirst, prov.TurnLast, prov.Origin, nullableID(prov.DocumentID),
 	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("pgstore: inserting fact: %w", err)
 	}
+	if err := insertTags(ctx, tx, id, tags); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("pgstore: committing fact: %w", err)
+	}
 	return id, nil
 }
 
@@ -807,12 +958,23 @@ func (s *PostgresStore) InsertBatch(ctx context.Context, facts []memstore.Fact)
 	// rejected owner is a caller bug, not a data condition, and finding it on
 	// fact 400 of 500 would mean a pointless round trip and rollback.
 	owners := make([]int64, len(facts))
+	provs := make([]memstore.Provenance, len(facts))
+	tags := make([][]string, len(facts))
 	for i := range facts {
 		owner, err := s.ownerFor(facts[i])
 		if err != nil {
 			return fmt.Errorf("pgstore: fact %d of %d: %w", i+1, len(facts), err)
 		}
 		owners[i] = owner
+		if provs[i], err = memstore.ResolveProvenance(ctx, facts[i].Provenance); err != nil {
+			return fmt.Errorf("pgstore: fact %d of %d: %w", i+1, len(facts), err)
+		}
+		if tags[i], err = memstore.NormalizeTags(facts[i].Tags); err != nil {
+			return fmt.Errorf("pgstore: fact %d of %d: %w", i+1, len(facts), err)
+		}
+		if err := s.schemas.Validate(facts[i].Kind, facts[i].Metadata); err != nil {
+			return fmt.Errorf("pgstore: fact %d of %d: %w", i+1, len(facts), err)
+		}
 	}
 
 	tx, err := s.pool.Begin(ctx)
@@ -835,16 +997,22 @@ func (s *PostgresStore) InsertBatch(ctx context.Context, facts []memstore.Fact)
 
 		userID := owners[i]
 
+		prov := provs[i]
 		err := tx.QueryRow(ctx,
-			`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, embedding, created_at)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, expires_at, embedding, created_at,
+			                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+			 VALUES ($1, $2, $3, `+canonicalSubjectExpr("$1", "$2", "$4")+`, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 			 RETURNING id`,
 			s.namespace, userID, facts[i].Content, facts[i].Subject, facts[i].Category, facts[i].Kind, facts[i].Subsystem,
-			nullableJSON(facts[i].Metadata), facts[i].SupersededBy, emb, facts[i].CreatedAt,
+			nullableJSON(facts[i].Metadata), facts[i].SupersededBy, facts[i].ExpiresAt, emb, facts[i].CreatedAt,
+			prov.Source, prov.SessionID, prov.TurnFirst, prov.TurnLast, prov.Origin, nullableID(prov.DocumentID),
 		).Scan(&facts[i].ID)
 		if err != nil {
 			return fmt.Errorf("pgstore: inserting fact %q: %w", facts[i].Content, err)
 		}
+		if err := insertTags(ctx, tx, facts[i].ID, tags[i]); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit(ctx)
@@ -854,7 +1022,7 @@ func (s *PostgresStore) InsertBatch(ctx context.Context, facts []memstore.Fact)
 func (s *PostgresStore) Supersede(ctx context.Context, oldID, newID int64) error {
 	now := time.Now().UTC()
 	q := `UPDATE memstore_facts SET superseded_by = $1, superseded_at = $2
-		 WHERE id = $3 AND namespace = $4 AND superseded_by IS NULL`
+		 WHERE id = $3 AND namespace = $4 AND superseded_by IS NULL AND deleted_at IS NULL`
 	args := []any{newID, now, oldID, s.namespace}
 	if s.userID != 0 {
 		// Both ends of the supersession must belong to the store's user. A
@@ -862,7 +1030,7 @@ func (s *PostgresStore) Supersede(ctx context.Context, oldID, newID int64) error
 		// so existence of other users' facts does not leak.
 		args = append(args, s.userID)
 		q += ` AND user_id = $5 AND EXISTS (
-			SELECT 1 FROM memstore_facts WHERE id = $1 AND namespace = $4 AND user_id = $5)`
+			SELECT 1 FROM memstore_facts WHERE id = $1 AND namespace = $4 AND user_id = $5 AND deleted_at IS NULL)`
 	}
 	ct, err := s.pool.Exec(ctx, q, args...)
 	if err != nil {
@@ -874,12 +1042,118 @@ func (s *PostgresStore) Supersede(ctx context.Context, oldID, newID int64) error
 	return nil
 }
 
+// Revise replaces an active fact's content in a single transaction. The new
+// version inherits the old one's owner, subject, category, kind, subsystem,
+// expiry, metadata (with patch merged in) and tags, gets a copy of every
+// link touching the old fact, and supersedes it. The new content is embedded
+// before the transaction when the store has an embedder; if that fails the
+// version is stored unembedded and the embedding pipeline picks it up later.
+func (s *PostgresStore) Revise(ctx context.Context, id int64, content string, patch map[string]any) (int64, error) {
+	if strings.TrimSpace(content) == "" {
+		return 0, fmt.Errorf("pgstore: revising fact %d: content is required", id)
+	}
+	prov, err := memstore.ResolveProvenance(ctx, memstore.Provenance{})
+	if err != nil {
+		return 0, err
+	}
+	var emb *pgvector.Vector
+	if s.embedder != nil {
+		if vec, err := embedding.Single(ctx, s.embedder, content); err == nil {
+			v := pgvector.NewVector(vec)
+			emb = &v
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		userID                             int64
+		subject, category, kind, subsystem string
+		metadata                           []byte
+		expiresAt                          *time.Time
+	)
+	q, args := s.userPredicate(
+		`SELECT user_id, subject, category, kind, subsystem, metadata, expires_at FROM memstore_facts
+		 WHERE id = $1 AND namespace = $2 AND superseded_by IS NULL`+notDeleted(""),
+		[]any{id, s.namespace})
+	err = tx.QueryRow(ctx, q+` FOR UPDATE`, args...).Scan(&userID, &subject, &category, &kind, &subsystem, &metadata, &expiresAt)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("pgstore: fact %d not found or already superseded", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: reading fact %d: %w", id, err)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return 0, fmt.Errorf("%w: fact %d expired at %s", memstore.ErrFactExpired, id, expiresAt.Format(time.RFC3339))
+	}
+
+	merged, err := mergeMetadata(metadata, patch)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: merging metadata for fact %d: %w", id, err)
+	}
+	if len(patch) > 0 {
+		if err := s.schemas.ValidateFact(memstore.Fact{ID: id, Kind: kind, Metadata: merged}); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now().UTC()
+	var newID int64
+	err = tx.QueryRow(ctx,
+		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, expires_at, embedding, created_at,
+		                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		 RETURNING id`,
+		s.namespace, userID, content, subject, category, kind, subsystem, merged, expiresAt, emb, now,
+		prov.Source, prov.SessionID, prov.TurnFirst, prov.TurnLast, prov.Origin, nullableID(prov.DocumentID),
+	).Scan(&newID)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: inserting revision of fact %d: %w", id, err)
+	}
+
+	// Links never span users, so every link touching the old fact already
+	// belongs to its owner and the copies keep that owner.
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memstore_links (namespace, user_id, source_id, target_id, link_type, bidirectional, label, metadata, created_at)
+		 SELECT namespace, user_id,
+		        CASE WHEN source_id = $1 THEN $2 ELSE source_id END,
+		        CASE WHEN target_id = $1 THEN $2 ELSE target_id END,
+		        link_type, bidirectional, label, metadata, $3
+		 FROM memstore_links WHERE namespace = $4 AND (source_id = $1 OR target_id = $1)`,
+		id, newID, now, s.namespace,
+	); err != nil {
+		return 0, fmt.Errorf("pgstore: copying links of fact %d: %w", id, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memstore_fact_tags (fact_id, tag) SELECT $1::bigint, tag FROM memstore_fact_tags WHERE fact_id = $2`,
+		newID, id,
+	); err != nil {
+		return 0, fmt.Errorf("pgstore: copying tags of fact %d: %w", id, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE memstore_facts SET superseded_by = $1, superseded_at = $2 WHERE id = $3`,
+		newID, now, id,
+	); err != nil {
+		return 0, fmt.Errorf("pgstore: superseding fact %d: %w", id, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("pgstore: committing revision of fact %d: %w", id, err)
+	}
+	return newID, nil
+}
+
 // Confirm increments a fact's confirmed_count and updates last_confirmed_at.
 func (s *PostgresStore) Confirm(ctx context.Context, id int64) error {
 	now := time.Now().UTC()
 	q, args := s.userPredicate(
 		`UPDATE memstore_facts SET confirmed_count = confirmed_count + 1, last_confirmed_at = $1
-		 WHERE id = $2 AND namespace = $3`,
+		 WHERE id = $2 AND namespace = $3`+notDeleted(""),
 		[]any{now, id, s.namespace})
 	ct, err := s.pool.Exec(ctx, q, args...)
 	if err != nil {
@@ -901,7 +1175,7 @@ func (s *PostgresStore) Touch(ctx context.Context, ids []int64) error {
 	// pgx supports ANY($1::bigint[]) for IN-list queries.
 	q, args := s.userPredicate(
 		`UPDATE memstore_facts SET use_count = use_count + 1, last_used_at = $1
-		 WHERE namespace = $2 AND id = ANY($3::bigint[])`,
+		 WHERE namespace = $2 AND id = ANY($3::bigint[])`+notDeleted(""),
 		[]any{now, s.namespace, ids})
 	_, err := s.pool.Exec(ctx, q, args...)
 	if err != nil {
@@ -913,11 +1187,14 @@ func (s *PostgresStore) Touch(ctx context.Context, ids []int64) error {
 // UpdateMetadata merges a patch into the metadata JSON for a fact.
 func (s *PostgresStore) UpdateMetadata(ctx context.Context, id int64, patch map[string]any) error {
 	// Read current metadata.
-	var raw []byte
+	var (
+		kind string
+		raw  []byte
+	)
 	readQ, readArgs := s.userPredicate(
-		`SELECT metadata FROM memstore_facts WHERE id = $1 AND namespace = $2`,
+		`SELECT kind, metadata FROM memstore_facts WHERE id = $1 AND namespace = $2`+notDeleted(""),
 		[]any{id, s.namespace})
-	err := s.pool.QueryRow(ctx, readQ, readArgs...).Scan(&raw)
+	err := s.pool.QueryRow(ctx, readQ, readArgs...).Scan(&kind, &raw)
 	if err == pgx.ErrNoRows {
 		return fmt.Errorf("pgstore: fact %d not found", id)
 	}
@@ -925,24 +1202,15 @@ func (s *PostgresStore) UpdateMetadata(ctx context.Context, id int64, patch map[
 		return fmt.Errorf("pgstore: reading metadata for fact %d: %w", id, err)
 	}
 
-	existing := make(map[string]any)
-	if len(raw) > 0 {
-		if err := json.Unmarshal(raw, &existing); err != nil {
-			return fmt.Errorf("pgstore: unmarshaling metadata for fact %d: %w", id, err)
-		}
+	merged, err := mergeMetadata(raw, patch)
+	if err != nil {
+		return fmt.Errorf("pgstore: merging metadata for fact %d: %w", id, err)
 	}
-
-	for k, v := range patch {
-		if v == nil {
-			delete(existing, k)
-		} else {
-			existing[k] = v
-		}
+	if merged == nil {
+		merged = []byte("{}")
 	}
-
-	merged, err := json.Marshal(existing)
-	if err != nil {
-		return fmt.Errorf("pgstore: marshaling metadata for fact %d: %w", id, err)
+	if err := s.schemas.ValidateFact(memstore.Fact{ID: id, Kind: kind, Metadata: merged}); err != nil {
+		return err
 	}
 
 	updQ, updArgs := s.userPredicate(
@@ -955,10 +1223,34 @@ func (s *PostgresStore) UpdateMetadata(ctx context.Context, id int64, patch map[
 	return nil
 }
 
-// Delete removes a fact by ID.
+// mergeMetadata applies a metadata patch to a fact's stored metadata JSON:
+// keys with non-nil values are set, keys with nil values are deleted. It
+// returns nil when there was no metadata and the patch is empty, so a NULL
+// column stays NULL.
+func mergeMetadata(raw []byte, patch map[string]any) ([]byte, error) {
+	if len(raw) == 0 && len(patch) == 0 {
+		return nil, nil
+	}
+	existing := make(map[string]any)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(existing, k)
+		} else {
+			existing[k] = v
+		}
+	}
+	return json.Marshal(existing)
+}
+
+// Delete moves a fact to the trash by stamping deleted_at.
 func (s *PostgresStore) Delete(ctx context.Context, id int64) error {
 	q, args := s.userPredicate(
-		`DELETE FROM memstore_facts WHERE id = $1 AND namespace = $2`,
+		`UPDATE memstore_facts SET deleted_at = now() WHERE id = $1 AND namespace = $2`+notDeleted(""),
 		[]any{id, s.namespace})
 	ct, err := s.pool.Exec(ctx, q, args...)
 	if err != nil {
@@ -973,7 +1265,7 @@ func (s *PostgresStore) Delete(ctx context.Context, id int64) error {
 // Get retrieves a single fact by ID. Returns nil if not found.
 func (s *PostgresStore) Get(ctx context.Context, id int64) (*memstore.Fact, error) {
 	q, args := s.userPredicate(
-		`SELECT `+factColumns+` FROM memstore_facts WHERE id = $1 AND namespace = $2`,
+		`SELECT `+factColumns+` FROM memstore_facts WHERE id = $1 AND namespace = $2`+notDeleted(""),
 		[]any{id, s.namespace})
 	row := s.pool.QueryRow(ctx, q, args...)
 	f, err := scanFact(row)
@@ -983,18 +1275,50 @@ func (s *PostgresStore) Get(ctx context.Context, id int64) (*memstore.Fact, erro
 	if err != nil {
 		return nil, fmt.Errorf("pgstore: getting fact %d: %w", id, err)
 	}
+	tags, err := loadTags(ctx, s.pool, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	f.Tags = tags[id]
 	return f, nil
 }
 
 // List returns facts matching the given filters, ordered by ID.
 func (s *PostgresStore) List(ctx context.Context, opts memstore.QueryOpts) ([]memstore.Fact, error) {
 	var b queryBuilder
-	b.write(`SELECT ` + factColumns + ` FROM memstore_facts WHERE 1=1`)
-	s.appendNamespaceFilter(&b, "namespace", false, opts.Namespaces)
-	s.appendUserFilter(&b, "user_id")
+	b.write(`SELECT ` + factColumns + ` FROM memstore_facts`)
+	if err := s.appendListFilter(&b, opts); err != nil {
+		return nil, err
+	}
+
+	b.q += ` ORDER BY id`
+
+	if opts.Limit > 0 {
+		b.write(` LIMIT `, opts.Limit)
+	}
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: listing facts: %w", err)
+	}
+	defer rows.Close()
+
+	facts, err := scanFacts(rows)
+	if err != nil {
+		return nil, err
+	}
+	return facts, attachTags(ctx, s.pool, facts)
+}
+
+// appendListFilter appends the WHERE clause List applies for opts; Limit is
+// left to the caller.
+func (s *PostgresStore) appendListFilter(b *queryBuilder, opts memstore.QueryOpts) error {
+	b.q += ` WHERE 1=1` + notDeleted("")
+	s.appendNamespaceFilter(b, "namespace", false, opts.Namespaces)
+	s.appendUserFilter(b, "user_id")
 
 	if opts.Subject != "" {
-		b.write(` AND subject = `, opts.Subject)
+		s.appendSubjectFilter(b, "subject", opts.Subject)
 	}
 	if opts.Category != "" {
 		b.write(` AND category = `, opts.Category)
@@ -1006,40 +1330,31 @@ func (s *PostgresStore) List(ctx context.Context, opts memstore.QueryOpts) ([]me
 		b.write(` AND subsystem = `, opts.Subsystem)
 	}
 	if opts.OnlyActive {
-		b.q += ` AND superseded_by IS NULL`
+		b.q += ` AND superseded_by IS NULL` + unexpired("")
 	}
 	if len(opts.IDs) > 0 {
 		b.write(` AND id = ANY(`, opts.IDs)
 		b.q += `::bigint[])`
 	}
-	if err := appendMetadataFilters(&b, "", opts.MetadataFilters); err != nil {
-		return nil, err
-	}
-	appendTemporalFilters(&b, "", opts.CreatedAfter, opts.CreatedBefore)
-
-	b.q += ` ORDER BY id`
-
-	if opts.Limit > 0 {
-		b.write(` LIMIT `, opts.Limit)
+	if err := appendMetadataFilters(b, "", opts.MetadataFilters); err != nil {
+		return err
 	}
-
-	rows, err := s.pool.Query(ctx, b.q, b.args...)
-	if err != nil {
-		return nil, fmt.Errorf("pgstore: listing facts: %w", err)
-	}
-	defer rows.Close()
-
-	return scanFacts(rows)
+	appendTemporalFilters(b, "", opts.CreatedAfter, opts.CreatedBefore)
+	appendProvenanceFilter(b, "", opts.Provenance)
+	appendTagFilter(b, "", opts.Tags)
+	return nil
 }
 
-// BySubject returns facts for a given subject.
+// BySubject returns facts for a given subject. If onlyActive is true,
+// superseded and expired facts are excluded.
 func (s *PostgresStore) BySubject(ctx context.Context, subject string, onlyActive bool) ([]memstore.Fact, error) {
 	var b queryBuilder
-	b.write(`SELECT `+factColumns+` FROM memstore_facts WHERE subject = `, subject)
-	b.write(` AND namespace = `, s.namespace)
+	b.write(`SELECT `+factColumns+` FROM memstore_facts WHERE namespace = `, s.namespace)
+	b.q += notDeleted("")
 	s.appendUserFilter(&b, "user_id")
+	s.appendSubjectFilter(&b, "subject", subject)
 	if onlyActive {
-		b.q += ` AND superseded_by IS NULL`
+		b.q += ` AND superseded_by IS NULL` + unexpired("")
 	}
 	b.q += ` ORDER BY id`
 
@@ -1049,27 +1364,36 @@ func (s *PostgresStore) BySubject(ctx context.Context, subject string, onlyActiv
 	}
 	defer rows.Close()
 
-	return scanFacts(rows)
+	facts, err := scanFacts(rows)
+	if err != nil {
+		return nil, err
+	}
+	return facts, attachTags(ctx, s.pool, facts)
 }
 
-// Exists checks whether a fact with the same content and subject exists.
+// Exists checks whether a fact with the same content exists under subject's
+// alias group, so a fact filed under the canonical subject is found by its
+// alias.
 func (s *PostgresStore) Exists(ctx context.Context, content, subject string) (bool, error) {
+	var b queryBuilder
+	b.write(`SELECT COUNT(*) FROM memstore_facts WHERE content = `, content)
+	b.write(` AND namespace = `, s.namespace)
+	b.q += notDeleted("")
+	s.appendUserFilter(&b, "user_id")
+	s.appendSubjectFilter(&b, "subject", subject)
 	var count int
-	q, args := s.userPredicate(
-		`SELECT COUNT(*) FROM memstore_facts WHERE content = $1 AND subject = $2 AND namespace = $3`,
-		[]any{content, subject, s.namespace})
-	err := s.pool.QueryRow(ctx, q, args...).Scan(&count)
+	err := s.pool.QueryRow(ctx, b.q, b.args...).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("pgstore: checking existence: %w", err)
 	}
 	return count > 0, nil
 }
 
-// ActiveCount returns the number of non-superseded facts.
+// ActiveCount returns the number of non-superseded, unexpired facts.
 func (s *PostgresStore) ActiveCount(ctx context.Context) (int64, error) {
 	var count int64
 	q, args := s.userPredicate(
-		`SELECT COUNT(*) FROM memstore_facts WHERE superseded_by IS NULL AND namespace = $1`,
+		`SELECT COUNT(*) FROM memstore_facts WHERE superseded_by IS NULL AND namespace = $1`+notDeleted("")+unexpired(""),
 		[]any{s.namespace})
 	err := s.pool.QueryRow(ctx, q, args...).Scan(&count)
 	if err != nil {
@@ -1087,7 +1411,7 @@ func (s *PostgresStore) NeedingEmbedding(ctx context.Context, limit int) ([]mems
 	q, args := s.userPredicate(
 		`SELECT `+factColumns+`
 		 FROM memstore_facts
-		 WHERE embedding IS NULL AND embed_failed_at IS NULL AND namespace = $1`,
+		 WHERE embedding IS NULL AND embed_failed_at IS NULL AND namespace = $1`+notDeleted(""),
 		[]any{s.namespace})
 	args = append(args, limit)
 	q += fmt.Sprintf(` ORDER BY id LIMIT $%d`, len(args))
@@ -1141,7 +1465,7 @@ func (s *PostgresStore) EmbedFacts(ctx context.Context, batchSize int) (int, err
 	}
 
 	q, args := s.userPredicate(
-		`SELECT id, content FROM memstore_facts WHERE embedding IS NULL AND namespace = $1`,
+		`SELECT id, content FROM memstore_facts WHERE embedding IS NULL AND namespace = $1`+notDeleted(""),
 		[]any{s.namespace})
 	q += ` ORDER BY id`
 	rows, err := s.pool.Query(ctx, q, args...)
@@ -1238,7 +1562,7 @@ func (s *PostgresStore) History(ctx context.Context, id int64, subject string) (
 
 func (s *PostgresStore) historyByID(ctx context.Context, id int64) ([]memstore.HistoryEntry, error) {
 	anchorQ, anchorArgs := s.userPredicate(
-		`SELECT `+factColumns+` FROM memstore_facts WHERE id = $1 AND namespace = $2`,
+		`SELECT `+factColumns+` FROM memstore_facts WHERE id = $1 AND namespace = $2`+notDeleted(""),
 		[]any{id, s.namespace})
 	row := s.pool.QueryRow(ctx, anchorQ, anchorArgs...)
 	anchor, err := scanFact(row)
@@ -1254,7 +1578,7 @@ func (s *PostgresStore) historyByID(ctx context.Context, id int64) ([]memstore.H
 		// The user predicate makes a forged superseded_by pointing into
 		// another user's chain terminate like a dangling pointer.
 		backQ, backArgs := s.userPredicate(
-			`SELECT `+factColumns+` FROM memstore_facts WHERE superseded_by = $1 AND namespace = $2`,
+			`SELECT `+factColumns+` FROM memstore_facts WHERE superseded_by = $1 AND namespace = $2`+notDeleted(""),
 			[]any{current, s.namespace})
 		row := s.pool.QueryRow(ctx, backQ, backArgs...)
 		pred, err := scanFact(row)
@@ -1281,7 +1605,7 @@ func (s *PostgresStore) historyByID(ctx context.Context, id int64) ([]memstore.H
 		// Walk until the chain ends or repeats.
 		for !visited[next] {
 			fwdQ, fwdArgs := s.userPredicate(
-				`SELECT `+factColumns+` FROM memstore_facts WHERE id = $1 AND namespace = $2`,
+				`SELECT `+factColumns+` FROM memstore_facts WHERE id = $1 AND namespace = $2`+notDeleted(""),
 				[]any{next, s.namespace})
 			row := s.pool.QueryRow(ctx, fwdQ, fwdArgs...)
 			succ, err := scanFact(row)
@@ -1306,7 +1630,7 @@ func (s *PostgresStore) historyByID(ctx context.Context, id int64) ([]memstore.H
 
 func (s *PostgresStore) historyBySubject(ctx context.Context, subject string) ([]memstore.HistoryEntry, error) {
 	q, args := s.userPredicate(
-		`SELECT `+factColumns+` FROM memstore_facts WHERE subject = $1 AND namespace = $2`,
+		`SELECT `+factColumns+` FROM memstore_facts WHERE subject = $1 AND namespace = $2`+notDeleted(""),
 		[]any{subject, s.namespace})
 	q += ` ORDER BY created_at, id`
 	rows, err := s.pool.Query(ctx, q, args...)
@@ -1332,7 +1656,7 @@ func (s *PostgresStore) ListSubsystems(ctx context.Context, subject string) ([]s
 	var b queryBuilder
 	b.write(`SELECT DISTINCT subsystem FROM memstore_facts WHERE namespace = `, s.namespace)
 	s.appendUserFilter(&b, "user_id")
-	b.q += ` AND superseded_by IS NULL AND subsystem != ''`
+	b.q += ` AND superseded_by IS NULL AND subsystem != ''` + notDeleted("") + unexpired("")
 	if subject != "" {
 		b.write(` AND subject = `, subject)
 	}
@@ -1365,7 +1689,7 @@ func (s *PostgresStore) TermDocCounts(ctx context.Context, terms []string) (map[
 	// Get total active document count.
 	var totalDocs int
 	countQ, countArgs := s.userPredicate(
-		`SELECT COUNT(*) FROM memstore_facts WHERE namespace = $1 AND superseded_by IS NULL`,
+		`SELECT COUNT(*) FROM memstore_facts WHERE namespace = $1 AND superseded_by IS NULL`+notDeleted(""),
 		[]any{s.namespace})
 	err := s.pool.QueryRow(ctx, countQ, countArgs...).Scan(&totalDocs)
 	if err != nil {
@@ -1376,7 +1700,7 @@ func (s *PostgresStore) TermDocCounts(ctx context.Context, terms []string) (map[
 	// ts_stat takes the inner query as a string literal, so the user
 	// predicate is inlined; userID is an int64, not attacker-controlled text.
 	statsQuery := fmt.Sprintf(
-		`SELECT fts FROM memstore_facts WHERE namespace = %s AND superseded_by IS NULL`,
+		`SELECT fts FROM memstore_facts WHERE namespace = %s AND superseded_by IS NULL AND deleted_at IS NULL`,
 		quoteLiteral(s.namespace))
 	if s.userID != 0 {
 		statsQuery += fmt.Sprintf(` AND user_id = %d`, s.userID)
@@ -1416,6 +1740,10 @@ func (s *PostgresStore) Close() error {
 
 const linkColumns = `id, namespace, source_id, target_id, link_type, bidirectional, label, metadata, created_at`
 
+// linkEndpointsLive hides links with a trashed endpoint. The rows stay, so
+// restoring the fact brings its links back.
+const linkEndpointsLive = ` AND NOT EXISTS (SELECT 1 FROM memstore_facts lf WHERE lf.id IN (source_id, target_id) AND lf.deleted_at IS NOT NULL)`
+
 // LinkFacts creates a directed edge between two facts.
 func (s *PostgresStore) LinkFacts(ctx context.Context, sourceID, targetID int64, linkType string, bidirectional bool, label string, metadata map[string]any) (int64, error) {
 	var metaJSON []byte
@@ -1445,7 +1773,7 @@ func (s *PostgresStore) LinkFacts(ctx context.Context, sourceID, targetID int64,
 			 SELECT $1, o.user_id, $2, $3, $4, $5, $6, $7, $8
 			 FROM (SELECT user_id, COUNT(DISTINCT id) AS n
 			       FROM memstore_facts
-			       WHERE id IN ($2, $3) AND namespace = $1
+			       WHERE id IN ($2, $3) AND namespace = $1 AND deleted_at IS NULL
 			       GROUP BY user_id) o
 			 WHERE o.n = (CASE WHEN $2 = $3 THEN 1 ELSE 2 END)
 			 RETURNING id`,
@@ -1464,7 +1792,7 @@ func (s *PostgresStore) LinkFacts(ctx context.Context, sourceID, targetID int64,
 			`INSERT INTO memstore_links (namespace, user_id, source_id, target_id, link_type, bidirectional, label, metadata, created_at)
 			 SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
 			 WHERE (SELECT COUNT(DISTINCT id) FROM memstore_facts
-			        WHERE id IN ($3, $4) AND namespace = $1 AND user_id = $2)
+			        WHERE id IN ($3, $4) AND namespace = $1 AND user_id = $2 AND deleted_at IS NULL)
 			       = (CASE WHEN $3 = $4 THEN 1 ELSE 2 END)
 			 RETURNING id`,
 			s.namespace, s.userID, sourceID, targetID, linkType, bidirectional, label, nullableBytes(metaJSON), time.Now().UTC(),
@@ -1480,11 +1808,11 @@ func (s *PostgresStore) LinkFacts(ctx context.Context, sourceID, targetID int64,
 }
 
 // GetLink retrieves a single link by ID. Returns (nil, nil) when no link with
-// that ID is visible in the caller's scope (absent, or owned by another user),
-// matching Get's not-found contract.
+// that ID is visible in the caller's scope (absent, owned by another user, or
+// touching a trashed fact), matching Get's not-found contract.
 func (s *PostgresStore) GetLink(ctx context.Context, linkID int64) (*memstore.Link, error) {
 	q, args := s.userPredicate(
-		`SELECT `+linkColumns+` FROM memstore_links WHERE id = $1 AND namespace = $2`,
+		`SELECT `+linkColumns+` FROM memstore_links WHERE id = $1 AND namespace = $2`+linkEndpointsLive,
 		[]any{linkID, s.namespace})
 	row := s.pool.QueryRow(ctx, q, args...)
 	l, err := scanLink(row)
@@ -1519,6 +1847,7 @@ func (s *PostgresStore) GetLinks(ctx context.Context, factID int64, direction me
 		b.q += `)`
 	}
 
+	b.q += linkEndpointsLive
 	s.appendUserFilter(&b, "user_id")
 
 	if len(linkTypes) > 0 {
@@ -1611,7 +1940,9 @@ type scanner interface {
 	Scan(dest ...any) error
 }
 
-func scanFact(row scanner) (*memstore.Fact, error) {
+// scanFact scans a row selected with factColumns. Any extra destinations
+// receive the columns selected after them, such as a search score.
+func scanFact(row scanner, extra ...any) (*memstore.Fact, error) {
 	var f memstore.Fact
 	var metadata []byte
 	var supersededBy *int64
@@ -1619,14 +1950,19 @@ func scanFact(row scanner) (*memstore.Fact, error) {
 	var lastConfirmedAt *time.Time
 	var lastUsedAt *time.Time
 	var emb *pgvector.Vector
+	var documentID *int64
 
-	err := row.Scan(
+	dest := []any{
 		&f.ID, &f.Namespace, &f.UserID, &f.Content, &f.Subject, &f.Category, &f.Kind, &f.Subsystem,
 		&metadata, &supersededBy, &supersededAt,
 		&f.ConfirmedCount, &lastConfirmedAt,
 		&f.UseCount, &lastUsedAt,
+		&f.ExpiresAt, &f.ArchivedAt, &f.DeletedAt,
 		&emb, &f.CreatedAt,
-	)
+		&f.Provenance.Source, &f.Provenance.SessionID, &f.Provenance.TurnFirst, &f.Provenance.TurnLast,
+		&f.Provenance.Origin, &documentID,
+	}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -1641,6 +1977,9 @@ func scanFact(row scanner) (*memstore.Fact, error) {
 	if emb != nil {
 		f.Embedding = emb.Slice()
 	}
+	if documentID != nil {
+		f.Provenance.DocumentID = *documentID
+	}
 
 	return &f, nil
 }
@@ -1874,6 +2213,18 @@ func appendMetadataFilters(b *queryBuilder, alias string, filters []memstore.Met
 	return nil
 }
 
+// unexpired returns the predicate excluding facts whose ExpiresAt has passed.
+// It is part of every OnlyActive filter alongside superseded_by IS NULL.
+func unexpired(alias string) string {
+	return fmt.Sprintf(` AND (%[1]sexpires_at IS NULL OR %[1]sexpires_at > NOW())`, alias)
+}
+
+// notDeleted returns the predicate excluding facts in the trash. Every fact
+// read applies it except Trash itself.
+func notDeleted(alias string) string {
+	return ` AND ` + alias + `deleted_at IS NULL`
+}
+
 func appendTemporalFilters(b *queryBuilder, alias string, after, before *time.Time) {
 	if after != nil {
 		b.write(fmt.Sprintf(` AND %screated_at >= `, alias), after.UTC())
@@ -74,10 +74,16 @@ func newTestStoreNS(t *testing.T, ns string) *pgstore.PostgresStore {
 	// into an ambiguous-user failure).
 	pool.Exec(ctx, `DROP TABLE IF EXISTS api_tokens`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_links CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_fact_citations CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_audit CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_subject_aliases CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_pins CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_fact_tags CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_facts CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 
@@ -135,6 +141,7 @@ func newTestStoreWithEmbedder(t *testing.T, embedder embedding.Embedder, dim, ca
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 
@@ -187,6 +194,36 @@ func TestSearchCachesQueryEmbedding(t *testing.T) {
 	}
 }
 
+func TestSearchPersistsQueryEmbedding(t *testing.T) {
+	emb := &countingEmbedder{mockEmbedder: mockEmbedder{dim: 4}}
+	// No LRU: every hit below comes from memstore_query_cache.
+	store := newTestStoreWithEmbedder(t, emb, 4, 0)
+	ctx := context.Background()
+
+	opts := memstore.SearchOpts{MaxResults: 5}
+	if _, err := store.SearchBatch(ctx, []string{"quick fox", "lazy dog"}, opts); err != nil {
+		t.Fatalf("SearchBatch: %v", err)
+	}
+	if emb.embedded != 2 {
+		t.Fatalf("first batch embedded %d texts, want 2", emb.embedded)
+	}
+	if _, err := store.Search(ctx, "Quick Fox", opts); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if emb.embedded != 2 {
+		t.Errorf("persisted query re-embedded: %d texts embedded, want 2", emb.embedded)
+	}
+
+	// Disabled, the table is bypassed.
+	store.SetQueryCache(memstore.QueryCachePolicy{MaxEntries: -1})
+	if _, err := store.Search(ctx, "quick fox", opts); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if emb.embedded != 3 {
+		t.Errorf("disabled cache: %d texts embedded, want 3", emb.embedded)
+	}
+}
+
 func TestInsertAndGet(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -591,6 +628,7 @@ func TestHistory_CycleTerminates(t *testing.T) {
 	rawPool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 	rawPool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 	rawPool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+	rawPool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 	rawPool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 	rawPool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 
@@ -887,6 +925,7 @@ func TestConformance(t *testing.T) {
 		pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 		pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 		pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+		pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 		pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 		pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 		return pool
@@ -1210,6 +1249,7 @@ func dropAll(ctx context.Context, pool *pgxpool.Pool) {
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_meta CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_version CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_document_chunks CASCADE`)
+	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_query_cache`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_documents CASCADE`)
 	pool.Exec(ctx, `DROP TABLE IF EXISTS memstore_users CASCADE`)
 }
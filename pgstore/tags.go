@@ -0,0 +1,311 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/matthewjhunter/memstore"
+)
+
+// migrateV16 creates the fact-tag table and backfills it from the ad-hoc
+// "tags" arrays facts kept in their metadata. The metadata is left as it
+// was; elements that are not valid tags are skipped.
+func (s *PostgresStore) migrateV16(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_fact_tags (
+			fact_id BIGINT NOT NULL REFERENCES memstore_facts(id) ON DELETE CASCADE,
+			tag     TEXT NOT NULL,
+			PRIMARY KEY (fact_id, tag)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_fact_tags_tag ON memstore_fact_tags(tag)`,
+		fmt.Sprintf(`INSERT INTO memstore_fact_tags (fact_id, tag)
+		 SELECT DISTINCT f.id, t.tag FROM memstore_facts f
+		 CROSS JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(f.metadata->'tags') = 'array' THEN f.metadata->'tags' ELSE '[]'::jsonb END) e
+		 CROSS JOIN LATERAL (SELECT lower(btrim(e #>> '{}', E' \t\r\n'))) AS t(tag)
+		 WHERE jsonb_typeof(e) = 'string' AND t.tag <> '' AND length(t.tag) <= %d AND t.tag !~ '[,[:space:]]'
+		 ON CONFLICT DO NOTHING`, memstore.MaxTagLength),
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V16 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// execer is the part of *pgxpool.Pool and pgx.Tx the tag helpers need.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// insertTags attaches already-normalized tags to fact id.
+func insertTags(ctx context.Context, db execer, id int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if _, err := db.Exec(ctx,
+		`INSERT INTO memstore_fact_tags (fact_id, tag) SELECT $1::bigint, unnest($2::text[]) ON CONFLICT DO NOTHING`,
+		id, tags); err != nil {
+		return fmt.Errorf("pgstore: tagging fact %d: %w", id, err)
+	}
+	return nil
+}
+
+// loadTags returns the tags of each of ids that has any, sorted.
+func loadTags(ctx context.Context, db execer, ids []int64) (map[int64][]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := db.Query(ctx,
+		`SELECT fact_id, tag FROM memstore_fact_tags WHERE fact_id = ANY($1::bigint[]) ORDER BY fact_id, tag`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: loading tags: %w", err)
+	}
+	defer rows.Close()
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			id  int64
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning tag: %w", err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, rows.Err()
+}
+
+// attachTags sets Tags on each fact.
+func attachTags(ctx context.Context, db execer, facts []memstore.Fact) error {
+	ids := make([]int64, len(facts))
+	for i, f := range facts {
+		ids[i] = f.ID
+	}
+	tags, err := loadTags(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range facts {
+		facts[i].Tags = tags[facts[i].ID]
+	}
+	return nil
+}
+
+// attachResultTags sets Tags on the fact of each search result.
+func attachResultTags(ctx context.Context, db execer, results []memstore.SearchResult) error {
+	ids := make([]int64, len(results))
+	for i, r := range results {
+		ids[i] = r.Fact.ID
+	}
+	tags, err := loadTags(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Fact.Tags = tags[results[i].Fact.ID]
+	}
+	return nil
+}
+
+// filterTags normalizes the tags of a filter for comparison.
+func filterTags(tags []string) []string {
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = memstore.NormalizeTag(t)
+	}
+	return out
+}
+
+// appendTagFilter appends WHERE clauses for tf. alias is a table alias
+// prefix such as "f." or "".
+func appendTagFilter(b *queryBuilder, alias string, tf memstore.TagFilter) {
+	has := `SELECT 1 FROM memstore_fact_tags ft WHERE ft.fact_id = ` + alias + `id AND ft.tag = ANY(`
+	if len(tf.Any) > 0 {
+		b.write(` AND EXISTS (`+has, filterTags(tf.Any))
+		b.q += `::text[]))`
+	}
+	if len(tf.All) > 0 {
+		all := filterTags(tf.All)
+		b.write(` AND (SELECT COUNT(DISTINCT ft.tag) FROM memstore_fact_tags ft WHERE ft.fact_id = `+alias+`id AND ft.tag = ANY(`, all)
+		b.write(`::text[])) = cardinality(ARRAY(SELECT DISTINCT unnest(`, all)
+		b.q += `::text[])))`
+	}
+	if len(tf.None) > 0 {
+		b.write(` AND NOT EXISTS (`+has, filterTags(tf.None))
+		b.q += `::text[]))`
+	}
+}
+
+// factInScope reports whether fact id is a live fact visible to the store.
+func (s *PostgresStore) factInScope(ctx context.Context, db execer, id int64) error {
+	q, args := s.userPredicate(
+		`SELECT 1 FROM memstore_facts WHERE id = $1 AND namespace = $2`+notDeleted(""),
+		[]any{id, s.namespace})
+	rows, err := db.Query(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("pgstore: looking up fact %d: %w", id, err)
+	}
+	found := rows.Next()
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("pgstore: looking up fact %d: %w", id, err)
+	}
+	if !found {
+		return fmt.Errorf("pgstore: fact %d not found", id)
+	}
+	return nil
+}
+
+// TagFact implements memstore.Store.
+func (s *PostgresStore) TagFact(ctx context.Context, id int64, tags ...string) error {
+	tags, err := memstore.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return errors.New("pgstore: no tags given")
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.factInScope(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := insertTags(ctx, tx, id, tags); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("pgstore: committing tags: %w", err)
+	}
+	return nil
+}
+
+// UntagFact implements memstore.Store.
+func (s *PostgresStore) UntagFact(ctx context.Context, id int64, tags ...string) error {
+	if len(tags) == 0 {
+		return errors.New("pgstore: no tags given")
+	}
+	if err := s.factInScope(ctx, s.pool, id); err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx,
+		`DELETE FROM memstore_fact_tags WHERE fact_id = $1 AND tag = ANY($2::text[])`,
+		id, filterTags(tags)); err != nil {
+		return fmt.Errorf("pgstore: untagging fact %d: %w", id, err)
+	}
+	return nil
+}
+
+// TagCounts implements memstore.Store.
+func (s *PostgresStore) TagCounts(ctx context.Context, subject string) ([]memstore.TagCount, error) {
+	var b queryBuilder
+	b.write(`SELECT ft.tag, COUNT(*) FROM memstore_fact_tags ft JOIN memstore_facts f ON f.id = ft.fact_id
+		WHERE f.namespace = `, s.namespace)
+	b.q += ` AND f.superseded_by IS NULL` + notDeleted("f.") + unexpired("f.")
+	s.appendUserFilter(&b, "f.user_id")
+	if subject != "" {
+		s.appendSubjectFilter(&b, "f.subject", subject)
+	}
+	b.q += ` GROUP BY ft.tag ORDER BY COUNT(*) DESC, ft.tag`
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: counting tags: %w", err)
+	}
+	defer rows.Close()
+
+	var out []memstore.TagCount
+	for rows.Next() {
+		var tc memstore.TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, fmt.Errorf("pgstore: scanning tag count: %w", err)
+		}
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+// scopedFactIDs appends the subquery selecting the IDs of the facts in the
+// store's scope.
+func (s *PostgresStore) scopedFactIDs(b *queryBuilder) {
+	b.write(`SELECT id FROM memstore_facts WHERE namespace = `, s.namespace)
+	s.appendUserFilter(b, "user_id")
+}
+
+// RenameTag implements memstore.Store.
+func (s *PostgresStore) RenameTag(ctx context.Context, from, to string) (int64, error) {
+	tags, err := memstore.NormalizeTags([]string{to})
+	if err != nil {
+		return 0, err
+	}
+	from, to = memstore.NormalizeTag(from), tags[0]
+	if from == to {
+		return 0, nil
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Facts that already carry the new tag just lose the old one.
+	var ins queryBuilder
+	ins.write(`INSERT INTO memstore_fact_tags (fact_id, tag) SELECT fact_id, `, to)
+	ins.write(`::text FROM memstore_fact_tags WHERE tag = `, from)
+	ins.q += ` AND fact_id IN (`
+	s.scopedFactIDs(&ins)
+	ins.q += `) ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(ctx, ins.q, ins.args...); err != nil {
+		return 0, fmt.Errorf("pgstore: renaming tag %q: %w", from, err)
+	}
+	var del queryBuilder
+	del.write(`DELETE FROM memstore_fact_tags WHERE tag = `, from)
+	del.q += ` AND fact_id IN (`
+	s.scopedFactIDs(&del)
+	del.q += `)`
+	ct, err := tx.Exec(ctx, del.q, del.args...)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: renaming tag %q: %w", from, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("pgstore: committing tag rename: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+// DeleteTag implements memstore.Store.
+func (s *PostgresStore) DeleteTag(ctx context.Context, tag string) (int64, error) {
+	var b queryBuilder
+	b.write(`DELETE FROM memstore_fact_tags WHERE tag = `, memstore.NormalizeTag(tag))
+	b.q += ` AND fact_id IN (`
+	s.scopedFactIDs(&b)
+	b.q += `)`
+	ct, err := s.pool.Exec(ctx, b.q, b.args...)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: deleting tag %q: %w", tag, err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+// copyNamespaceTags copies the tags of facts copied to another namespace,
+// re-keyed from oldIDs to the matching newIDs.
+func copyNamespaceTags(ctx context.Context, tx pgx.Tx, oldIDs, newIDs []int64) error {
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO memstore_fact_tags (fact_id, tag)
+		 SELECT m.new, t.tag
+		 FROM memstore_fact_tags t
+		 JOIN unnest($1::bigint[], $2::bigint[]) AS m(old, new) ON m.old = t.fact_id`,
+		oldIDs, newIDs); err != nil {
+		return fmt.Errorf("pgstore: copying tags: %w", err)
+	}
+	return nil
+}
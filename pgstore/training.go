@@ -0,0 +1,315 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// SessionStore replays its hint log as training events.
+var _ memstore.TrainingSource = (*SessionStore)(nil)
+
+// TrainingEvents returns the logged retrieval events matching filter, oldest
+// first, each joined with what the logs know about its candidates: fact text,
+// the rank a fact was injected at, and the feedback given in the sessions the
+// event's hint reached. Hints without a recorded search_query (rows from
+// before the query was logged) are skipped -- without the query they are not
+// training data.
+//
+// A hint is produced at the end of one session and injected into a later one,
+// so "the sessions the event reached" are the sessions with a 'hint'
+// injection row for it, not the session that generated it.
+//
+// Scoped-read: every table is filtered on user_id when userID != 0.
+// Service-conditional: at userID 0 (service scope) spans all users.
+func (s *SessionStore) TrainingEvents(ctx context.Context, filter memstore.TrainingFilter) ([]memstore.TrainingEvent, error) {
+	args := []any{}
+	where := " WHERE search_query != ''"
+	if filter.RankerVersion != "" {
+		args = append(args, filter.RankerVersion)
+		where += fmt.Sprintf(" AND ranker_version = $%d", len(args))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	userWhere, args := s.userClause("AND", args)
+	limit := ""
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		limit = fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, session_id, cwd, turn_index, hint_text,
+		       ref_ids, retrieved_ids, candidate_scores,
+		       search_query, ranker_version,
+		       relevance, desirability, created_at
+		FROM context_hints`+where+userWhere+`
+		ORDER BY created_at ASC, id ASC`+limit,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: training events: %w", err)
+	}
+	hints, err := scanHints(rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: training events: %w", err)
+	}
+	if len(hints) == 0 {
+		return nil, nil
+	}
+
+	in, err := s.loadTrainingInputs(ctx, hints)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: training events: %w", err)
+	}
+	events := make([]memstore.TrainingEvent, 0, len(hints))
+	for _, h := range hints {
+		events = append(events, in.event(h))
+	}
+	return events, nil
+}
+
+// trainingInputs is everything TrainingEvents joins onto the hint rows,
+// loaded in bulk so the export is a fixed handful of queries rather than one
+// round-trip per hint.
+type trainingInputs struct {
+	facts        map[string]trainingFact          // fact ID -> text
+	hintSessions map[string][]string              // hint ID -> sessions it was injected into
+	hintFeedback map[string]memstore.FeedbackStat // hint ID -> rating of the hint
+	factRanks    map[sessionRef]int               // (session, fact ID) -> injection rank
+	factScores   map[sessionRef][]int             // (session, fact ID) -> ratings
+}
+
+type trainingFact struct {
+	subject string
+	content string
+}
+
+type sessionRef struct {
+	session string
+	ref     string
+}
+
+func (s *SessionStore) loadTrainingInputs(ctx context.Context, hints []memstore.ContextHint) (*trainingInputs, error) {
+	in := &trainingInputs{
+		facts:        make(map[string]trainingFact),
+		hintSessions: make(map[string][]string),
+		hintFeedback: make(map[string]memstore.FeedbackStat),
+		factRanks:    make(map[sessionRef]int),
+		factScores:   make(map[sessionRef][]int),
+	}
+
+	hintIDs := make([]string, len(hints))
+	var factIDs []int64
+	var factRefs []string
+	seenFact := make(map[string]bool)
+	for i, h := range hints {
+		hintIDs[i] = strconv.FormatInt(h.ID, 10)
+		for _, id := range h.RetrievedIDs {
+			if seenFact[id] {
+				continue
+			}
+			seenFact[id] = true
+			n, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				continue // not a fact ID; it can have no text to train on
+			}
+			factIDs = append(factIDs, n)
+			factRefs = append(factRefs, id)
+		}
+	}
+
+	// Fact text. Facts are not per-user tables here: the hint row is already
+	// owner-filtered, and its candidates came from that owner's own search.
+	rows, err := s.pool.Query(ctx,
+		`SELECT id::text, subject, content FROM memstore_facts WHERE id = ANY($1)`,
+		factIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id string
+		var f trainingFact
+		if err := rows.Scan(&id, &f.subject, &f.content); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		in.facts[id] = f
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Sessions each hint reached.
+	args := []any{hintIDs}
+	userWhere, args := s.userClause("AND", args)
+	rows, err = s.pool.Query(ctx,
+		`SELECT ref_id, session_id FROM context_injections
+		WHERE ref_type = 'hint' AND ref_id = ANY($1)`+userWhere,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []string
+	seenSession := make(map[string]bool)
+	for rows.Next() {
+		var hintID, sessionID string
+		if err := rows.Scan(&hintID, &sessionID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		in.hintSessions[hintID] = append(in.hintSessions[hintID], sessionID)
+		if !seenSession[sessionID] {
+			seenSession[sessionID] = true
+			sessions = append(sessions, sessionID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Ratings of the hints themselves.
+	args = []any{hintIDs}
+	userWhere, args = s.userClause("AND", args)
+	rows, err = s.pool.Query(ctx,
+		`SELECT ref_id, AVG(score)::float8, COUNT(*)::int FROM context_feedback
+		WHERE ref_type = 'hint' AND ref_id = ANY($1)`+userWhere+`
+		GROUP BY ref_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var hintID string
+		var stat memstore.FeedbackStat
+		if err := rows.Scan(&hintID, &stat.Avg, &stat.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		in.hintFeedback[hintID] = stat
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(sessions) == 0 || len(factRefs) == 0 {
+		return in, nil
+	}
+
+	// Fact-level injection ranks in those sessions.
+	args = []any{sessions, factRefs}
+	userWhere, args = s.userClause("AND", args)
+	rows, err = s.pool.Query(ctx,
+		`SELECT session_id, ref_id, rank FROM context_injections
+		WHERE ref_type = 'fact' AND session_id = ANY($1) AND ref_id = ANY($2)`+userWhere,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var k sessionRef
+		var rank int
+		if err := rows.Scan(&k.session, &k.ref, &rank); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		in.factRanks[k] = rank
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Fact-level ratings in those sessions.
+	args = []any{sessions, factRefs}
+	userWhere, args = s.userClause("AND", args)
+	rows, err = s.pool.Query(ctx,
+		`SELECT session_id, ref_id, score FROM context_feedback
+		WHERE ref_type = 'fact' AND session_id = ANY($1) AND ref_id = ANY($2)`+userWhere,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var k sessionRef
+		var score int
+		if err := rows.Scan(&k.session, &k.ref, &score); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		in.factScores[k] = append(in.factScores[k], score)
+	}
+	rows.Close()
+	return in, rows.Err()
+}
+
+// event assembles one TrainingEvent from a hint and the bulk-loaded inputs.
+// A candidate's rank is the lowest injection rank it received in any session
+// the hint reached (-1 if none was recorded); its feedback aggregates its
+// ratings across those sessions.
+func (in *trainingInputs) event(h memstore.ContextHint) memstore.TrainingEvent {
+	hintID := strconv.FormatInt(h.ID, 10)
+	sessions := in.hintSessions[hintID]
+	selected := make(map[string]bool, len(h.RefIDs))
+	for _, id := range h.RefIDs {
+		selected[id] = true
+	}
+
+	ev := memstore.TrainingEvent{
+		HintID:        h.ID,
+		SessionID:     h.SessionID,
+		Query:         h.SearchQuery,
+		RankerVersion: h.RankerVersion,
+		CreatedAt:     h.CreatedAt,
+	}
+	for i, id := range h.RetrievedIDs {
+		f := in.facts[id]
+		c := memstore.TrainingCandidate{
+			RefID:        id,
+			Subject:      f.subject,
+			Text:         f.content,
+			Retrieved:    i,
+			Rank:         -1,
+			Selected:     selected[id],
+			VecScore:     h.CandidateScores[id],
+			HintFeedback: in.hintFeedback[hintID],
+		}
+		// Selected candidates rode along in the hint text, so they were shown
+		// wherever the hint was.
+		c.Shown = c.Selected && len(sessions) > 0
+		sum := 0
+		for _, sid := range sessions {
+			k := sessionRef{session: sid, ref: id}
+			if rank, ok := in.factRanks[k]; ok {
+				c.Shown = true
+				if rank >= 0 && (c.Rank < 0 || rank < c.Rank) {
+					c.Rank = rank
+				}
+			}
+			for _, score := range in.factScores[k] {
+				sum += score
+				c.Feedback.Count++
+			}
+		}
+		if c.Feedback.Count > 0 {
+			c.Feedback.Avg = float64(sum) / float64(c.Feedback.Count)
+		}
+		ev.Candidates = append(ev.Candidates, c)
+	}
+	return ev
+}
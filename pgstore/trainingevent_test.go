@@ -0,0 +1,63 @@
+package pgstore
+
+import (
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestTrainingInputsEvent(t *testing.T) {
+	in := &trainingInputs{
+		facts: map[string]trainingFact{
+			"1": {subject: "s", content: "one"},
+			"2": {subject: "s", content: "two"},
+			"3": {subject: "s", content: "three"},
+		},
+		hintSessions: map[string][]string{"7": {"sA", "sB"}},
+		hintFeedback: map[string]memstore.FeedbackStat{"7": {Avg: 1, Count: 1}},
+		factRanks: map[sessionRef]int{
+			{session: "sA", ref: "2"}: 3,
+			{session: "sB", ref: "2"}: 1,
+			{session: "sB", ref: "3"}: -1, // injected, rank unknown
+		},
+		factScores: map[sessionRef][]int{
+			{session: "sA", ref: "2"}: {1},
+			{session: "sB", ref: "2"}: {-1, 1},
+			{session: "sX", ref: "1"}: {-1}, // a session the hint never reached
+		},
+	}
+	h := memstore.ContextHint{
+		ID:              7,
+		SessionID:       "gen",
+		RefIDs:          []string{"1"},
+		RetrievedIDs:    []string{"1", "2", "3", "9"},
+		CandidateScores: map[string]float64{"1": 0.9, "2": 0.7},
+		SearchQuery:     "q",
+		RankerVersion:   "hint-v1",
+	}
+
+	ev := in.event(h)
+	if ev.HintID != 7 || ev.Query != "q" || ev.SessionID != "gen" || len(ev.Candidates) != 4 {
+		t.Fatalf("event header/candidates wrong: %+v", ev)
+	}
+	c1, c2, c3, c9 := ev.Candidates[0], ev.Candidates[1], ev.Candidates[2], ev.Candidates[3]
+
+	if !c1.Selected || !c1.Shown || c1.Rank != -1 || c1.VecScore != 0.9 || c1.Feedback.Count != 0 {
+		t.Errorf("selected candidate: %+v (want shown via the hint, unrated, rank -1)", c1)
+	}
+	if c1.HintFeedback.Count != 1 {
+		t.Errorf("hint feedback not attached: %+v", c1.HintFeedback)
+	}
+	if c2.Selected || !c2.Shown || c2.Rank != 1 {
+		t.Errorf("injected fact: %+v (want shown, lowest rank 1)", c2)
+	}
+	if c2.Feedback.Count != 3 || c2.Feedback.Avg != 1.0/3 {
+		t.Errorf("fact feedback = %+v, want avg 1/3 over 3 ratings", c2.Feedback)
+	}
+	if !c3.Shown || c3.Rank != -1 {
+		t.Errorf("unknown-rank injection: %+v (want shown, rank -1)", c3)
+	}
+	if c9.Text != "" || c9.Shown || c9.Retrieved != 3 {
+		t.Errorf("missing fact: %+v (want no text, not shown)", c9)
+	}
+}
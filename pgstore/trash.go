@@ -0,0 +1,96 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// migrateV9 adds soft deletion. deleted_at is stamped by Delete and cleared
+// by Restore; Purge removes the row. The partial index serves Trash and
+// Purge without touching live facts.
+func (s *PostgresStore) migrateV9(ctx context.Context) error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_deleted ON memstore_facts (deleted_at) WHERE deleted_at IS NOT NULL`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("pgstore V9 migration: %w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// Trash lists trashed facts, most recently deleted first.
+func (s *PostgresStore) Trash(ctx context.Context, opts memstore.QueryOpts) ([]memstore.Fact, error) {
+	var b queryBuilder
+	b.write(`SELECT `+factColumns+` FROM memstore_facts WHERE deleted_at IS NOT NULL AND namespace = `, s.namespace)
+	s.appendUserFilter(&b, "user_id")
+	if opts.Subject != "" {
+		b.write(` AND subject = `, opts.Subject)
+	}
+	if opts.Category != "" {
+		b.write(` AND category = `, opts.Category)
+	}
+	if opts.Kind != "" {
+		b.write(` AND kind = `, opts.Kind)
+	}
+	if opts.Subsystem != "" {
+		b.write(` AND subsystem = `, opts.Subsystem)
+	}
+	b.q += ` ORDER BY deleted_at DESC, id DESC`
+	if opts.Limit > 0 {
+		b.write(` LIMIT `, opts.Limit)
+	}
+
+	rows, err := s.pool.Query(ctx, b.q, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: listing trash: %w", err)
+	}
+	defer rows.Close()
+
+	return scanFacts(rows)
+}
+
+// Restore takes a fact out of the trash. Its links were never removed, so
+// they reappear with it.
+func (s *PostgresStore) Restore(ctx context.Context, id int64) error {
+	q, args := s.userPredicate(
+		`UPDATE memstore_facts SET deleted_at = NULL WHERE id = $1 AND namespace = $2 AND deleted_at IS NOT NULL`,
+		[]any{id, s.namespace})
+	ct, err := s.pool.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("pgstore: restoring fact %d: %w", id, err)
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("pgstore: fact %d not found in trash", id)
+	}
+	return nil
+}
+
+// Purge permanently removes facts trashed at or before olderThan. The
+// recursive CTE also collects every fact superseded by one of them, however
+// far back the chain goes, because superseded_by has no ON DELETE action.
+// Links go with their facts via ON DELETE CASCADE. A service-scoped store
+// purges every user's trash in its namespace.
+func (s *PostgresStore) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	q, args := s.userPredicate(
+		`SELECT id FROM memstore_facts
+		 WHERE namespace = $1 AND deleted_at IS NOT NULL AND deleted_at <= $2`,
+		[]any{s.namespace, olderThan})
+	ct, err := s.pool.Exec(ctx,
+		`WITH RECURSIVE doomed(id) AS (
+			`+q+`
+			UNION
+			SELECT f.id FROM memstore_facts f JOIN doomed d ON f.superseded_by = d.id
+		)
+		DELETE FROM memstore_facts WHERE id IN (SELECT id FROM doomed)`,
+		args...)
+	if err != nil {
+		return 0, fmt.Errorf("pgstore: purging trash: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
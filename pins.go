@@ -0,0 +1,291 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrPinNotFound is returned by Unpin when the fact is not pinned.
+var ErrPinNotFound = errors.New("memstore: fact is not pinned")
+
+// ErrNotPinnable is returned by Pin for a fact that does not exist in the
+// store or is not the active version of its chain.
+var ErrNotPinnable = errors.New("memstore: only an active fact can be pinned")
+
+// Pin puts a fact at the top of every recall and memory_get_context for a
+// project, whatever the prompt says. A pin names its project either by
+// Subject, matched against the project derived from the working directory
+// (through subject aliases), or by ProjectPath, matched when the working
+// directory is that path or below it. Exactly one of the two is set.
+type Pin struct {
+	FactID      int64     `json:"fact_id"`
+	Subject     string    `json:"subject,omitempty"`
+	ProjectPath string    `json:"project_path,omitempty"`
+	Position    int       `json:"position"` // ascending; ties go to the lower fact ID
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PinnedFact is a pin with the fact it injects. Pins follow supersession:
+// Fact is the active head of the pinned fact's chain, so revising a pinned
+// rule keeps it pinned.
+type PinnedFact struct {
+	Pin
+	Fact Fact `json:"fact"`
+}
+
+// Pinner is implemented by stores that keep pinned facts. Both built-in
+// backends and the HTTP client implement it.
+type Pinner interface {
+	// Pin pins an active fact, or re-scopes and re-orders an existing pin.
+	// A Position of 0 appends a new pin after the others and leaves an
+	// existing pin where it is.
+	Pin(ctx context.Context, p Pin) (*Pin, error)
+	// Unpin removes a fact's pin. factID is the pinned fact or any later
+	// version of it. It returns ErrPinNotFound if neither is pinned.
+	Unpin(ctx context.Context, factID int64) error
+	// Pins lists every pin in order, each with its active fact. Pins whose
+	// chain ends in a deleted or expired fact are left out.
+	Pins(ctx context.Context) ([]PinnedFact, error)
+}
+
+// CheckPin validates a pin before it reaches the store.
+func CheckPin(p Pin) error {
+	if p.FactID <= 0 {
+		return errors.New("memstore: pin needs a fact ID")
+	}
+	if (strings.TrimSpace(p.Subject) == "") == (strings.TrimSpace(p.ProjectPath) == "") {
+		return errors.New("memstore: pin needs exactly one of subject and project path")
+	}
+	if p.ProjectPath != "" && !filepath.IsAbs(p.ProjectPath) {
+		return fmt.Errorf("memstore: pin project path %q is not absolute", p.ProjectPath)
+	}
+	if p.Position < 0 {
+		return errors.New("memstore: pin position must not be negative")
+	}
+	return nil
+}
+
+// Matches reports whether the pin applies to a session in project (the
+// canonical subject derived from the working directory) at cwd. Either may
+// be empty. A subject pin of the form "org/repo" matches project "repo".
+func (p Pin) Matches(project, cwd string, aliases AliasMap) bool {
+	if p.Subject != "" {
+		if project == "" {
+			return false
+		}
+		if aliases.Same(p.Subject, project) {
+			return true
+		}
+		i := strings.LastIndex(p.Subject, "/")
+		return i >= 0 && aliases.Same(p.Subject[i+1:], project)
+	}
+	if p.ProjectPath == "" || cwd == "" {
+		return false
+	}
+	root, dir := filepath.Clean(p.ProjectPath), filepath.Clean(cwd)
+	return dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+// PinsFor returns the pins of s that apply to a session in project at cwd,
+// in order, one per active fact. A store without pin support, or one that
+// fails to answer, yields none: pins are an addition to context, never a
+// reason to fail it.
+func PinsFor(ctx context.Context, s Store, project, cwd string, aliases AliasMap) []PinnedFact {
+	pn, ok := s.(Pinner)
+	if !ok || (project == "" && cwd == "") {
+		return nil
+	}
+	pins, err := pn.Pins(ctx)
+	if err != nil {
+		return nil
+	}
+	var out []PinnedFact
+	seen := make(map[int64]bool)
+	for _, p := range pins {
+		if p.Matches(project, cwd, aliases) && !seen[p.Fact.ID] {
+			seen[p.Fact.ID] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// migrateV20 creates the pin table. A pin is keyed by the fact it pins and
+// goes with it when the fact is purged; namespace and owner come from the
+// fact.
+func (s *SQLiteStore) migrateV20() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS memstore_pins (
+		fact_id      INTEGER PRIMARY KEY REFERENCES memstore_facts(id) ON DELETE CASCADE,
+		subject      TEXT NOT NULL DEFAULT '',
+		project_path TEXT NOT NULL DEFAULT '',
+		position     INTEGER NOT NULL,
+		created_at   TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("memstore V20 migration: %w", err)
+	}
+	return nil
+}
+
+// Pin implements Pinner.
+func (s *SQLiteStore) Pin(ctx context.Context, p Pin) (*Pin, error) {
+	if err := CheckPin(p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var supersededBy sql.NullInt64
+	err = tx.QueryRowContext(ctx,
+		`SELECT superseded_by FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""),
+		p.FactID, s.namespace).Scan(&supersededBy)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: fact %d not found", ErrNotPinnable, p.FactID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("memstore: looking up fact %d: %w", p.FactID, err)
+	}
+	if supersededBy.Valid {
+		return nil, fmt.Errorf("%w: fact %d is superseded by %d", ErrNotPinnable, p.FactID, supersededBy.Int64)
+	}
+
+	if p.Position == 0 {
+		err := tx.QueryRowContext(ctx, `SELECT position FROM memstore_pins WHERE fact_id = ?`, p.FactID).Scan(&p.Position)
+		if err == sql.ErrNoRows {
+			err = tx.QueryRowContext(ctx,
+				`SELECT COALESCE(MAX(p.position), 0) + 1 FROM memstore_pins p
+				 JOIN memstore_facts f ON f.id = p.fact_id WHERE f.namespace = ?`,
+				s.namespace).Scan(&p.Position)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("memstore: placing pin: %w", err)
+		}
+	}
+	p.CreatedAt = time.Now().UTC()
+	var created string
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO memstore_pins (fact_id, subject, project_path, position, created_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (fact_id) DO UPDATE SET subject = excluded.subject, project_path = excluded.project_path, position = excluded.position
+		 RETURNING created_at`,
+		p.FactID, p.Subject, p.ProjectPath, p.Position, p.CreatedAt.Format(time.RFC3339)).Scan(&created); err != nil {
+		return nil, fmt.Errorf("memstore: pinning fact %d: %w", p.FactID, err)
+	}
+	p.CreatedAt, _ = time.Parse(time.RFC3339, created)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("memstore: committing pin: %w", err)
+	}
+	return &p, nil
+}
+
+// pinHeadsSQL walks each pin's supersession chain to its end. Arg: namespace.
+const pinHeadsSQL = `WITH RECURSIVE chain(pin_id, id) AS (
+	SELECT p.fact_id, p.fact_id FROM memstore_pins p JOIN memstore_facts f ON f.id = p.fact_id WHERE f.namespace = ?
+	UNION
+	SELECT c.pin_id, f.superseded_by FROM chain c JOIN memstore_facts f ON f.id = c.id WHERE f.superseded_by IS NOT NULL
+)`
+
+// Unpin implements Pinner. factID may also be a later version of the
+// pinned fact, as Pins reports it.
+func (s *SQLiteStore) Unpin(ctx context.Context, factID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.ExecContext(ctx,
+		pinHeadsSQL+` DELETE FROM memstore_pins WHERE fact_id IN (SELECT pin_id FROM chain WHERE pin_id = ? OR id = ?)`,
+		s.namespace, factID, factID)
+	if err != nil {
+		return fmt.Errorf("memstore: unpinning fact %d: %w", factID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPinNotFound
+	}
+	return nil
+}
+
+// Pins implements Pinner.
+func (s *SQLiteStore) Pins(ctx context.Context) ([]PinnedFact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q := pinHeadsSQL + `
+		SELECT ` + qualifiedFactColumns("f.") + `, p.fact_id, p.subject, p.project_path, p.position, p.created_at
+		FROM memstore_pins p
+		JOIN chain c ON c.pin_id = p.fact_id
+		JOIN memstore_facts f ON f.id = c.id
+		WHERE f.superseded_by IS NULL` + notDeleted("f.")
+	args := []any{s.namespace}
+	appendUnexpiredFilter(&q, &args, "f.")
+	rows, err := s.db.QueryContext(ctx, q+` ORDER BY p.position, p.fact_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: listing pins: %w", err)
+	}
+	defer rows.Close()
+
+	var out []PinnedFact
+	for rows.Next() {
+		var (
+			pf      PinnedFact
+			created string
+		)
+		f, err := scanFact(rows, &pf.FactID, &pf.Subject, &pf.ProjectPath, &pf.Position, &created)
+		if err != nil {
+			return nil, fmt.Errorf("memstore: scanning pin: %w", err)
+		}
+		pf.Fact = *f
+		pf.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		out = append(out, pf)
+	}
+	return out, rows.Err()
+}
+
+// copyNamespacePins copies the pins on facts copied to another namespace,
+// re-keyed by newID (old fact ID -> copy).
+func copyNamespacePins(ctx context.Context, tx *sql.Tx, from string, newID map[int64]int64) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT p.fact_id, p.subject, p.project_path, p.position, p.created_at FROM memstore_pins p
+		 JOIN memstore_facts f ON f.id = p.fact_id WHERE f.namespace = ?`, from)
+	if err != nil {
+		return fmt.Errorf("memstore: reading pins of namespace %q: %w", from, err)
+	}
+	type pin struct {
+		factID                          int64
+		subject, projectPath, createdAt string
+		position                        int
+	}
+	var pins []pin
+	for rows.Next() {
+		var p pin
+		if err := rows.Scan(&p.factID, &p.subject, &p.projectPath, &p.position, &p.createdAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("memstore: scanning pin: %w", err)
+		}
+		pins = append(pins, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("memstore: reading pins of namespace %q: %w", from, err)
+	}
+	for _, p := range pins {
+		id, ok := newID[p.factID]
+		if !ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO memstore_pins (fact_id, subject, project_path, position, created_at) VALUES (?, ?, ?, ?, ?)`,
+			id, p.subject, p.projectPath, p.position, p.createdAt); err != nil {
+			return fmt.Errorf("memstore: copying pin of fact %d: %w", p.factID, err)
+		}
+	}
+	return nil
+}
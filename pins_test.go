@@ -0,0 +1,73 @@
+package memstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestPins(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	rule := insertTestFact(t, s, "never commit generated protobufs", "memstore")
+	path := insertTestFact(t, s, "run make lint before pushing", "tooling")
+	other := insertTestFact(t, s, "use tabs", "herald")
+
+	if _, err := s.Pin(ctx, memstore.Pin{FactID: rule, Subject: "memstore"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Pin(ctx, memstore.Pin{FactID: other, Subject: "herald"}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.Pin(ctx, memstore.Pin{FactID: path, ProjectPath: "/src/memstore"}); err != nil || p.Position != 3 {
+		t.Fatalf("Pin(path) = %+v, %v; want it appended at 3", p, err)
+	}
+	// An explicit position re-orders an existing pin.
+	if _, err := s.Pin(ctx, memstore.Pin{FactID: rule, Subject: "memstore", Position: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Pin(ctx, memstore.Pin{FactID: rule, Subject: "memstore", ProjectPath: "/src"}); err == nil {
+		t.Error("a pin with both subject and project path should be refused")
+	}
+
+	pins := memstore.PinsFor(ctx, s, "memstore", "/src/memstore/cmd", nil)
+	if len(pins) != 2 || pins[0].Fact.ID != path || pins[1].Fact.ID != rule {
+		t.Fatalf("PinsFor = %+v, want path then rule", pins)
+	}
+
+	// Revising a pinned fact keeps it pinned, under its new version.
+	revised, err := s.Revise(ctx, rule, "never commit generated protobufs or mocks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins = memstore.PinsFor(ctx, s, "memstore", "", nil)
+	if len(pins) != 1 || pins[0].FactID != rule || pins[0].Fact.ID != revised {
+		t.Fatalf("PinsFor after revise = %+v, want the revised fact %d", pins, revised)
+	}
+	if _, err := s.Pin(ctx, memstore.Pin{FactID: rule, Subject: "memstore"}); err == nil {
+		t.Error("pinning a superseded fact should be refused")
+	}
+
+	// Aliases of the project match a subject pin.
+	if _, err := s.AddAlias(ctx, "memstore-fork", "memstore"); err != nil {
+		t.Fatal(err)
+	}
+	aliases := memstore.LoadAliases(ctx, s)
+	if pins := memstore.PinsFor(ctx, s, aliases.Canonical("memstore-fork"), "", aliases); len(pins) != 1 {
+		t.Errorf("PinsFor(memstore-fork) = %+v, want the memstore pin", pins)
+	}
+
+	if err := s.Unpin(ctx, revised); err != nil {
+		t.Fatalf("Unpin by the revised ID: %v", err)
+	}
+	if err := s.Unpin(ctx, rule); !errors.Is(err, memstore.ErrPinNotFound) {
+		t.Errorf("second Unpin = %v, want ErrPinNotFound", err)
+	}
+	all, err := s.Pins(ctx)
+	if err != nil || len(all) != 2 {
+		t.Errorf("Pins = %+v, %v; want 2", all, err)
+	}
+}
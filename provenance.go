@@ -0,0 +1,214 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Provenance source kinds.
+const (
+	SourceManual     = "manual"     // stored directly by a person or agent (memory_store, POST /v1/facts, the CLI)
+	SourceExtraction = "extraction" // distilled from a session transcript by the extract pipeline
+	SourceImport     = "import"     // loaded from an export file
+	SourceSummary    = "summary"    // a session summary written by the extract pipeline
+)
+
+// Provenance records where a fact came from. Like Document provenance it is
+// written by the store on insert and never updated afterwards: a revision or
+// merge is a new fact carrying the provenance of the write that created it,
+// while the versions it supersedes keep theirs.
+//
+// Facts stored before provenance was tracked have a zero Provenance.
+type Provenance struct {
+	Source     string `json:"source,omitempty"`      // SourceManual, SourceExtraction, SourceImport or SourceSummary
+	SessionID  string `json:"session_id,omitempty"`  // originating session, if any
+	TurnFirst  string `json:"turn_first,omitempty"`  // UUID of the first transcript turn the fact was drawn from
+	TurnLast   string `json:"turn_last,omitempty"`   // UUID of the last such turn
+	Origin     string `json:"origin,omitempty"`      // identity or token name of the writer; empty for unauthenticated local writes
+	DocumentID int64  `json:"document_id,omitempty"` // cited document-corpus document; 0 = none
+}
+
+// IsZero reports whether p records nothing.
+func (p Provenance) IsZero() bool {
+	return p == Provenance{}
+}
+
+// String renders p on one line for display, e.g.
+// "extraction session=abc turns=u1..u9 origin=laptop". A zero Provenance
+// renders as "unknown".
+func (p Provenance) String() string {
+	if p.IsZero() {
+		return "unknown"
+	}
+	parts := []string{p.Source}
+	if p.Source == "" {
+		parts[0] = "unknown"
+	}
+	if p.SessionID != "" {
+		parts = append(parts, "session="+p.SessionID)
+	}
+	switch {
+	case p.TurnFirst != "" && p.TurnLast != "" && p.TurnFirst != p.TurnLast:
+		parts = append(parts, "turns="+p.TurnFirst+".."+p.TurnLast)
+	case p.TurnFirst != "":
+		parts = append(parts, "turn="+p.TurnFirst)
+	case p.TurnLast != "":
+		parts = append(parts, "turn="+p.TurnLast)
+	}
+	if p.Origin != "" {
+		parts = append(parts, "origin="+p.Origin)
+	}
+	if p.DocumentID != 0 {
+		parts = append(parts, "document="+strconv.FormatInt(p.DocumentID, 10))
+	}
+	return strings.Join(parts, " ")
+}
+
+// validSource reports whether s is a known source kind.
+func validSource(s string) bool {
+	switch s {
+	case SourceManual, SourceExtraction, SourceImport, SourceSummary:
+		return true
+	}
+	return false
+}
+
+// TurnRange returns the UUIDs of the first and last turns that carry one,
+// for stamping Provenance.TurnFirst and TurnLast on facts drawn from them.
+func TurnRange(turns []SessionTurn) (first, last string) {
+	for _, t := range turns {
+		if t.UUID == "" {
+			continue
+		}
+		if first == "" {
+			first = t.UUID
+		}
+		last = t.UUID
+	}
+	return first, last
+}
+
+type provenanceCtxKey struct{}
+
+// WithProvenance returns a context whose writes are stamped with p. Write
+// paths that know where their facts come from -- the HTTP auth boundary (the
+// caller's identity), the extract pipeline (session and turns), Import --
+// set it once so every insert beneath them is attributed without threading
+// it through each call. Fields already set on ctx are kept unless p sets
+// them.
+func WithProvenance(ctx context.Context, p Provenance) context.Context {
+	if prev, ok := ProvenanceFromContext(ctx); ok {
+		p = p.fill(prev)
+	}
+	return context.WithValue(ctx, provenanceCtxKey{}, p)
+}
+
+// ProvenanceFromContext returns the provenance set on ctx by WithProvenance.
+func ProvenanceFromContext(ctx context.Context) (Provenance, bool) {
+	p, ok := ctx.Value(provenanceCtxKey{}).(Provenance)
+	return p, ok
+}
+
+// fill returns p with its empty fields taken from d.
+func (p Provenance) fill(d Provenance) Provenance {
+	if p.Source == "" {
+		p.Source = d.Source
+	}
+	if p.SessionID == "" {
+		p.SessionID = d.SessionID
+	}
+	if p.TurnFirst == "" {
+		p.TurnFirst = d.TurnFirst
+	}
+	if p.TurnLast == "" {
+		p.TurnLast = d.TurnLast
+	}
+	if p.Origin == "" {
+		p.Origin = d.Origin
+	}
+	if p.DocumentID == 0 {
+		p.DocumentID = d.DocumentID
+	}
+	return p
+}
+
+// ResolveProvenance computes the provenance a store records for a fact
+// written with p under ctx. Fields set on p win over the context's, except
+// Origin: an origin on ctx comes from an authenticated identity and is not
+// caller-assertable. An unset Source becomes SourceManual.
+func ResolveProvenance(ctx context.Context, p Provenance) (Provenance, error) {
+	if c, ok := ProvenanceFromContext(ctx); ok {
+		p = p.fill(c)
+		if c.Origin != "" {
+			p.Origin = c.Origin
+		}
+	}
+	if p.Source == "" {
+		p.Source = SourceManual
+	}
+	if !validSource(p.Source) {
+		return p, fmt.Errorf("memstore: unknown provenance source %q (want %s|%s|%s|%s)",
+			p.Source, SourceManual, SourceExtraction, SourceImport, SourceSummary)
+	}
+	return p, nil
+}
+
+// ProvenanceFilter restricts a query to facts with matching provenance.
+// Empty fields match anything.
+type ProvenanceFilter struct {
+	Source     string `json:"source,omitempty"`      // source kind
+	SessionID  string `json:"session_id,omitempty"`  // originating session
+	Origin     string `json:"origin,omitempty"`      // writer identity
+	DocumentID int64  `json:"document_id,omitempty"` // cited document; 0 = any
+}
+
+// migrateV17 adds the typed provenance columns. Existing rows keep the empty
+// defaults, which read back as an unknown (zero) Provenance.
+func (s *SQLiteStore) migrateV17() error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN source TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN source_session TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN source_turn_first TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN source_turn_last TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN source_origin TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE memstore_facts ADD COLUMN source_document_id INTEGER`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_source_session ON memstore_facts(source_session) WHERE source_session <> ''`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("memstore V17 migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendProvenanceFilter appends WHERE clauses for pf to q. alias is a
+// table alias prefix such as "f." or "".
+func appendProvenanceFilter(q *string, args *[]any, alias string, pf ProvenanceFilter) {
+	if pf.Source != "" {
+		*q += ` AND ` + alias + `source = ?`
+		*args = append(*args, pf.Source)
+	}
+	if pf.SessionID != "" {
+		*q += ` AND ` + alias + `source_session = ?`
+		*args = append(*args, pf.SessionID)
+	}
+	if pf.Origin != "" {
+		*q += ` AND ` + alias + `source_origin = ?`
+		*args = append(*args, pf.Origin)
+	}
+	if pf.DocumentID != 0 {
+		*q += ` AND ` + alias + `source_document_id = ?`
+		*args = append(*args, pf.DocumentID)
+	}
+}
+
+// nullableID maps 0 to NULL for optional ID columns.
+func nullableID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
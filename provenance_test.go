@@ -0,0 +1,94 @@
+package memstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestResolveProvenance(t *testing.T) {
+	ctx := memstore.WithProvenance(context.Background(), memstore.Provenance{Origin: "laptop", SessionID: "outer"})
+	ctx = memstore.WithProvenance(ctx, memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "inner"})
+
+	got, err := memstore.ResolveProvenance(ctx, memstore.Provenance{TurnFirst: "t1", Origin: "forged"})
+	if err != nil {
+		t.Fatalf("ResolveProvenance: %v", err)
+	}
+	want := memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "inner", TurnFirst: "t1", Origin: "laptop"}
+	if got != want {
+		t.Errorf("ResolveProvenance = %+v, want %+v", got, want)
+	}
+
+	if got, _ := memstore.ResolveProvenance(context.Background(), memstore.Provenance{}); got.Source != memstore.SourceManual {
+		t.Errorf("default source = %q, want manual", got.Source)
+	}
+	if _, err := memstore.ResolveProvenance(context.Background(), memstore.Provenance{Source: "hearsay"}); err == nil {
+		t.Error("ResolveProvenance accepted an unknown source")
+	}
+}
+
+func TestProvenanceString(t *testing.T) {
+	for _, tc := range []struct {
+		p    memstore.Provenance
+		want string
+	}{
+		{memstore.Provenance{}, "unknown"},
+		{memstore.Provenance{Source: memstore.SourceManual}, "manual"},
+		{memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "s1", TurnFirst: "a", TurnLast: "b", Origin: "laptop"},
+			"extraction session=s1 turns=a..b origin=laptop"},
+		{memstore.Provenance{Source: memstore.SourceSummary, TurnFirst: "a", TurnLast: "a", DocumentID: 7}, "summary turn=a document=7"},
+	} {
+		if got := tc.p.String(); got != tc.want {
+			t.Errorf("%+v.String() = %q, want %q", tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestTurnRange(t *testing.T) {
+	first, last := memstore.TurnRange([]memstore.SessionTurn{{UUID: ""}, {UUID: "u1"}, {UUID: "u2"}, {UUID: ""}})
+	if first != "u1" || last != "u2" {
+		t.Errorf("TurnRange = %q, %q; want u1, u2", first, last)
+	}
+	if first, last := memstore.TurnRange(nil); first != "" || last != "" {
+		t.Errorf("TurnRange(nil) = %q, %q; want empty", first, last)
+	}
+}
+
+func TestExportImport_Provenance(t *testing.T) {
+	ctx := context.Background()
+	srcDB := openTestDB(t)
+	src, err := memstore.NewSQLiteStore(srcDB, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := memstore.Provenance{Source: memstore.SourceExtraction, SessionID: "s1", TurnFirst: "a", TurnLast: "b", Origin: "laptop", DocumentID: 4}
+	if _, err := src.Insert(memstore.WithProvenance(ctx, prov), memstore.Fact{Content: "extracted", Subject: "p", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := memstore.Export(ctx, srcDB)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(data.Facts) != 1 || data.Facts[0].Provenance == nil || *data.Facts[0].Provenance != prov {
+		t.Fatalf("exported provenance = %+v, want %+v", data.Facts[0].Provenance, prov)
+	}
+
+	dstDB := openTestDB(t)
+	if _, err := memstore.Import(ctx, dstDB, data, memstore.ImportOpts{}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	dst, err := memstore.NewSQLiteStore(dstDB, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	facts, err := dst.List(ctx, memstore.QueryOpts{Provenance: memstore.ProvenanceFilter{Source: memstore.SourceImport}})
+	if err != nil || len(facts) != 1 {
+		t.Fatalf("imported facts = %+v, %v; want one with source import", facts, err)
+	}
+	want := memstore.Provenance{Source: memstore.SourceImport, SessionID: "s1", TurnFirst: "a", TurnLast: "b", Origin: "laptop"}
+	if facts[0].Provenance != want {
+		t.Errorf("imported provenance = %+v, want %+v (document citation dropped)", facts[0].Provenance, want)
+	}
+}
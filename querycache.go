@@ -0,0 +1,340 @@
+package memstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewjhunter/go-embedding"
+)
+
+// Persistent query-embedding cache defaults. Hook-driven recall embeds the
+// same handful of prompts across many short-lived processes; a month of
+// entries at 10k rows is a few tens of MB at typical dimensions.
+//
+// Trimming the table to its bounds costs a scan, so it runs after about one
+// write in DefaultQueryCacheTrimEvery rather than on every cache miss; the
+// table overshoots its size bound by a few dozen rows at most in between.
+const (
+	DefaultQueryCacheTTL        = 30 * 24 * time.Hour
+	DefaultQueryCacheMaxEntries = 10000
+	DefaultQueryCacheTrimEvery  = 64
+)
+
+// QueryCachePolicy bounds the persistent query-embedding cache. Zero fields
+// take the Default* values; a negative MaxEntries disables the cache and a
+// negative TTL keeps entries until they are evicted by size.
+type QueryCachePolicy struct {
+	MaxEntries int           // rows kept across all models; least recently used go first
+	TTL        time.Duration // entries older than this are re-embedded
+	TrimEvery  int           // trim after one write in this many, chosen at random; 1 trims on every write
+}
+
+// DefaultQueryCachePolicy returns the policy stores start with.
+func DefaultQueryCachePolicy() QueryCachePolicy {
+	return QueryCachePolicy{MaxEntries: DefaultQueryCacheMaxEntries, TTL: DefaultQueryCacheTTL, TrimEvery: DefaultQueryCacheTrimEvery}
+}
+
+func (p QueryCachePolicy) withDefaults() QueryCachePolicy {
+	if p.MaxEntries == 0 {
+		p.MaxEntries = DefaultQueryCacheMaxEntries
+	}
+	if p.TTL == 0 {
+		p.TTL = DefaultQueryCacheTTL
+	}
+	if p.TrimEvery <= 0 {
+		p.TrimEvery = DefaultQueryCacheTrimEvery
+	}
+	return p
+}
+
+// Enabled reports whether the policy caches at all.
+func (p QueryCachePolicy) Enabled() bool { return p.MaxEntries >= 0 }
+
+// QueryCachePolicyFromEnv reads a QueryCachePolicy from {prefix}_MAX_ENTRIES
+// (0 disables the cache) and {prefix}_TTL (a Go duration; "off" keeps entries
+// until evicted by size). Unset variables keep the defaults.
+func QueryCachePolicyFromEnv(prefix string) (QueryCachePolicy, error) {
+	pol := DefaultQueryCachePolicy()
+	if v := os.Getenv(prefix + "_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return QueryCachePolicy{}, fmt.Errorf("memstore: invalid %s_MAX_ENTRIES %q: must be a non-negative integer", prefix, v)
+		}
+		if n == 0 {
+			n = -1 // an explicit 0 means disabled, not "the default"
+		}
+		pol.MaxEntries = n
+	}
+	if v := os.Getenv(prefix + "_TTL"); v != "" {
+		if v == "off" {
+			pol.TTL = -1
+		} else {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return QueryCachePolicy{}, fmt.Errorf("memstore: invalid %s_TTL %q: must be a positive duration or \"off\"", prefix, v)
+			}
+			pol.TTL = d
+		}
+	}
+	return pol, nil
+}
+
+// QueryHash returns the persistent-cache key for a query: a SHA-256 of the
+// query with case folded and whitespace runs collapsed, the same
+// normalization as embedding.QueryCache. The raw text is still what gets
+// embedded on a miss.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(strings.ToLower(query)), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// QueryCacheTable is the storage behind a persistent query-embedding cache:
+// one row per (model, query hash). Each backend keeps it in its own database,
+// so every process sharing that database shares the cache, whatever model
+// each of them embeds with.
+type QueryCacheTable interface {
+	// GetQueryEmbeddings returns the cached embeddings for hashes under model
+	// that were stored at or after notBefore, keyed by hash, and marks them
+	// used.
+	GetQueryEmbeddings(ctx context.Context, model string, hashes []string, notBefore time.Time) (map[string][]float32, error)
+	// PutQueryEmbeddings stores embeddings keyed by hash under model.
+	PutQueryEmbeddings(ctx context.Context, model string, embs map[string][]float32) error
+	// TrimQueryEmbeddings deletes rows stored before notBefore, then the
+	// least recently used beyond maxEntries, across all models.
+	TrimQueryEmbeddings(ctx context.Context, notBefore time.Time, maxEntries int) error
+}
+
+// NewQueryCacheEmbedder wraps e so that Embed consults table before calling
+// it and stores what it embeds. Use it for query embedding only: document
+// inputs are all distinct and would only evict useful entries. Cache errors
+// are never fatal; a failed lookup or write falls through to e. A disabled
+// policy returns e unchanged.
+//
+// Entries are keyed by e.Model(), so switching models never serves vectors
+// from the old model's space. The old model's rows are deleted when a store
+// opens on a database whose recorded embedding model has moved on, and
+// otherwise age out or are evicted by size.
+func NewQueryCacheEmbedder(e embedding.Embedder, table QueryCacheTable, policy QueryCachePolicy) embedding.Embedder {
+	policy = policy.withDefaults()
+	if e == nil || table == nil || !policy.Enabled() {
+		return e
+	}
+	return &queryCacheEmbedder{Embedder: e, table: table, policy: policy}
+}
+
+type queryCacheEmbedder struct {
+	embedding.Embedder
+	table  QueryCacheTable
+	policy QueryCachePolicy
+}
+
+func (c *queryCacheEmbedder) notBefore() time.Time {
+	if c.policy.TTL < 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-c.policy.TTL)
+}
+
+func (c *queryCacheEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := c.Model()
+	hashes := make([]string, len(texts))
+	for i, t := range texts {
+		hashes[i] = QueryHash(t)
+	}
+	notBefore := c.notBefore()
+	cached, _ := c.table.GetQueryEmbeddings(ctx, model, hashes, notBefore)
+
+	out := make([][]float32, len(texts))
+	var missIdx []int
+	var missTexts []string
+	for i, h := range hashes {
+		if emb, ok := cached[h]; ok {
+			out[i] = emb
+		} else {
+			missIdx = append(missIdx, i)
+			missTexts = append(missTexts, texts[i])
+		}
+	}
+	if len(missTexts) == 0 {
+		return out, nil
+	}
+
+	embs, err := c.Embedder.Embed(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	fresh := make(map[string][]float32, len(embs))
+	for j, idx := range missIdx {
+		if j >= len(embs) {
+			break
+		}
+		out[idx] = embs[j]
+		if len(embs[j]) > 0 {
+			fresh[hashes[idx]] = embs[j]
+		}
+	}
+	if len(fresh) > 0 && c.table.PutQueryEmbeddings(ctx, model, fresh) == nil && rand.IntN(c.policy.TrimEvery) == 0 {
+		c.table.TrimQueryEmbeddings(ctx, notBefore, c.policy.MaxEntries)
+	}
+	return out, nil
+}
+
+// migrateV13 creates the persistent query-embedding cache. Rows carry no
+// namespace: an embedding depends only on the model and the text.
+// Timestamps are unix seconds.
+func (s *SQLiteStore) migrateV13() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS memstore_query_cache (
+			model        TEXT NOT NULL,
+			query_hash   TEXT NOT NULL,
+			embedding    BLOB NOT NULL,
+			created_at   INTEGER NOT NULL,
+			last_used_at INTEGER NOT NULL,
+			PRIMARY KEY (model, query_hash)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_query_cache_used ON memstore_query_cache(last_used_at)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("memstore V13 migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetQueryCache replaces the persistent query-embedding cache policy used by
+// Search and SearchBatch. Stores start with DefaultQueryCachePolicy; a policy
+// with a negative MaxEntries disables the cache. Intended to be called once
+// at startup.
+func (s *SQLiteStore) SetQueryCache(policy QueryCachePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryEmbedder = NewQueryCacheEmbedder(s.embedder, sqliteQueryCache{s}, policy)
+}
+
+// cachedQueryEmbedder returns the embedder for search queries: the store's
+// embedder behind the persistent cache, when enabled.
+func (s *SQLiteStore) cachedQueryEmbedder() embedding.Embedder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.queryEmbedder
+}
+
+// sqliteQueryCache is the SQLite QueryCacheTable, in memstore_query_cache.
+type sqliteQueryCache struct{ s *SQLiteStore }
+
+func (c sqliteQueryCache) GetQueryEmbeddings(ctx context.Context, model string, hashes []string, notBefore time.Time) (map[string][]float32, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	args := []any{model, notBefore.Unix()}
+	for _, h := range hashes {
+		args = append(args, h)
+	}
+	placeholders := strings.Repeat(",?", len(hashes))[1:]
+
+	c.s.mu.RLock()
+	rows, err := c.s.db.QueryContext(ctx,
+		`SELECT query_hash, embedding FROM memstore_query_cache
+		WHERE model = ? AND created_at >= ? AND query_hash IN (`+placeholders+`)`,
+		args...,
+	)
+	if err != nil {
+		c.s.mu.RUnlock()
+		return nil, err
+	}
+	out := make(map[string][]float32)
+	for rows.Next() {
+		var h string
+		var blob []byte
+		if err := rows.Scan(&h, &blob); err != nil {
+			rows.Close()
+			c.s.mu.RUnlock()
+			return nil, err
+		}
+		out[h] = embedding.DecodeFloat32s(blob)
+	}
+	rows.Close()
+	c.s.mu.RUnlock()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	// Touch the hits so size eviction is least-recently-used.
+	hitArgs := []any{time.Now().Unix(), model}
+	for h := range out {
+		hitArgs = append(hitArgs, h)
+	}
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	_, err = c.s.db.ExecContext(ctx,
+		`UPDATE memstore_query_cache SET last_used_at = ?
+		WHERE model = ? AND query_hash IN (`+strings.Repeat(",?", len(out))[1:]+`)`,
+		hitArgs...,
+	)
+	return out, err
+}
+
+func (c sqliteQueryCache) PutQueryEmbeddings(ctx context.Context, model string, embs map[string][]float32) error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	tx, err := c.s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	for h, emb := range embs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO memstore_query_cache (model, query_hash, embedding, created_at, last_used_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (model, query_hash) DO UPDATE SET
+				embedding = excluded.embedding, created_at = excluded.created_at, last_used_at = excluded.last_used_at`,
+			model, h, embedding.EncodeFloat32s(emb), now, now,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (c sqliteQueryCache) TrimQueryEmbeddings(ctx context.Context, notBefore time.Time, maxEntries int) error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if _, err := c.s.db.ExecContext(ctx,
+		`DELETE FROM memstore_query_cache WHERE created_at < ?`, notBefore.Unix(),
+	); err != nil {
+		return err
+	}
+	_, err := c.s.db.ExecContext(ctx, `
+		DELETE FROM memstore_query_cache WHERE rowid IN (
+			SELECT rowid FROM memstore_query_cache
+			ORDER BY last_used_at DESC, rowid DESC
+			LIMIT -1 OFFSET ?
+		)`, maxEntries)
+	return err
+}
+
+// purgeQueryCacheModels deletes cached query embeddings from models other
+// than the database's recorded embedding model. Once a model is recorded,
+// only processes embedding with it can open the database, so other models'
+// rows can never be served again. Called once at open.
+func (s *SQLiteStore) purgeQueryCacheModels() error {
+	_, err := s.db.Exec(`DELETE FROM memstore_query_cache
+		WHERE model <> (SELECT value FROM memstore_meta WHERE key = 'embedding_model')`)
+	if err != nil {
+		return fmt.Errorf("memstore: purging stale query cache: %w", err)
+	}
+	return nil
+}
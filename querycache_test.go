@@ -0,0 +1,177 @@
+package memstore_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func openFileStore(t *testing.T, path string, embedder *mockEmbedder) (*memstore.SQLiteStore, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	store, err := memstore.NewSQLiteStore(db, embedder, "test")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	return store, db
+}
+
+func queryCacheRows(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM memstore_query_cache`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestQueryCache_SharedAcrossStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memstore.db")
+	ctx := context.Background()
+
+	first := &mockEmbedder{dim: 4}
+	store, _ := openFileStore(t, path, first)
+	if _, err := store.Search(ctx, "What is the deploy process?", memstore.SearchOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if first.callCount != 1 {
+		t.Fatalf("first search: %d embed calls, want 1", first.callCount)
+	}
+
+	// A second process on the same database, asking the same question with
+	// different case and spacing, never calls its embedder.
+	second := &mockEmbedder{dim: 4}
+	other, _ := openFileStore(t, path, second)
+	if _, err := other.Search(ctx, "  what is the DEPLOY   process? ", memstore.SearchOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.SearchBatch(ctx, []string{"what is the deploy process?", "new question"}, memstore.SearchOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if second.callCount != 1 {
+		t.Errorf("second store: %d embed calls, want 1 (only the new batch query)", second.callCount)
+	}
+
+	// Disabled: every search embeds.
+	other.SetQueryCache(memstore.QueryCachePolicy{MaxEntries: -1})
+	other.Search(ctx, "new question", memstore.SearchOpts{})
+	if second.callCount != 2 {
+		t.Errorf("disabled cache: %d embed calls, want 2", second.callCount)
+	}
+}
+
+func TestQueryCache_Bounds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memstore.db")
+	ctx := context.Background()
+	embedder := &mockEmbedder{dim: 4}
+	store, db := openFileStore(t, path, embedder)
+
+	store.SetQueryCache(memstore.QueryCachePolicy{MaxEntries: 2, TrimEvery: 1})
+	for _, q := range []string{"one", "two", "three"} {
+		if _, err := store.Search(ctx, q, memstore.SearchOpts{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := queryCacheRows(t, db); n != 2 {
+		t.Errorf("cache holds %d rows, want the 2-row bound", n)
+	}
+
+	// Expired entries are re-embedded.
+	if _, err := db.Exec(`UPDATE memstore_query_cache SET created_at = created_at - ?`, int64((48 * time.Hour).Seconds())); err != nil {
+		t.Fatal(err)
+	}
+	store.SetQueryCache(memstore.QueryCachePolicy{TTL: 24 * time.Hour, TrimEvery: 1})
+	calls := embedder.callCount
+	store.Search(ctx, "three", memstore.SearchOpts{})
+	if embedder.callCount != calls+1 {
+		t.Errorf("expired entry served from cache")
+	}
+	if n := queryCacheRows(t, db); n != 1 {
+		t.Errorf("cache holds %d rows after expiry, want 1", n)
+	}
+}
+
+func TestQueryCache_ModelChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memstore.db")
+	ctx := context.Background()
+
+	cachedModels := func(db *sql.DB) []string {
+		t.Helper()
+		var models []string
+		rows, err := db.Query(`SELECT DISTINCT model FROM memstore_query_cache ORDER BY model`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m string
+			rows.Scan(&m)
+			models = append(models, m)
+		}
+		return models
+	}
+
+	old := &mockEmbedder{dim: 4, model: "old-model"}
+	store, db := openFileStore(t, path, old)
+	store.Search(ctx, "shared query", memstore.SearchOpts{})
+
+	// No facts were embedded, so the database accepts a new model; its cache
+	// must not serve the old model's vector. Processes on different models
+	// share the table without deleting each other's rows.
+	fresh := &mockEmbedder{dim: 4, model: "new-model"}
+	other, _ := openFileStore(t, path, fresh)
+	other.Search(ctx, "shared query", memstore.SearchOpts{})
+	if fresh.callCount != 1 {
+		t.Errorf("new model: %d embed calls, want 1", fresh.callCount)
+	}
+	store.Search(ctx, "shared query", memstore.SearchOpts{})
+	if old.callCount != 1 {
+		t.Errorf("old model: %d embed calls, want its row still cached", old.callCount)
+	}
+	if got := cachedModels(db); len(got) != 2 {
+		t.Errorf("cached models = %v, want both", got)
+	}
+
+	// Once facts are embedded the database is locked to new-model, and the
+	// next open drops the other model's rows.
+	if _, err := other.Insert(ctx, memstore.Fact{Content: "a fact", Subject: "x", Category: "note"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.EmbedFacts(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	openFileStore(t, path, &mockEmbedder{dim: 4, model: "new-model"})
+	if got := cachedModels(db); len(got) != 1 || got[0] != "new-model" {
+		t.Errorf("cached models after reopen = %v, want only new-model", got)
+	}
+}
+
+func TestQueryCachePolicyFromEnv(t *testing.T) {
+	t.Setenv("TESTQC_MAX_ENTRIES", "0")
+	t.Setenv("TESTQC_TTL", "off")
+	p, err := memstore.QueryCachePolicyFromEnv("TESTQC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Enabled() || p.TTL >= 0 {
+		t.Errorf("policy = %+v, want disabled with no expiry", p)
+	}
+	t.Setenv("TESTQC_MAX_ENTRIES", "50")
+	t.Setenv("TESTQC_TTL", "1h")
+	if p, err = memstore.QueryCachePolicyFromEnv("TESTQC"); err != nil || p.MaxEntries != 50 || p.TTL != time.Hour {
+		t.Errorf("policy = %+v, %v", p, err)
+	}
+	t.Setenv("TESTQC_TTL", "-1h")
+	if _, err := memstore.QueryCachePolicyFromEnv("TESTQC"); err == nil {
+		t.Error("expected an error for a negative TTL")
+	}
+}
@@ -0,0 +1,196 @@
+package memstore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is a parsed task recurrence rule. Three forms are accepted:
+//
+//   - "daily", "weekly", "monthly" or "yearly" — calendar steps taken
+//     with time.AddDate, so a month after January 31 normalizes into
+//     early March;
+//   - "every <ttl>" — a fixed interval in any form ParseTTL accepts
+//     ("every 90d", "every 2w", "every 36h");
+//   - a five-field cron expression "minute hour day-of-month month
+//     day-of-week" with *, lists, ranges and /steps, evaluated in UTC
+//     ("0 9 * * 1" is 09:00 UTC every Monday). As in cron, when both day
+//     fields are restricted a day matching either one qualifies.
+type Recurrence struct {
+	rule   string
+	every  time.Duration
+	months int // calendar step in months (monthly, yearly)
+	days   int // calendar step in days (daily, weekly)
+	cron   *cronSpec
+}
+
+// ParseRecurrence parses a recurrence rule.
+func ParseRecurrence(s string) (Recurrence, error) {
+	rule := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	r := Recurrence{rule: rule}
+	switch rule {
+	case "daily":
+		r.days = 1
+	case "weekly":
+		r.days = 7
+	case "monthly":
+		r.months = 1
+	case "yearly":
+		r.months = 12
+	default:
+		if ttl, ok := strings.CutPrefix(rule, "every "); ok {
+			d, err := ParseTTL(ttl)
+			if err != nil {
+				return Recurrence{}, fmt.Errorf("memstore: invalid recurrence %q: %w", s, err)
+			}
+			r.every = d
+			break
+		}
+		spec, err := parseCron(rule)
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("memstore: invalid recurrence %q: want daily, weekly, monthly, yearly, \"every <duration>\" or a 5-field cron expression: %w", s, err)
+		}
+		r.cron = spec
+		if r.Next(time.Now()).IsZero() {
+			return Recurrence{}, fmt.Errorf("memstore: invalid recurrence %q: the cron expression never matches", s)
+		}
+	}
+	return r, nil
+}
+
+// String returns the rule in its normalized form.
+func (r Recurrence) String() string { return r.rule }
+
+// wholeDays reports whether every occurrence falls a whole number of days
+// after the previous one, so date-only due dates stay date-only.
+func (r Recurrence) wholeDays() bool {
+	if r.cron != nil {
+		return false
+	}
+	return r.every%(24*time.Hour) == 0
+}
+
+// Next returns the first occurrence after from. For a cron rule that is
+// the next matching minute; otherwise it is from advanced by one step. It
+// returns the zero time for a cron rule with no match in the next five
+// years.
+func (r Recurrence) Next(from time.Time) time.Time {
+	switch {
+	case r.cron != nil:
+		return r.cron.next(from)
+	case r.every > 0:
+		return from.Add(r.every)
+	default:
+		return from.AddDate(0, r.months, r.days)
+	}
+}
+
+// cronSpec is a parsed five-field cron expression, one bit per allowed
+// value.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+func parseCron(s string) (*cronSpec, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("want 5 fields, got %d", len(fields))
+	}
+	var spec cronSpec
+	var err error
+	if spec.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if spec.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if spec.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if spec.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if spec.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if spec.dow&(1<<7) != 0 { // 7 is Sunday too
+		spec.dow |= 1
+	}
+	spec.domAny = strings.HasPrefix(fields[2], "*")
+	spec.dowAny = strings.HasPrefix(fields[4], "*")
+	return &spec, nil
+}
+
+// parseCronField parses one comma-separated cron field into a bit set of
+// the values in [lo, hi] it allows.
+func parseCronField(s string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			} else if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// next returns the first matching minute after from, in UTC, or the zero
+// time if there is none within five years.
+func (c *cronSpec) next(from time.Time) time.Time {
+	t := from.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
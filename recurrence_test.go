@@ -0,0 +1,45 @@
+package memstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	// Sunday 2026-05-03 10:30 UTC.
+	from := time.Date(2026, 5, 3, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		rule string
+		want time.Time
+	}{
+		{"daily", from.AddDate(0, 0, 1)},
+		{"Weekly", from.AddDate(0, 0, 7)},
+		{"monthly", time.Date(2026, 6, 3, 10, 30, 0, 0, time.UTC)},
+		{"every 90d", from.Add(90 * 24 * time.Hour)},
+		{"every  36h", from.Add(36 * time.Hour)},
+		{"0 9 * * 1", time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 5, 3, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1 1,7 *", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 10 * * 7", time.Date(2026, 5, 10, 10, 30, 0, 0, time.UTC)}, // 7 is Sunday; strictly after from
+		// Both day fields restricted: the 15th or any Friday, whichever is first.
+		{"0 12 15 * 5", time.Date(2026, 5, 8, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		r, err := memstore.ParseRecurrence(tc.rule)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", tc.rule, err)
+			continue
+		}
+		if got := r.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q.Next(%v) = %v, want %v", tc.rule, from, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", "fortnightly", "every", "every -1d", "0 9 * *", "60 * * * *", "0 0 30 2 *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := memstore.ParseRecurrence(bad); err == nil {
+			t.Errorf("ParseRecurrence(%q) accepted an invalid rule", bad)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package memstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reminder defaults. A task due on a date surfaces at the start of that
+// day; a quarter-hour sweep is fine-grained enough for day-scale deadlines.
+const (
+	DefaultRemindLead       = 24 * time.Hour
+	DefaultReminderInterval = 15 * time.Minute
+)
+
+// A deferred task is one that should not surface until shortly before it
+// is due: a recurring task (metadata "recur") or one with an explicit
+// reminder lead ("remind", a TTL). Its "remind_at" is the deadline less the
+// lead, and it carries surface="startup" only once that time has passed —
+// the reminder evaluator sets it and stamps "reminded_at" so a task whose
+// surface flag was cleared by hand is not surfaced again.
+
+// taskSchedule returns the metadata changes that keep task metadata meta's
+// reminder consistent with its due date, recurrence and lead, as a patch:
+// nil values delete keys. A recurring task without a due date gets its
+// first occurrence as one.
+func taskSchedule(meta map[string]any, now time.Time) (map[string]any, error) {
+	due, _ := meta["due"].(string)
+	recur, _ := meta["recur"].(string)
+	lead, _ := meta["remind"].(string)
+	status, _ := meta["status"].(string)
+	patch := map[string]any{}
+
+	if recur == "" && lead == "" {
+		// Not (or no longer) deferred: a task that was waiting on its
+		// reminder surfaces like any other open task.
+		if _, ok := meta["remind_at"]; ok {
+			patch["remind_at"] = nil
+			patch["reminded_at"] = nil
+			if !taskClosed(status) {
+				patch["surface"] = "startup"
+			}
+		}
+		return patch, nil
+	}
+
+	if due == "" {
+		if recur == "" {
+			return nil, errors.New("memstore: a task reminder needs a due date")
+		}
+		r, err := ParseRecurrence(recur)
+		if err != nil {
+			return nil, err
+		}
+		first := r.Next(now)
+		if r.wholeDays() {
+			due = first.UTC().Format(time.DateOnly) // due by the end of that day
+		} else {
+			due = first.UTC().Format(time.RFC3339)
+		}
+		patch["due"] = due
+	} else if recur != "" {
+		if _, err := ParseRecurrence(recur); err != nil {
+			return nil, err
+		}
+	}
+	deadline, err := ParseDue(due)
+	if err != nil {
+		return nil, err
+	}
+	leadDur := DefaultRemindLead
+	if lead != "" {
+		if leadDur, err = ParseTTL(lead); err != nil {
+			return nil, fmt.Errorf("memstore: invalid reminder lead: %w", err)
+		}
+	}
+
+	remindAt := deadline.Add(-leadDur)
+	patch["remind_at"] = remindAt.UTC().Format(time.RFC3339)
+	if taskClosed(status) {
+		return patch, nil
+	}
+	if now.Before(remindAt) {
+		patch["surface"] = nil
+		patch["reminded_at"] = nil
+	} else if _, reminded := meta["reminded_at"]; !reminded {
+		patch["surface"] = "startup"
+		patch["reminded_at"] = now.UTC().Format(time.RFC3339)
+	}
+	return patch, nil
+}
+
+// ScheduleTask prepares the metadata of a new task: when it recurs or sets
+// a reminder lead it validates them, fills in a first due date for a
+// recurring task that has none, and withholds the startup surface flag
+// until the reminder time. Other tasks are left as they are.
+func ScheduleTask(meta map[string]any, now time.Time) error {
+	patch, err := taskSchedule(meta, now)
+	if err != nil {
+		return err
+	}
+	mergePatch(meta, patch)
+	return nil
+}
+
+// mergePatch applies patch to meta the way UpdateMetadata does: nil values
+// delete keys.
+func mergePatch(meta, patch map[string]any) {
+	for k, v := range patch {
+		if v == nil {
+			delete(meta, k)
+		} else {
+			meta[k] = v
+		}
+	}
+}
+
+// formatDue renders a deadline as a due date: a calendar date when
+// dateOnly (the deadline being the end of that day), RFC3339 otherwise.
+func formatDue(deadline time.Time, dateOnly bool) string {
+	if dateOnly {
+		return deadline.UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+	return deadline.UTC().Format(time.RFC3339)
+}
+
+// nextOccurrence creates the task that follows completed recurring task f.
+// Occurrences stay anchored to the schedule: the next deadline is the first
+// step after the completed one that is still in the future, so a task
+// finished late does not shift the series and one finished early does not
+// repeat. Dependencies are not carried over; tags are.
+func nextOccurrence(ctx context.Context, store Store, f Fact, meta map[string]any, now time.Time) (*Task, error) {
+	recur, _ := meta["recur"].(string)
+	r, err := ParseRecurrence(recur)
+	if err != nil {
+		return nil, err
+	}
+	due, _ := meta["due"].(string)
+	dateOnly := r.wholeDays()
+	base := now
+	if deadline, err := ParseDue(due); err == nil {
+		base = deadline
+		_, err := time.Parse(time.DateOnly, strings.TrimSpace(due))
+		dateOnly = dateOnly && err == nil
+	}
+	if r.cron != nil && base.Before(now) {
+		base = now
+	}
+	next := r.Next(base)
+	for !next.After(now) {
+		next = r.Next(next)
+	}
+
+	newMeta := maps.Clone(meta)
+	for _, k := range []string{"note", "surface", "remind_at", "reminded_at"} {
+		delete(newMeta, k)
+	}
+	newMeta["status"] = TaskPending
+	newMeta["due"] = formatDue(next, dateOnly)
+	newMeta["previous_occurrence"] = f.ID
+	if err := ScheduleTask(newMeta, now); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(newMeta)
+	if err != nil {
+		return nil, err
+	}
+	id, err := store.Insert(ctx, Fact{
+		Content:   f.Content,
+		Subject:   f.Subject,
+		Category:  f.Category,
+		Kind:      f.Kind,
+		Metadata:  raw,
+		Embedding: f.Embedding,
+		Tags:      f.Tags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("memstore: creating next occurrence of task %d: %w", f.ID, err)
+	}
+	return getTask(ctx, store, id)
+}
+
+// EvaluateReminders surfaces the open deferred tasks whose reminder time
+// has passed and returns them, in display order. Each is surfaced once.
+func EvaluateReminders(ctx context.Context, store Store) ([]Task, error) {
+	return evaluateReminders(ctx, store, time.Now())
+}
+
+func evaluateReminders(ctx context.Context, store Store, now time.Time) ([]Task, error) {
+	facts, err := store.List(ctx, QueryOpts{
+		OnlyActive:      true,
+		MetadataFilters: []MetadataFilter{{Key: "kind", Op: "=", Value: "task"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var due []Task
+	for _, f := range facts {
+		t, ok := taskFromFact(f)
+		if !ok || taskClosed(t.Status) || t.RemindAt == nil || now.Before(*t.RemindAt) {
+			continue
+		}
+		var meta map[string]any
+		json.Unmarshal(f.Metadata, &meta) //nolint:errcheck // taskFromFact already decoded it
+		if _, reminded := meta["reminded_at"]; reminded {
+			continue
+		}
+		if err := store.UpdateMetadata(ctx, f.ID, map[string]any{
+			"surface":     "startup",
+			"reminded_at": now.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return nil, err
+		}
+		t.Surface = "startup"
+		due = append(due, t)
+	}
+	if err := resolveTaskStates(ctx, store, now, due); err != nil {
+		return nil, err
+	}
+	sortTasks(due)
+	return due, nil
+}
+
+// ReminderPolicy configures the background reminder evaluator.
+type ReminderPolicy struct {
+	Interval time.Duration // 0 = DefaultReminderInterval, <0 = never
+}
+
+// Enabled reports whether the evaluator runs at all.
+func (p ReminderPolicy) Enabled() bool { return p.Interval >= 0 }
+
+// ReminderPolicyFromEnv reads a ReminderPolicy from {prefix}_INTERVAL (a Go
+// duration, or "off" to leave deferred tasks unsurfaced). Unset keeps the
+// default.
+func ReminderPolicyFromEnv(prefix string) (ReminderPolicy, error) {
+	var pol ReminderPolicy
+	if v := os.Getenv(prefix + "_INTERVAL"); v != "" {
+		if strings.EqualFold(v, "off") {
+			pol.Interval = -1
+			return pol, nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return ReminderPolicy{}, fmt.Errorf("memstore: invalid %s_INTERVAL %q: must be a positive duration, or \"off\"", prefix, v)
+		}
+		pol.Interval = d
+	}
+	return pol, nil
+}
+
+// ReminderEvaluator surfaces due reminders on a timer.
+type ReminderEvaluator struct {
+	store  Store
+	policy ReminderPolicy
+	logf   func(format string, args ...any)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewReminderEvaluator creates a background reminder evaluator. logf
+// receives one line per evaluation that surfaced anything and one per
+// error; nil discards them.
+func NewReminderEvaluator(store Store, policy ReminderPolicy, logf func(format string, args ...any)) *ReminderEvaluator {
+	if policy.Interval == 0 {
+		policy.Interval = DefaultReminderInterval
+	}
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	return &ReminderEvaluator{store: store, policy: policy, logf: logf, done: make(chan struct{})}
+}
+
+// Start evaluates once immediately, then every interval.
+func (re *ReminderEvaluator) Start() {
+	re.wg.Add(1)
+	go re.loop()
+}
+
+// Stop signals the loop to stop and waits for it to finish.
+func (re *ReminderEvaluator) Stop() {
+	close(re.done)
+	re.wg.Wait()
+}
+
+func (re *ReminderEvaluator) loop() {
+	defer re.wg.Done()
+	if !re.policy.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(re.policy.Interval)
+	defer ticker.Stop()
+
+	re.EvaluateOnce(context.Background())
+	for {
+		select {
+		case <-re.done:
+			return
+		case <-ticker.C:
+			re.EvaluateOnce(context.Background())
+		}
+	}
+}
+
+// EvaluateOnce surfaces the reminders due by now. Called from the
+// background loop and exposed for tests.
+func (re *ReminderEvaluator) EvaluateOnce(ctx context.Context) ([]Task, error) {
+	if !re.policy.Enabled() {
+		return nil, nil
+	}
+	due, err := EvaluateReminders(ctx, re.store)
+	if err != nil {
+		re.logf("reminders: %v", err)
+		return nil, err
+	}
+	if len(due) > 0 {
+		ids := make([]int64, len(due))
+		for i, t := range due {
+			ids[i] = t.Fact.ID
+		}
+		re.logf("reminders: surfaced %d due tasks: %v", len(due), ids)
+	}
+	return due, nil
+}
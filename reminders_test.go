@@ -0,0 +1,142 @@
+package memstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestScheduleTask(t *testing.T) {
+	now := time.Date(2026, 5, 3, 10, 30, 0, 0, time.UTC)
+
+	// Recurring without a due date: first due one step out, held back
+	// until the start of its due day.
+	meta := map[string]any{"kind": "task", "status": "pending", "surface": "startup", "recur": "every 90d"}
+	if err := memstore.ScheduleTask(meta, now); err != nil {
+		t.Fatal(err)
+	}
+	if meta["due"] != "2026-08-01" || meta["remind_at"] != "2026-08-01T00:00:00Z" || meta["surface"] != nil {
+		t.Errorf("scheduled metadata = %v", meta)
+	}
+
+	// A reminder already due surfaces straight away.
+	meta = map[string]any{"kind": "task", "status": "pending", "surface": "startup", "due": "2026-05-05", "remind": "3d"}
+	if err := memstore.ScheduleTask(meta, now); err != nil {
+		t.Fatal(err)
+	}
+	if meta["surface"] != "startup" || meta["reminded_at"] == nil {
+		t.Errorf("due reminder metadata = %v, want it surfaced", meta)
+	}
+
+	// Plain tasks are untouched; a lead without a due date is an error.
+	meta = map[string]any{"kind": "task", "status": "pending", "surface": "startup"}
+	if err := memstore.ScheduleTask(meta, now); err != nil || len(meta) != 3 {
+		t.Errorf("plain task metadata = %v, %v", meta, err)
+	}
+	if err := memstore.ScheduleTask(map[string]any{"remind": "2d"}, now); err == nil {
+		t.Error("reminder lead without a due date accepted")
+	}
+	if err := memstore.ScheduleTask(map[string]any{"recur": "sometimes"}, now); err == nil {
+		t.Error("invalid recurrence accepted")
+	}
+}
+
+func TestEvaluateReminders(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	future := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	due := insertTestTask(t, s, "rotate certs", map[string]any{"recur": "every 90d", "due": "2099-01-01", "remind_at": past})
+	insertTestTask(t, s, "later", map[string]any{"recur": "weekly", "due": "2099-01-01", "remind_at": future})
+	insertTestTask(t, s, "snoozed", map[string]any{"recur": "weekly", "due": "2099-01-01", "remind_at": past, "reminded_at": past})
+	insertTestTask(t, s, "finished", map[string]any{"recur": "weekly", "due": "2099-01-01", "remind_at": past, "status": "completed"})
+
+	ev := memstore.NewReminderEvaluator(s, memstore.ReminderPolicy{}, nil)
+	surfaced, err := ev.EvaluateOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(surfaced) != 1 || surfaced[0].Fact.ID != due || surfaced[0].Surface != "startup" {
+		t.Fatalf("surfaced %+v, want only the due task", surfaced)
+	}
+	f, _ := s.Get(ctx, due)
+	var meta map[string]any
+	json.Unmarshal(f.Metadata, &meta)
+	if meta["surface"] != "startup" || meta["reminded_at"] == nil {
+		t.Errorf("metadata after evaluation = %v", meta)
+	}
+	if again, _ := ev.EvaluateOnce(ctx); len(again) != 0 {
+		t.Errorf("second evaluation surfaced %+v, want nothing", again)
+	}
+
+	off := memstore.NewReminderEvaluator(s, memstore.ReminderPolicy{Interval: -1}, nil)
+	if got, err := off.EvaluateOnce(ctx); got != nil || err != nil {
+		t.Errorf("disabled evaluator = %v, %v", got, err)
+	}
+}
+
+func TestUpdateTask_Recurring(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	// Ten days overdue on a weekly schedule: the next occurrence skips the
+	// missed week and lands on the first scheduled date still ahead.
+	lastDue := time.Now().UTC().AddDate(0, 0, -10).Format(time.DateOnly)
+	id := insertTestTask(t, s, "re-run backfill", map[string]any{"recur": "weekly", "due": lastDue, "project": "memstore"})
+	if err := s.TagFact(ctx, id, "ops"); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := memstore.UpdateTask(ctx, s, id, memstore.TaskUpdate{Status: memstore.TaskCompleted})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	next := res.Next
+	if next == nil {
+		t.Fatal("completing a recurring task created no next occurrence")
+	}
+	last, _ := time.Parse(time.DateOnly, lastDue)
+	if want := last.AddDate(0, 0, 14).Format(time.DateOnly); next.Due != want {
+		t.Errorf("next due = %q, want %q", next.Due, want)
+	}
+	if next.Status != memstore.TaskPending || next.Recur != "weekly" || next.Project != "memstore" || next.RemindAt == nil {
+		t.Errorf("next occurrence = %+v", next)
+	}
+	if !slices.Equal(next.Fact.Tags, []string{"ops"}) {
+		t.Errorf("next occurrence tags = %v, want [ops]", next.Fact.Tags)
+	}
+	var meta map[string]any
+	json.Unmarshal(next.Fact.Metadata, &meta)
+	if meta["previous_occurrence"] != float64(id) {
+		t.Errorf("previous_occurrence = %v, want %d", meta["previous_occurrence"], id)
+	}
+
+	// Cancelling ends the series.
+	res, err = memstore.UpdateTask(ctx, s, next.Fact.ID, memstore.TaskUpdate{Status: memstore.TaskCancelled})
+	if err != nil || res.Next != nil {
+		t.Errorf("cancel = %+v, %v; want no next occurrence", res, err)
+	}
+
+	// Moving a due date reschedules the reminder; clearing recur and remind
+	// turns the task back into a plain one that surfaces at once.
+	plain := insertTestTask(t, s, "renew domain", map[string]any{"due": "2099-01-01", "remind": "7d", "remind_at": "2098-12-25T00:00:00Z"})
+	if _, err := memstore.UpdateTask(ctx, s, plain, memstore.TaskUpdate{Due: "2099-02-01"}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := memstore.LoadTasks(ctx, s, memstore.MetadataFilter{Key: "due", Op: "=", Value: "2099-02-01"})
+	if len(tasks) != 1 || tasks[0].RemindAt == nil || !tasks[0].RemindAt.Equal(time.Date(2099, 1, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("rescheduled task = %+v, want reminder at 2099-01-26", tasks)
+	}
+	if _, err := memstore.UpdateTask(ctx, s, plain, memstore.TaskUpdate{Remind: "none"}); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := s.Get(ctx, plain)
+	meta = nil
+	json.Unmarshal(f.Metadata, &meta)
+	if meta["remind_at"] != nil || meta["surface"] != "startup" {
+		t.Errorf("metadata after clearing the reminder = %v", meta)
+	}
+}
@@ -0,0 +1,175 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Review queue defaults, used when the matching ReviewOpts field is zero.
+const (
+	// DefaultReviewMinAge is how long a fact goes unconfirmed (counting from
+	// creation when it never was) before it is due for review.
+	DefaultReviewMinAge = 30 * 24 * time.Hour
+	DefaultReviewLimit  = 10
+)
+
+// reviewKindWeight boosts the kinds whose staleness does the most damage:
+// an outdated invariant or convention is injected as a rule, not a hint.
+var reviewKindWeight = map[string]float64{
+	"invariant":  3,
+	"convention": 3,
+}
+
+// ReviewOpts selects and sizes a staleness review batch.
+type ReviewOpts struct {
+	Limit   int           // batch size; 0 = DefaultReviewLimit
+	MinAge  time.Duration // minimum time since last confirmation; 0 = DefaultReviewMinAge
+	Subject string        // restrict to one subject (empty = all)
+}
+
+func (o ReviewOpts) withDefaults() ReviewOpts {
+	if o.Limit <= 0 {
+		o.Limit = DefaultReviewLimit
+	}
+	if o.MinAge <= 0 {
+		o.MinAge = DefaultReviewMinAge
+	}
+	return o
+}
+
+// ReviewItem is one fact due for re-confirmation.
+type ReviewItem struct {
+	Fact     Fact         // embedding stripped
+	Priority float64      // higher = review sooner
+	Since    time.Time    // last confirmation, or creation if never confirmed
+	Feedback FeedbackStat // zero when the fact has no ratings or no feedback source
+	Reasons  []string     // human-readable factors behind Priority
+
+	StaleCitations int // citations whose code changed or vanished (see Citer)
+}
+
+// Reviewer is implemented by stores that can build a staleness review queue.
+// Both built-in backends and the HTTP client implement it.
+type Reviewer interface {
+	// ReviewQueue returns active facts unconfirmed for at least opts.MinAge,
+	// highest priority first, at most opts.Limit. Priority grows with time
+	// since the last confirmation, and is boosted for invariants and
+	// conventions, for facts recall uses often, and for facts with negative
+	// feedback. Confirm, supersede or delete a fact to take it off the queue.
+	//
+	// On stores that implement Citer, a fact with stale citations is queued
+	// whatever its age, with a strong priority boost; it stays until its
+	// stale citations are accepted or deleted.
+	ReviewQueue(ctx context.Context, opts ReviewOpts) ([]ReviewItem, error)
+}
+
+// RankReview scores stale candidate facts and returns the top opts.Limit,
+// highest priority first. Feedback comes from stage when it is non-nil; a
+// failing feedback source ranks without it, as search does.
+func RankReview(ctx context.Context, facts []Fact, stage *FeedbackStage, opts ReviewOpts, now time.Time) []ReviewItem {
+	return RankReviewCited(ctx, facts, nil, stage, opts, now)
+}
+
+// staleCitationWeight is the priority multiplier for a fact whose cited code
+// changed: the evidence it was checked against is known to be gone, which is
+// a stronger signal than age alone.
+const staleCitationWeight = 4
+
+// RankReviewCited is RankReview for stores that track citations. stale maps
+// fact IDs to their number of stale citations; those facts may be younger
+// than opts.MinAge.
+func RankReviewCited(ctx context.Context, facts []Fact, stale map[int64]int, stage *FeedbackStage, opts ReviewOpts, now time.Time) []ReviewItem {
+	opts = opts.withDefaults()
+	var stats map[string]FeedbackStat
+	if stage != nil && stage.Scorer != nil && len(facts) > 0 {
+		ids := make([]string, len(facts))
+		for i, f := range facts {
+			ids[i] = strconv.FormatInt(f.ID, 10)
+		}
+		stats, _ = FetchFeedback(ctx, stage.Scorer, ids, RefTypeFact, stage.Policy)
+	}
+
+	items := make([]ReviewItem, 0, len(facts))
+	for _, f := range facts {
+		f.Embedding = nil
+		it := ReviewItem{Fact: f, Since: f.CreatedAt, Feedback: stats[strconv.FormatInt(f.ID, 10)]}
+		if f.LastConfirmedAt != nil {
+			it.Since = *f.LastConfirmedAt
+		}
+		age := now.Sub(it.Since)
+		days := int(age / (24 * time.Hour))
+
+		// Staleness: 1 at MinAge, +1 per doubling beyond it.
+		p := 1 + math.Log2(math.Max(float64(age)/float64(opts.MinAge), 1))
+		if f.ConfirmedCount == 0 {
+			p *= 1.25
+			it.Reasons = append(it.Reasons, fmt.Sprintf("never confirmed (%dd old)", days))
+		} else {
+			it.Reasons = append(it.Reasons, fmt.Sprintf("last confirmed %dd ago", days))
+		}
+		if w, ok := reviewKindWeight[f.Kind]; ok {
+			p *= w
+			it.Reasons = append(it.Reasons, "kind "+f.Kind)
+		}
+		if f.UseCount > 0 {
+			p *= 1 + math.Log2(1+float64(f.UseCount))/4
+			it.Reasons = append(it.Reasons, fmt.Sprintf("used %d times", f.UseCount))
+		}
+		if fb := it.Feedback; fb.Avg < 0 && fb.weight() > 0 {
+			p *= 1 + 2*(-fb.Avg)*math.Min(fb.weight(), 1)
+			it.Reasons = append(it.Reasons, fmt.Sprintf("negative feedback (avg %.2f over %d ratings)", fb.Avg, fb.Count))
+		}
+		if n := stale[f.ID]; n > 0 {
+			it.StaleCitations = n
+			p *= staleCitationWeight
+			it.Reasons = append(it.Reasons, fmt.Sprintf("cited code changed (%d citations)", n))
+		}
+		it.Priority = p
+		items = append(items, it)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority > items[j].Priority
+		}
+		return items[i].Since.Before(items[j].Since)
+	})
+	if len(items) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+	return items
+}
+
+// ReviewQueue implements Reviewer.
+func (s *SQLiteStore) ReviewQueue(ctx context.Context, opts ReviewOpts) ([]ReviewItem, error) {
+	opts = opts.withDefaults()
+	now := time.Now()
+
+	s.mu.RLock()
+	q := `SELECT ` + factColumns + ` FROM memstore_facts
+	      WHERE namespace = ? AND superseded_by IS NULL` + notDeleted("") + `
+	        AND COALESCE(last_confirmed_at, created_at) <= ?`
+	args := []any{s.namespace, now.Add(-opts.MinAge).UTC().Format(time.RFC3339)}
+	appendUnexpiredFilter(&q, &args, "")
+	if opts.Subject != "" {
+		q += ` AND subject = ?`
+		args = append(args, opts.Subject)
+	}
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		s.mu.RUnlock()
+		return nil, fmt.Errorf("memstore: querying review candidates: %w", err)
+	}
+	facts, err := scanFacts(rows)
+	rows.Close()
+	stage := s.feedback
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return RankReview(ctx, facts, stage, opts, now), nil
+}
@@ -0,0 +1,100 @@
+package memstore_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// staticScorer returns fixed feedback stats by fact ID.
+type staticScorer map[string]memstore.FeedbackStat
+
+func (s staticScorer) FeedbackScores(_ context.Context, refIDs []string, _ string) (map[string]memstore.FeedbackStat, error) {
+	out := make(map[string]memstore.FeedbackStat)
+	for _, id := range refIDs {
+		if st, ok := s[id]; ok {
+			out[id] = st
+		}
+	}
+	return out, nil
+}
+
+func TestRankReview(t *testing.T) {
+	now := time.Now()
+	ago := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	confirmed := ago(40)
+	facts := []memstore.Fact{
+		{ID: 1, Kind: "", CreatedAt: ago(200), ConfirmedCount: 1, LastConfirmedAt: &confirmed, Embedding: []float32{1}},
+		{ID: 2, Kind: "invariant", CreatedAt: ago(45)},
+		{ID: 3, Kind: "", CreatedAt: ago(45), UseCount: 40},
+		{ID: 4, Kind: "", CreatedAt: ago(45)},
+		{ID: 5, Kind: "", CreatedAt: ago(45)},
+	}
+	stage := &memstore.FeedbackStage{
+		Scorer: staticScorer{"5": {Avg: -0.8, Count: 3}},
+		Policy: memstore.FeedbackPolicy{HalfLife: -1},
+	}
+
+	items := memstore.RankReview(context.Background(), facts, stage, memstore.ReviewOpts{Limit: 10}, now)
+	var order []int64
+	for _, it := range items {
+		order = append(order, it.Fact.ID)
+	}
+	// The invariant leads, then negative feedback, heavy use, the plain
+	// never-confirmed fact, and last the fact confirmed 40 days ago.
+	want := []int64{2, 5, 3, 4, 1}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if items[4].Fact.Embedding != nil {
+		t.Error("review item kept its embedding")
+	}
+	if !items[4].Since.Equal(confirmed) || !strings.Contains(items[4].Reasons[0], "last confirmed 40d ago") {
+		t.Errorf("confirmed fact: since %v, reasons %v", items[4].Since, items[4].Reasons)
+	}
+	if r := strings.Join(items[1].Reasons, ", "); !strings.Contains(r, "negative feedback") || items[1].Feedback.Avg != -0.8 {
+		t.Errorf("feedback fact reasons = %q, feedback %+v", r, items[1].Feedback)
+	}
+
+	if got := memstore.RankReview(context.Background(), facts, nil, memstore.ReviewOpts{Limit: 2}, now); len(got) != 2 || got[0].Fact.ID != 2 {
+		t.Errorf("limit 2 without feedback = %+v", got)
+	}
+}
+
+func TestSQLiteReviewQueue(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	stale, _ := store.Insert(ctx, memstore.Fact{Content: "builds use make", Subject: "ci", Category: "project", Kind: "convention", CreatedAt: old})
+	plain, _ := store.Insert(ctx, memstore.Fact{Content: "the CI runner is named gort", Subject: "ci", Category: "project", CreatedAt: old})
+	store.Insert(ctx, memstore.Fact{Content: "fresh fact", Subject: "ci", Category: "project"})
+	other, _ := store.Insert(ctx, memstore.Fact{Content: "tabs not spaces", Subject: "style", Category: "project", CreatedAt: old})
+
+	items, err := store.ReviewQueue(ctx, memstore.ReviewOpts{})
+	if err != nil {
+		t.Fatalf("ReviewQueue: %v", err)
+	}
+	if len(items) != 3 || items[0].Fact.ID != stale {
+		t.Fatalf("queue = %+v, want the three old facts, convention first", items)
+	}
+
+	if err := store.Confirm(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	items, err = store.ReviewQueue(ctx, memstore.ReviewOpts{Subject: "ci"})
+	if err != nil || len(items) != 1 || items[0].Fact.ID != plain {
+		t.Errorf("after confirm and delete = %+v, %v; want only %d", items, err, plain)
+	}
+	if items, _ := store.ReviewQueue(ctx, memstore.ReviewOpts{MinAge: 90 * 24 * time.Hour}); len(items) != 0 {
+		t.Errorf("min age 90d = %+v, want none", items)
+	}
+}
@@ -0,0 +1,306 @@
+package memstore
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// A fact's Kind implies the shape of its Metadata: a trigger only fires if it
+// names a signal_type and a signal pattern, a task needs a status. A
+// SchemaRegistry maps kinds to JSON Schemas (draft 2020-12) that the stores
+// check metadata against on every write that sets it. Kinds without a schema
+// are not checked, so free-form kinds keep working.
+
+// builtinSchemas are the schemas DefaultSchemas registers. They constrain
+// the keys memstore itself reads and leave any other key alone; only task
+// and trigger require anything.
+var builtinSchemas = map[string]string{
+	"convention": `{"type": "object"}`,
+	"invariant":  `{"type": "object"}`,
+	"pattern":    `{"type": "object"}`,
+	"decision":   `{"type": "object"}`,
+	// memstore reads no failure_mode key, and failure modes are mostly
+	// written as prose, so this schema only checks types: symptom, cause
+	// and fix are optional, and {} or no metadata at all is valid.
+	"failure_mode": `{
+		"type": "object",
+		"properties": {
+			"symptom": {"type": "string", "minLength": 1},
+			"cause":   {"type": "string", "minLength": 1},
+			"fix":     {"type": "string", "minLength": 1}
+		}
+	}`,
+	// A trigger without metadata is matched on its content by get_context;
+	// once it names a signal it must be complete enough to fire.
+	"trigger": `{
+		"type": "object",
+		"properties": {
+			"signal_type":    {"type": "string", "enum": ["file_pattern", "cwd_pattern"]},
+			"signal":         {"type": "string", "minLength": 1},
+			"load_subsystem": {"type": "string"},
+			"load_subject":   {"type": "string"},
+			"load_kinds":     {"type": "array", "items": {"type": "string"}}
+		},
+		"dependentRequired": {
+			"signal_type": ["signal"],
+			"signal":      ["signal_type"]
+		},
+		"dependentSchemas": {
+			"signal_type": {
+				"anyOf": [{"required": ["load_subsystem"]}, {"required": ["load_subject"]}]
+			}
+		}
+	}`,
+	"task": `{
+		"type": "object",
+		"required": ["status"],
+		"properties": {
+			"kind":      {"const": "task"},
+			"status":    {"enum": ["pending", "in_progress", "completed", "cancelled"]},
+			"priority":  {"enum": ["high", "normal", "low"]},
+			"scope":     {"type": "string"},
+			"project":   {"type": "string"},
+			"owner":     {"type": "string"},
+			"due":       {"type": "string"},
+			"recur":     {"type": "string"},
+			"remind":    {"type": "string"},
+			"remind_at": {"type": "string"},
+			"surface":   {"type": "string"}
+		}
+	}`,
+}
+
+// SchemaError reports fact metadata that does not match its kind's schema.
+type SchemaError struct {
+	FactID int64           // the fact written; 0 for a fact not yet inserted
+	Kind   string          // the fact's kind
+	Reason string          // the first violation, with its location in the schema
+	Schema json.RawMessage // the kind's schema, for callers that show it
+}
+
+func (e *SchemaError) Error() string {
+	if e.FactID > 0 {
+		return fmt.Sprintf("memstore: fact %d: metadata does not match the %q schema: %s", e.FactID, e.Kind, e.Reason)
+	}
+	return fmt.Sprintf("memstore: metadata does not match the %q schema: %s", e.Kind, e.Reason)
+}
+
+// kindSchema is a registered schema, kept in source form for display and
+// resolved for validation.
+type kindSchema struct {
+	raw      json.RawMessage
+	resolved *jsonschema.Resolved
+}
+
+// SchemaRegistry holds the metadata schema for each kind. A nil registry
+// validates nothing. A registry is not safe for concurrent modification;
+// build it at startup and then share it.
+type SchemaRegistry struct {
+	schemas map[string]kindSchema
+}
+
+// NewSchemaRegistry returns an empty registry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]kindSchema)}
+}
+
+// DefaultSchemas returns a registry holding the built-in schemas for
+// convention, failure_mode, invariant, pattern, decision, trigger and task.
+func DefaultSchemas() *SchemaRegistry {
+	r := NewSchemaRegistry()
+	for kind, raw := range builtinSchemas {
+		if err := r.Register(kind, json.RawMessage(raw)); err != nil {
+			panic(fmt.Sprintf("memstore: built-in %q schema: %v", kind, err))
+		}
+	}
+	return r
+}
+
+// Register sets the schema for kind, replacing any existing one. The schema
+// must be a JSON Schema object that resolves on its own: references to
+// other documents are not followed.
+func (r *SchemaRegistry) Register(kind string, schema json.RawMessage) error {
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		return fmt.Errorf("memstore: schema: kind is required")
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("memstore: schema for kind %q: %w", kind, err)
+	}
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("memstore: schema for kind %q: %w", kind, err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema); err != nil {
+		return fmt.Errorf("memstore: schema for kind %q: %w", kind, err)
+	}
+	r.schemas[kind] = kindSchema{raw: compact.Bytes(), resolved: resolved}
+	return nil
+}
+
+// Unregister removes the schema for kind, so its metadata is no longer
+// checked.
+func (r *SchemaRegistry) Unregister(kind string) {
+	delete(r.schemas, kind)
+}
+
+// Kinds returns the kinds with a schema, sorted.
+func (r *SchemaRegistry) Kinds() []string {
+	if r == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(r.schemas))
+}
+
+// Schema returns the schema registered for kind, or nil if there is none.
+func (r *SchemaRegistry) Schema(kind string) json.RawMessage {
+	if r == nil {
+		return nil
+	}
+	return r.schemas[kind].raw
+}
+
+// Validate checks metadata against the schema for kind and returns a
+// *SchemaError if it does not match. Absent metadata is checked as an empty
+// object, so a schema with required keys rejects it. A kind with no schema,
+// and a nil registry, accept anything.
+func (r *SchemaRegistry) Validate(kind string, metadata json.RawMessage) error {
+	if r == nil {
+		return nil
+	}
+	ks, ok := r.schemas[kind]
+	if !ok {
+		return nil
+	}
+	var instance any = map[string]any{}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &instance); err != nil {
+			return &SchemaError{Kind: kind, Reason: "metadata is not valid JSON: " + err.Error(), Schema: ks.raw}
+		}
+	}
+	if err := ks.resolved.Validate(instance); err != nil {
+		return &SchemaError{Kind: kind, Reason: schemaReason(err), Schema: ks.raw}
+	}
+	return nil
+}
+
+// ValidateFact is Validate for a fact's kind and metadata; the error names
+// the fact when it has an ID.
+func (r *SchemaRegistry) ValidateFact(f Fact) error {
+	err := r.Validate(f.Kind, f.Metadata)
+	if se, ok := err.(*SchemaError); ok {
+		se.FactID = f.ID
+	}
+	return err
+}
+
+// ValidateBulkChange checks the metadata of a fact after a bulk change
+// against the schema of its kind after the change. A change that sets
+// neither kind nor metadata is not checked: re-filing a legacy fact under a
+// new subject must not fail on metadata the change does not touch.
+func (r *SchemaRegistry) ValidateBulkChange(id int64, kind string, metadata json.RawMessage, c BulkChange) error {
+	if c.Kind == nil && len(c.Metadata) == 0 {
+		return nil
+	}
+	if c.Kind != nil {
+		kind = *c.Kind
+	}
+	return r.ValidateFact(Fact{ID: id, Kind: kind, Metadata: metadata})
+}
+
+// schemaReason trims a jsonschema validation error to its innermost
+// location. The library wraps the error once per schema level it descends
+// through ("validating root: validating /properties/x: ..."); only the last
+// path says where the problem is.
+func schemaReason(err error) string {
+	msg := strings.TrimPrefix(err.Error(), "validating root: ")
+	for {
+		rest, ok := strings.CutPrefix(msg, "validating ")
+		if !ok {
+			return msg
+		}
+		_, tail, ok := strings.Cut(rest, ": ")
+		if !ok || !strings.HasPrefix(tail, "validating ") {
+			return msg
+		}
+		msg = tail
+	}
+}
+
+// LoadSchemas returns the built-in schemas overlaid with the JSON object in
+// path, which maps each kind to its schema. A kind's schema replaces the
+// built-in one; null removes it. An empty path returns the built-ins.
+func LoadSchemas(path string) (*SchemaRegistry, error) {
+	r := DefaultSchemas()
+	if path == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: schemas: %w", err)
+	}
+	var defs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("memstore: schemas: parse %s: %w", path, err)
+	}
+	for _, kind := range slices.Sorted(maps.Keys(defs)) {
+		if string(defs[kind]) == "null" {
+			r.Unregister(kind)
+			continue
+		}
+		if err := r.Register(kind, defs[kind]); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// SchemaViolation is an existing fact whose metadata fails its kind's
+// schema, as reported by AuditSchemas.
+type SchemaViolation struct {
+	FactID  int64  `json:"fact_id"`
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Content string `json:"content"`
+	Reason  string `json:"reason"`
+}
+
+// AuditSchemas checks the active facts of every kind in r (or only kind,
+// when it is non-empty) against their schemas and returns the failures,
+// ordered by kind and then ID. Facts written before a schema existed or
+// changed are the usual finds.
+func AuditSchemas(ctx context.Context, store Store, r *SchemaRegistry, kind string) ([]SchemaViolation, error) {
+	kinds := r.Kinds()
+	if kind != "" {
+		if r.Schema(kind) == nil {
+			return nil, fmt.Errorf("memstore: no schema is registered for kind %q", kind)
+		}
+		kinds = []string{kind}
+	}
+	var out []SchemaViolation
+	for _, k := range kinds {
+		facts, err := store.List(ctx, QueryOpts{Kind: k, OnlyActive: true})
+		if err != nil {
+			return nil, fmt.Errorf("memstore: listing %s facts: %w", k, err)
+		}
+		slices.SortFunc(facts, func(a, b Fact) int { return cmp.Compare(a.ID, b.ID) })
+		for _, f := range facts {
+			if err := r.ValidateFact(f); err != nil {
+				se := err.(*SchemaError)
+				out = append(out, SchemaViolation{FactID: f.ID, Kind: k, Subject: f.Subject, Content: f.Content, Reason: se.Reason})
+			}
+		}
+	}
+	return out, nil
+}
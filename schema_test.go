@@ -0,0 +1,144 @@
+package memstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestSchemaRegistryValidate(t *testing.T) {
+	r := memstore.DefaultSchemas()
+	for _, tc := range []struct {
+		kind, meta string
+		want       string // substring of the reason; "" = valid
+	}{
+		{"trigger", ``, ""},
+		{"trigger", `{"signal_type":"file_pattern","signal":"*.go","load_kinds":["invariant"],"load_subsystem":"api"}`, ""},
+		{"trigger", `{"signal_type":"cwd","signal":"**","load_subject":"x"}`, "/properties/signal_type: enum"},
+		{"trigger", `{"signal_type":"cwd_pattern","load_subject":"x"}`, `missing properties ["signal"]`},
+		{"trigger", `{"signal_type":"cwd_pattern","signal":"**"}`, "load_subsystem"},
+		{"trigger", `{"signal_type":"cwd_pattern","signal":"**","load_subject":"x","load_kinds":[1]}`, `/properties/load_kinds/items: type`},
+		{"failure_mode", `{"symptom":"timeouts","fix":""}`, "/properties/fix: minLength"},
+		{"failure_mode", ``, ""},
+		{"failure_mode", `{}`, ""},
+		{"task", `{"kind":"task","status":"pending","priority":"high"}`, ""},
+		{"task", `{"kind":"task"}`, `missing properties: ["status"]`},
+		{"task", `{"status":"done"}`, "/properties/status: enum"},
+		{"convention", `[1,2]`, `want "object"`},
+		{"runbook", `[1,2]`, ""},
+	} {
+		err := r.Validate(tc.kind, json.RawMessage(tc.meta))
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("Validate(%s, %s) = %v, want nil", tc.kind, tc.meta, err)
+			}
+			continue
+		}
+		var se *memstore.SchemaError
+		if !errors.As(err, &se) || !strings.Contains(se.Reason, tc.want) {
+			t.Errorf("Validate(%s, %s) = %v, want a SchemaError mentioning %q", tc.kind, tc.meta, err, tc.want)
+			continue
+		}
+		if strings.HasPrefix(se.Reason, "validating root") || len(se.Schema) == 0 {
+			t.Errorf("Validate(%s, %s): reason %q should be trimmed and the schema attached", tc.kind, tc.meta, se.Reason)
+		}
+	}
+
+	var nilRegistry *memstore.SchemaRegistry
+	if err := nilRegistry.Validate("task", nil); err != nil {
+		t.Errorf("nil registry Validate = %v, want nil", err)
+	}
+}
+
+func TestLoadSchemas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemas.json")
+	defs := `{
+		"runbook": {"type": "object", "required": ["steps"], "properties": {"steps": {"type": "array", "minItems": 1}}},
+		"task": null
+	}`
+	if err := os.WriteFile(path, []byte(defs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := memstore.LoadSchemas(path)
+	if err != nil {
+		t.Fatalf("LoadSchemas: %v", err)
+	}
+	if r.Schema("task") != nil || r.Schema("trigger") == nil || r.Schema("runbook") == nil {
+		t.Errorf("kinds = %v, want the built-ins without task, plus runbook", r.Kinds())
+	}
+	if err := r.Validate("runbook", json.RawMessage(`{"steps":[]}`)); err == nil {
+		t.Error("runbook with no steps validated")
+	}
+	if err := r.Validate("task", json.RawMessage(`{}`)); err != nil {
+		t.Errorf("task with its schema removed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"runbook": {"type": 7}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memstore.LoadSchemas(path); err == nil || !strings.Contains(err.Error(), "runbook") {
+		t.Errorf("LoadSchemas with a broken schema: err = %v, want one naming the kind", err)
+	}
+}
+
+func TestAuditSchemas(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	// Facts written before schemas were enforced.
+	s.SetSchemas(nil)
+	insert := func(content, kind, meta string) int64 {
+		t.Helper()
+		id, err := s.Insert(ctx, memstore.Fact{Content: content, Subject: "audit", Category: "note", Kind: kind, Metadata: json.RawMessage(meta)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	badTrigger := insert("load api rules", "trigger", `{"signal_type":"file_pattern","pattern":"api/**"}`)
+	insert("load web rules", "trigger", `{"signal_type":"cwd_pattern","signal":"**/web/**","load_subsystem":"web"}`)
+	badTask := insert("ship it", "task", `{"kind":"task","status":"done"}`)
+	insert("free-form", "runbook", `{"x":1}`)
+	s.SetSchemas(memstore.DefaultSchemas())
+
+	got, err := memstore.AuditSchemas(ctx, s, memstore.DefaultSchemas(), "")
+	if err != nil {
+		t.Fatalf("AuditSchemas: %v", err)
+	}
+	if len(got) != 2 || got[0].FactID != badTask || got[1].FactID != badTrigger {
+		t.Fatalf("AuditSchemas = %+v, want the task then the trigger", got)
+	}
+	if !strings.Contains(got[1].Reason, `["signal"]`) {
+		t.Errorf("trigger reason = %q, want it to name the missing signal", got[1].Reason)
+	}
+
+	got, err = memstore.AuditSchemas(ctx, s, memstore.DefaultSchemas(), "trigger")
+	if err != nil || len(got) != 1 || got[0].FactID != badTrigger {
+		t.Errorf("AuditSchemas(trigger) = %+v, %v; want only the trigger", got, err)
+	}
+	if _, err := memstore.AuditSchemas(ctx, s, memstore.DefaultSchemas(), "runbook"); err == nil {
+		t.Error("AuditSchemas for a kind with no schema should fail")
+	}
+
+	// Re-filing a legacy fact leaves its metadata alone and is allowed;
+	// changing its metadata must produce a valid result.
+	subject := "audit-moved"
+	if _, err := s.BulkUpdate(ctx, memstore.BulkUpdateRequest{
+		Filter: memstore.QueryOpts{Subject: "audit", Kind: "task"},
+		Change: memstore.BulkChange{Subject: &subject},
+	}); err != nil {
+		t.Errorf("BulkUpdate of subject over a legacy task: %v", err)
+	}
+	if err := s.UpdateMetadata(ctx, badTask, map[string]any{"owner": "ana"}); err == nil {
+		t.Error("UpdateMetadata left the task invalid but was accepted")
+	}
+	if err := s.UpdateMetadata(ctx, badTask, map[string]any{"status": "completed"}); err != nil {
+		t.Errorf("UpdateMetadata fixing the task: %v", err)
+	}
+}
@@ -38,8 +38,9 @@ const (
 // ScoreResults builds the final ranked result set from first-stage FTS and
 // vector hits. It deduplicates by fact ID, computes the weighted first-stage
 // relevance, optionally reranks the top opts.RerankCandidates with rr and fuses
-// that relevance in, then applies the confirmation trust boost and recency
-// decay before sorting and truncating to opts.MaxResults.
+// that relevance in, optionally scales each fact by its rating history (fb),
+// then applies the confirmation trust boost and recency decay before sorting
+// and truncating to opts.MaxResults.
 //
 // It is shared by every backend (SQLite and Postgres) so the scoring policy
 // lives in one place. Rerank runs only when rr is non-nil AND opts.RerankMode
@@ -48,8 +49,9 @@ const (
 // the reranker is unreachable it degrades to that first-stage ordering (see
 // embedding.IsRerankAvailable) and never applies the threshold, so an outage
 // cannot empty the result set; only a non-availability rerank error — e.g. a
-// 4xx caller bug such as an unknown model — surfaces.
-func ScoreResults(ctx context.Context, rr embedding.Reranker, query string, fts, vec []SearchResult, opts SearchOpts) ([]SearchResult, error) {
+// 4xx caller bug such as an unknown model — surfaces. The feedback stage is
+// likewise optional (nil fb skips it) and a failing scorer is ignored.
+func ScoreResults(ctx context.Context, rr embedding.Reranker, fb *FeedbackStage, query string, fts, vec []SearchResult, opts SearchOpts) ([]SearchResult, error) {
 	merged := mergeFirstStage(fts, vec, opts)
 
 	if rr != nil && opts.RerankMode.Enabled() {
@@ -60,8 +62,9 @@ func ScoreResults(ctx context.Context, rr embedding.Reranker, query string, fts,
 		}
 	}
 
+	fb.apply(ctx, merged)
 	applyTrustDecay(merged, opts)
-	sort.Slice(merged, func(i, j int) bool { return merged[i].Combined > merged[j].Combined })
+	sortByCombined(merged)
 	if len(merged) > opts.MaxResults {
 		merged = merged[:opts.MaxResults]
 	}
@@ -123,7 +126,7 @@ func mergeFirstStage(fts, vec []SearchResult, opts SearchOpts) []SearchResult {
 		r.Combined = opts.FTSWeight*r.FTSScore + opts.VecWeight*r.VecScore
 		merged = append(merged, *r)
 	}
-	sort.Slice(merged, func(i, j int) bool { return merged[i].Combined > merged[j].Combined })
+	sortByCombined(merged)
 	return merged
 }
 
@@ -203,6 +206,92 @@ func fuseRerank(ctx context.Context, rr embedding.Reranker, query string, merged
 	return merged, nil
 }
 
+// RerankDocumentResults is the document-corpus counterpart of ScoreResults'
+// rerank stage: it rescores the top opts.RerankCandidates chunk hits (in their
+// first-stage order) with rr and fuses the rerank score into Score per
+// opts.RerankMode, using the same FuseScore arithmetic as facts. The rerank
+// document is each hit's RerankText, so path, symbol, and heading path count
+// toward relevance.
+//
+// First-stage document scores are on different scales per search mode (raw
+// ts_rank for FTS, [0,1] for vector and hybrid), so they are normalized by the
+// set's maximum before fusion. Gate mode keeps the first-stage order exactly
+// and only filters.
+//
+// The degraded-backend rule is the one fuseRerank follows: an unavailable
+// reranker returns results unchanged and unfiltered; only a non-availability
+// error surfaces. The caller truncates to MaxResults afterwards.
+func RerankDocumentResults(ctx context.Context, rr embedding.Reranker, query string, results []DocumentSearchResult, opts DocumentSearchOpts) ([]DocumentSearchResult, error) {
+	if rr == nil || !opts.RerankMode.Enabled() || len(results) == 0 {
+		return results, nil
+	}
+	n := opts.RerankCandidates
+	if n <= 0 {
+		n = DefaultRerankCandidates
+	}
+	n = min(n, len(results))
+
+	docs := make([]string, n)
+	for i := range docs {
+		docs[i] = results[i].RerankText()
+	}
+	docBytes := opts.RerankDocBytes
+	if docBytes <= 0 {
+		docBytes = DefaultRerankDocBytes
+	}
+	reranked, err := rr.Rerank(ctx, embedding.RerankRequest{
+		Query:            query,
+		Documents:        docs,
+		MaxDocumentBytes: docBytes,
+	})
+	if err != nil {
+		if !embedding.IsRerankAvailable(err) {
+			return results, nil // degrade: first-stage order, no threshold filtering
+		}
+		return nil, fmt.Errorf("memstore: document rerank: %w", err)
+	}
+
+	var maxScore float64
+	for _, r := range results {
+		maxScore = max(maxScore, r.Score)
+	}
+	out := make([]DocumentSearchResult, len(results))
+	copy(out, results)
+	if maxScore > 0 {
+		for i := range out {
+			out[i].Score /= maxScore
+		}
+	}
+
+	w := opts.RerankWeight
+	if w <= 0 {
+		w = DefaultRerankWeight
+	}
+	vouched := make([]bool, n)
+	for _, res := range reranked {
+		if res.Index < 0 || res.Index >= n {
+			continue // never trust a backend index unchecked
+		}
+		out[res.Index].RerankScore = res.Score
+		out[res.Index].Score = FuseScore(opts.RerankMode, w, res.Score, out[res.Index].Score)
+		vouched[res.Index] = true
+	}
+
+	if opts.RerankThreshold > 0 {
+		kept := out[:0:0]
+		for i := range out {
+			if i < n && vouched[i] && out[i].RerankScore >= opts.RerankThreshold {
+				kept = append(kept, out[i])
+			}
+		}
+		out = kept
+	}
+	if opts.RerankMode != RerankGate {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	}
+	return out, nil
+}
+
 // applyTrustDecay adjusts each result's Combined in place by the confirmation
 // trust boost (capped at 0.15) and the per-category recency decay. It runs
 // after any rerank fusion so the adjustments apply to the final relevance.
@@ -240,3 +329,14 @@ func FetchLimit(opts SearchOpts) int {
 	n := max(opts.RerankCandidates, opts.MaxResults*2)
 	return n
 }
+
+// sortByCombined orders results by Combined descending. Ties break on fact ID
+// so rankings built from map iteration are reproducible run to run.
+func sortByCombined(rs []SearchResult) {
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Combined != rs[j].Combined {
+			return rs[i].Combined > rs[j].Combined
+		}
+		return rs[i].Fact.ID < rs[j].Fact.ID
+	})
+}
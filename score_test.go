@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/matthewjhunter/go-embedding"
@@ -67,7 +68,7 @@ func ftsOnlyOpts() SearchOpts {
 func TestScoreResults_NoReranker_ReducesToWeightedSum(t *testing.T) {
 	fts := ftsHits(1, "a", 10.0, 2, "b", 5.0, 3, "c", 1.0)
 
-	got, err := ScoreResults(context.Background(), nil, "q", fts, nil, ftsOnlyOpts())
+	got, err := ScoreResults(context.Background(), nil, nil, "q", fts, nil, ftsOnlyOpts())
 	if err != nil {
 		t.Fatalf("ScoreResults: %v", err)
 	}
@@ -86,7 +87,7 @@ func TestScoreResults_FusesAndReorders(t *testing.T) {
 		return map[string]float64{"a": 0.1, "b": 0.2, "c": 0.9}[doc] // rerank prefers c
 	}}
 
-	got, err := ScoreResults(context.Background(), rr, "q", fts, nil, ftsOnlyOpts())
+	got, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, ftsOnlyOpts())
 	if err != nil {
 		t.Fatalf("ScoreResults: %v", err)
 	}
@@ -107,7 +108,7 @@ func TestScoreResults_DegradesWhenUnavailable(t *testing.T) {
 	fts := ftsHits(1, "a", 10.0, 2, "b", 5.0, 3, "c", 1.0)
 	rr := &fakeReranker{err: fmt.Errorf("%w: sidecar down", embedding.ErrRerankUnavailable)}
 
-	got, err := ScoreResults(context.Background(), rr, "q", fts, nil, ftsOnlyOpts())
+	got, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, ftsOnlyOpts())
 	if err != nil {
 		t.Fatalf("ScoreResults should degrade, not error: %v", err)
 	}
@@ -120,7 +121,7 @@ func TestScoreResults_SurfacesPermanentError(t *testing.T) {
 	fts := ftsHits(1, "a", 10.0)
 	rr := &fakeReranker{err: errors.New("HTTP 400: unknown model")} // reachable → caller bug
 
-	_, err := ScoreResults(context.Background(), rr, "q", fts, nil, ftsOnlyOpts())
+	_, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, ftsOnlyOpts())
 	if err == nil {
 		t.Fatal("expected a permanent rerank error to surface")
 	}
@@ -132,7 +133,7 @@ func TestScoreResults_LimitsCandidatePool(t *testing.T) {
 	opts := ftsOnlyOpts()
 	opts.RerankCandidates = 2 // only the top-2 first-stage docs get reranked
 
-	if _, err := ScoreResults(context.Background(), rr, "q", fts, nil, opts); err != nil {
+	if _, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, opts); err != nil {
 		t.Fatalf("ScoreResults: %v", err)
 	}
 	if len(rr.lastDocs) != 2 {
@@ -151,7 +152,7 @@ func TestScoreResults_DominantMode(t *testing.T) {
 	opts := ftsOnlyOpts()
 	opts.RerankMode = RerankDominant
 
-	got, err := ScoreResults(context.Background(), rr, "q", fts, nil, opts)
+	got, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, opts)
 	if err != nil {
 		t.Fatalf("ScoreResults: %v", err)
 	}
@@ -170,7 +171,7 @@ func TestScoreResults_GateMode_PreservesOrderFiltersByThreshold(t *testing.T) {
 	opts.RerankMode = RerankGate
 	opts.RerankThreshold = 0.15 // drops "a" (0.1); keeps b, c
 
-	got, err := ScoreResults(context.Background(), rr, "q", fts, nil, opts)
+	got, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, opts)
 	if err != nil {
 		t.Fatalf("ScoreResults: %v", err)
 	}
@@ -188,7 +189,7 @@ func TestScoreResults_ThresholdDropsLowRelevance(t *testing.T) {
 	opts := ftsOnlyOpts() // balanced
 	opts.RerankThreshold = 0.15
 
-	got, err := ScoreResults(context.Background(), rr, "q", fts, nil, opts)
+	got, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, opts)
 	if err != nil {
 		t.Fatalf("ScoreResults: %v", err)
 	}
@@ -205,7 +206,7 @@ func TestScoreResults_ThresholdNotAppliedOnDegrade(t *testing.T) {
 	opts := ftsOnlyOpts()
 	opts.RerankThreshold = 0.99
 
-	got, err := ScoreResults(context.Background(), rr, "q", fts, nil, opts)
+	got, err := ScoreResults(context.Background(), rr, nil, "q", fts, nil, opts)
 	if err != nil {
 		t.Fatalf("ScoreResults should degrade, not error: %v", err)
 	}
@@ -296,3 +297,104 @@ func equalIDs(a, b []int64) bool {
 	}
 	return true
 }
+
+// docHits builds first-stage document results from (chunkID, path, content,
+// score) quadruples.
+func docHits(quads ...any) []DocumentSearchResult {
+	var out []DocumentSearchResult
+	for i := 0; i < len(quads); i += 4 {
+		out = append(out, DocumentSearchResult{
+			Chunk: DocumentChunk{ID: int64(quads[i].(int)), Content: quads[i+2].(string)},
+			Path:  quads[i+1].(string),
+			Score: quads[i+3].(float64),
+		})
+	}
+	return out
+}
+
+func docIDs(results []DocumentSearchResult) []int64 {
+	out := make([]int64, len(results))
+	for i, r := range results {
+		out[i] = r.Chunk.ID
+	}
+	return out
+}
+
+func TestRerankDocumentResults_FusesWithContext(t *testing.T) {
+	hits := docHits(1, "docs/a.md", "alpha", 0.8, 2, "pgstore/tokens.go", "beta", 0.4)
+	hits[1].Chunk.Symbol = "Verify"
+	hits[1].Chunk.Receiver = "*TokenStore"
+	rr := &fakeReranker{score: func(doc string) float64 {
+		if strings.Contains(doc, "symbol: (*TokenStore).Verify") {
+			return 0.9
+		}
+		return 0.1
+	}}
+	opts := DocumentSearchOpts{RerankMode: RerankBalanced, RerankWeight: 0.7}
+
+	got, err := RerankDocumentResults(context.Background(), rr, "token verification", hits, opts)
+	if err != nil {
+		t.Fatalf("RerankDocumentResults: %v", err)
+	}
+	// First stage normalized to 1.0 and 0.5; fused:
+	//   2 = 0.7*0.9 + 0.3*0.5 = 0.78 ; 1 = 0.7*0.1 + 0.3*1.0 = 0.37
+	if want := []int64{2, 1}; !equalIDs(docIDs(got), want) {
+		t.Fatalf("order = %v, want %v (rerank should lift the symbol match)", docIDs(got), want)
+	}
+	if d := got[0].Score - 0.78; d > 1e-9 || d < -1e-9 {
+		t.Errorf("fused score = %v, want 0.78", got[0].Score)
+	}
+	if got[0].RerankScore != 0.9 {
+		t.Errorf("RerankScore = %v, want 0.9", got[0].RerankScore)
+	}
+	if want := "path: pgstore/tokens.go\nsymbol: (*TokenStore).Verify\nbeta"; rr.lastDocs[1] != want {
+		t.Errorf("rerank document = %q, want %q", rr.lastDocs[1], want)
+	}
+	if hits[0].Score != 0.8 {
+		t.Error("RerankDocumentResults mutated the caller's slice")
+	}
+}
+
+func TestRerankDocumentResults_DegradedBackendNeverEmpties(t *testing.T) {
+	hits := docHits(1, "a.md", "a", 0.8, 2, "b.md", "b", 0.4)
+	rr := &fakeReranker{err: fmt.Errorf("%w: down", embedding.ErrRerankUnavailable)}
+	opts := DocumentSearchOpts{RerankMode: RerankDominant, RerankThreshold: 0.99}
+
+	got, err := RerankDocumentResults(context.Background(), rr, "q", hits, opts)
+	if err != nil {
+		t.Fatalf("should degrade, not error: %v", err)
+	}
+	if want := []int64{1, 2}; !equalIDs(docIDs(got), want) {
+		t.Errorf("order = %v, want first-stage %v, unfiltered", docIDs(got), want)
+	}
+}
+
+func TestRerankDocumentResults_ThresholdAndGate(t *testing.T) {
+	// Gate keeps first-stage order (fallback hits stay below exact ones) and
+	// only filters; chunk 3 sits outside the pool, so the threshold drops it.
+	hits := docHits(1, "a.md", "keep", 0.2, 2, "b.md", "drop", 0.9, 3, "c.md", "keep", 0.1)
+	rr := &fakeReranker{score: func(doc string) float64 {
+		if strings.HasSuffix(doc, "keep") {
+			return 0.8
+		}
+		return 0.1
+	}}
+	opts := DocumentSearchOpts{RerankMode: RerankGate, RerankThreshold: 0.5, RerankCandidates: 2}
+
+	got, err := RerankDocumentResults(context.Background(), rr, "q", hits, opts)
+	if err != nil {
+		t.Fatalf("RerankDocumentResults: %v", err)
+	}
+	if want := []int64{1}; !equalIDs(docIDs(got), want) {
+		t.Errorf("gate+threshold = %v, want %v", docIDs(got), want)
+	}
+}
+
+func TestRerankDocumentResults_OffIsNoop(t *testing.T) {
+	hits := docHits(1, "a.md", "a", 3.0)
+	rr := &fakeReranker{score: func(string) float64 { return 1 }}
+	got, err := RerankDocumentResults(context.Background(), rr, "q", hits, DocumentSearchOpts{})
+	if err != nil || rr.calls != 0 || got[0].Score != 3.0 {
+		t.Errorf("RerankOff must not call the reranker or touch scores: calls=%d score=%v err=%v", rr.calls, got[0].Score, err)
+	}
+}
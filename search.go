@@ -2,12 +2,9 @@ package memstore
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/matthewjhunter/go-embedding"
 )
@@ -27,7 +24,7 @@ func (s *SQLiteStore) Search(ctx context.Context, query string, opts SearchOpts)
 		opts.VecWeight = 0.4
 	}
 
-	queryEmb, err := embedding.Single(ctx, s.embedder, query)
+	queryEmb, err := embedding.Single(ctx, s.cachedQueryEmbedder(), query)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +32,7 @@ func (s *SQLiteStore) Search(ctx context.Context, query string, opts SearchOpts)
 	// Hold the read lock only for the DB queries; rerank fusion in ScoreResults
 	// makes a network call and must not run while blocking writers.
 	s.mu.RLock()
-	rr := s.reranker
+	rr, fb := s.reranker, s.feedback
 	if rr != nil && opts.RerankMode.Enabled() && opts.RerankCandidates <= 0 {
 		// Size the candidate pool before the SQL runs (FetchLimit reads it).
 		opts.RerankCandidates = DefaultRerankCandidates
@@ -55,7 +52,19 @@ func (s *SQLiteStore) Search(ctx context.Context, query string, opts SearchOpts)
 	}
 	s.mu.RUnlock()
 
-	return ScoreResults(ctx, rr, query, ftsResults, vecResults, opts)
+	results, err := ScoreResults(ctx, rr, fb, query, ftsResults, vecResults, opts)
+	if err != nil {
+		return nil, err
+	}
+	return results, s.attachResultTags(ctx, results)
+}
+
+// attachResultTags sets the tags of scored search results, taking the read
+// lock for the query.
+func (s *SQLiteStore) attachResultTags(ctx context.Context, results []SearchResult) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return attachResultTags(ctx, s.db, results)
 }
 
 // quoteFTSQuery makes a raw string safe for use in an FTS5 MATCH expression.
@@ -82,22 +91,20 @@ func (s *SQLiteStore) searchFTS(ctx context.Context, query string, opts SearchOp
 		return nil, nil
 	}
 
-	q := `SELECT f.id, f.namespace, f.user_id, f.content, f.subject, f.category, f.kind, f.subsystem, f.metadata,
-	             f.superseded_by, f.superseded_at, f.confirmed_count, f.last_confirmed_at,
-	             f.use_count, f.last_used_at, f.embedding, f.created_at, rank
+	q := `SELECT ` + qualifiedFactColumns("f.") + `, rank
 	      FROM memstore_facts_fts fts
 	      JOIN memstore_facts f ON f.id = fts.rowid
-	      WHERE memstore_facts_fts MATCH ?`
+	      WHERE memstore_facts_fts MATCH ?` + notDeleted("f.")
 
 	args := []any{query}
 
 	s.appendNamespaceFilter(&q, &args, "f.namespace", opts.AllNamespaces, opts.Namespaces)
 	if opts.OnlyActive {
 		q += ` AND f.superseded_by IS NULL`
+		appendUnexpiredFilter(&q, &args, "f.")
 	}
 	if opts.Subject != "" {
-		q += ` AND f.subject = ?`
-		args = append(args, opts.Subject)
+		s.appendSubjectFilter(&q, &args, "f.subject", opts.Subject)
 	}
 	if opts.Category != "" {
 		q += ` AND f.category = ?`
@@ -115,6 +122,7 @@ func (s *SQLiteStore) searchFTS(ctx context.Context, query string, opts SearchOp
 		return nil, err
 	}
 	appendTemporalFilters(&q, &args, "f.", opts.CreatedAfter, opts.CreatedBefore)
+	appendTagFilter(&q, &args, "f.", opts.Tags)
 
 	q += ` ORDER BY rank LIMIT ?`
 	args = append(args, FetchLimit(opts)) // fetch extra for merge / rerank pool
@@ -127,55 +135,15 @@ func (s *SQLiteStore) searchFTS(ctx context.Context, query string, opts SearchOp
 
 	var results []SearchResult
 	for rows.Next() {
-		var f Fact
-		var userID sql.NullInt64
-		var metadata sql.NullString
-		var supersededBy *int64
-		var supersededAt sql.NullString
-		var lastConfirmedAt sql.NullString
-		var lastUsedAt sql.NullString
-		var embBlob []byte
-		var createdAt string
 		var rank float64
-
-		err := rows.Scan(
-			&f.ID, &f.Namespace, &userID, &f.Content, &f.Subject, &f.Category, &f.Kind, &f.Subsystem,
-			&metadata, &supersededBy, &supersededAt,
-			&f.ConfirmedCount, &lastConfirmedAt,
-			&f.UseCount, &lastUsedAt,
-			&embBlob, &createdAt, &rank,
-		)
+		f, err := scanFact(rows, &rank)
 		if err != nil {
 			return nil, fmt.Errorf("memstore: scanning FTS result: %w", err)
 		}
 
-		if userID.Valid {
-			f.UserID = userID.Int64
-		}
-		if metadata.Valid && metadata.String != "" {
-			f.Metadata = json.RawMessage(metadata.String)
-		}
-		f.SupersededBy = supersededBy
-		if supersededAt.Valid {
-			t, _ := time.Parse(time.RFC3339, supersededAt.String)
-			f.SupersededAt = &t
-		}
-		if lastConfirmedAt.Valid {
-			t, _ := time.Parse(time.RFC3339, lastConfirmedAt.String)
-			f.LastConfirmedAt = &t
-		}
-		if lastUsedAt.Valid {
-			t, _ := time.Parse(time.RFC3339, lastUsedAt.String)
-			f.LastUsedAt = &t
-		}
-		if len(embBlob) > 0 {
-			f.Embedding = embedding.DecodeFloat32s(embBlob)
-		}
-		f.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-
 		// BM25 rank is negative (lower = better match), negate for scoring.
 		results = append(results, SearchResult{
-			Fact:     f,
+			Fact:     *f,
 			FTSScore: -rank,
 		})
 	}
@@ -186,17 +154,17 @@ func (s *SQLiteStore) searchFTS(ctx context.Context, query string, opts SearchOp
 // searchVector performs cosine similarity search against stored embeddings.
 func (s *SQLiteStore) searchVector(ctx context.Context, queryEmb []float32, opts SearchOpts) ([]SearchResult, error) {
 	q := `SELECT ` + factColumns + `
-	      FROM memstore_facts WHERE embedding IS NOT NULL`
+	      FROM memstore_facts WHERE embedding IS NOT NULL` + notDeleted("")
 
 	var args []any
 
 	s.appendNamespaceFilter(&q, &args, "namespace", opts.AllNamespaces, opts.Namespaces)
 	if opts.OnlyActive {
 		q += ` AND superseded_by IS NULL`
+		appendUnexpiredFilter(&q, &args, "")
 	}
 	if opts.Subject != "" {
-		q += ` AND subject = ?`
-		args = append(args, opts.Subject)
+		s.appendSubjectFilter(&q, &args, "subject", opts.Subject)
 	}
 	if opts.Category != "" {
 		q += ` AND category = ?`
@@ -214,6 +182,7 @@ func (s *SQLiteStore) searchVector(ctx context.Context, queryEmb []float32, opts
 		return nil, err
 	}
 	appendTemporalFilters(&q, &args, "", opts.CreatedAfter, opts.CreatedBefore)
+	appendTagFilter(&q, &args, "", opts.Tags)
 
 	rows, err := s.db.QueryContext(ctx, q, args...)
 	if err != nil {
@@ -282,9 +251,14 @@ func (s *SQLiteStore) SearchFTS(ctx context.Context, query string, opts SearchOp
 		return nil, err
 	}
 
-	// FTS-only path does not rerank: no embedder context and it is the
-	// administrative fallback, not the interactive recall path.
-	return ScoreResults(ctx, nil, query, ftsResults, nil, opts)
+	// FTS-only path does not rerank or apply feedback: no embedder context and
+	// it is the administrative fallback (and recall's keyword pass, which
+	// applies feedback itself), not the interactive search path.
+	results, err := ScoreResults(ctx, nil, nil, query, ftsResults, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return results, s.attachResultTags(ctx, results)
 }
 
 // SearchBatch performs hybrid search for multiple queries, sharing a single
@@ -308,7 +282,7 @@ func (s *SQLiteStore) SearchBatch(ctx context.Context, queries []string, opts Se
 		opts.VecWeight = 0.4
 	}
 
-	queryEmbs, err := embedding.EmbedWithRetry(ctx, s.embedder, queries)
+	queryEmbs, err := embedding.EmbedWithRetry(ctx, s.cachedQueryEmbedder(), queries)
 	if err != nil {
 		return nil, err
 	}
@@ -332,11 +306,15 @@ func (s *SQLiteStore) SearchBatch(ctx context.Context, queries []string, opts Se
 		}
 
 		// Batch search does not rerank: it is the bulk/backfill path, where
-		// per-query cross-encoder latency would dominate.
-		scored, err := ScoreResults(ctx, nil, query, ftsResults, vecResults, opts)
+		// per-query cross-encoder latency would dominate. Feedback is one
+		// query, so it applies.
+		scored, err := ScoreResults(ctx, nil, s.feedback, query, ftsResults, vecResults, opts)
 		if err != nil {
 			return nil, err
 		}
+		if err := attachResultTags(ctx, s.db, scored); err != nil {
+			return nil, err
+		}
 		results[i] = scored
 	}
 
@@ -858,3 +858,81 @@ func (e *transientEmbedder) Model() string { return "transient-mock" }
 func (e *transientEmbedder) Fingerprint() embedding.Fingerprint {
 	return embedding.Fingerprint{Model: "transient-mock", Dim: e.dim}
 }
+
+// ratingsScorer is a FeedbackScorer over fixed per-fact stats.
+type ratingsScorer map[string]memstore.FeedbackStat
+
+func (r ratingsScorer) FeedbackScores(_ context.Context, refIDs []string, _ string) (map[string]memstore.FeedbackStat, error) {
+	out := make(map[string]memstore.FeedbackStat)
+	for _, id := range refIDs {
+		if s, ok := r[id]; ok {
+			out[id] = s
+		}
+	}
+	return out, nil
+}
+
+func TestSearch_FeedbackStage(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	bad, err := store.Insert(ctx, memstore.Fact{Content: "Deploys go through the staging cluster", Subject: "ops", Category: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	good, err := store.Insert(ctx, memstore.Fact{Content: "Deploys go through the canary cluster first", Subject: "ops", Category: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.EmbedFacts(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	opts := memstore.SearchOpts{MaxResults: 10, OnlyActive: true}
+	rank := func(results []memstore.SearchResult, id int64) int {
+		for i, r := range results {
+			if r.Fact.ID == id {
+				return i
+			}
+		}
+		t.Fatalf("fact %d missing from results", id)
+		return -1
+	}
+
+	store.SetFeedback(ratingsScorer{
+		fmt.Sprint(bad):  {Avg: -1, Count: 8},
+		fmt.Sprint(good): {Avg: 1, Count: 8},
+	}, memstore.DefaultFeedbackPolicy())
+
+	results, err := store.Search(ctx, "deploys staging cluster", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rank(results, good) > rank(results, bad) {
+		t.Errorf("Search: the downvoted fact still outranks the upvoted one")
+	}
+	batch, err := store.SearchBatch(ctx, []string{"deploys staging cluster"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rank(batch[0], good) > rank(batch[0], bad) {
+		t.Errorf("SearchBatch: the downvoted fact still outranks the upvoted one")
+	}
+
+	// SearchFTS is the raw keyword path and ignores feedback.
+	fts, err := store.SearchFTS(ctx, "staging", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fts) == 0 || fts[0].Fact.ID != bad {
+		t.Errorf("SearchFTS should be unaffected by feedback, got %v", fts)
+	}
+
+	// Disabling the stage restores first-stage order.
+	store.SetFeedback(nil, memstore.FeedbackPolicy{})
+	if results, err = store.Search(ctx, "deploys staging cluster", opts); err != nil {
+		t.Fatal(err)
+	}
+	if rank(results, bad) > rank(results, good) {
+		t.Errorf("without feedback the staging fact should rank first for a staging query")
+	}
+}
@@ -52,11 +52,13 @@ type ContextHint struct {
 	CWD             string             `json:"cwd"` // working directory of the generating session, for cross-session lookup
 	TurnIndex       int                `json:"turn_index"`
 	HintText        string             `json:"hint_text"`
-	RefIDs          []string           `json:"ref_ids"`          // selected candidate fact IDs (positives)
-	RetrievedIDs    []string           `json:"retrieved_ids"`    // all candidate fact IDs before selection (positives + negatives)
-	CandidateScores map[string]float64 `json:"candidate_scores"` // {fact_id_str: vec_score} for all candidates
-	SearchQuery     string             `json:"search_query"`     // query used for the Searcher stage
-	RankerVersion   string             `json:"ranker_version"`   // pipeline version at generation time
+	RefIDs          []string           `json:"ref_ids"`              // selected candidate fact IDs (positives)
+	RetrievedIDs    []string           `json:"retrieved_ids"`        // all candidate fact IDs before selection (positives + negatives)
+	CandidateScores map[string]float64 `json:"candidate_scores"`     // {fact_id_str: vec_score} for all candidates
+	SearchQuery     string             `json:"search_query"`         // query used for the Searcher stage
+	RankerVersion   string             `json:"ranker_version"`       // pipeline version at generation time
+	Experiment      string             `json:"experiment,omitempty"` // online experiment the hint was generated under, if any
+	Arm             string             `json:"arm,omitempty"`        // that experiment's arm
 	Relevance       float64            `json:"relevance"`
 	Desirability    float64            `json:"desirability"`
 	CreatedAt       time.Time          `json:"created_at"`
@@ -89,8 +91,19 @@ type FeedbackStore interface {
 
 // FeedbackStat is the aggregate feedback signal for a single ref.
 type FeedbackStat struct {
-	Avg   float64 // mean of recorded scores ([-1, +1])
+	Avg   float64 // mean of recorded scores ([-1, +1]); age-weighted when decayed
 	Count int     // number of recorded ratings
+	// Weight is the age-weighted rating count from a DecayedFeedbackScorer:
+	// Count when every rating is fresh, approaching 0 as they age. 0 means the
+	// stat is undecayed and Count is the weight.
+	Weight float64
+}
+
+func (s FeedbackStat) weight() float64 {
+	if s.Weight > 0 {
+		return s.Weight
+	}
+	return float64(s.Count)
 }
 
 // FeedbackScorer returns aggregate feedback stats in bulk.
@@ -101,6 +114,22 @@ type FeedbackScorer interface {
 	FeedbackScores(ctx context.Context, refIDs []string, refType string) (map[string]FeedbackStat, error)
 }
 
+// Exploration kinds recorded with an exploratory injection.
+const (
+	ExploreSwap    = "swap"    // swapped with the top item within the top-k
+	ExploreEpsilon = "epsilon" // a below-threshold candidate included on purpose
+)
+
+// ExplorationRecorder is implemented by session stores that can log an
+// exploratory injection: an item recall deliberately displayed out of score
+// order. rank is the displayed 0-based position; originalRank is where the
+// ranker put it (past the displayed set for an epsilon pick). Logging both
+// gives the feedback log the position variation counterfactual
+// learning-to-rank needs to estimate position bias.
+type ExplorationRecorder interface {
+	RecordExploration(ctx context.Context, sessionID, refID, refType string, rank, originalRank int, kind string) error
+}
+
 // SessionUserScoper is implemented by session stores that support per-user
 // scoping. ForUser returns a session store whose reads and writes are scoped
 // to the given user. userID must be positive.
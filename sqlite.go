@@ -14,11 +14,16 @@ import (
 	"github.com/matthewjhunter/go-embedding"
 )
 
-const schemaVersion = 12
+const schemaVersion = 22
 
 // factColumns is the canonical SELECT list for fact queries.
-// searchFTS has its own column list because it joins and adds rank.
-const factColumns = `id, namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, superseded_at, confirmed_count, last_confirmed_at, use_count, last_used_at, embedding, created_at`
+const factColumns = `id, namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, superseded_at, confirmed_count, last_confirmed_at, use_count, last_used_at, expires_at, archived_at, deleted_at, embedding, created_at, source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id`
+
+// qualifiedFactColumns returns factColumns with each column prefixed by alias
+// (e.g. "f."), for queries that join memstore_facts to another table.
+func qualifiedFactColumns(alias string) string {
+	return alias + strings.ReplaceAll(factColumns, ", ", ", "+alias)
+}
 
 // SQLiteStore implements Store backed by a caller-provided SQLite database.
 // It creates memstore_* tables and uses its own version tracking table so it
@@ -30,6 +35,10 @@ type SQLiteStore struct {
 	namespace string             // partition key for multi-tenant isolation
 	userID    int64              // resolved owner for this store; set after migrateV12
 	reranker  embedding.Reranker // nil means no second-stage rerank; set via SetReranker
+	feedback  *FeedbackStage     // nil means no feedback stage; set via SetFeedback
+	schemas   *SchemaRegistry    // metadata schemas checked on write; nil checks nothing; set via SetSchemas
+
+	queryEmbedder embedding.Embedder // embedder behind the persistent query cache; set via SetQueryCache
 }
 
 // SetReranker configures a second-stage cross-encoder reranker for Search.
@@ -42,6 +51,29 @@ func (s *SQLiteStore) SetReranker(rr embedding.Reranker) {
 	s.reranker = rr
 }
 
+// SetFeedback enables the feedback stage of Search and SearchBatch: results
+// are scaled by their facts' rating history from scorer under policy (see
+// FeedbackPolicy). SearchFTS is unaffected. Intended to be called once at
+// startup; a nil scorer disables the stage.
+func (s *SQLiteStore) SetFeedback(scorer FeedbackScorer, policy FeedbackPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feedback = nil
+	if scorer != nil {
+		s.feedback = &FeedbackStage{Scorer: scorer, Policy: policy}
+	}
+}
+
+// SetSchemas replaces the metadata schemas that Insert, InsertBatch,
+// UpdateMetadata, Revise, Merge and BulkUpdate check a fact's metadata
+// against (see SchemaRegistry). The store starts with DefaultSchemas.
+// Intended to be called once at startup; nil disables the check.
+func (s *SQLiteStore) SetSchemas(r *SchemaRegistry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas = r
+}
+
 // NewSQLiteStore creates a new fact store using the given database connection.
 // It creates memstore_* tables if needed and runs any pending migrations.
 // The caller is responsible for opening and configuring the database
@@ -55,7 +87,7 @@ func (s *SQLiteStore) SetReranker(rr embedding.Reranker) {
 // operation and validates that subsequent opens use the same model. Pass nil
 // only for write-only or administrative access (Search requires an embedder).
 func NewSQLiteStore(db *sql.DB, embedder embedding.Embedder, namespace string) (*SQLiteStore, error) {
-	s := &SQLiteStore{db: db, embedder: embedder, namespace: namespace}
+	s := &SQLiteStore{db: db, embedder: embedder, namespace: namespace, schemas: DefaultSchemas()}
 	// Enable foreign key enforcement. This is a per-connection setting in SQLite;
 	// safe here because callers are expected to use SetMaxOpenConns(1).
 	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
@@ -76,6 +108,10 @@ func NewSQLiteStore(db *sql.DB, embedder embedding.Embedder, namespace string) (
 			return nil, err
 		}
 	}
+	if err := s.purgeQueryCacheModels(); err != nil {
+		return nil, err
+	}
+	s.queryEmbedder = NewQueryCacheEmbedder(embedder, sqliteQueryCache{s}, DefaultQueryCachePolicy())
 	return s, nil
 }
 
@@ -211,6 +247,66 @@ func (s *SQLiteStore) migrate() error {
 		}
 	}
 
+	if version < 13 {
+		if err := s.migrateV13(); err != nil {
+			return err
+		}
+	}
+
+	if version < 14 {
+		if err := s.migrateV14(); err != nil {
+			return err
+		}
+	}
+
+	if version < 15 {
+		if err := s.migrateV15(); err != nil {
+			return err
+		}
+	}
+
+	if version < 16 {
+		if err := s.migrateV16(); err != nil {
+			return err
+		}
+	}
+
+	if version < 17 {
+		if err := s.migrateV17(); err != nil {
+			return err
+		}
+	}
+
+	if version < 18 {
+		if err := s.migrateV18(); err != nil {
+			return err
+		}
+	}
+
+	if version < 19 {
+		if err := s.migrateV19(); err != nil {
+			return err
+		}
+	}
+
+	if version < 20 {
+		if err := s.migrateV20(); err != nil {
+			return err
+		}
+	}
+
+	if version < 21 {
+		if err := s.migrateV21(); err != nil {
+			return err
+		}
+	}
+
+	if version < 22 {
+		if err := s.migrateV22(); err != nil {
+			return err
+		}
+	}
+
 	if version == 0 {
 		_, err = s.db.Exec("INSERT INTO memstore_version (version) VALUES (?)", schemaVersion)
 	} else {
@@ -390,7 +486,7 @@ func (s *SQLiteStore) TermDocCounts(ctx context.Context, terms []string) (map[st
 	// Get total active document count.
 	var totalDocs int
 	err := s.db.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM memstore_facts WHERE namespace = ? AND superseded_by IS NULL`,
+		`SELECT COUNT(*) FROM memstore_facts WHERE namespace = ? AND superseded_by IS NULL`+notDeleted(""),
 		s.namespace).Scan(&totalDocs)
 	if err != nil {
 		return nil, 0, fmt.Errorf("memstore: counting docs: %w", err)
@@ -632,9 +728,21 @@ func (s *SQLiteStore) migrateV1() error {
 // Insert adds a single fact and returns its ID. The fact's Namespace field
 // is set to the store's namespace regardless of any value provided.
 func (s *SQLiteStore) Insert(ctx context.Context, f Fact) (int64, error) {
+	prov, err := ResolveProvenance(ctx, f.Provenance)
+	if err != nil {
+		return 0, err
+	}
+	tags, err := NormalizeTags(f.Tags)
+	if err != nil {
+		return 0, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.schemas.Validate(f.Kind, f.Metadata); err != nil {
+		return 0, err
+	}
 	if f.CreatedAt.IsZero() {
 		f.CreatedAt = time.Now().UTC()
 	}
@@ -655,24 +763,61 @@ func (s *SQLiteStore) Insert(ctx context.Context, f Fact) (int64, error) {
 		userID = f.UserID
 	}
 
-	result, err := s.db.ExecContext(ctx,
-		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, embedding, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		s.namespace, userID, f.Content, f.Subject, f.Category, f.Kind, f.Subsystem, metadata,
-		f.SupersededBy, embBlob, f.CreatedAt.Format(time.RFC3339),
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, expires_at, embedding, created_at,
+		                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+		 VALUES (?, ?, ?, `+canonicalSubjectSQL+`, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.namespace, userID, f.Content, s.namespace, userID, f.Subject, f.Subject, f.Category, f.Kind, f.Subsystem, metadata,
+		f.SupersededBy, formatOptionalTime(f.ExpiresAt), embBlob, f.CreatedAt.Format(time.RFC3339),
+		prov.Source, prov.SessionID, prov.TurnFirst, prov.TurnLast, prov.Origin, nullableID(prov.DocumentID),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("memstore: inserting fact: %w", err)
 	}
-	return result.LastInsertId()
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("memstore: getting insert id: %w", err)
+	}
+	if err := insertTags(ctx, tx, id, tags); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("memstore: committing fact: %w", err)
+	}
+	return id, nil
 }
 
 // InsertBatch inserts multiple facts in a single transaction.
 // Each fact's ID field is set on the slice element after insertion.
 func (s *SQLiteStore) InsertBatch(ctx context.Context, facts []Fact) error {
+	provs := make([]Provenance, len(facts))
+	tags := make([][]string, len(facts))
+	for i := range facts {
+		p, err := ResolveProvenance(ctx, facts[i].Provenance)
+		if err != nil {
+			return err
+		}
+		provs[i] = p
+		if tags[i], err = NormalizeTags(facts[i].Tags); err != nil {
+			return err
+		}
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	for i := range facts {
+		if err := s.schemas.Validate(facts[i].Kind, facts[i].Metadata); err != nil {
+			return fmt.Errorf("memstore: inserting fact %q: %w", facts[i].Content, err)
+		}
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("memstore: beginning transaction: %w", err)
@@ -680,8 +825,9 @@ func (s *SQLiteStore) InsertBatch(ctx context.Context, facts []Fact) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, embedding, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, superseded_by, expires_at, embedding, created_at,
+		                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+		 VALUES (?, ?, ?, `+canonicalSubjectSQL+`, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 	)
 	if err != nil {
 		return fmt.Errorf("memstore: preparing insert: %w", err)
@@ -711,8 +857,9 @@ func (s *SQLiteStore) InsertBatch(ctx context.Context, facts []Fact) error {
 		}
 
 		result, err := stmt.ExecContext(ctx,
-			s.namespace, userID, facts[i].Content, facts[i].Subject, facts[i].Category, facts[i].Kind, facts[i].Subsystem, metadata,
-			facts[i].SupersededBy, embBlob, facts[i].CreatedAt.Format(time.RFC3339),
+			s.namespace, userID, facts[i].Content, s.namespace, userID, facts[i].Subject, facts[i].Subject, facts[i].Category, facts[i].Kind, facts[i].Subsystem, metadata,
+			facts[i].SupersededBy, formatOptionalTime(facts[i].ExpiresAt), embBlob, facts[i].CreatedAt.Format(time.RFC3339),
+			provs[i].Source, provs[i].SessionID, provs[i].TurnFirst, provs[i].TurnLast, provs[i].Origin, nullableID(provs[i].DocumentID),
 		)
 		if err != nil {
 			return fmt.Errorf("memstore: inserting fact %q: %w", facts[i].Content, err)
@@ -722,6 +869,9 @@ func (s *SQLiteStore) InsertBatch(ctx context.Context, facts []Fact) error {
 		if err != nil {
 			return fmt.Errorf("memstore: getting insert id: %w", err)
 		}
+		if err := insertTags(ctx, tx, id, tags[i]); err != nil {
+			return err
+		}
 		facts[i].ID = id
 	}
 
@@ -735,7 +885,7 @@ func (s *SQLiteStore) Supersede(ctx context.Context, oldID, newID int64) error {
 
 	now := time.Now().UTC().Format(time.RFC3339)
 	result, err := s.db.ExecContext(ctx,
-		`UPDATE memstore_facts SET superseded_by = ?, superseded_at = ? WHERE id = ? AND namespace = ? AND superseded_by IS NULL`,
+		`UPDATE memstore_facts SET superseded_by = ?, superseded_at = ? WHERE id = ? AND namespace = ? AND superseded_by IS NULL`+notDeleted(""),
 		newID, now, oldID, s.namespace,
 	)
 	if err != nil {
@@ -752,6 +902,118 @@ func (s *SQLiteStore) Supersede(ctx context.Context, oldID, newID int64) error {
 	return nil
 }
 
+// Revise replaces an active fact's content in a single transaction. The new
+// version inherits the old one's owner, subject, category, kind, subsystem,
+// expiry, metadata (with patch merged in) and tags, gets a copy of every
+// link touching the old fact, and supersedes it. The new content is embedded
+// before the transaction when the store has an embedder; if that fails the
+// version is stored unembedded and EmbedFacts picks it up later.
+func (s *SQLiteStore) Revise(ctx context.Context, id int64, content string, patch map[string]any) (int64, error) {
+	if strings.TrimSpace(content) == "" {
+		return 0, fmt.Errorf("memstore: revising fact %d: content is required", id)
+	}
+	prov, err := ResolveProvenance(ctx, Provenance{})
+	if err != nil {
+		return 0, err
+	}
+	var embBlob []byte
+	if s.embedder != nil {
+		if emb, err := embedding.Single(ctx, s.embedder, content); err == nil {
+			embBlob = embedding.EncodeFloat32s(emb)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		userID                             sql.NullInt64
+		subject, category, kind, subsystem string
+		metadata, expiresAt                sql.NullString
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT user_id, subject, category, kind, subsystem, metadata, expires_at FROM memstore_facts
+		 WHERE id = ? AND namespace = ? AND superseded_by IS NULL`+notDeleted(""),
+		id, s.namespace,
+	).Scan(&userID, &subject, &category, &kind, &subsystem, &metadata, &expiresAt)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("memstore: fact %d not found or already superseded", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("memstore: reading fact %d: %w", id, err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if expiresAt.Valid && expiresAt.String <= now {
+		return 0, fmt.Errorf("%w: fact %d expired at %s", ErrFactExpired, id, expiresAt.String)
+	}
+
+	merged, err := mergeMetadata([]byte(metadata.String), patch)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: merging metadata for fact %d: %w", id, err)
+	}
+	if len(patch) > 0 {
+		if err := s.schemas.ValidateFact(Fact{ID: id, Kind: kind, Metadata: merged}); err != nil {
+			return 0, err
+		}
+	}
+	var newMetadata *string
+	if merged != nil {
+		ms := string(merged)
+		newMetadata = &ms
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO memstore_facts (namespace, user_id, content, subject, category, kind, subsystem, metadata, expires_at, embedding, created_at,
+		                             source, source_session, source_turn_first, source_turn_last, source_origin, source_document_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.namespace, userID, content, subject, category, kind, subsystem, newMetadata, expiresAt, embBlob, now,
+		prov.Source, prov.SessionID, prov.TurnFirst, prov.TurnLast, prov.Origin, nullableID(prov.DocumentID),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: inserting revision of fact %d: %w", id, err)
+	}
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("memstore: getting insert id: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO memstore_links (namespace, user_id, source_id, target_id, link_type, bidirectional, label, metadata, created_at)
+		 SELECT namespace, user_id,
+		        CASE WHEN source_id = ? THEN ? ELSE source_id END,
+		        CASE WHEN target_id = ? THEN ? ELSE target_id END,
+		        link_type, bidirectional, label, metadata, ?
+		 FROM memstore_links WHERE namespace = ? AND (source_id = ? OR target_id = ?)`,
+		id, newID, id, newID, now, s.namespace, id, id,
+	); err != nil {
+		return 0, fmt.Errorf("memstore: copying links of fact %d: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO memstore_fact_tags (fact_id, tag) SELECT ?, tag FROM memstore_fact_tags WHERE fact_id = ?`,
+		newID, id,
+	); err != nil {
+		return 0, fmt.Errorf("memstore: copying tags of fact %d: %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE memstore_facts SET superseded_by = ?, superseded_at = ? WHERE id = ?`,
+		newID, now, id,
+	); err != nil {
+		return 0, fmt.Errorf("memstore: superseding fact %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("memstore: committing revision of fact %d: %w", id, err)
+	}
+	return newID, nil
+}
+
 // Confirm increments a fact's confirmed_count and updates last_confirmed_at.
 func (s *SQLiteStore) Confirm(ctx context.Context, id int64) error {
 	s.mu.Lock()
@@ -759,7 +1021,7 @@ func (s *SQLiteStore) Confirm(ctx context.Context, id int64) error {
 
 	now := time.Now().UTC().Format(time.RFC3339)
 	result, err := s.db.ExecContext(ctx,
-		`UPDATE memstore_facts SET confirmed_count = confirmed_count + 1, last_confirmed_at = ? WHERE id = ? AND namespace = ?`,
+		`UPDATE memstore_facts SET confirmed_count = confirmed_count + 1, last_confirmed_at = ? WHERE id = ? AND namespace = ?`+notDeleted(""),
 		now, id, s.namespace,
 	)
 	if err != nil {
@@ -795,7 +1057,7 @@ func (s *SQLiteStore) Touch(ctx context.Context, ids []int64) error {
 
 	_, err := s.db.ExecContext(ctx,
 		`UPDATE memstore_facts SET use_count = use_count + 1, last_used_at = ?
-		 WHERE namespace = ? AND id IN (`+placeholders+`)`,
+		 WHERE namespace = ? AND id IN (`+placeholders+`)`+notDeleted(""),
 		args...,
 	)
 	if err != nil {
@@ -813,11 +1075,14 @@ func (s *SQLiteStore) UpdateMetadata(ctx context.Context, id int64, patch map[st
 	defer s.mu.Unlock()
 
 	// Read current metadata.
-	var raw sql.NullString
+	var (
+		kind string
+		raw  sql.NullString
+	)
 	err := s.db.QueryRowContext(ctx,
-		`SELECT metadata FROM memstore_facts WHERE id = ? AND namespace = ?`,
+		`SELECT kind, metadata FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""),
 		id, s.namespace,
-	).Scan(&raw)
+	).Scan(&kind, &raw)
 	if err == sql.ErrNoRows {
 		return fmt.Errorf("memstore: fact %d not found", id)
 	}
@@ -825,26 +1090,15 @@ func (s *SQLiteStore) UpdateMetadata(ctx context.Context, id int64, patch map[st
 		return fmt.Errorf("memstore: reading metadata for fact %d: %w", id, err)
 	}
 
-	// Unmarshal existing metadata (empty object if NULL).
-	existing := make(map[string]any)
-	if raw.Valid && raw.String != "" {
-		if err := json.Unmarshal([]byte(raw.String), &existing); err != nil {
-			return fmt.Errorf("memstore: unmarshaling metadata for fact %d: %w", id, err)
-		}
+	merged, err := mergeMetadata([]byte(raw.String), patch)
+	if err != nil {
+		return fmt.Errorf("memstore: merging metadata for fact %d: %w", id, err)
 	}
-
-	// Apply patch: non-nil values set, nil values delete.
-	for k, v := range patch {
-		if v == nil {
-			delete(existing, k)
-		} else {
-			existing[k] = v
-		}
+	if merged == nil {
+		merged = []byte("{}")
 	}
-
-	merged, err := json.Marshal(existing)
-	if err != nil {
-		return fmt.Errorf("memstore: marshaling metadata for fact %d: %w", id, err)
+	if err := s.schemas.ValidateFact(Fact{ID: id, Kind: kind, Metadata: merged}); err != nil {
+		return err
 	}
 
 	_, err = s.db.ExecContext(ctx,
@@ -857,14 +1111,39 @@ func (s *SQLiteStore) UpdateMetadata(ctx context.Context, id int64, patch map[st
 	return nil
 }
 
-// Delete removes a fact by ID. Returns an error if the fact doesn't exist
-// in this namespace.
+// mergeMetadata applies a metadata patch to a fact's stored metadata JSON:
+// keys with non-nil values are set, keys with nil values are deleted. It
+// returns nil when there was no metadata and the patch is empty, so a NULL
+// column stays NULL.
+func mergeMetadata(raw []byte, patch map[string]any) ([]byte, error) {
+	if len(raw) == 0 && len(patch) == 0 {
+		return nil, nil
+	}
+	existing := make(map[string]any)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(existing, k)
+		} else {
+			existing[k] = v
+		}
+	}
+	return json.Marshal(existing)
+}
+
+// Delete moves a fact to the trash by stamping deleted_at. Returns an error
+// if the fact doesn't exist in this namespace or is already trashed.
 func (s *SQLiteStore) Delete(ctx context.Context, id int64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	result, err := s.db.ExecContext(ctx,
-		`DELETE FROM memstore_facts WHERE id = ? AND namespace = ?`, id, s.namespace,
+		`UPDATE memstore_facts SET deleted_at = ? WHERE id = ? AND namespace = ?`+notDeleted(""),
+		time.Now().UTC().Format(time.RFC3339), id, s.namespace,
 	)
 	if err != nil {
 		return fmt.Errorf("memstore: deleting fact %d: %w", id, err)
@@ -885,7 +1164,7 @@ func (s *SQLiteStore) Get(ctx context.Context, id int64) (*Fact, error) {
 	defer s.mu.RUnlock()
 
 	row := s.db.QueryRowContext(ctx,
-		`SELECT `+factColumns+` FROM memstore_facts WHERE id = ? AND namespace = ?`, id, s.namespace,
+		`SELECT `+factColumns+` FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""), id, s.namespace,
 	)
 	f, err := scanFact(row)
 	if err == sql.ErrNoRows {
@@ -894,21 +1173,55 @@ func (s *SQLiteStore) Get(ctx context.Context, id int64) (*Fact, error) {
 	if err != nil {
 		return nil, fmt.Errorf("memstore: getting fact %d: %w", id, err)
 	}
+	if f.Tags, err = s.factTags(ctx, id); err != nil {
+		return nil, err
+	}
 	return f, nil
 }
 
+// factTags returns the tags of one fact.
+func (s *SQLiteStore) factTags(ctx context.Context, id int64) ([]string, error) {
+	tags, err := loadTags(ctx, s.db, []int64{id})
+	return tags[id], err
+}
+
 // List returns facts matching the given filters, ordered by ID.
 func (s *SQLiteStore) List(ctx context.Context, opts QueryOpts) ([]Fact, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	q := `SELECT ` + factColumns + ` FROM memstore_facts WHERE 1=1`
+	where, args, err := s.listFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT ` + factColumns + ` FROM memstore_facts` + where + ` ORDER BY id`
+
+	if opts.Limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: listing facts: %w", err)
+	}
+	facts, err := scanFacts(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	return facts, attachTags(ctx, s.db, facts)
+}
+
+// listFilter builds the WHERE clause List applies for opts; Limit is left
+// to the caller.
+func (s *SQLiteStore) listFilter(opts QueryOpts) (string, []any, error) {
+	q := ` WHERE 1=1` + notDeleted("")
 	var args []any
 	s.appendNamespaceFilter(&q, &args, "namespace", false, opts.Namespaces)
 
 	if opts.Subject != "" {
-		q += ` AND subject = ?`
-		args = append(args, opts.Subject)
+		s.appendSubjectFilter(&q, &args, "subject", opts.Subject)
 	}
 	if opts.Category != "" {
 		q += ` AND category = ?`
@@ -924,6 +1237,7 @@ func (s *SQLiteStore) List(ctx context.Context, opts QueryOpts) ([]Fact, error)
 	}
 	if opts.OnlyActive {
 		q += ` AND superseded_by IS NULL`
+		appendUnexpiredFilter(&q, &args, "")
 	}
 	if len(opts.IDs) > 0 {
 		placeholders := make([]string, len(opts.IDs))
@@ -934,37 +1248,27 @@ func (s *SQLiteStore) List(ctx context.Context, opts QueryOpts) ([]Fact, error)
 		q += ` AND id IN (` + strings.Join(placeholders, ",") + `)`
 	}
 	if err := appendMetadataFilters(&q, &args, "", opts.MetadataFilters); err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	appendTemporalFilters(&q, &args, "", opts.CreatedAfter, opts.CreatedBefore)
-
-	q += ` ORDER BY id`
-
-	if opts.Limit > 0 {
-		q += ` LIMIT ?`
-		args = append(args, opts.Limit)
-	}
-
-	rows, err := s.db.QueryContext(ctx, q, args...)
-	if err != nil {
-		return nil, fmt.Errorf("memstore: listing facts: %w", err)
-	}
-	defer rows.Close()
-
-	return scanFacts(rows)
+	appendProvenanceFilter(&q, &args, "", opts.Provenance)
+	appendTagFilter(&q, &args, "", opts.Tags)
+	return q, args, nil
 }
 
 // BySubject returns facts for a given subject. If onlyActive is true,
-// superseded facts are excluded.
+// superseded and expired facts are excluded.
 func (s *SQLiteStore) BySubject(ctx context.Context, subject string, onlyActive bool) ([]Fact, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	q := `SELECT ` + factColumns + `
-	      FROM memstore_facts WHERE subject = ? AND namespace = ?`
-	args := []any{subject, s.namespace}
+	      FROM memstore_facts WHERE namespace = ?` + notDeleted("")
+	args := []any{s.namespace}
+	s.appendSubjectFilter(&q, &args, "subject", subject)
 	if onlyActive {
 		q += ` AND superseded_by IS NULL`
+		appendUnexpiredFilter(&q, &args, "")
 	}
 	q += ` ORDER BY id`
 
@@ -972,37 +1276,42 @@ func (s *SQLiteStore) BySubject(ctx context.Context, subject string, onlyActive
 	if err != nil {
 		return nil, fmt.Errorf("memstore: querying by subject: %w", err)
 	}
-	defer rows.Close()
-
-	return scanFacts(rows)
+	facts, err := scanFacts(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	return facts, attachTags(ctx, s.db, facts)
 }
 
-// Exists checks whether a fact with the same content and subject exists.
+// Exists checks whether a fact with the same content exists under subject's
+// alias group, so a fact filed under the canonical subject is found by its
+// alias.
 func (s *SQLiteStore) Exists(ctx context.Context, content, subject string) (bool, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	q := `SELECT COUNT(*) FROM memstore_facts WHERE content = ? AND namespace = ?` + notDeleted("")
+	args := []any{content, s.namespace}
+	s.appendSubjectFilter(&q, &args, "subject", subject)
 	var count int
-	err := s.db.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM memstore_facts WHERE content = ? AND subject = ? AND namespace = ?`,
-		content, subject, s.namespace,
-	).Scan(&count)
+	err := s.db.QueryRowContext(ctx, q, args...).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("memstore: checking existence: %w", err)
 	}
 	return count > 0, nil
 }
 
-// ActiveCount returns the number of non-superseded facts.
+// ActiveCount returns the number of non-superseded, unexpired facts.
 func (s *SQLiteStore) ActiveCount(ctx context.Context) (int64, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	q := `SELECT COUNT(*) FROM memstore_facts WHERE superseded_by IS NULL AND namespace = ?` + notDeleted("")
+	args := []any{s.namespace}
+	appendUnexpiredFilter(&q, &args, "")
 	var count int64
-	err := s.db.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM memstore_facts WHERE superseded_by IS NULL AND namespace = ?`,
-		s.namespace,
-	).Scan(&count)
+	err := s.db.QueryRowContext(ctx, q, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("memstore: counting active facts: %w", err)
 	}
@@ -1021,7 +1330,7 @@ func (s *SQLiteStore) NeedingEmbedding(ctx context.Context, limit int) ([]Fact,
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT `+factColumns+`
 		 FROM memstore_facts
-		 WHERE embedding IS NULL AND embed_failed_at IS NULL AND namespace = ?
+		 WHERE embedding IS NULL AND embed_failed_at IS NULL AND namespace = ?`+notDeleted("")+`
 		 ORDER BY id LIMIT ?`,
 		s.namespace, limit,
 	)
@@ -1100,7 +1409,7 @@ func (s *SQLiteStore) EmbedFacts(ctx context.Context, batchSize int) (int, error
 		defer s.mu.RUnlock()
 
 		rows, err := s.db.QueryContext(ctx,
-			`SELECT id, content FROM memstore_facts WHERE embedding IS NULL AND namespace = ? ORDER BY id`,
+			`SELECT id, content FROM memstore_facts WHERE embedding IS NULL AND namespace = ?`+notDeleted("")+` ORDER BY id`,
 			s.namespace)
 		if err != nil {
 			return fmt.Errorf("memstore: querying unembedded facts: %w", err)
@@ -1257,6 +1566,28 @@ func appendMetadataFilters(q *string, args *[]any, alias string, filters []Metad
 	return nil
 }
 
+// appendUnexpiredFilter excludes facts whose ExpiresAt has passed. It is part
+// of every OnlyActive filter alongside superseded_by IS NULL.
+func appendUnexpiredFilter(q *string, args *[]any, alias string) {
+	*q += fmt.Sprintf(` AND (%[1]sexpires_at IS NULL OR %[1]sexpires_at > ?)`, alias)
+	*args = append(*args, time.Now().UTC().Format(time.RFC3339))
+}
+
+// formatOptionalTime renders an optional timestamp for a nullable TEXT column.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
+
+// notDeleted returns the predicate excluding facts in the trash. Every fact
+// read applies it except Trash itself.
+func notDeleted(alias string) string {
+	return ` AND ` + alias + `deleted_at IS NULL`
+}
+
 // appendTemporalFilters adds created_at range conditions to the query.
 // The alias (e.g., "f." or "") is prepended to the column name.
 func appendTemporalFilters(q *string, args *[]any, alias string, after, before *time.Time) {
@@ -1294,7 +1625,7 @@ func (s *SQLiteStore) History(ctx context.Context, id int64, subject string) ([]
 func (s *SQLiteStore) historyByID(ctx context.Context, id int64) ([]HistoryEntry, error) {
 	// Start by fetching the anchor fact.
 	row := s.db.QueryRowContext(ctx,
-		`SELECT `+factColumns+` FROM memstore_facts WHERE id = ? AND namespace = ?`, id, s.namespace)
+		`SELECT `+factColumns+` FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""), id, s.namespace)
 	anchor, err := scanFact(row)
 	if err != nil {
 		return nil, fmt.Errorf("memstore: fact %d not found: %w", id, err)
@@ -1306,7 +1637,7 @@ func (s *SQLiteStore) historyByID(ctx context.Context, id int64) ([]HistoryEntry
 	current := anchor.ID
 	for {
 		row := s.db.QueryRowContext(ctx,
-			`SELECT `+factColumns+` FROM memstore_facts WHERE superseded_by = ? AND namespace = ?`,
+			`SELECT `+factColumns+` FROM memstore_facts WHERE superseded_by = ? AND namespace = ?`+notDeleted(""),
 			current, s.namespace)
 		pred, err := scanFact(row)
 		if err != nil {
@@ -1334,7 +1665,7 @@ func (s *SQLiteStore) historyByID(ctx context.Context, id int64) ([]HistoryEntry
 		// Walk until the chain ends or repeats.
 		for !visited[next] {
 			row := s.db.QueryRowContext(ctx,
-				`SELECT `+factColumns+` FROM memstore_facts WHERE id = ? AND namespace = ?`,
+				`SELECT `+factColumns+` FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""),
 				next, s.namespace)
 			succ, err := scanFact(row)
 			if err != nil {
@@ -1360,7 +1691,7 @@ func (s *SQLiteStore) historyByID(ctx context.Context, id int64) ([]HistoryEntry
 // historyBySubject returns all facts for a subject, including superseded ones.
 func (s *SQLiteStore) historyBySubject(ctx context.Context, subject string) ([]HistoryEntry, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT `+factColumns+` FROM memstore_facts WHERE subject = ? AND namespace = ? ORDER BY created_at, id`,
+		`SELECT `+factColumns+` FROM memstore_facts WHERE subject = ? AND namespace = ?`+notDeleted("")+` ORDER BY created_at, id`,
 		subject, s.namespace)
 	if err != nil {
 		return nil, fmt.Errorf("memstore: history by subject: %w", err)
@@ -1385,8 +1716,9 @@ func (s *SQLiteStore) ListSubsystems(ctx context.Context, subject string) ([]str
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	q := `SELECT DISTINCT subsystem FROM memstore_facts WHERE namespace = ? AND superseded_by IS NULL AND subsystem != ''`
+	q := `SELECT DISTINCT subsystem FROM memstore_facts WHERE namespace = ? AND superseded_by IS NULL AND subsystem != ''` + notDeleted("")
 	args := []any{s.namespace}
+	appendUnexpiredFilter(&q, &args, "")
 	if subject != "" {
 		q += ` AND subject = ?`
 		args = append(args, subject)
@@ -1419,6 +1751,10 @@ func (s *SQLiteStore) Close() error {
 
 const linkColumns = `id, namespace, source_id, target_id, link_type, bidirectional, label, metadata, created_at`
 
+// linkEndpointsLive hides links with a trashed endpoint. The rows stay, so
+// restoring the fact brings its links back.
+const linkEndpointsLive = ` AND NOT EXISTS (SELECT 1 FROM memstore_facts lf WHERE lf.id IN (source_id, target_id) AND lf.deleted_at IS NOT NULL)`
+
 func scanLink(r scanner) (*Link, error) {
 	var l Link
 	var bidi int
@@ -1484,13 +1820,14 @@ func (s *SQLiteStore) LinkFacts(ctx context.Context, sourceID, targetID int64, l
 }
 
 // GetLink retrieves a single link by ID. Returns (nil, nil) when no link with
-// that ID exists in this namespace, matching Get's not-found contract.
+// that ID exists in this namespace, or one of its facts is in the trash,
+// matching Get's not-found contract.
 func (s *SQLiteStore) GetLink(ctx context.Context, linkID int64) (*Link, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	row := s.db.QueryRowContext(ctx,
-		`SELECT `+linkColumns+` FROM memstore_links WHERE id = ? AND namespace = ?`,
+		`SELECT `+linkColumns+` FROM memstore_links WHERE id = ? AND namespace = ?`+linkEndpointsLive,
 		linkID, s.namespace,
 	)
 	l, err := scanLink(row)
@@ -1526,6 +1863,8 @@ func (s *SQLiteStore) GetLinks(ctx context.Context, factID int64, direction Link
 		args = []any{s.namespace, factID, factID}
 	}
 
+	q += linkEndpointsLive
+
 	if len(linkTypes) > 0 {
 		placeholders := "?" + strings.Repeat(", ?", len(linkTypes)-1)
 		q += ` AND link_type IN (` + placeholders + `)`
@@ -1630,7 +1969,9 @@ type scanner interface {
 	Scan(dest ...any) error
 }
 
-func scanFact(row scanner) (*Fact, error) {
+// scanFact scans a row selected with factColumns. Any extra destinations
+// receive the columns selected after them, such as a search score.
+func scanFact(row scanner, extra ...any) (*Fact, error) {
 	var f Fact
 	var userID sql.NullInt64
 	var metadata sql.NullString
@@ -1638,16 +1979,24 @@ func scanFact(row scanner) (*Fact, error) {
 	var supersededAt sql.NullString
 	var lastConfirmedAt sql.NullString
 	var lastUsedAt sql.NullString
+	var expiresAt sql.NullString
+	var archivedAt sql.NullString
+	var deletedAt sql.NullString
 	var embBlob []byte
 	var createdAt string
+	var documentID sql.NullInt64
 
-	err := row.Scan(
+	dest := []any{
 		&f.ID, &f.Namespace, &userID, &f.Content, &f.Subject, &f.Category, &f.Kind, &f.Subsystem,
 		&metadata, &supersededBy, &supersededAt,
 		&f.ConfirmedCount, &lastConfirmedAt,
 		&f.UseCount, &lastUsedAt,
+		&expiresAt, &archivedAt, &deletedAt,
 		&embBlob, &createdAt,
-	)
+		&f.Provenance.Source, &f.Provenance.SessionID, &f.Provenance.TurnFirst, &f.Provenance.TurnLast,
+		&f.Provenance.Origin, &documentID,
+	}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -1671,10 +2020,23 @@ func scanFact(row scanner) (*Fact, error) {
 		t, _ := time.Parse(time.RFC3339, lastUsedAt.String)
 		f.LastUsedAt = &t
 	}
+	if expiresAt.Valid {
+		t, _ := time.Parse(time.RFC3339, expiresAt.String)
+		f.ExpiresAt = &t
+	}
+	if archivedAt.Valid {
+		t, _ := time.Parse(time.RFC3339, archivedAt.String)
+		f.ArchivedAt = &t
+	}
+	if deletedAt.Valid {
+		t, _ := time.Parse(time.RFC3339, deletedAt.String)
+		f.DeletedAt = &t
+	}
 	if len(embBlob) > 0 {
 		f.Embedding = embedding.DecodeFloat32s(embBlob)
 	}
 	f.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	f.Provenance.DocumentID = documentID.Int64
 
 	return &f, nil
 }
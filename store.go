@@ -3,6 +3,7 @@ package memstore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -14,6 +15,11 @@ import (
 // level by both pgstore and sqlite stores.
 const MaxContentLength = 8000
 
+// ErrFactExpired is returned by Revise for a fact whose ExpiresAt has passed.
+// The revision would inherit the expiry and be inactive the moment it was
+// written.
+var ErrFactExpired = errors.New("memstore: an expired fact cannot be revised; store a new fact instead")
+
 // Fact represents a single factual claim in the knowledge store.
 type Fact struct {
 	ID              int64
@@ -31,8 +37,13 @@ type Fact struct {
 	LastConfirmedAt *time.Time      // when last confirmed
 	UseCount        int             // auto-incremented when retrieved via search
 	LastUsedAt      *time.Time      // when last retrieved
+	ExpiresAt       *time.Time      // optional; once passed, the fact is no longer active (see ExpiryReaper)
+	ArchivedAt      *time.Time      // when the expiry reaper archived the fact
+	DeletedAt       *time.Time      // when Delete moved the fact to the trash; set only on Trash results
 	Embedding       []float32       // nil until computed
 	CreatedAt       time.Time
+	Provenance      Provenance // where the fact came from; stamped on insert, immutable (see ResolveProvenance)
+	Tags            []string   // normalized, sorted labels (see NormalizeTags); written on insert, carried to revisions and merges
 }
 
 // MetadataFilter applies a condition on a JSON metadata field.
@@ -42,10 +53,10 @@ type Fact struct {
 // metadata or missing keys are excluded by comparison semantics
 // unless IncludeNull is set.
 type MetadataFilter struct {
-	Key         string // JSON field name (e.g., "chapter", "is_draft")
-	Op          string // comparison operator
-	Value       any    // value to compare against
-	IncludeNull bool   // if true, also match rows where Key is absent or metadata is NULL
+	Key         string `json:"key"`                    // JSON field name (e.g., "chapter", "is_draft")
+	Op          string `json:"op"`                     // comparison operator
+	Value       any    `json:"value"`                  // value to compare against
+	IncludeNull bool   `json:"include_null,omitempty"` // if true, also match rows where Key is absent or metadata is NULL
 }
 
 // RerankMode selects how a second-stage cross-encoder rerank score is fused
@@ -102,12 +113,13 @@ type SearchOpts struct {
 	Category        string                   // filter (empty = all)
 	Kind            string                   // filter by kind (empty = all)
 	Subsystem       string                   // filter by subsystem (empty = all)
-	OnlyActive      bool                     // exclude superseded
+	OnlyActive      bool                     // exclude superseded and expired
 	AllNamespaces   bool                     // search across all namespaces (ignores Namespaces field)
 	Namespaces      []string                 // search only these namespaces; empty means the store's own namespace
 	MetadataFilters []MetadataFilter         // filter on metadata JSON fields
 	CreatedAfter    *time.Time               // exclude facts created before this time
 	CreatedBefore   *time.Time               // exclude facts created after this time
+	Tags            TagFilter                // filter on tag membership (zero = no filter)
 	DecayHalfLife   time.Duration            // if >0, default exponential time decay for combined scores
 	CategoryDecay   map[string]time.Duration // per-category half-life overrides; 0 = no decay for that category
 	FTSWeight       float64                  // default 0.6
@@ -153,17 +165,19 @@ type SearchResult struct {
 
 // QueryOpts controls filtering for List queries.
 type QueryOpts struct {
-	Subject         string           // filter by subject (empty = all)
-	Category        string           // filter by category (empty = all)
-	Kind            string           // filter by kind (empty = all)
-	Subsystem       string           // filter by subsystem (empty = all)
-	OnlyActive      bool             // exclude superseded
-	Namespaces      []string         // list only these namespaces; empty means the store's own namespace
-	MetadataFilters []MetadataFilter // filter on metadata JSON fields
-	CreatedAfter    *time.Time       // exclude facts created before this time
-	CreatedBefore   *time.Time       // exclude facts created after this time
-	Limit           int              // max results (0 = no limit)
-	IDs             []int64          // fetch only these specific fact IDs (empty = no filter)
+	Subject         string           `json:"subject,omitempty"`          // filter by subject (empty = all)
+	Category        string           `json:"category,omitempty"`         // filter by category (empty = all)
+	Kind            string           `json:"kind,omitempty"`             // filter by kind (empty = all)
+	Subsystem       string           `json:"subsystem,omitempty"`        // filter by subsystem (empty = all)
+	OnlyActive      bool             `json:"only_active,omitempty"`      // exclude superseded and expired
+	Namespaces      []string         `json:"namespaces,omitempty"`       // list only these namespaces; empty means the store's own namespace
+	MetadataFilters []MetadataFilter `json:"metadata_filters,omitempty"` // filter on metadata JSON fields
+	CreatedAfter    *time.Time       `json:"created_after,omitempty"`    // exclude facts created before this time
+	CreatedBefore   *time.Time       `json:"created_before,omitempty"`   // exclude facts created after this time
+	Limit           int              `json:"limit,omitempty"`            // max results (0 = no limit)
+	IDs             []int64          `json:"ids,omitempty"`              // fetch only these specific fact IDs (empty = no filter)
+	Provenance      ProvenanceFilter `json:"provenance,omitzero"`        // filter on typed provenance (zero = no filter)
+	Tags            TagFilter        `json:"tags,omitzero"`              // filter on tag membership (zero = no filter)
 }
 
 // HistoryEntry wraps a Fact with its position in a supersession chain.
@@ -205,8 +219,24 @@ type Store interface {
 	Insert(ctx context.Context, f Fact) (int64, error)
 	InsertBatch(ctx context.Context, facts []Fact) error
 	Supersede(ctx context.Context, oldID, newID int64) error
+	// Revise atomically replaces a fact's content: it inserts a new version
+	// that keeps the old one's subject, category, kind, subsystem, expiry,
+	// links, tags, and metadata (with patch merged in as in UpdateMetadata),
+	// then supersedes the old fact. Only an active fact can be revised; an
+	// expired one returns ErrFactExpired. Returns the new version's ID.
+	Revise(ctx context.Context, id int64, content string, patch map[string]any) (int64, error)
+	// Merge atomically consolidates two or more active facts into a new one
+	// with the given content. The new fact takes its subject, category, kind,
+	// subsystem, and metadata (with patch merged in) from the first ID,
+	// carries every source's tags, and expires only if every source does, at
+	// the latest deadline. The source IDs are recorded under MergedFromKey in
+	// its metadata, every link touching a source is re-pointed at it, and
+	// every source is superseded by it. Returns the new fact's ID.
+	Merge(ctx context.Context, ids []int64, content string, patch map[string]any) (int64, error)
 	Confirm(ctx context.Context, id int64) error
 	Touch(ctx context.Context, ids []int64) error // bump use_count for retrieved facts
+	// Delete moves a fact to the trash. A trashed fact, and every link
+	// touching it, is invisible to all other methods until Restore.
 	Delete(ctx context.Context, id int64) error
 	// UpdateMetadata merges a patch into the fact's existing metadata JSON.
 	// Keys with non-nil values are set; keys with nil values are deleted.
@@ -224,6 +254,19 @@ type Store interface {
 	// it returns all facts for that subject ordered by creation time.
 	History(ctx context.Context, id int64, subject string) ([]HistoryEntry, error)
 
+	// Soft deletion
+	// Trash lists deleted facts, most recently deleted first. Subject,
+	// Category, Kind, Subsystem, and Limit filter as in List; the rest of
+	// opts is ignored.
+	Trash(ctx context.Context, opts QueryOpts) ([]Fact, error)
+	// Restore takes a fact out of the trash, and with it its links.
+	Restore(ctx context.Context, id int64) error
+	// Purge permanently removes facts deleted at or before olderThan, along
+	// with their links. A purged fact's superseded predecessors are removed
+	// with it, so no chain is left pointing at a missing fact. Returns the
+	// number of facts removed.
+	Purge(ctx context.Context, olderThan time.Time) (int64, error)
+
 	// Hybrid search (FTS5 + vector); requires an embedder.
 	Search(ctx context.Context, query string, opts SearchOpts) ([]SearchResult, error)
 	// SearchBatch shares a single batched embedding call across queries.
@@ -237,6 +280,23 @@ type Store interface {
 	// optionally filtered by subject (empty = all subjects).
 	ListSubsystems(ctx context.Context, subject string) ([]string, error)
 
+	// Tags — labels attached to facts, many-to-many. Tags are normalized
+	// with NormalizeTags; a tag exists while some fact carries it.
+	// TagFact adds tags to a fact; tags it already has are left alone.
+	TagFact(ctx context.Context, id int64, tags ...string) error
+	// UntagFact removes tags from a fact; tags it does not have are ignored.
+	UntagFact(ctx context.Context, id int64, tags ...string) error
+	// TagCounts returns every tag on an active fact with the number of
+	// active facts carrying it, most used first, optionally filtered by
+	// subject (empty = all subjects).
+	TagCounts(ctx context.Context, subject string) ([]TagCount, error)
+	// RenameTag renames a tag on every fact, merging it into to on facts
+	// that carry both. Returns the number of facts changed.
+	RenameTag(ctx context.Context, from, to string) (int64, error)
+	// DeleteTag removes a tag from every fact. Returns the number of facts
+	// it was removed from.
+	DeleteTag(ctx context.Context, tag string) (int64, error)
+
 	// Embedding pipeline
 	NeedingEmbedding(ctx context.Context, limit int) ([]Fact, error)
 	SetEmbedding(ctx context.Context, id int64, emb []float32) error
@@ -0,0 +1,418 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxTagLength caps the length of a tag in bytes.
+const MaxTagLength = 64
+
+// TagCount is a tag with the number of active facts carrying it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TagFilter restricts a query by tag membership. Empty fields match
+// anything; tags are compared after NormalizeTag.
+type TagFilter struct {
+	Any  []string `json:"any,omitempty"`  // at least one of these
+	All  []string `json:"all,omitempty"`  // every one of these
+	None []string `json:"none,omitempty"` // none of these
+}
+
+// IsZero reports whether the filter matches every fact.
+func (tf TagFilter) IsZero() bool {
+	return len(tf.Any) == 0 && len(tf.All) == 0 && len(tf.None) == 0
+}
+
+// NormalizeTag returns the stored form of a tag: trimmed and lower-cased.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes tags, drops duplicates and validates the rest:
+// a tag is non-empty, at most MaxTagLength bytes, and holds no whitespace
+// or commas, so a comma-separated list of tags is unambiguous.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, raw := range tags {
+		t := NormalizeTag(raw)
+		if t == "" {
+			return nil, errors.New("memstore: empty tag")
+		}
+		if len(t) > MaxTagLength {
+			return nil, fmt.Errorf("memstore: tag %q is longer than %d bytes", t, MaxTagLength)
+		}
+		if strings.ContainsFunc(t, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			return nil, fmt.Errorf("memstore: tag %q contains a comma or whitespace", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// normalizeFilterTags normalizes the tags of a filter for comparison.
+// Unlike NormalizeTags it never fails: a tag that could not have been
+// stored simply matches nothing.
+func normalizeFilterTags(tags []string) []any {
+	out := make([]any, len(tags))
+	for i, t := range tags {
+		out[i] = NormalizeTag(t)
+	}
+	return out
+}
+
+// appendTagFilter appends WHERE clauses for tf to q. alias is a table alias
+// prefix such as "f." or "".
+func appendTagFilter(q *string, args *[]any, alias string, tf TagFilter) {
+	has := `SELECT 1 FROM memstore_fact_tags ft WHERE ft.fact_id = ` + alias + `id AND ft.tag IN (`
+	if len(tf.Any) > 0 {
+		in, inArgs := placeholders(normalizeFilterTags(tf.Any))
+		*q += ` AND EXISTS (` + has + in + `))`
+		*args = append(*args, inArgs...)
+	}
+	if len(tf.All) > 0 {
+		all := normalizeFilterTags(tf.All)
+		in, inArgs := placeholders(all)
+		*q += ` AND (SELECT COUNT(DISTINCT ft.tag) FROM memstore_fact_tags ft WHERE ft.fact_id = ` + alias + `id AND ft.tag IN (` + in + `)) = ?`
+		*args = append(append(*args, inArgs...), distinctCount(all))
+	}
+	if len(tf.None) > 0 {
+		in, inArgs := placeholders(normalizeFilterTags(tf.None))
+		*q += ` AND NOT EXISTS (` + has + in + `))`
+		*args = append(*args, inArgs...)
+	}
+}
+
+// placeholders returns "?, ?, ..." for vals and vals themselves.
+func placeholders(vals []any) (string, []any) {
+	return "?" + strings.Repeat(", ?", len(vals)-1), vals
+}
+
+// distinctCount returns the number of distinct values in vals.
+func distinctCount(vals []any) int {
+	seen := make(map[any]bool, len(vals))
+	for _, v := range vals {
+		seen[v] = true
+	}
+	return len(seen)
+}
+
+// migrateV21 creates the fact-tag table and backfills it from the ad-hoc
+// "tags" arrays facts kept in their metadata. The metadata is left as it
+// was; string elements that are not valid tags are skipped.
+func (s *SQLiteStore) migrateV21() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS memstore_fact_tags (
+		fact_id INTEGER NOT NULL REFERENCES memstore_facts(id) ON DELETE CASCADE,
+		tag     TEXT NOT NULL,
+		PRIMARY KEY (fact_id, tag)
+	)`); err != nil {
+		return fmt.Errorf("memstore V21 migration: %w", err)
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_memstore_fact_tags_tag ON memstore_fact_tags(tag)`); err != nil {
+		return fmt.Errorf("memstore V21 migration: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT f.id, j.value FROM memstore_facts f, json_each(f.metadata, '$.tags') j
+		WHERE json_valid(f.metadata) AND json_type(f.metadata, '$.tags') = 'array' AND j.type = 'text'`)
+	if err != nil {
+		return fmt.Errorf("memstore V21 migration: reading metadata tags: %w", err)
+	}
+	type factTag struct {
+		id  int64
+		tag string
+	}
+	var backfill []factTag
+	for rows.Next() {
+		var ft factTag
+		if err := rows.Scan(&ft.id, &ft.tag); err != nil {
+			rows.Close()
+			return fmt.Errorf("memstore V21 migration: scanning metadata tags: %w", err)
+		}
+		if tags, err := NormalizeTags([]string{ft.tag}); err == nil {
+			ft.tag = tags[0]
+			backfill = append(backfill, ft)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("memstore V21 migration: reading metadata tags: %w", err)
+	}
+	for _, ft := range backfill {
+		if _, err := s.db.Exec(`INSERT OR IGNORE INTO memstore_fact_tags (fact_id, tag) VALUES (?, ?)`, ft.id, ft.tag); err != nil {
+			return fmt.Errorf("memstore V21 migration: backfilling tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// sqlExecer is the part of *sql.DB and *sql.Tx the tag helpers need.
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// insertTags attaches already-normalized tags to fact id.
+func insertTags(ctx context.Context, db sqlExecer, id int64, tags []string) error {
+	for _, t := range tags {
+		if _, err := db.ExecContext(ctx,
+			`INSERT OR IGNORE INTO memstore_fact_tags (fact_id, tag) VALUES (?, ?)`, id, t); err != nil {
+			return fmt.Errorf("memstore: tagging fact %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// loadTags returns the tags of each of ids that has any, sorted.
+func loadTags(ctx context.Context, db sqlExecer, ids []int64) (map[int64][]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	in, args := idList(ids)
+	rows, err := db.QueryContext(ctx,
+		`SELECT fact_id, tag FROM memstore_fact_tags WHERE fact_id IN (`+in+`) ORDER BY fact_id, tag`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: loading tags: %w", err)
+	}
+	defer rows.Close()
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			id  int64
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("memstore: scanning tag: %w", err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, rows.Err()
+}
+
+// attachTags sets Tags on each fact.
+func attachTags(ctx context.Context, db sqlExecer, facts []Fact) error {
+	ids := make([]int64, len(facts))
+	for i, f := range facts {
+		ids[i] = f.ID
+	}
+	tags, err := loadTags(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range facts {
+		facts[i].Tags = tags[facts[i].ID]
+	}
+	return nil
+}
+
+// attachResultTags sets Tags on the fact of each search result.
+func attachResultTags(ctx context.Context, db sqlExecer, results []SearchResult) error {
+	ids := make([]int64, len(results))
+	for i, r := range results {
+		ids[i] = r.Fact.ID
+	}
+	tags, err := loadTags(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Fact.Tags = tags[results[i].Fact.ID]
+	}
+	return nil
+}
+
+// TagFact implements Store.
+func (s *SQLiteStore) TagFact(ctx context.Context, id int64, tags ...string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return errors.New("memstore: no tags given")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var n int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""),
+		id, s.namespace).Scan(&n); err != nil {
+		return fmt.Errorf("memstore: looking up fact %d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("memstore: fact %d not found", id)
+	}
+	if err := insertTags(ctx, tx, id, tags); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("memstore: committing tags: %w", err)
+	}
+	return nil
+}
+
+// UntagFact implements Store.
+func (s *SQLiteStore) UntagFact(ctx context.Context, id int64, tags ...string) error {
+	if len(tags) == 0 {
+		return errors.New("memstore: no tags given")
+	}
+	in, args := placeholders(normalizeFilterTags(tags))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM memstore_facts WHERE id = ? AND namespace = ?`+notDeleted(""),
+		id, s.namespace).Scan(&n); err != nil {
+		return fmt.Errorf("memstore: looking up fact %d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("memstore: fact %d not found", id)
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM memstore_fact_tags WHERE fact_id = ? AND tag IN (`+in+`)`,
+		append([]any{id}, args...)...); err != nil {
+		return fmt.Errorf("memstore: untagging fact %d: %w", id, err)
+	}
+	return nil
+}
+
+// TagCounts implements Store.
+func (s *SQLiteStore) TagCounts(ctx context.Context, subject string) ([]TagCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q := `SELECT ft.tag, COUNT(*) FROM memstore_fact_tags ft JOIN memstore_facts f ON f.id = ft.fact_id
+	      WHERE f.namespace = ? AND f.superseded_by IS NULL` + notDeleted("f.")
+	args := []any{s.namespace}
+	appendUnexpiredFilter(&q, &args, "f.")
+	if subject != "" {
+		s.appendSubjectFilter(&q, &args, "f.subject", subject)
+	}
+	rows, err := s.db.QueryContext(ctx, q+` GROUP BY ft.tag ORDER BY COUNT(*) DESC, ft.tag`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: counting tags: %w", err)
+	}
+	defer rows.Close()
+
+	var out []TagCount
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, fmt.Errorf("memstore: scanning tag count: %w", err)
+		}
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+// RenameTag implements Store.
+func (s *SQLiteStore) RenameTag(ctx context.Context, from, to string) (int64, error) {
+	tags, err := NormalizeTags([]string{to})
+	if err != nil {
+		return 0, err
+	}
+	from, to = NormalizeTag(from), tags[0]
+	if from == to {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Facts that already carry the new tag just lose the old one.
+	scope := `SELECT id FROM memstore_facts WHERE namespace = ?`
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO memstore_fact_tags (fact_id, tag)
+		 SELECT fact_id, ? FROM memstore_fact_tags WHERE tag = ? AND fact_id IN (`+scope+`)`,
+		to, from, s.namespace); err != nil {
+		return 0, fmt.Errorf("memstore: renaming tag %q: %w", from, err)
+	}
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM memstore_fact_tags WHERE tag = ? AND fact_id IN (`+scope+`)`, from, s.namespace)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: renaming tag %q: %w", from, err)
+	}
+	n, _ := res.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("memstore: committing tag rename: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteTag implements Store.
+func (s *SQLiteStore) DeleteTag(ctx context.Context, tag string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM memstore_fact_tags WHERE tag = ? AND fact_id IN (SELECT id FROM memstore_facts WHERE namespace = ?)`,
+		NormalizeTag(tag), s.namespace)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: deleting tag %q: %w", tag, err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// copyNamespaceTags copies the tags of facts copied to another namespace,
+// re-keyed by newID (old fact ID -> copy).
+func copyNamespaceTags(ctx context.Context, tx *sql.Tx, from string, newID map[int64]int64) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT ft.fact_id, ft.tag FROM memstore_fact_tags ft
+		 JOIN memstore_facts f ON f.id = ft.fact_id WHERE f.namespace = ?`, from)
+	if err != nil {
+		return fmt.Errorf("memstore: reading tags of namespace %q: %w", from, err)
+	}
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			id  int64
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			rows.Close()
+			return fmt.Errorf("memstore: scanning tag: %w", err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("memstore: reading tags of namespace %q: %w", from, err)
+	}
+	for old, ts := range tags {
+		if id, ok := newID[old]; ok {
+			if err := insertTags(ctx, tx, id, ts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
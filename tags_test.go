@@ -0,0 +1,132 @@
+package memstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := memstore.NormalizeTags([]string{" Perf", "perf", "TODO"})
+	if err != nil || !slices.Equal(got, []string{"perf", "todo"}) {
+		t.Errorf("NormalizeTags = %v, %v; want [perf todo]", got, err)
+	}
+	for _, bad := range []string{"", "  ", "two words", "a,b", string(make([]byte, memstore.MaxTagLength+1))} {
+		if _, err := memstore.NormalizeTags([]string{bad}); err == nil {
+			t.Errorf("NormalizeTags(%q) accepted an invalid tag", bad)
+		}
+	}
+}
+
+// TestMigrateV21_BackfillsMetadataTags verifies that opening a pre-tags
+// database moves the ad-hoc metadata "tags" arrays into the tag table and
+// leaves the metadata alone.
+func TestMigrateV21_BackfillsMetadataTags(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	s, err := memstore.NewSQLiteStore(db, nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.Insert(ctx, memstore.Fact{Content: "legacy", Subject: "x", Category: "note",
+		Metadata: json.RawMessage(`{"tags":["Perf","perf","two words",3,"todo"]}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := s.Insert(ctx, memstore.Fact{Content: "no tags", Subject: "x", Category: "note",
+		Metadata: json.RawMessage(`{"tags":"perf"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Roll the schema back to V20.
+	for _, stmt := range []string{`DROP TABLE memstore_fact_tags`, `ALTER TABLE memstore_facts DROP COLUMN audit_attempts`, `UPDATE memstore_version SET version = 20`} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	s, err = memstore.NewSQLiteStore(db, nil, "test")
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	f, err := s.Get(ctx, id)
+	if err != nil || f == nil {
+		t.Fatalf("Get = %+v, %v", f, err)
+	}
+	if !slices.Equal(f.Tags, []string{"perf", "todo"}) {
+		t.Errorf("backfilled tags = %v, want [perf todo]", f.Tags)
+	}
+	if string(f.Metadata) != `{"tags":["Perf","perf","two words",3,"todo"]}` {
+		t.Errorf("metadata = %s, want it unchanged", f.Metadata)
+	}
+	if f, _ := s.Get(ctx, plain); len(f.Tags) != 0 {
+		t.Errorf("non-array metadata tags backfilled as %v", f.Tags)
+	}
+}
+
+func TestExportImport_Tags(t *testing.T) {
+	ctx := context.Background()
+	srcDB := openTestDB(t)
+	src, err := memstore.NewSQLiteStore(srcDB, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Insert(ctx, memstore.Fact{Content: "tagged", Subject: "p", Category: "note", Tags: []string{"perf", "todo"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := memstore.Export(ctx, srcDB)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(data.Facts) != 1 || !slices.Equal(data.Facts[0].Tags, []string{"perf", "todo"}) {
+		t.Fatalf("exported facts = %+v, want the tags", data.Facts)
+	}
+
+	dstDB := openTestDB(t)
+	if _, err := memstore.Import(ctx, dstDB, data, memstore.ImportOpts{}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	dst, err := memstore.NewSQLiteStore(dstDB, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	facts, err := dst.List(ctx, memstore.QueryOpts{Tags: memstore.TagFilter{All: []string{"perf", "todo"}}})
+	if err != nil || len(facts) != 1 {
+		t.Fatalf("imported facts = %+v, %v; want the tagged fact", facts, err)
+	}
+}
+
+func TestSQLiteCopyNamespace_Tags(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	a, err := memstore.NewSQLiteStore(db, nil, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Insert(ctx, memstore.Fact{Content: "tagged", Subject: "p", Category: "note", Tags: []string{"perf"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.CopyNamespace(ctx, "a", "b"); err != nil {
+		t.Fatalf("CopyNamespace: %v", err)
+	}
+	b, err := memstore.NewSQLiteStore(db, nil, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := b.TagCounts(ctx, "")
+	if err != nil || len(counts) != 1 || counts[0] != (memstore.TagCount{Tag: "perf", Count: 1}) {
+		t.Errorf("copied namespace tag counts = %+v, %v; want perf once", counts, err)
+	}
+
+	// Tags are per namespace: deleting one in the copy leaves the source alone.
+	if _, err := b.DeleteTag(ctx, "perf"); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := a.TagCounts(ctx, ""); len(counts) != 1 {
+		t.Errorf("source namespace tag counts = %+v, want perf kept", counts)
+	}
+}
@@ -0,0 +1,612 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tasks are facts with kind "task" whose metadata carries their status,
+// scope, priority and the optional project, owner and due date. A task that
+// cannot start until another is done is linked to it with a LinkBlockedBy
+// link; the helpers in this file derive each task's state from those links
+// and keep the graph acyclic.
+
+// LinkBlockedBy is the link type recording a task dependency: the source
+// task is blocked by the target task until the target is completed or
+// cancelled.
+const LinkBlockedBy = "blocked_by"
+
+// Task statuses, stored under the "status" metadata key.
+const (
+	TaskPending    = "pending"
+	TaskInProgress = "in_progress"
+	TaskCompleted  = "completed"
+	TaskCancelled  = "cancelled"
+)
+
+// TaskStatusValid reports whether status is one of the task statuses.
+func TaskStatusValid(status string) bool {
+	switch status {
+	case TaskPending, TaskInProgress, TaskCompleted, TaskCancelled:
+		return true
+	}
+	return false
+}
+
+// taskClosed reports whether status ends a task. A closed blocker no
+// longer blocks: cancelling a prerequisite releases its dependents just as
+// completing it does.
+func taskClosed(status string) bool {
+	return status == TaskCompleted || status == TaskCancelled
+}
+
+// TaskState is a task's place on the board, derived from its status and
+// its blockers.
+type TaskState string
+
+const (
+	TaskStateBlocked    TaskState = "blocked"     // open, with an open blocker
+	TaskStateReady      TaskState = "ready"       // pending, nothing blocking it
+	TaskStateInProgress TaskState = "in_progress" // being worked on, nothing blocking it
+	TaskStateDone       TaskState = "done"        // completed or cancelled
+)
+
+// taskStateOrder is the column order of the board.
+var taskStateOrder = []TaskState{TaskStateBlocked, TaskStateReady, TaskStateInProgress, TaskStateDone}
+
+// ErrNotATask is returned by the task helpers for a fact that is not a task.
+var ErrNotATask = errors.New("memstore: fact is not a task")
+
+// ErrTaskCycle is returned by AddTaskDependency when the new dependency
+// would make a task wait on itself.
+var ErrTaskCycle = errors.New("memstore: task dependency cycle")
+
+// Task is a task fact with its metadata decoded and its state derived.
+type Task struct {
+	Fact      Fact       `json:"fact"`
+	Status    string     `json:"status"`
+	Scope     string     `json:"scope,omitempty"`
+	Priority  string     `json:"priority,omitempty"`
+	Project   string     `json:"project,omitempty"`
+	Owner     string     `json:"owner,omitempty"`
+	Due       string     `json:"due,omitempty"`       // as stored
+	DueAt     *time.Time `json:"due_at,omitempty"`    // Due parsed as a deadline; nil for free-form legacy values
+	Recur     string     `json:"recur,omitempty"`     // recurrence rule; completing the task creates the next occurrence
+	RemindAt  *time.Time `json:"remind_at,omitempty"` // when a deferred task surfaces
+	Surface   string     `json:"surface,omitempty"`
+	BlockedBy []int64    `json:"blocked_by,omitempty"`
+	Blocks    []int64    `json:"blocks,omitempty"`
+	State     TaskState  `json:"state"`
+	Overdue   bool       `json:"overdue,omitempty"`  // open and past DueAt
+	DueSoon   bool       `json:"due_soon,omitempty"` // open, past RemindAt, not yet overdue
+}
+
+// ParseDue parses a task due date: a calendar date (2006-01-02), due by
+// the end of that day in UTC, or an RFC3339 time. It returns the
+// deadline.
+func ParseDue(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.Parse(time.DateOnly, s); err == nil {
+		return d.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("memstore: invalid due date %q: want YYYY-MM-DD or RFC3339", s)
+	}
+	return t.UTC(), nil
+}
+
+// taskFromFact decodes a task fact's metadata. State is left for the
+// caller, which knows the blockers.
+func taskFromFact(f Fact) (Task, bool) {
+	var meta map[string]any
+	if len(f.Metadata) > 0 {
+		json.Unmarshal(f.Metadata, &meta) //nolint:errcheck // malformed metadata reads as not a task
+	}
+	str := func(key string) string {
+		s, _ := meta[key].(string)
+		return s
+	}
+	if str("kind") != "task" && f.Kind != "task" {
+		return Task{}, false
+	}
+	t := Task{
+		Fact:     f,
+		Status:   str("status"),
+		Scope:    str("scope"),
+		Priority: str("priority"),
+		Project:  str("project"),
+		Owner:    str("owner"),
+		Due:      str("due"),
+		Recur:    str("recur"),
+		Surface:  str("surface"),
+	}
+	if t.Due != "" {
+		if d, err := ParseDue(t.Due); err == nil {
+			t.DueAt = &d
+		}
+	}
+	if r, err := time.Parse(time.RFC3339, str("remind_at")); err == nil {
+		t.RemindAt = &r
+	}
+	return t, true
+}
+
+// getTask loads a single task by ID.
+func getTask(ctx context.Context, store Store, id int64) (*Task, error) {
+	f, err := store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("memstore: task %d not found", id)
+	}
+	t, ok := taskFromFact(*f)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNotATask, id)
+	}
+	return &t, nil
+}
+
+// LoadTasks returns the active tasks matching filters (equality filters on
+// task metadata such as status, scope, project or owner), each with its
+// dependencies and derived state, ordered for display: overdue first, then
+// by priority, due date and ID.
+func LoadTasks(ctx context.Context, store Store, filters ...MetadataFilter) ([]Task, error) {
+	return loadTasks(ctx, store, time.Now(), filters)
+}
+
+func loadTasks(ctx context.Context, store Store, now time.Time, filters []MetadataFilter) ([]Task, error) {
+	facts, err := store.List(ctx, QueryOpts{
+		OnlyActive:      true,
+		MetadataFilters: append([]MetadataFilter{{Key: "kind", Op: "=", Value: "task"}}, filters...),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]Task, 0, len(facts))
+	for _, f := range facts {
+		if t, ok := taskFromFact(f); ok {
+			tasks = append(tasks, t)
+		}
+	}
+	if err := resolveTaskStates(ctx, store, now, tasks); err != nil {
+		return nil, err
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+// resolveTaskStates fills in each task's dependencies and derives its
+// state. Blockers outside tasks (filtered out of the listing) are fetched
+// for their status.
+func resolveTaskStates(ctx context.Context, store Store, now time.Time, tasks []Task) error {
+	status := make(map[int64]string, len(tasks))
+	for _, t := range tasks {
+		status[t.Fact.ID] = t.Status
+	}
+	for i := range tasks {
+		links, err := store.GetLinks(ctx, tasks[i].Fact.ID, LinkBoth, LinkBlockedBy)
+		if err != nil {
+			return err
+		}
+		for _, l := range links {
+			switch tasks[i].Fact.ID {
+			case l.SourceID:
+				tasks[i].BlockedBy = append(tasks[i].BlockedBy, l.TargetID)
+			case l.TargetID:
+				tasks[i].Blocks = append(tasks[i].Blocks, l.SourceID)
+			}
+		}
+	}
+
+	var missing []int64
+	for _, t := range tasks {
+		for _, id := range t.BlockedBy {
+			if _, ok := status[id]; !ok {
+				missing = append(missing, id)
+				status[id] = "" // an absent blocker (deleted, superseded) does not block
+			}
+		}
+	}
+	if len(missing) > 0 {
+		facts, err := store.List(ctx, QueryOpts{IDs: missing, OnlyActive: true})
+		if err != nil {
+			return err
+		}
+		for _, f := range facts {
+			if t, ok := taskFromFact(f); ok {
+				status[f.ID] = t.Status
+			}
+		}
+	}
+
+	for i := range tasks {
+		t := &tasks[i]
+		blocked := false
+		for _, id := range t.BlockedBy {
+			if s := status[id]; s != "" && !taskClosed(s) {
+				blocked = true
+			}
+		}
+		switch {
+		case taskClosed(t.Status):
+			t.State = TaskStateDone
+		case blocked:
+			t.State = TaskStateBlocked
+		case t.Status == TaskInProgress:
+			t.State = TaskStateInProgress
+		default:
+			t.State = TaskStateReady
+		}
+		t.Overdue = t.State != TaskStateDone && t.DueAt != nil && now.After(*t.DueAt)
+		t.DueSoon = t.State != TaskStateDone && !t.Overdue && t.RemindAt != nil && !now.Before(*t.RemindAt)
+	}
+	return nil
+}
+
+var taskPriorityRank = map[string]int{"high": 0, "normal": 1, "low": 2}
+
+// sortTasks orders tasks overdue first, then by priority, due date
+// (undated last) and ID.
+func sortTasks(tasks []Task) {
+	rank := func(p string) int {
+		if r, ok := taskPriorityRank[p]; ok {
+			return r
+		}
+		return taskPriorityRank["normal"]
+	}
+	slices.SortStableFunc(tasks, func(a, b Task) int {
+		if a.Overdue != b.Overdue {
+			if a.Overdue {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(rank(a.Priority), rank(b.Priority)); c != 0 {
+			return c
+		}
+		switch {
+		case a.DueAt != nil && b.DueAt != nil:
+			if c := a.DueAt.Compare(*b.DueAt); c != 0 {
+				return c
+			}
+		case a.DueAt != nil:
+			return -1
+		case b.DueAt != nil:
+			return 1
+		}
+		return cmp.Compare(a.Fact.ID, b.Fact.ID)
+	})
+}
+
+// TaskGroup is one column of a grouped task view.
+type TaskGroup struct {
+	Name  string `json:"name"`
+	Tasks []Task `json:"tasks"`
+}
+
+// TaskGroupings lists the keys GroupTasks accepts.
+var TaskGroupings = []string{"state", "project", "owner", "priority", "scope"}
+
+// GroupTasks splits tasks into groups by key, one of TaskGroupings. The
+// "state" grouping is the board: blocked, ready, in progress and done
+// columns in that order, empty columns left out. Other groupings are
+// ordered by name, with tasks lacking the field grouped last under "(none)".
+// Tasks keep their order within a group.
+func GroupTasks(tasks []Task, key string) ([]TaskGroup, error) {
+	var field func(Task) string
+	switch key {
+	case "state":
+		field = func(t Task) string { return string(t.State) }
+	case "project":
+		field = func(t Task) string { return t.Project }
+	case "owner":
+		field = func(t Task) string { return t.Owner }
+	case "priority":
+		field = func(t Task) string { return t.Priority }
+	case "scope":
+		field = func(t Task) string { return t.Scope }
+	default:
+		return nil, fmt.Errorf("memstore: unknown task grouping %q (want one of %s)", key, strings.Join(TaskGroupings, ", "))
+	}
+
+	byName := make(map[string][]Task)
+	for _, t := range tasks {
+		byName[field(t)] = append(byName[field(t)], t)
+	}
+	var names []string
+	if key == "state" {
+		for _, s := range taskStateOrder {
+			names = append(names, string(s))
+		}
+	} else {
+		for name := range byName {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		names = append(names, "")
+	}
+
+	var groups []TaskGroup
+	for _, name := range names {
+		if len(byName[name]) == 0 {
+			continue
+		}
+		label := name
+		if label == "" {
+			label = "(none)"
+		}
+		groups = append(groups, TaskGroup{Name: label, Tasks: byName[name]})
+	}
+	return groups, nil
+}
+
+// CreateTask inserts the task f and records that it is blocked by each task
+// in blockedBy. Every blocker is checked to be a current task before
+// anything is written, so a typo in blockedBy fails the call cleanly rather
+// than leaving a task without its dependencies for the caller to duplicate
+// on retry. If a dependency still cannot be recorded, the new task is
+// deleted again before the error is returned. A new task has no dependents
+// yet, so its dependencies cannot form a cycle.
+func CreateTask(ctx context.Context, store Store, f Fact, blockedBy []int64) (int64, error) {
+	for _, id := range blockedBy {
+		t, err := getTask(ctx, store, id)
+		if err != nil {
+			return 0, fmt.Errorf("memstore: blocking task: %w", err)
+		}
+		if t.Fact.SupersededBy != nil {
+			return 0, fmt.Errorf("memstore: blocking task %d was superseded by %d", id, *t.Fact.SupersededBy)
+		}
+	}
+	id, err := store.Insert(ctx, f)
+	if err != nil {
+		return 0, err
+	}
+	for _, blocker := range blockedBy {
+		if err := AddTaskDependency(ctx, store, id, blocker); err != nil {
+			if delErr := store.Delete(ctx, id); delErr != nil {
+				return 0, fmt.Errorf("memstore: adding dependency on task %d: %w (and removing task %d failed: %v)", blocker, err, id, delErr)
+			}
+			return 0, fmt.Errorf("memstore: adding dependency on task %d: %w", blocker, err)
+		}
+	}
+	return id, nil
+}
+
+// AddTaskDependency records that task taskID is blocked by task blockerID.
+// Both must be tasks, and the dependency must not close a cycle; it
+// returns an error wrapping ErrTaskCycle naming the path if it would.
+// Adding an existing dependency is a no-op.
+func AddTaskDependency(ctx context.Context, store Store, taskID, blockerID int64) error {
+	if taskID == blockerID {
+		return fmt.Errorf("%w: task %d cannot block itself", ErrTaskCycle, taskID)
+	}
+	if _, err := getTask(ctx, store, taskID); err != nil {
+		return err
+	}
+	if _, err := getTask(ctx, store, blockerID); err != nil {
+		return err
+	}
+
+	existing, err := store.GetLinks(ctx, taskID, LinkOutbound, LinkBlockedBy)
+	if err != nil {
+		return err
+	}
+	for _, l := range existing {
+		if l.SourceID == taskID && l.TargetID == blockerID {
+			return nil
+		}
+	}
+	if path, err := dependencyPath(ctx, store, blockerID, taskID); err != nil {
+		return err
+	} else if path != nil {
+		steps := make([]string, len(path))
+		for i, id := range path {
+			steps[i] = fmt.Sprint(id)
+		}
+		return fmt.Errorf("%w: %d already waits on %d (%s)", ErrTaskCycle, blockerID, taskID, strings.Join(steps, " -> "))
+	}
+
+	_, err = store.LinkFacts(ctx, taskID, blockerID, LinkBlockedBy, false, "", nil)
+	return err
+}
+
+// dependencyPath returns the chain of blocked_by links leading from task
+// from to task to, or nil when from does not (transitively) wait on to.
+func dependencyPath(ctx context.Context, store Store, from, to int64) ([]int64, error) {
+	prev := map[int64]int64{from: 0}
+	queue := []int64{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		links, err := store.GetLinks(ctx, id, LinkOutbound, LinkBlockedBy)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range links {
+			if l.SourceID != id {
+				continue
+			}
+			next := l.TargetID
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			prev[next] = id
+			if next == to {
+				path := []int64{to}
+				for at := id; at != 0; at = prev[at] {
+					path = append(path, at)
+				}
+				slices.Reverse(path)
+				return path, nil
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil, nil
+}
+
+// RemoveTaskDependency removes the dependency of task taskID on task
+// blockerID.
+func RemoveTaskDependency(ctx context.Context, store Store, taskID, blockerID int64) error {
+	links, err := store.GetLinks(ctx, taskID, LinkOutbound, LinkBlockedBy)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if l.SourceID == taskID && l.TargetID == blockerID {
+			return store.DeleteLink(ctx, l.ID)
+		}
+	}
+	return fmt.Errorf("memstore: task %d is not blocked by task %d", taskID, blockerID)
+}
+
+// TaskUpdate is a change to a task. Empty fields are left unchanged; for
+// Due, Recur and Remind, "none" clears the field.
+type TaskUpdate struct {
+	Status string
+	Note   string
+	Owner  string
+	Due    string // YYYY-MM-DD or RFC3339
+	Recur  string // a rule ParseRecurrence accepts
+	Remind string // reminder lead before the due date, a TTL such as 2d
+}
+
+// TaskUpdateOutcome reports the knock-on effects of UpdateTask.
+type TaskUpdateOutcome struct {
+	Unblocked []Task // open tasks whose last open blocker this update closed
+	Next      *Task  // the next occurrence created by completing a recurring task
+}
+
+// UpdateTask applies u to task id. Changing the due date, recurrence or
+// reminder lead reschedules the task's reminder. Closing a task (completed
+// or cancelled) clears its startup surface flag and reports the tasks it
+// was the last open blocker of; completing a recurring task also creates
+// its next occurrence. Cancelling one ends the series.
+func UpdateTask(ctx context.Context, store Store, id int64, u TaskUpdate) (TaskUpdateOutcome, error) {
+	var out TaskUpdateOutcome
+	if u.Status != "" && !TaskStatusValid(u.Status) {
+		return out, fmt.Errorf("memstore: invalid task status %q", u.Status)
+	}
+	task, err := getTask(ctx, store, id)
+	if err != nil {
+		return out, err
+	}
+	now := time.Now()
+
+	patch := map[string]any{}
+	if u.Status != "" {
+		patch["status"] = u.Status
+		if taskClosed(u.Status) {
+			patch["surface"] = nil
+		}
+	}
+	if u.Note != "" {
+		patch["note"] = u.Note
+	}
+	if u.Owner != "" {
+		patch["owner"] = u.Owner
+	}
+	for key, val := range map[string]string{"due": u.Due, "recur": u.Recur, "remind": u.Remind} {
+		switch val {
+		case "":
+		case "none":
+			patch[key] = nil
+		default:
+			patch[key] = strings.TrimSpace(val)
+		}
+	}
+	if v, ok := patch["due"].(string); ok {
+		if _, err := ParseDue(v); err != nil {
+			return out, err
+		}
+	}
+	if len(patch) == 0 {
+		return out, errors.New("memstore: task update changes nothing")
+	}
+
+	var meta map[string]any
+	if len(task.Fact.Metadata) > 0 {
+		if err := json.Unmarshal(task.Fact.Metadata, &meta); err != nil {
+			return out, fmt.Errorf("memstore: decoding task %d metadata: %w", id, err)
+		}
+	}
+	merged := maps.Clone(meta)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	mergePatch(merged, patch)
+	if u.Due != "" || u.Recur != "" || u.Remind != "" {
+		sched, err := taskSchedule(merged, now)
+		if err != nil {
+			return out, err
+		}
+		maps.Copy(patch, sched)
+		mergePatch(merged, sched)
+	}
+
+	if err := store.UpdateMetadata(ctx, id, patch); err != nil {
+		return out, err
+	}
+	if !taskClosed(u.Status) {
+		return out, nil
+	}
+	if u.Status == TaskCompleted && !taskClosed(task.Status) {
+		if recur, _ := merged["recur"].(string); recur != "" {
+			if out.Next, err = nextOccurrence(ctx, store, task.Fact, merged, now); err != nil {
+				return out, err
+			}
+		}
+	}
+	out.Unblocked, err = unblockedBy(ctx, store, id)
+	return out, err
+}
+
+// unblockedBy returns the open tasks waiting on the closed task id that
+// no longer have an open blocker.
+func unblockedBy(ctx context.Context, store Store, id int64) ([]Task, error) {
+	links, err := store.GetLinks(ctx, id, LinkInbound, LinkBlockedBy)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, l := range links {
+		if l.TargetID == id {
+			ids = append(ids, l.SourceID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	facts, err := store.List(ctx, QueryOpts{IDs: ids, OnlyActive: true})
+	if err != nil {
+		return nil, err
+	}
+	var dependents []Task
+	for _, f := range facts {
+		if t, ok := taskFromFact(f); ok {
+			dependents = append(dependents, t)
+		}
+	}
+	if err := resolveTaskStates(ctx, store, time.Now(), dependents); err != nil {
+		return nil, err
+	}
+	var unblocked []Task
+	for _, t := range dependents {
+		if t.State == TaskStateReady || t.State == TaskStateInProgress {
+			unblocked = append(unblocked, t)
+		}
+	}
+	sortTasks(unblocked)
+	return unblocked, nil
+}
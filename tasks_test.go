@@ -0,0 +1,219 @@
+package memstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+// insertTestTask inserts a task fact with the given extra metadata.
+func insertTestTask(t *testing.T, store memstore.Store, content string, meta map[string]any) int64 {
+	t.Helper()
+	m := map[string]any{"kind": "task", "scope": "claude", "status": "pending", "priority": "normal"}
+	for k, v := range meta {
+		m[k] = v
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := store.Insert(context.Background(), memstore.Fact{
+		Content: content, Subject: "todo", Category: "note", Kind: "task", Metadata: raw,
+	})
+	if err != nil {
+		t.Fatalf("insert task %q: %v", content, err)
+	}
+	return id
+}
+
+func TestParseDue(t *testing.T) {
+	got, err := memstore.ParseDue("2026-05-01")
+	if err != nil || !got.Equal(time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDue(date) = %v, %v; want the end of 2026-05-01 UTC", got, err)
+	}
+	got, err = memstore.ParseDue("2026-05-01T09:00:00-04:00")
+	if err != nil || !got.Equal(time.Date(2026, 5, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDue(RFC3339) = %v, %v", got, err)
+	}
+	if _, err := memstore.ParseDue("next friday"); err == nil {
+		t.Error("ParseDue accepted a free-form date")
+	}
+}
+
+func TestTaskDependencies(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	design := insertTestTask(t, s, "design", nil)
+	build := insertTestTask(t, s, "build", nil)
+	ship := insertTestTask(t, s, "ship", nil)
+
+	for _, dep := range [][2]int64{{build, design}, {ship, build}, {ship, design}} {
+		if err := memstore.AddTaskDependency(ctx, s, dep[0], dep[1]); err != nil {
+			t.Fatalf("AddTaskDependency(%d, %d): %v", dep[0], dep[1], err)
+		}
+	}
+	// Re-adding is a no-op rather than a duplicate link.
+	if err := memstore.AddTaskDependency(ctx, s, build, design); err != nil {
+		t.Fatalf("re-add: %v", err)
+	}
+	if links, _ := s.GetLinks(ctx, build, memstore.LinkOutbound, memstore.LinkBlockedBy); len(links) != 1 {
+		t.Errorf("build has %d blocked_by links, want 1", len(links))
+	}
+
+	// design -> ship would close design -> ship -> build -> design.
+	if err := memstore.AddTaskDependency(ctx, s, design, ship); !errors.Is(err, memstore.ErrTaskCycle) {
+		t.Errorf("cycle: err = %v, want ErrTaskCycle", err)
+	}
+	if err := memstore.AddTaskDependency(ctx, s, design, design); !errors.Is(err, memstore.ErrTaskCycle) {
+		t.Errorf("self-dependency: err = %v, want ErrTaskCycle", err)
+	}
+	note := insertTestFact(t, s, "not a task", "todo")
+	if err := memstore.AddTaskDependency(ctx, s, build, note); !errors.Is(err, memstore.ErrNotATask) {
+		t.Errorf("non-task blocker: err = %v, want ErrNotATask", err)
+	}
+
+	states := func() map[int64]memstore.TaskState {
+		t.Helper()
+		tasks, err := memstore.LoadTasks(ctx, s)
+		if err != nil {
+			t.Fatalf("LoadTasks: %v", err)
+		}
+		m := make(map[int64]memstore.TaskState)
+		for _, task := range tasks {
+			m[task.Fact.ID] = task.State
+		}
+		return m
+	}
+	if got := states(); got[design] != memstore.TaskStateReady || got[build] != memstore.TaskStateBlocked || got[ship] != memstore.TaskStateBlocked {
+		t.Errorf("initial states = %v", got)
+	}
+
+	// Completing design unblocks build but not ship, which still waits on build.
+	res, err := memstore.UpdateTask(ctx, s, design, memstore.TaskUpdate{Status: memstore.TaskCompleted})
+	if err != nil {
+		t.Fatalf("complete design: %v", err)
+	}
+	if len(res.Unblocked) != 1 || res.Unblocked[0].Fact.ID != build {
+		t.Errorf("completing design unblocked %+v, want only build", res.Unblocked)
+	}
+	// Cancelling a blocker releases its dependents too.
+	res, err = memstore.UpdateTask(ctx, s, build, memstore.TaskUpdate{Status: memstore.TaskCancelled})
+	if err != nil {
+		t.Fatalf("cancel build: %v", err)
+	}
+	if len(res.Unblocked) != 1 || res.Unblocked[0].Fact.ID != ship {
+		t.Errorf("cancelling build unblocked %+v, want ship", res.Unblocked)
+	}
+	if got := states(); got[ship] != memstore.TaskStateReady || got[design] != memstore.TaskStateDone {
+		t.Errorf("final states = %v", got)
+	}
+
+	if err := memstore.RemoveTaskDependency(ctx, s, ship, design); err != nil {
+		t.Fatalf("RemoveTaskDependency: %v", err)
+	}
+	if err := memstore.RemoveTaskDependency(ctx, s, ship, design); err == nil {
+		t.Error("removing a missing dependency succeeded")
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	schema := insertTestTask(t, store, "Write the schema", nil)
+	old := insertTestTask(t, store, "Old plan", nil)
+	if _, err := store.Revise(ctx, old, "New plan", nil); err != nil {
+		t.Fatal(err)
+	}
+	note, err := store.Insert(ctx, memstore.Fact{Content: "a note", Subject: "todo", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := memstore.Fact{Content: "Build the API", Subject: "todo", Category: "note", Kind: "task",
+		Metadata: json.RawMessage(`{"kind":"task","scope":"claude","status":"pending"}`)}
+
+	for _, blockers := range [][]int64{{schema, 99999}, {note}, {old}} {
+		if _, err := memstore.CreateTask(ctx, store, task, blockers); err == nil {
+			t.Errorf("CreateTask(blocked by %v) succeeded", blockers)
+		}
+	}
+	if tasks, _ := memstore.LoadTasks(ctx, store); len(tasks) != 2 {
+		t.Fatalf("failed creates left tasks behind: %d tasks, want 2", len(tasks))
+	}
+
+	id, err := memstore.CreateTask(ctx, store, task, []int64{schema})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	tasks, err := memstore.LoadTasks(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tk := range tasks {
+		if tk.Fact.ID == id && !slices.Equal(tk.BlockedBy, []int64{schema}) {
+			t.Errorf("new task blocked by %v, want [%d]", tk.BlockedBy, schema)
+		}
+	}
+}
+
+func TestLoadTasks_OverdueAndOrder(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	later := insertTestTask(t, s, "later", map[string]any{"due": "2999-01-01", "priority": "high"})
+	late := insertTestTask(t, s, "late", map[string]any{"due": "2000-01-01", "priority": "low"})
+	legacy := insertTestTask(t, s, "legacy", map[string]any{"due": "someday"})
+	done := insertTestTask(t, s, "done late", map[string]any{"due": "2000-01-01", "status": "completed"})
+
+	tasks, err := memstore.LoadTasks(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []int64
+	overdue := map[int64]bool{}
+	for _, task := range tasks {
+		order = append(order, task.Fact.ID)
+		overdue[task.Fact.ID] = task.Overdue
+	}
+	// Overdue first, then by priority; among the normal tasks a parseable
+	// due date sorts ahead of a free-form one.
+	if want := []int64{late, later, done, legacy}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if !overdue[late] || overdue[later] || overdue[legacy] || overdue[done] {
+		t.Errorf("overdue = %v; want only the open task past its date", overdue)
+	}
+}
+
+func TestGroupTasks(t *testing.T) {
+	tasks := []memstore.Task{
+		{Fact: memstore.Fact{ID: 1}, State: memstore.TaskStateReady, Owner: "ana"},
+		{Fact: memstore.Fact{ID: 2}, State: memstore.TaskStateBlocked},
+		{Fact: memstore.Fact{ID: 3}, State: memstore.TaskStateReady, Owner: "bo"},
+	}
+	groups, err := memstore.GroupTasks(tasks, "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Name != "blocked" || groups[1].Name != "ready" || len(groups[1].Tasks) != 2 {
+		t.Errorf("state groups = %+v, want blocked then ready", groups)
+	}
+	groups, err = memstore.GroupTasks(tasks, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	if want := []string{"ana", "bo", "(none)"}; !slices.Equal(names, want) {
+		t.Errorf("owner groups = %v, want %v", names, want)
+	}
+	if _, err := memstore.GroupTasks(tasks, "color"); err == nil {
+		t.Error("GroupTasks accepted an unknown key")
+	}
+}
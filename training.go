@@ -0,0 +1,394 @@
+package memstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Training-data export (docs/training-data-design.md). The hint pipeline logs
+// every retrieval event -- the query, the full candidate list, the per-
+// candidate vec scores, and which candidates were selected -- and recall and
+// feedback log what was shown and how it was rated. This file turns those
+// events into learning-to-rank and preference-tuning datasets. It is pure:
+// the backend supplies TrainingEvents, and the builders here decide labels,
+// positions, and which preference pairs are clean enough to keep.
+
+// TrainingFormat selects the shape of an exported training dataset.
+type TrainingFormat string
+
+const (
+	// TrainingPointwise emits one (query, document, label) line per candidate.
+	TrainingPointwise TrainingFormat = "pointwise"
+	// TrainingPairwise emits one (query, chosen, rejected) preference pair per
+	// line, for DPO-style or pairwise LTR training.
+	TrainingPairwise TrainingFormat = "pairwise"
+	// TrainingListwise emits one line per retrieval event with every
+	// candidate in displayed order.
+	TrainingListwise TrainingFormat = "listwise"
+)
+
+// ParseTrainingFormat validates a training format name. The empty string
+// selects TrainingPointwise.
+func ParseTrainingFormat(s string) (TrainingFormat, error) {
+	switch TrainingFormat(s) {
+	case "":
+		return TrainingPointwise, nil
+	case TrainingPointwise, TrainingPairwise, TrainingListwise:
+		return TrainingFormat(s), nil
+	}
+	return "", fmt.Errorf("memstore: unknown training format %q (want pointwise, pairwise, or listwise)", s)
+}
+
+// TrainingFilter narrows which retrieval events a TrainingSource returns.
+type TrainingFilter struct {
+	RankerVersion string    // exact match; empty = every version
+	Since         time.Time // inclusive lower bound on event time; zero = unbounded
+	Until         time.Time // exclusive upper bound on event time; zero = unbounded
+	Limit         int       // max events, oldest first; 0 = all
+}
+
+// ParseTrainingTime parses a TrainingFilter bound: RFC 3339, or a bare
+// YYYY-MM-DD date meaning midnight UTC. The empty string is the zero time
+// (unbounded).
+func ParseTrainingTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("memstore: invalid time %q (want RFC 3339 or YYYY-MM-DD)", s)
+	}
+	return t, nil
+}
+
+// TrainingCandidate is one retrieved document within a TrainingEvent.
+type TrainingCandidate struct {
+	RefID     string  // fact ID
+	Subject   string  // fact subject
+	Text      string  // fact content at export time
+	Retrieved int     // 0-based index in the retrieval list
+	Rank      int     // 0-based displayed rank from the injection log; -1 if not recorded
+	Selected  bool    // chosen by the pipeline (in ref_ids)
+	Shown     bool    // actually reached a session (as a fact or inside an injected hint)
+	VecScore  float64 // first-stage similarity: the soft label
+
+	// Feedback is the explicit rating of this fact in the sessions the event
+	// reached; HintFeedback is the rating of the hint that carried it. Count
+	// 0 means unrated.
+	Feedback     FeedbackStat
+	HintFeedback FeedbackStat
+}
+
+// TrainingEvent is one logged retrieval: a query and the candidates it
+// produced, in retrieval order.
+type TrainingEvent struct {
+	HintID        int64
+	SessionID     string
+	Query         string
+	RankerVersion string
+	CreatedAt     time.Time
+	Candidates    []TrainingCandidate
+}
+
+// TrainingSource is implemented by session stores that can replay their
+// retrieval logs as training events (the Postgres SessionStore).
+type TrainingSource interface {
+	TrainingEvents(ctx context.Context, filter TrainingFilter) ([]TrainingEvent, error)
+}
+
+// TrainingExportOpts controls how events become examples.
+type TrainingExportOpts struct {
+	Format TrainingFormat
+
+	// Pairwise only. Preference data is worth more clean than plentiful
+	// (docs/training-data-design.md, "Less is More"): MinMargin drops pairs
+	// whose label gap is below the threshold, MaxPairsPerQuery keeps only the
+	// widest-margin pairs of each event, and MaxPairsPerDoc stops one
+	// frequently retrieved fact from dominating the dataset. Zero disables
+	// each filter. Identical (query, chosen, rejected) pairs are always
+	// emitted once.
+	MinMargin        float64
+	MaxPairsPerQuery int
+	MaxPairsPerDoc   int
+}
+
+// Label sources, recorded on every exported document so a trainer can weight
+// or filter by provenance.
+const (
+	LabelFeedback     = "feedback"      // explicit rating of the fact
+	LabelHintFeedback = "hint_feedback" // explicit rating of the hint that carried it
+	LabelSelected     = "selected"      // chosen by the pipeline, unrated
+	LabelUnselected   = "unselected"    // retrieved but not chosen
+)
+
+// selectedLabel is the label of an unrated, pipeline-selected candidate.
+// Selection is weaker evidence than an explicit +1, so it sits halfway
+// between a positive rating and an unselected (0) candidate.
+const selectedLabel = 0.5
+
+// TrainingFeatures are the per-document features exported with each example.
+type TrainingFeatures struct {
+	VecScore         float64 `json:"vec_score"`
+	PositionFraction float64 `json:"position_fraction"` // (position+1)/n: the propensity proxy
+	Selected         bool    `json:"selected"`
+	Shown            bool    `json:"shown"`
+	FeedbackCount    int     `json:"feedback_count"`
+}
+
+// TrainingDoc is one labeled document within an exported example.
+type TrainingDoc struct {
+	RefID       string           `json:"ref_id"`
+	Subject     string           `json:"subject"`
+	Document    string           `json:"document"`
+	Position    int              `json:"position"`
+	Label       float64          `json:"label"`
+	LabelSource string           `json:"label_source"`
+	Features    TrainingFeatures `json:"features"`
+}
+
+// PointwiseExample is one line of a pointwise dataset.
+type PointwiseExample struct {
+	Query         string    `json:"query"`
+	RankerVersion string    `json:"ranker_version"`
+	HintID        int64     `json:"hint_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	TrainingDoc
+}
+
+// PairwiseExample is one line of a pairwise dataset: Chosen is preferred
+// over Rejected by Margin label points.
+type PairwiseExample struct {
+	Query         string      `json:"query"`
+	RankerVersion string      `json:"ranker_version"`
+	HintID        int64       `json:"hint_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Chosen        TrainingDoc `json:"chosen"`
+	Rejected      TrainingDoc `json:"rejected"`
+	Margin        float64     `json:"margin"`
+}
+
+// ListwiseExample is one line of a listwise dataset: every candidate of one
+// event, in displayed order.
+type ListwiseExample struct {
+	Query         string        `json:"query"`
+	RankerVersion string        `json:"ranker_version"`
+	HintID        int64         `json:"hint_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Docs          []TrainingDoc `json:"docs"`
+}
+
+// trainingDocs labels an event's candidates and returns them in displayed
+// order. The displayed position is the recorded injection rank when there is
+// one, otherwise the retrieval index. Candidates whose fact no longer exists
+// (empty Text) are dropped: a document without text is not a training input.
+func trainingDocs(ev TrainingEvent) []TrainingDoc {
+	var cands []TrainingCandidate
+	for _, c := range ev.Candidates {
+		if c.Text != "" {
+			cands = append(cands, c)
+		}
+	}
+	pos := func(c TrainingCandidate) int {
+		if c.Rank >= 0 {
+			return c.Rank
+		}
+		return c.Retrieved
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		if pi, pj := pos(cands[i]), pos(cands[j]); pi != pj {
+			return pi < pj
+		}
+		return cands[i].Retrieved < cands[j].Retrieved
+	})
+
+	docs := make([]TrainingDoc, len(cands))
+	for i, c := range cands {
+		label, source := trainingLabel(c)
+		docs[i] = TrainingDoc{
+			RefID:       c.RefID,
+			Subject:     c.Subject,
+			Document:    c.Text,
+			Position:    i,
+			Label:       label,
+			LabelSource: source,
+			Features: TrainingFeatures{
+				VecScore:         c.VecScore,
+				PositionFraction: float64(i+1) / float64(len(cands)),
+				Selected:         c.Selected,
+				Shown:            c.Shown,
+				FeedbackCount:    c.Feedback.Count + c.HintFeedback.Count,
+			},
+		}
+	}
+	return docs
+}
+
+// trainingLabel picks the strongest available evidence for a candidate: an
+// explicit rating of the fact, then a rating of the hint that carried it
+// (which only speaks for the candidates the hint actually contained), then
+// the pipeline's own selection.
+func trainingLabel(c TrainingCandidate) (float64, string) {
+	switch {
+	case c.Feedback.Count > 0:
+		return c.Feedback.Avg, LabelFeedback
+	case c.Selected && c.HintFeedback.Count > 0:
+		return c.HintFeedback.Avg, LabelHintFeedback
+	case c.Selected:
+		return selectedLabel, LabelSelected
+	}
+	return 0, LabelUnselected
+}
+
+// PointwiseExamples flattens events into one labeled example per candidate.
+func PointwiseExamples(events []TrainingEvent) []PointwiseExample {
+	var out []PointwiseExample
+	for _, ev := range events {
+		for _, d := range trainingDocs(ev) {
+			out = append(out, PointwiseExample{
+				Query:         ev.Query,
+				RankerVersion: ev.RankerVersion,
+				HintID:        ev.HintID,
+				CreatedAt:     ev.CreatedAt,
+				TrainingDoc:   d,
+			})
+		}
+	}
+	return out
+}
+
+// ListwiseExamples emits one example per event that has at least two
+// candidates; a single-document list carries no ranking signal.
+func ListwiseExamples(events []TrainingEvent) []ListwiseExample {
+	var out []ListwiseExample
+	for _, ev := range events {
+		docs := trainingDocs(ev)
+		if len(docs) < 2 {
+			continue
+		}
+		out = append(out, ListwiseExample{
+			Query:         ev.Query,
+			RankerVersion: ev.RankerVersion,
+			HintID:        ev.HintID,
+			CreatedAt:     ev.CreatedAt,
+			Docs:          docs,
+		})
+	}
+	return out
+}
+
+// PairwiseExamples builds preference pairs within each event: every pair of
+// candidates whose labels differ, oriented so Chosen has the higher label,
+// then filtered per opts. Within an event the widest margins are kept first
+// (ties by displayed position), and events are consumed in order, so
+// MaxPairsPerDoc favors the oldest evidence deterministically.
+func PairwiseExamples(events []TrainingEvent, opts TrainingExportOpts) []PairwiseExample {
+	var out []PairwiseExample
+	seen := make(map[string]bool)
+	perDoc := make(map[string]int)
+	for _, ev := range events {
+		docs := trainingDocs(ev)
+		var pairs []PairwiseExample
+		for i := range docs {
+			for j := i + 1; j < len(docs); j++ {
+				a, b := docs[i], docs[j]
+				if b.Label > a.Label {
+					a, b = b, a
+				}
+				margin := a.Label - b.Label
+				if margin <= 0 || margin < opts.MinMargin {
+					continue
+				}
+				pairs = append(pairs, PairwiseExample{
+					Query:         ev.Query,
+					RankerVersion: ev.RankerVersion,
+					HintID:        ev.HintID,
+					CreatedAt:     ev.CreatedAt,
+					Chosen:        a,
+					Rejected:      b,
+					Margin:        margin,
+				})
+			}
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			if pairs[i].Margin != pairs[j].Margin {
+				return pairs[i].Margin > pairs[j].Margin
+			}
+			if pairs[i].Chosen.Position != pairs[j].Chosen.Position {
+				return pairs[i].Chosen.Position < pairs[j].Chosen.Position
+			}
+			return pairs[i].Rejected.Position < pairs[j].Rejected.Position
+		})
+
+		kept := 0
+		for _, p := range pairs {
+			if opts.MaxPairsPerQuery > 0 && kept >= opts.MaxPairsPerQuery {
+				break
+			}
+			key := normalizeTrainingQuery(p.Query) + "\x00" + p.Chosen.RefID + "\x00" + p.Rejected.RefID
+			if seen[key] {
+				continue
+			}
+			if opts.MaxPairsPerDoc > 0 &&
+				(perDoc[p.Chosen.RefID] >= opts.MaxPairsPerDoc || perDoc[p.Rejected.RefID] >= opts.MaxPairsPerDoc) {
+				continue
+			}
+			seen[key] = true
+			perDoc[p.Chosen.RefID]++
+			perDoc[p.Rejected.RefID]++
+			out = append(out, p)
+			kept++
+		}
+	}
+	return out
+}
+
+// normalizeTrainingQuery folds case and whitespace so the same query logged
+// twice dedupes as one.
+func normalizeTrainingQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// WriteTrainingData encodes events as a JSONL dataset in opts.Format and
+// returns the number of lines written.
+func WriteTrainingData(w io.Writer, events []TrainingEvent, opts TrainingExportOpts) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	emit := func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("memstore: write training data: %w", err)
+		}
+		n++
+		return nil
+	}
+	switch opts.Format {
+	case TrainingPointwise, "":
+		for _, ex := range PointwiseExamples(events) {
+			if err := emit(ex); err != nil {
+				return n, err
+			}
+		}
+	case TrainingPairwise:
+		for _, ex := range PairwiseExamples(events, opts) {
+			if err := emit(ex); err != nil {
+				return n, err
+			}
+		}
+	case TrainingListwise:
+		for _, ex := range ListwiseExamples(events) {
+			if err := emit(ex); err != nil {
+				return n, err
+			}
+		}
+	default:
+		return 0, fmt.Errorf("memstore: unknown training format %q", opts.Format)
+	}
+	return n, nil
+}
@@ -0,0 +1,199 @@
+package memstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// trainingEvent builds an event whose candidates are retrieved in the order
+// given. Candidates carry their own text so none are dropped.
+func trainingEvent(hintID int64, query string, cands ...TrainingCandidate) TrainingEvent {
+	for i := range cands {
+		cands[i].Retrieved = i
+		if cands[i].Text == "" {
+			cands[i].Text = "text of " + cands[i].RefID
+		}
+	}
+	return TrainingEvent{HintID: hintID, Query: query, RankerVersion: "hint-v1", Candidates: cands}
+}
+
+func cand(id string, selected bool, rank int) TrainingCandidate {
+	return TrainingCandidate{RefID: id, Selected: selected, Rank: rank}
+}
+
+func TestParseTrainingFormat(t *testing.T) {
+	for in, want := range map[string]TrainingFormat{
+		"":          TrainingPointwise,
+		"pointwise": TrainingPointwise,
+		"pairwise":  TrainingPairwise,
+		"listwise":  TrainingListwise,
+	} {
+		got, err := ParseTrainingFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseTrainingFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseTrainingFormat("triplet"); err == nil {
+		t.Error("ParseTrainingFormat(triplet): want error")
+	}
+}
+
+func TestParseTrainingTime(t *testing.T) {
+	if got, err := ParseTrainingTime(""); err != nil || !got.IsZero() {
+		t.Errorf("empty: got %v, %v; want zero time", got, err)
+	}
+	day, err := ParseTrainingTime("2026-03-01")
+	if err != nil || !day.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date: got %v, %v", day, err)
+	}
+	ts, err := ParseTrainingTime("2026-03-01T12:30:00Z")
+	if err != nil || ts.Hour() != 12 {
+		t.Errorf("RFC 3339: got %v, %v", ts, err)
+	}
+	if _, err := ParseTrainingTime("last tuesday"); err == nil {
+		t.Error("garbage: want error")
+	}
+}
+
+func TestTrainingLabel_EvidenceOrder(t *testing.T) {
+	rated := TrainingCandidate{Selected: true, Feedback: FeedbackStat{Avg: -1, Count: 1}, HintFeedback: FeedbackStat{Avg: 1, Count: 1}}
+	if l, src := trainingLabel(rated); l != -1 || src != LabelFeedback {
+		t.Errorf("fact feedback: got %v/%s, want -1/feedback", l, src)
+	}
+	viaHint := TrainingCandidate{Selected: true, HintFeedback: FeedbackStat{Avg: 1, Count: 2}}
+	if l, src := trainingLabel(viaHint); l != 1 || src != LabelHintFeedback {
+		t.Errorf("hint feedback: got %v/%s, want 1/hint_feedback", l, src)
+	}
+	// Hint feedback only speaks for what the hint contained.
+	notInHint := TrainingCandidate{HintFeedback: FeedbackStat{Avg: 1, Count: 2}}
+	if l, src := trainingLabel(notInHint); l != 0 || src != LabelUnselected {
+		t.Errorf("unselected with hint feedback: got %v/%s, want 0/unselected", l, src)
+	}
+	if l, src := trainingLabel(TrainingCandidate{Selected: true}); l != selectedLabel || src != LabelSelected {
+		t.Errorf("selected: got %v/%s, want %v/selected", l, src, selectedLabel)
+	}
+}
+
+func TestPointwiseExamples_PositionFromInjectionRank(t *testing.T) {
+	// Retrieved a, b, c; the injection log shows c displayed first.
+	ev := trainingEvent(1, "q", cand("a", true, 1), cand("b", false, -1), cand("c", true, 0))
+	ev.Candidates[1].Text = "" // fact deleted since: dropped
+	got := PointwiseExamples([]TrainingEvent{ev})
+	if len(got) != 2 {
+		t.Fatalf("got %d examples, want 2 (deleted fact dropped)", len(got))
+	}
+	if got[0].RefID != "c" || got[0].Position != 0 || got[1].RefID != "a" || got[1].Position != 1 {
+		t.Errorf("order = %s@%d, %s@%d; want c@0, a@1", got[0].RefID, got[0].Position, got[1].RefID, got[1].Position)
+	}
+	if got[1].Features.PositionFraction != 1 {
+		t.Errorf("last position_fraction = %v, want 1", got[1].Features.PositionFraction)
+	}
+	if got[0].Query != "q" || got[0].HintID != 1 || got[0].Document != "text of c" {
+		t.Errorf("example fields not carried: %+v", got[0])
+	}
+}
+
+func TestListwiseExamples_SkipsSingletons(t *testing.T) {
+	events := []TrainingEvent{
+		trainingEvent(1, "one", cand("a", true, -1)),
+		trainingEvent(2, "two", cand("a", true, -1), cand("b", false, -1)),
+	}
+	got := ListwiseExamples(events)
+	if len(got) != 1 || got[0].HintID != 2 || len(got[0].Docs) != 2 {
+		t.Fatalf("got %+v, want only hint 2 with 2 docs", got)
+	}
+}
+
+func TestPairwiseExamples_OrientationAndMargin(t *testing.T) {
+	good := cand("good", true, -1)
+	good.Feedback = FeedbackStat{Avg: 1, Count: 1}
+	bad := cand("bad", true, -1)
+	bad.Feedback = FeedbackStat{Avg: -1, Count: 1}
+	ev := trainingEvent(1, "q", bad, cand("sel", true, -1), cand("rej", false, -1), good)
+
+	all := PairwiseExamples([]TrainingEvent{ev}, TrainingExportOpts{})
+	// Labels: good 1, sel 0.5, rej 0, bad -1 -- all distinct, so C(4,2) pairs.
+	if len(all) != 6 {
+		t.Fatalf("got %d pairs, want 6", len(all))
+	}
+	for _, p := range all {
+		if p.Chosen.Label <= p.Rejected.Label || p.Margin != p.Chosen.Label-p.Rejected.Label {
+			t.Errorf("pair %s>%s mis-oriented or wrong margin %v", p.Chosen.RefID, p.Rejected.RefID, p.Margin)
+		}
+	}
+	if all[0].Chosen.RefID != "good" || all[0].Rejected.RefID != "bad" {
+		t.Errorf("widest margin first: got %s>%s, want good>bad", all[0].Chosen.RefID, all[0].Rejected.RefID)
+	}
+
+	clean := PairwiseExamples([]TrainingEvent{ev}, TrainingExportOpts{MinMargin: 1})
+	// good>rej (1), good>bad (2), sel>bad (1.5), rej>bad (1).
+	if len(clean) != 4 {
+		t.Errorf("MinMargin 1: got %d pairs, want 4", len(clean))
+	}
+	top := PairwiseExamples([]TrainingEvent{ev}, TrainingExportOpts{MaxPairsPerQuery: 2})
+	if len(top) != 2 || top[0].Margin != 2 || top[1].Margin != 1.5 {
+		t.Errorf("MaxPairsPerQuery 2: got %+v, want the margin-2 and margin-1.5 pairs", top)
+	}
+}
+
+func TestPairwiseExamples_Diversity(t *testing.T) {
+	events := []TrainingEvent{
+		trainingEvent(1, "Fix the build", cand("a", true, -1), cand("b", false, -1)),
+		trainingEvent(2, "fix  the BUILD", cand("a", true, -1), cand("b", false, -1)), // same query, same pair
+		trainingEvent(3, "other", cand("a", true, -1), cand("c", false, -1)),
+		trainingEvent(4, "third", cand("a", true, -1), cand("d", false, -1)),
+	}
+	got := PairwiseExamples(events, TrainingExportOpts{})
+	if len(got) != 3 {
+		t.Fatalf("duplicate pair not collapsed: got %d pairs, want 3", len(got))
+	}
+	capped := PairwiseExamples(events, TrainingExportOpts{MaxPairsPerDoc: 2})
+	if len(capped) != 2 || capped[1].HintID != 3 {
+		t.Errorf("MaxPairsPerDoc 2: got %d pairs (%+v), want hints 1 and 3", len(capped), capped)
+	}
+}
+
+func TestWriteTrainingData_JSONL(t *testing.T) {
+	events := []TrainingEvent{
+		trainingEvent(1, "q", cand("a", true, -1), cand("b", false, -1), cand("c", false, -1)),
+	}
+	for _, tc := range []struct {
+		format TrainingFormat
+		lines  int
+		key    string
+	}{
+		{TrainingPointwise, 3, "label"},
+		{TrainingPairwise, 2, "chosen"},
+		{TrainingListwise, 1, "docs"},
+	} {
+		var buf bytes.Buffer
+		n, err := WriteTrainingData(&buf, events, TrainingExportOpts{Format: tc.format})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		if n != tc.lines {
+			t.Errorf("%s: wrote %d lines, want %d", tc.format, n, tc.lines)
+		}
+		sc := bufio.NewScanner(&buf)
+		lines := 0
+		for sc.Scan() {
+			var m map[string]any
+			if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+				t.Fatalf("%s: line %d not JSON: %v", tc.format, lines, err)
+			}
+			if _, ok := m[tc.key]; !ok || m["query"] != "q" {
+				t.Errorf("%s: line %d missing %q or query: %v", tc.format, lines, tc.key, m)
+			}
+			lines++
+		}
+		if lines != tc.lines {
+			t.Errorf("%s: scanned %d lines, want %d", tc.format, lines, tc.lines)
+		}
+	}
+	if _, err := WriteTrainingData(&bytes.Buffer{}, events, TrainingExportOpts{Format: "bogus"}); err == nil {
+		t.Error("unknown format: want error")
+	}
+}
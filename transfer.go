@@ -37,11 +37,30 @@ type ExportedFact struct {
 	LastConfirmedAt *time.Time      `json:"last_confirmed_at,omitempty"`
 	UseCount        int             `json:"use_count,omitempty"`
 	LastUsedAt      *time.Time      `json:"last_used_at,omitempty"`
+	ExpiresAt       *time.Time      `json:"expires_at,omitempty"`
+	ArchivedAt      *time.Time      `json:"archived_at,omitempty"` // informational; an imported expired fact is re-archived by the next reaper sweep
 	CreatedAt       time.Time       `json:"created_at"`
+	Provenance      *Provenance     `json:"provenance,omitempty"` // as recorded by the exporting store; nil when unknown
+	Tags            []string        `json:"tags,omitempty"`
 }
 
-// Export reads all facts (all namespaces, including superseded) from the
-// database and returns them as an ExportData struct. The database must
+// importedProvenance is the provenance an imported fact is stored with: the
+// exported session, turns and origin are kept, the source becomes
+// SourceImport, and the document citation is dropped because document IDs do
+// not carry across stores.
+func importedProvenance(ef ExportedFact) Provenance {
+	p := Provenance{Source: SourceImport}
+	if ef.Provenance != nil {
+		p.SessionID = ef.Provenance.SessionID
+		p.TurnFirst = ef.Provenance.TurnFirst
+		p.TurnLast = ef.Provenance.TurnLast
+		p.Origin = ef.Provenance.Origin
+	}
+	return p
+}
+
+// Export reads all facts (all namespaces, including superseded but not
+// trashed) from the database and returns them as an ExportData struct. The database must
 // have been initialized by NewSQLiteStore at least once.
 func Export(ctx context.Context, db *sql.DB) (*ExportData, error) {
 	data := &ExportData{
@@ -65,9 +84,11 @@ func Export(ctx context.Context, db *sql.DB) (*ExportData, error) {
 	rows, err := db.QueryContext(ctx,
 		`SELECT f.id, f.namespace, COALESCE(u.name, ''), f.content, f.subject, f.category, f.kind, f.subsystem, f.metadata,
 		        f.superseded_by, f.superseded_at, f.confirmed_count, f.last_confirmed_at,
-		        f.use_count, f.last_used_at, f.created_at
+		        f.use_count, f.last_used_at, f.expires_at, f.archived_at, f.created_at,
+		        f.source, f.source_session, f.source_turn_first, f.source_turn_last, f.source_origin, f.source_document_id
 		 FROM memstore_facts f
 		 LEFT JOIN memstore_users u ON u.id = f.user_id
+		 WHERE f.deleted_at IS NULL
 		 ORDER BY f.id`)
 	if err != nil {
 		return nil, fmt.Errorf("memstore export: querying facts: %w", err)
@@ -81,12 +102,17 @@ func Export(ctx context.Context, db *sql.DB) (*ExportData, error) {
 		var supersededAt sql.NullString
 		var lastConfirmedAt sql.NullString
 		var lastUsedAt sql.NullString
+		var expiresAt sql.NullString
+		var archivedAt sql.NullString
 		var createdAt string
+		var prov Provenance
+		var documentID sql.NullInt64
 
 		if err := rows.Scan(&ef.ID, &ef.Namespace, &ef.User, &ef.Content, &ef.Subject, &ef.Category,
 			&ef.Kind, &ef.Subsystem, &metadata, &supersededBy, &supersededAt,
 			&ef.ConfirmedCount, &lastConfirmedAt,
-			&ef.UseCount, &lastUsedAt, &createdAt); err != nil {
+			&ef.UseCount, &lastUsedAt, &expiresAt, &archivedAt, &createdAt,
+			&prov.Source, &prov.SessionID, &prov.TurnFirst, &prov.TurnLast, &prov.Origin, &documentID); err != nil {
 			return nil, fmt.Errorf("memstore export: scanning fact: %w", err)
 		}
 
@@ -106,7 +132,19 @@ func Export(ctx context.Context, db *sql.DB) (*ExportData, error) {
 			t, _ := time.Parse(time.RFC3339, lastUsedAt.String)
 			ef.LastUsedAt = &t
 		}
+		if expiresAt.Valid {
+			t, _ := time.Parse(time.RFC3339, expiresAt.String)
+			ef.ExpiresAt = &t
+		}
+		if archivedAt.Valid {
+			t, _ := time.Parse(time.RFC3339, archivedAt.String)
+			ef.ArchivedAt = &t
+		}
 		ef.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		prov.DocumentID = documentID.Int64
+		if !prov.IsZero() {
+			ef.Provenance = &prov
+		}
 
 		data.Facts = append(data.Facts, ef)
 	}
@@ -114,6 +152,30 @@ func Export(ctx context.Context, db *sql.DB) (*ExportData, error) {
 		return nil, fmt.Errorf("memstore export: iterating facts: %w", err)
 	}
 
+	// Tags. Errors are non-fatal, as for the embedder metadata: the table
+	// does not exist in older schemas.
+	if tagRows, err := db.QueryContext(ctx, `SELECT fact_id, tag FROM memstore_fact_tags ORDER BY fact_id, tag`); err == nil {
+		tags := make(map[int64][]string)
+		for tagRows.Next() {
+			var (
+				id  int64
+				tag string
+			)
+			if err := tagRows.Scan(&id, &tag); err != nil {
+				tagRows.Close()
+				return nil, fmt.Errorf("memstore export: scanning tag: %w", err)
+			}
+			tags[id] = append(tags[id], tag)
+		}
+		tagRows.Close()
+		if err := tagRows.Err(); err != nil {
+			return nil, fmt.Errorf("memstore export: iterating tags: %w", err)
+		}
+		for i := range data.Facts {
+			data.Facts[i].Tags = tags[data.Facts[i].ID]
+		}
+	}
+
 	return data, nil
 }
 
@@ -171,13 +233,16 @@ func Import(ctx context.Context, db *sql.DB, data *ExportData, opts ImportOpts)
 			}
 
 			newID, err := store.Insert(ctx, Fact{
-				Content:   ef.Content,
-				Subject:   ef.Subject,
-				Category:  ef.Category,
-				Kind:      ef.Kind,
-				Subsystem: ef.Subsystem,
-				Metadata:  ef.Metadata,
-				CreatedAt: ef.CreatedAt,
+				Content:    ef.Content,
+				Subject:    ef.Subject,
+				Category:   ef.Category,
+				Kind:       ef.Kind,
+				Subsystem:  ef.Subsystem,
+				Metadata:   ef.Metadata,
+				ExpiresAt:  ef.ExpiresAt,
+				CreatedAt:  ef.CreatedAt,
+				Provenance: importedProvenance(ef),
+				Tags:       ef.Tags,
 			})
 			if err != nil {
 				return nil, fmt.Errorf("memstore import: inserting fact %d: %w", ef.ID, err)
@@ -254,13 +319,16 @@ func StoreImport(ctx context.Context, store Store, data *ExportData, opts Import
 		}
 
 		newID, err := store.Insert(ctx, Fact{
-			Content:   ef.Content,
-			Subject:   ef.Subject,
-			Category:  ef.Category,
-			Kind:      ef.Kind,
-			Subsystem: ef.Subsystem,
-			Metadata:  ef.Metadata,
-			CreatedAt: ef.CreatedAt,
+			Content:    ef.Content,
+			Subject:    ef.Subject,
+			Category:   ef.Category,
+			Kind:       ef.Kind,
+			Subsystem:  ef.Subsystem,
+			Metadata:   ef.Metadata,
+			ExpiresAt:  ef.ExpiresAt,
+			CreatedAt:  ef.CreatedAt,
+			Provenance: importedProvenance(ef),
+			Tags:       ef.Tags,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("memstore store-import: inserting fact %d: %w", ef.ID, err)
@@ -0,0 +1,228 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trash retention defaults. A month is long enough to notice a mistaken
+// delete; the hourly sweep keeps the purge cheap.
+const (
+	DefaultTrashRetention     = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+)
+
+// TrashPolicy configures automatic purging of the trash.
+type TrashPolicy struct {
+	Retention time.Duration // trashed facts older than this are purged; 0 = DefaultTrashRetention, <0 = never
+	Interval  time.Duration // 0 = DefaultTrashPurgeInterval
+}
+
+// Enabled reports whether the purger runs at all.
+func (p TrashPolicy) Enabled() bool { return p.Retention >= 0 }
+
+// TrashPolicyFromEnv reads a TrashPolicy from {prefix}_RETENTION (a TTL as
+// accepted by ParseTTL, or "off" to keep trashed facts until purged by hand)
+// and {prefix}_INTERVAL (a Go duration). Unset variables keep the defaults.
+func TrashPolicyFromEnv(prefix string) (TrashPolicy, error) {
+	var pol TrashPolicy
+	if v := os.Getenv(prefix + "_RETENTION"); v != "" {
+		if strings.EqualFold(v, "off") {
+			pol.Retention = -1
+		} else {
+			d, err := ParseTTL(v)
+			if err != nil {
+				return TrashPolicy{}, fmt.Errorf("memstore: invalid %s_RETENTION %q: want a duration such as 30d, or \"off\"", prefix, v)
+			}
+			pol.Retention = d
+		}
+	}
+	if v := os.Getenv(prefix + "_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return TrashPolicy{}, fmt.Errorf("memstore: invalid %s_INTERVAL %q: must be a positive duration", prefix, v)
+		}
+		pol.Interval = d
+	}
+	return pol, nil
+}
+
+// TrashPurger empties a store's trash on a timer, purging facts that have
+// been trashed for longer than the policy's retention.
+type TrashPurger struct {
+	store  Store
+	policy TrashPolicy
+	logf   func(format string, args ...any)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewTrashPurger creates a background trash purger. logf receives one line
+// per purge that removed anything and one per error; nil discards them.
+func NewTrashPurger(store Store, policy TrashPolicy, logf func(format string, args ...any)) *TrashPurger {
+	if policy.Retention == 0 {
+		policy.Retention = DefaultTrashRetention
+	}
+	if policy.Interval == 0 {
+		policy.Interval = DefaultTrashPurgeInterval
+	}
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	return &TrashPurger{store: store, policy: policy, logf: logf, done: make(chan struct{})}
+}
+
+// Start purges once immediately, then every interval.
+func (tp *TrashPurger) Start() {
+	tp.wg.Add(1)
+	go tp.loop()
+}
+
+// Stop signals the loop to stop and waits for it to finish.
+func (tp *TrashPurger) Stop() {
+	close(tp.done)
+	tp.wg.Wait()
+}
+
+func (tp *TrashPurger) loop() {
+	defer tp.wg.Done()
+	ticker := time.NewTicker(tp.policy.Interval)
+	defer ticker.Stop()
+
+	tp.PurgeOnce(context.Background())
+	for {
+		select {
+		case <-tp.done:
+			return
+		case <-ticker.C:
+			tp.PurgeOnce(context.Background())
+		}
+	}
+}
+
+// PurgeOnce purges facts trashed longer than the retention ago. Called from
+// the background loop and exposed for tests.
+func (tp *TrashPurger) PurgeOnce(ctx context.Context) (int64, error) {
+	if !tp.policy.Enabled() {
+		return 0, nil
+	}
+	n, err := tp.store.Purge(ctx, time.Now().Add(-tp.policy.Retention))
+	if err != nil {
+		tp.logf("trash purger: %v", err)
+		return 0, err
+	}
+	if n > 0 {
+		tp.logf("trash purger: purged %d facts trashed more than %s ago", n, tp.policy.Retention)
+	}
+	return n, nil
+}
+
+// migrateV15 adds soft deletion. deleted_at is stamped by Delete and cleared
+// by Restore; Purge removes the row. The partial index serves Trash and
+// Purge without touching live facts.
+func (s *SQLiteStore) migrateV15() error {
+	stmts := []string{
+		`ALTER TABLE memstore_facts ADD COLUMN deleted_at TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_memstore_deleted ON memstore_facts(deleted_at) WHERE deleted_at IS NOT NULL`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("memstore V15 migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// Trash lists trashed facts in this namespace, most recently deleted first.
+func (s *SQLiteStore) Trash(ctx context.Context, opts QueryOpts) ([]Fact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q := `SELECT ` + factColumns + ` FROM memstore_facts WHERE namespace = ? AND deleted_at IS NOT NULL`
+	args := []any{s.namespace}
+	if opts.Subject != "" {
+		q += ` AND subject = ?`
+		args = append(args, opts.Subject)
+	}
+	if opts.Category != "" {
+		q += ` AND category = ?`
+		args = append(args, opts.Category)
+	}
+	if opts.Kind != "" {
+		q += ` AND kind = ?`
+		args = append(args, opts.Kind)
+	}
+	if opts.Subsystem != "" {
+		q += ` AND subsystem = ?`
+		args = append(args, opts.Subsystem)
+	}
+	q += ` ORDER BY deleted_at DESC, id DESC`
+	if opts.Limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: listing trash: %w", err)
+	}
+	defer rows.Close()
+
+	return scanFacts(rows)
+}
+
+// Restore takes a fact out of the trash. Its links were never removed, so
+// they reappear with it.
+func (s *SQLiteStore) Restore(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE memstore_facts SET deleted_at = NULL WHERE id = ? AND namespace = ? AND deleted_at IS NOT NULL`,
+		id, s.namespace,
+	)
+	if err != nil {
+		return fmt.Errorf("memstore: restoring fact %d: %w", id, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("memstore: checking restore result: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("memstore: fact %d not found in trash", id)
+	}
+	return nil
+}
+
+// Purge permanently removes facts trashed at or before olderThan. The
+// recursive CTE also collects every fact superseded by one of them, however
+// far back the chain goes, because superseded_by has no ON DELETE action.
+// Links go with their facts via ON DELETE CASCADE.
+func (s *SQLiteStore) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.ExecContext(ctx,
+		`WITH RECURSIVE doomed(id) AS (
+			SELECT id FROM memstore_facts
+			WHERE namespace = ? AND deleted_at IS NOT NULL AND deleted_at <= ?
+			UNION
+			SELECT f.id FROM memstore_facts f JOIN doomed d ON f.superseded_by = d.id
+		)
+		DELETE FROM memstore_facts WHERE id IN (SELECT id FROM doomed)`,
+		s.namespace, olderThan.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("memstore: purging trash: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("memstore: checking purge result: %w", err)
+	}
+	return n, nil
+}
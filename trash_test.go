@@ -0,0 +1,65 @@
+package memstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matthewjhunter/memstore"
+)
+
+func TestTrashPolicyFromEnv(t *testing.T) {
+	p, err := memstore.TrashPolicyFromEnv("TESTTRASH")
+	if err != nil || !p.Enabled() || p.Retention != 0 {
+		t.Errorf("default policy = %+v, %v; want enabled with the default retention", p, err)
+	}
+	t.Setenv("TESTTRASH_RETENTION", "2w")
+	t.Setenv("TESTTRASH_INTERVAL", "5m")
+	if p, err = memstore.TrashPolicyFromEnv("TESTTRASH"); err != nil || p.Retention != 14*24*time.Hour || p.Interval != 5*time.Minute {
+		t.Errorf("policy = %+v, %v", p, err)
+	}
+	t.Setenv("TESTTRASH_RETENTION", "off")
+	if p, _ = memstore.TrashPolicyFromEnv("TESTTRASH"); p.Enabled() {
+		t.Error("off policy reports enabled")
+	}
+	t.Setenv("TESTTRASH_RETENTION", "forever")
+	if _, err := memstore.TrashPolicyFromEnv("TESTTRASH"); err == nil {
+		t.Error("expected an error for an unparseable retention")
+	}
+}
+
+func TestTrashPurger_PurgeOnce(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	id, err := store.Insert(ctx, memstore.Fact{Content: "scratch note", Subject: "memstore", Category: "note"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default month-long retention leaves a fresh deletion alone.
+	if n, err := memstore.NewTrashPurger(store, memstore.TrashPolicy{}, nil).PurgeOnce(ctx); err != nil || n != 0 {
+		t.Errorf("default purge = %d, %v; want nothing", n, err)
+	}
+	if n, _ := memstore.NewTrashPurger(store, memstore.TrashPolicy{Retention: -1}, nil).PurgeOnce(ctx); n != 0 {
+		t.Errorf("disabled purge removed %d facts", n)
+	}
+
+	var logged int
+	purger := memstore.NewTrashPurger(store, memstore.TrashPolicy{Retention: time.Nanosecond},
+		func(string, ...any) { logged++ })
+	if n, err := purger.PurgeOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("purge = %d, %v; want 1", n, err)
+	}
+	if logged != 1 {
+		t.Errorf("logged %d lines, want one for the purge", logged)
+	}
+	if trash, _ := store.Trash(ctx, memstore.QueryOpts{}); len(trash) != 0 {
+		t.Errorf("trash still holds %d facts", len(trash))
+	}
+
+	purger.Start()
+	purger.Stop()
+}